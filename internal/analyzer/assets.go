@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+// DefaultSharedPayloadMinRepos is how many repositories of other owners must ship the same
+// release payload before the shared payload heuristic fires.
+const DefaultSharedPayloadMinRepos = 2
+
+// AssetHashLookup finds stored repositories ("owner/name") that ship a release asset hash.
+type AssetHashLookup interface {
+	FindReposByAssetHash(hash string) ([]string, error)
+}
+
+// AssetHash identifies an asset's content. It is GitHub's sha256 digest when the asset has one,
+// and otherwise the asset's size and lowercased name, which is weaker but still ties re-uploads
+// of one file together without downloading it.
+func AssetHash(asset models.ReleaseAsset) string {
+	if strings.HasPrefix(asset.Digest, "sha256:") {
+		return strings.ToLower(asset.Digest)
+	}
+	return fmt.Sprintf("size:%d:%s", asset.Size, strings.ToLower(asset.Name))
+}
+
+// PayloadAssets returns the archives and executables among assets with Hash set. Other assets,
+// such as checksums and source tarballs, say nothing about a payload. Empty files are skipped.
+func PayloadAssets(assets []models.ReleaseAsset) []models.ReleaseAsset {
+	var payloads []models.ReleaseAsset
+	for _, asset := range assets {
+		if asset.Size <= 0 || !isPayloadFile(asset.Name) {
+			continue
+		}
+		asset.Hash = AssetHash(asset)
+		payloads = append(payloads, asset)
+	}
+	return payloads
+}
+
+// SharedPayloadResult is the flag raised for a repository whose release ships a payload found
+// in repos of other owners.
+func SharedPayloadResult(asset models.ReleaseAsset, others []string) models.HeuristicResult {
+	return models.HeuristicResult{
+		Category: "Malware",
+		Flag:     true,
+		Name:     "SharedPayloadHeuristic",
+		Description: fmt.Sprintf("Release asset %s (%s) is also shipped by %s owned by other accounts: %s.",
+			asset.Name, asset.Hash, pluralize(len(others), "repository", "repositories"), strings.Join(others, ", ")),
+	}
+}
+
+// CheckSharedPayloads looks up stored repositories shipping each of repo's payload assets and
+// flags repo for the first asset that at least the configured number of other owners' repos
+// also ship. The returned asset is the one that matched.
+func (a *Analyzer) CheckSharedPayloads(repo models.RepoData, payloads []models.ReleaseAsset) (models.HeuristicResult, models.ReleaseAsset, error) {
+	if a.assetHashes == nil {
+		return models.HeuristicResult{}, models.ReleaseAsset{}, nil
+	}
+	for _, asset := range payloads {
+		matches, err := a.assetHashes.FindReposByAssetHash(asset.Hash)
+		if err != nil {
+			return models.HeuristicResult{}, models.ReleaseAsset{}, err
+		}
+		var others []string
+		for _, match := range matches {
+			owner, _, _ := strings.Cut(match, "/")
+			if !strings.EqualFold(owner, repo.Owner) {
+				others = append(others, match)
+			}
+		}
+		if len(others) >= a.sharedPayloadMinRepos {
+			return SharedPayloadResult(asset, others), asset, nil
+		}
+	}
+	return models.HeuristicResult{}, models.ReleaseAsset{}, nil
+}
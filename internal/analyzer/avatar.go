@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // registers the GIF decoder for avatars
+	_ "image/jpeg"
+	_ "image/png"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// DefaultAvatarMaxDistance is how many of the 64 hash bits two avatars may differ in and
+	// still count as the same image.
+	DefaultAvatarMaxDistance = 5
+	// DefaultAvatarMinMatches is how many flagged accounts must share a user's avatar before
+	// AvatarReuseHeuristic flags the user.
+	DefaultAvatarMinMatches = 3
+	// maxAvatarPixels bounds the decoded size of an avatar, so a small compressed file cannot
+	// expand into a huge image.
+	maxAvatarPixels = 2048 * 2048
+)
+
+// ErrIdenticon is returned by AvatarHash for GitHub's generated default avatars, which look
+// alike for unrelated accounts and are never compared.
+var ErrIdenticon = errors.New("default identicon avatar")
+
+// AvatarHashLookup finds flagged users whose stored avatar hash differs from hash in at most
+// maxDistance bits.
+type AvatarHashLookup interface {
+	FindFlaggedUsersByAvatarHash(hash uint64, maxDistance int) ([]string, error)
+}
+
+// AvatarHash returns the 64-bit difference hash of a PNG, JPEG, or GIF avatar: the image is
+// shrunk to 9x8 grayscale cells and each bit records whether a cell is darker than its right
+// neighbour. Rescaled and recompressed copies of an image hash within a few bits of each other.
+// GitHub identicons return ErrIdenticon.
+func AvatarHash(data []byte) (uint64, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("decoding avatar: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxAvatarPixels {
+		return 0, fmt.Errorf("avatar is %dx%d pixels", config.Width, config.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("decoding avatar: %w", err)
+	}
+	if isIdenticon(img) {
+		return 0, ErrIdenticon
+	}
+
+	bounds := img.Bounds()
+	var cells [8][9]float64
+	for y := range cells {
+		for x := range cells[y] {
+			cells[y][x] = averageLuma(img, image.Rect(
+				bounds.Min.X+x*bounds.Dx()/9, bounds.Min.Y+y*bounds.Dy()/8,
+				bounds.Min.X+(x+1)*bounds.Dx()/9, bounds.Min.Y+(y+1)*bounds.Dy()/8,
+			))
+		}
+	}
+	var hash uint64
+	for y := range cells {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if cells[y][x] < cells[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash, nil
+}
+
+// FormatAvatarHash renders hash as the 16 hex digits it is stored as.
+func FormatAvatarHash(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// ParseAvatarHash parses a hash rendered by FormatAvatarHash.
+func ParseAvatarHash(s string) (uint64, error) {
+	hash, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing avatar hash %q: %w", s, err)
+	}
+	return hash, nil
+}
+
+// AvatarDistance is the number of bits two avatar hashes differ in.
+func AvatarDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// averageLuma is the mean luma of the pixels in rect, which is widened to one pixel when empty.
+func averageLuma(img image.Image, rect image.Rectangle) float64 {
+	if rect.Dx() == 0 {
+		rect.Max.X = rect.Min.X + 1
+	}
+	if rect.Dy() == 0 {
+		rect.Max.Y = rect.Min.Y + 1
+	}
+	var sum float64
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			sum += float64(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+		}
+	}
+	return sum / float64(rect.Dx()*rect.Dy())
+}
+
+// identiconBackground is the light gray GitHub draws identicon patterns on.
+var identiconBackground = color.RGBA{R: 240, G: 240, B: 240, A: 255}
+
+// isIdenticon reports whether img looks like a GitHub identicon: one foreground color on the
+// identicon background, mirrored left to right. It samples a 40x40 grid and tolerates a few
+// blended pixels along the edges of rescaled identicons.
+func isIdenticon(img image.Image) bool {
+	const grid = 40
+	bounds := img.Bounds()
+	var foreground color.RGBA
+	var background, matched, other, asymmetric int
+	for gy := 0; gy < grid; gy++ {
+		y := bounds.Min.Y + (2*gy+1)*bounds.Dy()/(2*grid)
+		for gx := 0; gx < grid; gx++ {
+			x := bounds.Min.X + (2*gx+1)*bounds.Dx()/(2*grid)
+			pixel := rgba(img.At(x, y))
+			switch {
+			case similarColor(pixel, identiconBackground):
+				background++
+			case matched == 0:
+				foreground = pixel
+				matched++
+			case similarColor(pixel, foreground):
+				matched++
+			default:
+				other++
+			}
+			mirror := rgba(img.At(bounds.Max.X-1-(x-bounds.Min.X), y))
+			if !similarColor(pixel, mirror) {
+				asymmetric++
+			}
+		}
+	}
+	const samples = grid * grid
+	return matched > 0 && background >= samples/5 && other*20 <= samples && asymmetric*20 <= samples
+}
+
+func rgba(c color.Color) color.RGBA {
+	r, g, b, a := c.RGBA()
+	return color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)}
+}
+
+func similarColor(a, b color.RGBA) bool {
+	const tolerance = 8
+	near := func(x, y uint8) bool { return int(x)-int(y) <= tolerance && int(y)-int(x) <= tolerance }
+	return near(a.R, b.R) && near(a.G, b.G) && near(a.B, b.B) && near(a.A, b.A)
+}
+
+// AvatarReuseHeuristic detects accounts whose avatar is shared with several flagged accounts,
+// as campaign accounts reuse a handful of images. The analyzer fills in UserData.AvatarMatches
+// when an AvatarHashLookup is configured.
+type AvatarReuseHeuristic struct{}
+
+// Evaluate evaluates the avatar reuse heuristic.
+func (h *AvatarReuseHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
+	flag := len(data.AvatarMatches) >= DefaultAvatarMinMatches
+	description := "User avatar is shared with several flagged accounts."
+	if flag {
+		listed := data.AvatarMatches
+		if len(listed) > maxListedRepoNames {
+			listed = listed[:maxListedRepoNames]
+		}
+		description = fmt.Sprintf("Avatar matches %s: %s.", pluralize(len(data.AvatarMatches), "flagged account", "flagged accounts"), strings.Join(listed, ", "))
+	}
+
+	return models.HeuristicResult{
+		Category:    "Automated Activity",
+		Flag:        flag,
+		Name:        "AvatarReuseHeuristic",
+		Description: description,
+	}
+}
@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// DefaultBinaryOnlyMinStars is how many stars a binary-only repository needs before
+	// BinaryOnlyChecker flags it, so a starless personal backup is left alone.
+	DefaultBinaryOnlyMinStars = 10
+	// binaryOnlyMaxFiles is the most files a repository may hold and still count as binary-only.
+	binaryOnlyMaxFiles = 5
+)
+
+// binaryExtensions are the archives and Windows PE and Linux ELF binaries a binary-only
+// repository ships in place of code.
+var binaryExtensions = map[string]bool{
+	".zip": true, ".rar": true, ".7z": true, ".exe": true, ".dll": true, ".scr": true,
+	".msi": true, ".sys": true, ".elf": true, ".so": true, ".bin": true, ".appimage": true,
+}
+
+// projectSourceExtensions are the source files of a real project. Unlike sourceExtensions they
+// leave out the batch and VBScript launchers that droppers ship next to their payload.
+var projectSourceExtensions = map[string]bool{
+	".py": true, ".js": true, ".mjs": true, ".ts": true, ".go": true, ".rs": true, ".c": true,
+	".h": true, ".cc": true, ".cpp": true, ".hpp": true, ".cs": true, ".java": true, ".kt": true,
+	".swift": true, ".rb": true, ".php": true, ".lua": true, ".sh": true, ".dart": true,
+	".scala": true, ".zig": true,
+}
+
+// BinaryOnlyChecker flags repositories that hold nothing but archives or executables and a
+// README: no source files, no license, and at most five files. A common lure presents a
+// cracked tool or cheat this way, so there is no code to review. Only repositories with at
+// least MinStars stars are flagged, since a starless one is more likely a personal backup. It
+// reads only the tree CheckRepoFiles already fetched, and reports at medium severity.
+type BinaryOnlyChecker struct {
+	// MinStars overrides DefaultBinaryOnlyMinStars when positive.
+	MinStars int
+}
+
+// Check evaluates a repository's tree for binaries without source.
+func (bc *BinaryOnlyChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := bc.Run(ctx, repo)
+	return result.Flagged, err
+}
+
+// Run evaluates a repository's tree for binaries without source.
+func (bc *BinaryOnlyChecker) Run(_ context.Context, repo models.RepoData) (models.CheckerResult, error) {
+	result := models.CheckerResult{Name: "BinaryOnlyChecker", Severity: models.SeverityMedium}
+	minStars := bc.MinStars
+	if minStars <= 0 {
+		minStars = DefaultBinaryOnlyMinStars
+	}
+	if len(repo.TreeEntries) == 0 || len(repo.TreeEntries) > binaryOnlyMaxFiles {
+		return result, nil
+	}
+	var binaries []string
+	for _, entry := range repo.TreeEntries {
+		lower := strings.ToLower(entry)
+		base := path.Base(lower)
+		if projectSourceExtensions[path.Ext(lower)] || strings.HasPrefix(base, "license") || strings.HasPrefix(base, "copying") {
+			return result, nil
+		}
+		if binaryExtensions[path.Ext(lower)] {
+			binaries = append(binaries, entry)
+		}
+	}
+	if len(binaries) == 0 {
+		return result, nil
+	}
+	if repo.StargazerCount < minStars {
+		result.Evidence = fmt.Sprintf("Tree holds only %s and no source, but the repository has %s.",
+			strings.Join(binaries, ", "), pluralize(repo.StargazerCount, "star", "stars"))
+		return result, nil
+	}
+	result.Flagged = true
+	result.Evidence = fmt.Sprintf("Tree of %s holds %s and no source or license, on a repository with %s: %s.",
+		pluralize(len(repo.TreeEntries), "file", "files"), pluralize(len(binaries), "archive or executable", "archives or executables"),
+		pluralize(repo.StargazerCount, "star", "stars"), strings.Join(binaries, ", "))
+	return result, nil
+}
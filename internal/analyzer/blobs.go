@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+// DefaultPayloadBlobMinLength is the shortest base64 or hex run PayloadBlobChecker reports.
+// Hashes, keys, and lockfile integrity strings are far shorter.
+const DefaultPayloadBlobMinLength = 2000
+
+// PayloadBlobChecker flags small repositories whose source files embed a long base64 or hex
+// string, a common way to hide a second-stage payload in an innocent-looking script. It reads
+// the files Sources samples.
+type PayloadBlobChecker struct {
+	Sources *SourceSampler
+	// MinLength is the shortest run reported. Zero uses DefaultPayloadBlobMinLength.
+	MinLength int
+}
+
+// Check evaluates a repository's source files for embedded blobs.
+func (pc *PayloadBlobChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := pc.Run(ctx, repo)
+	return result.Flagged, err
+}
+
+// Run evaluates a repository's source files for embedded blobs.
+func (pc *PayloadBlobChecker) Run(ctx context.Context, repo models.RepoData) (models.CheckerResult, error) {
+	result := models.CheckerResult{Name: "PayloadBlobChecker", Severity: models.SeverityMedium}
+	minLength := pc.MinLength
+	if minLength <= 0 {
+		minLength = DefaultPayloadBlobMinLength
+	}
+	files, err := pc.Sources.Files(ctx, repo)
+	if err != nil {
+		return result, err
+	}
+
+	var matches []string
+	for _, file := range files {
+		if kind, length := longestBlob(file.Content); length >= minLength {
+			matches = append(matches, fmt.Sprintf("%s (%d-character %s string)", file.Path, length, kind))
+		}
+	}
+	if len(matches) > 0 {
+		result.Flagged = true
+		result.Evidence = fmt.Sprintf("Embeds an encoded blob in %s: %s.", pluralize(len(matches), "file", "files"), strings.Join(matches, ", "))
+	}
+	return result, nil
+}
+
+// longestBlob returns the longest run of base64 characters in content and whether it is "hex"
+// or "base64". Runs that cannot be encoded data, such as long identifiers without digits, are
+// ignored.
+func longestBlob(content string) (kind string, length int) {
+	start := -1
+	for i := 0; i <= len(content); i++ {
+		if i < len(content) && isBase64Char(content[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start > length {
+			if runKind := blobKind(content[start:i]); runKind != "" {
+				kind, length = runKind, i-start
+			}
+		}
+		start = -1
+	}
+	return kind, length
+}
+
+// blobKind classifies a run of base64 characters: hex digits with at least one digit are hex,
+// and runs mixing upper case, lower case, and digits are base64.
+func blobKind(run string) string {
+	var upper, lower, digit, nonHex bool
+	for i := 0; i < len(run); i++ {
+		c := run[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digit = true
+		case c >= 'A' && c <= 'Z':
+			upper = true
+			nonHex = nonHex || c > 'F'
+		case c >= 'a' && c <= 'z':
+			lower = true
+			nonHex = nonHex || c > 'f'
+		default:
+			nonHex = true
+		}
+	}
+	switch {
+	case digit && !nonHex:
+		return "hex"
+	case digit && upper && lower:
+		return "base64"
+	default:
+		return ""
+	}
+}
+
+func isBase64Char(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '+' || c == '/' || c == '='
+}
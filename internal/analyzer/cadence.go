@@ -0,0 +1,152 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// DefaultCommitCadenceCommits is how many recent commits CommitCadenceChecker reads.
+	DefaultCommitCadenceCommits = 30
+	// MaxCommitCadenceCommits caps the commits CommitCadenceChecker reads, the most one request
+	// returns.
+	MaxCommitCadenceCommits = 100
+	// DefaultCommitCadenceMaxVariation is the coefficient of variation of the intervals between
+	// commits, their standard deviation over their mean, at or under which CommitCadenceChecker
+	// calls them machine-regular. People who commit every hour still drift by minutes.
+	DefaultCommitCadenceMaxVariation = 0.05
+	// DefaultCommitCadenceSameSecond is how many commits sharing one exact timestamp flag a
+	// repository.
+	DefaultCommitCadenceSameSecond = 10
+	// minCadenceCommits is the fewest dated commits CommitCadenceChecker judges at all.
+	minCadenceCommits = 5
+	// minRegularCommits is the fewest dated commits whose intervals are judged for regularity.
+	minRegularCommits = 10
+)
+
+// CommitCadenceChecker flags repositories whose recent commits are timed by a script: the
+// intervals between at least ten commits barely vary, such as a commit every 60 minutes to the
+// second, or at least SameSecond commits share one exact timestamp. It judges author dates,
+// which rebases keep, and exempts repositories with fewer than five commits. The evidence
+// gives the interval statistics so reviewers can check them. It costs one request per
+// repository, and reports at low severity.
+type CommitCadenceChecker struct {
+	Client github.GitHubAPI
+	// MaxCommits caps the commits read. Zero uses DefaultCommitCadenceCommits, and it is never
+	// more than MaxCommitCadenceCommits.
+	MaxCommits int
+	// MaxVariation overrides DefaultCommitCadenceMaxVariation when positive.
+	MaxVariation float64
+	// SameSecond overrides DefaultCommitCadenceSameSecond when positive.
+	SameSecond int
+}
+
+// Check evaluates the timing of a repository's recent commits.
+func (cc *CommitCadenceChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := cc.Run(ctx, repo)
+	return result.Flagged, err
+}
+
+// Run evaluates the timing of a repository's recent commits on its default branch.
+func (cc *CommitCadenceChecker) Run(ctx context.Context, repo models.RepoData) (models.CheckerResult, error) {
+	result := models.CheckerResult{Name: "CommitCadenceChecker", Severity: models.SeverityLow}
+	limit := cc.MaxCommits
+	if limit <= 0 {
+		limit = DefaultCommitCadenceCommits
+	}
+	maxVariation := cc.MaxVariation
+	if maxVariation <= 0 {
+		maxVariation = DefaultCommitCadenceMaxVariation
+	}
+	sameSecond := cc.SameSecond
+	if sameSecond <= 0 {
+		sameSecond = DefaultCommitCadenceSameSecond
+	}
+	commits, err := cc.Client.GetRepoCommits(ctx, repo.Owner, repo.Name, "", min(limit, MaxCommitCadenceCommits))
+	if err != nil {
+		return result, err
+	}
+	var dates []time.Time
+	for _, commit := range commits {
+		if !commit.Date.IsZero() {
+			dates = append(dates, commit.Date.Truncate(time.Second))
+		}
+	}
+	if len(dates) < minCadenceCommits {
+		return result, nil
+	}
+
+	stats := CommitIntervals(dates)
+	switch {
+	case stats.SameSecond >= sameSecond:
+		result.Flagged = true
+		result.Evidence = fmt.Sprintf("%d of %d recent commits share the timestamp %s; %s.",
+			stats.SameSecond, len(dates), stats.BusiestSecond.UTC().Format(time.RFC3339), stats)
+	case len(dates) >= minRegularCommits && stats.Mean > 0 && stats.Variation <= maxVariation:
+		result.Flagged = true
+		result.Evidence = fmt.Sprintf("%d recent commits come at machine-regular intervals; %s.", len(dates), stats)
+	}
+	return result, nil
+}
+
+// CommitIntervalStats summarizes the intervals between commits.
+type CommitIntervalStats struct {
+	Mean, StdDev, Shortest, Longest time.Duration
+	// Variation is StdDev over Mean, or zero when Mean is zero.
+	Variation float64
+	// SameSecond is the most commits sharing one timestamp, and BusiestSecond that timestamp.
+	SameSecond    int
+	BusiestSecond time.Time
+}
+
+// String describes the statistics for evidence.
+func (s CommitIntervalStats) String() string {
+	return fmt.Sprintf("intervals average %s with a standard deviation of %s (variation %.3f), from %s to %s",
+		s.Mean.Round(time.Second), s.StdDev.Round(time.Second), s.Variation, s.Shortest.Round(time.Second), s.Longest.Round(time.Second))
+}
+
+// CommitIntervals computes the interval statistics of commit dates given in any order.
+func CommitIntervals(dates []time.Time) CommitIntervalStats {
+	var stats CommitIntervalStats
+	sorted := slices.Clone(dates)
+	slices.SortFunc(sorted, func(a, b time.Time) int { return a.Compare(b) })
+	for i := 0; i < len(sorted); {
+		j := i + 1
+		for j < len(sorted) && sorted[j].Equal(sorted[i]) {
+			j++
+		}
+		if j-i > stats.SameSecond {
+			stats.SameSecond, stats.BusiestSecond = j-i, sorted[i]
+		}
+		i = j
+	}
+	if len(sorted) < 2 {
+		return stats
+	}
+
+	intervals := make([]float64, 0, len(sorted)-1)
+	sum := 0.0
+	for i := 1; i < len(sorted); i++ {
+		interval := float64(sorted[i].Sub(sorted[i-1]))
+		intervals = append(intervals, interval)
+		sum += interval
+	}
+	mean := sum / float64(len(intervals))
+	squares := 0.0
+	for _, interval := range intervals {
+		squares += (interval - mean) * (interval - mean)
+	}
+	stdDev := math.Sqrt(squares / float64(len(intervals)))
+	stats.Mean, stats.StdDev = time.Duration(mean), time.Duration(stdDev)
+	stats.Shortest, stats.Longest = time.Duration(slices.Min(intervals)), time.Duration(slices.Max(intervals))
+	if mean > 0 {
+		stats.Variation = stdDev / mean
+	}
+	return stats
+}
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+// DefaultCommitMessageCommits is how many recent commits CommitMessageChecker reads.
+const DefaultCommitMessageCommits = 10
+
+// MaxCommitMessageCommits caps the commits CommitMessageChecker reads, bounding it to one
+// request per repository.
+const MaxCommitMessageCommits = 30
+
+// minCommitMessages is the fewest commits CommitMessageChecker judges, so a repository pushed
+// once is not flagged for its lone "Initial commit".
+const minCommitMessages = 3
+
+// boilerplateCommitMessage matches the one-line subjects generated and bulk-pushed repositories
+// are committed with.
+var boilerplateCommitMessage = regexp.MustCompile(`^(initial commit|first commit|init|initial upload|add files via upload|update readme(\.md)?|update|.*\bai[- ]generated\b.*)$`)
+
+// CommitMessageChecker flags repositories whose recent commits look scripted: every message is
+// the same, or every one is a boilerplate one-liner such as "Initial commit" or "Added
+// AI-generated code". A commit matching one of the configured markers flags the repository on
+// its own. It costs one request per repository.
+type CommitMessageChecker struct {
+	Client github.GitHubAPI
+	// MaxCommits caps the commits read. Zero uses DefaultCommitMessageCommits, and it is never
+	// more than MaxCommitMessageCommits.
+	MaxCommits int
+	// Markers are matched against each full commit message.
+	Markers []KeywordRule
+}
+
+// Check evaluates a repository's recent commit messages.
+func (cc *CommitMessageChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := cc.Run(ctx, repo)
+	return result.Flagged, err
+}
+
+// Run evaluates a repository's recent commit messages on its default branch.
+func (cc *CommitMessageChecker) Run(ctx context.Context, repo models.RepoData) (models.CheckerResult, error) {
+	result := models.CheckerResult{Name: "CommitMessageChecker", Severity: models.SeverityMedium}
+	limit := cc.MaxCommits
+	if limit <= 0 {
+		limit = DefaultCommitMessageCommits
+	}
+	commits, err := cc.Client.GetRepoCommits(ctx, repo.Owner, repo.Name, "", min(limit, MaxCommitMessageCommits))
+	if err != nil {
+		return result, err
+	}
+	if matches := cc.markerMatches(commits); len(matches) > 0 {
+		result.Flagged = true
+		result.Evidence = fmt.Sprintf("Recent commits match %s.", strings.Join(matches, "; "))
+		return result, nil
+	}
+	if len(commits) < minCommitMessages {
+		return result, nil
+	}
+
+	distinct := map[string]bool{}
+	boilerplate := 0
+	for _, commit := range commits {
+		subject := commitSubject(commit.Message)
+		distinct[subject] = true
+		if boilerplateCommitMessage.MatchString(subject) {
+			boilerplate++
+		}
+	}
+	first := firstLine(commits[0].Message)
+	switch {
+	case len(distinct) == 1:
+		result.Flagged = true
+		result.Evidence = fmt.Sprintf("All %d recent commits share the message %q.", len(commits), first)
+	case boilerplate == len(commits):
+		result.Flagged = true
+		result.Evidence = fmt.Sprintf("All %d recent commits are boilerplate one-liners, such as %q.", len(commits), first)
+	case boilerplate > 0:
+		result.Evidence = fmt.Sprintf("%d of %d recent commits are boilerplate one-liners.", boilerplate, len(commits))
+	}
+	return result, nil
+}
+
+// markerMatches describes each marker matched by a commit, with the first commit matching it.
+func (cc *CommitMessageChecker) markerMatches(commits []models.Commit) []string {
+	var matches []string
+	for _, marker := range cc.Markers {
+		for _, commit := range commits {
+			found, missing := splitPhrases(strings.ToLower(commit.Message), marker.Phrases)
+			if len(found) > 0 && len(missing) == 0 {
+				matches = append(matches, fmt.Sprintf("marker %s in %q", marker.Name, firstLine(commit.Message)))
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// firstLine returns the trimmed first line of a commit message.
+func firstLine(message string) string {
+	return strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+}
+
+// commitSubject normalizes the first line of a commit message for comparison.
+func commitSubject(message string) string {
+	subject := strings.SplitN(message, "\n", 2)[0]
+	return strings.TrimRight(strings.ToLower(strings.Join(strings.Fields(subject), " ")), ".!")
+}
@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+// Package ecosystems recognized in package lists and manifests.
+const (
+	EcosystemNPM  = "npm"
+	EcosystemPyPI = "pypi"
+)
+
+// maxManifests caps the manifests DependencyChecker fetches per repository, one request each.
+const maxManifests = 5
+
+// manifestEcosystems maps the manifest file names DependencyChecker reads to their ecosystem.
+var manifestEcosystems = map[string]string{
+	"package.json":     EcosystemNPM,
+	"requirements.txt": EcosystemPyPI,
+}
+
+var pypiNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// Dependency is a package a manifest declares.
+type Dependency struct {
+	Ecosystem string
+	Name      string
+}
+
+// PackageList is a set of known-malicious packages keyed by ecosystem and normalized name.
+type PackageList map[string]map[string]bool
+
+// ParsePackageList reads one "ecosystem:name" entry per line, such as "npm:event-stream" or
+// "pypi:colourama". Blank lines and lines starting with # are skipped.
+func ParsePackageList(data string) (PackageList, error) {
+	list := PackageList{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		ecosystem, name, ok := strings.Cut(entry, ":")
+		ecosystem = strings.ToLower(strings.TrimSpace(ecosystem))
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("package list line %d: expected ecosystem:name, got %q", line, entry)
+		}
+		if ecosystem != EcosystemNPM && ecosystem != EcosystemPyPI {
+			return nil, fmt.Errorf("package list line %d: unknown ecosystem %q", line, ecosystem)
+		}
+		if list[ecosystem] == nil {
+			list[ecosystem] = map[string]bool{}
+		}
+		list[ecosystem][normalizePackageName(ecosystem, name)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading package list: %w", err)
+	}
+	return list, nil
+}
+
+// Contains reports whether dep is on the list.
+func (l PackageList) Contains(dep Dependency) bool {
+	return l[dep.Ecosystem][normalizePackageName(dep.Ecosystem, dep.Name)]
+}
+
+// normalizePackageName folds the spellings a registry treats as one package: npm names are
+// case-insensitive, and PyPI also treats runs of -, _, and . as one separator.
+func normalizePackageName(ecosystem, name string) string {
+	name = strings.ToLower(name)
+	if ecosystem == EcosystemPyPI {
+		name = pypiNameSeparators.ReplaceAllString(name, "-")
+	}
+	return name
+}
+
+// ParseManifest returns the dependencies a package.json or requirements.txt declares, judged
+// by the file's base name. Other files declare none.
+func ParseManifest(filename, content string) ([]Dependency, error) {
+	switch path.Base(filename) {
+	case "package.json":
+		return parsePackageJSON(content)
+	case "requirements.txt":
+		return parseRequirements(content), nil
+	default:
+		return nil, nil
+	}
+}
+
+func parsePackageJSON(content string) ([]Dependency, error) {
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return nil, fmt.Errorf("decoding package.json: %w", err)
+	}
+	var deps []Dependency
+	for _, section := range []string{"dependencies", "devDependencies", "optionalDependencies", "peerDependencies"} {
+		var declared map[string]interface{}
+		if raw, ok := manifest[section]; !ok || json.Unmarshal(raw, &declared) != nil {
+			continue
+		}
+		names := make([]string, 0, len(declared))
+		for name := range declared {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			deps = append(deps, Dependency{Ecosystem: EcosystemNPM, Name: name})
+		}
+	}
+	return deps, nil
+}
+
+// parseRequirements reads requirement lines, skipping options such as -r and -e, URLs, and
+// comments. The name ends at the first extra, version specifier, marker, or space.
+func parseRequirements(content string) []Dependency {
+	var deps []Dependency
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+		if end := strings.IndexAny(line, "[<>=!~;@ \t"); end >= 0 {
+			line = line[:end]
+		}
+		if line != "" {
+			deps = append(deps, Dependency{Ecosystem: EcosystemPyPI, Name: line})
+		}
+	}
+	return deps
+}
+
+// DependencyChecker flags repositories whose package.json or requirements.txt declares a
+// package on a known-malicious list. It fetches up to maxManifests manifests from the tree,
+// skipping vendored node_modules.
+type DependencyChecker struct {
+	Client   github.GitHubAPI
+	Packages PackageList
+}
+
+// Check evaluates a repository's dependency manifests.
+func (dc *DependencyChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := dc.Run(ctx, repo)
+	return result.Flagged, err
+}
+
+// Run evaluates a repository's dependency manifests. Manifests that fail to parse are skipped.
+func (dc *DependencyChecker) Run(ctx context.Context, repo models.RepoData) (models.CheckerResult, error) {
+	result := models.CheckerResult{Name: "DependencyChecker", Severity: models.SeverityHigh}
+	var matches []string
+	fetched := 0
+	for _, entry := range repo.TreeEntries {
+		if manifestEcosystems[path.Base(entry)] == "" || strings.Contains("/"+entry, "/node_modules/") {
+			continue
+		}
+		if fetched == maxManifests {
+			break
+		}
+		fetched++
+		content, err := dc.Client.GetFileContent(ctx, repo.Owner, repo.Name, entry, "")
+		if err != nil {
+			return result, err
+		}
+		deps, err := ParseManifest(entry, content)
+		if err != nil {
+			continue
+		}
+		for _, dep := range deps {
+			if dc.Packages.Contains(dep) {
+				matches = append(matches, fmt.Sprintf("%s:%s in %s", dep.Ecosystem, dep.Name, entry))
+			}
+		}
+	}
+	if len(matches) > 0 {
+		result.Flagged = true
+		result.Evidence = fmt.Sprintf("Declares %s on the known-malicious list: %s.", pluralize(len(matches), "package", "packages"), strings.Join(matches, ", "))
+	}
+	return result, nil
+}
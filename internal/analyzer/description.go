@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"context"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+// DefaultDescriptionRules are the description markers checked when none are configured. They
+// match the way lures advertise themselves: cracked software, game cheats, free downloads of
+// paid tools, and crypto airdrops to claim.
+var DefaultDescriptionRules = []KeywordRule{
+	{Name: "CrackedSoftwareDescription", Phrases: []string{"cracked"}},
+	{Name: "KeygenDescription", Phrases: []string{"keygen"}},
+	{Name: "FreeDownloadDescription", Phrases: []string{"free download"}},
+	{Name: "AimbotDescription", Phrases: []string{"aimbot"}},
+	{Name: "UndetectedCheatDescription", Phrases: []string{"undetected", "cheat"}},
+	{Name: "AirdropClaimDescription", Phrases: []string{"airdrop", "claim"}, Category: "Spam Behavior"},
+}
+
+// DescriptionChecker flags repositories whose description matches a keyword rule. The
+// description comes with the search result, so the check costs no requests and runs for every
+// repository, including those too small for file analysis.
+type DescriptionChecker struct {
+	// Rules overrides DefaultDescriptionRules when non-nil.
+	Rules []KeywordRule
+}
+
+// Check reports whether any rule matches repo's description.
+func (dc *DescriptionChecker) Check(_ context.Context, repo models.RepoData) (bool, error) {
+	return len(dc.Matches(repo)) > 0, nil
+}
+
+// Matches returns a flagged result named after each rule whose phrases all appear in the
+// description.
+func (dc *DescriptionChecker) Matches(repo models.RepoData) []models.HeuristicResult {
+	if repo.Description == "" {
+		return nil
+	}
+	rules := dc.Rules
+	if rules == nil {
+		rules = DefaultDescriptionRules
+	}
+	return matchKeywordRules("Description", repo.Description, rules)
+}
@@ -0,0 +1,46 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+// DefaultHighSeverityCategories are the flag categories where one flag is enough evidence.
+var DefaultHighSeverityCategories = []string{"Malware", "Phishing"}
+
+// EvidencePolicy is the final gate on what is recorded as a finding. Flags pass when at least
+// MinHeuristics distinct heuristics fired, or when any of them is in a high-severity category.
+// The zero value passes any single flag.
+type EvidencePolicy struct {
+	MinHeuristics int
+	// HighSeverityCategories defaults to DefaultHighSeverityCategories when nil.
+	HighSeverityCategories []string
+}
+
+// Passes reports whether the flagged results carry enough evidence. Results that neither
+// flagged nor scored are ignored, so no evidence never passes.
+func (p EvidencePolicy) Passes(results []models.HeuristicResult) bool {
+	highSeverity := p.HighSeverityCategories
+	if highSeverity == nil {
+		highSeverity = DefaultHighSeverityCategories
+	}
+	fired := map[string]bool{}
+	for _, result := range results {
+		if !result.Flag && result.Score <= 0 {
+			continue
+		}
+		for _, category := range highSeverity {
+			if strings.EqualFold(result.Category, category) {
+				return true
+			}
+		}
+		fired[result.Name] = true
+	}
+	return len(fired) > 0 && len(fired) >= p.MinHeuristics
+}
+
+// PassesEvidence applies the analyzer's evidence policy to flagged results.
+func (a *Analyzer) PassesEvidence(results []models.HeuristicResult) bool {
+	return a.evidence.Passes(results)
+}
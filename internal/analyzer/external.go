@@ -0,0 +1,180 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// DefaultExternalCommandTimeout bounds a single external command run when no timeout is configured.
+	DefaultExternalCommandTimeout = 10 * time.Second
+	// maxExternalEvidence caps how much command output is kept as flag evidence.
+	maxExternalEvidence = 512
+)
+
+// ExternalCommand runs an operator-supplied detection script. The entity under
+// analysis is written to stdin as JSON; exit status 0 means clean, 1 means
+// flagged, and anything else is treated as a checker error. Stdout is kept as
+// evidence for the resulting flag.
+type ExternalCommand struct {
+	Path    string
+	Args    []string
+	Timeout time.Duration
+}
+
+// Run executes the command with payload encoded on stdin and returns its verdict and trimmed stdout.
+func (c ExternalCommand) Run(ctx context.Context, payload any) (bool, string, error) {
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return false, "", fmt.Errorf("encoding external command input: %w", err)
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultExternalCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	// Child processes can keep stdout open after the command is killed; don't wait on them.
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	output := truncateEvidence(strings.TrimSpace(stdout.String()))
+	if ctx.Err() == context.DeadlineExceeded {
+		return false, output, fmt.Errorf("external command %s timed out after %s", c.Path, timeout)
+	}
+	if err == nil {
+		return false, output, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, output, nil
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return false, output, fmt.Errorf("running external command %s: %w: %s", c.Path, err, truncateEvidence(msg))
+	}
+	return false, output, fmt.Errorf("running external command %s: %w", c.Path, err)
+}
+
+type externalRepoPayload struct {
+	Kind           string   `json:"kind"`
+	Owner          string   `json:"owner"`
+	Name           string   `json:"name"`
+	Readme         string   `json:"readme"`
+	TreeEntries    []string `json:"tree_entries"`
+	DiskUsage      int      `json:"disk_usage"`
+	StargazerCount int      `json:"stargazer_count"`
+}
+
+type externalUserRepo struct {
+	Name           string `json:"name"`
+	DiskUsage      int    `json:"disk_usage"`
+	StargazerCount int    `json:"stargazer_count"`
+}
+
+type externalUserPayload struct {
+	Kind          string             `json:"kind"`
+	Username      string             `json:"username"`
+	CreatedAt     time.Time          `json:"created_at"`
+	Contributions int                `json:"contributions"`
+	Repositories  []externalUserRepo `json:"repositories"`
+}
+
+// ExternalRepoChecker runs an external command against repository data.
+type ExternalRepoChecker struct {
+	Command ExternalCommand
+}
+
+// Check reports whether the external command flagged the repository.
+func (c *ExternalRepoChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := c.Evaluate(ctx, repo)
+	return result.Flag, err
+}
+
+// Evaluate runs the external command and returns its verdict with stdout as evidence.
+func (c *ExternalRepoChecker) Evaluate(ctx context.Context, repo models.RepoData) (models.HeuristicResult, error) {
+	flagged, output, err := c.Command.Run(ctx, externalRepoPayload{
+		Kind:           "repo",
+		Owner:          repo.Owner,
+		Name:           repo.Name,
+		Readme:         repo.Readme,
+		TreeEntries:    repo.TreeEntries,
+		DiskUsage:      repo.DiskUsage,
+		StargazerCount: repo.StargazerCount,
+	})
+	return externalResult(c.Command, flagged, output), err
+}
+
+// ExternalUserHeuristic runs an external command against user data.
+type ExternalUserHeuristic struct {
+	Command ExternalCommand
+}
+
+// Evaluate runs the external command for a user. Command failures leave the user unflagged.
+func (h *ExternalUserHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
+	return h.EvaluateContext(context.Background(), data, repos)
+}
+
+// EvaluateContext runs the external command for a user, killing it when ctx is done. Command
+// failures leave the user unflagged.
+func (h *ExternalUserHeuristic) EvaluateContext(ctx context.Context, data models.UserData, repos []models.RepoData) models.HeuristicResult {
+	payload := externalUserPayload{
+		Kind:          "user",
+		Username:      data.Username,
+		CreatedAt:     data.CreatedAt,
+		Contributions: data.Contributions,
+		Repositories:  make([]externalUserRepo, 0, len(repos)),
+	}
+	for _, repo := range repos {
+		payload.Repositories = append(payload.Repositories, externalUserRepo{
+			Name:           repo.Name,
+			DiskUsage:      repo.DiskUsage,
+			StargazerCount: repo.StargazerCount,
+		})
+	}
+
+	flagged, output, err := h.Command.Run(ctx, payload)
+	result := externalResult(h.Command, flagged, output)
+	if err != nil {
+		result.Description = fmt.Sprintf("External command failed: %v", err)
+	}
+	return result
+}
+
+func externalResult(command ExternalCommand, flagged bool, output string) models.HeuristicResult {
+	description := fmt.Sprintf("External command %s did not flag this entity.", command.Path)
+	if flagged {
+		description = fmt.Sprintf("External command %s flagged this entity.", command.Path)
+		if output != "" {
+			description = fmt.Sprintf("External command %s flagged this entity: %s", command.Path, output)
+		}
+	}
+	return models.HeuristicResult{
+		Category:    "Other Suspicious Patterns",
+		Flag:        flagged,
+		Name:        "ExternalCommandHeuristic",
+		Description: description,
+	}
+}
+
+func truncateEvidence(output string) string {
+	if len(output) <= maxExternalEvidence {
+		return output
+	}
+	return output[:maxExternalEvidence] + "..."
+}
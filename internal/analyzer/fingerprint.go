@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+// DefaultDuplicateContentMinRepos is how many repositories of other owners must share a
+// fingerprint before the duplicate content heuristic fires.
+const DefaultDuplicateContentMinRepos = 2
+
+// boilerplateFiles are the files GitHub can create with a new repository. A tree holding only
+// these is treated as blank and gets no fingerprint.
+var boilerplateFiles = map[string]bool{
+	"readme.md": true, "license": true, ".gitignore": true, ".gitattributes": true,
+}
+
+// FingerprintLookup finds stored repositories ("owner/name") with a content fingerprint.
+type FingerprintLookup interface {
+	ReposWithFingerprint(fingerprint string) ([]string, error)
+}
+
+// Fingerprint hashes a repository's sorted tree paths together with a hash of its README, so
+// byte-identical content pushed under different accounts maps to the same value. Blank
+// repositories return an empty fingerprint; otherwise every empty repo would form one cluster.
+func Fingerprint(repo models.RepoData) string {
+	paths := make([]string, 0, len(repo.TreeEntries))
+	blank := true
+	for _, entry := range repo.TreeEntries {
+		paths = append(paths, entry)
+		if !boilerplateFiles[strings.ToLower(entry)] {
+			blank = false
+		}
+	}
+	if blank {
+		return ""
+	}
+	sort.Strings(paths)
+
+	readme := sha256.Sum256([]byte(repo.Readme))
+	sum := sha256.New()
+	sum.Write([]byte(strings.Join(paths, "\n")))
+	sum.Write([]byte{0})
+	sum.Write([]byte(hex.EncodeToString(readme[:])))
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// ContentClusterID names the cluster of repositories sharing fingerprint.
+func ContentClusterID(fingerprint string) string {
+	if len(fingerprint) > 12 {
+		fingerprint = fingerprint[:12]
+	}
+	return "content-" + fingerprint
+}
+
+// DuplicateContentResult is the flag raised for a repository whose content matches repos of
+// other owners.
+func DuplicateContentResult(cluster string, others []string) models.HeuristicResult {
+	return models.HeuristicResult{
+		Category: "Mass Repository Creation",
+		Flag:     true,
+		Name:     "DuplicateContentHeuristic",
+		Description: fmt.Sprintf("Identical tree and README to %s owned by other accounts (cluster %s): %s.",
+			pluralize(len(others), "repository", "repositories"), cluster, strings.Join(others, ", ")),
+	}
+}
+
+// CheckDuplicateContent looks up stored repositories with repo's fingerprint and flags repo
+// when at least the configured number of them belong to other owners. The returned members
+// are those other repositories.
+func (a *Analyzer) CheckDuplicateContent(repo models.RepoData, fingerprint string) (models.HeuristicResult, []string, error) {
+	if a.fingerprints == nil || fingerprint == "" {
+		return models.HeuristicResult{}, nil, nil
+	}
+	matches, err := a.fingerprints.ReposWithFingerprint(fingerprint)
+	if err != nil {
+		return models.HeuristicResult{}, nil, err
+	}
+	var others []string
+	for _, match := range matches {
+		owner, _, _ := strings.Cut(match, "/")
+		if !strings.EqualFold(owner, repo.Owner) {
+			others = append(others, match)
+		}
+	}
+	if len(others) < a.duplicateMinRepos {
+		return models.HeuristicResult{}, nil, nil
+	}
+	return DuplicateContentResult(ContentClusterID(fingerprint), others), others, nil
+}
@@ -16,14 +16,110 @@ type UserHeuristic interface {
 	Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult
 }
 
+// ContextUserHeuristic is a UserHeuristic that blocks, such as on an external command, and
+// stops when the analysis context is done.
+type ContextUserHeuristic interface {
+	UserHeuristic
+	EvaluateContext(ctx context.Context, data models.UserData, repos []models.RepoData) models.HeuristicResult
+}
+
 var generatedRepoNamePattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*(?:[-_][A-Za-z0-9]+)*)[-_](\d{3,})$`)
 
+var digitRunPattern = regexp.MustCompile(`\d+`)
+
+var camelCaseNumberPattern = regexp.MustCompile(`^([A-Z][a-zA-Z]+)-\d{3,4}$`)
+
+const (
+	// DefaultTemplateUniformityThreshold is the share of an owner's repositories that must follow one numbered
+	// naming template before TemplatedNamingHeuristic flags the owner.
+	DefaultTemplateUniformityThreshold = 0.8
+	// minTemplatedRepos is the smallest number of template matches worth flagging.
+	minTemplatedRepos = 5
+	// DefaultCommitSampleSize is how many of an owner's non-empty repositories have their commit
+	// history sampled.
+	DefaultCommitSampleSize = 5
+	// singleCommitThreshold is the share of sampled repositories that must have a single commit
+	// before SingleCommitHeuristic flags the owner.
+	singleCommitThreshold = 0.9
+	// minCommitSample is the smallest sample worth judging.
+	minCommitSample = 3
+)
+
+// DefaultUsernamePatterns match logins made of a short lowercase stem and two to four digits,
+// such as mahas629.
+var DefaultUsernamePatterns = []string{`^[a-z]{3,12}[0-9]{2,4}$`}
+
+// DefaultCamelCaseNumberThreshold is how many of an owner's repositories may be named like
+// WeatherForecast-1409 before CamelCaseNumberHeuristic flags the owner.
+const DefaultCamelCaseNumberThreshold = 4
+
+const (
+	// minCamelCaseNumberStems is how many different names must carry a number suffix, so an owner
+	// numbering one project's versions is not flagged.
+	minCamelCaseNumberStems = 3
+	// maxListedRepoNames bounds the repository names quoted in a heuristic description.
+	maxListedRepoNames = 10
+)
+
+// usernamePatternMaxContributions is the most contributions a user matching a username
+// pattern can have and still be flagged.
+const usernamePatternMaxContributions = 5
+
+// usernamePatternNearMissContributions is the most contributions a user matching a username
+// pattern can have and still score a near miss.
+const usernamePatternNearMissContributions = 20
+
+// nearMissScore is what a heuristic scores a user who matches most of its pattern but not all
+// of it, without flagging. One near miss stays below the default user_score_threshold, while
+// two, or a near miss and a flag, pass it.
+const nearMissScore = 0.5
+
+const (
+	// followRatioMinFollowing is how many accounts a user must follow before
+	// FollowRatioHeuristic considers it a mass follower.
+	followRatioMinFollowing = 200
+	// followRatioMaxFollowers is how many followers a mass follower may have and still be flagged.
+	followRatioMaxFollowers = 3
+	// followRatioMaxAge is the account age under which FollowRatioHeuristic flags a mass follower.
+	followRatioMaxAge = 30 * 24 * time.Hour
+	// followRatioNearMissFollowing is how many accounts a young user with almost no followers
+	// must follow for FollowRatioHeuristic to score a near miss.
+	followRatioNearMissFollowing = 100
+)
+
+const (
+	// DefaultForkFarmMaxAge is the account age under which ForkFarmHeuristic flags a fork farm.
+	DefaultForkFarmMaxAge = 90 * 24 * time.Hour
+	// forkFarmMinRepos is how many repositories a fork farm must own.
+	forkFarmMinRepos = 10
+	// forkFarmMinForkShare is the share of repositories that must be forks, exclusive.
+	forkFarmMinForkShare = 0.9
+	// forkFarmMaxContributions is the most contributions a fork farm may have and still be flagged.
+	forkFarmMaxContributions = 5
+)
+
+// CompileUsernamePatterns compiles the regular expressions of UsernamePatternHeuristic.
+func CompileUsernamePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compiling username pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
 // OriginalHeuristic is the original heuristic for detecting suspicious users
-type OriginalHeuristic struct{}
+type OriginalHeuristic struct {
+	// Thresholds sets what counts as an empty repository. Zero fields use the defaults.
+	Thresholds RepoThresholds
+}
 
 // Evaluate evaluates the original heuristic
 func (h *OriginalHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
-	totalStars, emptyCount, _ := computeRepoMetrics(repos)
+	totalStars, emptyCount, _ := computeRepoMetrics(repos, h.Thresholds)
 	flag := totalStars >= 10 && emptyCount >= 20
 	return models.HeuristicResult{
 		Category:    "Mass Repository Creation",
@@ -33,13 +129,17 @@ func (h *OriginalHeuristic) Evaluate(data models.UserData, repos []models.RepoDa
 	}
 }
 
-// NewHeuristic is a newer heuristic for detecting suspicious users
-type NewHeuristic struct{}
+// NewHeuristic is a newer heuristic for detecting suspicious users. Organizations have no
+// contributions of their own, so it never flags them.
+type NewHeuristic struct {
+	// Thresholds sets what counts as an empty repository. Zero fields use the defaults.
+	Thresholds RepoThresholds
+}
 
 // Evaluate evaluates the new heuristic
 func (h *NewHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
-	_, _, suspiciousEmptyCount := computeRepoMetrics(repos)
-	flag := suspiciousEmptyCount >= 5 && data.Contributions <= 5
+	_, _, suspiciousEmptyCount := computeRepoMetrics(repos, h.Thresholds)
+	flag := suspiciousEmptyCount >= 5 && data.Contributions <= 5 && !data.IsOrganization()
 	return models.HeuristicResult{
 		Category:    "Automated Activity",
 		Flag:        flag,
@@ -48,13 +148,32 @@ func (h *NewHeuristic) Evaluate(data models.UserData, repos []models.RepoData) m
 	}
 }
 
+// orgRecentScore is what RecentHeuristic scores a recent organization with enough stars. New
+// organizations are routinely set up for launches that gather stars quickly, so one is only
+// weak evidence and does not flag on its own.
+const orgRecentScore = 0.5
+
 // RecentHeuristic is a heuristic for detecting suspicious recent users
-type RecentHeuristic struct{}
+type RecentHeuristic struct {
+	// Thresholds sets what counts as an empty repository. Zero fields use the defaults.
+	Thresholds RepoThresholds
+}
 
 // Evaluate evaluates the recent user heuristic
 func (h *RecentHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
-	totalStars, _, _ := computeRepoMetrics(repos)
+	totalStars, _, _ := computeRepoMetrics(repos, h.Thresholds)
 	flag := time.Since(data.CreatedAt) < (10*24*time.Hour) && totalStars >= 10
+	if data.IsOrganization() {
+		result := models.HeuristicResult{
+			Category:    "Spam Behavior",
+			Name:        "RecentHeuristic",
+			Description: "Organization is recent and has gathered enough stars.",
+		}
+		if flag {
+			result.Score = orgRecentScore
+		}
+		return result
+	}
 	return models.HeuristicResult{
 		Category:    "Spam Behavior",
 		Flag:        flag,
@@ -84,55 +203,439 @@ func (h *GeneratedPortfolioHeuristic) Evaluate(data models.UserData, repos []mod
 	}
 }
 
+// TemplatedNamingHeuristic detects owners whose repositories mostly follow one sequential naming template,
+// such as Project-1, Project-2, Project-3.
+type TemplatedNamingHeuristic struct {
+	// Threshold is the minimum fraction of repositories sharing the dominant template. Zero uses the default.
+	Threshold float64
+}
+
+// Evaluate evaluates the templated naming heuristic.
+func (h *TemplatedNamingHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
+	threshold := h.Threshold
+	if threshold <= 0 {
+		threshold = DefaultTemplateUniformityThreshold
+	}
+
+	template, matched := dominantNameTemplate(repos)
+	uniformity := templateUniformity(repos)
+	flag := matched >= minTemplatedRepos && uniformity >= threshold
+	description := "User's repositories follow a shared sequential naming template."
+	if flag {
+		description = fmt.Sprintf("%d of %d repositories (%.0f%%) follow naming template %q.",
+			matched, len(repos), uniformity*100, template)
+	}
+
+	return models.HeuristicResult{
+		Category:    "Mass Repository Creation",
+		Flag:        flag,
+		Name:        "TemplatedNamingHeuristic",
+		Description: description,
+	}
+}
+
+// SingleCommitHeuristic detects owners whose sampled repositories were pushed once and never
+// touched again, a sign of generated content rather than real development.
+type SingleCommitHeuristic struct{}
+
+// Evaluate evaluates the single commit heuristic.
+func (h *SingleCommitHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
+	fraction := singleCommitFraction(data)
+	flag := data.CommitSampled >= minCommitSample && fraction >= singleCommitThreshold
+	description := "User's repositories have no development after the initial import."
+	if flag {
+		description = fmt.Sprintf("%d of %d sampled repositories have no commits after the initial import.",
+			data.SingleCommitRepos, data.CommitSampled)
+	}
+
+	return models.HeuristicResult{
+		Category:    "Automated Activity",
+		Flag:        flag,
+		Name:        "SingleCommitHeuristic",
+		Description: description,
+	}
+}
+
+// CamelCaseNumberHeuristic detects owners with many repositories named by a CamelCase phrase and a
+// random number, such as WeatherForecast-1409 and ImageProcessor-4888.
+type CamelCaseNumberHeuristic struct {
+	// Threshold is how many matching repositories are tolerated. Zero uses the default.
+	Threshold int
+}
+
+// Evaluate evaluates the CamelCase number heuristic.
+func (h *CamelCaseNumberHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
+	threshold := h.Threshold
+	if threshold <= 0 {
+		threshold = DefaultCamelCaseNumberThreshold
+	}
+
+	var matched []string
+	stems := map[string]bool{}
+	for _, repo := range repos {
+		match := camelCaseNumberPattern.FindStringSubmatch(repo.Name)
+		if match == nil {
+			continue
+		}
+		matched = append(matched, repo.Name)
+		stems[match[1]] = true
+	}
+	flag := len(matched) > threshold && len(stems) >= minCamelCaseNumberStems
+	description := "User has many repositories named by a CamelCase phrase and a number."
+	if flag {
+		listed := strings.Join(matched[:min(len(matched), maxListedRepoNames)], ", ")
+		if len(matched) > maxListedRepoNames {
+			listed += fmt.Sprintf(", and %d more", len(matched)-maxListedRepoNames)
+		}
+		description = fmt.Sprintf("%d repositories with %d different names are named by a CamelCase phrase and a number: %s.",
+			len(matched), len(stems), listed)
+	}
+
+	return models.HeuristicResult{
+		Category:    "Mass Repository Creation",
+		Flag:        flag,
+		Name:        "CamelCaseNumberHeuristic",
+		Description: description,
+	}
+}
+
+// UsernamePatternHeuristic detects low-activity users whose login follows a naming scheme
+// seen on throwaway accounts. A matching login with up to 20 contributions scores a near miss
+// without flagging. Organizations, which have no contributions of their own, are not judged.
+type UsernamePatternHeuristic struct {
+	// Patterns are matched against the login. Nil uses DefaultUsernamePatterns.
+	Patterns []*regexp.Regexp
+}
+
+var defaultUsernamePatterns, _ = CompileUsernamePatterns(DefaultUsernamePatterns)
+
+// Evaluate evaluates the username pattern heuristic.
+func (h *UsernamePatternHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
+	patterns := h.Patterns
+	if patterns == nil {
+		patterns = defaultUsernamePatterns
+	}
+	result := models.HeuristicResult{
+		Category:    "Automated Activity",
+		Name:        "UsernamePatternHeuristic",
+		Description: "User's login follows a throwaway naming pattern and the user has few contributions.",
+	}
+	if data.Username == "" || data.Contributions > usernamePatternNearMissContributions || data.IsOrganization() {
+		return result
+	}
+	for _, pattern := range patterns {
+		if !pattern.MatchString(data.Username) {
+			continue
+		}
+		result.Description = fmt.Sprintf("Login %q matches username pattern %q and the user has %d contributions.",
+			data.Username, pattern.String(), data.Contributions)
+		if data.Contributions <= usernamePatternMaxContributions {
+			result.Flag = true
+		} else {
+			result.Score = nearMissScore
+		}
+		break
+	}
+	return result
+}
+
+// FollowRatioHeuristic detects young accounts that follow many others but are followed by almost
+// no one, as accounts created to follow and star in bulk are. Following more than 100 accounts
+// but not more than 200 scores a near miss without flagging.
+type FollowRatioHeuristic struct{}
+
+// Evaluate evaluates the follow ratio heuristic.
+func (h *FollowRatioHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
+	result := models.HeuristicResult{
+		Category:    "Automated Activity",
+		Name:        "FollowRatioHeuristic",
+		Description: "User is a new account following many others with almost no followers.",
+	}
+	if data.Following <= followRatioNearMissFollowing || data.Followers >= followRatioMaxFollowers ||
+		data.CreatedAt.IsZero() || time.Since(data.CreatedAt) >= followRatioMaxAge {
+		return result
+	}
+	result.Description = fmt.Sprintf("Account created %s ago follows %d accounts and has %d followers.",
+		formatAge(time.Since(data.CreatedAt)), data.Following, data.Followers)
+	if data.Following > followRatioMinFollowing {
+		result.Flag = true
+	} else {
+		result.Score = nearMissScore
+	}
+	return result
+}
+
+// ForkFarmHeuristic detects young accounts made almost entirely of forks with next to no
+// contributions, as boost accounts are that fork dozens of projects to look active. It flags
+// accounts younger than MaxAge with at least ten repositories, over 90% of them forks, and at
+// most five contributions. Forks last pushed before the account existed are counted in the
+// description, since the account cannot have changed them.
+type ForkFarmHeuristic struct {
+	// MaxAge overrides DefaultForkFarmMaxAge when positive.
+	MaxAge time.Duration
+}
+
+// Evaluate evaluates the fork farm heuristic.
+func (h *ForkFarmHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
+	maxAge := h.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultForkFarmMaxAge
+	}
+	forks, untouched := 0, 0
+	for _, repo := range repos {
+		if !repo.Fork {
+			continue
+		}
+		forks++
+		if !repo.PushedAt.IsZero() && repo.PushedAt.Before(data.CreatedAt) {
+			untouched++
+		}
+	}
+	flag := len(repos) >= forkFarmMinRepos && float64(forks) > forkFarmMinForkShare*float64(len(repos)) &&
+		data.Contributions <= forkFarmMaxContributions && !data.CreatedAt.IsZero() && time.Since(data.CreatedAt) < maxAge
+	description := "User is a new account whose repositories are almost all forks, with almost no contributions."
+	if flag {
+		description = fmt.Sprintf("Account created %s ago owns %s, %d of them forks (%d last pushed before the account existed), and has %s.",
+			formatAge(time.Since(data.CreatedAt)), pluralize(len(repos), "repository", "repositories"), forks, untouched,
+			pluralize(data.Contributions, "contribution", "contributions"))
+	}
+
+	return models.HeuristicResult{
+		Category:    "Automated Activity",
+		Flag:        flag,
+		Name:        "ForkFarmHeuristic",
+		Description: description,
+	}
+}
+
+// ProfileSpamHeuristic detects accounts whose bio, blog, Twitter handle, or profile README
+// contains one of SuspiciousKeywords, such as crypto giveaway language or a shortened URL.
+type ProfileSpamHeuristic struct{}
+
+// Evaluate evaluates the profile spam heuristic.
+func (h *ProfileSpamHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
+	fields := []struct{ name, text string }{
+		{"bio", data.Bio},
+		{"blog", data.Blog},
+		{"Twitter handle", data.TwitterUsername},
+		{"profile README", data.ProfileReadme},
+	}
+	var matches []string
+	for _, field := range fields {
+		if keyword := firstSuspiciousKeyword(field.text); keyword != "" {
+			matches = append(matches, fmt.Sprintf("%s contains %q", field.name, keyword))
+		}
+	}
+	description := "User profile contains spam or scam keywords."
+	if len(matches) > 0 {
+		description = "Profile " + strings.Join(matches, ", ") + "."
+	}
+
+	return models.HeuristicResult{
+		Category:    "Spam Behavior",
+		Flag:        len(matches) > 0,
+		Name:        "ProfileSpamHeuristic",
+		Description: description,
+	}
+}
+
+// singleCommitFraction is the share of sampled repositories with at most one commit.
+func singleCommitFraction(data models.UserData) float64 {
+	if data.CommitSampled == 0 {
+		return 0
+	}
+	return float64(data.SingleCommitRepos) / float64(data.CommitSampled)
+}
+
+// forkCount is how many of repos are forks.
+func forkCount(repos []models.RepoData) int {
+	forks := 0
+	for _, repo := range repos {
+		if repo.Fork {
+			forks++
+		}
+	}
+	return forks
+}
+
 // RepoChecker represents a checker that can be applied to repository data
 type RepoChecker interface {
 	Check(ctx context.Context, repo models.RepoData) (bool, error)
 }
 
+// EvidenceChecker is a RepoChecker that also reports its severity and evidence.
+type EvidenceChecker interface {
+	RepoChecker
+	Run(ctx context.Context, repo models.RepoData) (models.CheckerResult, error)
+}
+
 // RepoHeuristic represents a heuristic that can be applied to repository data.
 type RepoHeuristic interface {
 	Evaluate(repo models.RepoData) models.HeuristicResult
 }
 
+// KeywordRule is a named set of phrases that together mark malicious content, such as a README
+// or a commit message.
+type KeywordRule struct {
+	Name string
+	// Phrases must all appear in the content, ignoring case.
+	Phrases []string
+	// Category is the flag category of a match. Empty uses Malware.
+	Category string
+}
+
+// DefaultReadmeRules are the README markers checked when none are configured.
+var DefaultReadmeRules = []KeywordRule{
+	{Name: "DownloadLinkPasswordMarker", Phrases: []string{"download link", "password : 2025"}},
+}
+
 // ReadmeChecker checks repository README files for suspicious content
-type ReadmeChecker struct{}
+type ReadmeChecker struct {
+	// Rules overrides DefaultReadmeRules when non-nil.
+	Rules []KeywordRule
+}
+
+func (rc *ReadmeChecker) rules() []KeywordRule {
+	if rc.Rules == nil {
+		return DefaultReadmeRules
+	}
+	return rc.Rules
+}
 
 // Check evaluates a repository's README
 func (rc *ReadmeChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := rc.Run(ctx, repo)
+	return result.Flagged, err
+}
+
+// Run evaluates a repository's README against the rules. A README with only some of a rule's
+// phrases is reported as a near miss.
+func (rc *ReadmeChecker) Run(ctx context.Context, repo models.RepoData) (models.CheckerResult, error) {
+	result := models.CheckerResult{Name: "ReadmeChecker", Severity: models.SeverityHigh}
+	if matches := rc.Matches(repo); len(matches) > 0 {
+		result.Flagged = true
+		descriptions := make([]string, 0, len(matches))
+		for _, match := range matches {
+			descriptions = append(descriptions, match.Description)
+		}
+		result.Evidence = strings.Join(descriptions, " ")
+		return result, nil
+	}
+	lower := strings.ToLower(repo.Readme)
+	for _, rule := range rc.rules() {
+		found, missing := splitPhrases(lower, rule.Phrases)
+		if len(found) > 0 {
+			result.Evidence = fmt.Sprintf("README matches %s of rule %s but not %s.", quoteAll(found), rule.Name, quoteAll(missing))
+			break
+		}
+	}
+	return result, nil
+}
+
+// Matches returns a flagged result named after each rule whose phrases all appear in the README.
+func (rc *ReadmeChecker) Matches(repo models.RepoData) []models.HeuristicResult {
 	if repo.Readme == "" {
-		return false, nil
+		return nil
 	}
+	return matchKeywordRules("README", repo.Readme, rc.rules())
+}
 
-	lower := strings.ToLower(repo.Readme)
-	if strings.Contains(lower, "download link") && strings.Contains(lower, "password : 2025") {
-		return true, nil
+// matchKeywordRules returns a flagged result named after each rule whose phrases all appear in
+// text, describing the match as found in source.
+func matchKeywordRules(source, text string, rules []KeywordRule) []models.HeuristicResult {
+	lower := strings.ToLower(text)
+	var matches []models.HeuristicResult
+	for _, rule := range rules {
+		found, missing := splitPhrases(lower, rule.Phrases)
+		if len(found) == 0 || len(missing) > 0 {
+			continue
+		}
+		category := rule.Category
+		if category == "" {
+			category = "Malware"
+		}
+		matches = append(matches, models.HeuristicResult{
+			Category:    category,
+			Flag:        true,
+			Name:        rule.Name,
+			Description: fmt.Sprintf("%s matches rule %s: %s.", source, rule.Name, quoteAll(found)),
+		})
+	}
+	return matches
+}
+
+// splitPhrases separates the phrases found in the lowercased text from the missing ones.
+func splitPhrases(lower string, phrases []string) (found, missing []string) {
+	for _, phrase := range phrases {
+		if strings.Contains(lower, strings.ToLower(phrase)) {
+			found = append(found, phrase)
+		} else {
+			missing = append(missing, phrase)
+		}
 	}
+	return found, missing
+}
 
-	return false, nil
+// quoteAll renders phrases as a comma-separated list of quoted strings.
+func quoteAll(phrases []string) string {
+	quoted := make([]string, len(phrases))
+	for i, phrase := range phrases {
+		quoted[i] = fmt.Sprintf("%q", phrase)
+	}
+	return strings.Join(quoted, ", ")
 }
 
-// LoaderChecker checks repositories for suspicious loader files
+// LoaderChecker checks repositories for suspicious loader files.
+// A nil Client limits the check to tree entries.
 type LoaderChecker struct {
-	Client *github.Client
+	Client github.GitHubAPI
 }
 
 // Check evaluates a repository for suspicious loader files
 func (lc *LoaderChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := lc.Run(ctx, repo)
+	return result.Flagged, err
+}
+
+// Run evaluates a repository for loader archives in its tree and releases. Other archives or
+// executables in the tree are reported as a near miss.
+func (lc *LoaderChecker) Run(ctx context.Context, repo models.RepoData) (models.CheckerResult, error) {
+	result := models.CheckerResult{Name: "LoaderChecker", Severity: models.SeverityHigh}
+
 	// Check tree entries for loader files
+	var payloads []string
 	for _, entry := range repo.TreeEntries {
 		lower := strings.ToLower(entry)
 		if lower == "loader.zip" || lower == "loader.rar" {
-			return true, nil
+			result.Flagged = true
+			result.Evidence = fmt.Sprintf("Tree contains %s.", entry)
+			return result, nil
+		}
+		if isPayloadFile(entry) {
+			payloads = append(payloads, entry)
 		}
 	}
+	if len(payloads) > 0 {
+		result.Evidence = fmt.Sprintf("Tree contains archives or executables not named loader: %s.", strings.Join(payloads, ", "))
+	}
 
-	// Check releases for loader files
-	found, err := lc.Client.CheckRepoReleases(ctx, repo.Owner, repo.Name)
+	// Check releases for loader files; without a client only the tree is checked
+	if lc.Client == nil {
+		return result, nil
+	}
+	assets, err := lc.Client.GetReleaseAssets(ctx, repo.Owner, repo.Name)
 	if err != nil {
-		return false, err
+		return result, err
 	}
-
-	return found, nil
+	for _, asset := range assets {
+		lower := strings.ToLower(asset.Name)
+		if lower == "loader.zip" || lower == "loader.rar" {
+			result.Flagged = true
+			result.Evidence = fmt.Sprintf("A release ships %s (%d bytes, %d downloads).", asset.Name, asset.Size, asset.DownloadCount)
+			return result, nil
+		}
+	}
+	return result, nil
 }
 
 // GeneratedRepoNamingHeuristic detects repeated project-name plus numeric suffix patterns.
@@ -212,8 +715,8 @@ type PromotionSpamReadmeHeuristic struct{}
 // Evaluate evaluates the promotion spam README heuristic.
 func (h *PromotionSpamReadmeHeuristic) Evaluate(repo models.RepoData) models.HeuristicResult {
 	lower := strings.ToLower(repo.Readme)
-	incentiveMatch := firstMatchingPhrase(lower, []string{"airdrop", "token", "giveaway", "reward", "referral"})
-	actionMatch := firstMatchingPhrase(lower, []string{"join telegram", "join discord", "claim now", "follow for rewards", "star this repo", "dm for access"})
+	incentiveMatch := firstMatchingPhrase(lower, promotionIncentivePhrases)
+	actionMatch := firstMatchingPhrase(lower, promotionActionPhrases)
 	flag := incentiveMatch != "" && actionMatch != ""
 	description := "Repository README combines incentive language with promotional calls to action."
 	if flag {
@@ -228,14 +731,66 @@ func (h *PromotionSpamReadmeHeuristic) Evaluate(repo models.RepoData) models.Heu
 	}
 }
 
-// EvaluateRepoHeuristics evaluates repository heuristics that indicate generated or inauthentic content.
-func EvaluateRepoHeuristics(repo models.RepoData) []models.HeuristicResult {
-	heuristics := []RepoHeuristic{
+// DefaultSingleLanguageShare is the share of a repository's code bytes its top language must
+// exceed before SingleLanguageHeuristic flags it.
+const DefaultSingleLanguageShare = 0.98
+
+// SingleLanguageHeuristic detects repos with fewer than three files that are almost entirely
+// one language, typical of a single dropped script.
+type SingleLanguageHeuristic struct{}
+
+// Evaluate evaluates the single language heuristic.
+func (h *SingleLanguageHeuristic) Evaluate(repo models.RepoData) models.HeuristicResult {
+	language, share := topLanguage(repo.Languages)
+	flag := len(repo.TreeEntries) > 0 && len(repo.TreeEntries) < 3 && share > DefaultSingleLanguageShare
+	description := "Repository has fewer than three files and is almost entirely one language."
+	if flag {
+		description = fmt.Sprintf("Repository has %d files and %.1f%% of its code is %s.", len(repo.TreeEntries), share*100, language)
+	}
+
+	return models.HeuristicResult{
+		Category:    "Other Suspicious Patterns",
+		Flag:        flag,
+		Name:        "SingleLanguageHeuristic",
+		Description: description,
+	}
+}
+
+// Check reports whether repo is flagged.
+func (h *SingleLanguageHeuristic) Check(_ context.Context, repo models.RepoData) (bool, error) {
+	return h.Evaluate(repo).Flag, nil
+}
+
+// topLanguage returns the language with the most bytes and its share of all bytes. Ties go to
+// the alphabetically first language.
+func topLanguage(languages map[string]int) (string, float64) {
+	var top string
+	total := 0
+	for language, bytes := range languages {
+		total += bytes
+		if top == "" || bytes > languages[top] || (bytes == languages[top] && language < top) {
+			top = language
+		}
+	}
+	if total <= 0 {
+		return "", 0
+	}
+	return top, float64(languages[top]) / float64(total)
+}
+
+// DefaultRepoHeuristics returns the built-in repository heuristics.
+func DefaultRepoHeuristics() []RepoHeuristic {
+	return []RepoHeuristic{
 		&GeneratedRepoNamingHeuristic{},
 		&BoilerplateReadmeHeuristic{},
 		&SparseProjectHeuristic{},
 		&PromotionSpamReadmeHeuristic{},
 	}
+}
+
+// EvaluateRepoHeuristics evaluates repository heuristics that indicate generated or inauthentic content.
+func EvaluateRepoHeuristics(repo models.RepoData) []models.HeuristicResult {
+	heuristics := DefaultRepoHeuristics()
 
 	results := make([]models.HeuristicResult, 0, len(heuristics))
 	for _, heuristic := range heuristics {
@@ -270,6 +825,43 @@ func generatedPortfolioStats(repos []models.RepoData) (matchedCount int, dominan
 	return matchedCount, dominantPrefix, dominantCount, lowContentCount
 }
 
+// nameTemplate normalizes a repository name into a template by lowercasing it and replacing digit runs with '#'.
+// Names without digits have no sequential template.
+func nameTemplate(name string) (string, bool) {
+	lower := strings.ToLower(name)
+	template := digitRunPattern.ReplaceAllString(lower, "#")
+	if template == lower || strings.Trim(template, "#-_.") == "" {
+		return "", false
+	}
+	return template, true
+}
+
+// dominantNameTemplate returns the most common sequential template across repos and how many repos match it.
+func dominantNameTemplate(repos []models.RepoData) (string, int) {
+	counts := map[string]int{}
+	dominant, dominantCount := "", 0
+	for _, repo := range repos {
+		template, ok := nameTemplate(repo.Name)
+		if !ok {
+			continue
+		}
+		counts[template]++
+		if counts[template] > dominantCount || (counts[template] == dominantCount && template < dominant) {
+			dominant, dominantCount = template, counts[template]
+		}
+	}
+	return dominant, dominantCount
+}
+
+// templateUniformity is the fraction of repos that follow the owner's dominant sequential naming template.
+func templateUniformity(repos []models.RepoData) float64 {
+	if len(repos) == 0 {
+		return 0
+	}
+	_, matched := dominantNameTemplate(repos)
+	return float64(matched) / float64(len(repos))
+}
+
 func generatedRepoNamePrefix(name string) (string, bool) {
 	matches := generatedRepoNamePattern.FindStringSubmatch(name)
 	if len(matches) != 3 {
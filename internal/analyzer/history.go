@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+// DefaultHistoryCommits is how many recent commits the deep history check inspects.
+const DefaultHistoryCommits = 20
+
+// payloadExtensions are archive and executable types droppers commit and later delete.
+var payloadExtensions = map[string]bool{
+	".zip": true, ".rar": true, ".7z": true, ".exe": true, ".scr": true, ".dll": true, ".msi": true,
+}
+
+// HistoryChecker looks for payloads that were committed and later removed, so they no longer
+// appear in the current tree. It costs one request per inspected commit.
+type HistoryChecker struct {
+	Client github.GitHubAPI
+	// MaxCommits caps the commits inspected. Zero uses DefaultHistoryCommits.
+	MaxCommits int
+}
+
+// Evaluate inspects the recent history of branch and flags payloads missing from repo.TreeEntries.
+func (hc *HistoryChecker) Evaluate(ctx context.Context, repo models.RepoData, branch string) (models.HeuristicResult, error) {
+	result := models.HeuristicResult{
+		Category:    "Malware",
+		Name:        "HistoricalPayloadHeuristic",
+		Description: "Repository history contains an archive or executable that was removed from the current tree.",
+	}
+
+	limit := hc.MaxCommits
+	if limit <= 0 {
+		limit = DefaultHistoryCommits
+	}
+	shas, err := hc.Client.ListCommits(ctx, repo.Owner, repo.Name, branch, limit)
+	if err != nil {
+		return result, err
+	}
+	commits := make([]models.Commit, 0, len(shas))
+	for _, sha := range shas {
+		files, err := hc.Client.GetCommitFiles(ctx, repo.Owner, repo.Name, sha)
+		if err != nil {
+			return result, err
+		}
+		commits = append(commits, models.Commit{SHA: sha, Files: files})
+	}
+
+	payloads := HistoricalPayloads(commits, repo.TreeEntries)
+	if len(payloads) > 0 {
+		result.Flag = true
+		result.Description = fmt.Sprintf("%s added in history but absent from %s: %s.",
+			pluralize(len(payloads), "payload was", "payloads were"), branch, strings.Join(payloads, ", "))
+	}
+	return result, nil
+}
+
+// HistoricalPayloads returns payload files added by commits but absent from tree, in the order
+// they were first added. Commits are newest first, as ListCommits returns them.
+func HistoricalPayloads(commits []models.Commit, tree []string) []string {
+	present := make(map[string]bool, len(tree))
+	for _, entry := range tree {
+		present[entry] = true
+	}
+
+	seen := map[string]bool{}
+	var payloads []string
+	for i := len(commits) - 1; i >= 0; i-- {
+		for _, file := range commits[i].Files {
+			if file.Status != "added" || !isPayloadFile(file.Filename) {
+				continue
+			}
+			if present[file.Filename] || seen[file.Filename] {
+				continue
+			}
+			seen[file.Filename] = true
+			payloads = append(payloads, file.Filename)
+		}
+	}
+	return payloads
+}
+
+func isPayloadFile(filename string) bool {
+	return payloadExtensions[strings.ToLower(path.Ext(filename))]
+}
+
+func pluralize(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+// IndicatorLookup reports whether a repo ("owner/name") or user is listed on an imported blocklist.
+// The "repo_id" and "user_id" types are keyed by GitHub's numeric ID in decimal.
+type IndicatorLookup interface {
+	LookupExternalIndicator(indicatorType, value string) (source string, found bool, err error)
+}
+
+// AllowlistLookup reports whether an operator cleared a repo ("owner/name") or user of
+// suspicion.
+type AllowlistLookup interface {
+	IsAllowlisted(entityType, entityID string) (bool, error)
+}
+
+// IsAllowlisted reports whether the configured allowlist lists an entity. Lookup errors are
+// logged and treated as not listed, so the entity is analyzed as usual.
+func (a *Analyzer) IsAllowlisted(entityType, entityID string) bool {
+	if a.allowlist == nil {
+		return false
+	}
+	listed, err := a.allowlist.IsAllowlisted(entityType, entityID)
+	if err != nil {
+		a.logger.Error("Error looking up %s %s in the allowlist: %v", entityType, entityID, err)
+		return false
+	}
+	return listed
+}
+
+// ExternalIndicatorResult is the flag raised for an entity listed on a shared blocklist.
+func ExternalIndicatorResult(source string) models.HeuristicResult {
+	return models.HeuristicResult{
+		Category:    "Malware",
+		Flag:        true,
+		Name:        "ExternalIndicatorHeuristic",
+		Description: fmt.Sprintf("Listed as confirmed malicious on shared blocklist %s.", source),
+	}
+}
+
+// externalIndicator returns the blocklist flag for an entity, if any. Lookup errors are logged
+// and treated as no match so a broken blocklist never blocks analysis.
+func (a *Analyzer) externalIndicator(indicatorType, value string) (models.HeuristicResult, bool) {
+	if a.indicators == nil {
+		return models.HeuristicResult{}, false
+	}
+	source, found, err := a.indicators.LookupExternalIndicator(indicatorType, value)
+	if err != nil {
+		a.logger.Error("Error looking up %s %s in blocklists: %v", indicatorType, value, err)
+		return models.HeuristicResult{}, false
+	}
+	if !found {
+		return models.HeuristicResult{}, false
+	}
+	return ExternalIndicatorResult(source), true
+}
+
+// entityIndicator looks an entity up by name and then by GitHub's numeric ID, so a listed
+// repository or account is still matched after it is renamed.
+func (a *Analyzer) entityIndicator(indicatorType, name string, id int64) (models.HeuristicResult, bool) {
+	if result, found := a.externalIndicator(indicatorType, name); found {
+		return result, true
+	}
+	if id == 0 {
+		return models.HeuristicResult{}, false
+	}
+	return a.externalIndicator(indicatorType+"_id", strconv.FormatInt(id, 10))
+}
@@ -0,0 +1,41 @@
+package analyzer
+
+import "strings"
+
+// SuspiciousKeywords are phrases that mark promotional or scam text in profiles and READMEs:
+// crypto giveaway language and links through URL shorteners, which hide where they lead.
+// Matching ignores case.
+var SuspiciousKeywords = []string{
+	// Crypto giveaway and get-rich language.
+	"crypto giveaway",
+	"free crypto",
+	"free btc",
+	"free usdt",
+	"airdrop",
+	"giveaway",
+	"double your",
+	"claim your reward",
+	"guaranteed profit",
+	"passive income",
+	"forex signals",
+	"pump signals",
+	// URL shorteners.
+	"bit.ly/",
+	"tinyurl.com/",
+	"cutt.ly/",
+	"rb.gy/",
+	"is.gd/",
+	"goo.gl/",
+	"shorturl.at/",
+}
+
+// Phrases of PromotionSpamReadmeHeuristic: an incentive together with a call to action.
+var (
+	promotionIncentivePhrases = []string{"airdrop", "token", "giveaway", "reward", "referral"}
+	promotionActionPhrases    = []string{"join telegram", "join discord", "claim now", "follow for rewards", "star this repo", "dm for access"}
+)
+
+// firstSuspiciousKeyword returns the first of SuspiciousKeywords found in text, or "" when none is.
+func firstSuspiciousKeyword(text string) string {
+	return firstMatchingPhrase(strings.ToLower(text), SuspiciousKeywords)
+}
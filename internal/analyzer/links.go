@@ -0,0 +1,24 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+var readmeLinkPattern = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+
+// ExtractLinks returns the distinct http(s) URLs referenced in README content, in order of appearance.
+func ExtractLinks(readme string) []string {
+	matches := readmeLinkPattern.FindAllString(readme, -1)
+	seen := make(map[string]struct{}, len(matches))
+	links := make([]string, 0, len(matches))
+	for _, match := range matches {
+		link := strings.TrimRight(match, ".,;:!?*_")
+		if _, ok := seen[link]; ok {
+			continue
+		}
+		seen[link] = struct{}{}
+		links = append(links, link)
+	}
+	return links
+}
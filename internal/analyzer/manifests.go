@@ -0,0 +1,210 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+// npmInstallHooks are the package.json scripts npm runs when the package is installed.
+var npmInstallHooks = []string{"preinstall", "install", "postinstall"}
+
+// legitInstallScripts are install hook commands common in honest packages, such as native
+// addon builds. A hook that runs one of them and chains no other command is not inspected.
+var legitInstallScripts = []string{
+	"node-gyp rebuild",
+	"node-gyp-build",
+	"prebuild-install",
+	"node-pre-gyp install",
+	"husky install",
+	"husky",
+	"patch-package",
+	"opencollective-postinstall",
+}
+
+// shellChaining are the shell operators that append another command to an allowlisted one.
+var shellChaining = []string{"&&", "||", ";", "|", "`", "$(", "\n"}
+
+// installFetchMarkers, installDecodeMarkers, and the pipeToShell pattern are what
+// ManifestChecker looks for in install hooks: downloads, base64 decoding, and downloads run
+// straight in a shell. Matching ignores case.
+var (
+	installFetchMarkers  = []string{"curl ", "wget ", "invoke-webrequest", "iwr ", "urlopen(", "urlretrieve(", "requests.get(", "requests.post(", "http.get(", "https.get(", "fetch("}
+	installDecodeMarkers = []string{"base64 -d", "base64 --decode", "b64decode(", "frombase64string", "atob(", "'base64')", "\"base64\")"}
+	// urlRequirement matches a requirement installed from a URL, such as "pkg @ https://host/pkg.whl".
+	urlRequirement = regexp.MustCompile(`(?i)["'][^"'\n]*(@\s*|git\+)https?://[^"'\n]*["']`)
+)
+
+// ManifestChecker flags repositories whose package manifests run suspicious code on install: a
+// package.json preinstall, install, or postinstall script that downloads, decodes base64, or
+// pipes into a shell; a setup.py with custom install commands (cmdclass) that does the same,
+// or setup_requires pulling from a URL; and a pyproject.toml whose build requirements come from
+// a URL. Hooks that only run a common legitimate command such as node-gyp rebuild are skipped.
+// It fetches up to maxManifests manifests from the tree, skipping node_modules, and reports at
+// high severity.
+type ManifestChecker struct {
+	Client github.GitHubAPI
+}
+
+// Check evaluates a repository's package manifests.
+func (mc *ManifestChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := mc.Run(ctx, repo)
+	return result.Flagged, err
+}
+
+// Run evaluates a repository's package manifests. Manifests that fail to parse are skipped.
+func (mc *ManifestChecker) Run(ctx context.Context, repo models.RepoData) (models.CheckerResult, error) {
+	result := models.CheckerResult{Name: "ManifestChecker", Severity: models.SeverityHigh}
+	findings, err := mc.findings(ctx, repo)
+	if len(findings) > 0 {
+		result.Flagged = true
+		result.Evidence = strings.Join(findings, " ")
+	}
+	return result, err
+}
+
+// Matches returns a Malware flag for each suspicious install hook. Manifests that cannot be
+// fetched are skipped; Run reports the error.
+func (mc *ManifestChecker) Matches(ctx context.Context, repo models.RepoData) []models.HeuristicResult {
+	findings, _ := mc.findings(ctx, repo)
+	matches := make([]models.HeuristicResult, 0, len(findings))
+	for _, finding := range findings {
+		matches = append(matches, models.HeuristicResult{
+			Category:    "Malware",
+			Flag:        true,
+			Name:        "InstallHookHeuristic",
+			Description: finding,
+		})
+	}
+	return matches
+}
+
+// findings describes each suspicious install hook in the repository's manifests.
+func (mc *ManifestChecker) findings(ctx context.Context, repo models.RepoData) ([]string, error) {
+	var findings []string
+	fetched := 0
+	for _, entry := range repo.TreeEntries {
+		switch path.Base(entry) {
+		case "package.json", "setup.py", "pyproject.toml":
+		default:
+			continue
+		}
+		if strings.Contains("/"+entry, "/node_modules/") {
+			continue
+		}
+		if fetched == maxManifests {
+			break
+		}
+		fetched++
+		content, err := mc.Client.GetFileContent(ctx, repo.Owner, repo.Name, entry, "")
+		if err != nil {
+			return findings, err
+		}
+		findings = append(findings, InspectManifest(entry, content)...)
+	}
+	return findings, nil
+}
+
+// InspectManifest describes the suspicious install hooks in a package.json, setup.py, or
+// pyproject.toml, judged by the file's base name. Other files and unparsable manifests have
+// none.
+func InspectManifest(filename, content string) []string {
+	switch path.Base(filename) {
+	case "package.json":
+		return inspectPackageJSON(filename, content)
+	case "setup.py":
+		return inspectSetupPy(filename, content)
+	case "pyproject.toml":
+		if match := urlRequirement.FindString(buildSystemSection(content)); match != "" {
+			return []string{fmt.Sprintf("%s installs a build requirement from a URL: %s.", filename, match)}
+		}
+	}
+	return nil
+}
+
+func inspectPackageJSON(filename, content string) []string {
+	var manifest struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return nil
+	}
+	var findings []string
+	for _, hook := range npmInstallHooks {
+		script := strings.TrimSpace(manifest.Scripts[hook])
+		if script == "" || isLegitInstallScript(script) {
+			continue
+		}
+		if behavior := installBehavior(script); behavior != "" {
+			findings = append(findings, fmt.Sprintf("%s %s script %s: %q.", filename, hook, behavior, script))
+		}
+	}
+	return findings
+}
+
+func inspectSetupPy(filename, content string) []string {
+	var findings []string
+	if strings.Contains(content, "cmdclass") {
+		if behavior := installBehavior(content); behavior != "" {
+			findings = append(findings, fmt.Sprintf("%s overrides install commands with cmdclass and %s.", filename, behavior))
+		}
+	}
+	if i := strings.Index(content, "setup_requires"); i >= 0 {
+		section := content[i:]
+		if end := strings.IndexAny(section, "])"); end >= 0 {
+			section = section[:end]
+		}
+		if match := urlRequirement.FindString(section); match != "" {
+			findings = append(findings, fmt.Sprintf("%s setup_requires installs from a URL: %s.", filename, match))
+		}
+	}
+	return findings
+}
+
+// installBehavior names what install code does that a package install should not, or returns
+// "" when it does none of it.
+func installBehavior(code string) string {
+	lower := strings.ToLower(code)
+	switch {
+	case pipeToShell.MatchString(code):
+		return "pipes a download into a shell"
+	case firstMatchingPhrase(lower, installDecodeMarkers) != "":
+		return "decodes base64"
+	case firstMatchingPhrase(lower, installFetchMarkers) != "":
+		return "downloads from the network"
+	default:
+		return ""
+	}
+}
+
+func isLegitInstallScript(script string) bool {
+	lower := strings.ToLower(script)
+	if firstMatchingPhrase(lower, shellChaining) != "" {
+		return false
+	}
+	for _, legit := range legitInstallScripts {
+		if strings.HasPrefix(lower, legit) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildSystemSection returns the [build-system] table of a pyproject.toml.
+func buildSystemSection(content string) string {
+	start := strings.Index(content, "[build-system]")
+	if start < 0 {
+		return ""
+	}
+	section := content[start+len("[build-system]"):]
+	if end := strings.Index(section, "\n["); end >= 0 {
+		section = section[:end]
+	}
+	return section
+}
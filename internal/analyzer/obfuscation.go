@@ -0,0 +1,132 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// DefaultObfuscationThreshold is the score at which ObfuscationChecker flags a file: one
+	// decode-and-run indicator alone, or an extremely long line together with dense escapes.
+	DefaultObfuscationThreshold = 3
+	// obfuscationLineLength is the line length ObfuscationChecker counts as extremely long.
+	// Minified files are never sampled, so hand-written code rarely comes close.
+	obfuscationLineLength = 5000
+	// obfuscationEscapeRatio and obfuscationMinEscapes are the share of a file's bytes and the
+	// number of escape sequences that count as dense escaping.
+	obfuscationEscapeRatio = 0.2
+	obfuscationMinEscapes  = 50
+)
+
+// Scores of the obfuscation indicators. Running decoded data is scored higher than the
+// indicators that only make a file hard to read.
+const (
+	longLineScore   = 2
+	escapeScore     = 2
+	decodeExecScore = 3
+	encodedPSScore  = 3
+)
+
+var (
+	// escapeSequence matches a character written as a hex or unicode escape, chr(), or
+	// String.fromCharCode() with its arguments.
+	escapeSequence = regexp.MustCompile(`\\x[0-9a-fA-F]{2}|\\u[0-9a-fA-F]{4}|\bchr\(\s*\d+\s*\)|String\.fromCharCode\([\d\s,]*\)`)
+	// decodeExec matches eval or exec called on decoded data on the same line.
+	decodeExec = regexp.MustCompile(`(?i)\b(eval|exec)\s*\([^\n]{0,200}?(b64decode|base64|atob\(|unhexlify|fromcharcode|decompress|marshal\.loads|gzinflate|str_rot13)`)
+	// encodedCommand matches a PowerShell -EncodedCommand argument and its abbreviations.
+	encodedCommand = regexp.MustCompile(`(?i)\s-(e|ec|en|enc|encodedcommand)\s+['"]?[A-Za-z0-9+/]{40,}={0,2}`)
+	// invokeDecoded matches Invoke-Expression or iex on a base64-decoded string.
+	invokeDecoded = regexp.MustCompile(`(?i)(invoke-expression|\biex\b)[^\n]*frombase64string|frombase64string[^\n]*(invoke-expression|\biex\b)`)
+)
+
+// ObfuscationChecker flags repositories whose scripts are obfuscated, the usual disguise of a
+// loader. It scores each file Sources samples: an extremely long line and dense character
+// escapes (\x41, chr(65), String.fromCharCode) add 2 each, and eval or exec of decoded data or
+// a PowerShell encoded command add 3 each. A file scoring at least Threshold flags the
+// repository at medium severity.
+type ObfuscationChecker struct {
+	Sources *SourceSampler
+	// Threshold is the score that flags a file. Zero uses DefaultObfuscationThreshold.
+	Threshold int
+}
+
+// Check evaluates a repository's source files for obfuscation.
+func (oc *ObfuscationChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := oc.Run(ctx, repo)
+	return result.Flagged, err
+}
+
+// Run evaluates a repository's source files for obfuscation.
+func (oc *ObfuscationChecker) Run(ctx context.Context, repo models.RepoData) (models.CheckerResult, error) {
+	result := models.CheckerResult{Name: "ObfuscationChecker", Severity: models.SeverityMedium}
+	threshold := oc.Threshold
+	if threshold <= 0 {
+		threshold = DefaultObfuscationThreshold
+	}
+	files, err := oc.Sources.Files(ctx, repo)
+	if err != nil {
+		return result, err
+	}
+
+	var matches []string
+	for _, file := range files {
+		if score, indicators := ScoreObfuscation(file.Content); score >= threshold {
+			matches = append(matches, fmt.Sprintf("%s (score %d: %s)", file.Path, score, strings.Join(indicators, ", ")))
+		}
+	}
+	if len(matches) > 0 {
+		result.Flagged = true
+		result.Evidence = fmt.Sprintf("Obfuscated code in %s: %s.", pluralize(len(matches), "file", "files"), strings.Join(matches, "; "))
+	}
+	return result, nil
+}
+
+// ScoreObfuscation scores how obfuscated a script is and describes the indicators found.
+func ScoreObfuscation(content string) (score int, indicators []string) {
+	if longest := longestLine(content); longest >= obfuscationLineLength {
+		score += longLineScore
+		indicators = append(indicators, fmt.Sprintf("%d-character line", longest))
+	}
+	escapes := escapeSequence.FindAllStringIndex(content, -1)
+	escaped := 0
+	for _, loc := range escapes {
+		escaped += loc[1] - loc[0]
+	}
+	if len(escapes) >= obfuscationMinEscapes && float64(escaped) >= obfuscationEscapeRatio*float64(len(content)) {
+		score += escapeScore
+		indicators = append(indicators, fmt.Sprintf("escape sequences make up %d%% of the file", escaped*100/len(content)))
+	}
+	if match := decodeExec.FindString(content); match != "" {
+		score += decodeExecScore
+		indicators = append(indicators, fmt.Sprintf("runs decoded data: %q", truncateIndicator(match)))
+	}
+	if match := encodedCommand.FindString(content); match != "" {
+		score += encodedPSScore
+		indicators = append(indicators, fmt.Sprintf("PowerShell encoded command: %q", truncateIndicator(strings.TrimSpace(match))))
+	} else if match := invokeDecoded.FindString(content); match != "" {
+		score += encodedPSScore
+		indicators = append(indicators, fmt.Sprintf("Invoke-Expression on decoded data: %q", truncateIndicator(match)))
+	}
+	return score, indicators
+}
+
+func longestLine(content string) int {
+	longest := 0
+	for _, line := range strings.Split(content, "\n") {
+		longest = max(longest, len(line))
+	}
+	return longest
+}
+
+// truncateIndicator shortens a matched snippet to keep evidence readable.
+func truncateIndicator(match string) string {
+	const limit = 60
+	if len(match) <= limit {
+		return match
+	}
+	return match[:limit] + "..."
+}
@@ -0,0 +1,334 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// DefaultRedirectMaxHops is how many redirects RedirectResolver follows from a shortened link.
+	DefaultRedirectMaxHops = 5
+	// DefaultRedirectTimeout bounds the whole redirect chain of one link.
+	DefaultRedirectTimeout = 10 * time.Second
+	// maxOutboundLinks caps the README links OutboundLinkChecker evaluates, bounding the
+	// redirect requests per repository.
+	maxOutboundLinks = 20
+	// maxResolvedLinks caps the links RedirectResolver remembers before it starts over.
+	maxResolvedLinks = 1024
+)
+
+// DefaultLinkShorteners are the URL shortener domains whose links OutboundLinkChecker unwraps.
+var DefaultLinkShorteners = NewDomainList(
+	"bit.ly", "buff.ly", "cutt.ly", "goo.gl", "is.gd", "ow.ly", "rb.gy", "rebrand.ly",
+	"s.id", "shorturl.at", "t.co", "t.ly", "tiny.cc", "tinyurl.com",
+)
+
+// DefaultFileHostingDomains are the file-hosting services loader campaigns send downloads
+// through. OutboundLinkChecker flags README links that end up on them.
+var DefaultFileHostingDomains = NewDomainList(
+	"anonfiles.com", "catbox.moe", "dropbox.com", "dropboxusercontent.com", "drive.google.com",
+	"file.io", "gofile.io", "krakenfiles.com", "mediafire.com", "mega.io", "mega.nz",
+	"pixeldrain.com", "sendspace.com", "transfer.sh", "upload.ee", "workupload.com",
+)
+
+// DomainList is a set of domains. A listed domain also covers its subdomains.
+type DomainList map[string]bool
+
+// NewDomainList builds a DomainList from domains, ignoring case and a leading "*." or ".".
+func NewDomainList(domains ...string) DomainList {
+	list := DomainList{}
+	for _, domain := range domains {
+		if domain = normalizeDomain(domain); domain != "" {
+			list[domain] = true
+		}
+	}
+	return list
+}
+
+// ParseDomainList reads one domain per line, such as "mega.nz" or "*.example.com". Blank lines
+// and lines starting with # are skipped.
+func ParseDomainList(data string) (DomainList, error) {
+	list := DomainList{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		domain := normalizeDomain(entry)
+		if domain == "" || strings.ContainsAny(domain, " /:?#@") {
+			return nil, fmt.Errorf("domain list line %d: expected a domain, got %q", line, entry)
+		}
+		list[domain] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading domain list: %w", err)
+	}
+	return list, nil
+}
+
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimPrefix(strings.TrimPrefix(domain, "*"), ".")
+	return strings.TrimSuffix(domain, ".")
+}
+
+// Match returns the listed domain that host is or falls under, or "" when none does.
+func (l DomainList) Match(host string) string {
+	host = normalizeDomain(host)
+	for host != "" {
+		if l[host] {
+			return host
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			break
+		}
+		host = parent
+	}
+	return ""
+}
+
+// linkHost returns the lowercased hostname of an http(s) link, or "" when it has none.
+func linkHost(link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+	return normalizeDomain(parsed.Hostname())
+}
+
+// URLResolver finds where a link ends up after its redirects.
+type URLResolver interface {
+	Resolve(ctx context.Context, link string) (string, error)
+}
+
+// RedirectResolver follows redirects with HEAD requests, without downloading what a link points
+// to. It remembers the links it resolved, so a shortened link shared by a campaign's
+// repositories costs its requests once.
+type RedirectResolver struct {
+	// Client sends the requests. Nil uses a client that does not follow redirects itself.
+	Client *http.Client
+	// MaxHops overrides DefaultRedirectMaxHops when positive.
+	MaxHops int
+	// Timeout overrides DefaultRedirectTimeout when positive.
+	Timeout time.Duration
+
+	mu       sync.Mutex
+	resolved map[string]resolution
+}
+
+type resolution struct {
+	url string
+	err error
+}
+
+// errTooManyRedirects is returned when a redirect chain exceeds the hop limit.
+var errTooManyRedirects = errors.New("too many redirects")
+
+// NewRedirectResolver returns a resolver following at most maxHops redirects within timeout.
+func NewRedirectResolver(maxHops int, timeout time.Duration) *RedirectResolver {
+	return &RedirectResolver{MaxHops: maxHops, Timeout: timeout}
+}
+
+// Resolve returns the URL link ends up at. A response that is not a redirect ends the chain, so
+// a shortener refusing HEAD requests resolves to itself. Redirects to anything but http(s), more
+// than MaxHops redirects, and chains outlasting Timeout are errors.
+func (r *RedirectResolver) Resolve(ctx context.Context, link string) (string, error) {
+	r.mu.Lock()
+	if cached, ok := r.resolved[link]; ok {
+		r.mu.Unlock()
+		return cached.url, cached.err
+	}
+	r.mu.Unlock()
+
+	final, err := r.follow(ctx, link)
+	if ctx.Err() != nil {
+		// The caller gave up, so the link may resolve next time.
+		return final, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.resolved == nil || len(r.resolved) >= maxResolvedLinks {
+		r.resolved = map[string]resolution{}
+	}
+	r.resolved[link] = resolution{url: final, err: err}
+	return final, err
+}
+
+func (r *RedirectResolver) follow(ctx context.Context, link string) (string, error) {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRedirectTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	maxHops := r.MaxHops
+	if maxHops <= 0 {
+		maxHops = DefaultRedirectMaxHops
+	}
+	client := r.Client
+	if client == nil {
+		client = &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	}
+
+	current := link
+	for hops := 0; ; hops++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, current, nil)
+		if err != nil {
+			return current, fmt.Errorf("resolving %s: %w", link, err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return current, fmt.Errorf("resolving %s: %w", link, err)
+		}
+		resp.Body.Close()
+		location := resp.Header.Get("Location")
+		if resp.StatusCode < 300 || resp.StatusCode > 399 || location == "" {
+			return current, nil
+		}
+		if hops == maxHops {
+			return current, fmt.Errorf("resolving %s: %w after %d hops", link, errTooManyRedirects, maxHops)
+		}
+		next, err := resp.Request.URL.Parse(location)
+		if err != nil {
+			return current, fmt.Errorf("resolving %s: bad redirect %q: %w", link, location, err)
+		}
+		if next.Scheme != "http" && next.Scheme != "https" {
+			return current, fmt.Errorf("resolving %s: redirect to %s URL", link, next.Scheme)
+		}
+		current = next.String()
+	}
+}
+
+// OutboundLink is a README link and where it ends up.
+type OutboundLink struct {
+	URL string
+	// Resolved is the URL a shortened link redirects to, or URL itself.
+	Resolved string
+	// Domain is the blocklisted or file-hosting domain Resolved falls under.
+	Domain string
+	// Blocklisted is set for a domain on the blocklist rather than a file host.
+	Blocklisted bool
+}
+
+// describe renders the link for evidence, showing where a shortened link leads.
+func (l OutboundLink) describe() string {
+	kind := "file host"
+	if l.Blocklisted {
+		kind = "blocklisted domain"
+	}
+	if l.Resolved != l.URL {
+		return fmt.Sprintf("%s, which redirects to %s on %s %s", l.URL, l.Resolved, kind, l.Domain)
+	}
+	return fmt.Sprintf("%s on %s %s", l.URL, kind, l.Domain)
+}
+
+// OutboundLinkChecker flags repositories whose README funnels readers to blocklisted domains or
+// file-hosting services. Links through URL shorteners are unwrapped first, and allowlisted
+// domains are never flagged. Blocklisted domains flag at high severity and file hosts at medium.
+type OutboundLinkChecker struct {
+	// Resolver unwraps shortened links. Nil checks them as they are written.
+	Resolver URLResolver
+	// Blocklist and Allowlist are the known-bad and trusted domains.
+	Blocklist DomainList
+	Allowlist DomainList
+	// FileHosts overrides DefaultFileHostingDomains when non-nil.
+	FileHosts DomainList
+	// Shorteners overrides DefaultLinkShorteners when non-nil.
+	Shorteners DomainList
+}
+
+// Check evaluates a repository's README links.
+func (oc *OutboundLinkChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := oc.Run(ctx, repo)
+	return result.Flagged, err
+}
+
+// Run evaluates a repository's README links, naming where each offending link ends up.
+func (oc *OutboundLinkChecker) Run(ctx context.Context, repo models.RepoData) (models.CheckerResult, error) {
+	result := models.CheckerResult{Name: "OutboundLinkChecker", Severity: models.SeverityMedium}
+	links, unresolved := oc.Links(ctx, repo)
+	descriptions := make([]string, 0, len(links))
+	for _, link := range links {
+		descriptions = append(descriptions, link.describe())
+		if link.Blocklisted {
+			result.Severity = models.SeverityHigh
+		}
+	}
+	if len(links) > 0 {
+		result.Flagged = true
+		result.Evidence = fmt.Sprintf("README links to %s.", strings.Join(descriptions, "; "))
+	} else if len(unresolved) > 0 {
+		result.Evidence = fmt.Sprintf("Could not resolve shortened %s: %s.", pluralize(len(unresolved), "link", "links"), strings.Join(unresolved, ", "))
+	}
+	return result, nil
+}
+
+// Matches returns a flag for each offending README link, carrying the resolved URL.
+func (oc *OutboundLinkChecker) Matches(ctx context.Context, repo models.RepoData) []models.HeuristicResult {
+	links, _ := oc.Links(ctx, repo)
+	var matches []models.HeuristicResult
+	for _, link := range links {
+		category := "Other Suspicious Patterns"
+		if link.Blocklisted {
+			category = "Malware"
+		}
+		matches = append(matches, models.HeuristicResult{
+			Category:    category,
+			Flag:        true,
+			Name:        "OutboundLinkHeuristic",
+			Description: fmt.Sprintf("README links to %s.", link.describe()),
+		})
+	}
+	return matches
+}
+
+// Links returns the README links that end up on a blocklisted or file-hosting domain, and the
+// shortened links that could not be resolved. At most 20 links are evaluated.
+func (oc *OutboundLinkChecker) Links(ctx context.Context, repo models.RepoData) (flagged []OutboundLink, unresolved []string) {
+	shorteners := oc.Shorteners
+	if shorteners == nil {
+		shorteners = DefaultLinkShorteners
+	}
+	fileHosts := oc.FileHosts
+	if fileHosts == nil {
+		fileHosts = DefaultFileHostingDomains
+	}
+	links := ExtractLinks(repo.Readme)
+	if len(links) > maxOutboundLinks {
+		links = links[:maxOutboundLinks]
+	}
+	for _, link := range links {
+		host := linkHost(link)
+		if host == "" || oc.Allowlist.Match(host) != "" {
+			continue
+		}
+		resolved := link
+		if oc.Resolver != nil && shorteners.Match(host) != "" {
+			final, err := oc.Resolver.Resolve(ctx, link)
+			if err != nil {
+				unresolved = append(unresolved, link)
+			}
+			resolved = final
+			if host = linkHost(resolved); host == "" || oc.Allowlist.Match(host) != "" {
+				continue
+			}
+		}
+		if domain := oc.Blocklist.Match(host); domain != "" {
+			flagged = append(flagged, OutboundLink{URL: link, Resolved: resolved, Domain: domain, Blocklisted: true})
+		} else if domain := fileHosts.Match(host); domain != "" {
+			flagged = append(flagged, OutboundLink{URL: link, Resolved: resolved, Domain: domain})
+		}
+	}
+	return flagged, unresolved
+}
@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// duplicateReadmeSimilarity is the shingle similarity at which two READMEs count as copies.
+	duplicateReadmeSimilarity = 0.9
+	// duplicateReadmeMinRepos is how many of an owner's READMEs must be copies of one another
+	// before DuplicateReadmeHeuristic flags the owner.
+	duplicateReadmeMinRepos = 6
+	// readmeShingleWords is how many consecutive words make one shingle.
+	readmeShingleWords = 3
+)
+
+// DuplicateReadmeHeuristic detects owners who reuse one near-identical README across their
+// repositories. It only sees READMEs when the analyzer samples them; see Options.ReadmeSampleSize.
+type DuplicateReadmeHeuristic struct{}
+
+// Evaluate evaluates the duplicate README heuristic.
+func (h *DuplicateReadmeHeuristic) Evaluate(data models.UserData, repos []models.RepoData) models.HeuristicResult {
+	group, sampled, similarity := similarReadmeGroup(repos)
+	flag := len(group) >= duplicateReadmeMinRepos
+	description := "User's repositories share near-identical READMEs."
+	if flag {
+		description = fmt.Sprintf("%d of %d sampled READMEs are near-identical (similarity %.2f or more): %s.",
+			len(group), sampled, similarity, strings.Join(group, ", "))
+	}
+
+	return models.HeuristicResult{
+		Category:    "Spam Behavior",
+		Flag:        flag,
+		Name:        "DuplicateReadmeHeuristic",
+		Description: description,
+	}
+}
+
+// similarReadmeGroup finds the largest group of repositories whose READMEs are all at least
+// duplicateReadmeSimilarity similar to one of them. It returns the group's repository names, how
+// many non-empty READMEs were compared, and the lowest similarity within the group.
+func similarReadmeGroup(repos []models.RepoData) (group []string, sampled int, similarity float64) {
+	var names []string
+	var shingles []map[string]bool
+	for _, repo := range repos {
+		set := readmeShingles(repo.Readme)
+		if len(set) == 0 {
+			continue
+		}
+		names = append(names, repo.Name)
+		shingles = append(shingles, set)
+	}
+
+	for i := range shingles {
+		members := []string{names[i]}
+		lowest := 1.0
+		for j := range shingles {
+			if i == j {
+				continue
+			}
+			if score := shingleSimilarity(shingles[i], shingles[j]); score >= duplicateReadmeSimilarity {
+				members = append(members, names[j])
+				lowest = min(lowest, score)
+			}
+		}
+		if len(members) > len(group) {
+			group, similarity = members, lowest
+		}
+	}
+	return group, len(shingles), similarity
+}
+
+// readmeShingles returns the set of lowercased word shingles of a README. A README shorter than
+// one shingle is a single shingle, so short boilerplate READMEs still compare.
+func readmeShingles(readme string) map[string]bool {
+	words := strings.Fields(strings.ToLower(readme))
+	if len(words) == 0 {
+		return nil
+	}
+	set := map[string]bool{}
+	if len(words) < readmeShingleWords {
+		set[strings.Join(words, " ")] = true
+		return set
+	}
+	for i := 0; i+readmeShingleWords <= len(words); i++ {
+		set[strings.Join(words[i:i+readmeShingleWords], " ")] = true
+	}
+	return set
+}
+
+// shingleSimilarity is the Jaccard similarity of two shingle sets.
+func shingleSimilarity(a, b map[string]bool) float64 {
+	shared := 0
+	for shingle := range a {
+		if b[shingle] {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
@@ -0,0 +1,212 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+)
+
+// Registration names a user heuristic or repository checker so that configuration can enable
+// and disable it. Exactly one of User and Repo is set.
+type Registration struct {
+	// Name is the stable name used by enabled_heuristics and disabled_heuristics. It matches the
+	// name the heuristic or checker reports its results under.
+	Name string
+	// Experimental registrations are off unless EnabledHeuristics names them.
+	Experimental bool
+	// User builds the user heuristic from the analyzer options.
+	User func(opts Options) UserHeuristic
+	// Repo builds the repository checker, or returns nil when opts leave it unconfigured.
+	Repo func(client github.GitHubAPI, opts Options) RepoChecker
+}
+
+var (
+	registrationsMu sync.RWMutex
+	// registrations are evaluated in order, so user heuristics and checkers registered later
+	// run after the built-in ones.
+	registrations = []Registration{
+		{Name: "OriginalHeuristic", User: func(opts Options) UserHeuristic { return &OriginalHeuristic{Thresholds: opts.Thresholds} }},
+		{Name: "NewHeuristic", User: func(opts Options) UserHeuristic { return &NewHeuristic{Thresholds: opts.Thresholds} }},
+		{Name: "RecentHeuristic", User: func(opts Options) UserHeuristic { return &RecentHeuristic{Thresholds: opts.Thresholds} }},
+		{Name: "GeneratedPortfolioHeuristic", User: func(Options) UserHeuristic { return &GeneratedPortfolioHeuristic{} }},
+		{Name: "TemplatedNamingHeuristic", User: func(opts Options) UserHeuristic {
+			return &TemplatedNamingHeuristic{Threshold: opts.TemplateUniformityThreshold}
+		}},
+		{Name: "SingleCommitHeuristic", User: func(Options) UserHeuristic { return &SingleCommitHeuristic{} }},
+		{Name: "UsernamePatternHeuristic", User: func(opts Options) UserHeuristic {
+			return &UsernamePatternHeuristic{Patterns: opts.UsernamePatterns}
+		}},
+		{Name: "CamelCaseNumberHeuristic", User: func(opts Options) UserHeuristic {
+			return &CamelCaseNumberHeuristic{Threshold: opts.CamelCaseNumberThreshold}
+		}},
+		{Name: "DuplicateReadmeHeuristic", User: func(Options) UserHeuristic { return &DuplicateReadmeHeuristic{} }},
+		{Name: "FollowRatioHeuristic", User: func(Options) UserHeuristic { return &FollowRatioHeuristic{} }},
+		{Name: "ForkFarmHeuristic", User: func(opts Options) UserHeuristic { return &ForkFarmHeuristic{MaxAge: opts.ForkFarmMaxAge} }},
+		{Name: "ProfileSpamHeuristic", User: func(Options) UserHeuristic { return &ProfileSpamHeuristic{} }},
+		{Name: "AvatarReuseHeuristic", User: func(Options) UserHeuristic { return &AvatarReuseHeuristic{} }},
+		{Name: "ReadmeChecker", Repo: func(_ github.GitHubAPI, opts Options) RepoChecker {
+			return &ReadmeChecker{Rules: opts.ReadmeRules}
+		}},
+		{Name: "LoaderChecker", Repo: func(client github.GitHubAPI, _ Options) RepoChecker { return &LoaderChecker{Client: client} }},
+		{Name: "KeywordChecker", Repo: func(_ github.GitHubAPI, opts Options) RepoChecker {
+			if opts.SuspiciousTLDs != nil && len(opts.SuspiciousTLDs) == 0 {
+				return nil
+			}
+			return &KeywordChecker{TLDs: opts.SuspiciousTLDs}
+		}},
+		{Name: "DependencyChecker", Repo: func(client github.GitHubAPI, opts Options) RepoChecker {
+			if len(opts.MaliciousPackages) == 0 {
+				return nil
+			}
+			return &DependencyChecker{Client: client, Packages: opts.MaliciousPackages}
+		}},
+		{Name: "CommitMessageChecker", Repo: func(client github.GitHubAPI, opts Options) RepoChecker {
+			if opts.CommitMessageCommits <= 0 {
+				return nil
+			}
+			return &CommitMessageChecker{Client: client, MaxCommits: opts.CommitMessageCommits, Markers: opts.CommitMarkers}
+		}},
+		{Name: "CommitCadenceChecker", Repo: func(client github.GitHubAPI, opts Options) RepoChecker {
+			if opts.CommitCadenceCommits <= 0 {
+				return nil
+			}
+			return &CommitCadenceChecker{
+				Client:       client,
+				MaxCommits:   opts.CommitCadenceCommits,
+				MaxVariation: opts.CommitCadenceMaxVariation,
+				SameSecond:   opts.CommitCadenceSameSecond,
+			}
+		}},
+		{Name: "ManifestChecker", Repo: func(client github.GitHubAPI, opts Options) RepoChecker {
+			if !opts.ManifestCheck {
+				return nil
+			}
+			return &ManifestChecker{Client: client}
+		}},
+		{Name: "WorkflowChecker", Repo: func(client github.GitHubAPI, opts Options) RepoChecker {
+			if opts.WorkflowMaxFiles <= 0 {
+				return nil
+			}
+			return &WorkflowChecker{Client: client, MaxFiles: opts.WorkflowMaxFiles}
+		}},
+		{Name: "PayloadBlobChecker", Repo: func(client github.GitHubAPI, opts Options) RepoChecker {
+			if opts.PayloadBlobMinLength <= 0 {
+				return nil
+			}
+			return &PayloadBlobChecker{Sources: opts.sources, MinLength: opts.PayloadBlobMinLength}
+		}},
+		{Name: "ObfuscationChecker", Repo: func(_ github.GitHubAPI, opts Options) RepoChecker {
+			if opts.ObfuscationThreshold <= 0 {
+				return nil
+			}
+			return &ObfuscationChecker{Sources: opts.sources, Threshold: opts.ObfuscationThreshold}
+		}},
+		{Name: "TopicSpamChecker", Repo: func(_ github.GitHubAPI, opts Options) RepoChecker {
+			if opts.TopicSpamMinTopics <= 0 {
+				return nil
+			}
+			return &TopicSpamChecker{MinTopics: opts.TopicSpamMinTopics}
+		}},
+		{Name: "BinaryOnlyChecker", Repo: func(_ github.GitHubAPI, opts Options) RepoChecker {
+			if opts.BinaryOnlyMinStars <= 0 {
+				return nil
+			}
+			return &BinaryOnlyChecker{MinStars: opts.BinaryOnlyMinStars}
+		}},
+		{Name: "OutboundLinkChecker", Repo: func(_ github.GitHubAPI, opts Options) RepoChecker {
+			if opts.LinkResolver == nil {
+				return nil
+			}
+			return &OutboundLinkChecker{Resolver: opts.LinkResolver, Blocklist: opts.LinkBlocklist, Allowlist: opts.LinkAllowlist}
+		}},
+		{Name: "SingleLanguageHeuristic", Repo: func(github.GitHubAPI, Options) RepoChecker { return &SingleLanguageHeuristic{} }},
+		{Name: "DescriptionChecker", Repo: func(_ github.GitHubAPI, opts Options) RepoChecker {
+			if opts.DescriptionRules != nil && len(opts.DescriptionRules) == 0 {
+				return nil
+			}
+			return &DescriptionChecker{Rules: opts.DescriptionRules}
+		}},
+		{Name: "TyposquatHeuristic", Repo: func(client github.GitHubAPI, opts Options) RepoChecker {
+			if opts.TyposquatMaxOwnerAge <= 0 {
+				return nil
+			}
+			return &TyposquatChecker{Client: client, Names: opts.PopularNames, MaxOwnerAge: opts.TyposquatMaxOwnerAge}
+		}},
+		{Name: "ReleaseAssetHeuristic", Repo: func(client github.GitHubAPI, opts Options) RepoChecker {
+			if opts.ReleaseMaxOwnerAge <= 0 {
+				return nil
+			}
+			return &ReleaseChecker{Client: client, MaxOwnerAge: opts.ReleaseMaxOwnerAge, DownloadRatio: opts.ReleaseDownloadRatio}
+		}},
+	}
+)
+
+// Register adds r to the heuristics and checkers analyzers are built from. Call it from an init
+// function: analyzers created earlier do not see it. It panics when r has no name, reuses a
+// registered name, or does not set exactly one of User and Repo.
+func Register(r Registration) {
+	if r.Name == "" || (r.User == nil) == (r.Repo == nil) {
+		panic(fmt.Sprintf("analyzer: invalid registration %q", r.Name))
+	}
+	registrationsMu.Lock()
+	defer registrationsMu.Unlock()
+	if containsName(registrations, r.Name) {
+		panic(fmt.Sprintf("analyzer: duplicate registration %q", r.Name))
+	}
+	registrations = append(registrations, r)
+}
+
+// Registrations returns the registered heuristics and checkers in evaluation order.
+func Registrations() []Registration {
+	registrationsMu.RLock()
+	defer registrationsMu.RUnlock()
+	return append([]Registration(nil), registrations...)
+}
+
+// IsRegistered reports whether a heuristic or checker is registered under name, ignoring case.
+func IsRegistered(name string) bool {
+	return containsName(Registrations(), name)
+}
+
+// IsUserHeuristic reports whether a user heuristic is registered under name, ignoring case.
+func IsUserHeuristic(name string) bool {
+	for _, r := range Registrations() {
+		if r.User != nil && strings.EqualFold(r.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// activeRegistrations returns the registrations opts turn on: every one that is not
+// experimental, plus those named by EnabledHeuristics, minus those named by DisabledHeuristics.
+func activeRegistrations(opts Options) []Registration {
+	var active []Registration
+	for _, r := range Registrations() {
+		enabled := !r.Experimental || nameListed(opts.EnabledHeuristics, r.Name)
+		if enabled && !nameListed(opts.DisabledHeuristics, r.Name) {
+			active = append(active, r)
+		}
+	}
+	return active
+}
+
+func containsName(registrations []Registration, name string) bool {
+	for _, r := range registrations {
+		if strings.EqualFold(r.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func nameListed(names []string, name string) bool {
+	for _, listed := range names {
+		if strings.EqualFold(listed, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// DefaultReleaseMaxOwnerAge is how young an owner account must be for ReleaseChecker to flag
+	// the executables its releases ship.
+	DefaultReleaseMaxOwnerAge = 14 * 24 * time.Hour
+	// DefaultReleaseDownloadRatio is how many times the repository's star count an asset's
+	// download count must reach before ReleaseChecker flags it.
+	DefaultReleaseDownloadRatio = 100
+	// releaseMinDownloads is the fewest downloads ReleaseChecker counts as inflated, so a
+	// starless repository whose tool a few people fetched is not flagged.
+	releaseMinDownloads = 1000
+)
+
+// executableExtensions are the release assets that run when opened. Unlike payloadExtensions,
+// they leave out archives, which ship honest source and binaries just as often.
+var executableExtensions = map[string]bool{
+	".exe": true, ".scr": true, ".msi": true, ".com": true, ".pif": true, ".bat": true,
+	".cmd": true, ".vbs": true, ".hta": true, ".lnk": true, ".jar": true, ".ps1": true,
+}
+
+// decoyExtensions are the document, media, and archive types a double extension such as
+// setup.pdf.exe pretends the asset is.
+var decoyExtensions = map[string]bool{
+	".pdf": true, ".doc": true, ".docx": true, ".xls": true, ".xlsx": true, ".ppt": true,
+	".pptx": true, ".txt": true, ".rtf": true, ".jpg": true, ".jpeg": true, ".png": true,
+	".gif": true, ".mp3": true, ".mp4": true, ".avi": true, ".zip": true, ".rar": true,
+}
+
+// passwordArchive matches archives named for the password that opens them, such as
+// Pass_2025.rar or key-1234.zip. Lures encrypt the payload so scanners cannot open it and put
+// the password in the name or the README.
+var passwordArchive = regexp.MustCompile(`(?i)(^|[^a-z])(pass|password|passwd|pwd|key)([^a-z].*)?\.(zip|rar|7z)$`)
+
+// ReleaseChecker flags repositories whose release assets look like malware payloads: an
+// executable, screensaver, or installer shipped by an owner younger than MaxOwnerAge; a double
+// extension such as setup.pdf.exe; an archive named for its password, such as pass-2025.rar; and
+// an asset downloaded far more often than the repository was starred, which suggests the
+// downloads come from links planted elsewhere. It reads the release list, which LoaderChecker
+// and the shared payload check also read, and looks up the owner only when an executable is
+// shipped.
+type ReleaseChecker struct {
+	Client github.GitHubAPI
+	// MaxOwnerAge overrides DefaultReleaseMaxOwnerAge when positive.
+	MaxOwnerAge time.Duration
+	// DownloadRatio overrides DefaultReleaseDownloadRatio when positive.
+	DownloadRatio int
+}
+
+// ReleaseResult is the outcome of a release check.
+type ReleaseResult struct {
+	models.HeuristicResult
+	// Assets are the flagged assets with the reasons each was flagged.
+	Assets []models.FlaggedAsset
+}
+
+// Check reports whether any of repo's release assets is flagged.
+func (rc *ReleaseChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := rc.Evaluate(ctx, repo)
+	return result.Flag, err
+}
+
+// Evaluate lists repo's release assets and flags the repository when any of them matches.
+func (rc *ReleaseChecker) Evaluate(ctx context.Context, repo models.RepoData) (ReleaseResult, error) {
+	result := ReleaseResult{HeuristicResult: models.HeuristicResult{
+		Category:    "Malware",
+		Name:        "ReleaseAssetHeuristic",
+		Description: "Release assets do not look like malware payloads.",
+	}}
+	assets, err := rc.Client.GetReleaseAssets(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		return result, err
+	}
+
+	ratio := rc.DownloadRatio
+	if ratio <= 0 {
+		ratio = DefaultReleaseDownloadRatio
+	}
+	reasons := make([][]string, len(assets))
+	shipsExecutable := false
+	for i, asset := range assets {
+		reasons[i] = releaseAssetReasons(asset, repo.StargazerCount, ratio)
+		shipsExecutable = shipsExecutable || executableExtensions[strings.ToLower(path.Ext(asset.Name))]
+	}
+	if shipsExecutable {
+		young, err := rc.youngOwner(ctx, repo)
+		if err != nil {
+			return result, err
+		}
+		for i, asset := range assets {
+			if young != "" && executableExtensions[strings.ToLower(path.Ext(asset.Name))] {
+				reasons[i] = append(reasons[i], "executable from an account "+young+" old")
+			}
+		}
+	}
+
+	var descriptions []string
+	for i, asset := range assets {
+		if len(reasons[i]) == 0 {
+			continue
+		}
+		result.Assets = append(result.Assets, models.FlaggedAsset{ReleaseAsset: asset, Reasons: reasons[i]})
+		descriptions = append(descriptions, fmt.Sprintf("%s (%d bytes, %d downloads: %s)",
+			asset.Name, asset.Size, asset.DownloadCount, strings.Join(reasons[i], "; ")))
+	}
+	if len(result.Assets) > 0 {
+		result.Flag = true
+		result.Description = fmt.Sprintf("Releases ship %s that look like malware payloads: %s.",
+			pluralize(len(result.Assets), "asset", "assets"), strings.Join(descriptions, ", "))
+	}
+	return result, nil
+}
+
+// youngOwner returns the age of repo's owner in days when the account is younger than the
+// limit, and "" otherwise.
+func (rc *ReleaseChecker) youngOwner(ctx context.Context, repo models.RepoData) (string, error) {
+	maxAge := rc.MaxOwnerAge
+	if maxAge <= 0 {
+		maxAge = DefaultReleaseMaxOwnerAge
+	}
+	info, err := rc.Client.GetUserInfo(ctx, repo.Owner)
+	if err != nil {
+		return "", fmt.Errorf("fetching owner of %s/%s: %w", repo.Owner, repo.Name, err)
+	}
+	age := time.Since(info.CreatedAt)
+	if info.CreatedAt.IsZero() || age >= maxAge {
+		return "", nil
+	}
+	return pluralize(int(age.Hours()/24), "day", "days"), nil
+}
+
+// releaseAssetReasons describes why asset looks like a payload, judged by its name and
+// download count alone.
+func releaseAssetReasons(asset models.ReleaseAsset, stars, ratio int) []string {
+	var reasons []string
+	lower := strings.ToLower(asset.Name)
+	ext := path.Ext(lower)
+	if decoy := path.Ext(strings.TrimSuffix(lower, ext)); executableExtensions[ext] && decoyExtensions[decoy] {
+		reasons = append(reasons, fmt.Sprintf("double extension %s%s", decoy, ext))
+	}
+	if passwordArchive.MatchString(lower) {
+		reasons = append(reasons, "archive named for its password")
+	}
+	if asset.DownloadCount >= releaseMinDownloads && asset.DownloadCount > ratio*max(stars, 1) {
+		reasons = append(reasons, fmt.Sprintf("%d downloads against %s", asset.DownloadCount, pluralize(stars, "star", "stars")))
+	}
+	return reasons
+}
+
+// CheckReleases runs the release asset check. enabled is false when it is not configured.
+func (a *Analyzer) CheckReleases(ctx context.Context, repo models.RepoData) (result ReleaseResult, enabled bool, err error) {
+	if a.releases == nil {
+		return ReleaseResult{}, false, nil
+	}
+	result, err = a.releases.Evaluate(ctx, repo)
+	return result, true, err
+}
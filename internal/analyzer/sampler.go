@@ -0,0 +1,137 @@
+package analyzer
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// DefaultSourceSampleMaxFiles caps the source files SourceSampler fetches per repository, one
+	// request each.
+	DefaultSourceSampleMaxFiles = 10
+	// DefaultSourceSampleMaxBytes caps the file contents SourceSampler downloads per repository.
+	DefaultSourceSampleMaxBytes = 1 << 20
+	// DefaultSourceSampleMaxRepoSize is the disk usage in KB above which SourceSampler reads
+	// nothing. Payload droppers are small, and large projects are costly to sample.
+	DefaultSourceSampleMaxRepoSize = 5000
+)
+
+// sourceExtensions are the script and source files SourceSampler reads. Images, fonts,
+// archives, and other binaries are never fetched.
+var sourceExtensions = map[string]bool{
+	".py": true, ".pyw": true, ".js": true, ".mjs": true, ".cjs": true, ".ts": true,
+	".ps1": true, ".psm1": true, ".bat": true, ".cmd": true, ".vbs": true, ".sh": true,
+	".php": true, ".rb": true, ".lua": true, ".go": true, ".cs": true, ".java": true,
+}
+
+// SourceFile is a file SourceSampler read.
+type SourceFile struct {
+	Path    string
+	Content string
+}
+
+// SourceSampler reads the script and source files of small repositories for the checkers that
+// inspect them, PayloadBlobChecker and ObfuscationChecker. It reads up to MaxFiles files from
+// the tree, skipping vendored and minified files, and stops once MaxBytes have been downloaded.
+// The checkers share one sample per repository, so together they cost no more requests than
+// either alone.
+type SourceSampler struct {
+	Client github.GitHubAPI
+	// MaxFiles and MaxBytes bound the downloads per repository. Zero uses
+	// DefaultSourceSampleMaxFiles and DefaultSourceSampleMaxBytes.
+	MaxFiles int
+	MaxBytes int
+	// MaxRepoSize skips repositories using more disk in KB. Zero uses
+	// DefaultSourceSampleMaxRepoSize.
+	MaxRepoSize int
+
+	mu      sync.Mutex
+	samples map[string]*sourceSample
+}
+
+type sourceSample struct {
+	once  sync.Once
+	files []SourceFile
+	err   error
+}
+
+// Files returns the sampled files of repo, reading them on the first call. Later calls for
+// the same repository reuse the sample until Forget drops it. Missing files are skipped.
+func (s *SourceSampler) Files(ctx context.Context, repo models.RepoData) ([]SourceFile, error) {
+	key := repo.Owner + "/" + repo.Name
+	s.mu.Lock()
+	if s.samples == nil {
+		s.samples = make(map[string]*sourceSample)
+	}
+	sample, ok := s.samples[key]
+	if !ok {
+		sample = &sourceSample{}
+		s.samples[key] = sample
+	}
+	s.mu.Unlock()
+
+	sample.once.Do(func() {
+		sample.files, sample.err = s.read(ctx, repo)
+	})
+	return sample.files, sample.err
+}
+
+// Forget drops the sample of repo, so the next Files call reads it again.
+func (s *SourceSampler) Forget(repo models.RepoData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.samples, repo.Owner+"/"+repo.Name)
+}
+
+func (s *SourceSampler) read(ctx context.Context, repo models.RepoData) ([]SourceFile, error) {
+	maxFiles, maxBytes, maxRepoSize := s.MaxFiles, s.MaxBytes, s.MaxRepoSize
+	if maxFiles <= 0 {
+		maxFiles = DefaultSourceSampleMaxFiles
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultSourceSampleMaxBytes
+	}
+	if maxRepoSize <= 0 {
+		maxRepoSize = DefaultSourceSampleMaxRepoSize
+	}
+	if repo.DiskUsage > maxRepoSize {
+		return nil, nil
+	}
+
+	var files []SourceFile
+	fetched, downloaded := 0, 0
+	for _, entry := range repo.TreeEntries {
+		if !isSourceCandidate(entry) {
+			continue
+		}
+		if fetched == maxFiles || downloaded >= maxBytes {
+			break
+		}
+		fetched++
+		content, err := s.Client.GetFileContent(ctx, repo.Owner, repo.Name, entry, "")
+		if err != nil {
+			return files, err
+		}
+		downloaded += len(content)
+		if content != "" {
+			files = append(files, SourceFile{Path: entry, Content: content})
+		}
+	}
+	return files, nil
+}
+
+// isSourceCandidate reports whether SourceSampler reads the file at entry: a script or source
+// file outside vendored directories that is not minified.
+func isSourceCandidate(entry string) bool {
+	lower := strings.ToLower(entry)
+	if !sourceExtensions[path.Ext(lower)] || strings.Contains(path.Base(lower), ".min.") {
+		return false
+	}
+	wrapped := "/" + lower
+	return !strings.Contains(wrapped, "/node_modules/") && !strings.Contains(wrapped, "/vendor/")
+}
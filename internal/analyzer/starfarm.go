@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// DefaultStarFarmSampleSize is how many of a repository's first stargazers StarFarmChecker
+	// looks up.
+	DefaultStarFarmSampleSize = 30
+	// DefaultStarFarmMaxMedianAge is the median stargazer account age below which
+	// StarFarmHeuristic flags a repository.
+	DefaultStarFarmMaxMedianAge = 7 * 24 * time.Hour
+	// DefaultStarFarmMinStars is how many stars an otherwise unflagged, empty repository needs
+	// before its stargazers are looked up.
+	DefaultStarFarmMinStars = 10
+	// minStarFarmSample is the smallest number of looked-up stargazers worth judging.
+	minStarFarmSample = 5
+)
+
+// StarFarmChecker looks up the accounts behind a repository's first stargazers and measures how
+// old each was when it starred. Malicious repositories are often starred within hours by
+// accounts registered days earlier. It costs one request for the stargazers and one per
+// sampled account.
+type StarFarmChecker struct {
+	Client github.GitHubAPI
+	// SampleSize overrides DefaultStarFarmSampleSize when positive.
+	SampleSize int
+	// MaxMedianAge overrides DefaultStarFarmMaxMedianAge when positive.
+	MaxMedianAge time.Duration
+}
+
+// StarFarmResult is the outcome of a star farm check.
+type StarFarmResult struct {
+	models.HeuristicResult
+	// MedianAge is the median account age at starring over the accounts that could be looked up.
+	MedianAge time.Duration
+	// Stargazers are the sampled stargazers, including accounts that could not be looked up.
+	Stargazers []models.Stargazer
+}
+
+// Evaluate samples repo's first stargazers and flags the repository when the median account
+// age at starring is under the limit. Accounts that cannot be looked up are left out of the
+// median.
+func (sc *StarFarmChecker) Evaluate(ctx context.Context, repo models.RepoData) (StarFarmResult, error) {
+	result := StarFarmResult{HeuristicResult: models.HeuristicResult{
+		Category:    "Automated Activity",
+		Name:        "StarFarmHeuristic",
+		Description: "Most sampled stargazers created their accounts shortly before starring.",
+	}}
+
+	sampleSize := sc.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = DefaultStarFarmSampleSize
+	}
+	stargazers, err := sc.Client.GetStargazers(ctx, repo.Owner, repo.Name, sampleSize)
+	if err != nil {
+		return result, err
+	}
+	result.Stargazers = stargazers
+
+	now := time.Now()
+	var ages []time.Duration
+	for _, stargazer := range stargazers {
+		info, err := sc.Client.GetUserInfo(ctx, stargazer.Login)
+		if err != nil || info.CreatedAt.IsZero() {
+			continue
+		}
+		starredAt := stargazer.StarredAt
+		if starredAt.IsZero() {
+			starredAt = now
+		}
+		ages = append(ages, max(starredAt.Sub(info.CreatedAt), 0))
+	}
+	if len(ages) == 0 {
+		return result, nil
+	}
+	result.MedianAge = medianDuration(ages)
+
+	maxAge := sc.MaxMedianAge
+	if maxAge <= 0 {
+		maxAge = DefaultStarFarmMaxMedianAge
+	}
+	if len(ages) >= minStarFarmSample && result.MedianAge < maxAge {
+		result.Flag = true
+		result.Description = fmt.Sprintf("The median age of %d sampled stargazer accounts was %s when they starred.",
+			len(ages), formatAge(result.MedianAge))
+	}
+	return result, nil
+}
+
+// medianDuration returns the median of ages, which it sorts in place.
+func medianDuration(ages []time.Duration) time.Duration {
+	sort.Slice(ages, func(i, j int) bool { return ages[i] < ages[j] })
+	mid := len(ages) / 2
+	if len(ages)%2 == 0 {
+		return (ages[mid-1] + ages[mid]) / 2
+	}
+	return ages[mid]
+}
+
+// formatAge renders an account age in days, or in whole hours under a day.
+func formatAge(age time.Duration) string {
+	if age < 24*time.Hour {
+		return fmt.Sprintf("%d hours", int(age.Hours()))
+	}
+	return fmt.Sprintf("%.1f days", age.Hours()/24)
+}
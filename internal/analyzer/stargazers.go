@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// DefaultLoneStargazerThreshold is the share of sampled stargazers that must have starred
+	// nothing else before LoneStargazerHeuristic flags a repository.
+	DefaultLoneStargazerThreshold = 0.6
+	// minLoneStargazerSample is the smallest stargazer sample worth judging.
+	minLoneStargazerSample = 5
+)
+
+// LoneStargazerChecker samples a repository's stargazers and counts those whose only starred
+// repository is this one, a common trait of sockpuppet accounts bought to inflate stars. It
+// costs one request for the stargazers and one per sampled account.
+type LoneStargazerChecker struct {
+	Client github.GitHubAPI
+	// SampleSize caps the stargazers inspected.
+	SampleSize int
+	// Threshold overrides DefaultLoneStargazerThreshold when positive.
+	Threshold float64
+}
+
+// Evaluate samples repo's stargazers and returns the flag with the share of sampled accounts
+// that starred nothing else. Accounts whose stars cannot be listed are left out of the sample.
+func (lc *LoneStargazerChecker) Evaluate(ctx context.Context, repo models.RepoData) (models.HeuristicResult, float64, error) {
+	result := models.HeuristicResult{
+		Category:    "Automated Activity",
+		Name:        "LoneStargazerHeuristic",
+		Description: "Most sampled stargazers have starred no other repository.",
+	}
+
+	stargazers, err := lc.Client.GetStargazers(ctx, repo.Owner, repo.Name, lc.SampleSize)
+	if err != nil {
+		return result, 0, err
+	}
+	sampled, lone := 0, 0
+	for _, stargazer := range stargazers {
+		// Two is enough to tell "this repository only" from anything more.
+		count, err := lc.Client.GetUserStarred(ctx, stargazer.Login, 2)
+		if err != nil {
+			continue
+		}
+		sampled++
+		if count <= 1 {
+			lone++
+		}
+	}
+
+	fraction := loneStargazerFraction(lone, sampled)
+	threshold := lc.Threshold
+	if threshold <= 0 {
+		threshold = DefaultLoneStargazerThreshold
+	}
+	if sampled >= minLoneStargazerSample && fraction >= threshold {
+		result.Flag = true
+		result.Description = fmt.Sprintf("%d of %d sampled stargazers have starred no other repository.", lone, sampled)
+	}
+	return result, fraction, nil
+}
+
+// loneStargazerFraction is the share of sampled stargazers that starred nothing else.
+func loneStargazerFraction(lone, sampled int) float64 {
+	if sampled == 0 {
+		return 0
+	}
+	return float64(lone) / float64(sampled)
+}
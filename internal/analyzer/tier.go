@@ -0,0 +1,30 @@
+package analyzer
+
+import "github.com/arkouda/github/GitHubWatchdog/internal/models"
+
+// DefaultTierHeuristics are the user heuristics whose agreement grades a suspicious user.
+var DefaultTierHeuristics = []string{"OriginalHeuristic", "NewHeuristic", "RecentHeuristic"}
+
+// UserTier grades a user by how many of the tier heuristics flagged: all of them is high, more
+// than one is medium, and one is low. It returns an empty tier when none flagged.
+func UserTier(results []models.HeuristicResult, heuristics []string) string {
+	fired := 0
+	for _, name := range heuristics {
+		for _, result := range results {
+			if result.Flag && result.Name == name {
+				fired++
+				break
+			}
+		}
+	}
+	switch {
+	case fired == 0:
+		return ""
+	case fired == len(heuristics):
+		return models.SeverityHigh
+	case fired > 1:
+		return models.SeverityMedium
+	default:
+		return models.SeverityLow
+	}
+}
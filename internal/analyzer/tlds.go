@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"context"
+	_ "embed" // embeds the default suspicious TLD list
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+//go:embed suspicious_tlds.txt
+var defaultSuspiciousTLDs string
+
+// DefaultSuspiciousTLDs are the top-level domains KeywordChecker flags links to when none are
+// configured.
+var DefaultSuspiciousTLDs = parseTLDList(defaultSuspiciousTLDs)
+
+// parseTLDList reads one TLD per line, skipping blank lines and # comments.
+func parseTLDList(list string) []string {
+	var tlds []string
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tlds = append(tlds, NormalizeTLD(line))
+	}
+	return tlds
+}
+
+// NormalizeTLD lowercases tld and drops a leading dot, so ".XYZ" and "xyz" match alike.
+func NormalizeTLD(tld string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(tld)), ".")
+}
+
+// KeywordChecker flags repositories whose README links to hosts under suspicious top-level
+// domains. It matches the hostname of each link rather than the README text, so ".us" does not
+// match inside "status" and a path or query mentioning a TLD is ignored.
+type KeywordChecker struct {
+	// TLDs overrides DefaultSuspiciousTLDs when non-nil. Entries may carry a leading dot and
+	// may span labels, such as "co.in".
+	TLDs []string
+}
+
+func (kc *KeywordChecker) tlds() []string {
+	if kc.TLDs == nil {
+		return DefaultSuspiciousTLDs
+	}
+	return kc.TLDs
+}
+
+// Check evaluates a repository's README links.
+func (kc *KeywordChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := kc.Run(ctx, repo)
+	return result.Flagged, err
+}
+
+// Run evaluates a repository's README links, naming each offending URL in the evidence.
+func (kc *KeywordChecker) Run(_ context.Context, repo models.RepoData) (models.CheckerResult, error) {
+	result := models.CheckerResult{Name: "KeywordChecker", Severity: models.SeverityMedium}
+	var matches []string
+	for _, link := range ExtractLinks(repo.Readme) {
+		if tld := kc.suspiciousTLD(link); tld != "" {
+			matches = append(matches, fmt.Sprintf("%s (.%s)", link, tld))
+		}
+	}
+	if len(matches) == 0 {
+		return result, nil
+	}
+	result.Flagged = true
+	listed := matches
+	if len(listed) > maxListedRepoNames {
+		listed = listed[:maxListedRepoNames]
+	}
+	result.Evidence = fmt.Sprintf("README links to %s under suspicious TLDs: %s.", pluralize(len(matches), "URL", "URLs"), strings.Join(listed, ", "))
+	return result, nil
+}
+
+// suspiciousTLD returns the configured TLD the hostname of link falls under, or "" when none
+// does or the link does not parse.
+func (kc *KeywordChecker) suspiciousTLD(link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "" {
+		return ""
+	}
+	for _, tld := range kc.tlds() {
+		tld = NormalizeTLD(tld)
+		if tld != "" && strings.HasSuffix(host, "."+tld) {
+			return tld
+		}
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// DefaultTopicSpamMinTopics is how many topics a repository must carry before
+	// TopicSpamChecker judges them. GitHub allows 20.
+	DefaultTopicSpamMinTopics = 10
+	// topicSpamMinCategories is how many unrelated taxonomy categories the topics must reach.
+	topicSpamMinCategories = 3
+	// topicSpamMaxFiles is the most files a repository may hold and still count as nearly empty.
+	topicSpamMaxFiles = 5
+)
+
+// topicTaxonomy groups the trending topics spam repositories collect to appear on topic pages.
+// A real project's topics rarely span more than one or two of these groups.
+var topicTaxonomy = []struct {
+	Category string
+	Topics   []string
+}{
+	{"AI", []string{"ai", "chatgpt", "openai", "gpt", "gpt-4", "gpt4", "llm", "claude", "gemini", "midjourney", "stable-diffusion", "deepseek", "copilot"}},
+	{"crypto", []string{"crypto", "cryptocurrency", "bitcoin", "btc", "ethereum", "eth", "solana", "nft", "web3", "defi", "airdrop", "wallet", "metamask", "binance", "usdt"}},
+	{"games", []string{"fortnite", "roblox", "minecraft", "valorant", "gta", "gta5", "cs2", "csgo", "apex-legends", "warzone", "pubg", "rust-game", "genshin-impact", "league-of-legends"}},
+	{"cheats", []string{"hack", "hacks", "cheat", "cheats", "aimbot", "esp", "wallhack", "exploit", "injector", "executor", "spoofer", "unlocker", "triggerbot"}},
+	{"piracy", []string{"crack", "cracked", "keygen", "activator", "free-download", "adobe", "photoshop", "premiere-pro", "windows-activator", "office-activator", "serial-key"}},
+	{"social", []string{"discord", "telegram", "instagram", "tiktok", "youtube", "twitter", "spotify", "netflix", "whatsapp", "nitro"}},
+	{"trading", []string{"trading", "trading-bot", "forex", "sniper-bot", "arbitrage", "mev-bot", "pump", "signals"}},
+}
+
+// TopicSpamChecker flags nearly empty repositories whose topics are a grab bag of unrelated
+// trending subjects, such as chatgpt, crypto, fortnite, and hack, attached so the repository
+// shows up on many topic pages. It needs at least MinTopics topics matching at least three
+// categories of a small taxonomy, on a repository of at most five files. It reads only the
+// topics CheckRepoFiles already fetched, and reports at low severity.
+type TopicSpamChecker struct {
+	// MinTopics overrides DefaultTopicSpamMinTopics when positive.
+	MinTopics int
+}
+
+// Check evaluates a repository's topics.
+func (tc *TopicSpamChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := tc.Run(ctx, repo)
+	return result.Flagged, err
+}
+
+// Run evaluates a repository's topics.
+func (tc *TopicSpamChecker) Run(_ context.Context, repo models.RepoData) (models.CheckerResult, error) {
+	result := models.CheckerResult{Name: "TopicSpamChecker", Severity: models.SeverityLow}
+	minTopics := tc.MinTopics
+	if minTopics <= 0 {
+		minTopics = DefaultTopicSpamMinTopics
+	}
+	if len(repo.Topics) < minTopics || len(repo.TreeEntries) > topicSpamMaxFiles {
+		return result, nil
+	}
+	categories := TopicCategories(repo.Topics)
+	if len(categories) < topicSpamMinCategories {
+		return result, nil
+	}
+	result.Flagged = true
+	result.Evidence = fmt.Sprintf("%s on a repository of %s span %d unrelated categories: %s.",
+		pluralize(len(repo.Topics), "topic", "topics"), pluralize(len(repo.TreeEntries), "file", "files"),
+		len(categories), strings.Join(categories, "; "))
+	return result, nil
+}
+
+// TopicCategories describes the taxonomy categories topics fall in, in taxonomy order, such as
+// "crypto (bitcoin, nft)". Topics outside the taxonomy are left out.
+func TopicCategories(topics []string) []string {
+	var categories []string
+	for _, group := range topicTaxonomy {
+		var matched []string
+		for _, topic := range topics {
+			if topic = strings.ToLower(strings.TrimSpace(topic)); slices.Contains(group.Topics, topic) {
+				matched = append(matched, topic)
+			}
+		}
+		if len(matched) > 0 {
+			categories = append(categories, fmt.Sprintf("%s (%s)", group.Category, strings.Join(matched, ", ")))
+		}
+	}
+	return categories
+}
@@ -0,0 +1,248 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	_ "embed" // embeds the default popular name list
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+// DefaultTyposquatMaxOwnerAge is how young an owner account must be for TyposquatChecker to flag
+// its look-alike repository names.
+const DefaultTyposquatMaxOwnerAge = 90 * 24 * time.Hour
+
+// minTyposquatLength is the shortest popular name TyposquatChecker matches typos against. Shorter
+// names sit within an edit or two of countless honest ones.
+const minTyposquatLength = 4
+
+//go:embed popular_names.txt
+var defaultPopularNames string
+
+// DefaultPopularNames are the names TyposquatChecker compares repositories against when none are
+// configured.
+var DefaultPopularNames, _ = ParseNameList(defaultPopularNames)
+
+// baitSuffixes are the words campaigns append to a popular name to promise a free or cracked
+// copy, such as teslamate-pro or discord-nitro-generator.
+var baitSuffixes = []string{
+	"free", "crack", "cracked", "keygen", "activator", "unlocked", "premium", "pro", "full",
+	"generator", "gen", "hack", "hacks", "cheat", "cheats", "mod", "menu", "nitro", "bypass",
+}
+
+var repoNamePattern = regexp.MustCompile(`^[a-z0-9._-]+$`)
+
+// NameList is a set of popular repository or package names, normalized by normalizeRepoName.
+type NameList map[string]bool
+
+// NewNameList builds a NameList from names, ignoring case and treating _ and . like -.
+func NewNameList(names ...string) NameList {
+	list := NameList{}
+	for _, name := range names {
+		if name = normalizeRepoName(name); name != "" {
+			list[name] = true
+		}
+	}
+	return list
+}
+
+// ParseNameList reads one repository or package name per line, such as "requests" or
+// "discord.js". Blank lines and lines starting with # are skipped.
+func ParseNameList(data string) (NameList, error) {
+	list := NameList{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if !repoNamePattern.MatchString(strings.ToLower(entry)) {
+			return nil, fmt.Errorf("name list line %d: expected a repository name, got %q", line, entry)
+		}
+		list[normalizeRepoName(entry)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading name list: %w", err)
+	}
+	return list, nil
+}
+
+// normalizeRepoName lowercases name and writes its separators as -, so requests_toolbelt and
+// requests.toolbelt compare equal.
+func normalizeRepoName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Trim(strings.NewReplacer("_", "-", ".", "-").Replace(name), "-")
+}
+
+// TyposquatChecker flags repositories named after a popular project with a small twist, the way
+// campaigns ride search traffic: a name one or two edits away such as requsts, a separator
+// inserted or dropped, a doubled letter, or a bait suffix such as -free or -crack. Only owners
+// younger than MaxOwnerAge are flagged, since established accounts rarely typosquat. The owner is
+// looked up only when the name matches, at one request.
+type TyposquatChecker struct {
+	Client github.GitHubAPI
+	// Names overrides DefaultPopularNames when non-nil.
+	Names NameList
+	// MaxOwnerAge overrides DefaultTyposquatMaxOwnerAge when positive.
+	MaxOwnerAge time.Duration
+}
+
+// Check reports whether repo's name is flagged.
+func (tc *TyposquatChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := tc.Evaluate(ctx, repo)
+	return result.Flag, err
+}
+
+// Evaluate returns the flag for repo's name.
+func (tc *TyposquatChecker) Evaluate(ctx context.Context, repo models.RepoData) (models.HeuristicResult, error) {
+	result := models.HeuristicResult{
+		Category:    "Spam Behavior",
+		Name:        "TyposquatHeuristic",
+		Description: "Repository name does not imitate a popular name.",
+	}
+	names := tc.Names
+	if names == nil {
+		names = DefaultPopularNames
+	}
+	popular, technique, ok := MatchPopularName(repo.Name, names)
+	if !ok {
+		return result, nil
+	}
+
+	maxAge := tc.MaxOwnerAge
+	if maxAge <= 0 {
+		maxAge = DefaultTyposquatMaxOwnerAge
+	}
+	info, err := tc.Client.GetUserInfo(ctx, repo.Owner)
+	if err != nil {
+		return result, fmt.Errorf("fetching owner of %s/%s: %w", repo.Owner, repo.Name, err)
+	}
+	age := time.Since(info.CreatedAt)
+	if age >= maxAge {
+		return result, nil
+	}
+	result.Flag = true
+	result.Description = fmt.Sprintf("Repository name %q imitates the popular name %q (%s), and the owner account is %s old.",
+		repo.Name, popular, technique, pluralize(int(age.Hours()/24), "day", "days"))
+	return result, nil
+}
+
+// MatchPopularName returns the popular name that name imitates and how, or ok false when it
+// imitates none. A name that is itself on the list imitates nothing.
+func MatchPopularName(name string, popular NameList) (match, technique string, ok bool) {
+	candidate := normalizeRepoName(name)
+	if candidate == "" || popular[candidate] {
+		return "", "", false
+	}
+	base, suffix := stripBaitSuffixes(candidate)
+	if suffix != "" && popular[base] {
+		return base, fmt.Sprintf("bait suffix %q", suffix), true
+	}
+	match, technique, ok = closestPopularName(base, popular)
+	if ok && suffix != "" {
+		technique += fmt.Sprintf(" and bait suffix %q", suffix)
+	}
+	return match, technique, ok
+}
+
+// stripBaitSuffixes drops trailing bait words from a normalized name and returns them as the
+// suffix, such as "-nitro-generator" for discord-nitro-generator.
+func stripBaitSuffixes(name string) (base, suffix string) {
+	base = name
+	for {
+		i := strings.LastIndex(base, "-")
+		if i <= 0 || !slices.Contains(baitSuffixes, base[i+1:]) {
+			return base, strings.TrimPrefix(name, base)
+		}
+		base = base[:i]
+	}
+}
+
+// closestPopularName finds the popular name candidate is a typo of, preferring a separator
+// change, then a doubled or dropped letter, then the fewest edits. Ties go to the name that
+// sorts first, so the result does not depend on map order.
+func closestPopularName(candidate string, popular NameList) (match, technique string, ok bool) {
+	collapsed := strings.ReplaceAll(candidate, "-", "")
+	squeezed := squeezeRepeats(collapsed)
+	bestRank := 0
+	for name := range popular {
+		target := strings.ReplaceAll(name, "-", "")
+		if len(target) < minTyposquatLength || name == candidate {
+			continue
+		}
+		rank, how := 0, ""
+		switch {
+		case collapsed == target:
+			rank, how = 1, "separator inserted or dropped"
+		case squeezed == squeezeRepeats(target):
+			rank, how = 2, "doubled or dropped letter"
+		default:
+			limit := 1
+			if len(target) >= 8 {
+				limit = 2
+			}
+			if len(target) < 5 || abs(len(collapsed)-len(target)) > limit {
+				continue
+			}
+			distance := editDistance(collapsed, target)
+			if distance > limit {
+				continue
+			}
+			rank, how = 2+distance, fmt.Sprintf("edit distance %d", distance)
+		}
+		if !ok || rank < bestRank || (rank == bestRank && name < match) {
+			match, technique, bestRank, ok = name, how, rank, true
+		}
+	}
+	return match, technique, ok
+}
+
+// squeezeRepeats collapses runs of a repeated character, so "reqquests" becomes "requests".
+func squeezeRepeats(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if i == 0 || s[i] != s[i-1] {
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// editDistance is the optimal string alignment distance between a and b: insertions,
+// deletions, substitutions, and swaps of adjacent characters each cost one.
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
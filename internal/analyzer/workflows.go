@@ -0,0 +1,230 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// DefaultWorkflowMaxFiles caps the workflow files WorkflowChecker fetches per repository, one
+	// request each.
+	DefaultWorkflowMaxFiles = 5
+	// workflowMatrixJobs is the matrix size WorkflowChecker flags. Real build matrices cross a
+	// few operating systems and versions; miners fan out to GitHub's cap of 256 jobs.
+	workflowMatrixJobs = 100
+	// workflowCronRunsPerHour is how often per hour a schedule may fire before WorkflowChecker
+	// flags it, so every ten minutes or more often.
+	workflowCronRunsPerHour = 6
+)
+
+// workflowMiners are mining tools and pool protocols no build needs, matched ignoring case.
+var workflowMiners = []string{"xmrig", "xmr-stak", "cpuminer", "ethminer", "nbminer", "lolminer", "phoenixminer", "stratum+tcp://", "stratum+ssl://"}
+
+var (
+	// pipeToShell matches a download piped straight into a shell.
+	pipeToShell  = regexp.MustCompile(`(?i)\b(curl|wget)\b[^\n|]*\|\s*(sudo\s+)?(ba|z|da)?sh\b`)
+	workflowCron = regexp.MustCompile(`(?m)^\s*-?\s*cron:\s*['"]?([^'"\n#]+)`)
+	matrixKey    = regexp.MustCompile(`^(\s*)matrix:\s*$`)
+	yamlKey      = regexp.MustCompile(`^(\s*)([\w-]+):\s*(.*)$`)
+)
+
+// WorkflowChecker flags repositories whose GitHub Actions workflows abuse the runners, usually to
+// mine cryptocurrency: they name a mining tool or pool, pipe a downloaded script into a shell,
+// fan out to a matrix of at least 100 jobs, or run on a schedule every few minutes. It reads the
+// workflow files as text rather than parsing the YAML. Mining indicators report at high
+// severity, the others at medium.
+type WorkflowChecker struct {
+	Client github.GitHubAPI
+	// MaxFiles caps the workflow files read. Zero uses DefaultWorkflowMaxFiles.
+	MaxFiles int
+}
+
+// Check evaluates a repository's GitHub Actions workflows.
+func (wc *WorkflowChecker) Check(ctx context.Context, repo models.RepoData) (bool, error) {
+	result, err := wc.Run(ctx, repo)
+	return result.Flagged, err
+}
+
+// Run evaluates a repository's GitHub Actions workflows on its default branch.
+func (wc *WorkflowChecker) Run(ctx context.Context, repo models.RepoData) (models.CheckerResult, error) {
+	result := models.CheckerResult{Name: "WorkflowChecker", Severity: models.SeverityMedium}
+	limit := wc.MaxFiles
+	if limit <= 0 {
+		limit = DefaultWorkflowMaxFiles
+	}
+	var matches []string
+	fetched := 0
+	for _, entry := range repo.TreeEntries {
+		if !isWorkflowFile(entry) {
+			continue
+		}
+		if fetched == limit {
+			break
+		}
+		fetched++
+		content, err := wc.Client.GetFileContent(ctx, repo.Owner, repo.Name, entry, "")
+		if err != nil {
+			return result, err
+		}
+		indicators, mining := workflowIndicators(content)
+		if mining {
+			result.Severity = models.SeverityHigh
+		}
+		for _, indicator := range indicators {
+			matches = append(matches, fmt.Sprintf("%s (%s)", entry, indicator))
+		}
+	}
+	if len(matches) > 0 {
+		result.Flagged = true
+		result.Evidence = fmt.Sprintf("Workflows abuse Actions runners: %s.", strings.Join(matches, ", "))
+	}
+	return result, nil
+}
+
+func isWorkflowFile(entry string) bool {
+	ext := strings.ToLower(path.Ext(entry))
+	return path.Dir(entry) == ".github/workflows" && (ext == ".yml" || ext == ".yaml")
+}
+
+// workflowIndicators describes each suspicious thing in a workflow file, and reports whether one
+// of them is a mining tool or pool.
+func workflowIndicators(content string) (indicators []string, mining bool) {
+	lower := strings.ToLower(content)
+	for _, miner := range workflowMiners {
+		if strings.Contains(lower, miner) {
+			indicators = append(indicators, fmt.Sprintf("mining indicator %q", miner))
+			mining = true
+		}
+	}
+	if match := pipeToShell.FindString(content); match != "" {
+		indicators = append(indicators, fmt.Sprintf("pipes a download into a shell: %q", strings.TrimSpace(match)))
+	}
+	if jobs := largestMatrix(content); jobs >= workflowMatrixJobs {
+		indicators = append(indicators, fmt.Sprintf("matrix of %d jobs", jobs))
+	}
+	for _, match := range workflowCron.FindAllStringSubmatch(content, -1) {
+		schedule := strings.TrimSpace(match[1])
+		if runs := cronRunsPerHour(schedule); runs >= workflowCronRunsPerHour {
+			indicators = append(indicators, fmt.Sprintf("schedule %q runs %d times an hour", schedule, runs))
+		}
+	}
+	return indicators, mining
+}
+
+// largestMatrix returns the job count of the largest strategy matrix in a workflow: the product
+// of the lengths of its lists, written either inline as [a, b] or as a block of - items. The
+// include and exclude keys are ignored.
+func largestMatrix(content string) int {
+	lines := strings.Split(content, "\n")
+	largest := 0
+	for i, line := range lines {
+		m := matrixKey.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		jobs, found := 1, false
+		baseIndent := len(m[1])
+		keyIndent := -1
+		key, items := "", 0
+		flush := func() {
+			if key != "" && key != "include" && key != "exclude" && items > 0 {
+				jobs *= items
+				found = true
+			}
+			key, items = "", 0
+		}
+		for _, next := range lines[i+1:] {
+			trimmed := strings.TrimSpace(next)
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			indent := len(next) - len(strings.TrimLeft(next, " \t"))
+			if indent <= baseIndent {
+				break
+			}
+			if keyIndent < 0 {
+				keyIndent = indent
+			}
+			if indent == keyIndent {
+				flush()
+				km := yamlKey.FindStringSubmatch(next)
+				if km == nil {
+					continue
+				}
+				key = km[2]
+				if value := strings.TrimSpace(km[3]); strings.HasPrefix(value, "[") {
+					items = inlineListLength(value)
+				}
+				continue
+			}
+			if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+				items++
+			}
+		}
+		flush()
+		if found && jobs > largest {
+			largest = jobs
+		}
+	}
+	return largest
+}
+
+// inlineListLength counts the items of an inline YAML list such as [1, 2, 3].
+func inlineListLength(value string) int {
+	inner := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+	if inner == "" {
+		return 0
+	}
+	return strings.Count(inner, ",") + 1
+}
+
+// cronRunsPerHour is how many times per hour the minute field of a five-field cron schedule
+// fires, or 0 when the schedule does not parse.
+func cronRunsPerHour(schedule string) int {
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return 0
+	}
+	runs := 0
+	for _, part := range strings.Split(fields[0], ",") {
+		span, step, hasStep := strings.Cut(part, "/")
+		every := 1
+		if hasStep {
+			n, err := strconv.Atoi(step)
+			if err != nil || n < 1 {
+				return 0
+			}
+			every = n
+		}
+		first, last := 0, 59
+		switch {
+		case span == "*":
+		case strings.Contains(span, "-"):
+			lo, hi, _ := strings.Cut(span, "-")
+			a, errA := strconv.Atoi(lo)
+			b, errB := strconv.Atoi(hi)
+			if errA != nil || errB != nil || a > b {
+				return 0
+			}
+			first, last = a, b
+		default:
+			n, err := strconv.Atoi(span)
+			if err != nil {
+				return 0
+			}
+			first, last = n, n
+			if hasStep {
+				last = 59
+			}
+		}
+		runs += (last-first)/every + 1
+	}
+	return runs
+}
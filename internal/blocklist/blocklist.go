@@ -0,0 +1,278 @@
+// Package blocklist builds, signs, fetches, and verifies shared blocklists of confirmed-malicious
+// repositories, users, and asset hashes so watchdog instances can share intelligence.
+package blocklist
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+)
+
+const (
+	// FormatVersion is the blocklist payload version written by Encode.
+	FormatVersion = 1
+	// DefaultValidity is how long exported entries stay valid when no window is configured.
+	DefaultValidity = 30 * 24 * time.Hour
+	// maxBlocklistSize bounds how much of a remote blocklist is read.
+	maxBlocklistSize = 16 << 20
+)
+
+// Indicator types stored in the external_indicators table.
+const (
+	TypeRepo      = "repo"
+	TypeUser      = "user"
+	TypeAssetHash = "asset_hash"
+	// TypeRepoID and TypeUserID key an entry by GitHub's numeric ID, which survives renames.
+	TypeRepoID = "repo_id"
+	TypeUserID = "user_id"
+)
+
+// ErrUnsigned is returned when a public key is configured but the blocklist carries no signature.
+var ErrUnsigned = errors.New("blocklist is not signed")
+
+// ErrBadSignature is returned when a blocklist signature does not verify.
+var ErrBadSignature = errors.New("blocklist signature does not verify")
+
+// Entry is one confirmed indicator.
+type Entry struct {
+	Value    string    `json:"value"`
+	GitHubID int64     `json:"github_id,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+	AddedAt  time.Time `json:"added_at"`
+}
+
+// Blocklist is the shared list payload.
+type Blocklist struct {
+	Version      int       `json:"version"`
+	Source       string    `json:"source,omitempty"`
+	GeneratedAt  time.Time `json:"generated_at"`
+	ValidityDays int       `json:"validity_days"`
+	Repos        []Entry   `json:"repos"`
+	Users        []Entry   `json:"users"`
+	AssetHashes  []Entry   `json:"asset_hashes"`
+}
+
+// Envelope is the published document. Signature is a base64 ed25519 signature over the compact
+// JSON encoding of Payload, so re-indenting the document does not invalidate it.
+type Envelope struct {
+	Payload   json.RawMessage `json:"payload"`
+	Signature string          `json:"signature,omitempty"`
+}
+
+// Build collects confirmed-malicious repositories and suspicious users from the local database.
+// Asset hashes are carried by the format but not yet recorded locally, so they are exported empty.
+func Build(database *db.Database, source string, validity time.Duration, now time.Time) (Blocklist, error) {
+	if validity <= 0 {
+		validity = DefaultValidity
+	}
+	list := Blocklist{
+		Version:      FormatVersion,
+		Source:       source,
+		GeneratedAt:  now.UTC(),
+		ValidityDays: int(validity / (24 * time.Hour)),
+		Repos:        []Entry{},
+		Users:        []Entry{},
+		AssetHashes:  []Entry{},
+	}
+	cutoff := now.Add(-validity)
+
+	repos, err := database.ListMaliciousRepos()
+	if err != nil {
+		return Blocklist{}, err
+	}
+	for _, repo := range repos {
+		if repo.ProcessedAt.Before(cutoff) {
+			continue
+		}
+		list.Repos = append(list.Repos, Entry{Value: repo.RepoID, GitHubID: repo.GitHubID, Reason: "malware loader checks", AddedAt: repo.ProcessedAt.UTC()})
+	}
+
+	users, err := database.ListSuspiciousUsers()
+	if err != nil {
+		return Blocklist{}, err
+	}
+	for _, user := range users {
+		if user.ProcessedAt.Before(cutoff) {
+			continue
+		}
+		list.Users = append(list.Users, Entry{Value: user.Username, GitHubID: user.GitHubID, Reason: "suspicious account heuristics", AddedAt: user.ProcessedAt.UTC()})
+	}
+	return list, nil
+}
+
+// Encode wraps list in an envelope, signing it when key is non-nil.
+func Encode(list Blocklist, key ed25519.PrivateKey) ([]byte, error) {
+	payload, err := json.Marshal(list)
+	if err != nil {
+		return nil, fmt.Errorf("encoding blocklist: %w", err)
+	}
+	envelope := Envelope{Payload: payload}
+	if key != nil {
+		envelope.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("encoding blocklist envelope: %w", err)
+	}
+	return data, nil
+}
+
+// Decode parses an envelope. When publicKey is non-nil the signature is required and verified.
+func Decode(data []byte, publicKey ed25519.PublicKey) (Blocklist, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return Blocklist{}, fmt.Errorf("parsing blocklist envelope: %w", err)
+	}
+	if len(envelope.Payload) == 0 {
+		return Blocklist{}, errors.New("blocklist envelope has no payload")
+	}
+	if publicKey != nil {
+		if envelope.Signature == "" {
+			return Blocklist{}, ErrUnsigned
+		}
+		var payload bytes.Buffer
+		if err := json.Compact(&payload, envelope.Payload); err != nil {
+			return Blocklist{}, fmt.Errorf("parsing blocklist payload: %w", err)
+		}
+		signature, err := base64.StdEncoding.DecodeString(envelope.Signature)
+		if err != nil || !ed25519.Verify(publicKey, payload.Bytes(), signature) {
+			return Blocklist{}, ErrBadSignature
+		}
+	}
+
+	var list Blocklist
+	if err := json.Unmarshal(envelope.Payload, &list); err != nil {
+		return Blocklist{}, fmt.Errorf("parsing blocklist payload: %w", err)
+	}
+	if list.Version != FormatVersion {
+		return Blocklist{}, fmt.Errorf("unsupported blocklist version %d", list.Version)
+	}
+	return list, nil
+}
+
+// Indicators converts the list into database records for source. Entries older than the list's
+// validity window are dropped, and the rest expire when their window ends. Entries carrying a
+// GitHub ID are also recorded under the matching ID type so they still match after a rename.
+func (b Blocklist) Indicators(source string, now time.Time) []db.ExternalIndicator {
+	var validity time.Duration
+	if b.ValidityDays > 0 {
+		validity = time.Duration(b.ValidityDays) * 24 * time.Hour
+	}
+
+	var indicators []db.ExternalIndicator
+	add := func(indicatorType, idType string, entries []Entry) {
+		for _, entry := range entries {
+			value := strings.TrimSpace(entry.Value)
+			if value == "" {
+				continue
+			}
+			indicator := db.ExternalIndicator{
+				Source:        source,
+				IndicatorType: indicatorType,
+				Value:         value,
+				Reason:        entry.Reason,
+				AddedAt:       entry.AddedAt,
+			}
+			if validity > 0 {
+				indicator.ExpiresAt = entry.AddedAt.Add(validity)
+				if !indicator.ExpiresAt.After(now) {
+					continue
+				}
+			}
+			indicators = append(indicators, indicator)
+			if idType != "" && entry.GitHubID != 0 {
+				indicator.IndicatorType = idType
+				indicator.Value = strconv.FormatInt(entry.GitHubID, 10)
+				indicators = append(indicators, indicator)
+			}
+		}
+	}
+	add(TypeRepo, TypeRepoID, b.Repos)
+	add(TypeUser, TypeUserID, b.Users)
+	add(TypeAssetHash, "", b.AssetHashes)
+	return indicators
+}
+
+// Fetch reads a blocklist from an http(s) URL or a local file path.
+func Fetch(ctx context.Context, client *http.Client, location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		data, err := os.ReadFile(location)
+		if err != nil {
+			return nil, fmt.Errorf("reading blocklist %s: %w", location, err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching blocklist %s: %w", location, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching blocklist %s: %s", location, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBlocklistSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading blocklist %s: %w", location, err)
+	}
+	return data, nil
+}
+
+// ParsePublicKey decodes a base64 ed25519 public key. An empty string yields a nil key.
+func ParsePublicKey(value string) (ed25519.PublicKey, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid blocklist public key: expected base64 of %d bytes", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// LoadPrivateKey reads a base64 ed25519 private key or seed from path.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading blocklist signing key: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("decoding blocklist signing key: %w", err)
+	}
+	switch len(key) {
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(key), nil
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(key), nil
+	default:
+		return nil, fmt.Errorf("invalid blocklist signing key: expected %d or %d bytes", ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+}
+
+// GenerateKey returns a new base64-encoded ed25519 public and private key pair.
+func GenerateKey() (string, string, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("generating blocklist key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(public), base64.StdEncoding.EncodeToString(private), nil
+}
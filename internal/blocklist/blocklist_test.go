@@ -0,0 +1,119 @@
+package blocklist
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+)
+
+func testList(now time.Time) Blocklist {
+	return Blocklist{
+		Version:      FormatVersion,
+		Source:       "partner",
+		GeneratedAt:  now,
+		ValidityDays: 7,
+		Repos:        []Entry{{Value: "evil/loader", GitHubID: 42, AddedAt: now.Add(-24 * time.Hour)}},
+		Users:        []Entry{{Value: "old-spammer", AddedAt: now.Add(-8 * 24 * time.Hour)}},
+		AssetHashes:  []Entry{{Value: "deadbeef", AddedAt: now}},
+	}
+}
+
+func TestDecodeVerifiesSignature(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	otherPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	signed, err := Encode(testList(now), private)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	list, err := Decode(signed, public)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(list.Repos) != 1 || list.Repos[0].Value != "evil/loader" {
+		t.Fatalf("Decode() repos = %+v, want evil/loader", list.Repos)
+	}
+	if _, err := Decode(signed, otherPublic); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("Decode(wrong key) error = %v, want ErrBadSignature", err)
+	}
+
+	tampered := bytes.Replace(signed, []byte("evil/loader"), []byte("good/loader"), 1)
+	if _, err := Decode(tampered, public); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("Decode(tampered) error = %v, want ErrBadSignature", err)
+	}
+
+	unsigned, err := Encode(testList(now), nil)
+	if err != nil {
+		t.Fatalf("Encode(unsigned) error = %v", err)
+	}
+	if _, err := Decode(unsigned, public); !errors.Is(err, ErrUnsigned) {
+		t.Fatalf("Decode(unsigned) error = %v, want ErrUnsigned", err)
+	}
+	if _, err := Decode(unsigned, nil); err != nil {
+		t.Fatalf("Decode(unsigned, no key) error = %v", err)
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(signed, &envelope); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if envelope.Signature == "" {
+		t.Fatal("Encode() signature is empty")
+	}
+}
+
+func TestIndicatorsDropsExpiredEntries(t *testing.T) {
+	now := time.Now().UTC()
+	indicators := testList(now).Indicators("partner", now)
+	if len(indicators) != 3 || indicators[1].IndicatorType != TypeRepoID || indicators[1].Value != "42" {
+		t.Fatalf("Indicators() = %+v, want repo by name and ID, and asset hash", indicators)
+	}
+	for _, indicator := range indicators {
+		if indicator.Value == "old-spammer" {
+			t.Fatalf("Indicators() kept expired entry %+v", indicator)
+		}
+		if indicator.Source != "partner" || !indicator.ExpiresAt.Equal(indicator.AddedAt.Add(7*24*time.Hour)) {
+			t.Fatalf("Indicators() entry = %+v, want partner source expiring after validity window", indicator)
+		}
+	}
+}
+
+func TestBuildExportsConfirmedIndicators(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	defer database.Close()
+
+	if err := database.InsertProcessedRepo("evil/loader", "evil", "loader", time.Now(), 10, 5, true); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	if err := database.SetRepoGitHubID("evil/loader", 42, "R_42"); err != nil {
+		t.Fatalf("SetRepoGitHubID() error = %v", err)
+	}
+	if err := database.InsertProcessedRepo("clean/tool", "clean", "tool", time.Now(), 10, 5, false); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+
+	list, err := Build(database, "local", 0, time.Now())
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if list.ValidityDays != 30 || len(list.Repos) != 1 || list.Repos[0].Value != "evil/loader" || list.Repos[0].GitHubID != 42 {
+		t.Fatalf("Build() = %+v, want only evil/loader with its ID and default validity", list)
+	}
+}
@@ -3,26 +3,43 @@ package cli
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
+	"github.com/arkouda/github/GitHubWatchdog/internal/analyzer"
+	"github.com/arkouda/github/GitHubWatchdog/internal/blocklist"
 	"github.com/arkouda/github/GitHubWatchdog/internal/config"
 	"github.com/arkouda/github/GitHubWatchdog/internal/db"
 	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/legacy"
 	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+	"github.com/arkouda/github/GitHubWatchdog/internal/notify"
+	"github.com/arkouda/github/GitHubWatchdog/internal/report"
+	"github.com/arkouda/github/GitHubWatchdog/internal/safebrowsing"
 	"github.com/arkouda/github/GitHubWatchdog/internal/scan"
+	"github.com/arkouda/github/GitHubWatchdog/internal/selftest"
+	"github.com/arkouda/github/GitHubWatchdog/internal/urlscan"
 )
 
 const exitCodeFindings = 10
 
+// lastRunSince is the --since value meaning "since the previous successful search".
+const lastRunSince = "last-run"
+
 type searchProfile struct {
 	Name          string
 	Description   string
@@ -43,44 +60,51 @@ type searchNDJSONEvent struct {
 }
 
 type searchSummary struct {
-	Activity          string    `json:"activity,omitempty"`
-	CheckpointName    string    `json:"checkpoint_name,omitempty"`
-	ProfileName       string    `json:"profile_name,omitempty"`
-	BaseQuery         string    `json:"base_query,omitempty"`
-	Query             string    `json:"query"`
-	Queries           []string  `json:"queries,omitempty"`
-	Since             string    `json:"since,omitempty"`
-	CreatedSince      string    `json:"created_since,omitempty"`
-	CreatedBefore     string    `json:"created_before,omitempty"`
-	UpdatedSince      string    `json:"updated_since,omitempty"`
-	UpdatedBefore     string    `json:"updated_before,omitempty"`
-	NextCreatedBefore string    `json:"next_created_before,omitempty"`
-	NextUpdatedBefore string    `json:"next_updated_before,omitempty"`
-	StartedAt         time.Time `json:"started_at"`
-	CompletedAt       time.Time `json:"completed_at"`
-	OldestCreatedAt   time.Time `json:"oldest_created_at,omitempty"`
-	OldestUpdatedAt   time.Time `json:"oldest_updated_at,omitempty"`
-	TotalCount        int       `json:"total_count"`
-	AnalyzedCount     int       `json:"analyzed_count"`
-	FlaggedCount      int       `json:"flagged_count"`
-	EmittedCount      int       `json:"emitted_count"`
+	Activity          string         `json:"activity,omitempty"`
+	CheckpointName    string         `json:"checkpoint_name,omitempty"`
+	ProfileName       string         `json:"profile_name,omitempty"`
+	BaseQuery         string         `json:"base_query,omitempty"`
+	Query             string         `json:"query"`
+	Queries           []string       `json:"queries,omitempty"`
+	Since             string         `json:"since,omitempty"`
+	CreatedSince      string         `json:"created_since,omitempty"`
+	CreatedBefore     string         `json:"created_before,omitempty"`
+	UpdatedSince      string         `json:"updated_since,omitempty"`
+	UpdatedBefore     string         `json:"updated_before,omitempty"`
+	NextCreatedBefore string         `json:"next_created_before,omitempty"`
+	NextUpdatedBefore string         `json:"next_updated_before,omitempty"`
+	StartedAt         time.Time      `json:"started_at"`
+	CompletedAt       time.Time      `json:"completed_at"`
+	OldestCreatedAt   time.Time      `json:"oldest_created_at,omitempty"`
+	OldestUpdatedAt   time.Time      `json:"oldest_updated_at,omitempty"`
+	TotalCount        int            `json:"total_count"`
+	AnalyzedCount     int            `json:"analyzed_count"`
+	FlaggedCount      int            `json:"flagged_count"`
+	EmittedCount      int            `json:"emitted_count"`
+	UserAnalysis      analyzer.Stats `json:"user_analysis"`
 }
 
 type repoSummary struct {
-	EntityType      string   `json:"entity_type"`
-	RepoID          string   `json:"repo_id"`
-	IsFlagged       bool     `json:"is_flagged"`
-	IsMalicious     bool     `json:"is_malicious"`
-	OwnerSuspicious bool     `json:"owner_suspicious"`
-	RepoFlagCount   int      `json:"repo_flag_count"`
-	RepoFlags       []string `json:"repo_flags,omitempty"`
-	Errors          []string `json:"errors,omitempty"`
+	EntityType        string   `json:"entity_type"`
+	RepoID            string   `json:"repo_id"`
+	IsFlagged         bool     `json:"is_flagged"`
+	IsMalicious       bool     `json:"is_malicious"`
+	OwnerSuspicious   bool     `json:"owner_suspicious"`
+	RenamedFrom       string   `json:"renamed_from,omitempty"`
+	Status            string   `json:"status,omitempty"`
+	TakedownConfirmed bool     `json:"takedown_confirmed,omitempty"`
+	RepoFlagCount     int      `json:"repo_flag_count"`
+	RepoFlags         []string `json:"repo_flags,omitempty"`
+	FlaggedCheckers   []string `json:"flagged_checkers,omitempty"`
+	Errors            []string `json:"errors,omitempty"`
 }
 
 type userSummary struct {
 	EntityType     string   `json:"entity_type"`
 	Username       string   `json:"username"`
 	IsSuspicious   bool     `json:"is_suspicious"`
+	Tier           string   `json:"tier,omitempty"`
+	Score          float64  `json:"score"`
 	HeuristicCount int      `json:"heuristic_count"`
 	Heuristics     []string `json:"heuristics,omitempty"`
 	Contributions  int      `json:"contributions"`
@@ -125,6 +149,13 @@ func Run(args []string, stdout, stderr io.Writer) error {
 		}
 		return err
 	}
+	if !flagPassed(root, "db") {
+		cfg, err := config.Load(*configPath)
+		if err != nil {
+			return err
+		}
+		*dbPath = firstNonEmpty(cfg.DBPath, *dbPath)
+	}
 
 	command := "search"
 	commandArgs := root.Args()
@@ -181,6 +212,81 @@ func Run(args []string, stdout, stderr io.Writer) error {
 		}
 		defer database.Close()
 		return runCheckpointCommand(commandArgs, stdout, stderr, database)
+	case "flags":
+		database, err := db.New(*dbPath)
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer database.Close()
+		return runFlagsCommand(commandArgs, stdout, stderr, database)
+	case "rings":
+		database, err := db.New(*dbPath)
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer database.Close()
+		return runRingsCommand(commandArgs, stdout, stderr, database)
+	case "report":
+		cfg, database, err := openLocalRuntime(*configPath, *dbPath)
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+		return runReportCommand(commandArgs, stdout, stderr, cfg, database)
+	case "urlscan":
+		database, err := db.New(*dbPath)
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer database.Close()
+		client := urlscan.NewClient(config.URLScanAPIKey(), logger.NewWithQuiet(false, *quiet))
+		return runURLScanCommand(commandArgs, stdout, stderr, client, database)
+	case "export":
+		database, err := db.New(*dbPath)
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer database.Close()
+		return runExportCommand(commandArgs, stdout, stderr, database)
+	case "import":
+		database, err := db.New(*dbPath)
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer database.Close()
+		return runImportCommand(commandArgs, stdout, stderr, database)
+	case "blocklist":
+		cfg, database, err := openLocalRuntime(*configPath, *dbPath)
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+		return runBlocklistCommand(commandArgs, stdout, stderr, cfg, database)
+	case "allowlist":
+		database, err := db.New(*dbPath)
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer database.Close()
+		return runAllowlistCommand(commandArgs, stdout, stderr, database)
+	case "db":
+		return runDBCommand(commandArgs, stdout, stderr)
+	case "maintenance":
+		if helpRequested(commandArgs) {
+			return runMaintenanceCommand(commandArgs, stdout, stderr, defaultConfig(), nil, logger.New(false))
+		}
+		cfg, database, appLogger, err := openRuntime(*configPath, *dbPath, *quiet)
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+		return runMaintenanceCommand(commandArgs, stdout, stderr, cfg, database, appLogger)
+	case "selftest":
+		cfg, err := config.Load(*configPath)
+		if err != nil {
+			return err
+		}
+		return runSelftestCommand(commandArgs, stdout, stderr, cfg)
 	case "capabilities":
 		return runCapabilitiesCommand(commandArgs, stdout, stderr)
 	case "recommend":
@@ -204,7 +310,7 @@ func runSearchCommand(args []string, stdout, stderr io.Writer, cfg *config.Confi
 	checkpointName := fs.String("checkpoint", "", "Save search progress under this checkpoint name")
 	resume := fs.Bool("resume", false, "Resume search defaults from the named checkpoint")
 	activity := fs.String("activity", "updated", "Search activity source: updated, created, or either")
-	since := fs.String("since", "", "Only include repositories updated on or after this date (YYYY-MM-DD or RFC3339)")
+	since := fs.String("since", "", "Only include repositories updated on or after this date (YYYY-MM-DD or RFC3339), or last-run for the start of the previous successful search")
 	updatedBefore := fs.String("updated-before", "", "Only include repositories updated on or before this date (YYYY-MM-DD or RFC3339)")
 	createdSince := fs.String("created-since", "", "Only include repositories created on or after this date (YYYY-MM-DD or RFC3339)")
 	createdBefore := fs.String("created-before", "", "Only include repositories created on or before this date (YYYY-MM-DD or RFC3339)")
@@ -270,7 +376,11 @@ func runSearchCommand(args []string, stdout, stderr io.Writer, cfg *config.Confi
 	}
 	updatedSinceValue := *since
 	if !flagPassed(fs, "since") {
-		updatedSinceValue = firstNonEmpty(checkpoint.UpdatedSince, checkpoint.Since, profile.UpdatedSince)
+		updatedSinceValue = firstNonEmpty(checkpoint.UpdatedSince, checkpoint.Since, profile.UpdatedSince, cfg.Since)
+	}
+	updatedSinceValue, err = resolveSinceValue(database, *checkpointName, updatedSinceValue)
+	if err != nil {
+		return err
 	}
 	updatedBeforeValue := *updatedBefore
 	if !flagPassed(fs, "updated-before") {
@@ -310,23 +420,31 @@ func runSearchCommand(args []string, stdout, stderr io.Writer, cfg *config.Confi
 		return err
 	}
 	searchOpts := scan.SearchOptions{
-		CheckpointName: *checkpointName,
-		ProfileName:    profileValue,
-		Activity:       activityValue,
-		BaseQuery:      queryValue,
-		Query:          queryPlan.PrimaryQuery(),
-		Queries:        append([]string(nil), queryPlan.Queries...),
-		CreatedSince:   createdSinceValue,
-		CreatedBefore:  createdBeforeValue,
-		UpdatedSince:   updatedSinceValue,
-		UpdatedBefore:  updatedBeforeValue,
-		MaxPages:       maxPagesValue,
-		PerPage:        perPageValue,
-		MaxConcurrent:  *maxConcurrent,
-		Persist:        *persist,
-	}
-
-	service := newScanService(cfg, database, appLogger)
+		CheckpointName:    *checkpointName,
+		ProfileName:       profileValue,
+		Activity:          activityValue,
+		BaseQuery:         queryValue,
+		Query:             queryPlan.PrimaryQuery(),
+		Queries:           append([]string(nil), queryPlan.Queries...),
+		CreatedSince:      createdSinceValue,
+		CreatedBefore:     createdBeforeValue,
+		UpdatedSince:      updatedSinceValue,
+		UpdatedBefore:     updatedBeforeValue,
+		MaxPages:          maxPagesValue,
+		PerPage:           perPageValue,
+		MaxConcurrent:     *maxConcurrent,
+		Persist:           *persist,
+		IncompleteRetries: intValue(cfg.SearchIncompleteRetries, 0),
+		Band: scan.RepoBand{
+			MinStars: intValue(cfg.MinStars, 0),
+			MaxStars: intValue(cfg.MaxStars, 0),
+			MinSize:  intValue(cfg.MinSize, 0),
+			MaxSize:  intValue(cfg.MaxSize, 0),
+		},
+	}
+
+	service, client := newScanService(cfg, database, appLogger)
+	defer closeScanService(service, client, appLogger)
 	ctx, cancel := interruptibleContext(*timeout)
 	defer cancel()
 
@@ -342,21 +460,23 @@ func runSearchCommand(args []string, stdout, stderr io.Writer, cfg *config.Confi
 		if reportErr != nil {
 			return reportErr
 		}
-		report.NextCreatedBefore = nextCreatedBefore(report.OldestCreatedAt)
-		report.NextUpdatedBefore = nextUpdatedBefore(report.OldestUpdatedAt)
 		if err := writeSearchReport(stdout, *format, report.Filter(*onlyFlagged, *includeSkipped)); err != nil {
 			return err
 		}
 	}
-	if *format == "ndjson" && report.NextUpdatedBefore == "" {
-		report.NextCreatedBefore = nextCreatedBefore(report.OldestCreatedAt)
-		report.NextUpdatedBefore = nextUpdatedBefore(report.OldestUpdatedAt)
+	if report.BandFiltered > 0 {
+		appLogger.Info("Skipped %d repositories outside the configured star/size band", report.BandFiltered)
 	}
 	if *checkpointName != "" {
 		if err := saveSearchCheckpoint(database, report); err != nil {
 			return err
 		}
 	}
+	if *persist && database != nil && ctx.Err() == nil {
+		if err := database.RecordSearchRun(*checkpointName, report.StartedAt, report.CompletedAt); err != nil {
+			return err
+		}
+	}
 	if *failOnFindings && report.FlaggedCount() > 0 {
 		return exitError{code: exitCodeFindings}
 	}
@@ -391,7 +511,8 @@ func runRepoCommand(args []string, stdout, stderr io.Writer, cfg *config.Config,
 		return err
 	}
 
-	service := newScanService(cfg, database, appLogger)
+	service, client := newScanService(cfg, database, appLogger)
+	defer closeScanService(service, client, appLogger)
 	ctx, cancel := interruptibleContext(*timeout)
 	defer cancel()
 
@@ -440,7 +561,8 @@ func runUserCommand(args []string, stdout, stderr io.Writer, cfg *config.Config,
 		return err
 	}
 
-	service := newScanService(cfg, database, appLogger)
+	service, client := newScanService(cfg, database, appLogger)
+	defer closeScanService(service, client, appLogger)
 	ctx, cancel := interruptibleContext(*timeout)
 	defer cancel()
 
@@ -489,7 +611,8 @@ func runVerdictCommand(args []string, stdout, stderr io.Writer, cfg *config.Conf
 		return errors.New("verdict command requires a single <owner>/<repo> or <username> argument, or --input for batch mode")
 	}
 
-	service := newScanService(cfg, database, appLogger)
+	service, client := newScanService(cfg, database, appLogger)
+	defer closeScanService(service, client, appLogger)
 	ctx, cancel := interruptibleContext(*timeout)
 	defer cancel()
 
@@ -547,64 +670,1004 @@ func runCheckpointCommand(args []string, stdout, stderr io.Writer, database *db.
 		if err != nil {
 			return err
 		}
-		return writeCheckpointList(stdout, *format, checkpoints)
-	case "show":
-		if fs.NArg() != 2 {
-			return errors.New("checkpoints show requires a checkpoint name")
+		return writeCheckpointList(stdout, *format, checkpoints)
+	case "show":
+		if fs.NArg() != 2 {
+			return errors.New("checkpoints show requires a checkpoint name")
+		}
+		checkpoint, err := database.GetSearchCheckpoint(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		return writeCheckpoint(stdout, *format, checkpoint)
+	case "delete":
+		if fs.NArg() != 2 {
+			return errors.New("checkpoints delete requires a checkpoint name")
+		}
+		return database.DeleteSearchCheckpoint(fs.Arg(1))
+	case "export":
+		return runCheckpointExport(stdout, *format, database, fs.Args()[1:])
+	case "import":
+		return runCheckpointImport(stdout, *format, database, *input)
+	default:
+		return fmt.Errorf("unknown checkpoints subcommand %q", subcommand)
+	}
+}
+
+func runCheckpointExport(stdout io.Writer, format string, database *db.Database, args []string) error {
+	switch len(args) {
+	case 0:
+		checkpoints, err := database.ListSearchCheckpoints()
+		if err != nil {
+			return err
+		}
+		return writeCheckpointList(stdout, format, checkpoints)
+	case 1:
+		checkpoint, err := database.GetSearchCheckpoint(args[0])
+		if err != nil {
+			return err
+		}
+		return writeCheckpoint(stdout, format, checkpoint)
+	default:
+		return errors.New("checkpoints export accepts at most one checkpoint name")
+	}
+}
+
+func runCheckpointImport(stdout io.Writer, format string, database *db.Database, inputPath string) error {
+	data, err := readCheckpointImportInput(inputPath)
+	if err != nil {
+		return err
+	}
+	checkpoints, err := decodeCheckpointImport(data)
+	if err != nil {
+		return err
+	}
+	for _, checkpoint := range checkpoints {
+		if err := database.UpsertSearchCheckpoint(checkpoint); err != nil {
+			return err
+		}
+	}
+	return writeCheckpointImportResult(stdout, format, len(checkpoints))
+}
+
+type flaggedEntityPage struct {
+	Heuristic string             `json:"heuristic"`
+	Status    string             `json:"status,omitempty"`
+	Total     int                `json:"total"`
+	Limit     int                `json:"limit"`
+	Offset    int                `json:"offset"`
+	Entities  []db.FlaggedEntity `json:"entities"`
+}
+
+func runFlagsCommand(args []string, stdout, stderr io.Writer, database *db.Database) error {
+	fs := flag.NewFlagSet("flags", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	status := fs.String("status", "", "Filter by abuse report review status, or unreviewed")
+	limit := fs.Int("limit", 50, "Maximum entities to return")
+	offset := fs.Int("offset", 0, "Entities to skip before the first returned")
+	format := fs.String("format", "json", "Output format: json or text")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if err := validateSimpleFormat(*format); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("flags requires a single <heuristic> argument")
+	}
+	switch *status {
+	case "", db.AbuseReportDraft, db.AbuseReportReported, db.AbuseReportActioned, db.AbuseReportDeclined, db.ReviewUnreviewed:
+	default:
+		return fmt.Errorf("invalid status %q: expected draft, reported, actioned, declined, or unreviewed", *status)
+	}
+	if *limit <= 0 || *offset < 0 {
+		return errors.New("--limit must be positive and --offset must not be negative")
+	}
+
+	entities, total, err := database.ListEntitiesByHeuristic(fs.Arg(0), *status, *limit, *offset)
+	if err != nil {
+		return err
+	}
+	if entities == nil {
+		entities = []db.FlaggedEntity{}
+	}
+	page := flaggedEntityPage{Heuristic: fs.Arg(0), Status: *status, Total: total, Limit: *limit, Offset: *offset, Entities: entities}
+	if *format == "json" {
+		return writeJSON(stdout, page)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %d flagged (showing %d from offset %d)\n", page.Heuristic, page.Total, len(page.Entities), page.Offset))
+	for _, entity := range page.Entities {
+		sb.WriteString(fmt.Sprintf("%s %s [%s] %s (%s), %d stars, last flagged %s\n", entity.EntityType, entity.EntityID, entity.ReviewStatus, entity.Flag, entity.Category, entity.Stars, entity.UpdatedAt.Format(time.RFC3339)))
+		if entity.Message != "" {
+			sb.WriteString("  " + entity.Message + "\n")
+		}
+	}
+	_, err = io.WriteString(stdout, sb.String())
+	return err
+}
+
+func runRingsCommand(args []string, stdout, stderr io.Writer, database *db.Database) error {
+	fs := flag.NewFlagSet("rings", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	minRepos := fs.Int("min-repos", scan.DefaultRingMinRepos, "Malicious repositories an account must have starred")
+	flagged := fs.Bool("flagged", false, "Count every flagged repository, not only malicious ones")
+	persist := fs.Bool("persist", true, "Record a StarringRingHeuristic flag on each member")
+	format := fs.String("format", "json", "Output format: json or text")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if err := validateSimpleFormat(*format); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("rings takes no arguments")
+	}
+	if *minRepos < 2 {
+		return errors.New("--min-repos must be at least 2")
+	}
+
+	report, err := scan.DetectStarringRings(database, scan.RingOptions{MinRepos: *minRepos, Flagged: *flagged, Record: *persist})
+	if err != nil {
+		return err
+	}
+	if *format == "json" {
+		return writeJSON(stdout, report)
+	}
+
+	kind := "malicious"
+	if report.Flagged {
+		kind = "flagged"
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d accounts starred %d or more %s repositories\n", len(report.Members), report.MinRepos, kind))
+	for _, member := range report.Members {
+		sb.WriteString(fmt.Sprintf("%s (%d): %s\n", member.Username, len(member.Repos), strings.Join(member.Repos, ", ")))
+	}
+	_, err = io.WriteString(stdout, sb.String())
+	return err
+}
+
+func runReportCommand(args []string, stdout, stderr io.Writer, cfg *config.Config, database *db.Database) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return errors.New("report requires a subcommand: text, status, list, markdown, weekly, or publish")
+	}
+	subcommand := args[0]
+
+	fs := flag.NewFlagSet("report "+subcommand, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "Output format: json or text")
+	templatePath := fs.String("template", "", "Abuse report template path; overrides abuse_report_template in config")
+	save := fs.Bool("save", true, "Store generated report text in the SQLite database")
+	status := fs.String("status", "", "Filter report list by review status")
+	since := fs.String("since", "", "Only include entries first seen on or after this YYYY-MM-DD or RFC3339 time (markdown), or the window start such as 7d (weekly)")
+	category := fs.String("category", "", "Only include entries with a flag in this category (markdown)")
+	tier := fs.String("tier", "", "Only include users of this tier or above: low, medium, or high (markdown)")
+	var output string
+	fs.StringVar(&output, "output", "-", "Output path or - for stdout (markdown)")
+	fs.StringVar(&output, "o", "-", "Shorthand for --output")
+	outputDir := fs.String("output-dir", firstNonEmpty(cfg.ReportOutputDir, "reports"), "Directory for dated weekly summaries (weekly)")
+	html := fs.Bool("html", false, "Also write an HTML copy of the summary (weekly)")
+	to := fs.String("to", firstNonEmpty(cfg.PublishTarget, "issue"), "Publish target: issue or gist (publish)")
+	publishRepo := fs.String("repo", cfg.PublishRepo, "Repository <owner>/<repo> holding the findings issue (publish)")
+	issueNumber := fs.Int("issue", 0, "Issue number to update instead of looking one up by title (publish)")
+	gistID := fs.String("gist", cfg.PublishGist, "Gist id to update; empty creates a secret gist (publish)")
+	title := fs.String("title", defaultPublishTitle, "Issue title or gist description (publish)")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Overall command timeout (publish)")
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if err := validateSimpleFormat(*format); err != nil {
+		return err
+	}
+
+	switch subcommand {
+	case "text":
+		if fs.NArg() != 1 {
+			return errors.New("report text requires a single <owner/repo|username> argument")
+		}
+		tmpl, err := report.LoadAbuseTemplate(firstNonEmpty(*templatePath, cfg.AbuseReportTemplate))
+		if err != nil {
+			return err
+		}
+		abuse, err := report.BuildAbuseReport(database, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		body, err := report.RenderAbuseReport(tmpl, abuse)
+		if err != nil {
+			return err
+		}
+		if *save {
+			if err := database.SaveAbuseReport(reportEntityType(fs.Arg(0)), fs.Arg(0), body); err != nil {
+				return err
+			}
+		}
+		if *format == "json" {
+			return writeJSON(stdout, struct {
+				report.AbuseReport
+				Text string `json:"text"`
+			}{abuse, body})
+		}
+		_, err = io.WriteString(stdout, body)
+		return err
+	case "status":
+		if fs.NArg() != 2 {
+			return errors.New("report status requires <owner/repo|username> and a status: draft, reported, actioned, or declined")
+		}
+		return database.SetAbuseReportStatus(reportEntityType(fs.Arg(0)), fs.Arg(0), fs.Arg(1))
+	case "list":
+		reports, err := database.ListAbuseReports(*status)
+		if err != nil {
+			return err
+		}
+		return writeAbuseReportList(stdout, *format, reports)
+	case "markdown":
+		if fs.NArg() != 0 {
+			return errors.New("report markdown does not accept positional arguments")
+		}
+		filter, err := markdownFilter(*since, *category, *tier)
+		if err != nil {
+			return err
+		}
+		result, err := report.BuildMarkdownReport(database, filter)
+		if err != nil {
+			return err
+		}
+		if output == "-" {
+			return writeMarkdownReport(stdout, *format, result)
+		}
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("creating markdown report: %w", err)
+		}
+		defer file.Close()
+		if err := writeMarkdownReport(file, *format, result); err != nil {
+			return err
+		}
+		return file.Close()
+	case "weekly":
+		if fs.NArg() != 0 {
+			return errors.New("report weekly does not accept positional arguments")
+		}
+		until := time.Now().UTC()
+		start, err := parseWindowStart(firstNonEmpty(*since, "7d"), until)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		result, err := report.BuildWeeklyReport(database, start, until)
+		if err != nil {
+			return err
+		}
+		files, err := writeWeeklyReport(*outputDir, result, *html)
+		if err != nil {
+			return err
+		}
+		if *format == "json" {
+			return writeJSON(stdout, struct {
+				Files  []string            `json:"files"`
+				Report report.WeeklyReport `json:"report"`
+			}{files, result})
+		}
+		for _, path := range files {
+			if _, err := fmt.Fprintf(stdout, "Weekly summary written to %s\n", path); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "publish":
+		if fs.NArg() != 0 {
+			return errors.New("report publish does not accept positional arguments")
+		}
+		target := publishTarget{To: *to, Repo: *publishRepo, Issue: *issueNumber, Gist: *gistID, Title: *title}
+		if err := target.validate(); err != nil {
+			return err
+		}
+		token := config.GitHubToken()
+		if token == "" {
+			return errors.New("report publish needs GITHUB_TOKEN or GH_TOKEN, or gh authentication")
+		}
+		filter, err := markdownFilter(*since, *category, *tier)
+		if err != nil {
+			return err
+		}
+		result, err := report.BuildMarkdownReport(database, filter)
+		if err != nil {
+			return err
+		}
+		client := github.NewClient(token, intValue(cfg.RateLimitBuffer, 500), 0, nil, nil)
+		defer client.Close()
+		ctx, cancel := interruptibleContext(*timeout)
+		defer cancel()
+		published, err := publishFindings(ctx, client, target, report.RenderMarkdown(result))
+		if err != nil {
+			return err
+		}
+		return writePublishResult(stdout, *format, published)
+	default:
+		return fmt.Errorf("unknown report subcommand %q", subcommand)
+	}
+}
+
+// markdownFilter builds the markdown report filter from the --since, --category, and --tier flags.
+func markdownFilter(since, category, tier string) (report.MarkdownFilter, error) {
+	switch tier {
+	case "", models.SeverityLow, models.SeverityMedium, models.SeverityHigh:
+	default:
+		return report.MarkdownFilter{}, fmt.Errorf("invalid --tier %q: expected low, medium, or high", tier)
+	}
+	filter := report.MarkdownFilter{Category: category, Tier: tier}
+	if since != "" {
+		parsed, err := parseDateOrTime(since)
+		if err != nil {
+			return report.MarkdownFilter{}, fmt.Errorf("invalid --since: %w", err)
+		}
+		filter.Since = parsed
+	}
+	return filter, nil
+}
+
+func reportEntityType(target string) string {
+	if strings.Contains(target, "/") {
+		return "repo"
+	}
+	return "user"
+}
+
+func runURLScanCommand(args []string, stdout, stderr io.Writer, client *urlscan.Client, database *db.Database) error {
+	fs := flag.NewFlagSet("urlscan", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	repoRef := fs.String("repo", "", "Attach the scan to, or list scans for, this <owner>/<repo>")
+	persist := fs.Bool("persist", true, "Persist the scan result to the SQLite database")
+	format := fs.String("format", "json", "Output format: json or text")
+	timeout := fs.Duration("timeout", 5*time.Minute, "Overall command timeout")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if err := validateSimpleFormat(*format); err != nil {
+		return err
+	}
+
+	repoID := ""
+	if *repoRef != "" {
+		owner, name, err := parseRepoRef(*repoRef)
+		if err != nil {
+			return err
+		}
+		repoID = owner + "/" + name
+	}
+
+	if fs.NArg() == 0 {
+		if repoID == "" {
+			return errors.New("urlscan requires a <url> argument or --repo to list recorded scans")
+		}
+		scans, err := database.ListURLScans(repoID)
+		if err != nil {
+			return err
+		}
+		return writeURLScans(stdout, *format, scans)
+	}
+	if fs.NArg() != 1 {
+		return errors.New("urlscan accepts a single <url> argument")
+	}
+	if !client.Enabled() {
+		return urlscan.ErrDisabled
+	}
+
+	ctx, cancel := interruptibleContext(*timeout)
+	defer cancel()
+	result, err := client.Scan(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	record := scan.URLScanRecord(repoID, result)
+	if *persist {
+		if err := database.UpsertURLScan(record); err != nil {
+			return err
+		}
+	}
+	return writeURLScans(stdout, *format, []db.URLScan{record})
+}
+
+func runExportCommand(args []string, stdout, stderr io.Writer, database *db.Database) error {
+	if len(args) == 0 {
+		return errors.New("export requires a subcommand: sarif or csv")
+	}
+	switch args[0] {
+	case "sarif":
+		return runExportSARIF(args[1:], stdout, stderr, database)
+	case "csv":
+		return runExportCSV(args[1:], stdout, stderr, database)
+	default:
+		return fmt.Errorf("unknown export subcommand %q", args[0])
+	}
+}
+
+func runExportSARIF(args []string, stdout, stderr io.Writer, database *db.Database) error {
+	fs := flag.NewFlagSet("export sarif", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	owner := fs.String("owner", "", "Only export repositories owned by this account")
+	since := fs.String("since", "", "Only export findings first flagged on or after this YYYY-MM-DD or RFC3339 time")
+	category := fs.String("category", "", "Only export findings in this flag category")
+	output := fs.String("output", "-", "Output path or - for stdout")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("export sarif does not accept positional arguments")
+	}
+
+	filter := report.SARIFFilter{Owner: *owner, Category: *category}
+	if *since != "" {
+		parsed, err := parseDateOrTime(*since)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		filter.Since = parsed
+	}
+
+	log, err := report.BuildSARIF(database, filter)
+	if err != nil {
+		return err
+	}
+	if *output == "-" {
+		return writeJSON(stdout, log)
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("creating SARIF output: %w", err)
+	}
+	defer file.Close()
+	if err := writeJSON(file, log); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func runExportCSV(args []string, stdout, stderr io.Writer, database *db.Database) error {
+	fs := flag.NewFlagSet("export csv", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	exportType := fs.String("type", report.CSVUsers, "What to export: users, repos, or flags")
+	output := fs.String("output", "-", "Output path or - for stdout")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("export csv does not accept positional arguments")
+	}
+	switch *exportType {
+	case report.CSVUsers, report.CSVRepos, report.CSVFlags:
+	default:
+		return fmt.Errorf("invalid --type %q: expected users, repos, or flags", *exportType)
+	}
+
+	if *output == "-" {
+		_, err := report.WriteCSV(stdout, database, *exportType)
+		return err
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("creating CSV output: %w", err)
+	}
+	defer file.Close()
+	if _, err := report.WriteCSV(file, database, *exportType); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func runImportCommand(args []string, stdout, stderr io.Writer, database *db.Database) error {
+	if len(args) == 0 {
+		return errors.New("import requires a subcommand: legacy")
+	}
+	if args[0] != "legacy" {
+		return fmt.Errorf("unknown import subcommand %q", args[0])
+	}
+
+	fs := flag.NewFlagSet("import legacy", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dir := fs.String("dir", ".", "Directory searched recursively for legacy record files")
+	format := fs.String("format", "text", "Output format: json or text")
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if err := validateSimpleFormat(*format); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("import legacy does not accept positional arguments")
+	}
+
+	summary, err := legacy.Import(database, *dir)
+	if err != nil {
+		return err
+	}
+	if *format == "json" {
+		return writeJSON(stdout, summary)
+	}
+	var sb strings.Builder
+	if len(summary.Files) == 0 {
+		sb.WriteString(fmt.Sprintf("No legacy files found under %s.\n", summary.Dir))
+	}
+	for _, file := range summary.Files {
+		sb.WriteString(fmt.Sprintf("%s (%s): %d inserted, %d duplicate, %d skipped\n", file.Path, file.EntityType, file.Inserted, file.Duplicates, file.Skipped))
+	}
+	sb.WriteString(fmt.Sprintf("Total: %d inserted, %d duplicate, %d skipped\n", summary.Inserted, summary.Duplicates, summary.Skipped))
+	_, err = io.WriteString(stdout, sb.String())
+	return err
+}
+
+func runDBCommand(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("db requires a subcommand: diff")
+	}
+	if args[0] != "diff" {
+		return fmt.Errorf("unknown db subcommand %q", args[0])
+	}
+
+	fs := flag.NewFlagSet("db diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "Output format: json or text")
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if err := validateSimpleFormat(*format); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("db diff requires two arguments: <old.db> <new.db>")
+	}
+
+	result, err := db.Diff(fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if *format == "json" {
+		return writeJSON(stdout, result)
+	}
+	return writeDBDiff(stdout, result)
+}
+
+func writeDBDiff(w io.Writer, result db.DiffReport) error {
+	if len(result.Added)+len(result.Removed)+len(result.StatusChanged)+len(result.NewFlags) == 0 {
+		_, err := fmt.Fprintf(w, "No differences between %s and %s.\n", result.OldPath, result.NewPath)
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHANGE\tTYPE\tENTITY\tDETAIL")
+	for _, change := range result.Added {
+		fmt.Fprintf(tw, "added\t%s\t%s\t%s\n", change.EntityType, change.EntityID, change.NewStatus)
+	}
+	for _, change := range result.StatusChanged {
+		fmt.Fprintf(tw, "status\t%s\t%s\t%s -> %s\n", change.EntityType, change.EntityID, change.OldStatus, change.NewStatus)
+	}
+	for _, flag := range result.NewFlags {
+		fmt.Fprintf(tw, "new flag\t%s\t%s\t%s\n", flag.EntityType, flag.EntityID, flag.Flag)
+	}
+	for _, change := range result.Removed {
+		fmt.Fprintf(tw, "removed\t%s\t%s\twas %s\n", change.EntityType, change.EntityID, change.OldStatus)
+	}
+	return tw.Flush()
+}
+
+type pendingNDJSONEvent struct {
+	Type    string              `json:"type"`
+	Result  *scan.PendingResult `json:"result,omitempty"`
+	Summary *scan.PendingReport `json:"summary,omitempty"`
+}
+
+func runMaintenanceCommand(args []string, stdout, stderr io.Writer, cfg *config.Config, database *db.Database, appLogger *logger.Logger) error {
+	if len(args) == 0 {
+		return errors.New("maintenance requires a subcommand: analyze-pending or reanalyze")
+	}
+	subcommand := args[0]
+	var limitUsage string
+	switch subcommand {
+	case "analyze-pending":
+		limitUsage = "Maximum pending entities to take up; 0 takes all"
+	case "reanalyze":
+		limitUsage = "Maximum users to re-analyze, least recently analyzed first; 0 takes all"
+	default:
+		return fmt.Errorf("unknown maintenance subcommand %q", subcommand)
+	}
+
+	fs := flag.NewFlagSet("maintenance "+subcommand, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	timeout := fs.Duration("timeout", 30*time.Minute, "Time box for the whole job")
+	maxConcurrent := fs.Int("max-concurrent", scan.DefaultPendingConcurrency, "Maximum analyses in flight")
+	limit := fs.Int("limit", 0, limitUsage)
+	format := fs.String("format", "json", "Output format: json, ndjson, or text")
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if err := validateFormat(*format); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("maintenance %s takes no arguments", subcommand)
+	}
+	if *maxConcurrent <= 0 {
+		return errors.New("--max-concurrent must be positive")
+	}
+	if *limit < 0 {
+		return errors.New("--limit must not be negative")
+	}
+
+	service, client := newScanService(cfg, database, appLogger)
+	defer closeScanService(service, client, appLogger)
+	ctx, cancel := interruptibleContext(*timeout)
+	defer cancel()
+
+	var writeErr error
+	opts := scan.PendingOptions{MaxConcurrent: *maxConcurrent, Limit: *limit}
+	switch *format {
+	case "ndjson":
+		opts.OnResult = func(result scan.PendingResult) {
+			if writeErr == nil {
+				writeErr = writeCompactJSON(stdout, pendingNDJSONEvent{Type: "result", Result: &result})
+			}
+		}
+	case "text":
+		opts.OnResult = func(result scan.PendingResult) {
+			if writeErr == nil {
+				writeErr = writePendingResult(stdout, result)
+			}
+		}
+	}
+	run := service.AnalyzePending
+	if subcommand == "reanalyze" {
+		run = service.ReanalyzeUsers
+	}
+	report, err := run(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+
+	switch *format {
+	case "ndjson":
+		report.Results = nil
+		return writeCompactJSON(stdout, pendingNDJSONEvent{Type: "summary", Summary: &report})
+	case "text":
+		var err error
+		if subcommand == "reanalyze" {
+			_, err = fmt.Fprintf(stdout, "Users: %d, reanalyzed: %d, verdicts changed: %d, failed: %d, skipped: %d\n", report.Pending, report.Analyzed, report.Changed, report.Failed, report.Skipped)
+		} else {
+			_, err = fmt.Fprintf(stdout, "Pending: %d, analyzed: %d, failed: %d, skipped: %d\n", report.Pending, report.Analyzed, report.Failed, report.Skipped)
+		}
+		if err == nil && report.StopReason != "" {
+			_, err = fmt.Fprintf(stdout, "Stopped early: %s\n", report.StopReason)
+		}
+		return err
+	default:
+		return writeJSON(stdout, report)
+	}
+}
+
+func writePendingResult(w io.Writer, result scan.PendingResult) error {
+	line := fmt.Sprintf("%s %s: %s", result.EntityType, result.EntityID, result.Status)
+	if result.Flagged {
+		line += " (flagged)"
+	}
+	if result.Changed {
+		line += " (verdict changed)"
+	}
+	if result.Error != "" {
+		line += ": " + result.Error
+	}
+	_, err := fmt.Fprintln(w, line)
+	return err
+}
+
+type blocklistImportResult struct {
+	Source   string `json:"source"`
+	Location string `json:"location"`
+	Imported int    `json:"imported"`
+	Error    string `json:"error,omitempty"`
+}
+
+func runBlocklistCommand(args []string, stdout, stderr io.Writer, cfg *config.Config, database *db.Database) error {
+	if len(args) == 0 {
+		return errors.New("blocklist requires a subcommand: export, import, or keygen")
+	}
+	switch args[0] {
+	case "export":
+		return runBlocklistExport(args[1:], stdout, stderr, cfg, database)
+	case "import":
+		return runBlocklistImport(args[1:], stdout, stderr, cfg, database)
+	case "keygen":
+		return runBlocklistKeygen(args[1:], stdout, stderr)
+	default:
+		return fmt.Errorf("unknown blocklist subcommand %q", args[0])
+	}
+}
+
+func runBlocklistExport(args []string, stdout, stderr io.Writer, cfg *config.Config, database *db.Database) error {
+	validityDays := int(blocklist.DefaultValidity / (24 * time.Hour))
+	if cfg.BlocklistValidityDays != nil {
+		validityDays = *cfg.BlocklistValidityDays
+	}
+
+	fs := flag.NewFlagSet("blocklist export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	source := fs.String("source", "", "Publisher name recorded in the blocklist")
+	validity := fs.Int("validity-days", validityDays, "Days each entry stays valid")
+	signKey := fs.String("sign-key", cfg.BlocklistSigningKey, "Path to a base64 ed25519 private key; empty exports an unsigned list")
+	output := fs.String("output", "-", "Output path or - for stdout")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("blocklist export does not accept positional arguments")
+	}
+	if *validity <= 0 {
+		return errors.New("--validity-days must be positive")
+	}
+
+	list, err := blocklist.Build(database, *source, time.Duration(*validity)*24*time.Hour, time.Now())
+	if err != nil {
+		return err
+	}
+	var key ed25519.PrivateKey
+	if *signKey != "" {
+		key, err = blocklist.LoadPrivateKey(*signKey)
+		if err != nil {
+			return err
+		}
+	}
+	data, err := blocklist.Encode(list, key)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if *output == "-" {
+		_, err := stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		return fmt.Errorf("writing blocklist: %w", err)
+	}
+	return nil
+}
+
+func runBlocklistImport(args []string, stdout, stderr io.Writer, cfg *config.Config, database *db.Database) error {
+	fs := flag.NewFlagSet("blocklist import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	publicKey := fs.String("public-key", "", "Base64 ed25519 public key required for blocklists given as arguments")
+	format := fs.String("format", "text", "Output format: json or text")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if err := validateSimpleFormat(*format); err != nil {
+		return err
+	}
+
+	sources := cfg.BlocklistSources
+	if fs.NArg() > 0 {
+		sources = nil
+		for _, location := range fs.Args() {
+			sources = append(sources, config.BlocklistSource{URL: location, PublicKey: *publicKey})
+		}
+	}
+	if len(sources) == 0 {
+		return errors.New("blocklist import requires a location or blocklist_sources in config.json")
+	}
+
+	ctx := context.Background()
+	client := &http.Client{Timeout: 30 * time.Second}
+	var results []blocklistImportResult
+	failed := 0
+	for _, source := range sources {
+		result := blocklistImportResult{Source: source.Name, Location: source.URL}
+		if result.Source == "" {
+			result.Source = source.URL
+		}
+		imported, err := importBlocklist(ctx, client, database, result.Source, source)
+		result.Imported = imported
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	switch *format {
+	case "json":
+		if err := writeJSON(stdout, results); err != nil {
+			return err
+		}
+	default:
+		for _, result := range results {
+			if result.Error != "" {
+				fmt.Fprintf(stdout, "FAIL  %s: %s\n", result.Source, result.Error)
+				continue
+			}
+			fmt.Fprintf(stdout, "OK    %s: %d indicators\n", result.Source, result.Imported)
+		}
+	}
+	if failed > 0 {
+		return exitError{code: 1, message: fmt.Sprintf("blocklist import failed for %d of %d source(s)", failed, len(results))}
+	}
+	return nil
+}
+
+// importBlocklist fetches, verifies, and merges one source, replacing what it previously contributed.
+func importBlocklist(ctx context.Context, client *http.Client, database *db.Database, name string, source config.BlocklistSource) (int, error) {
+	key, err := blocklist.ParsePublicKey(source.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+	data, err := blocklist.Fetch(ctx, client, source.URL)
+	if err != nil {
+		return 0, err
+	}
+	list, err := blocklist.Decode(data, key)
+	if err != nil {
+		return 0, err
+	}
+	indicators := list.Indicators(name, time.Now())
+	if err := database.ReplaceExternalIndicators(name, indicators); err != nil {
+		return 0, err
+	}
+	return len(indicators), nil
+}
+
+func runBlocklistKeygen(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("blocklist keygen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	output := fs.String("output", "", "Write the private key to this path instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	public, private, err := blocklist.GenerateKey()
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		fmt.Fprintf(stdout, "public_key: %s\nprivate_key: %s\n", public, private)
+		return nil
+	}
+	if err := os.WriteFile(*output, []byte(private+"\n"), 0o600); err != nil {
+		return fmt.Errorf("writing blocklist signing key: %w", err)
+	}
+	fmt.Fprintf(stdout, "public_key: %s\n", public)
+	return nil
+}
+
+// runAllowlistCommand manages the known-good repos and users that scans never flag. The entity
+// type follows the target: owner/repo is a repo and anything else a user.
+func runAllowlistCommand(args []string, stdout, stderr io.Writer, database *db.Database) error {
+	if len(args) == 0 {
+		return errors.New("allowlist requires a subcommand: add, remove, or list")
+	}
+	subcommand := args[0]
+	fs := flag.NewFlagSet("allowlist "+subcommand, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	reason := fs.String("reason", "", "Why the target is known to be legitimate")
+	format := fs.String("format", "text", "Output format: json or text")
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if err := validateSimpleFormat(*format); err != nil {
+		return err
+	}
+
+	switch subcommand {
+	case "add":
+		if fs.NArg() != 1 {
+			return errors.New("allowlist add requires a single <owner/repo|username> argument")
+		}
+		if strings.TrimSpace(*reason) == "" {
+			return errors.New("allowlist add requires --reason")
 		}
-		checkpoint, err := database.GetSearchCheckpoint(fs.Arg(1))
-		if err != nil {
+		target := fs.Arg(0)
+		if err := database.AddAllowlistEntry(reportEntityType(target), target, *reason); err != nil {
 			return err
 		}
-		return writeCheckpoint(stdout, *format, checkpoint)
-	case "delete":
-		if fs.NArg() != 2 {
-			return errors.New("checkpoints delete requires a checkpoint name")
+		fmt.Fprintf(stdout, "allowlisted %s %s\n", reportEntityType(target), target)
+		return nil
+	case "remove":
+		if fs.NArg() != 1 {
+			return errors.New("allowlist remove requires a single <owner/repo|username> argument")
 		}
-		return database.DeleteSearchCheckpoint(fs.Arg(1))
-	case "export":
-		return runCheckpointExport(stdout, *format, database, fs.Args()[1:])
-	case "import":
-		return runCheckpointImport(stdout, *format, database, *input)
-	default:
-		return fmt.Errorf("unknown checkpoints subcommand %q", subcommand)
-	}
-}
-
-func runCheckpointExport(stdout io.Writer, format string, database *db.Database, args []string) error {
-	switch len(args) {
-	case 0:
-		checkpoints, err := database.ListSearchCheckpoints()
-		if err != nil {
+		target := fs.Arg(0)
+		if err := database.RemoveAllowlistEntry(reportEntityType(target), target); err != nil {
 			return err
 		}
-		return writeCheckpointList(stdout, format, checkpoints)
-	case 1:
-		checkpoint, err := database.GetSearchCheckpoint(args[0])
+		fmt.Fprintf(stdout, "removed %s %s from the allowlist\n", reportEntityType(target), target)
+		return nil
+	case "list":
+		entries, err := database.ListAllowlist()
 		if err != nil {
 			return err
 		}
-		return writeCheckpoint(stdout, format, checkpoint)
+		if *format == "json" {
+			if entries == nil {
+				entries = []db.AllowlistEntry{}
+			}
+			return writeJSON(stdout, entries)
+		}
+		if len(entries) == 0 {
+			fmt.Fprintln(stdout, "allowlist is empty")
+			return nil
+		}
+		for _, entry := range entries {
+			fmt.Fprintf(stdout, "%-4s  %s  %s  %s\n", entry.EntityType, entry.EntityID, entry.AddedAt.Format(time.RFC3339), entry.Reason)
+		}
+		return nil
 	default:
-		return errors.New("checkpoints export accepts at most one checkpoint name")
+		return fmt.Errorf("unknown allowlist subcommand %q", subcommand)
 	}
 }
 
-func runCheckpointImport(stdout io.Writer, format string, database *db.Database, inputPath string) error {
-	data, err := readCheckpointImportInput(inputPath)
-	if err != nil {
+func runSelftestCommand(args []string, stdout, stderr io.Writer, cfg *config.Config) error {
+	fs := flag.NewFlagSet("selftest", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "Output format: json or text")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
 		return err
 	}
-	checkpoints, err := decodeCheckpointImport(data)
+	if err := validateSimpleFormat(*format); err != nil {
+		return err
+	}
+
+	result, err := selftest.Run(context.Background(), newAnalyzerOptions(cfg))
 	if err != nil {
 		return err
 	}
-	for _, checkpoint := range checkpoints {
-		if err := database.UpsertSearchCheckpoint(checkpoint); err != nil {
-			return err
-		}
+	if err := writeSelftestReport(stdout, *format, result); err != nil {
+		return err
 	}
-	return writeCheckpointImportResult(stdout, format, len(checkpoints))
+	if !result.Passed {
+		return exitError{code: 1, message: fmt.Sprintf("selftest failed: %d detector(s) did not pass", result.FailedCount())}
+	}
+	return nil
 }
 
 func runCapabilitiesCommand(args []string, stdout, stderr io.Writer) error {
@@ -671,14 +1734,253 @@ func runRecommendCommand(args []string, stdout, stderr io.Writer) error {
 	}
 }
 
-func newScanService(cfg *config.Config, database *db.Database, appLogger *logger.Logger) *scan.Service {
+// newResponseCache returns the API response cache configured by cfg: on disk under cache_dir when
+// it is set, and in memory otherwise or when the directory cannot be created.
+func newResponseCache(cfg *config.Config, appLogger *logger.Logger) github.ResponseCache {
+	opts := github.CacheOptions{
+		MaxAge:     time.Duration(intValue(cfg.CacheMaxAge, int(github.DefaultCacheMaxAge/time.Minute))) * time.Minute,
+		MaxEntries: intValue(cfg.CacheMaxEntries, 0),
+	}
+	if cfg.CacheDir != "" {
+		cache, err := github.NewDiskCache(cfg.CacheDir, opts)
+		if err == nil {
+			return cache
+		}
+		appLogger.Warn("Disk cache disabled, caching in memory: %v", err)
+	}
+	return github.NewAPICacheWithOptions(opts)
+}
+
+// newScanService builds the scan service and the GitHub client it calls. Close both with
+// closeScanService.
+func newScanService(cfg *config.Config, database *db.Database, appLogger *logger.Logger) (*scan.Service, *github.Client) {
 	client := github.NewClient(
 		cfg.Token,
 		intValue(cfg.RateLimitBuffer, 500),
 		intValue(cfg.CacheTTL, 60),
+		newResponseCache(cfg, appLogger),
 		appLogger,
 	)
-	return scan.NewService(client, database)
+	client.SetOnRateLimit(cfg.OnRateLimit)
+	client.SetContributionSource(cfg.ContributionSource)
+	opts := scan.ServiceOptions{
+		SafeBrowsing:   safebrowsing.NewClient(cfg.SafeBrowsingKey, appLogger),
+		URLScan:        urlscan.NewClient(cfg.URLScanKey, appLogger),
+		Analyzer:       newAnalyzerOptions(cfg),
+		OnMalicious:    cfg.OnMalicious,
+		CoalesceOwners: cfg.CoalesceOwnerRepos,
+		StoredText: scan.StoredTextPolicy{
+			MaxChars:     intValue(cfg.StoredTextMaxChars, scan.DefaultStoredTextMaxChars),
+			RedactURLs:   cfg.RedactStoredURLs,
+			RedactEmails: cfg.RedactStoredEmails,
+		},
+	}
+	if cfg.MaxStargazers != nil {
+		opts.MaxStargazers = *cfg.MaxStargazers
+	}
+	if cfg.AssetDownload {
+		opts.AssetDownload = scan.AssetDownloadPolicy{
+			MaxBytes: int64(intValue(cfg.AssetDownloadMaxBytes, scan.DefaultAssetDownloadMaxBytes)),
+			Budget:   int64(intValue(cfg.AssetDownloadBudgetBytes, scan.DefaultAssetDownloadBudget)),
+		}
+	}
+	if cfg.MaliciousPackagesSource != "" {
+		packages, err := loadPackageList(context.Background(), cfg.MaliciousPackagesSource)
+		if err != nil {
+			appLogger.Warn("Dependency manifest check disabled: %v", err)
+		} else {
+			opts.Analyzer.MaliciousPackages = packages
+		}
+	}
+	if cfg.TyposquatCheck && cfg.PopularNamesSource != "" {
+		names, err := loadNameList(context.Background(), cfg.PopularNamesSource)
+		if err != nil {
+			appLogger.Warn("Typosquatting check uses the built-in popular names: %v", err)
+		} else {
+			opts.Analyzer.PopularNames = names
+		}
+	}
+	if cfg.OutboundLinkCheck {
+		for _, list := range []struct {
+			source string
+			target *analyzer.DomainList
+		}{
+			{cfg.LinkBlocklistSource, &opts.Analyzer.LinkBlocklist},
+			{cfg.LinkAllowlistSource, &opts.Analyzer.LinkAllowlist},
+		} {
+			if list.source == "" {
+				continue
+			}
+			domains, err := loadDomainList(context.Background(), list.source)
+			if err != nil {
+				appLogger.Warn("Outbound link check runs without %s: %v", list.source, err)
+				continue
+			}
+			*list.target = domains
+		}
+	}
+	if cfg.WebhookURL != "" {
+		opts.Notifier = notify.NewWebhookNotifier(cfg.WebhookURL, appLogger)
+	}
+	if cfg.OwnerReanalyzeDays != nil {
+		opts.ReuseOwnerAnalysis = true
+		opts.OwnerAnalysisTTL = time.Duration(*cfg.OwnerReanalyzeDays) * 24 * time.Hour
+	}
+	return scan.NewServiceWithOptions(client, database, opts), client
+}
+
+// closeScanService flushes the service's pending notifications before the command exits, then
+// closes the GitHub client.
+func closeScanService(service *scan.Service, client *github.Client, appLogger *logger.Logger) {
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := service.Close(ctx); err != nil {
+		appLogger.Warn("Some notifications were not delivered: %v", err)
+	}
+}
+
+// loadPackageList reads the known-malicious package list from a path or http(s) URL.
+func loadPackageList(ctx context.Context, source string) (analyzer.PackageList, error) {
+	data, err := blocklist.Fetch(ctx, &http.Client{Timeout: 30 * time.Second}, source)
+	if err != nil {
+		return nil, fmt.Errorf("loading malicious package list: %w", err)
+	}
+	return analyzer.ParsePackageList(string(data))
+}
+
+// loadDomainList reads an outbound link blocklist or allowlist from a path or http(s) URL.
+func loadDomainList(ctx context.Context, source string) (analyzer.DomainList, error) {
+	data, err := blocklist.Fetch(ctx, &http.Client{Timeout: 30 * time.Second}, source)
+	if err != nil {
+		return nil, fmt.Errorf("loading domain list: %w", err)
+	}
+	return analyzer.ParseDomainList(string(data))
+}
+
+// loadNameList reads the popular repository names of the typosquatting check from a path or
+// http(s) URL.
+func loadNameList(ctx context.Context, source string) (analyzer.NameList, error) {
+	data, err := blocklist.Fetch(ctx, &http.Client{Timeout: 30 * time.Second}, source)
+	if err != nil {
+		return nil, fmt.Errorf("loading popular name list: %w", err)
+	}
+	return analyzer.ParseNameList(string(data))
+}
+
+func newAnalyzerOptions(cfg *config.Config) analyzer.Options {
+	opts := analyzer.Options{
+		MaliciousSeverity:        cfg.MaliciousMinSeverity,
+		DuplicateContentMinRepos: intValue(cfg.DuplicateContentMinRepos, analyzer.DefaultDuplicateContentMinRepos),
+		SharedPayloadMinRepos:    intValue(cfg.SharedPayloadMinRepos, analyzer.DefaultSharedPayloadMinRepos),
+		CommitSampleSize:         intValue(cfg.CommitSampleSize, analyzer.DefaultCommitSampleSize),
+		StargazerSampleSize:      intValue(cfg.StargazerSampleSize, 0),
+		ReadmeSampleSize:         intValue(cfg.ReadmeSampleSize, 0),
+		TierHeuristics:           cfg.TierHeuristics,
+		EnabledHeuristics:        cfg.EnabledHeuristics,
+		DisabledHeuristics:       cfg.DisabledHeuristics,
+		HeuristicWeights:         cfg.HeuristicWeights,
+		CamelCaseNumberThreshold: intValue(cfg.CamelCaseNumberThreshold, analyzer.DefaultCamelCaseNumberThreshold),
+		ForkFarmMaxAge:           time.Duration(intValue(cfg.ForkFarmMaxAgeDays, int(analyzer.DefaultForkFarmMaxAge/(24*time.Hour)))) * 24 * time.Hour,
+		Thresholds: analyzer.RepoThresholds{
+			EmptySize:            intValue(cfg.EmptyRepoSizeThreshold, analyzer.DefaultEmptyRepoSizeThreshold),
+			SuspiciousEmptyStars: intValue(cfg.SuspiciousEmptyStarThreshold, analyzer.DefaultSuspiciousEmptyStarThreshold),
+		},
+		Evidence: analyzer.EvidencePolicy{
+			MinHeuristics:          intValue(cfg.FlagMinHeuristics, 1),
+			HighSeverityCategories: cfg.FlagHighSeverityCategories,
+		},
+	}
+	if opts.CommitSampleSize == 0 {
+		opts.CommitSampleSize = -1
+	}
+	if cfg.DeepHistoryCheck {
+		opts.HistoryCommits = intValue(cfg.DeepHistoryCommits, analyzer.DefaultHistoryCommits)
+	}
+	if cfg.StarFarmCheck {
+		opts.StarFarmMinStars = intValue(cfg.StarFarmMinStars, analyzer.DefaultStarFarmMinStars)
+	}
+	if cfg.TopicSpamCheck {
+		opts.TopicSpamMinTopics = intValue(cfg.TopicSpamMinTopics, analyzer.DefaultTopicSpamMinTopics)
+	}
+	if cfg.BinaryOnlyCheck {
+		opts.BinaryOnlyMinStars = intValue(cfg.BinaryOnlyMinStars, analyzer.DefaultBinaryOnlyMinStars)
+	}
+	if cfg.ReleaseCheck {
+		opts.ReleaseMaxOwnerAge = time.Duration(intValue(cfg.ReleaseMaxOwnerAgeDays, int(analyzer.DefaultReleaseMaxOwnerAge/(24*time.Hour)))) * 24 * time.Hour
+		opts.ReleaseDownloadRatio = intValue(cfg.ReleaseDownloadRatio, analyzer.DefaultReleaseDownloadRatio)
+	}
+	if cfg.TyposquatCheck {
+		opts.TyposquatMaxOwnerAge = time.Duration(intValue(cfg.TyposquatMaxOwnerAgeDays, int(analyzer.DefaultTyposquatMaxOwnerAge/(24*time.Hour)))) * 24 * time.Hour
+	}
+	if cfg.CommitMessageCheck {
+		opts.CommitMessageCommits = intValue(cfg.CommitMessageCommits, analyzer.DefaultCommitMessageCommits)
+	}
+	if cfg.CommitCadenceCheck {
+		opts.CommitCadenceCommits = intValue(cfg.CommitCadenceCommits, analyzer.DefaultCommitCadenceCommits)
+		opts.CommitCadenceSameSecond = intValue(cfg.CommitCadenceSameSecond, analyzer.DefaultCommitCadenceSameSecond)
+		if cfg.CommitCadenceMaxVariation != nil {
+			opts.CommitCadenceMaxVariation = *cfg.CommitCadenceMaxVariation
+		}
+	}
+	opts.ManifestCheck = cfg.ManifestCheck
+	if cfg.WorkflowCheck {
+		opts.WorkflowMaxFiles = intValue(cfg.WorkflowMaxFiles, analyzer.DefaultWorkflowMaxFiles)
+	}
+	if cfg.PayloadBlobCheck {
+		opts.PayloadBlobMinLength = intValue(cfg.PayloadBlobMinLength, analyzer.DefaultPayloadBlobMinLength)
+	}
+	if cfg.ObfuscationCheck {
+		opts.ObfuscationThreshold = intValue(cfg.ObfuscationThreshold, analyzer.DefaultObfuscationThreshold)
+	}
+	opts.SourceSampleMaxFiles = intValue(cfg.SourceSampleMaxFiles, analyzer.DefaultSourceSampleMaxFiles)
+	opts.SourceSampleMaxBytes = intValue(cfg.SourceSampleMaxBytes, analyzer.DefaultSourceSampleMaxBytes)
+	if cfg.OutboundLinkCheck {
+		opts.LinkResolver = analyzer.NewRedirectResolver(
+			intValue(cfg.LinkRedirectMaxHops, analyzer.DefaultRedirectMaxHops),
+			time.Duration(intValue(cfg.LinkRedirectTimeout, int(analyzer.DefaultRedirectTimeout/time.Second)))*time.Second,
+		)
+	}
+	if cfg.UsernamePatterns != nil {
+		// config.Load has already rejected patterns that do not compile.
+		opts.UsernamePatterns, _ = analyzer.CompileUsernamePatterns(cfg.UsernamePatterns)
+	}
+	if cfg.KeywordRules.ReadmeMarkers != nil {
+		opts.ReadmeRules = keywordRules(cfg.KeywordRules.ReadmeMarkers)
+	}
+	if cfg.KeywordRules.DescriptionMarkers != nil {
+		opts.DescriptionRules = keywordRules(cfg.KeywordRules.DescriptionMarkers)
+	}
+	opts.CommitMarkers = keywordRules(cfg.KeywordRules.CommitMarkers)
+	if cfg.KeywordRules.SuspiciousTLDs != nil {
+		opts.SuspiciousTLDs = cfg.KeywordRules.SuspiciousTLDs
+	}
+	if cfg.TemplateUniformityThreshold != nil {
+		opts.TemplateUniformityThreshold = *cfg.TemplateUniformityThreshold
+	}
+	if cfg.UserScoreThreshold != nil {
+		opts.UserScoreThreshold = *cfg.UserScoreThreshold
+	}
+	if cfg.ExternalCommand != "" {
+		opts.ExternalCommand = &analyzer.ExternalCommand{
+			Path:    cfg.ExternalCommand,
+			Args:    cfg.ExternalCommandArgs,
+			Timeout: time.Duration(intValue(cfg.ExternalCommandTimeout, 10)) * time.Second,
+		}
+	}
+	return opts
+}
+
+// keywordRules converts configured keyword rules, keeping an empty list distinct from nil.
+func keywordRules(rules []config.KeywordRule) []analyzer.KeywordRule {
+	if rules == nil {
+		return nil
+	}
+	converted := make([]analyzer.KeywordRule, 0, len(rules))
+	for _, rule := range rules {
+		converted = append(converted, analyzer.KeywordRule{Name: rule.Name, Phrases: rule.Phrases, Category: rule.Category})
+	}
+	return converted
 }
 
 func loadConfig(configPath string) (*config.Config, error) {
@@ -711,7 +2013,11 @@ func openRuntime(configPath, dbPath string, quiet bool) (*config.Config, *db.Dat
 		return nil, nil, nil, err
 	}
 
-	appLogger := logger.NewWithQuiet(cfg.Verbose != nil && *cfg.Verbose, quiet)
+	appLogger := logger.NewWithQuiet(cfg.Verbose != nil && *cfg.Verbose, quiet).WithLevels(logLevels(cfg.LogLevels))
+	if level, err := logger.ParseLevel(cfg.LogLevel); err == nil {
+		// config.Load has already rejected invalid levels, so only an unset one fails here.
+		appLogger = appLogger.WithLevel(level)
+	}
 	database, err := db.New(dbPath)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("opening database: %w", err)
@@ -720,6 +2026,31 @@ func openRuntime(configPath, dbPath string, quiet bool) (*config.Config, *db.Dat
 	return cfg, database, appLogger, nil
 }
 
+// logLevels parses the configured per-subsystem levels. config.Load has already rejected
+// invalid ones.
+func logLevels(configured map[string]string) map[string]logger.Level {
+	levels := make(map[string]logger.Level, len(configured))
+	for subsystem, name := range configured {
+		if level, err := logger.ParseLevel(name); err == nil {
+			levels[strings.ToLower(subsystem)] = level
+		}
+	}
+	return levels
+}
+
+// openLocalRuntime opens the config and database for commands that never call the GitHub API.
+func openLocalRuntime(configPath, dbPath string) (*config.Config, *db.Database, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	database, err := db.New(dbPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening database: %w", err)
+	}
+	return cfg, database, nil
+}
+
 func writeSearchReport(w io.Writer, format string, report scan.SearchReport) error {
 	switch format {
 	case "json":
@@ -749,12 +2080,17 @@ func writeSearchReport(w io.Writer, format string, report scan.SearchReport) err
 		sb.WriteString(fmt.Sprintf("Completed: %s\n", report.CompletedAt.Format(time.RFC3339)))
 		sb.WriteString(fmt.Sprintf("Repositories: %d total, %d analyzed, %d flagged\n",
 			len(report.Results), report.AnalyzedCount(), report.FlaggedCount()))
+		sb.WriteString(fmt.Sprintf("Users: %d analyzed, %d flagged, %d cache hits, %d errors\n",
+			report.UserAnalysis.Analyzed, report.UserAnalysis.Flagged, report.UserAnalysis.CacheHits, report.UserAnalysis.Errored))
 		if !report.OldestCreatedAt.IsZero() {
 			sb.WriteString(fmt.Sprintf("Oldest created_at: %s\n", report.OldestCreatedAt.Format(time.RFC3339)))
 		}
 		if !report.OldestUpdatedAt.IsZero() {
 			sb.WriteString(fmt.Sprintf("Oldest updated_at: %s\n", report.OldestUpdatedAt.Format(time.RFC3339)))
 		}
+		for _, page := range report.IncompletePages {
+			sb.WriteString(fmt.Sprintf("Incomplete: page %d of %s timed out on GitHub\n", page.Page, page.Query))
+		}
 		for _, result := range report.Results {
 			status := "clean"
 			if result.Skipped {
@@ -792,14 +2128,52 @@ func writeRepoReport(w io.Writer, format string, report scan.RepoReport) error {
 	case "text":
 		var sb strings.Builder
 		sb.WriteString(fmt.Sprintf("Repository: %s\n", report.RepoID))
+		if report.GitHubID != 0 {
+			sb.WriteString(fmt.Sprintf("GitHub ID: %d (%s)\n", report.GitHubID, report.NodeID))
+		}
+		if report.RenamedFrom != "" {
+			sb.WriteString(fmt.Sprintf("Renamed from: %s\n", report.RenamedFrom))
+		}
 		sb.WriteString(fmt.Sprintf("Created: %s\n", report.CreatedAt.Format(time.RFC3339)))
 		sb.WriteString(fmt.Sprintf("Updated: %s\n", report.UpdatedAt.Format(time.RFC3339)))
 		sb.WriteString(fmt.Sprintf("Disk usage: %d KB\n", report.DiskUsage))
 		sb.WriteString(fmt.Sprintf("Stargazers: %d\n", report.Stargazers))
 		sb.WriteString(fmt.Sprintf("Malicious: %t\n", report.IsMalicious))
+		if report.Status != "" {
+			sb.WriteString(fmt.Sprintf("Status: %s\n", report.Status))
+		}
+		if report.TakedownConfirmed {
+			sb.WriteString("Takedown confirmed: flagged before GitHub removed it\n")
+		}
+		for _, result := range report.CheckerResults {
+			status := "clean"
+			if result.Flagged {
+				status = "flagged"
+			}
+			line := fmt.Sprintf("Checker: %s %s (%s)", result.Name, status, result.Severity)
+			if result.Evidence != "" {
+				line += " - " + result.Evidence
+			}
+			sb.WriteString(line + "\n")
+		}
 		sb.WriteString(fmt.Sprintf("Repo flags: %d\n", len(report.RepoFlags)))
+		if len(report.HeldFlags) > 0 {
+			sb.WriteString(fmt.Sprintf("Held flags: %d, below the evidence policy\n", len(report.HeldFlags)))
+		}
+		for _, verdict := range report.LinkVerdicts {
+			if verdict.IsThreat() {
+				sb.WriteString(fmt.Sprintf("Link threat: %s (%s)\n", verdict.URL, verdict.ThreatType))
+			}
+		}
+		for _, result := range report.URLScans {
+			sb.WriteString(fmt.Sprintf("URL scan: %s -> %s (%s)\n", result.URL, result.ResultURL, result.Verdict))
+		}
 		if report.OwnerAnalysis != nil {
-			sb.WriteString(fmt.Sprintf("Owner suspicious: %t\n", report.OwnerAnalysis.Suspicious))
+			line := fmt.Sprintf("Owner suspicious: %t", report.OwnerAnalysis.Suspicious)
+			if report.OwnerAnalysis.Reused {
+				line += " (stored analysis)"
+			}
+			sb.WriteString(line + "\n")
 		}
 		if report.Skipped {
 			sb.WriteString(fmt.Sprintf("Skipped: %s\n", report.SkipReason))
@@ -826,10 +2200,22 @@ func writeRepoSummary(w io.Writer, format string, summary repoSummary) error {
 		sb.WriteString(fmt.Sprintf("Flagged: %t\n", summary.IsFlagged))
 		sb.WriteString(fmt.Sprintf("Malicious: %t\n", summary.IsMalicious))
 		sb.WriteString(fmt.Sprintf("Owner suspicious: %t\n", summary.OwnerSuspicious))
+		if summary.RenamedFrom != "" {
+			sb.WriteString(fmt.Sprintf("Renamed from: %s\n", summary.RenamedFrom))
+		}
+		if summary.Status != "" {
+			sb.WriteString(fmt.Sprintf("Status: %s\n", summary.Status))
+		}
+		if summary.TakedownConfirmed {
+			sb.WriteString("Takedown confirmed: true\n")
+		}
 		sb.WriteString(fmt.Sprintf("Repo flag count: %d\n", summary.RepoFlagCount))
 		for _, flag := range summary.RepoFlags {
 			sb.WriteString(fmt.Sprintf("Flag: %s\n", flag))
 		}
+		for _, checker := range summary.FlaggedCheckers {
+			sb.WriteString(fmt.Sprintf("Checker: %s\n", checker))
+		}
 		for _, err := range summary.Errors {
 			sb.WriteString(fmt.Sprintf("Error: %s\n", err))
 		}
@@ -849,8 +2235,27 @@ func writeUserReport(w io.Writer, format string, report scan.UserReport) error {
 	case "text":
 		var sb strings.Builder
 		sb.WriteString(fmt.Sprintf("User: %s\n", report.Username))
+		if report.OwnerType == models.OwnerTypeOrganization {
+			sb.WriteString("Type: organization\n")
+		}
+		if report.GitHubID != 0 {
+			sb.WriteString(fmt.Sprintf("GitHub ID: %d (%s)\n", report.GitHubID, report.NodeID))
+		}
+		if report.PreviousGitHubID != 0 {
+			sb.WriteString(fmt.Sprintf("Re-registered: login previously held by GitHub ID %d; its flags were cleared\n", report.PreviousGitHubID))
+		}
 		sb.WriteString(fmt.Sprintf("Created: %s\n", report.CreatedAt.Format(time.RFC3339)))
 		sb.WriteString(fmt.Sprintf("Suspicious: %t\n", report.Suspicious))
+		sb.WriteString(fmt.Sprintf("Score: %.2f\n", report.Score))
+		if report.Allowlisted {
+			sb.WriteString("Allowlisted: not analyzed\n")
+		}
+		if report.InsufficientEvidence {
+			sb.WriteString("Insufficient evidence: flags below the evidence policy are not recorded\n")
+		}
+		if report.Tier != "" {
+			sb.WriteString(fmt.Sprintf("Tier: %s\n", report.Tier))
+		}
 		sb.WriteString(fmt.Sprintf("Contributions: %d\n", report.Contributions))
 		sb.WriteString(fmt.Sprintf("Total stars: %d\n", report.TotalStars))
 		sb.WriteString(fmt.Sprintf("Empty repos: %d\n", report.EmptyCount))
@@ -880,6 +2285,7 @@ func writeUserSummary(w io.Writer, format string, summary userSummary) error {
 		var sb strings.Builder
 		sb.WriteString(fmt.Sprintf("User: %s\n", summary.Username))
 		sb.WriteString(fmt.Sprintf("Suspicious: %t\n", summary.IsSuspicious))
+		sb.WriteString(fmt.Sprintf("Score: %.2f\n", summary.Score))
 		sb.WriteString(fmt.Sprintf("Heuristic count: %d\n", summary.HeuristicCount))
 		sb.WriteString(fmt.Sprintf("Contributions: %d\n", summary.Contributions))
 		sb.WriteString(fmt.Sprintf("Total stars: %d\n", summary.TotalStars))
@@ -923,6 +2329,125 @@ func writeVerdictErrorSummary(w io.Writer, format string, summary verdictErrorSu
 	}
 }
 
+func writeAbuseReportList(w io.Writer, format string, reports []db.AbuseReport) error {
+	switch format {
+	case "json":
+		if reports == nil {
+			reports = []db.AbuseReport{}
+		}
+		return writeJSON(w, reports)
+	case "text":
+		var sb strings.Builder
+		if len(reports) == 0 {
+			sb.WriteString("No abuse reports stored.\n")
+		}
+		for _, report := range reports {
+			sb.WriteString(fmt.Sprintf("%s %s: %s (generated %s)\n", report.EntityType, report.EntityID, report.Status, report.GeneratedAt.Format(time.RFC3339)))
+		}
+		_, err := io.WriteString(w, sb.String())
+		return err
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func writeMarkdownReport(w io.Writer, format string, result report.MarkdownReport) error {
+	if format == "json" {
+		return writeJSON(w, result)
+	}
+	_, err := io.WriteString(w, report.RenderMarkdown(result))
+	return err
+}
+
+// writeWeeklyReport writes the summary into dir under its dated file name, as Markdown and
+// optionally HTML, and returns the written paths.
+func writeWeeklyReport(dir string, result report.WeeklyReport, withHTML bool) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating report directory: %w", err)
+	}
+	base := filepath.Join(dir, report.WeeklyFileName(result.Until))
+	contents := map[string]string{base + ".md": report.RenderWeeklyMarkdown(result)}
+	if withHTML {
+		page, err := report.RenderWeeklyHTML(result)
+		if err != nil {
+			return nil, err
+		}
+		contents[base+".html"] = page
+	}
+	var paths []string
+	for _, path := range []string{base + ".md", base + ".html"} {
+		content, ok := contents[path]
+		if !ok {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return nil, fmt.Errorf("writing weekly summary: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func writeSelftestReport(w io.Writer, format string, result selftest.Report) error {
+	switch format {
+	case "json":
+		return writeJSON(w, result)
+	case "text":
+		for _, detector := range result.Detectors {
+			status := "PASS"
+			if !detector.Passed {
+				status = "FAIL"
+			}
+			fmt.Fprintf(w, "%s  %s (%d cases)\n", status, detector.Detector, len(detector.Cases))
+			if detector.Error != "" {
+				fmt.Fprintf(w, "      %s\n", detector.Error)
+			}
+			for _, c := range detector.Cases {
+				if c.Passed {
+					continue
+				}
+				fmt.Fprintf(w, "      %s: expected flag=%t, got %t", c.Name, c.Expected, c.Got)
+				if c.Error != "" {
+					fmt.Fprintf(w, " (%s)", c.Error)
+				}
+				fmt.Fprintln(w)
+			}
+		}
+		fmt.Fprintf(w, "%d of %d detectors passed\n", len(result.Detectors)-result.FailedCount(), len(result.Detectors))
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func writeURLScans(w io.Writer, format string, scans []db.URLScan) error {
+	switch format {
+	case "json":
+		if len(scans) == 1 {
+			return writeJSON(w, scans[0])
+		}
+		return writeJSON(w, scans)
+	case "text":
+		var sb strings.Builder
+		for _, scan := range scans {
+			sb.WriteString(fmt.Sprintf("URL: %s\n", scan.URL))
+			if scan.RepoID != "" {
+				sb.WriteString(fmt.Sprintf("Repository: %s\n", scan.RepoID))
+			}
+			sb.WriteString(fmt.Sprintf("Verdict: %s (score %d)\n", scan.Verdict, scan.Score))
+			sb.WriteString(fmt.Sprintf("Result: %s\n", scan.ResultURL))
+			if scan.ScreenshotURL != "" {
+				sb.WriteString(fmt.Sprintf("Screenshot: %s\n", scan.ScreenshotURL))
+			}
+			sb.WriteString("\n")
+		}
+		_, err := io.WriteString(w, sb.String())
+		return err
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
 func writeJSON(w io.Writer, value interface{}) error {
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
@@ -1082,6 +2607,31 @@ func normalizeSearchDate(value string) (string, error) {
 	return "", fmt.Errorf("expected YYYY-MM-DD or RFC3339, got %q", value)
 }
 
+// parseWindowStart parses a relative window such as 7d, counted back from now, or a
+// YYYY-MM-DD date or RFC3339 time.
+func parseWindowStart(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return time.Time{}, fmt.Errorf("expected a positive number of days such as 7d, got %q", value)
+		}
+		return now.AddDate(0, 0, -n), nil
+	}
+	return parseDateOrTime(value)
+}
+
+// parseDateOrTime parses a YYYY-MM-DD date as UTC midnight, or an RFC3339 time.
+func parseDateOrTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or RFC3339, got %q", value)
+}
+
 func parseRepoRef(value string) (string, string, error) {
 	parts := strings.Split(value, "/")
 	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
@@ -1124,20 +2674,6 @@ func listProfilesRequested(args []string) bool {
 	return false
 }
 
-func nextUpdatedBefore(oldest time.Time) string {
-	if oldest.IsZero() {
-		return ""
-	}
-	return oldest.Add(-1 * time.Second).UTC().Format(time.RFC3339)
-}
-
-func nextCreatedBefore(oldest time.Time) string {
-	if oldest.IsZero() {
-		return ""
-	}
-	return oldest.Add(-1 * time.Second).UTC().Format(time.RFC3339)
-}
-
 func resolveSearchProfile(name string) (searchProfile, error) {
 	return resolveSearchProfileAt(name, time.Now().UTC())
 }
@@ -1309,6 +2845,25 @@ func writeCheckpointImportResult(w io.Writer, format string, imported int) error
 	}
 }
 
+// resolveSinceValue turns --since last-run into the start time of the previous successful search
+// recorded under runName. With no recorded run the search is unbounded.
+func resolveSinceValue(database *db.Database, runName, value string) (string, error) {
+	if strings.TrimSpace(value) != lastRunSince {
+		return value, nil
+	}
+	if database == nil {
+		return "", errors.New("--since last-run requires a database")
+	}
+	startedAt, err := database.LastSearchRun(runName)
+	if err != nil {
+		return "", err
+	}
+	if startedAt.IsZero() {
+		return "", nil
+	}
+	return startedAt.UTC().Format(time.RFC3339), nil
+}
+
 func saveSearchCheckpoint(database *db.Database, report scan.SearchReport) error {
 	if database == nil || report.CheckpointName == "" {
 		return nil
@@ -1335,17 +2890,25 @@ func saveSearchCheckpoint(database *db.Database, report scan.SearchReport) error
 
 func summarizeRepoReport(report scan.RepoReport) repoSummary {
 	summary := repoSummary{
-		EntityType:      "repo",
-		RepoID:          report.RepoID,
-		IsFlagged:       report.IsFlagged(),
-		IsMalicious:     report.IsMalicious,
-		OwnerSuspicious: report.OwnerAnalysis != nil && report.OwnerAnalysis.Suspicious,
-		RepoFlagCount:   len(report.RepoFlags),
-		Errors:          append([]string(nil), report.Errors...),
+		EntityType:        "repo",
+		RepoID:            report.RepoID,
+		IsFlagged:         report.IsFlagged(),
+		IsMalicious:       report.IsMalicious,
+		OwnerSuspicious:   report.OwnerAnalysis != nil && report.OwnerAnalysis.Suspicious,
+		RenamedFrom:       report.RenamedFrom,
+		Status:            report.Status,
+		TakedownConfirmed: report.TakedownConfirmed,
+		RepoFlagCount:     len(report.RepoFlags),
+		Errors:            append([]string(nil), report.Errors...),
 	}
 	for _, flag := range report.RepoFlags {
 		summary.RepoFlags = append(summary.RepoFlags, fmt.Sprintf("%s:%s", flag.Category, flag.Name))
 	}
+	for _, result := range report.CheckerResults {
+		if result.Flagged {
+			summary.FlaggedCheckers = append(summary.FlaggedCheckers, result.Name)
+		}
+	}
 	return summary
 }
 
@@ -1354,6 +2917,8 @@ func summarizeUserReport(report scan.UserReport) userSummary {
 		EntityType:    "user",
 		Username:      report.Username,
 		IsSuspicious:  report.Suspicious,
+		Tier:          report.Tier,
+		Score:         report.Score,
 		Contributions: report.Contributions,
 		TotalStars:    report.TotalStars,
 		Errors:        append([]string(nil), report.Errors...),
@@ -1600,9 +3165,6 @@ func writeSearchNDJSON(
 	if err != nil {
 		return report, err
 	}
-	report.NextCreatedBefore = nextCreatedBefore(report.OldestCreatedAt)
-	report.NextUpdatedBefore = nextUpdatedBefore(report.OldestUpdatedAt)
-
 	return report, writeCompactJSON(w, searchNDJSONEvent{
 		Type: "summary",
 		Summary: &searchSummary{
@@ -1627,6 +3189,7 @@ func writeSearchNDJSON(
 			AnalyzedCount:     report.AnalyzedCount(),
 			FlaggedCount:      report.FlaggedCount(),
 			EmittedCount:      emittedCount,
+			UserAnalysis:      report.UserAnalysis,
 		},
 	})
 }
@@ -2,15 +2,24 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/arkouda/github/GitHubWatchdog/internal/analyzer"
+	"github.com/arkouda/github/GitHubWatchdog/internal/config"
 	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/github/githubtest"
+	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
 	"github.com/arkouda/github/GitHubWatchdog/internal/models"
 	"github.com/arkouda/github/GitHubWatchdog/internal/scan"
+	"github.com/arkouda/github/GitHubWatchdog/internal/selftest"
 )
 
 func TestParseRepoRef(t *testing.T) {
@@ -137,6 +146,41 @@ func TestBuildSearchQuerySingleBound(t *testing.T) {
 	}
 }
 
+func TestBuildSearchQuerySinceLastRun(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	defer database.Close()
+
+	since, err := resolveSinceValue(database, "", lastRunSince)
+	if err != nil || since != "" {
+		t.Fatalf("resolveSinceValue(no runs) = %q, %v, want unbounded", since, err)
+	}
+
+	startedAt := time.Date(2026, 3, 12, 6, 30, 0, 0, time.UTC)
+	if err := database.RecordSearchRun("", startedAt, startedAt.Add(time.Hour)); err != nil {
+		t.Fatalf("RecordSearchRun() error = %v", err)
+	}
+	since, err = resolveSinceValue(database, "", lastRunSince)
+	if err != nil {
+		t.Fatalf("resolveSinceValue() error = %v", err)
+	}
+	plan, err := buildSearchQueryPlan("stars:>5", searchTimeFilters{Activity: "updated", UpdatedSince: since})
+	if err != nil {
+		t.Fatalf("buildSearchQueryPlan() error = %v", err)
+	}
+	if got := plan.PrimaryQuery(); got != "stars:>5 updated:>=2026-03-12T06:30:00Z" {
+		t.Fatalf("PrimaryQuery(last-run) = %q", got)
+	}
+	if since, _ := resolveSinceValue(database, "nightly", lastRunSince); since != "" {
+		t.Fatalf("resolveSinceValue(other run) = %q, want runs tracked per checkpoint", since)
+	}
+	if since, _ := resolveSinceValue(database, "", "2026-03-01"); since != "2026-03-01" {
+		t.Fatalf("resolveSinceValue(date) = %q, want unchanged", since)
+	}
+}
+
 func TestBuildSearchQueryRejectsDuplicateUpdatedQualifier(t *testing.T) {
 	if _, err := buildSearchQueryPlan("stars:>5 updated:>=2026-03-01", searchTimeFilters{
 		Activity:     "updated",
@@ -180,6 +224,19 @@ func TestNormalizeSearchDate(t *testing.T) {
 	}
 }
 
+func TestParseWindowStart(t *testing.T) {
+	now := time.Date(2026, 3, 16, 12, 0, 0, 0, time.UTC)
+	if got, err := parseWindowStart("7d", now); err != nil || !got.Equal(now.AddDate(0, 0, -7)) {
+		t.Fatalf("parseWindowStart(7d) = %v, %v", got, err)
+	}
+	if got, err := parseWindowStart("2026-03-01", now); err != nil || !got.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("parseWindowStart(date) = %v, %v", got, err)
+	}
+	if _, err := parseWindowStart("0d", now); err == nil {
+		t.Fatal("parseWindowStart(0d) expected error")
+	}
+}
+
 func TestResolveSearchProfile(t *testing.T) {
 	now := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
 	profile, err := resolveSearchProfileAt("recent", now)
@@ -224,7 +281,7 @@ func TestBuildCapabilityCatalogIncludesCommands(t *testing.T) {
 	for _, command := range caps.Commands {
 		names = append(names, command.Name)
 	}
-	for _, name := range []string{"search", "repo", "user", "verdict", "checkpoints", "capabilities", "recommend"} {
+	for _, name := range []string{"search", "repo", "user", "verdict", "checkpoints", "flags", "rings", "report", "urlscan", "export", "import", "blocklist", "allowlist", "db", "maintenance", "selftest", "capabilities", "recommend"} {
 		if !strings.Contains(strings.Join(names, ","), name) {
 			t.Fatalf("buildCapabilityCatalog() missing %q in %v", name, names)
 		}
@@ -269,16 +326,6 @@ func TestRecommendTaskCheckpoint(t *testing.T) {
 	}
 }
 
-func TestNextUpdatedBefore(t *testing.T) {
-	oldest := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
-	if got := nextUpdatedBefore(oldest); got != "2026-03-13T11:59:59Z" {
-		t.Fatalf("nextUpdatedBefore() = %q", got)
-	}
-	if got := nextUpdatedBefore(time.Time{}); got != "" {
-		t.Fatalf("nextUpdatedBefore(zero) = %q, want empty", got)
-	}
-}
-
 func TestValidateCheckpointFormat(t *testing.T) {
 	if err := validateCheckpointFormat("json"); err != nil {
 		t.Fatalf("validateCheckpointFormat(json) error = %v", err)
@@ -523,3 +570,141 @@ func TestWriteUserSummary(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteSelftestReportText(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeSelftestReport(&buf, "text", selftest.Report{
+		Detectors: []selftest.DetectorResult{
+			{Detector: "ReadmeChecker", Passed: true, Cases: []selftest.CaseResult{{Name: "bad", Expected: true, Got: true, Passed: true}}},
+			{Detector: "LoaderChecker", Cases: []selftest.CaseResult{{Name: "loader archive", Expected: true, Got: false}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("writeSelftestReport() error = %v", err)
+	}
+	output := buf.String()
+	for _, needle := range []string{"PASS  ReadmeChecker", "FAIL  LoaderChecker", "loader archive: expected flag=true, got false", "1 of 2 detectors passed"} {
+		if !strings.Contains(output, needle) {
+			t.Fatalf("writeSelftestReport() missing %q in %q", needle, output)
+		}
+	}
+}
+
+func TestRunUsesConfiguredDBPath(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	dbPath := filepath.Join(dir, "data", "watchdog.db")
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte(`{"db_path": "`+filepath.ToSlash(dbPath)+`"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := Run([]string{"-config", configPath, "checkpoints", "list"}, &stdout, &stderr); err != nil {
+		t.Fatalf("Run() error = %v, stderr = %s", err, stderr.String())
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Fatalf("configured database not created: %v", err)
+	}
+
+	if err := Run([]string{"-config", configPath, "-db", db.MemoryPath, "checkpoints", "list"}, &stdout, &stderr); err != nil {
+		t.Fatalf("Run(-db :memory:) error = %v", err)
+	}
+}
+
+func TestConfiguredReadmeMarkersFlagRepos(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	sample := `{"keyword_rules": {"readme_markers": [{"name": "CrackedLoaderMarker", "phrases": ["cracked", "loader.exe"], "category": "Scam"}]}}`
+	if err := os.WriteFile(configPath, []byte(sample), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+
+	checker := &analyzer.ReadmeChecker{Rules: newAnalyzerOptions(cfg).ReadmeRules}
+	repo := models.RepoData{Readme: "Free CRACKED tool. Run Loader.exe as admin."}
+	if flagged, err := checker.Check(context.Background(), repo); err != nil || !flagged {
+		t.Fatalf("Check() = %v, %v, want the configured marker flagged", flagged, err)
+	}
+	matches := checker.Matches(repo)
+	if len(matches) != 1 || matches[0].Name != "CrackedLoaderMarker" || matches[0].Category != "Scam" {
+		t.Fatalf("Matches() = %+v, want one CrackedLoaderMarker match", matches)
+	}
+	if flagged, _ := checker.Check(context.Background(), models.RepoData{Readme: "download link\npassword : 2025"}); flagged {
+		t.Fatal("Check() flagged the default marker, want configured rules to replace the defaults")
+	}
+
+	if err := os.WriteFile(configPath, []byte(`{"keyword_rules": {"readme_markers": [{"name": "Empty"}]}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Load(configPath); err == nil {
+		t.Fatal("config.Load() accepted a rule without phrases")
+	}
+}
+
+func TestPublishFindingsUpdatesOrCreatesIssue(t *testing.T) {
+	server := githubtest.NewServer(t)
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	target := publishTarget{To: "issue", Repo: "watch/findings", Title: defaultPublishTitle}
+
+	server.HandleJSON("/repos/watch/findings/issues", []map[string]interface{}{
+		{"number": 3, "title": defaultPublishTitle, "pull_request": map[string]string{}},
+		{"number": 7, "title": defaultPublishTitle, "html_url": "https://github.com/watch/findings/issues/7"},
+	})
+	server.HandleJSON("/repos/watch/findings/issues/7", map[string]interface{}{"number": 7, "html_url": "https://github.com/watch/findings/issues/7"})
+	updated, err := publishFindings(context.Background(), client, target, "# Findings")
+	if err != nil {
+		t.Fatalf("publishFindings(existing) error = %v", err)
+	}
+	if updated.Created || updated.Number != 7 {
+		t.Fatalf("publishFindings(existing) = %+v, want issue 7 updated", updated)
+	}
+	patch := server.Requests()[len(server.Requests())-1]
+	if patch.Method != "PATCH" || patch.Path != "/repos/watch/findings/issues/7" || !strings.Contains(patch.Body, `"body":"# Findings"`) {
+		t.Fatalf("last request = %s %s %s, want PATCH of issue 7 with the findings", patch.Method, patch.Path, patch.Body)
+	}
+
+	// The list and create calls share a path; the first response answers the lookup.
+	server.Handle("/repos/watch/findings/issues",
+		githubtest.Response{Body: `[{"number": 7, "title": "Older findings"}]`},
+		githubtest.Response{Status: 201, Body: `{"number": 8, "html_url": "https://github.com/watch/findings/issues/8"}`},
+	)
+	created, err := publishFindings(context.Background(), client, target, "# Findings")
+	if err != nil {
+		t.Fatalf("publishFindings(new) error = %v", err)
+	}
+	if !created.Created || created.Number != 8 || created.URL != "https://github.com/watch/findings/issues/8" {
+		t.Fatalf("publishFindings(new) = %+v, want issue 8 created", created)
+	}
+	post := server.Requests()[len(server.Requests())-1]
+	if post.Method != "POST" || !strings.Contains(post.Body, `"title":"GitHubWatchdog findings"`) {
+		t.Fatalf("last request = %s %s, want POST with the title", post.Method, post.Body)
+	}
+}
+
+func TestPublishFindingsToGist(t *testing.T) {
+	server := githubtest.NewServer(t)
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	server.Handle("/gists", githubtest.Response{Status: 201, Body: `{"id": "abc123", "html_url": "https://gist.github.com/abc123"}`})
+	server.HandleJSON("/gists/abc123", map[string]string{"id": "abc123", "html_url": "https://gist.github.com/abc123"})
+
+	created, err := publishFindings(context.Background(), client, publishTarget{To: "gist", Title: defaultPublishTitle}, "# Findings")
+	if err != nil || !created.Created || created.GistID != "abc123" {
+		t.Fatalf("publishFindings(new gist) = %+v, %v, want gist abc123 created", created, err)
+	}
+	if body := server.Requests()[0].Body; !strings.Contains(body, `"public":false`) || !strings.Contains(body, publishGistFile) {
+		t.Fatalf("create gist body = %s, want a secret gist holding %s", body, publishGistFile)
+	}
+	updated, err := publishFindings(context.Background(), client, publishTarget{To: "gist", Gist: "abc123", Title: defaultPublishTitle}, "# Findings")
+	if err != nil || updated.Created || server.RequestCount("/gists/abc123") != 1 {
+		t.Fatalf("publishFindings(existing gist) = %+v, %v, want gist updated in place", updated, err)
+	}
+}
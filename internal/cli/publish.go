@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+)
+
+const (
+	defaultPublishTitle = "GitHubWatchdog findings"
+	publishGistFile     = "githubwatchdog-findings.md"
+)
+
+// publishTarget says where `report publish` writes the findings.
+type publishTarget struct {
+	To    string // issue or gist
+	Repo  string // owner/name, for issues
+	Issue int    // issue to update; zero looks up an open issue by Title
+	Gist  string // gist to update; empty creates a secret gist
+	Title string
+}
+
+func (t publishTarget) validate() error {
+	switch t.To {
+	case "issue":
+		if t.Repo == "" {
+			return errors.New("report publish --to issue requires --repo or publish_repo in config")
+		}
+		_, _, err := parseRepoRef(t.Repo)
+		return err
+	case "gist":
+		return nil
+	default:
+		return fmt.Errorf("invalid --to %q: expected issue or gist", t.To)
+	}
+}
+
+// publishResult is the issue or gist the findings were written to.
+type publishResult struct {
+	Target  string `json:"target"`
+	URL     string `json:"url"`
+	Number  int    `json:"number,omitempty"`
+	GistID  string `json:"gist_id,omitempty"`
+	Created bool   `json:"created"`
+}
+
+// publishFindings writes body to the target. An issue is updated when --issue names one or an
+// open issue already carries the title, and created otherwise, so repeated runs keep a single
+// issue current. A gist is updated when its id is known and created as a secret gist otherwise.
+func publishFindings(ctx context.Context, client *github.Client, target publishTarget, body string) (publishResult, error) {
+	if target.To == "gist" {
+		if target.Gist != "" {
+			gist, err := client.UpdateGist(ctx, target.Gist, target.Title, publishGistFile, body)
+			if err != nil {
+				return publishResult{}, err
+			}
+			return publishResult{Target: "gist", URL: gist.HTMLURL, GistID: gist.ID}, nil
+		}
+		gist, err := client.CreateGist(ctx, target.Title, publishGistFile, body)
+		if err != nil {
+			return publishResult{}, err
+		}
+		return publishResult{Target: "gist", URL: gist.HTMLURL, GistID: gist.ID, Created: true}, nil
+	}
+
+	owner, repo, err := parseRepoRef(target.Repo)
+	if err != nil {
+		return publishResult{}, err
+	}
+	number := target.Issue
+	if number == 0 {
+		existing, found, err := client.FindOpenIssue(ctx, owner, repo, target.Title)
+		if err != nil {
+			return publishResult{}, err
+		}
+		if found {
+			number = existing.Number
+		}
+	}
+	if number != 0 {
+		issue, err := client.UpdateIssue(ctx, owner, repo, number, target.Title, body)
+		if err != nil {
+			return publishResult{}, err
+		}
+		return publishResult{Target: "issue", URL: issue.HTMLURL, Number: issue.Number}, nil
+	}
+	issue, err := client.CreateIssue(ctx, owner, repo, target.Title, body)
+	if err != nil {
+		return publishResult{}, err
+	}
+	return publishResult{Target: "issue", URL: issue.HTMLURL, Number: issue.Number, Created: true}, nil
+}
+
+func writePublishResult(w io.Writer, format string, result publishResult) error {
+	if format == "json" {
+		return writeJSON(w, result)
+	}
+	action := "Updated"
+	if result.Created {
+		action = "Created"
+	}
+	var err error
+	if result.Target == "issue" {
+		_, err = fmt.Fprintf(w, "%s issue #%d: %s\n", action, result.Number, result.URL)
+	} else {
+		_, err = fmt.Fprintf(w, "%s gist %s: %s\n", action, result.GistID, result.URL)
+		if err == nil && result.Created {
+			_, err = fmt.Fprintf(w, "Set publish_gist to %s to update this gist on later runs.\n", result.GistID)
+		}
+	}
+	return err
+}
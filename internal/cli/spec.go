@@ -90,7 +90,7 @@ func buildCapabilityCatalog(now time.Time) capabilityCatalog {
 					{Name: "--checkpoint", Type: "string", Description: "Save search progress under this checkpoint name"},
 					{Name: "--resume", Type: "bool", Default: "false", Description: "Resume search defaults from the named checkpoint", Requires: []string{"--checkpoint"}},
 					{Name: "--activity", Type: "string", Default: "updated", Description: "Search activity source", Enum: []string{"updated", "created", "either"}},
-					{Name: "--since", Type: "string", Description: "Alias for --updated-since for backward-compatible updated-time searches"},
+					{Name: "--since", Type: "string", Description: "Alias for --updated-since for backward-compatible updated-time searches; last-run uses the start of the previous successful search"},
 					{Name: "--updated-before", Type: "string", Description: "Upper bound for updated-time searches"},
 					{Name: "--created-since", Type: "string", Description: "Lower bound for created-time searches"},
 					{Name: "--created-before", Type: "string", Description: "Upper bound for created-time searches"},
@@ -163,6 +163,177 @@ func buildCapabilityCatalog(now time.Time) capabilityCatalog {
 					{Name: "import", Summary: "Import checkpoint JSON.", Usage: "githubwatchdog checkpoints import --input <path|->", Flags: []capabilityFlag{{Name: "--format", Type: "string", Default: "text", Description: "Output format", Enum: []string{"json", "text"}}, {Name: "--input", Type: "string", Default: "-", Description: "Import input path or - for stdin"}}},
 				},
 			},
+			{
+				Name:    "flags",
+				Summary: "List entities flagged by one heuristic with their scan details and review status.",
+				Usage:   "githubwatchdog [global flags] flags [--status <status>] [--limit <n>] [--offset <n>] <heuristic>",
+				Positional: []capabilityArg{
+					{Name: "<heuristic>", Required: true, Description: "Heuristic or checker name, such as SafeBrowsingHeuristic"},
+				},
+				Flags: []capabilityFlag{
+					{Name: "--status", Type: "string", Description: "Filter by abuse report review status", Enum: []string{"draft", "reported", "actioned", "declined", "unreviewed"}},
+					{Name: "--limit", Type: "int", Default: "50", Description: "Maximum entities to return"},
+					{Name: "--offset", Type: "int", Default: "0", Description: "Entities to skip before the first returned"},
+					{Name: "--format", Type: "string", Default: "json", Description: "Output format", Enum: []string{"json", "text"}},
+				},
+			},
+			{
+				Name:    "rings",
+				Summary: "Find accounts that starred several malicious repositories and flag them as a starring ring.",
+				Usage:   "githubwatchdog [global flags] rings [--min-repos <n>] [--flagged] [--persist=false] [--format json|text]",
+				Flags: []capabilityFlag{
+					{Name: "--min-repos", Type: "int", Default: "3", Description: "Malicious repositories an account must have starred"},
+					{Name: "--flagged", Type: "bool", Default: "false", Description: "Count every flagged repository, not only malicious ones"},
+					{Name: "--persist", Type: "bool", Default: "true", Description: "Record a StarringRingHeuristic flag on each member"},
+					{Name: "--format", Type: "string", Default: "json", Description: "Output format", Enum: []string{"json", "text"}},
+				},
+			},
+			{
+				Name:    "report",
+				Summary: "Generate paste-ready abuse report text from persisted findings and track review status.",
+				Usage:   "githubwatchdog [global flags] report <text|status|list|markdown|weekly|publish> [args]",
+				Subcommands: []capabilityCommand{
+					{Name: "text", Summary: "Render abuse report text for a flagged repo or user.", Usage: "githubwatchdog report text <owner/repo|username>", Positional: []capabilityArg{{Name: "<owner/repo|username>", Required: true, Description: "Persisted target"}}, Flags: []capabilityFlag{{Name: "--format", Type: "string", Default: "text", Description: "Output format", Enum: []string{"json", "text"}}, {Name: "--template", Type: "string", Description: "Template path overriding abuse_report_template"}, {Name: "--save", Type: "bool", Default: "true", Description: "Store the generated text"}}},
+					{Name: "status", Summary: "Set the review status of a stored report.", Usage: "githubwatchdog report status <owner/repo|username> <draft|reported|actioned|declined>", Positional: []capabilityArg{{Name: "<owner/repo|username>", Required: true, Description: "Reported target"}, {Name: "<status>", Required: true, Description: "Review status"}}},
+					{Name: "list", Summary: "List stored reports.", Usage: "githubwatchdog report list [--status <status>]", Flags: []capabilityFlag{{Name: "--format", Type: "string", Default: "text", Description: "Output format", Enum: []string{"json", "text"}}, {Name: "--status", Type: "string", Description: "Filter by review status", Enum: []string{"draft", "reported", "actioned", "declined"}}}},
+					{Name: "markdown", Summary: "Render suspicious users and flagged repositories as Markdown grouped by category.", Usage: "githubwatchdog report markdown [--since <date>] [--category <category>] [--tier <tier>] [-o <path>]", Flags: []capabilityFlag{
+						{Name: "--since", Type: "string", Description: "Only include entries first seen on or after this YYYY-MM-DD or RFC3339 time"},
+						{Name: "--category", Type: "string", Description: "Only include entries with a flag in this category"},
+						{Name: "--tier", Type: "string", Description: "Only include users of this tier or above; repositories are unaffected", Enum: []string{"low", "medium", "high"}},
+						{Name: "--output", Type: "string", Default: "-", Description: "Output path or - for stdout; -o is shorthand"},
+						{Name: "--format", Type: "string", Default: "text", Description: "Markdown text or the grouped entries as JSON", Enum: []string{"json", "text"}},
+					}},
+					{Name: "weekly", Summary: "Write a dated summary of new detections, top heuristics, clusters, scan counts, and reports filed to GitHub.", Usage: "githubwatchdog report weekly [--since 7d] [--output-dir <dir>] [--html]", Flags: []capabilityFlag{
+						{Name: "--since", Type: "string", Default: "7d", Description: "Window start as a number of days back, a YYYY-MM-DD date, or an RFC3339 time"},
+						{Name: "--output-dir", Type: "string", Default: "reports", Description: "Directory for weekly-YYYY-MM-DD files; defaults to report_output_dir"},
+						{Name: "--html", Type: "bool", Default: "false", Description: "Also write an HTML copy"},
+						{Name: "--format", Type: "string", Default: "text", Description: "Print written paths, or the paths and summary as JSON", Enum: []string{"json", "text"}},
+					}},
+					{Name: "publish", Summary: "Post the Markdown findings to a GitHub issue, updating it on later runs, or to a secret gist.", Usage: "githubwatchdog report publish [--to issue|gist] [--repo <owner>/<repo>] [--issue <n>] [--gist <id>]", Flags: []capabilityFlag{
+						{Name: "--to", Type: "string", Default: "issue", Description: "Publish target; defaults to publish_target", Enum: []string{"issue", "gist"}},
+						{Name: "--repo", Type: "string", Description: "Repository holding the findings issue; defaults to publish_repo"},
+						{Name: "--issue", Type: "int", Default: "0", Description: "Issue number to update instead of looking up an open issue by title"},
+						{Name: "--gist", Type: "string", Description: "Gist id to update; defaults to publish_gist, and empty creates a secret gist"},
+						{Name: "--title", Type: "string", Default: "GitHubWatchdog findings", Description: "Issue title or gist description"},
+						{Name: "--since", Type: "string", Description: "Only include entries first seen on or after this YYYY-MM-DD or RFC3339 time"},
+						{Name: "--category", Type: "string", Description: "Only include entries with a flag in this category"},
+						{Name: "--tier", Type: "string", Description: "Only include users of this tier or above; repositories are unaffected", Enum: []string{"low", "medium", "high"}},
+						{Name: "--format", Type: "string", Default: "text", Description: "Output format", Enum: []string{"json", "text"}},
+					}},
+				},
+			},
+			{
+				Name:    "urlscan",
+				Summary: "Submit a URL to urlscan.io privately, or list recorded scans for a repository.",
+				Usage:   "githubwatchdog [global flags] urlscan [--repo <owner>/<repo>] [<url>]",
+				Positional: []capabilityArg{
+					{Name: "<url>", Required: false, Description: "URL to submit; omit with --repo to list recorded scans"},
+				},
+				Flags: []capabilityFlag{
+					{Name: "--repo", Type: "string", Description: "Attach the scan to, or list scans for, this repository"},
+					{Name: "--persist", Type: "bool", Default: "true", Description: "Persist the scan result to the SQLite database"},
+					{Name: "--format", Type: "string", Default: "json", Description: "Output format", Enum: []string{"json", "text"}},
+					{Name: "--timeout", Type: "duration", Default: "5m0s", Description: "Overall command timeout"},
+				},
+			},
+			{
+				Name:    "export",
+				Summary: "Export persisted findings for security dashboards and other tooling.",
+				Usage:   "githubwatchdog [global flags] export <sarif|csv> [export flags]",
+				Subcommands: []capabilityCommand{
+					{Name: "sarif", Summary: "Write repository flags as a SARIF 2.1.0 log with stable rule metadata.", Usage: "githubwatchdog export sarif [--owner <owner>] [--since <date>] [--category <category>] [--output <path>]", Flags: []capabilityFlag{
+						{Name: "--owner", Type: "string", Description: "Only export repositories owned by this account"},
+						{Name: "--since", Type: "string", Description: "Only export findings first flagged on or after this YYYY-MM-DD or RFC3339 time"},
+						{Name: "--category", Type: "string", Description: "Only export findings in this flag category"},
+						{Name: "--output", Type: "string", Default: "-", Description: "Output path or - for stdout"},
+					}},
+					{Name: "csv", Summary: "Stream suspicious users, flagged repositories, or recorded flags as CSV with a header row.", Usage: "githubwatchdog export csv [--type users|repos|flags] [--output <path>]", Flags: []capabilityFlag{
+						{Name: "--type", Type: "string", Default: "users", Description: "What to export", Enum: []string{"users", "repos", "flags"}},
+						{Name: "--output", Type: "string", Default: "-", Description: "Output path or - for stdout"},
+					}},
+				},
+			},
+			{
+				Name:    "import",
+				Summary: "Import records from earlier flat-file versions of the watchdog into the database.",
+				Usage:   "githubwatchdog [global flags] import legacy [--dir <path>] [--format json|text]",
+				Subcommands: []capabilityCommand{
+					{Name: "legacy", Summary: "Find processed, suspicious, and malicious record files by name and insert their entries idempotently.", Usage: "githubwatchdog import legacy [--dir <path>] [--format json|text]", Flags: []capabilityFlag{
+						{Name: "--dir", Type: "string", Default: ".", Description: "Directory searched recursively for legacy record files"},
+						{Name: "--format", Type: "string", Default: "text", Description: "Output format", Enum: []string{"json", "text"}},
+					}},
+				},
+			},
+			{
+				Name:    "blocklist",
+				Summary: "Publish and import signed blocklists of confirmed-malicious repositories, users, and asset hashes.",
+				Usage:   "githubwatchdog [global flags] blocklist <export|import|keygen> [args]",
+				Subcommands: []capabilityCommand{
+					{Name: "export", Summary: "Write confirmed indicators from the local database as a JSON blocklist, signed when a key is given.", Usage: "githubwatchdog blocklist export [--source <name>] [--validity-days <n>] [--sign-key <path>] [--output <path>]", Flags: []capabilityFlag{
+						{Name: "--source", Type: "string", Description: "Publisher name recorded in the blocklist"},
+						{Name: "--validity-days", Type: "int", Default: "30", Description: "Days each entry stays valid"},
+						{Name: "--sign-key", Type: "string", Description: "Path to a base64 ed25519 private key; empty exports an unsigned list"},
+						{Name: "--output", Type: "string", Default: "-", Description: "Output path or - for stdout"},
+					}},
+					{Name: "import", Summary: "Fetch, verify, and merge blocklists into external_indicators; defaults to blocklist_sources.", Usage: "githubwatchdog blocklist import [--public-key <base64>] [--format json|text] [<url-or-path>...]", Flags: []capabilityFlag{
+						{Name: "--public-key", Type: "string", Description: "Base64 ed25519 public key required for blocklists given as arguments"},
+						{Name: "--format", Type: "string", Default: "text", Description: "Output format", Enum: []string{"json", "text"}},
+					}},
+					{Name: "keygen", Summary: "Generate an ed25519 signing key pair for blocklist export.", Usage: "githubwatchdog blocklist keygen [--output <path>]", Flags: []capabilityFlag{
+						{Name: "--output", Type: "string", Description: "Write the private key to this path instead of stdout"},
+					}},
+				},
+			},
+			{
+				Name:    "allowlist",
+				Summary: "Mark known-good repositories and users so scans never flag them.",
+				Usage:   "githubwatchdog [global flags] allowlist <add|remove|list> [args]",
+				Subcommands: []capabilityCommand{
+					{Name: "add", Summary: "Allowlist a repo or user, replacing the reason when already listed.", Usage: "githubwatchdog allowlist add --reason <text> <owner/repo|username>", Positional: []capabilityArg{{Name: "<owner/repo|username>", Required: true, Description: "Target to allowlist"}}, Flags: []capabilityFlag{{Name: "--reason", Type: "string", Description: "Why the target is known to be legitimate"}}},
+					{Name: "remove", Summary: "Take a repo or user off the allowlist.", Usage: "githubwatchdog allowlist remove <owner/repo|username>", Positional: []capabilityArg{{Name: "<owner/repo|username>", Required: true, Description: "Allowlisted target"}}},
+					{Name: "list", Summary: "List allowlisted repos and users, most recent first.", Usage: "githubwatchdog allowlist list [--format json|text]", Flags: []capabilityFlag{{Name: "--format", Type: "string", Default: "text", Description: "Output format", Enum: []string{"json", "text"}}}},
+				},
+			},
+			{
+				Name:    "db",
+				Summary: "Inspect database snapshots without migrating them.",
+				Usage:   "githubwatchdog db diff [--format json|text] <old.db> <new.db>",
+				Subcommands: []capabilityCommand{
+					{Name: "diff", Summary: "Compare two snapshots: entities added, removed, or whose verdict flipped, and newly recorded flags. Refuses snapshots with different schemas.", Usage: "githubwatchdog db diff [--format json|text] <old.db> <new.db>", Positional: []capabilityArg{
+						{Name: "<old.db>", Required: true, Description: "Older database snapshot"},
+						{Name: "<new.db>", Required: true, Description: "Newer database snapshot"},
+					}, Flags: []capabilityFlag{
+						{Name: "--format", Type: "string", Default: "text", Description: "Output format", Enum: []string{"json", "text"}},
+					}},
+				},
+			},
+			{
+				Name:    "maintenance",
+				Summary: "Run database maintenance jobs.",
+				Usage:   "githubwatchdog [global flags] maintenance <analyze-pending|reanalyze> [flags]",
+				Subcommands: []capabilityCommand{
+					{Name: "analyze-pending", Summary: "Analyze and persist repositories and users recorded without analysis, such as legacy imports. Stops starting new ones on timeout, interrupt, or rate limit; the rest stay pending.", Usage: "githubwatchdog [global flags] maintenance analyze-pending [--timeout 30m] [--max-concurrent 4] [--limit N] [--format json|ndjson|text]", Flags: []capabilityFlag{
+						{Name: "--timeout", Type: "duration", Default: "30m0s", Description: "Time box for the whole job"},
+						{Name: "--max-concurrent", Type: "int", Default: "4", Description: "Maximum analyses in flight"},
+						{Name: "--limit", Type: "int", Default: "0", Description: "Maximum pending entities to take up; 0 takes all"},
+						{Name: "--format", Type: "string", Default: "json", Description: "Output format; ndjson and text report progress per entity", Enum: []string{"json", "ndjson", "text"}},
+					}},
+					{Name: "reanalyze", Summary: "Re-run the current heuristics on every user with a stored verdict, least recently analyzed first, updating verdicts and adding new flags. Reports how many verdicts changed; stops starting new users on timeout, interrupt, or rate limit.", Usage: "githubwatchdog [global flags] maintenance reanalyze [--timeout 30m] [--max-concurrent 4] [--limit N] [--format json|ndjson|text]", Flags: []capabilityFlag{
+						{Name: "--timeout", Type: "duration", Default: "30m0s", Description: "Time box for the whole job"},
+						{Name: "--max-concurrent", Type: "int", Default: "4", Description: "Maximum analyses in flight"},
+						{Name: "--limit", Type: "int", Default: "0", Description: "Maximum users to re-analyze, least recently analyzed first; 0 takes all"},
+						{Name: "--format", Type: "string", Default: "json", Description: "Output format; ndjson and text report progress per user", Enum: []string{"json", "ndjson", "text"}},
+					}},
+				},
+			},
+			{
+				Name:    "selftest",
+				Summary: "Run every built-in detector against bundled known-bad and known-clean fixtures; exits 1 if any detector misses.",
+				Usage:   "githubwatchdog [global flags] selftest [--format json|text]",
+				Flags: []capabilityFlag{
+					{Name: "--format", Type: "string", Default: "text", Description: "Output format", Enum: []string{"json", "text"}},
+				},
+			},
 			{
 				Name:    "capabilities",
 				Summary: "Emit the authoritative command and flag catalog for agents.",
@@ -7,7 +7,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
 	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/analyzer"
+	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
 )
 
 // Config holds application configuration. Optional fields use pointers.
@@ -20,10 +24,237 @@ type Config struct {
 	RateLimitBuffer *int   `json:"rate_limit_buffer"` // minimum remaining rate limit before pausing
 	CacheTTL        *int   `json:"cache_ttl"`         // cache time-to-live in minutes
 	Verbose         *bool  `json:"verbose"`           // enable verbose logging
+	SafeBrowsingKey string `json:"-"`                 // loaded from SAFE_BROWSING_API_KEY
+	URLScanKey      string `json:"-"`                 // loaded from URLSCAN_API_KEY
+	// CacheMaxAge is how many minutes cached responses are kept for revalidation; defaults to 1440.
+	CacheMaxAge *int `json:"cache_max_age"`
+	// CacheMaxEntries caps the number of cached responses; 0 leaves it unbounded.
+	CacheMaxEntries *int `json:"cache_max_entries"`
+	// CacheDir, when set, keeps cached responses on disk in that directory across runs.
+	CacheDir string `json:"cache_dir"`
+	// WebhookURL, when set, receives a JSON POST whenever a persisted user or repo gains flags.
+	WebhookURL string `json:"webhook_url"`
+	// LogLevel is the lowest level logged, debug, info, warn, or error, and replaces Verbose when
+	// set.
+	LogLevel string `json:"log_level"`
+	// LogLevels sets debug, info, warn, or error per subsystem (github, cache, ratelimit, analyzer,
+	// safebrowsing, urlscan, notify). Subsystems not listed follow LogLevel or Verbose.
+	LogLevels map[string]string `json:"log_levels"`
+	// ExternalCommand is an optional detection script run for every analyzed repo and user.
+	ExternalCommand        string   `json:"external_command"`
+	ExternalCommandArgs    []string `json:"external_command_args"`
+	ExternalCommandTimeout *int     `json:"external_command_timeout"` // seconds per run
+	// TemplateUniformityThreshold is the share of an owner's repos that must follow one numbered naming template.
+	TemplateUniformityThreshold *float64 `json:"template_uniformity_threshold"`
+	// AbuseReportTemplate is an optional text/template file overriding the built-in abuse report text.
+	AbuseReportTemplate string `json:"abuse_report_template"`
+	// OnMalicious is none, fetch_stargazers, or fetch_stargazers_and_analyze.
+	OnMalicious   string `json:"on_malicious"`
+	MaxStargazers *int   `json:"max_stargazers"` // stargazers fetched per malicious repo
+	// MaliciousMinSeverity is the lowest flagged checker severity (low, medium, high) that marks a repo malicious.
+	MaliciousMinSeverity string `json:"malicious_min_severity"`
+	// OnRateLimit is wait (block until the limit resets) or fail (return a rate-limit error at once).
+	OnRateLimit string `json:"on_rate_limit"`
+	// ContributionSource is events (count a page of public events, the default) or graphql
+	// (read the contribution calendar, falling back to events).
+	ContributionSource string `json:"contribution_source"`
+	// MaliciousPackagesSource is a path or http(s) URL listing known-malicious packages, one
+	// ecosystem:name per line; it is read again on every run.
+	MaliciousPackagesSource string `json:"malicious_packages_source"`
+	// BlocklistSources are shared blocklists imported by `blocklist import`.
+	BlocklistSources      []BlocklistSource `json:"blocklist_sources"`
+	BlocklistValidityDays *int              `json:"blocklist_validity_days"` // validity window written into exported lists
+	BlocklistSigningKey   string            `json:"blocklist_signing_key"`   // path to a base64 ed25519 private key
+	// StoredTextMaxChars caps persisted flag messages; 0 stores them whole.
+	StoredTextMaxChars *int `json:"stored_text_max_chars"`
+	RedactStoredURLs   bool `json:"redact_stored_urls"`   // replace URLs in persisted flag messages with [url]
+	RedactStoredEmails bool `json:"redact_stored_emails"` // replace email addresses in persisted flag messages with [email]
+	// DBPath is the SQLite database used when -db is not given; ":memory:" keeps it in memory.
+	DBPath string `json:"db_path"`
+	// ReportOutputDir is where `report weekly` writes dated summaries; defaults to reports.
+	ReportOutputDir string `json:"report_output_dir"`
+	// PublishTarget is where `report publish` posts findings: issue (default) or gist.
+	PublishTarget string `json:"publish_target"`
+	PublishRepo   string `json:"publish_repo"` // owner/name holding the findings issue
+	PublishGist   string `json:"publish_gist"` // id of the gist to update; empty creates a secret gist
+	// DeepHistoryCheck inspects recent commits of borderline repos for payloads removed from the tree.
+	DeepHistoryCheck   bool `json:"deep_history_check"`
+	DeepHistoryCommits *int `json:"deep_history_commits"` // commits inspected per repo; defaults to 20
+	// CommitMessageCheck flags repos whose recent commit messages are all identical or all boilerplate.
+	CommitMessageCheck   bool `json:"commit_message_check"`
+	CommitMessageCommits *int `json:"commit_message_commits"` // commits read per repo; defaults to 10
+	// CommitCadenceCheck flags repos whose recent commits come at machine-regular intervals or
+	// share one timestamp.
+	CommitCadenceCheck        bool     `json:"commit_cadence_check"`
+	CommitCadenceCommits      *int     `json:"commit_cadence_commits"`       // commits read per repo; defaults to 30
+	CommitCadenceMaxVariation *float64 `json:"commit_cadence_max_variation"` // defaults to 0.05
+	CommitCadenceSameSecond   *int     `json:"commit_cadence_same_second"`   // defaults to 10
+	// ManifestCheck inspects package.json, setup.py, and pyproject.toml install hooks for downloads and decoding.
+	ManifestCheck bool `json:"manifest_check"`
+	// WorkflowCheck reads GitHub Actions workflows for miners and other runner abuse.
+	WorkflowCheck    bool `json:"workflow_check"`
+	WorkflowMaxFiles *int `json:"workflow_max_files"` // workflow files read per repo; defaults to 5
+	// PayloadBlobCheck reads source files of small repos for long embedded base64 or hex strings.
+	PayloadBlobCheck     bool `json:"payload_blob_check"`
+	PayloadBlobMinLength *int `json:"payload_blob_min_length"` // shortest reported run; defaults to 2000
+	// ObfuscationCheck scores source files of small repos for obfuscation.
+	ObfuscationCheck     bool `json:"obfuscation_check"`
+	ObfuscationThreshold *int `json:"obfuscation_threshold"` // score that flags a file; defaults to 3
+	// SourceSampleMaxFiles and SourceSampleMaxBytes bound the source files read per repo, shared
+	// by the payload blob and obfuscation checks.
+	SourceSampleMaxFiles *int `json:"source_sample_max_files"` // defaults to 10
+	SourceSampleMaxBytes *int `json:"source_sample_max_bytes"` // defaults to 1048576
+	// OutboundLinkCheck unwraps shortened README links and flags those ending on file hosts or
+	// on domains listed by LinkBlocklistSource.
+	OutboundLinkCheck bool `json:"outbound_link_check"`
+	// LinkBlocklistSource and LinkAllowlistSource are paths or http(s) URLs listing one domain
+	// per line; they are read again on every run.
+	LinkBlocklistSource string `json:"link_blocklist_source"`
+	LinkAllowlistSource string `json:"link_allowlist_source"`
+	// LinkRedirectMaxHops is how many redirects a shortened link may take; defaults to 5.
+	LinkRedirectMaxHops *int `json:"link_redirect_max_hops"`
+	LinkRedirectTimeout *int `json:"link_redirect_timeout"` // seconds per link; defaults to 10
+	// DuplicateContentMinRepos is how many repos of other owners must share a content fingerprint to flag a repo; defaults to 2.
+	DuplicateContentMinRepos *int `json:"duplicate_content_min_repos"`
+	// SharedPayloadMinRepos is how many repos of other owners must ship the same release payload to flag a repo; defaults to 2.
+	SharedPayloadMinRepos *int `json:"shared_payload_min_repos"`
+	// StargazerSampleSize enables the lone stargazer check over that many stargazers per repo; 0 (default) disables it.
+	StargazerSampleSize *int `json:"stargazer_sample_size"`
+	// StarFarmCheck looks up the account ages of the first stargazers of flagged repos and of
+	// empty repos with at least StarFarmMinStars stars.
+	StarFarmCheck    bool `json:"star_farm_check"`
+	StarFarmMinStars *int `json:"star_farm_min_stars"` // defaults to 10
+	// TopicSpamCheck flags nearly empty repos with at least TopicSpamMinTopics topics spanning
+	// unrelated trending subjects.
+	TopicSpamCheck     bool `json:"topic_spam_check"`
+	TopicSpamMinTopics *int `json:"topic_spam_min_topics"` // defaults to 10
+	// BinaryOnlyCheck flags tiny repos of archives or executables without source or license
+	// that have at least BinaryOnlyMinStars stars.
+	BinaryOnlyCheck    bool `json:"binary_only_check"`
+	BinaryOnlyMinStars *int `json:"binary_only_min_stars"` // defaults to 10
+	// ReleaseCheck flags release assets that look like payloads: executables from owners younger
+	// than ReleaseMaxOwnerAgeDays, double extensions, archives named for their password, and
+	// download counts over ReleaseDownloadRatio times the star count.
+	ReleaseCheck           bool `json:"release_check"`
+	ReleaseMaxOwnerAgeDays *int `json:"release_max_owner_age_days"` // defaults to 14
+	ReleaseDownloadRatio   *int `json:"release_download_ratio"`     // defaults to 100
+	// AssetDownload downloads the release assets the release check flags, up to
+	// AssetDownloadMaxBytes each and AssetDownloadBudgetBytes per run, and records the SHA-256
+	// of their content.
+	AssetDownload            bool `json:"asset_download"`
+	AssetDownloadMaxBytes    *int `json:"asset_download_max_bytes"`    // defaults to 25 MiB
+	AssetDownloadBudgetBytes *int `json:"asset_download_budget_bytes"` // defaults to 250 MiB
+	// TyposquatCheck flags repos named like a popular project with a typo or bait suffix when the
+	// owner account is younger than TyposquatMaxOwnerAgeDays.
+	TyposquatCheck           bool `json:"typosquat_check"`
+	TyposquatMaxOwnerAgeDays *int `json:"typosquat_max_owner_age_days"` // defaults to 90
+	// PopularNamesSource is a path or http(s) URL listing one popular repo or package name per
+	// line, replacing the built-in list; it is read again on every run.
+	PopularNamesSource string `json:"popular_names_source"`
+	// CommitSampleSize is how many of an owner's repos have their commit count sampled; defaults to 5, 0 disables sampling.
+	CommitSampleSize *int `json:"commit_sample_size"`
+	// ReadmeSampleSize is how many of an owner's repos have their README compared for near-identical
+	// copies; 0 (default) disables it, and 20 is a reasonable bound.
+	ReadmeSampleSize *int `json:"readme_sample_size"`
+	// TierHeuristics are the user heuristics whose agreement grades a user low, medium, or high;
+	// defaults to OriginalHeuristic, NewHeuristic, and RecentHeuristic.
+	TierHeuristics []string `json:"tier_heuristics"`
+	// EnabledHeuristics turns on experimental heuristics and checkers by name.
+	EnabledHeuristics []string `json:"enabled_heuristics"`
+	// DisabledHeuristics turns off heuristics and checkers by name.
+	DisabledHeuristics []string `json:"disabled_heuristics"`
+	// EmptyRepoSizeThreshold is the size in KB below which the user heuristics count a repo as empty; defaults to 10.
+	EmptyRepoSizeThreshold *int `json:"empty_repo_size_threshold"`
+	// SuspiciousEmptyStarThreshold is how many stars make an empty repo suspicious to the user heuristics; defaults to 5.
+	SuspiciousEmptyStarThreshold *int `json:"suspicious_empty_star_threshold"`
+	// CamelCaseNumberThreshold is how many repos named like WeatherForecast-1409 an owner may have
+	// before CamelCaseNumberHeuristic flags it; defaults to 4.
+	CamelCaseNumberThreshold *int `json:"camel_case_number_threshold"`
+	// ForkFarmMaxAgeDays is the account age under which ForkFarmHeuristic flags an owner whose
+	// repos are almost all forks; defaults to 90.
+	ForkFarmMaxAgeDays *int `json:"fork_farm_max_age_days"`
+	// UsernamePatterns are the regular expressions UsernamePatternHeuristic matches logins against;
+	// unset uses the built-in stem-plus-digits pattern, and an empty list turns the heuristic off.
+	UsernamePatterns []string `json:"username_patterns"`
+	// KeywordRules are the keyword lists detectors match content against.
+	KeywordRules KeywordRules `json:"keyword_rules"`
+	// UserScoreThreshold is the total heuristic score that makes a user suspicious; defaults to 1.
+	UserScoreThreshold *float64 `json:"user_score_threshold"`
+	// HeuristicWeights sets how much each named user heuristic counts toward the score; unlisted heuristics weigh 1.
+	HeuristicWeights map[string]float64 `json:"heuristic_weights"`
+	// FlagMinHeuristics is how many distinct heuristics must fire before a user is suspicious or repo flags are recorded; defaults to 1.
+	FlagMinHeuristics *int `json:"flag_min_heuristics"`
+	// FlagHighSeverityCategories are flag categories where one flag is enough; defaults to Malware and Phishing.
+	FlagHighSeverityCategories []string `json:"flag_high_severity_categories"`
+	// SearchIncompleteRetries is how many more times search asks for a page GitHub returned as incomplete; defaults to 0.
+	SearchIncompleteRetries *int `json:"search_incomplete_retries"`
+	// CoalesceOwnerRepos skips file checks for further repos of an owner already found suspicious in the same search page.
+	CoalesceOwnerRepos bool `json:"coalesce_owner_repos"`
+	// OwnerReanalyzeDays reuses an owner's stored analysis in search for that many days; unset
+	// analyzes owners on every scan, and 0 reuses a stored analysis for good.
+	OwnerReanalyzeDays *int `json:"owner_reanalyze_days"`
+	// MinStars, MaxStars, MinSize, and MaxSize keep search results inside a star and size band
+	// after fetching; size is in KB, as GitHub reports it. Unset bounds are open, and maximums
+	// must be positive.
+	MinStars *int `json:"min_stars"`
+	MaxStars *int `json:"max_stars"`
+	MinSize  *int `json:"min_size"`
+	MaxSize  *int `json:"max_size"`
+	// Since is the default search --since: a YYYY-MM-DD or RFC3339 time, or last-run.
+	Since string `json:"since"`
+}
+
+// BlocklistSource is a remote or local blocklist. PublicKey, when set, is the base64 ed25519
+// key its signature must verify against.
+type BlocklistSource struct {
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	PublicKey string `json:"public_key"`
+}
+
+// KeywordRules groups keyword lists by the content they are matched against, so detection can
+// be tuned in config.json without recompiling.
+type KeywordRules struct {
+	// ReadmeMarkers replace the built-in README markers; an empty list turns them off.
+	ReadmeMarkers []KeywordRule `json:"readme_markers"`
+	// DescriptionMarkers replace the built-in repository description markers; an empty list
+	// turns them off.
+	DescriptionMarkers []KeywordRule `json:"description_markers"`
+	// CommitMarkers flag a repo when one of its recent commit messages matches, with the
+	// commit message check on.
+	CommitMarkers []KeywordRule `json:"commit_markers"`
+	// SuspiciousTLDs replace the built-in top-level domains README links are flagged for; an
+	// empty list turns the link check off.
+	SuspiciousTLDs []string `json:"suspicious_tlds"`
+}
+
+// KeywordRule is a named set of phrases that all have to appear for the rule to match. Category
+// is the flag category of a match and defaults to Malware.
+type KeywordRule struct {
+	Name     string   `json:"name"`
+	Phrases  []string `json:"phrases"`
+	Category string   `json:"category"`
 }
 
 // New loads configuration from config.json and env variables.
 func New(configPath string) (*Config, error) {
+	conf, err := Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	conf.Token = resolveGitHubToken()
+	if conf.Token == "" {
+		return nil, errors.New("please set GITHUB_TOKEN or GH_TOKEN, or authenticate gh")
+	}
+	conf.SafeBrowsingKey = strings.TrimSpace(os.Getenv("SAFE_BROWSING_API_KEY"))
+	conf.URLScanKey = URLScanAPIKey()
+	return conf, nil
+}
+
+// Load reads config.json over the defaults without resolving credentials. Commands that only
+// read the local database use it so they work without GitHub auth.
+func Load(configPath string) (*Config, error) {
 	// defaults
 	maxPages := 10
 	perPage := 100
@@ -31,14 +262,16 @@ func New(configPath string) (*Config, error) {
 	rateLimitBuffer := 500
 	cacheTTL := 60 // 1 hour cache TTL
 	verbose := false
+	storedTextMaxChars := 1000
 	conf := Config{
-		MaxPages:        &maxPages,
-		PerPage:         &perPage,
-		GitHubQuery:     "stars:>5",
-		MaxConcurrent:   &maxConcurrent,
-		RateLimitBuffer: &rateLimitBuffer,
-		CacheTTL:        &cacheTTL,
-		Verbose:         &verbose,
+		MaxPages:           &maxPages,
+		PerPage:            &perPage,
+		GitHubQuery:        "stars:>5",
+		MaxConcurrent:      &maxConcurrent,
+		RateLimitBuffer:    &rateLimitBuffer,
+		CacheTTL:           &cacheTTL,
+		Verbose:            &verbose,
+		StoredTextMaxChars: &storedTextMaxChars,
 	}
 
 	if _, err := os.Stat(configPath); err == nil {
@@ -54,14 +287,245 @@ func New(configPath string) (*Config, error) {
 	if conf.GitHubQuery == "" {
 		return nil, errors.New("github_query must be set in config.json")
 	}
-
-	conf.Token = resolveGitHubToken()
-	if conf.Token == "" {
-		return nil, errors.New("please set GITHUB_TOKEN or GH_TOKEN, or authenticate gh")
+	switch conf.OnMalicious {
+	case "", "none", "fetch_stargazers", "fetch_stargazers_and_analyze":
+	default:
+		return nil, fmt.Errorf("on_malicious must be none, fetch_stargazers, or fetch_stargazers_and_analyze, got %q", conf.OnMalicious)
+	}
+	switch conf.MaliciousMinSeverity {
+	case "", "low", "medium", "high":
+	default:
+		return nil, fmt.Errorf("malicious_min_severity must be low, medium, or high, got %q", conf.MaliciousMinSeverity)
+	}
+	switch conf.OnRateLimit {
+	case "", "wait", "fail":
+	default:
+		return nil, fmt.Errorf("on_rate_limit must be wait or fail, got %q", conf.OnRateLimit)
+	}
+	switch conf.ContributionSource {
+	case "", "events", "graphql":
+	default:
+		return nil, fmt.Errorf("contribution_source must be events or graphql, got %q", conf.ContributionSource)
+	}
+	if conf.LogLevel != "" {
+		if _, err := logger.ParseLevel(conf.LogLevel); err != nil {
+			return nil, fmt.Errorf("log_level: %w", err)
+		}
+	}
+	for subsystem, level := range conf.LogLevels {
+		if _, err := logger.ParseLevel(level); err != nil {
+			return nil, fmt.Errorf("log_levels.%s: %w", subsystem, err)
+		}
+	}
+	for i, name := range conf.EnabledHeuristics {
+		if !analyzer.IsRegistered(name) {
+			return nil, fmt.Errorf("enabled_heuristics[%d]: unknown heuristic %q", i, name)
+		}
+	}
+	for i, name := range conf.DisabledHeuristics {
+		if !analyzer.IsRegistered(name) {
+			return nil, fmt.Errorf("disabled_heuristics[%d]: unknown heuristic %q", i, name)
+		}
+		for _, enabled := range conf.EnabledHeuristics {
+			if strings.EqualFold(name, enabled) {
+				return nil, fmt.Errorf("disabled_heuristics[%d]: %q is also enabled", i, name)
+			}
+		}
+	}
+	switch conf.PublishTarget {
+	case "", "issue", "gist":
+	default:
+		return nil, fmt.Errorf("publish_target must be issue or gist, got %q", conf.PublishTarget)
+	}
+	if conf.UserScoreThreshold != nil && *conf.UserScoreThreshold <= 0 {
+		return nil, errors.New("user_score_threshold must be greater than 0")
+	}
+	for name, weight := range conf.HeuristicWeights {
+		if !analyzer.IsRegistered(name) {
+			return nil, fmt.Errorf("heuristic_weights: unknown heuristic %q", name)
+		}
+		if !analyzer.IsUserHeuristic(name) {
+			return nil, fmt.Errorf("heuristic_weights: %q is a repository checker, which is not scored", name)
+		}
+		if weight <= 0 {
+			return nil, fmt.Errorf("heuristic_weights: weight of %q must be greater than 0", name)
+		}
+	}
+	if conf.FlagMinHeuristics != nil && *conf.FlagMinHeuristics < 1 {
+		return nil, errors.New("flag_min_heuristics must be at least 1")
+	}
+	if conf.EmptyRepoSizeThreshold != nil && *conf.EmptyRepoSizeThreshold < 1 {
+		return nil, errors.New("empty_repo_size_threshold must be at least 1")
+	}
+	if conf.SuspiciousEmptyStarThreshold != nil && *conf.SuspiciousEmptyStarThreshold < 1 {
+		return nil, errors.New("suspicious_empty_star_threshold must be at least 1")
+	}
+	if conf.CacheMaxAge != nil && *conf.CacheMaxAge < 1 {
+		return nil, errors.New("cache_max_age must be at least 1")
+	}
+	if conf.CacheMaxEntries != nil && *conf.CacheMaxEntries < 0 {
+		return nil, errors.New("cache_max_entries must not be negative")
+	}
+	if conf.CommitMessageCommits != nil && (*conf.CommitMessageCommits < 1 || *conf.CommitMessageCommits > 30) {
+		return nil, errors.New("commit_message_commits must be between 1 and 30")
+	}
+	if conf.CommitCadenceCommits != nil && (*conf.CommitCadenceCommits < 10 || *conf.CommitCadenceCommits > 100) {
+		return nil, errors.New("commit_cadence_commits must be between 10 and 100")
+	}
+	if conf.CommitCadenceMaxVariation != nil && *conf.CommitCadenceMaxVariation <= 0 {
+		return nil, errors.New("commit_cadence_max_variation must be positive")
+	}
+	if conf.CommitCadenceSameSecond != nil && *conf.CommitCadenceSameSecond < 2 {
+		return nil, errors.New("commit_cadence_same_second must be at least 2")
+	}
+	if conf.WorkflowMaxFiles != nil && *conf.WorkflowMaxFiles < 1 {
+		return nil, errors.New("workflow_max_files must be at least 1")
+	}
+	if conf.PayloadBlobMinLength != nil && *conf.PayloadBlobMinLength < 100 {
+		return nil, errors.New("payload_blob_min_length must be at least 100")
+	}
+	if conf.ObfuscationThreshold != nil && *conf.ObfuscationThreshold < 1 {
+		return nil, errors.New("obfuscation_threshold must be at least 1")
+	}
+	if conf.SourceSampleMaxFiles != nil && *conf.SourceSampleMaxFiles < 1 {
+		return nil, errors.New("source_sample_max_files must be at least 1")
+	}
+	if conf.SourceSampleMaxBytes != nil && *conf.SourceSampleMaxBytes < 1 {
+		return nil, errors.New("source_sample_max_bytes must be at least 1")
+	}
+	if conf.StarFarmMinStars != nil && *conf.StarFarmMinStars < 1 {
+		return nil, errors.New("star_farm_min_stars must be at least 1")
+	}
+	if conf.TopicSpamMinTopics != nil && *conf.TopicSpamMinTopics < 1 {
+		return nil, errors.New("topic_spam_min_topics must be at least 1")
+	}
+	if conf.BinaryOnlyMinStars != nil && *conf.BinaryOnlyMinStars < 1 {
+		return nil, errors.New("binary_only_min_stars must be at least 1")
+	}
+	if conf.ReleaseMaxOwnerAgeDays != nil && *conf.ReleaseMaxOwnerAgeDays < 1 {
+		return nil, errors.New("release_max_owner_age_days must be at least 1")
+	}
+	if conf.ReleaseDownloadRatio != nil && *conf.ReleaseDownloadRatio < 1 {
+		return nil, errors.New("release_download_ratio must be at least 1")
+	}
+	if conf.AssetDownloadMaxBytes != nil && *conf.AssetDownloadMaxBytes < 1 {
+		return nil, errors.New("asset_download_max_bytes must be at least 1")
+	}
+	if conf.AssetDownloadBudgetBytes != nil && *conf.AssetDownloadBudgetBytes < 1 {
+		return nil, errors.New("asset_download_budget_bytes must be at least 1")
+	}
+	if conf.TyposquatMaxOwnerAgeDays != nil && *conf.TyposquatMaxOwnerAgeDays < 1 {
+		return nil, errors.New("typosquat_max_owner_age_days must be at least 1")
+	}
+	if conf.ReadmeSampleSize != nil && *conf.ReadmeSampleSize < 0 {
+		return nil, errors.New("readme_sample_size must not be negative")
+	}
+	if conf.CamelCaseNumberThreshold != nil && *conf.CamelCaseNumberThreshold < 1 {
+		return nil, errors.New("camel_case_number_threshold must be at least 1")
+	}
+	if conf.ForkFarmMaxAgeDays != nil && *conf.ForkFarmMaxAgeDays < 1 {
+		return nil, errors.New("fork_farm_max_age_days must be at least 1")
+	}
+	for i, pattern := range conf.UsernamePatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("username_patterns[%d]: %w", i, err)
+		}
+	}
+	if conf.WebhookURL != "" && !strings.HasPrefix(conf.WebhookURL, "https://") && !strings.HasPrefix(conf.WebhookURL, "http://") {
+		return nil, errors.New("webhook_url must be an http(s) URL")
+	}
+	if conf.LinkRedirectMaxHops != nil && *conf.LinkRedirectMaxHops < 1 {
+		return nil, errors.New("link_redirect_max_hops must be at least 1")
+	}
+	if conf.LinkRedirectTimeout != nil && *conf.LinkRedirectTimeout < 1 {
+		return nil, errors.New("link_redirect_timeout must be at least 1")
+	}
+	if conf.OwnerReanalyzeDays != nil && *conf.OwnerReanalyzeDays < 0 {
+		return nil, errors.New("owner_reanalyze_days must not be negative")
+	}
+	if err := validateBand("stars", conf.MinStars, conf.MaxStars); err != nil {
+		return nil, err
+	}
+	if err := validateBand("size", conf.MinSize, conf.MaxSize); err != nil {
+		return nil, err
+	}
+	if *conf.StoredTextMaxChars < 0 {
+		return nil, errors.New("stored_text_max_chars must not be negative")
+	}
+	for i, source := range conf.BlocklistSources {
+		if strings.TrimSpace(source.URL) == "" {
+			return nil, fmt.Errorf("blocklist_sources[%d] must set url", i)
+		}
+	}
+	if err := validateKeywordRules("keyword_rules.readme_markers", conf.KeywordRules.ReadmeMarkers); err != nil {
+		return nil, err
+	}
+	if err := validateKeywordRules("keyword_rules.description_markers", conf.KeywordRules.DescriptionMarkers); err != nil {
+		return nil, err
+	}
+	if err := validateKeywordRules("keyword_rules.commit_markers", conf.KeywordRules.CommitMarkers); err != nil {
+		return nil, err
+	}
+	for i, tld := range conf.KeywordRules.SuspiciousTLDs {
+		if !tldPattern.MatchString(strings.TrimSpace(tld)) {
+			return nil, fmt.Errorf("keyword_rules.suspicious_tlds[%d]: %q is not a domain suffix", i, tld)
+		}
 	}
 	return &conf, nil
 }
 
+// tldPattern matches a top-level domain or multi-label suffix, with an optional leading dot.
+var tldPattern = regexp.MustCompile(`^\.?[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*$`)
+
+// validateKeywordRules checks that each rule has a unique name and at least one non-blank phrase.
+func validateKeywordRules(key string, rules []KeywordRule) error {
+	names := map[string]bool{}
+	for i, rule := range rules {
+		name := strings.TrimSpace(rule.Name)
+		if name == "" {
+			return fmt.Errorf("%s[%d] must set name", key, i)
+		}
+		if names[strings.ToLower(name)] {
+			return fmt.Errorf("%s[%d]: duplicate name %q", key, i, name)
+		}
+		names[strings.ToLower(name)] = true
+		if len(rule.Phrases) == 0 {
+			return fmt.Errorf("%s[%d] must list phrases", key, i)
+		}
+		for _, phrase := range rule.Phrases {
+			if strings.TrimSpace(phrase) == "" {
+				return fmt.Errorf("%s[%d] has a blank phrase", key, i)
+			}
+		}
+	}
+	return nil
+}
+
+// validateBand checks the min_<name> and max_<name> bounds of a search result band.
+func validateBand(name string, min, max *int) error {
+	if min != nil && *min < 0 {
+		return fmt.Errorf("min_%s must not be negative", name)
+	}
+	if max != nil && *max <= 0 {
+		return fmt.Errorf("max_%s must be positive", name)
+	}
+	if min != nil && max != nil && *min > *max {
+		return fmt.Errorf("min_%s must not exceed max_%s", name, name)
+	}
+	return nil
+}
+
+// URLScanAPIKey returns the urlscan.io API key from the environment, or "" when unset.
+func URLScanAPIKey() string {
+	return strings.TrimSpace(os.Getenv("URLSCAN_API_KEY"))
+}
+
+// GitHubToken resolves the GitHub token the way New does, for commands that load config with
+// Load but still need to call the API.
+func GitHubToken() string {
+	return resolveGitHubToken()
+}
+
 func resolveGitHubToken() string {
 	return resolveGitHubTokenWith(os.Getenv, ghAuthToken)
 }
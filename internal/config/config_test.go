@@ -2,6 +2,8 @@ package config
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -64,3 +66,32 @@ func TestResolveGitHubTokenWithNoSource(t *testing.T) {
 		t.Fatalf("resolveGitHubTokenWith() = %q, want empty", token)
 	}
 }
+
+func TestLoadRejectsUnknownHeuristicNames(t *testing.T) {
+	tests := map[string]string{
+		"unknown":  `{"disabled_heuristics": ["NewHeuristic", "NoSuchHeuristic"]}`,
+		"conflict": `{"enabled_heuristics": ["LoaderChecker"], "disabled_heuristics": ["loaderchecker"]}`,
+		"weight":   `{"heuristic_weights": {"NoSuchHeuristic": 2}}`,
+		"checker":  `{"heuristic_weights": {"LoaderChecker": 2}}`,
+		"negative": `{"heuristic_weights": {"FollowRatioHeuristic": -1}}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(path); err == nil {
+				t.Fatalf("Load(%s) accepted the heuristic names", body)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"disabled_heuristics": ["NewHeuristic", "ReadmeChecker", "TyposquatHeuristic", "ReleaseAssetHeuristic", "DescriptionChecker", "SingleLanguageHeuristic"], "heuristic_weights": {"followratioheuristic": 0.5}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// diffTables lists the tables compared by Diff. Both databases must give each the same columns.
+var diffTables = []string{"processed_repositories", "processed_users", "heuristic_flags"}
+
+// EntityChange is a repository or user that appeared, disappeared, or changed verdict between snapshots.
+type EntityChange struct {
+	EntityType string `json:"entity_type"`
+	EntityID   string `json:"entity_id"`
+	OldStatus  string `json:"old_status,omitempty"`
+	NewStatus  string `json:"new_status,omitempty"`
+}
+
+// FlagChange is a heuristic flag recorded in the newer snapshot but not the older one.
+type FlagChange struct {
+	EntityType  string    `json:"entity_type"`
+	EntityID    string    `json:"entity_id"`
+	Flag        string    `json:"flag"`
+	TriggeredAt time.Time `json:"triggered_at"`
+}
+
+// DiffReport compares two database snapshots.
+type DiffReport struct {
+	OldPath       string         `json:"old_path"`
+	NewPath       string         `json:"new_path"`
+	Added         []EntityChange `json:"added"`
+	Removed       []EntityChange `json:"removed"`
+	StatusChanged []EntityChange `json:"status_changed"`
+	NewFlags      []FlagChange   `json:"new_flags"`
+}
+
+type snapshot struct {
+	statuses map[string]EntityChange
+	flags    map[string]FlagChange
+}
+
+// Diff compares the snapshot at oldPath with the one at newPath. Both files are opened
+// read-only and never migrated, so Diff refuses snapshots whose schemas differ.
+func Diff(oldPath, newPath string) (DiffReport, error) {
+	oldDB, err := openReadOnly(oldPath)
+	if err != nil {
+		return DiffReport{}, err
+	}
+	defer oldDB.Close()
+	newDB, err := openReadOnly(newPath)
+	if err != nil {
+		return DiffReport{}, err
+	}
+	defer newDB.Close()
+
+	if err := compareSchemas(oldDB, newDB, oldPath, newPath); err != nil {
+		return DiffReport{}, err
+	}
+	before, err := loadSnapshot(oldDB)
+	if err != nil {
+		return DiffReport{}, fmt.Errorf("reading %s: %w", oldPath, err)
+	}
+	after, err := loadSnapshot(newDB)
+	if err != nil {
+		return DiffReport{}, fmt.Errorf("reading %s: %w", newPath, err)
+	}
+
+	report := DiffReport{
+		OldPath:       oldPath,
+		NewPath:       newPath,
+		Added:         []EntityChange{},
+		Removed:       []EntityChange{},
+		StatusChanged: []EntityChange{},
+		NewFlags:      []FlagChange{},
+	}
+	for key, current := range after.statuses {
+		previous, ok := before.statuses[key]
+		switch {
+		case !ok:
+			report.Added = append(report.Added, current)
+		case previous.NewStatus != current.NewStatus:
+			current.OldStatus = previous.NewStatus
+			report.StatusChanged = append(report.StatusChanged, current)
+		}
+	}
+	for key, previous := range before.statuses {
+		if _, ok := after.statuses[key]; !ok {
+			previous.OldStatus, previous.NewStatus = previous.NewStatus, ""
+			report.Removed = append(report.Removed, previous)
+		}
+	}
+	for key, flag := range after.flags {
+		if _, ok := before.flags[key]; !ok {
+			report.NewFlags = append(report.NewFlags, flag)
+		}
+	}
+
+	for _, changes := range [][]EntityChange{report.Added, report.Removed, report.StatusChanged} {
+		sort.Slice(changes, func(i, j int) bool {
+			if changes[i].EntityType != changes[j].EntityType {
+				return changes[i].EntityType < changes[j].EntityType
+			}
+			return changes[i].EntityID < changes[j].EntityID
+		})
+	}
+	sort.Slice(report.NewFlags, func(i, j int) bool {
+		a, b := report.NewFlags[i], report.NewFlags[j]
+		if a.EntityType != b.EntityType {
+			return a.EntityType < b.EntityType
+		}
+		if a.EntityID != b.EntityID {
+			return a.EntityID < b.EntityID
+		}
+		return a.Flag < b.Flag
+	})
+	return report, nil
+}
+
+func openReadOnly(path string) (*sql.DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("opening snapshot: %w", err)
+	}
+	escaped := strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23").Replace(path)
+	conn, err := sql.Open("sqlite3", "file:"+escaped+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("opening snapshot %s: %w", path, err)
+	}
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("opening snapshot %s: %w", path, err)
+	}
+	return conn, nil
+}
+
+func compareSchemas(oldDB, newDB *sql.DB, oldPath, newPath string) error {
+	for _, table := range diffTables {
+		oldColumns, err := columnList(oldDB, table)
+		if err != nil {
+			return fmt.Errorf("reading schema of %s: %w", oldPath, err)
+		}
+		newColumns, err := columnList(newDB, table)
+		if err != nil {
+			return fmt.Errorf("reading schema of %s: %w", newPath, err)
+		}
+		if len(oldColumns) == 0 || len(newColumns) == 0 {
+			return fmt.Errorf("schema mismatch: table %s is missing from %s", table, firstMissing(oldColumns, oldPath, newPath))
+		}
+		if strings.Join(oldColumns, ",") != strings.Join(newColumns, ",") {
+			return fmt.Errorf("schema mismatch in %s: %s has columns (%s) but %s has (%s); open both with the same watchdog version before comparing",
+				table, oldPath, strings.Join(oldColumns, ", "), newPath, strings.Join(newColumns, ", "))
+		}
+	}
+	return nil
+}
+
+func firstMissing(oldColumns []string, oldPath, newPath string) string {
+	if len(oldColumns) == 0 {
+		return oldPath
+	}
+	return newPath
+}
+
+func columnList(conn *sql.DB, table string) ([]string, error) {
+	columns, err := tableColumnsOf(conn, table)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func loadSnapshot(conn *sql.DB) (snapshot, error) {
+	snap := snapshot{statuses: map[string]EntityChange{}, flags: map[string]FlagChange{}}
+	queries := []struct {
+		entityType string
+		query      string
+		flagged    string
+	}{
+		{"repo", `SELECT repo_id, is_malicious FROM processed_repositories`, "malicious"},
+		{"user", `SELECT username, analysis_result FROM processed_users`, "suspicious"},
+	}
+	for _, q := range queries {
+		rows, err := conn.Query(q.query)
+		if err != nil {
+			return snapshot{}, err
+		}
+		for rows.Next() {
+			var id string
+			var flagged sql.NullBool
+			if err := rows.Scan(&id, &flagged); err != nil {
+				rows.Close()
+				return snapshot{}, err
+			}
+			status := "clean"
+			if flagged.Bool {
+				status = q.flagged
+			}
+			snap.statuses[q.entityType+"\x00"+id] = EntityChange{EntityType: q.entityType, EntityID: id, NewStatus: status}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return snapshot{}, err
+		}
+	}
+
+	rows, err := conn.Query(`SELECT entity_type, entity_id, flag, triggered_at FROM heuristic_flags`)
+	if err != nil {
+		return snapshot{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var flag FlagChange
+		if err := rows.Scan(&flag.EntityType, &flag.EntityID, &flag.Flag, &flag.TriggeredAt); err != nil {
+			return snapshot{}, err
+		}
+		key := flag.EntityType + "\x00" + flag.EntityID + "\x00" + FlagKey(flag.Flag)
+		if existing, ok := snap.flags[key]; !ok || flag.TriggeredAt.Before(existing.TriggeredAt) {
+			snap.flags[key] = flag
+		}
+	}
+	return snap, rows.Err()
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSnapshot(t *testing.T, path string, seed func(*Database)) {
+	t.Helper()
+	database, err := New(path)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	seed(database)
+	if err := database.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+func TestDiffReportsChanges(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.db")
+	newPath := filepath.Join(dir, "new.db")
+	newSnapshot(t, oldPath, func(d *Database) {
+		mustNil(t, d.InsertProcessedRepo("evil/loader", "evil", "loader", time.Now(), 1, 1, false))
+		mustNil(t, d.InsertProcessedRepo("gone/repo", "gone", "repo", time.Now(), 1, 1, false))
+		mustNil(t, d.InsertProcessedUser("farmer", time.Now(), 1, 1, 1, 0, true, ""))
+		mustNil(t, d.InsertHeuristicFlag("user", "farmer", "GeneratedPortfolioHeuristic", "Spam Behavior:GeneratedPortfolioHeuristic", "old"))
+	})
+	newSnapshot(t, newPath, func(d *Database) {
+		mustNil(t, d.InsertProcessedRepo("evil/loader", "evil", "loader", time.Now(), 1, 1, true))
+		mustNil(t, d.InsertProcessedRepo("fresh/repo", "fresh", "repo", time.Now(), 1, 1, false))
+		mustNil(t, d.InsertProcessedUser("farmer", time.Now(), 1, 1, 1, 0, true, ""))
+		mustNil(t, d.InsertHeuristicFlag("user", "farmer", "GeneratedPortfolioHeuristic", "Spam Behavior:GeneratedPortfolioHeuristic", "new message"))
+		mustNil(t, d.InsertHeuristicFlag("repo", "evil/loader", "SafeBrowsingHeuristic", "Malware:SafeBrowsingHeuristic", ""))
+	})
+
+	report, err := Diff(oldPath, newPath)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if len(report.Added) != 1 || report.Added[0].EntityID != "fresh/repo" || report.Added[0].NewStatus != "clean" {
+		t.Fatalf("Diff() added = %+v, want fresh/repo", report.Added)
+	}
+	if len(report.Removed) != 1 || report.Removed[0].EntityID != "gone/repo" || report.Removed[0].OldStatus != "clean" {
+		t.Fatalf("Diff() removed = %+v, want gone/repo", report.Removed)
+	}
+	if len(report.StatusChanged) != 1 || report.StatusChanged[0].OldStatus != "clean" || report.StatusChanged[0].NewStatus != "malicious" {
+		t.Fatalf("Diff() status changed = %+v, want evil/loader clean to malicious", report.StatusChanged)
+	}
+	if len(report.NewFlags) != 1 || report.NewFlags[0].Flag != "Malware:SafeBrowsingHeuristic" {
+		t.Fatalf("Diff() new flags = %+v, want only the SafeBrowsing flag", report.NewFlags)
+	}
+}
+
+func TestDiffRefusesSchemaMismatch(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.db")
+	newPath := filepath.Join(dir, "new.db")
+	newSnapshot(t, newPath, func(*Database) {})
+
+	legacy, err := sql.Open("sqlite3", oldPath)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	_, err = legacy.Exec(`
+		CREATE TABLE processed_repositories (id INTEGER PRIMARY KEY, repo_id TEXT UNIQUE, is_malicious BOOLEAN);
+		CREATE TABLE processed_users (id INTEGER PRIMARY KEY, username TEXT UNIQUE, analysis_result BOOLEAN);
+		CREATE TABLE heuristic_flags (id INTEGER PRIMARY KEY, entity_type TEXT, entity_id TEXT, flag TEXT, triggered_at TIMESTAMP);
+	`)
+	legacy.Close()
+	if err != nil {
+		t.Fatalf("creating legacy schema error = %v", err)
+	}
+
+	if _, err := Diff(oldPath, newPath); err == nil || !strings.Contains(err.Error(), "schema mismatch") {
+		t.Fatalf("Diff() error = %v, want schema mismatch", err)
+	}
+	columns, err := columnList(mustOpen(t, oldPath), "heuristic_flags")
+	if err != nil || len(columns) != 5 {
+		t.Fatalf("old snapshot columns = %v, %v, want it left unmigrated", columns, err)
+	}
+}
+
+func mustNil(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error = %v", err)
+	}
+}
+
+func mustOpen(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	conn, err := openReadOnly(path)
+	if err != nil {
+		t.Fatalf("openReadOnly() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
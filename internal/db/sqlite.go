@@ -5,11 +5,27 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
 	_ "github.com/mattn/go-sqlite3" // required SQLite driver
 )
 
+// ErrNotFound is wrapped by lookups of a single record that does not exist, so callers can tell
+// a missing entity from a failed query.
+var ErrNotFound = errors.New("not found")
+
+// canonicalID returns the key a repository ID ("owner/name") or username is stored under.
+// GitHub treats logins and repository names case-insensitively, so keys are lowercased and
+// Owner/Repo and owner/repo share one row.
+func canonicalID(id string) string {
+	return strings.ToLower(id)
+}
+
 // Database wraps an sql.DB and prepared statements.
 type Database struct {
 	db             *sql.DB
@@ -18,6 +34,196 @@ type Database struct {
 	insertFlagStmt *sql.Stmt
 }
 
+// ProcessedRepo is a persisted repository scan result. RepoID is the lowercased key; Owner
+// and Name keep the casing the repository was recorded with.
+type ProcessedRepo struct {
+	RepoID         string    `json:"repo_id"`
+	Owner          string    `json:"owner"`
+	Name           string    `json:"name"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	DiskUsage      int       `json:"disk_usage"`
+	StargazerCount int       `json:"stargazer_count"`
+	IsMalicious    bool      `json:"is_malicious"`
+	// GitHubID and NodeID are GitHub's stable identifiers for the repository, when known.
+	GitHubID int64  `json:"github_id,omitempty"`
+	NodeID   string `json:"node_id,omitempty"`
+	// Status is blocked_dmca, disabled, or deleted_on_github when GitHub stopped serving the
+	// repository, and empty otherwise. StatusUpdatedAt is when it was recorded.
+	Status          string     `json:"status,omitempty"`
+	StatusUpdatedAt *time.Time `json:"status_updated_at,omitempty"`
+	// Topics are the repository's GitHub topics as of the latest scan that read its files.
+	Topics []string `json:"topics,omitempty"`
+	// Description is the repository's description as of the latest scan.
+	Description string    `json:"description,omitempty"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+// RepoRename records that a repository previously stored as OldID now lives at NewID.
+type RepoRename struct {
+	OldID     string    `json:"old_id"`
+	NewID     string    `json:"new_id"`
+	RenamedAt time.Time `json:"renamed_at"`
+}
+
+// RepoCheckerResult is the persisted outcome of one repository checker from the latest scan.
+type RepoCheckerResult struct {
+	RepoID    string    `json:"repo_id"`
+	Checker   string    `json:"checker"`
+	Flagged   bool      `json:"flagged"`
+	Severity  string    `json:"severity"`
+	Evidence  string    `json:"evidence,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// ReleaseAsset is a payload asset a repository's releases ship, identified by Hash: GitHub's
+// sha256 digest, or the asset's size and name when it has none.
+type ReleaseAsset struct {
+	RepoID     string    `json:"repo_id"`
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	Hash       string    `json:"hash"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// ProcessedUser is a persisted user analysis result.
+type ProcessedUser struct {
+	// Username is the lowercased key; Login keeps the casing the user was recorded with.
+	Username             string    `json:"username"`
+	Login                string    `json:"login"`
+	CreatedAt            time.Time `json:"created_at"`
+	TotalStars           int       `json:"total_stars"`
+	EmptyCount           int       `json:"empty_count"`
+	SuspiciousEmptyCount int       `json:"suspicious_empty_count"`
+	Contributions        int       `json:"contributions"`
+	Followers            int       `json:"followers"`
+	Following            int       `json:"following"`
+	ForkCount            int       `json:"fork_count"`
+	Score                float64   `json:"score"`
+	Suspicious           bool      `json:"is_suspicious"`
+	Tier                 string    `json:"tier,omitempty"`
+	GitHubID             int64     `json:"github_id,omitempty"`
+	NodeID               string    `json:"node_id,omitempty"`
+	ProcessedAt          time.Time `json:"processed_at"`
+}
+
+// HeuristicFlag is a persisted heuristic flag in Category:Name form. There is one row per
+// entity and FlagKey; Message holds the latest description and UpdatedAt when it last fired.
+type HeuristicFlag struct {
+	EntityType string `json:"entity_type"`
+	EntityID   string `json:"entity_id"`
+	Flag       string `json:"flag"`
+	FlagKey    string `json:"flag_key"`
+	// HeuristicName is the heuristic or checker that raised the flag, the Name part of Flag.
+	HeuristicName string `json:"heuristic_name"`
+	// Category is the flag's taxonomy category, such as spam or star_abuse.
+	Category    string    `json:"category"`
+	Message     string    `json:"message,omitempty"`
+	TriggeredAt time.Time `json:"triggered_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ScanStats counts the repositories and users processed within a time window.
+type ScanStats struct {
+	ReposProcessed int `json:"repos_processed"`
+	ReposMalicious int `json:"repos_malicious"`
+	// ReposTakenDown counts repositories found blocked or disabled, and TakedownsConfirmed the
+	// subset that was flagged before GitHub removed it.
+	ReposTakenDown     int `json:"repos_taken_down"`
+	TakedownsConfirmed int `json:"takedowns_confirmed"`
+	UsersProcessed     int `json:"users_processed"`
+	UsersSuspicious    int `json:"users_suspicious"`
+	// FlagsByCategory counts the flags first triggered in the window by taxonomy category.
+	FlagsByCategory map[string]int `json:"flags_by_category"`
+}
+
+// SharedStargazer is a user recorded as a stargazer of several malicious repositories.
+type SharedStargazer struct {
+	Username string   `json:"username"`
+	Repos    []string `json:"repos"`
+}
+
+// ContentCluster is a set of repositories with identical content under different owners.
+type ContentCluster struct {
+	ID     string   `json:"id"`
+	Owners []string `json:"owners"`
+	Repos  []string `json:"repos"`
+}
+
+// URLThreat is a persisted Safe Browsing match for a README link.
+type URLThreat struct {
+	RepoID     string    `json:"repo_id"`
+	URL        string    `json:"url"`
+	ThreatType string    `json:"threat_type"`
+	Platform   string    `json:"platform,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Abuse report review statuses.
+const (
+	AbuseReportDraft    = "draft"
+	AbuseReportReported = "reported"
+	AbuseReportActioned = "actioned"
+	AbuseReportDeclined = "declined"
+	// ReviewUnreviewed matches flagged entities that have no stored abuse report.
+	ReviewUnreviewed = "unreviewed"
+)
+
+// FlaggedEntity is one entity flagged by a heuristic, with its scan details and review status.
+type FlaggedEntity struct {
+	EntityType   string    `json:"entity_type"`
+	EntityID     string    `json:"entity_id"`
+	Flag         string    `json:"flag"`
+	Category     string    `json:"category"`
+	Message      string    `json:"message,omitempty"`
+	TriggeredAt  time.Time `json:"triggered_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Stars        int       `json:"stars"`
+	Malicious    bool      `json:"is_malicious"`
+	ProcessedAt  time.Time `json:"processed_at,omitempty"`
+	ReviewStatus string    `json:"review_status"`
+}
+
+// AbuseReport is generated abuse report text and its review status.
+type AbuseReport struct {
+	EntityType      string    `json:"entity_type"`
+	EntityID        string    `json:"entity_id"`
+	Body            string    `json:"body"`
+	Status          string    `json:"status"`
+	GeneratedAt     time.Time `json:"generated_at"`
+	StatusUpdatedAt time.Time `json:"status_updated_at"`
+}
+
+// AllowlistEntry is a repo ("owner/name") or user cleared of suspicion by an operator.
+type AllowlistEntry struct {
+	EntityType string    `json:"entity_type"`
+	EntityID   string    `json:"entity_id"`
+	Reason     string    `json:"reason"`
+	AddedAt    time.Time `json:"added_at"`
+}
+
+// URLScan records a urlscan.io submission and its verdict.
+type URLScan struct {
+	UUID          string    `json:"uuid"`
+	RepoID        string    `json:"repo_id,omitempty"`
+	URL           string    `json:"url"`
+	ResultURL     string    `json:"result_url"`
+	ScreenshotURL string    `json:"screenshot_url,omitempty"`
+	Verdict       string    `json:"verdict"`
+	Score         int       `json:"score"`
+	SubmittedAt   time.Time `json:"submitted_at"`
+	CompletedAt   time.Time `json:"completed_at,omitempty"`
+}
+
+// ExternalIndicator is a confirmed-malicious repo, user, or asset hash imported from a shared blocklist.
+type ExternalIndicator struct {
+	Source        string    `json:"source"`
+	IndicatorType string    `json:"indicator_type"`
+	Value         string    `json:"value"`
+	Reason        string    `json:"reason,omitempty"`
+	AddedAt       time.Time `json:"added_at"`
+	ExpiresAt     time.Time `json:"expires_at,omitempty"`
+}
+
 // SearchCheckpoint stores resume information for named CLI scans.
 type SearchCheckpoint struct {
 	Name              string    `json:"name"`
@@ -56,14 +262,44 @@ func (d *Database) Exec(query string, args ...interface{}) (sql.Result, error) {
 	return d.db.Exec(query, args...)
 }
 
-// New creates a new database connection and initializes tables
+// MemoryPath opens a private in-memory database that is discarded when it is closed.
+const MemoryPath = ":memory:"
+
+// IsMemoryPath reports whether dbPath names an in-memory database.
+func IsMemoryPath(dbPath string) bool {
+	return dbPath == MemoryPath || strings.Contains(dbPath, "mode=memory")
+}
+
+// fileDSN adds the connection settings for a file-backed database: WAL so reports can read
+// while a scan writes, and a busy timeout so concurrent writers wait instead of failing.
+func fileDSN(dbPath string) string {
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
+	}
+	return dbPath + separator + "_journal_mode=WAL&_busy_timeout=5000"
+}
+
+// New creates a new database connection and initializes tables. dbPath is a file path or
+// MemoryPath.
 func New(dbPath string) (*Database, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	memory := IsMemoryPath(dbPath)
+	dsn := dbPath
+	if !memory {
+		dsn = fileDSN(dbPath)
+	}
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("opening database: %w", err)
 	}
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
+	if memory {
+		// Every connection to :memory: opens its own empty database, so keep exactly one.
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+	} else {
+		db.SetMaxOpenConns(10)
+		db.SetMaxIdleConns(5)
+	}
 	db.SetConnMaxLifetime(0)
 
 	database := &Database{db: db}
@@ -71,7 +307,7 @@ func New(dbPath string) (*Database, error) {
 		db.Close()
 		return nil, fmt.Errorf("creating tables: %w", err)
 	}
-	if err := database.migrateTables(); err != nil {
+	if err := database.migrate(); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("migrating tables: %w", err)
 	}
@@ -119,21 +355,62 @@ func (d *Database) createTables() error {
 		disk_usage INTEGER,
 		stargazer_count INTEGER,
 		is_malicious BOOLEAN,
+		status TEXT,
+		status_updated_at TIMESTAMP,
+		fingerprint TEXT,
+		content_cluster TEXT,
+		github_id INTEGER,
+		node_id TEXT,
+		topics TEXT,
+		description TEXT,
 		processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);`
 	if _, err := d.db.Exec(repoTable); err != nil {
 		return fmt.Errorf("creating processed_repositories table: %w", err)
 	}
+	renameTable := `
+	CREATE TABLE IF NOT EXISTS repo_renames (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		old_id TEXT UNIQUE,
+		new_id TEXT,
+		renamed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`
+	if _, err := d.db.Exec(renameTable); err != nil {
+		return fmt.Errorf("creating repo_renames table: %w", err)
+	}
+	checkerResultTable := `
+	CREATE TABLE IF NOT EXISTS repo_checker_results (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id TEXT,
+		checker TEXT,
+		flagged BOOLEAN,
+		severity TEXT,
+		evidence TEXT,
+		checked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(repo_id, checker)
+	);`
+	if _, err := d.db.Exec(checkerResultTable); err != nil {
+		return fmt.Errorf("creating repo_checker_results table: %w", err)
+	}
 	userTable := `
 	CREATE TABLE IF NOT EXISTS processed_users (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		username TEXT UNIQUE,
+		login TEXT,
 		created_at TIMESTAMP,
 		total_stars INTEGER,
 		empty_count INTEGER,
 		suspicious_empty_count INTEGER,
 		contributions INTEGER,
+		followers INTEGER,
+		following INTEGER,
+		fork_count INTEGER,
+		score REAL,
+		avatar_hash TEXT,
 		analysis_result BOOLEAN,
+		tier TEXT,
+		github_id INTEGER,
+		node_id TEXT,
 		processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);`
 	if _, err := d.db.Exec(userTable); err != nil {
@@ -145,7 +422,12 @@ func (d *Database) createTables() error {
 		entity_type TEXT,
 		entity_id TEXT,
 		flag TEXT,
-		triggered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		flag_key TEXT,
+		heuristic_name TEXT,
+		category TEXT DEFAULT 'uncategorized',
+		message TEXT,
+		triggered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);`
 	if _, err := d.db.Exec(flagTable); err != nil {
 		return fmt.Errorf("creating heuristic_flags table: %w", err)
@@ -172,10 +454,190 @@ func (d *Database) createTables() error {
 	if _, err := d.db.Exec(checkpointTable); err != nil {
 		return fmt.Errorf("creating search_checkpoints table: %w", err)
 	}
+	searchRunTable := `
+	CREATE TABLE IF NOT EXISTS search_runs (
+		name TEXT PRIMARY KEY,
+		started_at TIMESTAMP,
+		completed_at TIMESTAMP
+	);`
+	if _, err := d.db.Exec(searchRunTable); err != nil {
+		return fmt.Errorf("creating search_runs table: %w", err)
+	}
+	urlThreatTable := `
+	CREATE TABLE IF NOT EXISTS url_threats (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id TEXT,
+		url TEXT,
+		threat_type TEXT,
+		platform TEXT,
+		checked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(repo_id, url, threat_type)
+	);`
+	if _, err := d.db.Exec(urlThreatTable); err != nil {
+		return fmt.Errorf("creating url_threats table: %w", err)
+	}
+	urlScanTable := `
+	CREATE TABLE IF NOT EXISTS url_scans (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		uuid TEXT UNIQUE,
+		repo_id TEXT,
+		url TEXT,
+		result_url TEXT,
+		screenshot_url TEXT,
+		verdict TEXT,
+		score INTEGER,
+		submitted_at TIMESTAMP,
+		completed_at TIMESTAMP
+	);`
+	if _, err := d.db.Exec(urlScanTable); err != nil {
+		return fmt.Errorf("creating url_scans table: %w", err)
+	}
+	abuseReportTable := `
+	CREATE TABLE IF NOT EXISTS abuse_reports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entity_type TEXT,
+		entity_id TEXT,
+		body TEXT,
+		status TEXT DEFAULT 'draft',
+		generated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		status_updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(entity_type, entity_id)
+	);`
+	if _, err := d.db.Exec(abuseReportTable); err != nil {
+		return fmt.Errorf("creating abuse_reports table: %w", err)
+	}
+	allowlistTable := `
+	CREATE TABLE IF NOT EXISTS allowlist (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entity_type TEXT,
+		entity_id TEXT,
+		reason TEXT,
+		added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(entity_type, entity_id)
+	);`
+	if _, err := d.db.Exec(allowlistTable); err != nil {
+		return fmt.Errorf("creating allowlist table: %w", err)
+	}
+	stargazerTable := `
+	CREATE TABLE IF NOT EXISTS stargazers (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id TEXT,
+		username TEXT,
+		starred_at TIMESTAMP,
+		fetched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(repo_id, username)
+	);`
+	if _, err := d.db.Exec(stargazerTable); err != nil {
+		return fmt.Errorf("creating stargazers table: %w", err)
+	}
+	indicatorTable := `
+	CREATE TABLE IF NOT EXISTS external_indicators (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		source TEXT,
+		indicator_type TEXT,
+		value TEXT,
+		reason TEXT,
+		added_at TIMESTAMP,
+		expires_at TIMESTAMP,
+		imported_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(source, indicator_type, value)
+	);`
+	if _, err := d.db.Exec(indicatorTable); err != nil {
+		return fmt.Errorf("creating external_indicators table: %w", err)
+	}
+	releaseAssetTable := `
+	CREATE TABLE IF NOT EXISTS release_assets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id TEXT,
+		name TEXT,
+		size INTEGER,
+		hash TEXT,
+		recorded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(repo_id, name)
+	);`
+	if _, err := d.db.Exec(releaseAssetTable); err != nil {
+		return fmt.Errorf("creating release_assets table: %w", err)
+	}
+	if _, err := d.db.Exec("CREATE INDEX IF NOT EXISTS idx_release_assets_hash ON release_assets(hash);"); err != nil {
+		return fmt.Errorf("indexing release asset hashes: %w", err)
+	}
+	return nil
+}
+
+// migration upgrades the schema to version. Migrations must be idempotent: databases created
+// by createTables already have every change, and a migration interrupted before its version was
+// recorded runs again.
+type migration struct {
+	version     int
+	description string
+	apply       func(d *Database) error
+}
+
+// migrations are applied in order by migrate. Append new ones with the next version; never
+// renumber or remove a recorded one.
+var migrations = []migration{
+	{1, "search checkpoint columns", (*Database).migrateSearchCheckpoints},
+	{2, "repository and user columns", (*Database).migrateEntityColumns},
+	{3, "one row per heuristic flag", (*Database).migrateHeuristicFlags},
+	{4, "heuristic names", (*Database).migrateHeuristicNames},
+	{5, "lowercased keys", (*Database).canonicalizeKeys},
+	{6, "stargazer username index", (*Database).indexStargazers},
+	{7, "user follow counts", (*Database).migrateFollowCounts},
+	{8, "user scores", (*Database).migrateUserScores},
+	{9, "flag categories", (*Database).migrateFlagCategories},
+	{10, "user avatar hashes", (*Database).migrateAvatarHashes},
+	{11, "repository topics", (*Database).migrateRepoTopics},
+	{12, "repository descriptions", (*Database).migrateRepoDescriptions},
+	{13, "user fork counts", (*Database).migrateForkCounts},
+}
+
+// LatestSchemaVersion is the schema version New brings databases to.
+var LatestSchemaVersion = migrations[len(migrations)-1].version
+
+// migrate applies the migrations newer than the recorded schema version in order, recording
+// each version as it succeeds so an interrupted upgrade resumes at the failed step. Databases
+// from before schema versioning are at version 0 and take every migration.
+func (d *Database) migrate() error {
+	if _, err := d.db.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		description TEXT,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`); err != nil {
+		return fmt.Errorf("creating schema_version table: %w", err)
+	}
+	current, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current > LatestSchemaVersion {
+		return fmt.Errorf("database schema version %d is newer than this build supports (%d)", current, LatestSchemaVersion)
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := m.apply(d); err != nil {
+			return fmt.Errorf("applying migration %d (%s): %w", m.version, m.description, err)
+		}
+		if _, err := d.db.Exec(`INSERT INTO schema_version (version, description) VALUES (?, ?)`, m.version, m.description); err != nil {
+			return fmt.Errorf("recording schema version %d: %w", m.version, err)
+		}
+	}
 	return nil
 }
 
-func (d *Database) migrateTables() error {
+// SchemaVersion returns the version of the last migration applied to the database.
+func (d *Database) SchemaVersion() (int, error) {
+	var version int
+	if err := d.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("reading schema version: %w", err)
+	}
+	return version, nil
+}
+
+// migrateSearchCheckpoints adds the search_checkpoints columns added after the table.
+func (d *Database) migrateSearchCheckpoints() error {
 	columns, err := d.tableColumns("search_checkpoints")
 	if err != nil {
 		return err
@@ -200,8 +662,363 @@ func (d *Database) migrateTables() error {
 	return nil
 }
 
+// migrateEntityColumns adds the processed_repositories and processed_users columns added after
+// the tables, with their indexes.
+func (d *Database) migrateEntityColumns() error {
+	repoColumns, err := d.tableColumns("processed_repositories")
+	if err != nil {
+		return err
+	}
+	for _, column := range []string{"status TEXT", "status_updated_at TIMESTAMP", "fingerprint TEXT", "content_cluster TEXT", "github_id INTEGER", "node_id TEXT"} {
+		name, _, _ := strings.Cut(column, " ")
+		if repoColumns[name] {
+			continue
+		}
+		if _, err := d.db.Exec("ALTER TABLE processed_repositories ADD COLUMN " + column + ";"); err != nil {
+			return fmt.Errorf("adding %s to processed_repositories: %w", name, err)
+		}
+	}
+	if _, err := d.db.Exec("CREATE INDEX IF NOT EXISTS idx_processed_repositories_fingerprint ON processed_repositories(fingerprint);"); err != nil {
+		return fmt.Errorf("indexing repository fingerprints: %w", err)
+	}
+	if _, err := d.db.Exec("CREATE INDEX IF NOT EXISTS idx_processed_repositories_github_id ON processed_repositories(github_id);"); err != nil {
+		return fmt.Errorf("indexing repository GitHub IDs: %w", err)
+	}
+	userColumns, err := d.tableColumns("processed_users")
+	if err != nil {
+		return err
+	}
+	for _, column := range []string{"tier TEXT", "github_id INTEGER", "node_id TEXT", "login TEXT"} {
+		name, _, _ := strings.Cut(column, " ")
+		if userColumns[name] {
+			continue
+		}
+		if _, err := d.db.Exec("ALTER TABLE processed_users ADD COLUMN " + column + ";"); err != nil {
+			return fmt.Errorf("adding %s to processed_users: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// canonicalizeKeys lowercases repository IDs and usernames stored before keys were
+// canonicalized, along with the rows keyed by them. Where the lowercased key already has an
+// equivalent row, that row is kept, as in RenameRepo.
+func (d *Database) canonicalizeKeys() error {
+	stmts := []string{
+		`UPDATE processed_users SET login = username WHERE login IS NULL;`,
+		`UPDATE processed_repositories SET is_malicious = 1
+			WHERE NOT is_malicious AND EXISTS (SELECT 1 FROM processed_repositories AS variant
+				WHERE lower(variant.repo_id) = lower(processed_repositories.repo_id) AND variant.is_malicious);`,
+		`UPDATE processed_users SET analysis_result = 1
+			WHERE NOT analysis_result AND EXISTS (SELECT 1 FROM processed_users AS variant
+				WHERE lower(variant.username) = lower(processed_users.username) AND variant.analysis_result);`,
+	}
+	keyed := append([]struct{ table, column, filter string }{
+		{"processed_repositories", "repo_id", ""},
+		{"processed_users", "username", ""},
+		{"heuristic_flags", "entity_id", " AND entity_type = 'user'"},
+		{"abuse_reports", "entity_id", " AND entity_type = 'user'"},
+		{"stargazers", "username", ""},
+		{"repo_renames", "old_id", ""},
+		{"repo_renames", "new_id", ""},
+	}, repoKeyedTables...)
+	for _, table := range keyed {
+		stmts = append(stmts,
+			fmt.Sprintf("UPDATE OR IGNORE %s SET %s = lower(%s) WHERE %s <> lower(%s)%s;", table.table, table.column, table.column, table.column, table.column, table.filter),
+			fmt.Sprintf("DELETE FROM %s WHERE %s <> lower(%s)%s;", table.table, table.column, table.column, table.filter),
+		)
+	}
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("starting key migration: %w", err)
+	}
+	defer tx.Rollback()
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("lowercasing stored keys: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing key migration: %w", err)
+	}
+	return nil
+}
+
+// migrateHeuristicFlags adds flag_key to databases created before it existed, collapses the
+// duplicate rows earlier re-scans accumulated, and enforces one row per entity and flag key.
+func (d *Database) migrateHeuristicFlags() error {
+	columns, err := d.tableColumns("heuristic_flags")
+	if err != nil {
+		return err
+	}
+	stmts := []string{}
+	for _, name := range []string{"flag_key", "message"} {
+		if !columns[name] {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE heuristic_flags ADD COLUMN %s TEXT;", name))
+		}
+	}
+	if !columns["updated_at"] {
+		// SQLite cannot add a column with a non-constant default, so backfill it instead.
+		stmts = append(stmts,
+			"ALTER TABLE heuristic_flags ADD COLUMN updated_at TIMESTAMP;",
+			"UPDATE heuristic_flags SET updated_at = triggered_at;",
+		)
+	}
+	if !columns["flag_key"] {
+		stmts = append(stmts,
+			"UPDATE heuristic_flags SET flag_key = LOWER(TRIM(flag));",
+			`UPDATE heuristic_flags SET updated_at = (
+				SELECT MAX(newer.triggered_at) FROM heuristic_flags AS newer
+				WHERE newer.entity_type = heuristic_flags.entity_type
+					AND newer.entity_id = heuristic_flags.entity_id
+					AND newer.flag_key = heuristic_flags.flag_key
+			);`,
+			`DELETE FROM heuristic_flags WHERE id NOT IN (
+				SELECT MIN(id) FROM heuristic_flags GROUP BY entity_type, entity_id, flag_key
+			);`,
+		)
+	}
+	stmts = append(stmts, "CREATE UNIQUE INDEX IF NOT EXISTS idx_heuristic_flags_key ON heuristic_flags(entity_type, entity_id, flag_key);")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("starting heuristic_flags migration: %w", err)
+	}
+	defer tx.Rollback()
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrating heuristic_flags: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing heuristic_flags migration: %w", err)
+	}
+	return nil
+}
+
+// migrateHeuristicNames adds heuristic_name to heuristic_flags, backfilled from the Name part of
+// Category:Name flags.
+func (d *Database) migrateHeuristicNames() error {
+	columns, err := d.tableColumns("heuristic_flags")
+	if err != nil {
+		return err
+	}
+	stmts := []string{}
+	if !columns["heuristic_name"] {
+		stmts = append(stmts,
+			"ALTER TABLE heuristic_flags ADD COLUMN heuristic_name TEXT;",
+			"UPDATE heuristic_flags SET heuristic_name = TRIM(SUBSTR(flag, INSTR(flag, ':') + 1));",
+		)
+	}
+	stmts = append(stmts, "CREATE INDEX IF NOT EXISTS idx_heuristic_flags_name ON heuristic_flags(heuristic_name COLLATE NOCASE);")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("starting heuristic_name migration: %w", err)
+	}
+	defer tx.Rollback()
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("adding heuristic_name: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing heuristic_name migration: %w", err)
+	}
+	return nil
+}
+
+// indexStargazers indexes stargazers by username for joining stargazer lists across repositories.
+func (d *Database) indexStargazers() error {
+	if _, err := d.db.Exec("CREATE INDEX IF NOT EXISTS idx_stargazers_username ON stargazers(username);"); err != nil {
+		return fmt.Errorf("indexing stargazers: %w", err)
+	}
+	return nil
+}
+
+// migrateFollowCounts adds the follower and followed-account counts to processed_users.
+// Users analyzed before the migration keep zero counts until they are analyzed again.
+func (d *Database) migrateFollowCounts() error {
+	columns, err := d.tableColumns("processed_users")
+	if err != nil {
+		return err
+	}
+	for _, column := range []string{"followers INTEGER", "following INTEGER"} {
+		name, _, _ := strings.Cut(column, " ")
+		if columns[name] {
+			continue
+		}
+		if _, err := d.db.Exec("ALTER TABLE processed_users ADD COLUMN " + column + ";"); err != nil {
+			return fmt.Errorf("adding %s to processed_users: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// migrateUserScores adds the total heuristic score to processed_users and indexes it for
+// ordering users by score. Users analyzed before the migration score zero until analyzed again.
+func (d *Database) migrateUserScores() error {
+	columns, err := d.tableColumns("processed_users")
+	if err != nil {
+		return err
+	}
+	if !columns["score"] {
+		if _, err := d.db.Exec("ALTER TABLE processed_users ADD COLUMN score REAL;"); err != nil {
+			return fmt.Errorf("adding score to processed_users: %w", err)
+		}
+	}
+	if _, err := d.db.Exec("CREATE INDEX IF NOT EXISTS idx_processed_users_score ON processed_users(score);"); err != nil {
+		return fmt.Errorf("indexing user scores: %w", err)
+	}
+	return nil
+}
+
+// migrateFlagCategories adds the taxonomy category to heuristic_flags, backfilled from the
+// Category label and heuristic name of each recorded flag. Flags with labels outside the
+// taxonomy stay uncategorized.
+func (d *Database) migrateFlagCategories() error {
+	columns, err := d.tableColumns("heuristic_flags")
+	if err != nil {
+		return err
+	}
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("starting flag category migration: %w", err)
+	}
+	defer tx.Rollback()
+	if !columns["category"] {
+		if _, err := tx.Exec("ALTER TABLE heuristic_flags ADD COLUMN category TEXT DEFAULT 'uncategorized';"); err != nil {
+			return fmt.Errorf("adding category to heuristic_flags: %w", err)
+		}
+	}
+	rows, err := tx.Query("SELECT DISTINCT flag, COALESCE(heuristic_name, '') FROM heuristic_flags;")
+	if err != nil {
+		return fmt.Errorf("querying flags to categorize: %w", err)
+	}
+	type flagName struct{ flag, name string }
+	var flags []flagName
+	for rows.Next() {
+		var f flagName
+		if err := rows.Scan(&f.flag, &f.name); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning flag to categorize: %w", err)
+		}
+		flags = append(flags, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating flags to categorize: %w", err)
+	}
+	for _, f := range flags {
+		if _, err := tx.Exec("UPDATE heuristic_flags SET category = ? WHERE flag = ? AND COALESCE(heuristic_name, '') = ?;", FlagCategory(f.flag, f.name), f.flag, f.name); err != nil {
+			return fmt.Errorf("categorizing %s: %w", f.flag, err)
+		}
+	}
+	if _, err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_heuristic_flags_category ON heuristic_flags(category);"); err != nil {
+		return fmt.Errorf("indexing flag categories: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing flag category migration: %w", err)
+	}
+	return nil
+}
+
+// migrateAvatarHashes adds the avatar perceptual hash to processed_users. Users analyzed before
+// the migration have no hash until they are analyzed again.
+func (d *Database) migrateAvatarHashes() error {
+	columns, err := d.tableColumns("processed_users")
+	if err != nil {
+		return err
+	}
+	if !columns["avatar_hash"] {
+		if _, err := d.db.Exec("ALTER TABLE processed_users ADD COLUMN avatar_hash TEXT;"); err != nil {
+			return fmt.Errorf("adding avatar_hash to processed_users: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrateRepoTopics adds the GitHub topics to processed_repositories. Repositories scanned
+// before the migration have no topics until they are scanned again.
+func (d *Database) migrateRepoTopics() error {
+	columns, err := d.tableColumns("processed_repositories")
+	if err != nil {
+		return err
+	}
+	if !columns["topics"] {
+		if _, err := d.db.Exec("ALTER TABLE processed_repositories ADD COLUMN topics TEXT;"); err != nil {
+			return fmt.Errorf("adding topics to processed_repositories: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrateForkCounts adds the number of forks each user owns to processed_users and indexes it
+// for ordering users by it. Users analyzed before the migration count zero forks until analyzed
+// again.
+func (d *Database) migrateForkCounts() error {
+	columns, err := d.tableColumns("processed_users")
+	if err != nil {
+		return err
+	}
+	if !columns["fork_count"] {
+		if _, err := d.db.Exec("ALTER TABLE processed_users ADD COLUMN fork_count INTEGER;"); err != nil {
+			return fmt.Errorf("adding fork_count to processed_users: %w", err)
+		}
+	}
+	if _, err := d.db.Exec("CREATE INDEX IF NOT EXISTS idx_processed_users_fork_count ON processed_users(fork_count);"); err != nil {
+		return fmt.Errorf("indexing user fork counts: %w", err)
+	}
+	return nil
+}
+
+// migrateRepoDescriptions adds the description to processed_repositories. Repositories scanned
+// before the migration have no description until they are scanned again.
+func (d *Database) migrateRepoDescriptions() error {
+	columns, err := d.tableColumns("processed_repositories")
+	if err != nil {
+		return err
+	}
+	if !columns["description"] {
+		if _, err := d.db.Exec("ALTER TABLE processed_repositories ADD COLUMN description TEXT;"); err != nil {
+			return fmt.Errorf("adding description to processed_repositories: %w", err)
+		}
+	}
+	return nil
+}
+
+// FlagCategory returns the taxonomy category of a Category:Name flag raised by heuristicName.
+func FlagCategory(flag, heuristicName string) string {
+	label, _, found := strings.Cut(flag, ":")
+	if !found {
+		label = ""
+	}
+	if heuristicName == "" {
+		heuristicName = HeuristicName(flag)
+	}
+	return models.Taxonomy(label, heuristicName)
+}
+
+// FlagKey returns the stable identity of a Category:Name flag. It ignores case and
+// surrounding whitespace so re-scans map onto the same row.
+func FlagKey(flag string) string {
+	return strings.ToLower(strings.TrimSpace(flag))
+}
+
+// HeuristicName returns the Name part of a Category:Name flag, or the whole flag without a category.
+func HeuristicName(flag string) string {
+	if _, name, found := strings.Cut(flag, ":"); found {
+		return strings.TrimSpace(name)
+	}
+	return strings.TrimSpace(flag)
+}
+
 func (d *Database) tableColumns(table string) (map[string]bool, error) {
-	rows, err := d.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	return tableColumnsOf(d.db, table)
+}
+
+func tableColumnsOf(conn *sql.DB, table string) (map[string]bool, error) {
+	rows, err := conn.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
 	if err != nil {
 		return nil, fmt.Errorf("querying table info for %s: %w", table, err)
 	}
@@ -237,6 +1054,7 @@ func (d *Database) prepareStatements() error {
 			disk_usage = excluded.disk_usage,
 			stargazer_count = excluded.stargazer_count,
 			is_malicious = excluded.is_malicious,
+			status = NULL,
 			processed_at = CURRENT_TIMESTAMP;
 	`)
 	if err != nil {
@@ -244,23 +1062,31 @@ func (d *Database) prepareStatements() error {
 	}
 	d.insertUserStmt, err = d.db.Prepare(`
 		INSERT INTO processed_users 
-			(username, created_at, total_stars, empty_count, suspicious_empty_count, contributions, analysis_result)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+			(username, login, created_at, total_stars, empty_count, suspicious_empty_count, contributions, analysis_result, tier)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''))
 		ON CONFLICT(username) DO UPDATE SET
+			login = excluded.login,
 			created_at = excluded.created_at,
 			total_stars = excluded.total_stars,
 			empty_count = excluded.empty_count,
 			suspicious_empty_count = excluded.suspicious_empty_count,
 			contributions = excluded.contributions,
 			analysis_result = excluded.analysis_result,
+			tier = excluded.tier,
 			processed_at = CURRENT_TIMESTAMP;
 	`)
 	if err != nil {
 		return fmt.Errorf("preparing insertUserStmt: %w", err)
 	}
 	d.insertFlagStmt, err = d.db.Prepare(`
-		INSERT INTO heuristic_flags (entity_type, entity_id, flag)
-		VALUES (?, ?, ?);
+		INSERT INTO heuristic_flags (entity_type, entity_id, flag, flag_key, heuristic_name, category, message, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(entity_type, entity_id, flag_key) DO UPDATE SET
+			flag = excluded.flag,
+			heuristic_name = excluded.heuristic_name,
+			category = excluded.category,
+			message = excluded.message,
+			updated_at = CURRENT_TIMESTAMP;
 	`)
 	if err != nil {
 		return fmt.Errorf("preparing insertFlagStmt: %w", err)
@@ -270,6 +1096,7 @@ func (d *Database) prepareStatements() error {
 
 // InsertProcessedRepo inserts a processed repository record
 func (d *Database) InsertProcessedRepo(repoID, owner, name string, updatedAt time.Time, diskUsage, stargazerCount int, isMalicious bool) error {
+	repoID = canonicalID(repoID)
 	_, err := d.insertRepoStmt.Exec(repoID, owner, name, updatedAt, diskUsage, stargazerCount, isMalicious)
 	if err != nil {
 		return fmt.Errorf("inserting processed repository: %w", err)
@@ -277,25 +1104,1380 @@ func (d *Database) InsertProcessedRepo(repoID, owner, name string, updatedAt tim
 	return nil
 }
 
-// InsertProcessedUser inserts a processed user record
-func (d *Database) InsertProcessedUser(username string, createdAt time.Time, totalStars, emptyCount, suspiciousEmptyCount, contributions int, analysisResult bool) error {
-	_, err := d.insertUserStmt.Exec(username, createdAt, totalStars, emptyCount, suspiciousEmptyCount, contributions, analysisResult)
+// InsertProcessedUser inserts a processed user record, keyed by the lowercased username and
+// keeping username's casing as the login. tier is empty when no tier heuristic flagged the user.
+func (d *Database) InsertProcessedUser(username string, createdAt time.Time, totalStars, emptyCount, suspiciousEmptyCount, contributions int, analysisResult bool, tier string) error {
+	_, err := d.insertUserStmt.Exec(canonicalID(username), username, createdAt, totalStars, emptyCount, suspiciousEmptyCount, contributions, analysisResult, tier)
 	if err != nil {
 		return fmt.Errorf("inserting processed user: %w", err)
 	}
 	return nil
 }
 
-// InsertHeuristicFlag inserts a heuristic flag record
-func (d *Database) InsertHeuristicFlag(entityType, entityID, flag string) error {
-	_, err := d.insertFlagStmt.Exec(entityType, entityID, flag)
+// InsertProcessedRepoIfAbsent records a minimal repository row unless repoID is already known.
+// It reports whether a row was inserted.
+func (d *Database) InsertProcessedRepoIfAbsent(repoID, owner, name string, isMalicious bool) (bool, error) {
+	repoID = canonicalID(repoID)
+	result, err := d.db.Exec(`
+		INSERT INTO processed_repositories (repo_id, owner, name, updated_at, disk_usage, stargazer_count, is_malicious)
+		VALUES (?, ?, ?, ?, 0, 0, ?)
+		ON CONFLICT(repo_id) DO NOTHING;
+	`, repoID, owner, name, time.Time{}, isMalicious)
 	if err != nil {
-		return fmt.Errorf("inserting heuristic flag: %w", err)
+		return false, fmt.Errorf("inserting processed repository: %w", err)
 	}
-	return nil
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("inserting processed repository: %w", err)
+	}
+	return affected > 0, nil
 }
 
-// GetProcessedUsers returns a list of all processed usernames
+// RecordSkippedRepo records that a repository was seen at updatedAt but deliberately not
+// analyzed, so WasRepoProcessed skips it until it changes. A stored verdict and its flags are
+// kept.
+func (d *Database) RecordSkippedRepo(repoID, owner, name string, updatedAt time.Time, diskUsage, stargazerCount int) error {
+	_, err := d.db.Exec(`
+		INSERT INTO processed_repositories (repo_id, owner, name, updated_at, disk_usage, stargazer_count, is_malicious)
+		VALUES (?, ?, ?, ?, ?, ?, 0)
+		ON CONFLICT(repo_id) DO UPDATE SET
+			updated_at = excluded.updated_at,
+			disk_usage = excluded.disk_usage,
+			stargazer_count = excluded.stargazer_count,
+			processed_at = CURRENT_TIMESTAMP;
+	`, canonicalID(repoID), owner, name, updatedAt, diskUsage, stargazerCount)
+	if err != nil {
+		return fmt.Errorf("recording skipped repository: %w", err)
+	}
+	return nil
+}
+
+// SetRepoStatus records that GitHub stopped serving a repository, adding a row when it was
+// never processed. Earlier verdicts and flags are kept.
+func (d *Database) SetRepoStatus(repoID, owner, name, status string) error {
+	repoID = canonicalID(repoID)
+	_, err := d.db.Exec(`
+		INSERT INTO processed_repositories (repo_id, owner, name, updated_at, disk_usage, stargazer_count, is_malicious, status, status_updated_at)
+		VALUES (?, ?, ?, ?, 0, 0, 0, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(repo_id) DO UPDATE SET
+			status = excluded.status,
+			status_updated_at = CURRENT_TIMESTAMP,
+			processed_at = CURRENT_TIMESTAMP;
+	`, repoID, owner, name, time.Time{}, status)
+	if err != nil {
+		return fmt.Errorf("setting repository status: %w", err)
+	}
+	return nil
+}
+
+// WasRepoFlagged reports whether a repository was stored as malicious or has heuristic flags.
+func (d *Database) WasRepoFlagged(repoID string) (bool, error) {
+	repoID = canonicalID(repoID)
+	var flagged bool
+	err := d.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM processed_repositories WHERE repo_id = ? AND is_malicious)
+			OR EXISTS (SELECT 1 FROM heuristic_flags WHERE entity_type = 'repo' AND entity_id = ?);
+	`, repoID, repoID).Scan(&flagged)
+	if err != nil {
+		return false, fmt.Errorf("checking repository flags: %w", err)
+	}
+	return flagged, nil
+}
+
+// InsertProcessedUserIfAbsent records a minimal user row unless username is already known.
+// It reports whether a row was inserted.
+func (d *Database) InsertProcessedUserIfAbsent(username string, analysisResult bool) (bool, error) {
+	result, err := d.db.Exec(`
+		INSERT INTO processed_users (username, login, created_at, total_stars, empty_count, suspicious_empty_count, contributions, analysis_result)
+		VALUES (?, ?, ?, 0, 0, 0, 0, ?)
+		ON CONFLICT(username) DO NOTHING;
+	`, canonicalID(username), username, time.Time{}, analysisResult)
+	if err != nil {
+		return false, fmt.Errorf("inserting processed user: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("inserting processed user: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// InsertHeuristicFlag records a flag raised by the named heuristic, categorized by FlagCategory.
+// A flag already recorded for the entity keeps its first triggered time and takes the new
+// message.
+func (d *Database) InsertHeuristicFlag(entityType, entityID, heuristicName, flag, message string) error {
+	entityID = canonicalID(entityID)
+	_, err := d.insertFlagStmt.Exec(entityType, entityID, flag, FlagKey(flag), heuristicName, FlagCategory(flag, heuristicName), message)
+	if err != nil {
+		return fmt.Errorf("inserting heuristic flag: %w", err)
+	}
+	return nil
+}
+
+// ReplaceRepoCheckerResults stores the checker results of a repository's latest scan,
+// replacing those of earlier scans.
+func (d *Database) ReplaceRepoCheckerResults(repoID string, results []RepoCheckerResult) error {
+	repoID = canonicalID(repoID)
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning checker result update: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM repo_checker_results WHERE repo_id = ?`, repoID); err != nil {
+		return fmt.Errorf("clearing checker results: %w", err)
+	}
+	for _, result := range results {
+		if _, err := tx.Exec(`
+			INSERT INTO repo_checker_results (repo_id, checker, flagged, severity, evidence, checked_at)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			repoID, result.Checker, result.Flagged, result.Severity, result.Evidence, time.Now().UTC(),
+		); err != nil {
+			return fmt.Errorf("inserting checker result %s: %w", result.Checker, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing checker results: %w", err)
+	}
+	return nil
+}
+
+// ListRepoCheckerResults returns the stored checker results for a repository, ordered by checker.
+func (d *Database) ListRepoCheckerResults(repoID string) ([]RepoCheckerResult, error) {
+	repoID = canonicalID(repoID)
+	rows, err := d.db.Query(`
+		SELECT repo_id, checker, flagged, severity, evidence, checked_at
+		FROM repo_checker_results
+		WHERE repo_id = ?
+		ORDER BY checker ASC;`, repoID)
+	if err != nil {
+		return nil, fmt.Errorf("querying checker results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []RepoCheckerResult
+	for rows.Next() {
+		var result RepoCheckerResult
+		var evidence sql.NullString
+		if err := rows.Scan(&result.RepoID, &result.Checker, &result.Flagged, &result.Severity, &evidence, &result.CheckedAt); err != nil {
+			return nil, fmt.Errorf("scanning checker result: %w", err)
+		}
+		result.Evidence = evidence.String
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating checker results: %w", err)
+	}
+	return results, nil
+}
+
+// UpsertURLThreat records a Safe Browsing match for a link found in a repository README
+func (d *Database) UpsertURLThreat(repoID, url, threatType, platform string) error {
+	repoID = canonicalID(repoID)
+	_, err := d.db.Exec(`
+		INSERT INTO url_threats (repo_id, url, threat_type, platform)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(repo_id, url, threat_type) DO UPDATE SET
+			platform = excluded.platform,
+			checked_at = CURRENT_TIMESTAMP;
+	`, repoID, url, threatType, platform)
+	if err != nil {
+		return fmt.Errorf("upserting url threat: %w", err)
+	}
+	return nil
+}
+
+// UpsertURLScan stores a urlscan.io submission, updating its verdict once the scan completes.
+func (d *Database) UpsertURLScan(scan URLScan) error {
+	scan.RepoID = canonicalID(scan.RepoID)
+	_, err := d.db.Exec(`
+		INSERT INTO url_scans (uuid, repo_id, url, result_url, screenshot_url, verdict, score, submitted_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(uuid) DO UPDATE SET
+			result_url = excluded.result_url,
+			screenshot_url = excluded.screenshot_url,
+			verdict = excluded.verdict,
+			score = excluded.score,
+			completed_at = excluded.completed_at;
+	`, scan.UUID, scan.RepoID, scan.URL, scan.ResultURL, scan.ScreenshotURL, scan.Verdict, scan.Score, scan.SubmittedAt, scan.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("upserting url scan: %w", err)
+	}
+	return nil
+}
+
+// ListURLScans returns the urlscan.io submissions recorded for a repository, newest first.
+func (d *Database) ListURLScans(repoID string) ([]URLScan, error) {
+	repoID = canonicalID(repoID)
+	rows, err := d.db.Query(`
+		SELECT uuid, repo_id, url, result_url, screenshot_url, verdict, score, submitted_at, completed_at
+		FROM url_scans
+		WHERE repo_id = ?
+		ORDER BY submitted_at DESC;
+	`, repoID)
+	if err != nil {
+		return nil, fmt.Errorf("querying url scans: %w", err)
+	}
+	defer rows.Close()
+
+	var scans []URLScan
+	for rows.Next() {
+		var scan URLScan
+		if err := rows.Scan(&scan.UUID, &scan.RepoID, &scan.URL, &scan.ResultURL, &scan.ScreenshotURL, &scan.Verdict, &scan.Score, &scan.SubmittedAt, &scan.CompletedAt); err != nil {
+			return nil, fmt.Errorf("scanning url scan: %w", err)
+		}
+		scans = append(scans, scan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating url scans: %w", err)
+	}
+	return scans, nil
+}
+
+// processedRepoColumns are the processed_repositories columns read by scanProcessedRepo.
+const processedRepoColumns = "repo_id, owner, name, updated_at, disk_usage, stargazer_count, is_malicious, COALESCE(github_id, 0), COALESCE(node_id, ''), COALESCE(status, ''), status_updated_at, COALESCE(topics, ''), COALESCE(description, ''), processed_at"
+
+func scanProcessedRepo(row interface{ Scan(...interface{}) error }) (ProcessedRepo, error) {
+	var repo ProcessedRepo
+	var statusUpdatedAt sql.NullTime
+	var topics string
+	if err := row.Scan(&repo.RepoID, &repo.Owner, &repo.Name, &repo.UpdatedAt, &repo.DiskUsage, &repo.StargazerCount, &repo.IsMalicious, &repo.GitHubID, &repo.NodeID, &repo.Status, &statusUpdatedAt, &topics, &repo.Description, &repo.ProcessedAt); err != nil {
+		return ProcessedRepo{}, err
+	}
+	if statusUpdatedAt.Valid {
+		repo.StatusUpdatedAt = &statusUpdatedAt.Time
+	}
+	if topics != "" {
+		repo.Topics = strings.Split(topics, ",")
+	}
+	return repo, nil
+}
+
+// repoKeyedTables are the tables whose rows belong to a repository, with the columns that
+// identify it. RenameRepo moves their rows to the new repo ID.
+var repoKeyedTables = []struct {
+	table, column, filter string
+}{
+	{"repo_checker_results", "repo_id", ""},
+	{"heuristic_flags", "entity_id", " AND entity_type = 'repo'"},
+	{"url_threats", "repo_id", ""},
+	{"url_scans", "repo_id", ""},
+	{"abuse_reports", "entity_id", " AND entity_type = 'repo'"},
+	{"allowlist", "entity_id", " AND entity_type = 'repo'"},
+	{"stargazers", "repo_id", ""},
+	{"release_assets", "repo_id", ""},
+}
+
+// RenameRepo moves a stored repository from oldID to owner/name and records the mapping in
+// repo_renames. Verdicts, flags, and other rows keyed by the repository follow it; where the
+// new ID already has an equivalent row, that row is kept.
+func (d *Database) RenameRepo(oldID, owner, name string) error {
+	oldID = canonicalID(oldID)
+	newID := canonicalID(owner + "/" + name)
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("starting repository rename: %w", err)
+	}
+	defer tx.Rollback()
+
+	type statement struct {
+		query string
+		args  []interface{}
+	}
+	stmts := []statement{
+		{`INSERT INTO repo_renames (old_id, new_id) VALUES (?, ?)
+			ON CONFLICT(old_id) DO UPDATE SET new_id = excluded.new_id, renamed_at = CURRENT_TIMESTAMP;`, []interface{}{oldID, newID}},
+		{`UPDATE processed_repositories SET is_malicious = 1
+			WHERE repo_id = ? AND EXISTS (SELECT 1 FROM processed_repositories WHERE repo_id = ? AND is_malicious);`, []interface{}{newID, oldID}},
+		{`UPDATE OR IGNORE processed_repositories SET repo_id = ?, owner = ?, name = ? WHERE repo_id = ?;`, []interface{}{newID, owner, name, oldID}},
+		{`DELETE FROM processed_repositories WHERE repo_id = ?;`, []interface{}{oldID}},
+	}
+	for _, table := range repoKeyedTables {
+		stmts = append(stmts,
+			statement{fmt.Sprintf("UPDATE OR IGNORE %s SET %s = ? WHERE %s = ?%s;", table.table, table.column, table.column, table.filter), []interface{}{newID, oldID}},
+			statement{fmt.Sprintf("DELETE FROM %s WHERE %s = ?%s;", table.table, table.column, table.filter), []interface{}{oldID}},
+		)
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt.query, stmt.args...); err != nil {
+			return fmt.Errorf("renaming repository %s to %s: %w", oldID, newID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing repository rename: %w", err)
+	}
+	return nil
+}
+
+// ListRepoRenames returns recorded repository renames, most recent first.
+func (d *Database) ListRepoRenames() ([]RepoRename, error) {
+	rows, err := d.db.Query(`SELECT old_id, new_id, renamed_at FROM repo_renames ORDER BY renamed_at DESC, id DESC;`)
+	if err != nil {
+		return nil, fmt.Errorf("querying repository renames: %w", err)
+	}
+	defer rows.Close()
+
+	var renames []RepoRename
+	for rows.Next() {
+		var rename RepoRename
+		if err := rows.Scan(&rename.OldID, &rename.NewID, &rename.RenamedAt); err != nil {
+			return nil, fmt.Errorf("scanning repository rename: %w", err)
+		}
+		renames = append(renames, rename)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating repository renames: %w", err)
+	}
+	return renames, nil
+}
+
+// GetProcessedRepo returns the persisted scan result for a repository.
+func (d *Database) GetProcessedRepo(repoID string) (ProcessedRepo, error) {
+	repoID = canonicalID(repoID)
+	repo, err := scanProcessedRepo(d.db.QueryRow(`
+		SELECT `+processedRepoColumns+`
+		FROM processed_repositories
+		WHERE repo_id = ?;
+	`, repoID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ProcessedRepo{}, fmt.Errorf("processed repository %q %w", repoID, ErrNotFound)
+		}
+		return ProcessedRepo{}, fmt.Errorf("querying processed repository: %w", err)
+	}
+	return repo, nil
+}
+
+// GetProcessedUser returns the persisted analysis result for a user.
+func (d *Database) GetProcessedUser(username string) (ProcessedUser, error) {
+	user, found, err := d.LookupProcessedUser(username)
+	if err != nil {
+		return ProcessedUser{}, err
+	}
+	if !found {
+		return ProcessedUser{}, fmt.Errorf("processed user %q %w", username, ErrNotFound)
+	}
+	return user, nil
+}
+
+// LookupProcessedUser returns the persisted analysis result for a user and whether one exists.
+func (d *Database) LookupProcessedUser(username string) (ProcessedUser, bool, error) {
+	username = canonicalID(username)
+	var user ProcessedUser
+	err := d.db.QueryRow(`
+		SELECT username, COALESCE(login, username), created_at, total_stars, empty_count, suspicious_empty_count, contributions, COALESCE(followers, 0), COALESCE(following, 0), COALESCE(fork_count, 0), COALESCE(score, 0), analysis_result, COALESCE(tier, ''), COALESCE(github_id, 0), COALESCE(node_id, ''), processed_at
+		FROM processed_users
+		WHERE username = ?;
+	`, username).Scan(&user.Username, &user.Login, &user.CreatedAt, &user.TotalStars, &user.EmptyCount, &user.SuspiciousEmptyCount, &user.Contributions, &user.Followers, &user.Following, &user.ForkCount, &user.Score, &user.Suspicious, &user.Tier, &user.GitHubID, &user.NodeID, &user.ProcessedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ProcessedUser{}, false, nil
+	}
+	if err != nil {
+		return ProcessedUser{}, false, fmt.Errorf("querying processed user: %w", err)
+	}
+	return user, true, nil
+}
+
+// ListFlaggedRepos returns processed repositories that are malicious or carry at least one
+// repository flag, ordered by repo ID. A non-empty owner limits the result to that owner.
+func (d *Database) ListFlaggedRepos(owner string) ([]ProcessedRepo, error) {
+	var repos []ProcessedRepo
+	err := d.EachFlaggedRepo(owner, func(repo ProcessedRepo) error {
+		repos = append(repos, repo)
+		return nil
+	})
+	return repos, err
+}
+
+// EachFlaggedRepo calls fn with each repository ListFlaggedRepos would return, reading rows as
+// fn consumes them, and stops at the first error fn returns. fn must not query the database,
+// which may hold a single connection.
+func (d *Database) EachFlaggedRepo(owner string, fn func(ProcessedRepo) error) error {
+	rows, err := d.db.Query(`
+		SELECT `+processedRepoColumns+`
+		FROM processed_repositories
+		WHERE (? = '' OR owner = ? COLLATE NOCASE)
+			AND (is_malicious OR repo_id IN (SELECT entity_id FROM heuristic_flags WHERE entity_type = 'repo'))
+		ORDER BY repo_id ASC;
+	`, owner, owner)
+	if err != nil {
+		return fmt.Errorf("querying flagged repositories: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		repo, err := scanProcessedRepo(rows)
+		if err != nil {
+			return fmt.Errorf("scanning flagged repository: %w", err)
+		}
+		if err := fn(repo); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating flagged repositories: %w", err)
+	}
+	return nil
+}
+
+// ListHeuristicFlags returns the flags recorded for one entity, oldest first.
+func (d *Database) ListHeuristicFlags(entityType, entityID string) ([]HeuristicFlag, error) {
+	entityID = canonicalID(entityID)
+	rows, err := d.db.Query(`
+		SELECT entity_type, entity_id, flag, COALESCE(flag_key, ''), COALESCE(heuristic_name, ''), COALESCE(category, 'uncategorized'), COALESCE(message, ''), triggered_at, updated_at
+		FROM heuristic_flags
+		WHERE entity_type = ? AND entity_id = ?
+		ORDER BY triggered_at ASC, id ASC;
+	`, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("querying heuristic flags: %w", err)
+	}
+	defer rows.Close()
+
+	var flags []HeuristicFlag
+	for rows.Next() {
+		var flag HeuristicFlag
+		if err := rows.Scan(&flag.EntityType, &flag.EntityID, &flag.Flag, &flag.FlagKey, &flag.HeuristicName, &flag.Category, &flag.Message, &flag.TriggeredAt, &flag.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning heuristic flag: %w", err)
+		}
+		flags = append(flags, flag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating heuristic flags: %w", err)
+	}
+	return flags, nil
+}
+
+// ListURLThreats returns the Safe Browsing matches recorded for a repository.
+func (d *Database) ListURLThreats(repoID string) ([]URLThreat, error) {
+	repoID = canonicalID(repoID)
+	rows, err := d.db.Query(`
+		SELECT repo_id, url, threat_type, platform, checked_at
+		FROM url_threats
+		WHERE repo_id = ?
+		ORDER BY url ASC;
+	`, repoID)
+	if err != nil {
+		return nil, fmt.Errorf("querying url threats: %w", err)
+	}
+	defer rows.Close()
+
+	var threats []URLThreat
+	for rows.Next() {
+		var threat URLThreat
+		if err := rows.Scan(&threat.RepoID, &threat.URL, &threat.ThreatType, &threat.Platform, &threat.CheckedAt); err != nil {
+			return nil, fmt.Errorf("scanning url threat: %w", err)
+		}
+		threats = append(threats, threat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating url threats: %w", err)
+	}
+	return threats, nil
+}
+
+// SaveAbuseReport stores generated report text. Regenerating a report keeps its review status.
+func (d *Database) SaveAbuseReport(entityType, entityID, body string) error {
+	entityID = canonicalID(entityID)
+	_, err := d.db.Exec(`
+		INSERT INTO abuse_reports (entity_type, entity_id, body, status)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(entity_type, entity_id) DO UPDATE SET
+			body = excluded.body,
+			generated_at = CURRENT_TIMESTAMP;
+	`, entityType, entityID, body, AbuseReportDraft)
+	if err != nil {
+		return fmt.Errorf("saving abuse report: %w", err)
+	}
+	return nil
+}
+
+// SetAbuseReportStatus records the review status of a stored abuse report.
+func (d *Database) SetAbuseReportStatus(entityType, entityID, status string) error {
+	entityID = canonicalID(entityID)
+	switch status {
+	case AbuseReportDraft, AbuseReportReported, AbuseReportActioned, AbuseReportDeclined:
+	default:
+		return fmt.Errorf("unknown abuse report status %q", status)
+	}
+	result, err := d.db.Exec(`
+		UPDATE abuse_reports
+		SET status = ?, status_updated_at = CURRENT_TIMESTAMP
+		WHERE entity_type = ? AND entity_id = ?;
+	`, status, entityType, entityID)
+	if err != nil {
+		return fmt.Errorf("updating abuse report status: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("abuse report for %s %q %w", entityType, entityID, ErrNotFound)
+	}
+	return nil
+}
+
+// ListAbuseReports returns stored abuse reports, optionally filtered by status, newest first.
+func (d *Database) ListAbuseReports(status string) ([]AbuseReport, error) {
+	rows, err := d.db.Query(`
+		SELECT entity_type, entity_id, body, status, generated_at, status_updated_at
+		FROM abuse_reports
+		WHERE ? = '' OR status = ?
+		ORDER BY generated_at DESC, id DESC;
+	`, status, status)
+	if err != nil {
+		return nil, fmt.Errorf("querying abuse reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []AbuseReport
+	for rows.Next() {
+		var report AbuseReport
+		if err := rows.Scan(&report.EntityType, &report.EntityID, &report.Body, &report.Status, &report.GeneratedAt, &report.StatusUpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning abuse report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating abuse reports: %w", err)
+	}
+	return reports, nil
+}
+
+// AddAllowlistEntry clears a repo or user of suspicion. Adding an entity again replaces its
+// reason and keeps the time it was first added.
+func (d *Database) AddAllowlistEntry(entityType, entityID, reason string) error {
+	entityID = canonicalID(entityID)
+	_, err := d.db.Exec(`
+		INSERT INTO allowlist (entity_type, entity_id, reason)
+		VALUES (?, ?, ?)
+		ON CONFLICT(entity_type, entity_id) DO UPDATE SET reason = excluded.reason;
+	`, entityType, entityID, reason)
+	if err != nil {
+		return fmt.Errorf("adding allowlist entry: %w", err)
+	}
+	return nil
+}
+
+// RemoveAllowlistEntry takes an entity off the allowlist. An entity that is not listed returns
+// an error wrapping ErrNotFound.
+func (d *Database) RemoveAllowlistEntry(entityType, entityID string) error {
+	entityID = canonicalID(entityID)
+	result, err := d.db.Exec(`DELETE FROM allowlist WHERE entity_type = ? AND entity_id = ?;`, entityType, entityID)
+	if err != nil {
+		return fmt.Errorf("removing allowlist entry: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("allowlist entry for %s %q %w", entityType, entityID, ErrNotFound)
+	}
+	return nil
+}
+
+// IsAllowlisted reports whether a repo or user is on the allowlist.
+func (d *Database) IsAllowlisted(entityType, entityID string) (bool, error) {
+	var exists bool
+	err := d.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM allowlist WHERE entity_type = ? AND entity_id = ?);`, entityType, canonicalID(entityID)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("checking allowlist: %w", err)
+	}
+	return exists, nil
+}
+
+// ListAllowlist returns the allowlisted entities, most recently added first.
+func (d *Database) ListAllowlist() ([]AllowlistEntry, error) {
+	rows, err := d.db.Query(`
+		SELECT entity_type, entity_id, reason, added_at
+		FROM allowlist
+		ORDER BY added_at DESC, id DESC;
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying allowlist: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []AllowlistEntry
+	for rows.Next() {
+		var entry AllowlistEntry
+		if err := rows.Scan(&entry.EntityType, &entry.EntityID, &entry.Reason, &entry.AddedAt); err != nil {
+			return nil, fmt.Errorf("scanning allowlist entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating allowlist: %w", err)
+	}
+	return entries, nil
+}
+
+// ListEntitiesByHeuristic returns entities flagged by the named heuristic, most recently
+// flagged first, with the total number of matches before limit and offset apply. The name is
+// matched case-insensitively against the flag's heuristic_name. status filters by
+// abuse report review status, or ReviewUnreviewed for entities without a report.
+func (d *Database) ListEntitiesByHeuristic(heuristic, status string, limit, offset int) ([]FlaggedEntity, int, error) {
+	const from = `
+		FROM heuristic_flags f
+		LEFT JOIN processed_repositories r ON f.entity_type = 'repo' AND r.repo_id = f.entity_id
+		LEFT JOIN processed_users u ON f.entity_type = 'user' AND u.username = f.entity_id
+		LEFT JOIN abuse_reports a ON a.entity_type = f.entity_type AND a.entity_id = f.entity_id
+		WHERE f.heuristic_name = ? COLLATE NOCASE
+			AND (? = '' OR COALESCE(a.status, 'unreviewed') = ?)`
+	key := strings.TrimSpace(heuristic)
+
+	var total int
+	if err := d.db.QueryRow(`SELECT COUNT(*)`+from, key, status, status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting flagged entities: %w", err)
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := d.db.Query(`
+		SELECT f.entity_type, f.entity_id, f.flag, COALESCE(f.category, 'uncategorized'), COALESCE(f.message, ''), f.triggered_at, f.updated_at,
+			COALESCE(r.stargazer_count, u.total_stars, 0), COALESCE(r.is_malicious, u.analysis_result, 0),
+			r.processed_at, u.processed_at, COALESCE(a.status, 'unreviewed')`+from+`
+		ORDER BY f.updated_at DESC, f.id DESC
+		LIMIT ? OFFSET ?;
+	`, key, status, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("querying flagged entities: %w", err)
+	}
+	defer rows.Close()
+
+	var entities []FlaggedEntity
+	for rows.Next() {
+		var entity FlaggedEntity
+		var repoProcessed, userProcessed sql.NullTime
+		if err := rows.Scan(&entity.EntityType, &entity.EntityID, &entity.Flag, &entity.Category, &entity.Message, &entity.TriggeredAt, &entity.UpdatedAt,
+			&entity.Stars, &entity.Malicious, &repoProcessed, &userProcessed, &entity.ReviewStatus); err != nil {
+			return nil, 0, fmt.Errorf("scanning flagged entity: %w", err)
+		}
+		if repoProcessed.Valid {
+			entity.ProcessedAt = repoProcessed.Time
+		} else if userProcessed.Valid {
+			entity.ProcessedAt = userProcessed.Time
+		}
+		entities = append(entities, entity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterating flagged entities: %w", err)
+	}
+	return entities, total, nil
+}
+
+// InsertStargazer records that username starred a repository.
+func (d *Database) InsertStargazer(repoID, username string, starredAt time.Time) error {
+	repoID, username = canonicalID(repoID), canonicalID(username)
+	_, err := d.db.Exec(`
+		INSERT INTO stargazers (repo_id, username, starred_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(repo_id, username) DO UPDATE SET
+			starred_at = excluded.starred_at,
+			fetched_at = CURRENT_TIMESTAMP;
+	`, repoID, username, starredAt)
+	if err != nil {
+		return fmt.Errorf("inserting stargazer: %w", err)
+	}
+	return nil
+}
+
+// ListStargazers returns the recorded stargazer logins of a repository.
+func (d *Database) ListStargazers(repoID string) ([]string, error) {
+	repoID = canonicalID(repoID)
+	rows, err := d.db.Query(`SELECT username FROM stargazers WHERE repo_id = ? ORDER BY username ASC;`, repoID)
+	if err != nil {
+		return nil, fmt.Errorf("querying stargazers: %w", err)
+	}
+	defer rows.Close()
+
+	var usernames []string
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, fmt.Errorf("scanning stargazer: %w", err)
+		}
+		usernames = append(usernames, username)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating stargazers: %w", err)
+	}
+	return usernames, nil
+}
+
+// SetRepoFingerprint stores the content fingerprint of a processed repository. An empty
+// fingerprint clears it.
+func (d *Database) SetRepoFingerprint(repoID, fingerprint string) error {
+	repoID = canonicalID(repoID)
+	_, err := d.db.Exec(`UPDATE processed_repositories SET fingerprint = NULLIF(?, '') WHERE repo_id = ?;`, fingerprint, repoID)
+	if err != nil {
+		return fmt.Errorf("setting repository fingerprint: %w", err)
+	}
+	return nil
+}
+
+// SetRepoTopics stores the GitHub topics of a processed repository. GitHub topics are
+// lowercase letters, digits, and hyphens, so they are stored comma-separated.
+func (d *Database) SetRepoTopics(repoID string, topics []string) error {
+	repoID = canonicalID(repoID)
+	_, err := d.db.Exec(`UPDATE processed_repositories SET topics = NULLIF(?, '') WHERE repo_id = ?;`, strings.Join(topics, ","), repoID)
+	if err != nil {
+		return fmt.Errorf("setting repository topics: %w", err)
+	}
+	return nil
+}
+
+// SetRepoDescription stores the description of a processed repository.
+func (d *Database) SetRepoDescription(repoID, description string) error {
+	repoID = canonicalID(repoID)
+	_, err := d.db.Exec(`UPDATE processed_repositories SET description = NULLIF(?, '') WHERE repo_id = ?;`, description, repoID)
+	if err != nil {
+		return fmt.Errorf("setting repository description: %w", err)
+	}
+	return nil
+}
+
+// SetRepoGitHubID stores GitHub's numeric and node IDs for a processed repository.
+func (d *Database) SetRepoGitHubID(repoID string, githubID int64, nodeID string) error {
+	repoID = canonicalID(repoID)
+	_, err := d.db.Exec(`UPDATE processed_repositories SET github_id = ?, node_id = NULLIF(?, '') WHERE repo_id = ?;`, githubID, nodeID, repoID)
+	if err != nil {
+		return fmt.Errorf("setting repository GitHub ID: %w", err)
+	}
+	return nil
+}
+
+// RepoIDForGitHubID returns the stored repository ID ("owner/name") carrying githubID, so a
+// repository seen under a new name can be matched to its earlier record.
+func (d *Database) RepoIDForGitHubID(githubID int64) (string, bool, error) {
+	var repoID string
+	err := d.db.QueryRow(`SELECT repo_id FROM processed_repositories WHERE github_id = ? ORDER BY processed_at DESC LIMIT 1;`, githubID).Scan(&repoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("querying repository by GitHub ID: %w", err)
+	}
+	return repoID, true, nil
+}
+
+// SetUserFollowCounts stores a processed user's follower and followed-account counts.
+func (d *Database) SetUserFollowCounts(username string, followers, following int) error {
+	_, err := d.db.Exec(`UPDATE processed_users SET followers = ?, following = ? WHERE username = ?;`, followers, following, canonicalID(username))
+	if err != nil {
+		return fmt.Errorf("updating user follow counts: %w", err)
+	}
+	return nil
+}
+
+// SetUserForkCount stores how many of a processed user's repositories are forks.
+func (d *Database) SetUserForkCount(username string, forks int) error {
+	_, err := d.db.Exec(`UPDATE processed_users SET fork_count = ? WHERE username = ?;`, forks, canonicalID(username))
+	if err != nil {
+		return fmt.Errorf("updating user fork count: %w", err)
+	}
+	return nil
+}
+
+// SetUserAvatarHash stores a processed user's avatar hash, as 16 hex digits.
+func (d *Database) SetUserAvatarHash(username, hash string) error {
+	_, err := d.db.Exec(`UPDATE processed_users SET avatar_hash = ? WHERE username = ?;`, hash, canonicalID(username))
+	if err != nil {
+		return fmt.Errorf("updating user avatar hash: %w", err)
+	}
+	return nil
+}
+
+// FindFlaggedUsersByAvatarHash returns the suspicious users whose stored avatar hash differs
+// from hash in at most maxDistance bits, ordered by username.
+func (d *Database) FindFlaggedUsersByAvatarHash(hash uint64, maxDistance int) ([]string, error) {
+	rows, err := d.db.Query(`SELECT username, avatar_hash FROM processed_users
+		WHERE analysis_result = 1 AND avatar_hash IS NOT NULL AND avatar_hash != ''
+		ORDER BY username ASC;`)
+	if err != nil {
+		return nil, fmt.Errorf("querying users by avatar hash: %w", err)
+	}
+	defer rows.Close()
+
+	var usernames []string
+	for rows.Next() {
+		var username, stored string
+		if err := rows.Scan(&username, &stored); err != nil {
+			return nil, fmt.Errorf("scanning user by avatar hash: %w", err)
+		}
+		other, err := strconv.ParseUint(stored, 16, 64)
+		if err != nil {
+			continue
+		}
+		if bits.OnesCount64(hash^other) <= maxDistance {
+			usernames = append(usernames, username)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating users by avatar hash: %w", err)
+	}
+	return usernames, nil
+}
+
+// SetUserScore stores a processed user's total heuristic score.
+func (d *Database) SetUserScore(username string, score float64) error {
+	_, err := d.db.Exec(`UPDATE processed_users SET score = ? WHERE username = ?;`, score, canonicalID(username))
+	if err != nil {
+		return fmt.Errorf("updating user score: %w", err)
+	}
+	return nil
+}
+
+// SetUserGitHubID stores GitHub's numeric and node IDs for a processed user and returns the
+// numeric ID stored before, or zero. A different earlier ID means the login was deleted and
+// registered again by another account, so the heuristic flags recorded for the earlier account
+// are dropped.
+func (d *Database) SetUserGitHubID(username string, githubID int64, nodeID string) (int64, error) {
+	username = canonicalID(username)
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("starting user GitHub ID update: %w", err)
+	}
+	defer tx.Rollback()
+
+	var previous int64
+	err = tx.QueryRow(`SELECT COALESCE(github_id, 0) FROM processed_users WHERE username = ?;`, username).Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("querying user GitHub ID: %w", err)
+	}
+	if previous != 0 && previous != githubID {
+		if _, err := tx.Exec(`DELETE FROM heuristic_flags WHERE entity_type = 'user' AND entity_id = ?;`, username); err != nil {
+			return 0, fmt.Errorf("dropping flags of re-registered user: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE processed_users SET github_id = ?, node_id = NULLIF(?, '') WHERE username = ?;`, githubID, nodeID, username); err != nil {
+		return 0, fmt.Errorf("setting user GitHub ID: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing user GitHub ID: %w", err)
+	}
+	return previous, nil
+}
+
+// ReposWithFingerprint returns the IDs of stored repositories with a content fingerprint,
+// ordered by repo ID.
+func (d *Database) ReposWithFingerprint(fingerprint string) ([]string, error) {
+	rows, err := d.db.Query(`SELECT repo_id FROM processed_repositories WHERE fingerprint = ? ORDER BY repo_id ASC;`, fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("querying repositories by fingerprint: %w", err)
+	}
+	defer rows.Close()
+
+	var repoIDs []string
+	for rows.Next() {
+		var repoID string
+		if err := rows.Scan(&repoID); err != nil {
+			return nil, fmt.Errorf("scanning repository by fingerprint: %w", err)
+		}
+		repoIDs = append(repoIDs, repoID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating repositories by fingerprint: %w", err)
+	}
+	return repoIDs, nil
+}
+
+// ReplaceReleaseAssets replaces the payload assets recorded for a repository.
+func (d *Database) ReplaceReleaseAssets(repoID string, assets []ReleaseAsset) error {
+	repoID = canonicalID(repoID)
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning release asset update: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM release_assets WHERE repo_id = ?;`, repoID); err != nil {
+		return fmt.Errorf("clearing release assets: %w", err)
+	}
+	for _, asset := range assets {
+		if _, err := tx.Exec(`
+			INSERT INTO release_assets (repo_id, name, size, hash) VALUES (?, ?, ?, ?)
+			ON CONFLICT(repo_id, name) DO UPDATE SET size = excluded.size, hash = excluded.hash;`,
+			repoID, asset.Name, asset.Size, asset.Hash,
+		); err != nil {
+			return fmt.Errorf("inserting release asset %s: %w", asset.Name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing release assets: %w", err)
+	}
+	return nil
+}
+
+// FindReposByAssetHash returns the IDs of stored repositories whose releases ship an asset
+// with hash, ordered by repo ID.
+func (d *Database) FindReposByAssetHash(hash string) ([]string, error) {
+	rows, err := d.db.Query(`SELECT DISTINCT repo_id FROM release_assets WHERE hash = ? ORDER BY repo_id ASC;`, hash)
+	if err != nil {
+		return nil, fmt.Errorf("querying repositories by asset hash: %w", err)
+	}
+	defer rows.Close()
+
+	var repoIDs []string
+	for rows.Next() {
+		var repoID string
+		if err := rows.Scan(&repoID); err != nil {
+			return nil, fmt.Errorf("scanning repository by asset hash: %w", err)
+		}
+		repoIDs = append(repoIDs, repoID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating repositories by asset hash: %w", err)
+	}
+	return repoIDs, nil
+}
+
+// SetContentCluster records clusterID on every stored repository with fingerprint.
+func (d *Database) SetContentCluster(fingerprint, clusterID string) error {
+	_, err := d.db.Exec(`UPDATE processed_repositories SET content_cluster = ? WHERE fingerprint = ?;`, clusterID, fingerprint)
+	if err != nil {
+		return fmt.Errorf("setting content cluster: %w", err)
+	}
+	return nil
+}
+
+// ListContentClusters returns recorded content clusters spanning at least minOwners owners,
+// largest first.
+func (d *Database) ListContentClusters(minOwners int) ([]ContentCluster, error) {
+	rows, err := d.db.Query(`
+		SELECT content_cluster, owner, repo_id
+		FROM processed_repositories
+		WHERE content_cluster IS NOT NULL AND content_cluster != ''
+		ORDER BY content_cluster ASC, repo_id ASC;
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying content clusters: %w", err)
+	}
+	defer rows.Close()
+
+	var clusters []ContentCluster
+	for rows.Next() {
+		var clusterID, owner, repoID string
+		if err := rows.Scan(&clusterID, &owner, &repoID); err != nil {
+			return nil, fmt.Errorf("scanning content cluster: %w", err)
+		}
+		if len(clusters) == 0 || clusters[len(clusters)-1].ID != clusterID {
+			clusters = append(clusters, ContentCluster{ID: clusterID})
+		}
+		cluster := &clusters[len(clusters)-1]
+		cluster.Repos = append(cluster.Repos, repoID)
+		if !containsFold(cluster.Owners, owner) {
+			cluster.Owners = append(cluster.Owners, owner)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating content clusters: %w", err)
+	}
+
+	var result []ContentCluster
+	for _, cluster := range clusters {
+		if len(cluster.Owners) >= minOwners {
+			sort.Strings(cluster.Owners)
+			result = append(result, cluster)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return len(result[i].Repos) > len(result[j].Repos) })
+	return result, nil
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListSharedStargazers returns users who starred at least minRepos malicious repositories,
+// most repositories first. The stargazer lists are joined in SQL.
+func (d *Database) ListSharedStargazers(minRepos int) ([]SharedStargazer, error) {
+	return d.sharedStargazers("r.is_malicious = 1", minRepos)
+}
+
+// GetStargazerOverlap returns users who starred at least minShared flagged repositories, ones
+// that are malicious or carry a repository flag, most repositories first.
+func (d *Database) GetStargazerOverlap(minShared int) ([]SharedStargazer, error) {
+	return d.sharedStargazers(`r.is_malicious = 1
+				OR r.repo_id IN (SELECT entity_id FROM heuristic_flags WHERE entity_type = 'repo')`, minShared)
+}
+
+// sharedStargazers returns users who starred at least minRepos of the processed repositories
+// r matching repoFilter, a fixed SQL condition.
+func (d *Database) sharedStargazers(repoFilter string, minRepos int) ([]SharedStargazer, error) {
+	rows, err := d.db.Query(`
+		WITH matched_stars AS (
+			SELECT s.username, s.repo_id
+			FROM stargazers s
+			JOIN processed_repositories r ON r.repo_id = s.repo_id
+			WHERE (`+repoFilter+`)
+		)
+		SELECT username, repo_id
+		FROM matched_stars
+		WHERE username IN (
+			SELECT username FROM matched_stars
+			GROUP BY username
+			HAVING COUNT(DISTINCT repo_id) >= ?
+		)
+		ORDER BY username ASC, repo_id ASC;
+	`, minRepos)
+	if err != nil {
+		return nil, fmt.Errorf("querying shared stargazers: %w", err)
+	}
+	defer rows.Close()
+
+	var shared []SharedStargazer
+	for rows.Next() {
+		var username, repoID string
+		if err := rows.Scan(&username, &repoID); err != nil {
+			return nil, fmt.Errorf("scanning shared stargazer: %w", err)
+		}
+		if len(shared) == 0 || shared[len(shared)-1].Username != username {
+			shared = append(shared, SharedStargazer{Username: username})
+		}
+		last := &shared[len(shared)-1]
+		last.Repos = append(last.Repos, repoID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating shared stargazers: %w", err)
+	}
+	sort.SliceStable(shared, func(i, j int) bool { return len(shared[i].Repos) > len(shared[j].Repos) })
+	return shared, nil
+}
+
+// EachHeuristicFlag calls fn with every recorded flag, ordered by entity and then oldest first,
+// reading rows as fn consumes them. It stops at the first error fn returns, and fn must not
+// query the database.
+func (d *Database) EachHeuristicFlag(fn func(HeuristicFlag) error) error {
+	rows, err := d.db.Query(`
+		SELECT entity_type, entity_id, flag, COALESCE(flag_key, ''), COALESCE(heuristic_name, ''), COALESCE(category, 'uncategorized'), COALESCE(message, ''), triggered_at, updated_at
+		FROM heuristic_flags
+		ORDER BY entity_type ASC, entity_id ASC, triggered_at ASC, id ASC;
+	`)
+	if err != nil {
+		return fmt.Errorf("querying heuristic flags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var flag HeuristicFlag
+		if err := rows.Scan(&flag.EntityType, &flag.EntityID, &flag.Flag, &flag.FlagKey, &flag.HeuristicName, &flag.Category, &flag.Message, &flag.TriggeredAt, &flag.UpdatedAt); err != nil {
+			return fmt.Errorf("scanning heuristic flag: %w", err)
+		}
+		if err := fn(flag); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating heuristic flags: %w", err)
+	}
+	return nil
+}
+
+// ListHeuristicFlagsBetween returns flags first triggered in [since, until), oldest first.
+func (d *Database) ListHeuristicFlagsBetween(since, until time.Time) ([]HeuristicFlag, error) {
+	rows, err := d.db.Query(`
+		SELECT entity_type, entity_id, flag, flag_key, COALESCE(heuristic_name, ''), COALESCE(category, 'uncategorized'), message, triggered_at, updated_at
+		FROM heuristic_flags
+		WHERE datetime(triggered_at) >= datetime(?) AND datetime(triggered_at) < datetime(?)
+		ORDER BY triggered_at ASC, id ASC;
+	`, sqliteTime(since), sqliteTime(until))
+	if err != nil {
+		return nil, fmt.Errorf("querying heuristic flags: %w", err)
+	}
+	defer rows.Close()
+
+	var flags []HeuristicFlag
+	for rows.Next() {
+		var flag HeuristicFlag
+		if err := rows.Scan(&flag.EntityType, &flag.EntityID, &flag.Flag, &flag.FlagKey, &flag.HeuristicName, &flag.Category, &flag.Message, &flag.TriggeredAt, &flag.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning heuristic flag: %w", err)
+		}
+		flags = append(flags, flag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating heuristic flags: %w", err)
+	}
+	return flags, nil
+}
+
+// GetScanStats counts repositories and users last processed in [since, until).
+func (d *Database) GetScanStats(since, until time.Time) (ScanStats, error) {
+	var stats ScanStats
+	err := d.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN is_malicious THEN 1 ELSE 0 END), 0)
+		FROM processed_repositories
+		WHERE datetime(processed_at) >= datetime(?) AND datetime(processed_at) < datetime(?);
+	`, sqliteTime(since), sqliteTime(until)).Scan(&stats.ReposProcessed, &stats.ReposMalicious)
+	if err != nil {
+		return ScanStats{}, fmt.Errorf("counting processed repositories: %w", err)
+	}
+	err = d.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN is_malicious
+			OR repo_id IN (SELECT entity_id FROM heuristic_flags WHERE entity_type = 'repo') THEN 1 ELSE 0 END), 0)
+		FROM processed_repositories
+		WHERE status IN ('blocked_dmca', 'disabled')
+			AND datetime(processed_at) >= datetime(?) AND datetime(processed_at) < datetime(?);
+	`, sqliteTime(since), sqliteTime(until)).Scan(&stats.ReposTakenDown, &stats.TakedownsConfirmed)
+	if err != nil {
+		return ScanStats{}, fmt.Errorf("counting unavailable repositories: %w", err)
+	}
+	err = d.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN analysis_result THEN 1 ELSE 0 END), 0)
+		FROM processed_users
+		WHERE datetime(processed_at) >= datetime(?) AND datetime(processed_at) < datetime(?);
+	`, sqliteTime(since), sqliteTime(until)).Scan(&stats.UsersProcessed, &stats.UsersSuspicious)
+	if err != nil {
+		return ScanStats{}, fmt.Errorf("counting processed users: %w", err)
+	}
+	rows, err := d.db.Query(`
+		SELECT COALESCE(category, 'uncategorized'), COUNT(*)
+		FROM heuristic_flags
+		WHERE datetime(triggered_at) >= datetime(?) AND datetime(triggered_at) < datetime(?)
+		GROUP BY 1;
+	`, sqliteTime(since), sqliteTime(until))
+	if err != nil {
+		return ScanStats{}, fmt.Errorf("counting flags by category: %w", err)
+	}
+	defer rows.Close()
+	stats.FlagsByCategory = map[string]int{}
+	for rows.Next() {
+		var category string
+		var count int
+		if err := rows.Scan(&category, &count); err != nil {
+			return ScanStats{}, fmt.Errorf("scanning flag category count: %w", err)
+		}
+		stats.FlagsByCategory[category] = count
+	}
+	if err := rows.Err(); err != nil {
+		return ScanStats{}, fmt.Errorf("iterating flag category counts: %w", err)
+	}
+	return stats, nil
+}
+
+// sqliteTime formats t the way SQLite's datetime() compares it.
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// ReplaceExternalIndicators replaces every indicator previously imported from source, so entries
+// dropped from a blocklist stop matching. Indicators from other sources are left alone.
+func (d *Database) ReplaceExternalIndicators(source string, indicators []ExternalIndicator) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("starting indicator import: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM external_indicators WHERE source = ?;`, source); err != nil {
+		return fmt.Errorf("clearing external indicators: %w", err)
+	}
+	for _, indicator := range indicators {
+		var expiresAt interface{}
+		if !indicator.ExpiresAt.IsZero() {
+			expiresAt = indicator.ExpiresAt.UTC()
+		}
+		_, err := tx.Exec(`
+			INSERT INTO external_indicators (source, indicator_type, value, reason, added_at, expires_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT(source, indicator_type, value) DO UPDATE SET
+				reason = excluded.reason,
+				added_at = excluded.added_at,
+				expires_at = excluded.expires_at;
+		`, source, indicator.IndicatorType, strings.ToLower(indicator.Value), indicator.Reason, indicator.AddedAt.UTC(), expiresAt)
+		if err != nil {
+			return fmt.Errorf("inserting external indicator: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing indicator import: %w", err)
+	}
+	return nil
+}
+
+// LookupExternalIndicator returns the blocklist source that lists value, ignoring expired entries.
+func (d *Database) LookupExternalIndicator(indicatorType, value string) (string, bool, error) {
+	var source string
+	err := d.db.QueryRow(`
+		SELECT source
+		FROM external_indicators
+		WHERE indicator_type = ? AND value = ? AND (expires_at IS NULL OR expires_at > ?)
+		ORDER BY added_at ASC, id ASC
+		LIMIT 1;
+	`, indicatorType, strings.ToLower(value), time.Now().UTC()).Scan(&source)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("querying external indicator: %w", err)
+	}
+	return source, true, nil
+}
+
+// ListMaliciousRepos returns repositories whose content matched the malware checks, ordered by repo ID.
+func (d *Database) ListMaliciousRepos() ([]ProcessedRepo, error) {
+	rows, err := d.db.Query(`
+		SELECT ` + processedRepoColumns + `
+		FROM processed_repositories
+		WHERE is_malicious
+		ORDER BY repo_id ASC;
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying malicious repositories: %w", err)
+	}
+	defer rows.Close()
+
+	var repos []ProcessedRepo
+	for rows.Next() {
+		repo, err := scanProcessedRepo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning malicious repository: %w", err)
+		}
+		repos = append(repos, repo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating malicious repositories: %w", err)
+	}
+	return repos, nil
+}
+
+// ListSuspiciousUsers returns users whose analysis flagged them, ordered by username.
+func (d *Database) ListSuspiciousUsers() ([]ProcessedUser, error) {
+	var users []ProcessedUser
+	err := d.EachSuspiciousUser(func(user ProcessedUser) error {
+		users = append(users, user)
+		return nil
+	})
+	return users, err
+}
+
+// EachSuspiciousUser calls fn with each user ListSuspiciousUsers would return, reading rows as
+// fn consumes them, and stops at the first error fn returns. fn must not query the database.
+func (d *Database) EachSuspiciousUser(fn func(ProcessedUser) error) error {
+	rows, err := d.db.Query(`
+		SELECT username, COALESCE(login, username), created_at, total_stars, empty_count, suspicious_empty_count, contributions, COALESCE(followers, 0), COALESCE(following, 0), COALESCE(fork_count, 0), COALESCE(score, 0), analysis_result, COALESCE(tier, ''), COALESCE(github_id, 0), COALESCE(node_id, ''), processed_at
+		FROM processed_users
+		WHERE analysis_result
+		ORDER BY username ASC;
+	`)
+	if err != nil {
+		return fmt.Errorf("querying suspicious users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user ProcessedUser
+		if err := rows.Scan(&user.Username, &user.Login, &user.CreatedAt, &user.TotalStars, &user.EmptyCount, &user.SuspiciousEmptyCount, &user.Contributions, &user.Followers, &user.Following, &user.ForkCount, &user.Score, &user.Suspicious, &user.Tier, &user.GitHubID, &user.NodeID, &user.ProcessedAt); err != nil {
+			return fmt.Errorf("scanning suspicious user: %w", err)
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating suspicious users: %w", err)
+	}
+	return nil
+}
+
+// ListPendingRepos returns repositories recorded without being analyzed, such as legacy
+// imports, oldest first. They still have the zero updated_at of InsertProcessedRepoIfAbsent;
+// repositories GitHub stopped serving are left out. A limit of zero or less lists all of them.
+func (d *Database) ListPendingRepos(limit int) ([]ProcessedRepo, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := d.db.Query(`
+		SELECT `+processedRepoColumns+`
+		FROM processed_repositories
+		WHERE updated_at = ? AND COALESCE(status, '') = ''
+		ORDER BY processed_at ASC, id ASC
+		LIMIT ?;
+	`, time.Time{}, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying pending repositories: %w", err)
+	}
+	defer rows.Close()
+
+	var repos []ProcessedRepo
+	for rows.Next() {
+		repo, err := scanProcessedRepo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning pending repository: %w", err)
+		}
+		repos = append(repos, repo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating pending repositories: %w", err)
+	}
+	return repos, nil
+}
+
+// ListPendingUsers returns users recorded without being analyzed, oldest first. They still
+// have the zero created_at of InsertProcessedUserIfAbsent. A limit of zero or less lists all.
+func (d *Database) ListPendingUsers(limit int) ([]string, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := d.db.Query(`
+		SELECT COALESCE(login, username)
+		FROM processed_users
+		WHERE created_at = ?
+		ORDER BY processed_at ASC, id ASC
+		LIMIT ?;
+	`, time.Time{}, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying pending users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []string
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, fmt.Errorf("scanning pending user: %w", err)
+		}
+		users = append(users, username)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating pending users: %w", err)
+	}
+	return users, nil
+}
+
+// ListAnalyzedUsers returns the users with a stored verdict, least recently analyzed first.
+// Pending users, recorded without being analyzed, are left out. A limit of zero or less lists
+// all of them.
+func (d *Database) ListAnalyzedUsers(limit int) ([]ProcessedUser, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := d.db.Query(`
+		SELECT username, COALESCE(login, username), created_at, total_stars, empty_count, suspicious_empty_count, contributions, COALESCE(followers, 0), COALESCE(following, 0), COALESCE(fork_count, 0), COALESCE(score, 0), analysis_result, COALESCE(tier, ''), COALESCE(github_id, 0), COALESCE(node_id, ''), processed_at
+		FROM processed_users
+		WHERE created_at != ?
+		ORDER BY processed_at ASC, id ASC
+		LIMIT ?;
+	`, time.Time{}, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying analyzed users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []ProcessedUser
+	for rows.Next() {
+		var user ProcessedUser
+		if err := rows.Scan(&user.Username, &user.Login, &user.CreatedAt, &user.TotalStars, &user.EmptyCount, &user.SuspiciousEmptyCount, &user.Contributions, &user.Followers, &user.Following, &user.ForkCount, &user.Score, &user.Suspicious, &user.Tier, &user.GitHubID, &user.NodeID, &user.ProcessedAt); err != nil {
+			return nil, fmt.Errorf("scanning analyzed user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating analyzed users: %w", err)
+	}
+	return users, nil
+}
+
+// GetProcessedUsers returns a list of all processed usernames
 func (d *Database) GetProcessedUsers() ([]string, error) {
 	rows, err := d.db.Query(`SELECT username FROM processed_users;`)
 	if err != nil {
@@ -318,6 +2500,7 @@ func (d *Database) GetProcessedUsers() ([]string, error) {
 
 // WasRepoProcessed checks if a repository has already been processed
 func (d *Database) WasRepoProcessed(repoID string, updatedAt time.Time) (bool, error) {
+	repoID = canonicalID(repoID)
 	var storedUpdatedAt time.Time
 	err := d.db.QueryRow("SELECT updated_at FROM processed_repositories WHERE repo_id = ?", repoID).Scan(&storedUpdatedAt)
 	if err != nil {
@@ -403,7 +2586,7 @@ func (d *Database) GetSearchCheckpoint(name string) (SearchCheckpoint, error) {
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return SearchCheckpoint{}, fmt.Errorf("search checkpoint %q not found", name)
+			return SearchCheckpoint{}, fmt.Errorf("search checkpoint %q %w", name, ErrNotFound)
 		}
 		return SearchCheckpoint{}, fmt.Errorf("querying search checkpoint: %w", err)
 	}
@@ -452,6 +2635,36 @@ func (d *Database) ListSearchCheckpoints() ([]SearchCheckpoint, error) {
 	return checkpoints, nil
 }
 
+// RecordSearchRun stores the start and completion time of the latest successful search under
+// name, which is the checkpoint name or "" for searches without one.
+func (d *Database) RecordSearchRun(name string, startedAt, completedAt time.Time) error {
+	_, err := d.db.Exec(`
+		INSERT INTO search_runs (name, started_at, completed_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			started_at = excluded.started_at,
+			completed_at = excluded.completed_at;
+	`, name, startedAt.UTC(), completedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("recording search run: %w", err)
+	}
+	return nil
+}
+
+// LastSearchRun returns when the latest successful search recorded under name started, or the
+// zero time when none has been recorded.
+func (d *Database) LastSearchRun(name string) (time.Time, error) {
+	var startedAt time.Time
+	err := d.db.QueryRow(`SELECT started_at FROM search_runs WHERE name = ?`, name).Scan(&startedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("querying last search run: %w", err)
+	}
+	return startedAt, nil
+}
+
 // DeleteSearchCheckpoint removes a named search checkpoint.
 func (d *Database) DeleteSearchCheckpoint(name string) error {
 	result, err := d.db.Exec(`DELETE FROM search_checkpoints WHERE name = ?`, name)
@@ -463,7 +2676,7 @@ func (d *Database) DeleteSearchCheckpoint(name string) error {
 		return fmt.Errorf("checking deleted checkpoint rows: %w", err)
 	}
 	if affected == 0 {
-		return fmt.Errorf("search checkpoint %q not found", name)
+		return fmt.Errorf("search checkpoint %q %w", name, ErrNotFound)
 	}
 	return nil
 }
@@ -1,9 +1,16 @@
 package db
 
 import (
+	"database/sql"
+	"errors"
+	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
 )
 
 func TestInsertProcessedRepoUpsertsUpdatedAt(t *testing.T) {
@@ -41,10 +48,10 @@ func TestInsertProcessedUserUpsertsMetrics(t *testing.T) {
 
 	createdAt := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
 
-	if err := database.InsertProcessedUser("octocat", createdAt, 10, 1, 1, 2, false); err != nil {
+	if err := database.InsertProcessedUser("octocat", createdAt, 10, 1, 1, 2, false, ""); err != nil {
 		t.Fatalf("InsertProcessedUser() initial error = %v", err)
 	}
-	if err := database.InsertProcessedUser("octocat", createdAt, 20, 3, 2, 5, true); err != nil {
+	if err := database.InsertProcessedUser("octocat", createdAt, 20, 3, 2, 5, true, "medium"); err != nil {
 		t.Fatalf("InsertProcessedUser() updated error = %v", err)
 	}
 
@@ -63,6 +70,98 @@ func TestInsertProcessedUserUpsertsMetrics(t *testing.T) {
 		t.Fatalf("processed_users row was not updated: got stars=%d empty=%d suspicious_empty=%d contributions=%d suspicious=%v",
 			totalStars, emptyCount, suspiciousEmptyCount, contributions, analysisResult)
 	}
+	user, err := database.GetProcessedUser("octocat")
+	if err != nil {
+		t.Fatalf("GetProcessedUser() error = %v", err)
+	}
+	if user.Tier != "medium" {
+		t.Fatalf("GetProcessedUser().Tier = %q, want medium", user.Tier)
+	}
+	if _, err := database.GetProcessedUser("ghost"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetProcessedUser(ghost) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMixedCaseIDsResolveToOneKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watchdog.db")
+	database, err := New(path)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	updatedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := database.InsertProcessedRepo("Owner/Repo", "Owner", "Repo", updatedAt, 1, 2, false); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	if err := database.InsertProcessedRepo("owner/repo", "owner", "repo", updatedAt, 1, 2, true); err != nil {
+		t.Fatalf("InsertProcessedRepo() lowercase error = %v", err)
+	}
+	if err := database.InsertHeuristicFlag("repo", "OWNER/REPO", "SafeBrowsingHeuristic", "Malware:SafeBrowsingHeuristic", "first"); err != nil {
+		t.Fatalf("InsertHeuristicFlag() error = %v", err)
+	}
+	if err := database.InsertHeuristicFlag("repo", "owner/Repo", "SafeBrowsingHeuristic", "Malware:SafeBrowsingHeuristic", "second"); err != nil {
+		t.Fatalf("InsertHeuristicFlag() error = %v", err)
+	}
+	if err := database.InsertProcessedUser("OctoCat", updatedAt, 1, 0, 0, 2, false, ""); err != nil {
+		t.Fatalf("InsertProcessedUser() error = %v", err)
+	}
+	if inserted, err := database.InsertProcessedUserIfAbsent("octocat", true); err != nil || inserted {
+		t.Fatalf("InsertProcessedUserIfAbsent() = %v, %v, want the existing row kept", inserted, err)
+	}
+
+	var repos, users int
+	if err := database.QueryRow(`SELECT (SELECT COUNT(*) FROM processed_repositories), (SELECT COUNT(*) FROM processed_users);`).Scan(&repos, &users); err != nil {
+		t.Fatalf("QueryRow().Scan() error = %v", err)
+	}
+	if repos != 1 || users != 1 {
+		t.Fatalf("rows = %d repos, %d users, want one of each", repos, users)
+	}
+	repo, err := database.GetProcessedRepo("Owner/REPO")
+	if err != nil || repo.RepoID != "owner/repo" || !repo.IsMalicious {
+		t.Fatalf("GetProcessedRepo() = %+v, %v, want the lowercased key", repo, err)
+	}
+	flags, err := database.ListHeuristicFlags("repo", "Owner/Repo")
+	if err != nil || len(flags) != 1 || flags[0].Message != "second" {
+		t.Fatalf("ListHeuristicFlags() = %+v, %v, want one flag row", flags, err)
+	}
+	user, err := database.GetProcessedUser("OCTOCAT")
+	if err != nil || user.Username != "octocat" || user.Login != "OctoCat" {
+		t.Fatalf("GetProcessedUser() = %+v, %v, want key octocat with login OctoCat", user, err)
+	}
+
+	// Rows stored under mixed-case keys before canonicalization, in a database from before
+	// schema versioning, are merged on the next open.
+	if _, err := database.Exec(`
+		DELETE FROM schema_version;
+		INSERT INTO processed_repositories (repo_id, owner, name, updated_at, disk_usage, stargazer_count, is_malicious)
+		VALUES ('Evil/Loader', 'Evil', 'Loader', ?, 0, 0, 1), ('evil/loader', 'evil', 'loader', ?, 0, 0, 0);
+		INSERT INTO heuristic_flags (entity_type, entity_id, flag, flag_key, message) VALUES ('repo', 'Evil/Loader', 'Malware:X', 'malware:x', 'old');
+		INSERT INTO processed_users (username, created_at, total_stars, empty_count, suspicious_empty_count, contributions, analysis_result)
+		VALUES ('Spammer', ?, 0, 0, 0, 0, 1);
+	`, updatedAt, updatedAt, updatedAt); err != nil {
+		t.Fatalf("inserting legacy rows error = %v", err)
+	}
+	database.Close()
+	database, err = New(path)
+	if err != nil {
+		t.Fatalf("New() reopen error = %v", err)
+	}
+	defer database.Close()
+
+	repo, err = database.GetProcessedRepo("evil/loader")
+	if err != nil || !repo.IsMalicious {
+		t.Fatalf("GetProcessedRepo() after migration = %+v, %v, want the merged malicious row", repo, err)
+	}
+	if flags, err := database.ListHeuristicFlags("repo", "evil/loader"); err != nil || len(flags) != 1 {
+		t.Fatalf("ListHeuristicFlags() after migration = %+v, %v", flags, err)
+	}
+	user, err = database.GetProcessedUser("spammer")
+	if err != nil || user.Login != "Spammer" || !user.Suspicious {
+		t.Fatalf("GetProcessedUser() after migration = %+v, %v", user, err)
+	}
+	if err := database.QueryRow(`SELECT COUNT(*) FROM processed_repositories WHERE lower(repo_id) = 'evil/loader';`).Scan(&repos); err != nil || repos != 1 {
+		t.Fatalf("evil/loader rows = %d, %v, want one", repos, err)
+	}
 }
 
 func TestSearchCheckpointUpsertAndGet(t *testing.T) {
@@ -158,3 +257,656 @@ func TestListAndDeleteSearchCheckpoints(t *testing.T) {
 		t.Fatalf("remaining checkpoints = %+v", checkpoints)
 	}
 }
+
+func TestUpsertURLScanUpdatesPendingVerdict(t *testing.T) {
+	database, err := New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer database.Close()
+
+	submitted := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	scan := URLScan{
+		UUID:        "abc-123",
+		RepoID:      "owner/repo",
+		URL:         "https://landing.example",
+		ResultURL:   "https://urlscan.io/result/abc-123/",
+		Verdict:     "pending",
+		SubmittedAt: submitted,
+	}
+	if err := database.UpsertURLScan(scan); err != nil {
+		t.Fatalf("UpsertURLScan() pending error = %v", err)
+	}
+	scan.Verdict = "malicious"
+	scan.Score = 100
+	scan.CompletedAt = submitted.Add(time.Minute)
+	if err := database.UpsertURLScan(scan); err != nil {
+		t.Fatalf("UpsertURLScan() completed error = %v", err)
+	}
+
+	scans, err := database.ListURLScans("owner/repo")
+	if err != nil {
+		t.Fatalf("ListURLScans() error = %v", err)
+	}
+	if len(scans) != 1 {
+		t.Fatalf("ListURLScans() len = %d, want 1", len(scans))
+	}
+	if scans[0].Verdict != "malicious" || scans[0].Score != 100 {
+		t.Fatalf("ListURLScans()[0] = %+v, want completed malicious scan", scans[0])
+	}
+}
+
+func TestSaveAbuseReportKeepsReviewStatus(t *testing.T) {
+	database, err := New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer database.Close()
+
+	if err := database.SaveAbuseReport("repo", "evil/loader", "first"); err != nil {
+		t.Fatalf("SaveAbuseReport() error = %v", err)
+	}
+	if err := database.SetAbuseReportStatus("repo", "evil/loader", AbuseReportReported); err != nil {
+		t.Fatalf("SetAbuseReportStatus() error = %v", err)
+	}
+	if err := database.SaveAbuseReport("repo", "evil/loader", "second"); err != nil {
+		t.Fatalf("SaveAbuseReport() regenerate error = %v", err)
+	}
+
+	reports, err := database.ListAbuseReports(AbuseReportReported)
+	if err != nil {
+		t.Fatalf("ListAbuseReports() error = %v", err)
+	}
+	if len(reports) != 1 || reports[0].Body != "second" {
+		t.Fatalf("ListAbuseReports() = %+v, want regenerated reported report", reports)
+	}
+	if err := database.SetAbuseReportStatus("repo", "evil/loader", "bogus"); err == nil {
+		t.Fatal("SetAbuseReportStatus() error = nil, want unknown status error")
+	}
+}
+
+func TestAllowlistEntries(t *testing.T) {
+	database, err := New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer database.Close()
+
+	if err := database.AddAllowlistEntry("repo", "Acme/Tool", "first"); err != nil {
+		t.Fatalf("AddAllowlistEntry() error = %v", err)
+	}
+	if err := database.AddAllowlistEntry("repo", "acme/tool", "vendor SDK"); err != nil {
+		t.Fatalf("AddAllowlistEntry() again error = %v", err)
+	}
+	if listed, err := database.IsAllowlisted("repo", "ACME/tool"); err != nil || !listed {
+		t.Fatalf("IsAllowlisted(repo, ACME/tool) = %v, %v, want true", listed, err)
+	}
+	if listed, err := database.IsAllowlisted("user", "acme/tool"); err != nil || listed {
+		t.Fatalf("IsAllowlisted(user, acme/tool) = %v, %v, want false for another entity type", listed, err)
+	}
+	entries, err := database.ListAllowlist()
+	if err != nil || len(entries) != 1 || entries[0].EntityID != "acme/tool" || entries[0].Reason != "vendor SDK" {
+		t.Fatalf("ListAllowlist() = %+v, %v, want one entry with the replaced reason", entries, err)
+	}
+
+	if err := database.RemoveAllowlistEntry("repo", "acme/tool"); err != nil {
+		t.Fatalf("RemoveAllowlistEntry() error = %v", err)
+	}
+	if err := database.RemoveAllowlistEntry("repo", "acme/tool"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("RemoveAllowlistEntry() again error = %v, want ErrNotFound", err)
+	}
+	if listed, err := database.IsAllowlisted("repo", "acme/tool"); err != nil || listed {
+		t.Fatalf("IsAllowlisted() after remove = %v, %v, want false", listed, err)
+	}
+}
+
+func TestReplaceExternalIndicatorsMergesBySource(t *testing.T) {
+	database, err := New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer database.Close()
+
+	now := time.Now()
+	if err := database.ReplaceExternalIndicators("alpha", []ExternalIndicator{
+		{IndicatorType: "repo", Value: "Evil/Loader", AddedAt: now},
+		{IndicatorType: "user", Value: "dropped", AddedAt: now},
+	}); err != nil {
+		t.Fatalf("ReplaceExternalIndicators(alpha) error = %v", err)
+	}
+	if err := database.ReplaceExternalIndicators("beta", []ExternalIndicator{
+		{IndicatorType: "user", Value: "spammer", AddedAt: now},
+		{IndicatorType: "user", Value: "stale", AddedAt: now.Add(-48 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+	}); err != nil {
+		t.Fatalf("ReplaceExternalIndicators(beta) error = %v", err)
+	}
+	if err := database.ReplaceExternalIndicators("alpha", []ExternalIndicator{
+		{IndicatorType: "repo", Value: "evil/loader", AddedAt: now},
+	}); err != nil {
+		t.Fatalf("ReplaceExternalIndicators(alpha) refresh error = %v", err)
+	}
+
+	tests := []struct {
+		indicatorType string
+		value         string
+		wantSource    string
+		wantFound     bool
+	}{
+		{"repo", "EVIL/loader", "alpha", true},
+		{"user", "dropped", "", false},
+		{"user", "spammer", "beta", true},
+		{"user", "stale", "", false},
+	}
+	for _, tt := range tests {
+		source, found, err := database.LookupExternalIndicator(tt.indicatorType, tt.value)
+		if err != nil {
+			t.Fatalf("LookupExternalIndicator(%q) error = %v", tt.value, err)
+		}
+		if source != tt.wantSource || found != tt.wantFound {
+			t.Fatalf("LookupExternalIndicator(%q) = %q, %v, want %q, %v", tt.value, source, found, tt.wantSource, tt.wantFound)
+		}
+	}
+}
+
+func TestInsertHeuristicFlagUpdatesOnRescan(t *testing.T) {
+	database, err := New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer database.Close()
+
+	for _, message := range []string{"12 of 14 repositories are empty.", "13 of 15 repositories are empty."} {
+		if err := database.InsertHeuristicFlag("user", "spammer", "EmptyRepoHeuristic", "Mass Repository Creation:EmptyRepoHeuristic", message); err != nil {
+			t.Fatalf("InsertHeuristicFlag() error = %v", err)
+		}
+	}
+	if err := database.InsertHeuristicFlag("user", "spammer", "BoilerplateReadmeHeuristic", "Spam Behavior:BoilerplateReadmeHeuristic", ""); err != nil {
+		t.Fatalf("InsertHeuristicFlag() error = %v", err)
+	}
+
+	flags, err := database.ListHeuristicFlags("user", "spammer")
+	if err != nil {
+		t.Fatalf("ListHeuristicFlags() error = %v", err)
+	}
+	if len(flags) != 2 {
+		t.Fatalf("ListHeuristicFlags() = %+v, want one row per heuristic", flags)
+	}
+	if flags[0].Message != "13 of 15 repositories are empty." || flags[0].FlagKey != "mass repository creation:emptyrepoheuristic" {
+		t.Fatalf("ListHeuristicFlags()[0] = %+v, want latest message under stable key", flags[0])
+	}
+}
+
+func TestNewMigratesDuplicateHeuristicFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watchdog.db")
+	legacy, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	_, err = legacy.Exec(`
+		CREATE TABLE heuristic_flags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entity_type TEXT,
+			entity_id TEXT,
+			flag TEXT,
+			triggered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		INSERT INTO heuristic_flags (entity_type, entity_id, flag, triggered_at) VALUES
+			('repo', 'evil/loader', 'Malware:SafeBrowsingHeuristic', '2026-01-01 00:00:00'),
+			('repo', 'evil/loader', 'Malware:SafeBrowsingHeuristic', '2026-02-01 00:00:00'),
+			('repo', 'evil/loader', 'Spam Behavior:BoilerplateReadmeHeuristic', '2026-01-15 00:00:00');
+	`)
+	if err != nil {
+		t.Fatalf("creating legacy table error = %v", err)
+	}
+	legacy.Close()
+
+	database, err := New(path)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer database.Close()
+
+	flags, err := database.ListHeuristicFlags("repo", "evil/loader")
+	if err != nil {
+		t.Fatalf("ListHeuristicFlags() error = %v", err)
+	}
+	if len(flags) != 2 {
+		t.Fatalf("ListHeuristicFlags() = %+v, want duplicates collapsed", flags)
+	}
+	first := flags[0]
+	if first.FlagKey != "malware:safebrowsingheuristic" || first.TriggeredAt.Month() != time.January || first.UpdatedAt.Month() != time.February {
+		t.Fatalf("ListHeuristicFlags()[0] = %+v, want first and last trigger kept", first)
+	}
+	if first.HeuristicName != "SafeBrowsingHeuristic" || flags[1].HeuristicName != "BoilerplateReadmeHeuristic" {
+		t.Fatalf("ListHeuristicFlags() = %+v, want heuristic names backfilled from the flags", flags)
+	}
+
+	if err := database.InsertHeuristicFlag("repo", "evil/loader", "SafeBrowsingHeuristic", "Malware:SafeBrowsingHeuristic", "http://bad.example"); err != nil {
+		t.Fatalf("InsertHeuristicFlag() after migration error = %v", err)
+	}
+	flags, err = database.ListHeuristicFlags("repo", "evil/loader")
+	if err != nil {
+		t.Fatalf("ListHeuristicFlags() error = %v", err)
+	}
+	if len(flags) != 2 {
+		t.Fatalf("ListHeuristicFlags() after rescan = %+v, want no new row", flags)
+	}
+}
+
+func TestMigrateBringsOldSchemaCurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "github_watchdog.db")
+	fixture, err := os.ReadFile(filepath.Join("testdata", "schema_v0.sql"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	old, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	if _, err := old.Exec(string(fixture)); err != nil {
+		t.Fatalf("loading old schema error = %v", err)
+	}
+	old.Close()
+
+	database, err := New(path)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if version, err := database.SchemaVersion(); err != nil || version != LatestSchemaVersion {
+		t.Fatalf("SchemaVersion() = %d, %v, want %d", version, err, LatestSchemaVersion)
+	}
+	for table, want := range map[string][]string{
+		"processed_repositories": {"status", "fingerprint", "github_id", "topics", "description"},
+		"processed_users":        {"tier", "login", "followers", "following", "score", "avatar_hash", "fork_count"},
+		"heuristic_flags":        {"flag_key", "heuristic_name", "category", "message", "updated_at"},
+		"search_checkpoints":     {"activity", "queries_json", "oldest_created_at"},
+	} {
+		columns, err := database.tableColumns(table)
+		if err != nil {
+			t.Fatalf("tableColumns(%s) error = %v", table, err)
+		}
+		for _, column := range want {
+			if !columns[column] {
+				t.Fatalf("%s is missing %s after migration", table, column)
+			}
+		}
+	}
+
+	repo, err := database.GetProcessedRepo("evil/loader")
+	if err != nil || repo.RepoID != "evil/loader" || !repo.IsMalicious {
+		t.Fatalf("GetProcessedRepo() = %+v, %v, want the old row under its lowercased key", repo, err)
+	}
+	if err := database.SetRepoTopics("evil/loader", []string{"chatgpt", "crypto", "fortnite"}); err != nil {
+		t.Fatalf("SetRepoTopics() error = %v", err)
+	}
+	if repo, err := database.GetProcessedRepo("evil/loader"); err != nil || strings.Join(repo.Topics, " ") != "chatgpt crypto fortnite" {
+		t.Fatalf("GetProcessedRepo() = %+v, %v, want the stored topics", repo, err)
+	}
+	if err := database.SetRepoDescription("evil/loader", "Free download, cracked"); err != nil {
+		t.Fatalf("SetRepoDescription() error = %v", err)
+	}
+	if repo, err := database.GetProcessedRepo("evil/loader"); err != nil || repo.Description != "Free download, cracked" {
+		t.Fatalf("GetProcessedRepo() = %+v, %v, want the stored description", repo, err)
+	}
+	user, err := database.GetProcessedUser("farmer")
+	if err != nil || user.Login != "Farmer" {
+		t.Fatalf("GetProcessedUser() = %+v, %v, want the old row with its login", user, err)
+	}
+	if err := database.SetUserForkCount("Farmer", 23); err != nil {
+		t.Fatalf("SetUserForkCount() error = %v", err)
+	}
+	if user, err := database.GetProcessedUser("farmer"); err != nil || user.ForkCount != 23 {
+		t.Fatalf("GetProcessedUser() = %+v, %v, want the stored fork count", user, err)
+	}
+	flags, err := database.ListHeuristicFlags("repo", "evil/loader")
+	if err != nil || len(flags) != 1 || flags[0].HeuristicName != "LoaderHeuristic" || flags[0].Category != models.TaxonomyMalwareDistribution {
+		t.Fatalf("ListHeuristicFlags() = %+v, %v, want one named and categorized flag", flags, err)
+	}
+	if err := database.InsertHeuristicFlag("user", "farmer", "NewHeuristic", "Automated Activity:NewHeuristic", "rescanned"); err != nil {
+		t.Fatalf("InsertHeuristicFlag() after migration error = %v", err)
+	}
+	database.Close()
+
+	// Reopening applies nothing, and a database newer than this build is refused.
+	database, err = New(path)
+	if err != nil {
+		t.Fatalf("New() reopen error = %v", err)
+	}
+	var applied int
+	if err := database.QueryRow(`SELECT COUNT(*) FROM schema_version`).Scan(&applied); err != nil || applied != len(migrations) {
+		t.Fatalf("schema_version rows = %d, %v, want one per migration", applied, err)
+	}
+	if _, err := database.Exec(`INSERT INTO schema_version (version) VALUES (?)`, LatestSchemaVersion+1); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	database.Close()
+	if _, err := New(path); err == nil {
+		t.Fatal("New() opened a database with a newer schema version")
+	}
+}
+
+func TestHeuristicNameIsStoredSeparately(t *testing.T) {
+	database, err := New(MemoryPath)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer database.Close()
+
+	if err := database.InsertHeuristicFlag("user", "farmer", "NewHeuristic", "Automated Activity:NewHeuristic", "many empty repos"); err != nil {
+		t.Fatalf("InsertHeuristicFlag() error = %v", err)
+	}
+	if err := database.InsertHeuristicFlag("user", "farmer", "CustomForkHeuristic", "Custom:renamed in the flag text", ""); err != nil {
+		t.Fatalf("InsertHeuristicFlag() error = %v", err)
+	}
+
+	flags, err := database.ListHeuristicFlags("user", "farmer")
+	if err != nil {
+		t.Fatalf("ListHeuristicFlags() error = %v", err)
+	}
+	if len(flags) != 2 || flags[0].HeuristicName != "NewHeuristic" || flags[1].HeuristicName != "CustomForkHeuristic" {
+		t.Fatalf("ListHeuristicFlags() = %+v, want the stored heuristic names", flags)
+	}
+	if flags[0].Category != models.TaxonomyAutomation || flags[1].Category != models.TaxonomyUncategorized {
+		t.Fatalf("ListHeuristicFlags() = %+v, want automation and uncategorized flags", flags)
+	}
+	between, err := database.ListHeuristicFlagsBetween(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ListHeuristicFlagsBetween() error = %v", err)
+	}
+	if len(between) != 2 || between[1].HeuristicName != "CustomForkHeuristic" {
+		t.Fatalf("ListHeuristicFlagsBetween() = %+v, want the stored heuristic names", between)
+	}
+
+	entities, total, err := database.ListEntitiesByHeuristic("customforkheuristic", "", 0, 0)
+	if err != nil {
+		t.Fatalf("ListEntitiesByHeuristic() error = %v", err)
+	}
+	if total != 1 || entities[0].EntityID != "farmer" {
+		t.Fatalf("ListEntitiesByHeuristic() = %+v, want farmer matched by heuristic name", entities)
+	}
+}
+
+func TestListEntitiesByHeuristic(t *testing.T) {
+	database, err := New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer database.Close()
+
+	if err := database.InsertProcessedRepo("evil/loader", "evil", "loader", time.Now(), 10, 42, true); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	if err := database.InsertProcessedUser("farmer", time.Now(), 7, 20, 18, 0, true, ""); err != nil {
+		t.Fatalf("InsertProcessedUser() error = %v", err)
+	}
+	flags := []struct{ entityType, entityID, flag string }{
+		{"repo", "evil/loader", "Malware:SafeBrowsingHeuristic"},
+		{"repo", "other/repo", "Phishing:SafeBrowsingHeuristic"},
+		{"user", "farmer", "Spam Behavior:SafeBrowsingHeuristic"},
+		{"user", "farmer", "Mass Repository Creation:EmptyRepoHeuristic"},
+	}
+	for _, f := range flags {
+		if err := database.InsertHeuristicFlag(f.entityType, f.entityID, HeuristicName(f.flag), f.flag, "msg"); err != nil {
+			t.Fatalf("InsertHeuristicFlag() error = %v", err)
+		}
+	}
+	if err := database.SaveAbuseReport("repo", "evil/loader", "body"); err != nil {
+		t.Fatalf("SaveAbuseReport() error = %v", err)
+	}
+
+	entities, total, err := database.ListEntitiesByHeuristic("safebrowsingheuristic", "", 2, 0)
+	if err != nil {
+		t.Fatalf("ListEntitiesByHeuristic() error = %v", err)
+	}
+	if total != 3 || len(entities) != 2 {
+		t.Fatalf("ListEntitiesByHeuristic() = %d entities of %d, want 2 of 3", len(entities), total)
+	}
+	page, _, err := database.ListEntitiesByHeuristic("SafeBrowsingHeuristic", "", 2, 2)
+	if err != nil {
+		t.Fatalf("ListEntitiesByHeuristic() page 2 error = %v", err)
+	}
+	if len(page) != 1 {
+		t.Fatalf("ListEntitiesByHeuristic() page 2 = %+v, want 1 entity", page)
+	}
+
+	drafts, total, err := database.ListEntitiesByHeuristic("SafeBrowsingHeuristic", AbuseReportDraft, 0, 0)
+	if err != nil {
+		t.Fatalf("ListEntitiesByHeuristic(draft) error = %v", err)
+	}
+	if total != 1 || drafts[0].EntityID != "evil/loader" || drafts[0].Stars != 42 || !drafts[0].Malicious || drafts[0].ProcessedAt.IsZero() {
+		t.Fatalf("ListEntitiesByHeuristic(draft) = %+v, want evil/loader with repo details", drafts)
+	}
+
+	unreviewed, total, err := database.ListEntitiesByHeuristic("SafeBrowsingHeuristic", ReviewUnreviewed, 0, 0)
+	if err != nil {
+		t.Fatalf("ListEntitiesByHeuristic(unreviewed) error = %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("ListEntitiesByHeuristic(unreviewed) = %+v, want other/repo and farmer", unreviewed)
+	}
+	for _, entity := range unreviewed {
+		if entity.EntityID == "farmer" && (entity.Stars != 7 || !entity.Malicious) {
+			t.Fatalf("ListEntitiesByHeuristic() farmer = %+v, want user details", entity)
+		}
+	}
+}
+
+func TestGetStargazerOverlapCountsFlaggedRepos(t *testing.T) {
+	database, err := New(MemoryPath)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer database.Close()
+
+	repos := []struct {
+		id        string
+		malicious bool
+		flag      string
+		stars     []string
+	}{
+		{"evil/loader", true, "", []string{"sock", "bot", "fan"}},
+		{"farm/empty", false, "Automated Activity:StarFarmHeuristic", []string{"sock", "bot"}},
+		{"farm/other", false, "Other Suspicious Patterns:SparseProjectHeuristic", []string{"sock"}},
+		{"clean/lib", false, "", []string{"sock", "bot", "fan"}},
+	}
+	for _, repo := range repos {
+		owner, name, _ := strings.Cut(repo.id, "/")
+		if err := database.InsertProcessedRepo(repo.id, owner, name, time.Now(), 1, len(repo.stars), repo.malicious); err != nil {
+			t.Fatalf("InsertProcessedRepo() error = %v", err)
+		}
+		if repo.flag != "" {
+			if err := database.InsertHeuristicFlag("repo", repo.id, HeuristicName(repo.flag), repo.flag, "msg"); err != nil {
+				t.Fatalf("InsertHeuristicFlag() error = %v", err)
+			}
+		}
+		for _, login := range repo.stars {
+			if err := database.InsertStargazer(repo.id, login, time.Now()); err != nil {
+				t.Fatalf("InsertStargazer() error = %v", err)
+			}
+		}
+	}
+
+	overlap, err := database.GetStargazerOverlap(2)
+	if err != nil {
+		t.Fatalf("GetStargazerOverlap() error = %v", err)
+	}
+	want := []SharedStargazer{
+		{Username: "sock", Repos: []string{"evil/loader", "farm/empty", "farm/other"}},
+		{Username: "bot", Repos: []string{"evil/loader", "farm/empty"}},
+	}
+	if !reflect.DeepEqual(overlap, want) {
+		t.Fatalf("GetStargazerOverlap(2) = %+v, want %+v", overlap, want)
+	}
+	if overlap, err := database.GetStargazerOverlap(4); err != nil || len(overlap) != 0 {
+		t.Fatalf("GetStargazerOverlap(4) = %+v, %v, want no account on four flagged repos", overlap, err)
+	}
+	if shared, err := database.ListSharedStargazers(2); err != nil || len(shared) != 0 {
+		t.Fatalf("ListSharedStargazers(2) = %+v, %v, want only one malicious repo counted", shared, err)
+	}
+}
+
+func TestFindFlaggedUsersByAvatarHash(t *testing.T) {
+	database, err := New(MemoryPath)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer database.Close()
+
+	users := []struct {
+		username   string
+		suspicious bool
+		hash       string
+	}{
+		{"bot-b", true, "ff00ff00ff00ff03"},
+		{"bot-a", true, "ff00ff00ff00ff00"},
+		{"far", true, "00ff00ff00ff00ff"},
+		{"clean", false, "ff00ff00ff00ff00"},
+		{"unhashed", true, ""},
+	}
+	for _, user := range users {
+		if err := database.InsertProcessedUser(user.username, time.Now(), 0, 0, 0, 0, user.suspicious, ""); err != nil {
+			t.Fatalf("InsertProcessedUser() error = %v", err)
+		}
+		if user.hash == "" {
+			continue
+		}
+		if err := database.SetUserAvatarHash(user.username, user.hash); err != nil {
+			t.Fatalf("SetUserAvatarHash() error = %v", err)
+		}
+	}
+
+	got, err := database.FindFlaggedUsersByAvatarHash(0xff00ff00ff00ff01, 2)
+	if err != nil || strings.Join(got, ",") != "bot-a,bot-b" {
+		t.Fatalf("FindFlaggedUsersByAvatarHash() = %v, %v, want the two nearby suspicious users", got, err)
+	}
+}
+
+func TestReplaceRepoCheckerResults(t *testing.T) {
+	database, err := New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer database.Close()
+
+	first := []RepoCheckerResult{
+		{Checker: "ReadmeChecker", Flagged: true, Severity: "high", Evidence: "download link"},
+		{Checker: "LoaderChecker", Severity: "high"},
+	}
+	if err := database.ReplaceRepoCheckerResults("owner/repo", first); err != nil {
+		t.Fatalf("ReplaceRepoCheckerResults() error = %v", err)
+	}
+	rescan := []RepoCheckerResult{{Checker: "LoaderChecker", Flagged: true, Severity: "high", Evidence: "Tree contains loader.zip."}}
+	if err := database.ReplaceRepoCheckerResults("owner/repo", rescan); err != nil {
+		t.Fatalf("ReplaceRepoCheckerResults() rescan error = %v", err)
+	}
+
+	results, err := database.ListRepoCheckerResults("owner/repo")
+	if err != nil {
+		t.Fatalf("ListRepoCheckerResults() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Checker != "LoaderChecker" || !results[0].Flagged || results[0].Evidence != "Tree contains loader.zip." || results[0].CheckedAt.IsZero() {
+		t.Fatalf("ListRepoCheckerResults() = %+v, want only the rescan result", results)
+	}
+}
+
+func TestRenameRepoKeepsFlagsAttached(t *testing.T) {
+	database, err := New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer database.Close()
+
+	if err := database.InsertProcessedRepo("old/loader", "old", "loader", time.Now(), 10, 5, true); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	if err := database.InsertHeuristicFlag("repo", "old/loader", "LoaderHeuristic", "Malware:LoaderHeuristic", "loader.zip"); err != nil {
+		t.Fatalf("InsertHeuristicFlag() error = %v", err)
+	}
+	if err := database.ReplaceRepoCheckerResults("old/loader", []RepoCheckerResult{{Checker: "LoaderChecker", Flagged: true, Severity: "high"}}); err != nil {
+		t.Fatalf("ReplaceRepoCheckerResults() error = %v", err)
+	}
+
+	if err := database.RenameRepo("old/loader", "new", "loader"); err != nil {
+		t.Fatalf("RenameRepo() error = %v", err)
+	}
+	repo, err := database.GetProcessedRepo("new/loader")
+	if err != nil || !repo.IsMalicious || repo.Owner != "new" {
+		t.Fatalf("GetProcessedRepo(new/loader) = %+v, %v, want the moved malicious row", repo, err)
+	}
+	if _, err := database.GetProcessedRepo("old/loader"); err == nil {
+		t.Fatal("GetProcessedRepo(old/loader) found the stale row")
+	}
+	flags, err := database.ListHeuristicFlags("repo", "new/loader")
+	if err != nil || len(flags) != 1 {
+		t.Fatalf("ListHeuristicFlags(new/loader) = %+v, %v, want the moved flag", flags, err)
+	}
+	results, err := database.ListRepoCheckerResults("new/loader")
+	if err != nil || len(results) != 1 {
+		t.Fatalf("ListRepoCheckerResults(new/loader) = %+v, %v, want the moved result", results, err)
+	}
+	renames, err := database.ListRepoRenames()
+	if err != nil || len(renames) != 1 || renames[0].OldID != "old/loader" || renames[0].NewID != "new/loader" {
+		t.Fatalf("ListRepoRenames() = %+v, %v", renames, err)
+	}
+
+	if err := database.SetRepoStatus("new/loader", "new", "loader", "deleted_on_github"); err != nil {
+		t.Fatalf("SetRepoStatus() error = %v", err)
+	}
+	repo, err = database.GetProcessedRepo("new/loader")
+	if err != nil || repo.Status != "deleted_on_github" || repo.StatusUpdatedAt == nil || !repo.IsMalicious {
+		t.Fatalf("GetProcessedRepo() after delete = %+v, %v, want status with timestamp", repo, err)
+	}
+}
+
+func TestNewInMemoryDatabase(t *testing.T) {
+	database, err := New(MemoryPath)
+	if err != nil {
+		t.Fatalf("New(:memory:) error = %v", err)
+	}
+	defer database.Close()
+
+	if err := database.InsertProcessedRepo("owner/repo", "owner", "repo", time.Now(), 10, 5, true); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	if err := database.InsertHeuristicFlag("repo", "owner/repo", "LoaderHeuristic", "Malware:LoaderHeuristic", "loader.zip"); err != nil {
+		t.Fatalf("InsertHeuristicFlag() error = %v", err)
+	}
+	if err := database.ReplaceRepoCheckerResults("owner/repo", []RepoCheckerResult{{Checker: "LoaderChecker", Flagged: true, Severity: "high"}}); err != nil {
+		t.Fatalf("ReplaceRepoCheckerResults() error = %v", err)
+	}
+	if repo, err := database.GetProcessedRepo("owner/repo"); err != nil || !repo.IsMalicious {
+		t.Fatalf("GetProcessedRepo() = %+v, %v, want the malicious repo", repo, err)
+	}
+	if flags, err := database.ListHeuristicFlags("repo", "owner/repo"); err != nil || len(flags) != 1 {
+		t.Fatalf("ListHeuristicFlags() = %+v, %v, want one flag", flags, err)
+	}
+	if repos, err := database.ListFlaggedRepos(""); err != nil || len(repos) != 1 {
+		t.Fatalf("ListFlaggedRepos() = %+v, %v, want one repo", repos, err)
+	}
+	var mode string
+	if err := database.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil || mode != "memory" {
+		t.Fatalf("journal_mode = %q, %v, want memory", mode, err)
+	}
+
+	other, err := New(MemoryPath)
+	if err != nil {
+		t.Fatalf("New(:memory:) second error = %v", err)
+	}
+	defer other.Close()
+	if _, err := other.GetProcessedRepo("owner/repo"); err == nil {
+		t.Fatal("second in-memory database shares rows with the first")
+	}
+}
+
+func TestNewFileDatabaseUsesWAL(t *testing.T) {
+	database, err := New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer database.Close()
+
+	var mode string
+	if err := database.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil || mode != "wal" {
+		t.Fatalf("journal_mode = %q, %v, want wal", mode, err)
+	}
+}
@@ -0,0 +1,50 @@
+-- A github_watchdog.db from before schema versioning, with the columns of the first release.
+CREATE TABLE processed_repositories (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	repo_id TEXT UNIQUE,
+	owner TEXT,
+	name TEXT,
+	updated_at TIMESTAMP,
+	disk_usage INTEGER,
+	stargazer_count INTEGER,
+	is_malicious BOOLEAN,
+	processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE processed_users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	username TEXT UNIQUE,
+	created_at TIMESTAMP,
+	total_stars INTEGER,
+	empty_count INTEGER,
+	suspicious_empty_count INTEGER,
+	contributions INTEGER,
+	analysis_result BOOLEAN,
+	processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE heuristic_flags (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	entity_type TEXT,
+	entity_id TEXT,
+	flag TEXT,
+	triggered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE search_checkpoints (
+	name TEXT PRIMARY KEY,
+	profile_name TEXT,
+	base_query TEXT,
+	effective_query TEXT,
+	since TEXT,
+	updated_before TEXT,
+	next_updated_before TEXT,
+	oldest_updated_at TIMESTAMP,
+	completed_at TIMESTAMP
+);
+
+INSERT INTO processed_repositories (repo_id, owner, name, updated_at, disk_usage, stargazer_count, is_malicious)
+	VALUES ('Evil/Loader', 'Evil', 'Loader', '2025-06-01 00:00:00', 12, 40, 1);
+INSERT INTO processed_users (username, created_at, total_stars, empty_count, suspicious_empty_count, contributions, analysis_result)
+	VALUES ('Farmer', '2025-05-01 00:00:00', 40, 25, 6, 0, 1);
+INSERT INTO heuristic_flags (entity_type, entity_id, flag, triggered_at) VALUES
+	('repo', 'Evil/Loader', 'Malware:LoaderHeuristic', '2025-06-01 00:00:00'),
+	('repo', 'Evil/Loader', 'Malware:LoaderHeuristic', '2025-06-02 00:00:00'),
+	('user', 'Farmer', 'Automated Activity:NewHeuristic', '2025-06-01 00:00:00');
@@ -0,0 +1,35 @@
+package github
+
+import (
+	"context"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+// GitHubAPI is the set of GitHub operations the analyzer and scan service depend on. *Client is
+// the production implementation; tests substitute fakes that need no network access.
+type GitHubAPI interface {
+	GetLogger() *logger.Logger
+	SearchRepositories(ctx context.Context, query string, page, perPage int) (*models.SearchResult, error)
+	GetRepository(ctx context.Context, owner, repo string) (models.RepoItem, error)
+	GetUserInfo(ctx context.Context, username string) (models.UserInfo, error)
+	GetAvatar(ctx context.Context, avatarURL string) ([]byte, error)
+	GetUserRepositories(ctx context.Context, username string) ([]models.RepoMetrics, error)
+	GetUserContributions(ctx context.Context, username string) (int, error)
+	GetRepoReadme(ctx context.Context, owner, repo string) (string, error)
+	GetRepoReadmes(ctx context.Context, owner string, repos []string) (map[string]string, error)
+	GetRepoTree(ctx context.Context, owner, repo, branch string) ([]string, error)
+	GetRepoLanguages(ctx context.Context, owner, repo string) (map[string]int, error)
+	GetRepoTopics(ctx context.Context, owner, repo string) ([]string, error)
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
+	GetReleaseAssets(ctx context.Context, owner, repo string) ([]models.ReleaseAsset, error)
+	DownloadReleaseAsset(ctx context.Context, owner, repo string, assetID, maxBytes int64) (string, int64, error)
+	ListCommits(ctx context.Context, owner, repo, branch string, limit int) ([]string, error)
+	GetRepoCommits(ctx context.Context, owner, repo, branch string, limit int) ([]models.Commit, error)
+	GetCommitFiles(ctx context.Context, owner, repo, sha string) ([]models.CommitFile, error)
+	GetStargazers(ctx context.Context, owner, repo string, limit int) ([]models.Stargazer, error)
+	GetUserStarred(ctx context.Context, username string, limit int) (int, error)
+}
+
+var _ GitHubAPI = (*Client)(nil)
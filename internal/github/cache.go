@@ -1,45 +1,183 @@
 package github
 
 import (
+	"sort"
 	"sync"
 	"time"
 )
 
-// APICache holds cached API responses
+const (
+	// DefaultCacheMaxAge is how long a response stays cached after it was stored or
+	// revalidated. Entries older than the client's TTL are only kept for ETag revalidation.
+	DefaultCacheMaxAge = 24 * time.Hour
+	// DefaultCacheJanitorInterval is how often the janitor purges entries older than MaxAge.
+	DefaultCacheJanitorInterval = 5 * time.Minute
+)
+
+// CacheOptions bounds an APICache.
+type CacheOptions struct {
+	// MaxAge overrides DefaultCacheMaxAge when positive.
+	MaxAge time.Duration
+	// MaxEntries, when positive, caps the number of cached responses. Storing past it evicts the
+	// least recently used tenth of the entries.
+	MaxEntries int
+	// JanitorInterval overrides DefaultCacheJanitorInterval when positive.
+	JanitorInterval time.Duration
+}
+
+// APICache holds cached API responses. A janitor goroutine purges entries older than the
+// maximum age until Close is called.
 type APICache struct {
-	data sync.Map // map[string]cacheEntry
+	mu         sync.Mutex
+	entries    map[string]*cacheEntry
+	maxAge     time.Duration
+	maxEntries int
+	stop       chan struct{}
+	stopOnce   sync.Once
 }
 
 type cacheEntry struct {
 	data      []byte
+	etag      string
 	timestamp time.Time
+	// lastUsed orders entries for eviction when the cache is full.
+	lastUsed time.Time
 }
 
-// NewAPICache creates a new API cache
+// NewAPICache creates a new API cache with the default bounds.
 func NewAPICache() *APICache {
-	return &APICache{}
+	return NewAPICacheWithOptions(CacheOptions{})
+}
+
+// NewAPICacheWithOptions creates a new API cache bounded by opts and starts its janitor.
+func NewAPICacheWithOptions(opts CacheOptions) *APICache {
+	c := &APICache{
+		entries:    map[string]*cacheEntry{},
+		maxAge:     opts.MaxAge,
+		maxEntries: max(opts.MaxEntries, 0),
+		stop:       make(chan struct{}),
+	}
+	if c.maxAge <= 0 {
+		c.maxAge = DefaultCacheMaxAge
+	}
+	interval := opts.JanitorInterval
+	if interval <= 0 {
+		interval = DefaultCacheJanitorInterval
+	}
+	go c.janitor(interval)
+	return c
+}
+
+// janitor purges expired entries every interval until the cache is closed.
+func (c *APICache) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.Purge()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// Close stops the janitor. The cache stays usable, but expired entries are no longer purged.
+func (c *APICache) Close() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// Purge deletes the entries older than the maximum age and returns how many it deleted.
+func (c *APICache) Purge() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	purged := 0
+	for key, entry := range c.entries {
+		if time.Since(entry.timestamp) >= c.maxAge {
+			delete(c.entries, key)
+			purged++
+		}
+	}
+	return purged
+}
+
+// Len returns the number of cached responses, including expired ones not yet purged.
+func (c *APICache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
 }
 
 // Get retrieves a cached response if it exists and is not expired
 func (c *APICache) Get(key string, ttl time.Duration) ([]byte, bool) {
-	if val, ok := c.data.Load(key); ok {
-		entry := val.(cacheEntry)
-		if time.Since(entry.timestamp) < ttl {
-			return entry.data, true
-		}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok && time.Since(entry.timestamp) < ttl {
+		entry.lastUsed = time.Now()
+		return entry.data, true
 	}
 	return nil, false
 }
 
+// Stale retrieves a cached response regardless of age, with the ETag it was served with, so an
+// expired entry can be revalidated.
+func (c *APICache) Stale(key string) ([]byte, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok {
+		entry.lastUsed = time.Now()
+		return entry.data, entry.etag, true
+	}
+	return nil, "", false
+}
+
 // Set stores a response in the cache
 func (c *APICache) Set(key string, data []byte) {
-	c.data.Store(key, cacheEntry{
+	c.SetWithETag(key, data, "")
+}
+
+// SetWithETag stores a response in the cache along with its ETag validator.
+func (c *APICache) SetWithETag(key string, data []byte, etag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	c.entries[key] = &cacheEntry{
 		data:      data,
-		timestamp: time.Now(),
+		etag:      etag,
+		timestamp: now,
+		lastUsed:  now,
+	}
+	if c.maxEntries > 0 && len(c.entries) > c.maxEntries {
+		c.evictLocked()
+	}
+}
+
+// evictLocked drops the least recently used entries until a tenth of MaxEntries is free, so a
+// full cache is not scanned on every store. The caller holds c.mu.
+func (c *APICache) evictLocked() {
+	keep := max(c.maxEntries-max(c.maxEntries/10, 1), 1)
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].lastUsed.After(c.entries[keys[j]].lastUsed)
 	})
+	for _, key := range keys[keep:] {
+		delete(c.entries, key)
+	}
+}
+
+// Delete drops a cached response.
+func (c *APICache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
 }
 
 // Clear empties the cache
 func (c *APICache) Clear() {
-	c.data = sync.Map{}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]*cacheEntry{}
 }
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAPICacheJanitorPurgesExpiredEntries(t *testing.T) {
+	cache := NewAPICacheWithOptions(CacheOptions{MaxAge: 50 * time.Millisecond, JanitorInterval: 10 * time.Millisecond})
+	t.Cleanup(cache.Close)
+	cache.Set("old", []byte("old"))
+	time.Sleep(60 * time.Millisecond)
+	cache.Set("fresh", []byte("fresh"))
+
+	deadline := time.Now().Add(time.Second)
+	for cache.Len() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Len() = %d after the janitor ran, want only the fresh entry", cache.Len())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, _, found := cache.Stale("old"); found {
+		t.Fatal("expired entry survived the janitor")
+	}
+	if data, found := cache.Get("fresh", time.Minute); !found || string(data) != "fresh" {
+		t.Fatalf("Get(fresh) = %q, %v, want the fresh entry", data, found)
+	}
+}
+
+func TestAPICachePurgeKeepsEntriesForRevalidation(t *testing.T) {
+	cache := NewAPICacheWithOptions(CacheOptions{MaxAge: time.Hour})
+	t.Cleanup(cache.Close)
+	cache.SetWithETag("repo", []byte("body"), `"v1"`)
+
+	if purged := cache.Purge(); purged != 0 {
+		t.Fatalf("Purge() = %d, want entries younger than MaxAge kept", purged)
+	}
+	if _, found := cache.Get("repo", 0); found {
+		t.Fatal("Get() served an entry past its TTL")
+	}
+	if _, etag, found := cache.Stale("repo"); !found || etag != `"v1"` {
+		t.Fatalf("Stale() = %q, %v, want the entry kept for revalidation", etag, found)
+	}
+}
+
+func TestAPICacheRespectsMaxEntries(t *testing.T) {
+	cache := NewAPICacheWithOptions(CacheOptions{MaxEntries: 10})
+	t.Cleanup(cache.Close)
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("key-%d", i), []byte("x"))
+		time.Sleep(time.Millisecond)
+	}
+	// Reading key-0 makes key-1 the least recently used.
+	if _, found := cache.Get("key-0", time.Minute); !found {
+		t.Fatal("Get(key-0) missed before the cache was full")
+	}
+
+	cache.Set("key-10", []byte("x"))
+	if cache.Len() > 10 {
+		t.Fatalf("Len() = %d, want at most 10", cache.Len())
+	}
+	for key, want := range map[string]bool{"key-0": true, "key-1": false, "key-10": true} {
+		if _, _, found := cache.Stale(key); found != want {
+			t.Fatalf("Stale(%s) found = %v, want %v", key, found, want)
+		}
+	}
+	for i := 11; i < 100; i++ {
+		cache.Set(fmt.Sprintf("key-%d", i), []byte("x"))
+	}
+	if cache.Len() > 10 {
+		t.Fatalf("Len() = %d after many stores, want at most 10", cache.Len())
+	}
+}
+
+func TestClientCloseStopsCacheJanitor(t *testing.T) {
+	cache := NewAPICacheWithOptions(CacheOptions{MaxAge: 10 * time.Millisecond, JanitorInterval: 5 * time.Millisecond})
+	client := NewClient(testToken, 0, 60, cache, nil)
+	client.Close()
+	client.Close()
+
+	cache.Set("old", []byte("old"))
+	time.Sleep(40 * time.Millisecond)
+	if cache.Len() != 1 {
+		t.Fatalf("Len() = %d after Close, want the janitor stopped", cache.Len())
+	}
+}
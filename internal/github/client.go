@@ -3,12 +3,16 @@ package github
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,38 +20,312 @@ import (
 	"github.com/arkouda/github/GitHubWatchdog/internal/models"
 )
 
+// DefaultBaseURL is the public GitHub REST API root.
+const DefaultBaseURL = "https://api.github.com"
+
+const (
+	// maxRateLimitRetries bounds how often getWithBackoff repeats a rate-limited request.
+	maxRateLimitRetries = 4
+	// defaultRetryBaseDelay is the first backoff delay when GitHub gives no retry time.
+	defaultRetryBaseDelay = time.Second
+)
+
+// ErrPartialResults is wrapped by errors from paginated calls that gave up after some pages,
+// which return the results gathered so far alongside the error.
+var ErrPartialResults = errors.New("partial results")
+
+// ErrAssetTooLarge is wrapped by DownloadReleaseAsset errors for assets over the size limit.
+var ErrAssetTooLarge = errors.New("release asset too large")
+
 // Client handles GitHub API requests with rate limiting and caching
 type Client struct {
 	httpClient  *http.Client
+	baseURL     string
 	token       string
-	apiCache    *APICache
+	apiCache    ResponseCache
 	rateLimiter *RateLimiter
 	cacheTTL    time.Duration
+	failFast    bool
 	logger      *logger.Logger
+	cacheLog    *logger.Logger
+	// retryBaseDelay is the first getWithBackoff delay, doubled on each retry.
+	retryBaseDelay time.Duration
+	// contributionSource is ContributionSourceEvents or ContributionSourceGraphQL; empty counts
+	// events.
+	contributionSource string
 }
 
-// NewClient creates a new GitHub client.
-func NewClient(token string, bufferSize int, cacheTTLMinutes int, appLogger *logger.Logger) *Client {
+// NewClient creates a new GitHub client that caches responses in cache, or in a new in-memory
+// APICache when cache is nil.
+func NewClient(token string, bufferSize int, cacheTTLMinutes int, cache ResponseCache, appLogger *logger.Logger) *Client {
 	cacheTTL := time.Duration(cacheTTLMinutes) * time.Minute
 	if appLogger == nil {
 		appLogger = logger.New(false)
 	}
+	if cache == nil {
+		cache = NewAPICache()
+	}
 
 	return &Client{
 		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		baseURL:     DefaultBaseURL,
 		token:       token,
-		apiCache:    NewAPICache(),
-		rateLimiter: NewRateLimiter(bufferSize, appLogger),
+		apiCache:    cache,
+		rateLimiter: NewRateLimiter(bufferSize, appLogger.For("ratelimit")),
 		cacheTTL:    cacheTTL,
-		logger:      appLogger,
+		logger:      appLogger.For("github"),
+		cacheLog:    appLogger.For("cache"),
+
+		retryBaseDelay: defaultRetryBaseDelay,
 	}
 }
 
+// Close closes the client's response cache, stopping its janitor. Cached responses are kept, so a
+// disk cache serves them to the next process.
+func (c *Client) Close() {
+	c.apiCache.Close()
+}
+
+// SetBaseURL points the client at another API root, such as GitHub Enterprise Server or a test server.
+func (c *Client) SetBaseURL(baseURL string) {
+	c.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// SetHTTPClient replaces the HTTP client, for example to record responses in tests.
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// SetOnRateLimit selects OnRateLimitWait (the default) or OnRateLimitFail. In fail mode every
+// method returns a *RateLimitError instead of blocking until the limit resets.
+func (c *Client) SetOnRateLimit(mode string) {
+	c.failFast = mode == OnRateLimitFail
+	c.rateLimiter.SetFailFast(c.failFast)
+}
+
 // GetLogger returns the client's logger
 func (c *Client) GetLogger() *logger.Logger {
 	return c.logger
 }
 
+// APIError is a non-success response from the GitHub API.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Body       string
+	// RetryAfter is the Retry-After header, when GitHub sent one.
+	RetryAfter time.Duration
+	// Reset is the X-RateLimit-Reset header of a response with no requests remaining.
+	Reset time.Time
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s - %s", e.Status, e.Body)
+}
+
+// IsNotFound reports whether err is a 404 from the GitHub API.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Repository statuses recorded when GitHub refuses to serve a repository's content.
+const (
+	RepoStatusBlockedDMCA = "blocked_dmca"
+	RepoStatusDisabled    = "disabled"
+	// RepoStatusDeleted is recorded when a previously scanned repository returns 404.
+	RepoStatusDeleted = "deleted_on_github"
+)
+
+// RepoMovedError reports that a repository-scoped request was redirected because the
+// repository was renamed or transferred. GetRepository follows the redirect and returns the
+// repository under its new name.
+type RepoMovedError struct {
+	Repo     string
+	Location string
+}
+
+func (e *RepoMovedError) Error() string {
+	return fmt.Sprintf("repository %s has moved to %s", e.Repo, e.Location)
+}
+
+// IsRepoMoved reports whether err is a *RepoMovedError.
+func IsRepoMoved(err error) bool {
+	var moved *RepoMovedError
+	return errors.As(err, &moved)
+}
+
+// movedRepo returns the owner/name a /repos/ request was made for when the response came from
+// a different URL after following redirects.
+func movedRepo(requested, final *url.URL) (string, bool) {
+	if final == nil || final.Path == requested.Path {
+		return "", false
+	}
+	_, rest, found := strings.Cut(requested.Path, "/repos/")
+	if !found {
+		return "", false
+	}
+	parts := strings.SplitN(rest, "/", 3)
+	if len(parts) < 2 {
+		return "", false
+	}
+	return parts[0] + "/" + parts[1], true
+}
+
+// RepoUnavailableError reports a repository GitHub blocked for legal reasons (451) or disabled (403).
+type RepoUnavailableError struct {
+	Status string
+	Err    *APIError
+}
+
+func (e *RepoUnavailableError) Error() string {
+	return fmt.Sprintf("repository %s: %s", strings.ReplaceAll(e.Status, "_", " "), e.Err.Error())
+}
+
+func (e *RepoUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// RepoUnavailableStatus returns RepoStatusBlockedDMCA or RepoStatusDisabled when err reports an
+// unavailable repository.
+func RepoUnavailableStatus(err error) (string, bool) {
+	var unavailable *RepoUnavailableError
+	if errors.As(err, &unavailable) {
+		return unavailable.Status, true
+	}
+	return "", false
+}
+
+// classifyAPIError wraps responses for blocked and disabled repositories in a *RepoUnavailableError.
+func classifyAPIError(apiErr *APIError) error {
+	switch {
+	case apiErr.StatusCode == http.StatusUnavailableForLegalReasons:
+		return &RepoUnavailableError{Status: RepoStatusBlockedDMCA, Err: apiErr}
+	case apiErr.StatusCode == http.StatusForbidden && isDisabledMessage(apiErr.Body):
+		return &RepoUnavailableError{Status: RepoStatusDisabled, Err: apiErr}
+	default:
+		return apiErr
+	}
+}
+
+func isDisabledMessage(body string) bool {
+	lower := strings.ToLower(body)
+	return strings.Contains(lower, "repository access blocked") || strings.Contains(lower, "has been disabled")
+}
+
+// get fetches reqURL, serving fresh responses from the cache under cacheKey. Expired entries
+// that carry an ETag are revalidated with If-None-Match, and a 304 reuses the cached body
+// without counting against the rate limit. Any other non-200 response is an *APIError, wrapped
+// in a *RepoUnavailableError for blocked or disabled repositories. A repository-scoped request
+// that was redirected returns a *RepoMovedError so content is never recorded under a stale name.
+func (c *Client) get(ctx context.Context, reqURL, accept, cacheKey string) ([]byte, error) {
+	return c.fetch(ctx, reqURL, accept, cacheKey, false)
+}
+
+// fetch implements get. followMoves accepts redirected repository responses.
+func (c *Client) fetch(ctx context.Context, reqURL, accept, cacheKey string, followMoves bool) ([]byte, error) {
+	if cachedData, found := c.apiCache.Get(cacheKey, c.cacheTTL); found {
+		c.cacheLog.Debug("Cache hit for %s", cacheKey)
+		return cachedData, nil
+	}
+	c.cacheLog.Debug("Cache miss for %s, fetching from API", cacheKey)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "token "+c.token)
+	req.Header.Set("Accept", accept)
+	staleData, etag, stale := c.apiCache.Stale(cacheKey)
+	if stale && etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	c.rateLimiter.UpdateFromResponse(resp)
+
+	if resp.StatusCode == http.StatusNotModified && stale {
+		c.cacheLog.Debug("Not modified: %s", cacheKey)
+		c.apiCache.SetWithETag(cacheKey, staleData, etag)
+		return staleData, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		apiErr := &APIError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(bodyBytes)}
+		if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+			if seconds, err := strconv.Atoi(retryAfter); err == nil {
+				apiErr.RetryAfter = time.Duration(seconds) * time.Second
+			}
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				apiErr.Reset = time.Unix(reset, 0)
+			}
+		}
+		return nil, classifyAPIError(apiErr)
+	}
+	if repo, moved := movedRepo(req.URL, resp.Request.URL); moved && !followMoves {
+		return nil, &RepoMovedError{Repo: repo, Location: resp.Request.URL.String()}
+	}
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	c.apiCache.SetWithETag(cacheKey, responseBody, resp.Header.Get("ETag"))
+	c.cacheLog.Debug("Cached response for '%s' (%d bytes)", cacheKey, len(responseBody))
+	return responseBody, nil
+}
+
+// getWithBackoff is get for paginated calls. A rate-limited response is retried after the
+// Retry-After or X-RateLimit-Reset time GitHub sent, or after an exponentially growing delay
+// when it sent neither. After maxRateLimitRetries retries, or at once in fail mode, it returns
+// a *RateLimitError for resource.
+func (c *Client) getWithBackoff(ctx context.Context, reqURL, accept, cacheKey, resource string) ([]byte, error) {
+	delay := c.retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		responseBody, err := c.get(ctx, reqURL, accept, cacheKey)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !isRateLimited(apiErr) {
+			return responseBody, err
+		}
+		if c.failFast || attempt == maxRateLimitRetries {
+			return nil, &RateLimitError{Resource: resource, Reset: apiErr.Reset, RetryAfter: apiErr.RetryAfter}
+		}
+
+		wait := delay
+		switch {
+		case apiErr.RetryAfter > 0:
+			wait = apiErr.RetryAfter
+		case time.Until(apiErr.Reset) > 0:
+			wait = time.Until(apiErr.Reset)
+		}
+		c.logger.Info("Rate limited on %s. Retrying in %s.", cacheKey, wait)
+		if err := sleepWithContext(ctx, wait); err != nil {
+			return nil, err
+		}
+		delay *= 2
+	}
+}
+
+// isRateLimited reports whether apiErr is GitHub refusing a request for its primary or
+// secondary rate limit rather than for permissions.
+func isRateLimited(apiErr *APIError) bool {
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return apiErr.RetryAfter > 0 || !apiErr.Reset.IsZero() || strings.Contains(strings.ToLower(apiErr.Body), "rate limit")
+	default:
+		return false
+	}
+}
+
 // SearchRepositories searches for repositories using the GitHub search API
 func (c *Client) SearchRepositories(ctx context.Context, query string, page, perPage int) (*models.SearchResult, error) {
 	// First check if context is already canceled
@@ -60,69 +338,28 @@ func (c *Client) SearchRepositories(ctx context.Context, query string, page, per
 		return nil, err
 	}
 
-	reqURL := fmt.Sprintf("https://api.github.com/search/repositories?q=%s&page=%d&per_page=%d", url.QueryEscape(query), page, perPage)
+	reqURL := fmt.Sprintf("%s/search/repositories?q=%s&page=%d&per_page=%d", c.baseURL, url.QueryEscape(query), page, perPage)
 	cacheKey := fmt.Sprintf("search:%s:%d:%d", query, page, perPage)
 
-	var responseBody []byte
+	responseBody, err := c.get(ctx, reqURL, "application/vnd.github.v3+json", cacheKey)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden {
+		c.logger.Error("Rate limit exceeded: %s - %s", apiErr.Status, apiErr.Body)
 
-	// Try to get from cache first
-	if cachedData, found := c.apiCache.Get(cacheKey, c.cacheTTL); found {
-		c.logger.Debug("Cache hit for query '%s' page %d", query, page)
-		responseBody = cachedData
-	} else {
-		c.logger.Debug("Cache miss for query '%s' page %d, fetching from API", query, page)
-
-		// Create request with context to respect timeouts
-		req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
-		if err != nil {
-			return nil, err
+		// Handle rate limiting
+		if apiErr.RetryAfter <= 0 || c.failFast {
+			// Without a Retry-After, avoid a retry loop that could hang
+			c.logger.Info("Rate limited. Returning error.")
+			return nil, &RateLimitError{Resource: "search", RetryAfter: apiErr.RetryAfter}
 		}
-
-		req.Header.Set("Authorization", "token "+c.token)
-		req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-		// Perform request with timeout from context
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
+		c.logger.Info("Rate limited. Waiting %v seconds.", apiErr.RetryAfter)
+		if err := sleepWithContext(ctx, apiErr.RetryAfter); err != nil {
 			return nil, err
 		}
-		defer resp.Body.Close()
-
-		// Update rate limits
-		c.rateLimiter.UpdateFromResponse(resp)
-
-		if resp.StatusCode != http.StatusOK {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			if resp.StatusCode == http.StatusForbidden {
-				c.logger.Error("Rate limit exceeded: %s - %s", resp.Status, string(bodyBytes))
-
-				// Handle rate limiting
-				if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
-					if d, err := time.ParseDuration(retryAfter + "s"); err == nil {
-						c.logger.Info("Rate limited. Waiting %v seconds.", d)
-						if err := sleepWithContext(ctx, d); err != nil {
-							return nil, err
-						}
-						return c.SearchRepositories(ctx, query, page, perPage)
-					}
-				} else {
-					// If no Retry-After, avoid recursion that could cause a hang
-					c.logger.Info("Rate limited with no retry header. Returning error.")
-					return nil, fmt.Errorf("search rate limit exceeded, please retry later")
-				}
-			}
-			return nil, fmt.Errorf("search failed: %s - %s", resp.Status, string(bodyBytes))
-		}
-
-		// Read the response body
-		responseBody, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("reading response body: %w", err)
-		}
-
-		// Cache the response
-		c.apiCache.Set(cacheKey, responseBody)
-		c.logger.Debug("Cached response for '%s' (%d bytes)", cacheKey, len(responseBody))
+		return c.SearchRepositories(ctx, query, page, perPage)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
 	// Parse the response
@@ -130,147 +367,167 @@ func (c *Client) SearchRepositories(ctx context.Context, query string, page, per
 	if err := json.Unmarshal(responseBody, &result); err != nil {
 		return nil, fmt.Errorf("decoding search results: %w", err)
 	}
+	if result.IncompleteResults {
+		// Keep the partial page out of the cache so asking again reaches GitHub.
+		c.apiCache.Delete(cacheKey)
+		c.logger.Warn("Page %d of %q timed out on GitHub; results are incomplete", page, query)
+	}
 
 	c.logger.Info("Page %d: Found %d repositories", page, len(result.Items))
 	return &result, nil
 }
 
-// GetUserInfo fetches user info from GitHub
-func (c *Client) GetUserInfo(ctx context.Context, username string) (time.Time, error) {
+// GetRepository fetches a repository's metadata. Blocked or disabled repositories return a
+// *RepoUnavailableError. Renamed repositories are followed, so the returned item carries the
+// current owner and name.
+func (c *Client) GetRepository(ctx context.Context, owner, repo string) (models.RepoItem, error) {
 	if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
-		return time.Time{}, err
+		return models.RepoItem{}, err
 	}
 
-	url := fmt.Sprintf("https://api.github.com/users/%s", username)
-	cacheKey := fmt.Sprintf("user:%s", username)
-
-	var responseBody []byte
-
-	// Try from cache first
-	if cachedData, found := c.apiCache.Get(cacheKey, c.cacheTTL); found {
-		c.logger.Debug("Cache hit for user '%s'", username)
-		responseBody = cachedData
-	} else {
-		c.logger.Debug("Cache miss for user '%s', fetching from API", username)
-
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return time.Time{}, err
-		}
-
-		req.Header.Set("Authorization", "token "+c.token)
-		req.Header.Set("Accept", "application/vnd.github.v3+json")
+	reqURL := fmt.Sprintf("%s/repos/%s/%s", c.baseURL, owner, repo)
+	cacheKey := fmt.Sprintf("repo:%s:%s", owner, repo)
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return time.Time{}, err
-		}
-		defer resp.Body.Close()
+	responseBody, err := c.fetch(ctx, reqURL, "application/vnd.github.v3+json", cacheKey, true)
+	if err != nil {
+		return models.RepoItem{}, fmt.Errorf("failed to fetch repository: %w", err)
+	}
 
-		// Update rate limits
-		c.rateLimiter.UpdateFromResponse(resp)
+	var item models.RepoItem
+	if err := json.Unmarshal(responseBody, &item); err != nil {
+		return models.RepoItem{}, fmt.Errorf("decoding repository: %w", err)
+	}
+	return item, nil
+}
 
-		if resp.StatusCode != http.StatusOK {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			return time.Time{}, fmt.Errorf("failed to fetch user info: %s - %s", resp.Status, string(bodyBytes))
-		}
+// GetUserInfo fetches a user's profile from GitHub
+func (c *Client) GetUserInfo(ctx context.Context, username string) (models.UserInfo, error) {
+	if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
+		return models.UserInfo{}, err
+	}
 
-		// Read response body
-		responseBody, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return time.Time{}, fmt.Errorf("reading response body: %w", err)
-		}
+	url := fmt.Sprintf("%s/users/%s", c.baseURL, username)
+	cacheKey := fmt.Sprintf("user:%s", username)
 
-		// Cache the response
-		c.apiCache.Set(cacheKey, responseBody)
-		c.logger.Debug("Cached user info for '%s'", username)
+	responseBody, err := c.get(ctx, url, "application/vnd.github.v3+json", cacheKey)
+	if err != nil {
+		return models.UserInfo{}, fmt.Errorf("failed to fetch user info: %w", err)
 	}
 
 	// Parse the user data
 	var userInfo struct {
+		ID        int64  `json:"id"`
+		NodeID    string `json:"node_id"`
 		CreatedAt string `json:"created_at"`
+		Followers int    `json:"followers"`
+		Following int    `json:"following"`
+		Type      string `json:"type"`
+		// The profile fields are null when unset.
+		Bio             *string `json:"bio"`
+		Blog            *string `json:"blog"`
+		TwitterUsername *string `json:"twitter_username"`
+		AvatarURL       string  `json:"avatar_url"`
 	}
 
 	if err := json.Unmarshal(responseBody, &userInfo); err != nil {
-		return time.Time{}, fmt.Errorf("decoding user info: %w", err)
+		return models.UserInfo{}, fmt.Errorf("decoding user info: %w", err)
 	}
 
 	createdAt, err := time.Parse(time.RFC3339, userInfo.CreatedAt)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("parsing user creation date: %w", err)
+		return models.UserInfo{}, fmt.Errorf("parsing user creation date: %w", err)
+	}
+
+	return models.UserInfo{
+		ID:              userInfo.ID,
+		NodeID:          userInfo.NodeID,
+		CreatedAt:       createdAt,
+		Followers:       userInfo.Followers,
+		Following:       userInfo.Following,
+		Type:            userInfo.Type,
+		Bio:             stringValue(userInfo.Bio),
+		Blog:            stringValue(userInfo.Blog),
+		TwitterUsername: stringValue(userInfo.TwitterUsername),
+		AvatarURL:       userInfo.AvatarURL,
+	}, nil
+}
+
+// maxAvatarBytes bounds avatar downloads.
+const maxAvatarBytes = 1 << 20
+
+// GetAvatar downloads the avatar image at avatarURL, refusing images over maxAvatarBytes. The
+// request carries no token: avatars are served from outside the API and count against no rate
+// limit.
+func (c *Client) GetAvatar(ctx context.Context, avatarURL string) ([]byte, error) {
+	if !strings.HasPrefix(avatarURL, "https://") && !strings.HasPrefix(avatarURL, "http://") {
+		return nil, fmt.Errorf("unsupported avatar URL %q", avatarURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, avatarURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching avatar: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxAvatarBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading avatar: %w", err)
 	}
+	if len(body) > maxAvatarBytes {
+		return nil, fmt.Errorf("avatar exceeds %d bytes", maxAvatarBytes)
+	}
+	return body, nil
+}
 
-	return createdAt, nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
 
-// GetUserRepositories fetches a user's repositories from GitHub
+// GetUserRepositories fetches a user's repositories from GitHub. Rate-limited pages are retried
+// with backoff. When a page after the first still fails, the repositories from earlier pages are
+// returned with an error wrapping ErrPartialResults.
 func (c *Client) GetUserRepositories(ctx context.Context, username string) ([]models.RepoMetrics, error) {
 	var repos []models.RepoMetrics
 	page := 1
+	fail := func(err error) ([]models.RepoMetrics, error) {
+		if page == 1 {
+			return nil, err
+		}
+		return repos, fmt.Errorf("%w: stopped at page %d: %w", ErrPartialResults, page, err)
+	}
 
 	for {
 		if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
-			return nil, err
+			return fail(err)
 		}
 
-		url := fmt.Sprintf("https://api.github.com/users/%s/repos?per_page=100&page=%d", username, page)
+		url := fmt.Sprintf("%s/users/%s/repos?per_page=100&page=%d", c.baseURL, username, page)
 		cacheKey := fmt.Sprintf("repos:%s:%d", username, page)
 
-		var responseBody []byte
-
-		// Try from cache first
-		if cachedData, found := c.apiCache.Get(cacheKey, c.cacheTTL); found {
-			c.logger.Debug("Cache hit for repos of user '%s' page %d", username, page)
-			responseBody = cachedData
-		} else {
-			c.logger.Debug("Cache miss for repos of user '%s' page %d, fetching from API", username, page)
-
-			req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-			if err != nil {
-				return nil, err
-			}
-
-			req.Header.Set("Authorization", "token "+c.token)
-			req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-			resp, err := c.httpClient.Do(req)
-			if err != nil {
-				return nil, err
-			}
-
-			// Update rate limits
-			c.rateLimiter.UpdateFromResponse(resp)
-
-			if resp.StatusCode != http.StatusOK {
-				bodyBytes, _ := io.ReadAll(resp.Body)
-				resp.Body.Close()
-				return nil, fmt.Errorf("failed to fetch user repos: %s - Body: %s", resp.Status, string(bodyBytes))
-			}
-
-			// Read response body
-			responseBody, err = io.ReadAll(resp.Body)
-			closeErr := resp.Body.Close()
-			if err != nil {
-				return nil, fmt.Errorf("reading response body: %w", err)
-			}
-			if closeErr != nil {
-				return nil, fmt.Errorf("closing response body: %w", closeErr)
-			}
-
-			// Cache the response
-			c.apiCache.Set(cacheKey, responseBody)
-			c.logger.Debug("Cached repos for user '%s' page %d", username, page)
+		responseBody, err := c.getWithBackoff(ctx, url, "application/vnd.github.v3+json", cacheKey, "core")
+		if err != nil {
+			return fail(fmt.Errorf("failed to fetch user repos: %w", err))
 		}
 
 		// Parse the repositories
 		var userRepos []struct {
-			Name            string `json:"name"`
-			Size            int    `json:"size"`
-			StargazersCount int    `json:"stargazers_count"`
+			Name            string    `json:"name"`
+			Size            int       `json:"size"`
+			StargazersCount int       `json:"stargazers_count"`
+			Fork            bool      `json:"fork"`
+			PushedAt        time.Time `json:"pushed_at"`
 		}
 
 		if err := json.Unmarshal(responseBody, &userRepos); err != nil {
-			return nil, fmt.Errorf("decoding user repositories: %w", err)
+			return fail(fmt.Errorf("decoding user repositories: %w", err))
 		}
 
 		if len(userRepos) == 0 {
@@ -282,6 +539,8 @@ func (c *Client) GetUserRepositories(ctx context.Context, username string) ([]mo
 				Name:           r.Name,
 				DiskUsage:      r.Size,
 				StargazerCount: r.StargazersCount,
+				Fork:           r.Fork,
+				PushedAt:       r.PushedAt,
 			})
 		}
 
@@ -295,155 +554,177 @@ func (c *Client) GetUserRepositories(ctx context.Context, username string) ([]mo
 	return repos, nil
 }
 
-// GetUserContributions fetches a user's contributions from GitHub
+// GetUserContributions fetches a user's contributions from GitHub over the last year. With
+// ContributionSourceGraphQL it reads the contribution calendar and falls back to counting
+// public events when the GraphQL call fails.
 func (c *Client) GetUserContributions(ctx context.Context, username string) (int, error) {
-	if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
-		return 0, err
+	if c.contributionSource == ContributionSourceGraphQL {
+		count, err := c.graphQLContributions(ctx, username)
+		if err == nil {
+			return count, nil
+		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		c.logger.Warn("Counting public events of %s instead of its contribution calendar: %v", username, err)
 	}
+	return c.eventContributions(ctx, username)
+}
 
-	url := fmt.Sprintf("https://api.github.com/users/%s/events/public?per_page=100", username)
-	cacheKey := fmt.Sprintf("events:%s", username)
-
-	var responseBody []byte
-
-	// Try from cache first
-	if cachedData, found := c.apiCache.Get(cacheKey, c.cacheTTL); found {
-		c.logger.Debug("Cache hit for events of user '%s'", username)
-		responseBody = cachedData
-	} else {
-		c.logger.Debug("Cache miss for events of user '%s', fetching from API", username)
+// maxEventPages is how many pages of 100 public events GitHub serves; later pages are refused.
+const maxEventPages = 3
 
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
+// eventContributions counts the user's public events from the last year. It pages through the
+// events, newest first, until one is older than a year or GitHub has no more to serve, so at
+// most 300 are counted. When a page after the first fails, the events counted so far are
+// returned with an error wrapping ErrPartialResults.
+func (c *Client) eventContributions(ctx context.Context, username string) (int, error) {
+	oneYearAgo := time.Now().Add(-365 * 24 * time.Hour)
+	count := 0
+	page := 1
+	fail := func(err error) (int, error) {
+		if page == 1 {
 			return 0, err
 		}
+		return count, fmt.Errorf("%w: stopped at page %d: %w", ErrPartialResults, page, err)
+	}
 
-		req.Header.Set("Authorization", "token "+c.token)
-		req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return 0, err
+	for ; page <= maxEventPages; page++ {
+		if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
+			return fail(err)
 		}
-		defer resp.Body.Close()
 
-		// Update rate limits
-		c.rateLimiter.UpdateFromResponse(resp)
+		url := fmt.Sprintf("%s/users/%s/events/public?per_page=100&page=%d", c.baseURL, username, page)
+		cacheKey := fmt.Sprintf("events:%s:%d", username, page)
 
-		if resp.StatusCode != http.StatusOK {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			return 0, fmt.Errorf("failed to fetch user events: %s - %s", resp.Status, string(bodyBytes))
+		responseBody, err := c.getWithBackoff(ctx, url, "application/vnd.github.v3+json", cacheKey, "core")
+		var apiErr *APIError
+		if page > 1 && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnprocessableEntity {
+			// GitHub refuses pages past the events it keeps.
+			break
 		}
-
-		// Read response body
-		responseBody, err = io.ReadAll(resp.Body)
 		if err != nil {
-			return 0, fmt.Errorf("reading response body: %w", err)
+			return fail(fmt.Errorf("failed to fetch user events: %w", err))
 		}
 
-		// Cache the response
-		c.apiCache.Set(cacheKey, responseBody)
-		c.logger.Debug("Cached events for user '%s'", username)
-	}
-
-	// Parse the events
-	var events []struct {
-		CreatedAt string `json:"created_at"`
-	}
-
-	if err := json.Unmarshal(responseBody, &events); err != nil {
-		return 0, fmt.Errorf("decoding user events: %w", err)
-	}
-
-	oneYearAgo := time.Now().Add(-365 * 24 * time.Hour)
-	count := 0
+		var events []struct {
+			CreatedAt string `json:"created_at"`
+		}
+		if err := json.Unmarshal(responseBody, &events); err != nil {
+			return fail(fmt.Errorf("decoding user events: %w", err))
+		}
 
-	for _, e := range events {
-		t, err := time.Parse(time.RFC3339, e.CreatedAt)
-		if err == nil && t.After(oneYearAgo) {
+		expired := false
+		for _, e := range events {
+			t, err := time.Parse(time.RFC3339, e.CreatedAt)
+			if err != nil {
+				continue
+			}
+			if !t.After(oneYearAgo) {
+				expired = true
+				continue
+			}
 			count++
 		}
+		if expired || len(events) < 100 {
+			break
+		}
 	}
 
 	return count, nil
 }
 
-// GetRepoReadme fetches a repository's README from GitHub
+// GetRepoReadme fetches a repository's README from GitHub. A repository without a README
+// returns an empty string.
 func (c *Client) GetRepoReadme(ctx context.Context, owner, repo string) (string, error) {
 	if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
 		return "", err
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/readme", owner, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s/readme", c.baseURL, owner, repo)
 	cacheKey := fmt.Sprintf("readme:%s:%s", owner, repo)
 
-	var responseBody []byte
-
-	// Try from cache first
-	if cachedData, found := c.apiCache.Get(cacheKey, c.cacheTTL); found {
-		c.logger.Debug("Cache hit for readme of %s/%s", owner, repo)
-		responseBody = cachedData
-	} else {
-		c.logger.Debug("Cache miss for readme of %s/%s, fetching from API", owner, repo)
-
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return "", err
-		}
-
-		req.Header.Set("Authorization", "token "+c.token)
-		req.Header.Set("Accept", "application/vnd.github.v3+json")
+	responseBody, err := c.get(ctx, url, "application/vnd.github.v3+json", cacheKey)
+	if IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("fetching readme: %w", err)
+	}
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return "", err
-		}
-		defer resp.Body.Close()
+	// Parse the readme data
+	var data struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
 
-		// Update rate limits
-		c.rateLimiter.UpdateFromResponse(resp)
+	if err := json.Unmarshal(responseBody, &data); err != nil {
+		return "", fmt.Errorf("decoding readme: %w", err)
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			c.logger.Debug("Non-OK response for readme: status=%s, body=%s", resp.Status, string(bodyBytes))
+	if data.Encoding != "base64" {
+		return "", fmt.Errorf("unexpected readme encoding: %s", data.Encoding)
+	}
 
-			if resp.StatusCode == http.StatusNotFound {
-				return "", nil
-			}
+	decoded, err := base64.StdEncoding.DecodeString(data.Content)
+	if err != nil {
+		return "", fmt.Errorf("decoding readme content: %w", err)
+	}
 
-			return "", fmt.Errorf("fetching readme: %s - body: %s", resp.Status, string(bodyBytes))
-		}
+	return string(decoded), nil
+}
 
-		// Read the response body
-		responseBody, err = io.ReadAll(resp.Body)
+// GetRepoReadmes fetches the READMEs of several repositories of one owner, keyed by repository
+// name. Each README costs one core API request unless the response cache still holds it, so
+// analyzing an owner again within the cache TTL downloads nothing and later analyses revalidate
+// with ETags. It stops at the first error and returns the READMEs fetched so far with it.
+func (c *Client) GetRepoReadmes(ctx context.Context, owner string, repos []string) (map[string]string, error) {
+	readmes := make(map[string]string, len(repos))
+	for _, repo := range repos {
+		readme, err := c.GetRepoReadme(ctx, owner, repo)
 		if err != nil {
-			return "", fmt.Errorf("reading readme body: %w", err)
+			return readmes, err
 		}
+		readmes[repo] = readme
+	}
+	return readmes, nil
+}
 
-		// Cache the response
-		c.apiCache.Set(cacheKey, responseBody)
-		c.logger.Debug("Cached readme for %s/%s", owner, repo)
+// GetFileContent fetches one file at ref, or on the default branch when ref is empty. A missing
+// file returns an empty string and no error.
+func (c *Client) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
+		return "", err
+	}
+
+	reqURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s", c.baseURL, owner, repo, path)
+	if ref != "" {
+		reqURL += "?ref=" + url.QueryEscape(ref)
+	}
+	cacheKey := fmt.Sprintf("content:%s:%s:%s:%s", owner, repo, ref, path)
+
+	responseBody, err := c.get(ctx, reqURL, "application/vnd.github.v3+json", cacheKey)
+	if IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("fetching %s: %w", path, err)
 	}
 
-	// Parse the readme data
 	var data struct {
 		Content  string `json:"content"`
 		Encoding string `json:"encoding"`
 	}
-
 	if err := json.Unmarshal(responseBody, &data); err != nil {
-		return "", fmt.Errorf("decoding readme: %w", err)
+		return "", fmt.Errorf("decoding %s: %w", path, err)
 	}
-
 	if data.Encoding != "base64" {
-		return "", fmt.Errorf("unexpected readme encoding: %s", data.Encoding)
+		return "", fmt.Errorf("unexpected %s encoding: %s", path, data.Encoding)
 	}
-
 	decoded, err := base64.StdEncoding.DecodeString(data.Content)
 	if err != nil {
-		return "", fmt.Errorf("decoding readme content: %w", err)
+		return "", fmt.Errorf("decoding %s content: %w", path, err)
 	}
-
 	return string(decoded), nil
 }
 
@@ -453,50 +734,12 @@ func (c *Client) GetRepoTree(ctx context.Context, owner, repo, branch string) ([
 		return nil, err
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/trees/%s?recursive=1", owner, repo, branch)
+	url := fmt.Sprintf("%s/repos/%s/%s/git/trees/%s?recursive=1", c.baseURL, owner, repo, branch)
 	cacheKey := fmt.Sprintf("tree:%s:%s:%s", owner, repo, branch)
 
-	var responseBody []byte
-
-	// Try from cache first
-	if cachedData, found := c.apiCache.Get(cacheKey, c.cacheTTL); found {
-		c.logger.Debug("Cache hit for tree of %s/%s:%s", owner, repo, branch)
-		responseBody = cachedData
-	} else {
-		c.logger.Debug("Cache miss for tree of %s/%s:%s, fetching from API", owner, repo, branch)
-
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-
-		req.Header.Set("Authorization", "token "+c.token)
-		req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
-
-		// Update rate limits
-		c.rateLimiter.UpdateFromResponse(resp)
-
-		if resp.StatusCode != http.StatusOK {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			c.logger.Debug("Non-OK response for tree: status=%s, body=%s", resp.Status, string(bodyBytes))
-			return nil, fmt.Errorf("fetching repo tree: %s", resp.Status)
-		}
-
-		// Read the response body
-		responseBody, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("reading tree body: %w", err)
-		}
-
-		// Cache the response
-		c.apiCache.Set(cacheKey, responseBody)
-		c.logger.Debug("Cached tree for %s/%s:%s", owner, repo, branch)
+	responseBody, err := c.get(ctx, url, "application/vnd.github.v3+json", cacheKey)
+	if err != nil {
+		return nil, fmt.Errorf("fetching repo tree: %w", err)
 	}
 
 	// Parse the tree data
@@ -521,85 +764,285 @@ func (c *Client) GetRepoTree(ctx context.Context, owner, repo, branch string) ([
 	return entries, nil
 }
 
-// CheckRepoReleases checks a repository's releases for malicious files
-func (c *Client) CheckRepoReleases(ctx context.Context, owner, repo string) (bool, error) {
+// GetRepoLanguages fetches the bytes of code GitHub attributes to each language in a repository.
+func (c *Client) GetRepoLanguages(ctx context.Context, owner, repo string) (map[string]int, error) {
 	if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
-		return false, err
+		return nil, err
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", owner, repo)
-	cacheKey := fmt.Sprintf("releases:%s:%s", owner, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s/languages", c.baseURL, owner, repo)
+	cacheKey := fmt.Sprintf("languages:%s:%s", owner, repo)
 
-	var responseBody []byte
+	responseBody, err := c.get(ctx, url, "application/vnd.github.v3+json", cacheKey)
+	if err != nil {
+		return nil, fmt.Errorf("fetching repo languages: %w", err)
+	}
 
-	// Try from cache first
-	if cachedData, found := c.apiCache.Get(cacheKey, c.cacheTTL); found {
-		c.logger.Debug("Cache hit for releases of %s/%s", owner, repo)
-		responseBody = cachedData
-	} else {
-		c.logger.Debug("Cache miss for releases of %s/%s, fetching from API", owner, repo)
+	var languages map[string]int
+	if err := json.Unmarshal(responseBody, &languages); err != nil {
+		return nil, fmt.Errorf("decoding repo languages: %w", err)
+	}
+	return languages, nil
+}
 
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return false, err
-		}
+// GetRepoTopics fetches a repository's topics.
+func (c *Client) GetRepoTopics(ctx context.Context, owner, repo string) ([]string, error) {
+	if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
+		return nil, err
+	}
 
-		req.Header.Set("Authorization", "token "+c.token)
-		req.Header.Set("Accept", "application/vnd.github.v3+json")
+	url := fmt.Sprintf("%s/repos/%s/%s/topics", c.baseURL, owner, repo)
+	cacheKey := fmt.Sprintf("topics:%s:%s", owner, repo)
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return false, err
-		}
-		defer resp.Body.Close()
+	responseBody, err := c.get(ctx, url, "application/vnd.github+json", cacheKey)
+	if err != nil {
+		return nil, fmt.Errorf("fetching repo topics: %w", err)
+	}
 
-		// Update rate limits
-		c.rateLimiter.UpdateFromResponse(resp)
+	var data struct {
+		Names []string `json:"names"`
+	}
+	if err := json.Unmarshal(responseBody, &data); err != nil {
+		return nil, fmt.Errorf("decoding repo topics: %w", err)
+	}
+	return data.Names, nil
+}
 
-		if resp.StatusCode != http.StatusOK {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			c.logger.Debug("Non-OK response for releases: status=%s, body=%s", resp.Status, string(bodyBytes))
-			return false, fmt.Errorf("failed to fetch releases: %s", resp.Status)
-		}
+// GetReleaseAssets lists the assets of a repository's most recent releases with their sizes,
+// download counts, and content types. The response is cached, so the checkers and the scan
+// service that each list a repository's assets share one request.
+func (c *Client) GetReleaseAssets(ctx context.Context, owner, repo string) ([]models.ReleaseAsset, error) {
+	if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
+		return nil, err
+	}
 
-		// Read the response body
-		responseBody, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return false, fmt.Errorf("reading releases body: %w", err)
-		}
+	url := fmt.Sprintf("%s/repos/%s/%s/releases", c.baseURL, owner, repo)
+	cacheKey := fmt.Sprintf("releases:%s:%s", owner, repo)
 
-		// Cache the response
-		c.apiCache.Set(cacheKey, responseBody)
-		c.logger.Debug("Cached releases for %s/%s", owner, repo)
+	responseBody, err := c.get(ctx, url, "application/vnd.github.v3+json", cacheKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch releases: %w", err)
 	}
 
-	// Parse the releases data
 	var releases []struct {
-		Assets []struct {
-			Name string `json:"name"`
-		} `json:"assets"`
+		Assets []models.ReleaseAsset `json:"assets"`
 	}
-
 	if err := json.Unmarshal(responseBody, &releases); err != nil {
-		return false, fmt.Errorf("decoding releases: %w", err)
+		return nil, fmt.Errorf("decoding releases: %w", err)
 	}
 
+	var assets []models.ReleaseAsset
 	for _, rel := range releases {
-		for _, asset := range rel.Assets {
-			lower := strings.ToLower(asset.Name)
-			if lower == "loader.zip" || lower == "loader.rar" {
-				c.logger.Info("Found suspicious asset in releases of %s/%s: %s", owner, repo, asset.Name)
-				return true, nil
+		assets = append(assets, rel.Assets...)
+	}
+	return assets, nil
+}
+
+// DownloadReleaseAsset streams a release asset and returns the SHA-256 of its content as
+// "sha256:<hex>", the form of GitHub's asset digests, with its size. The content is hashed as it
+// arrives and never held in memory. Reading stops with an error wrapping ErrAssetTooLarge once
+// more than maxBytes arrive.
+func (c *Client) DownloadReleaseAsset(ctx context.Context, owner, repo string, assetID, maxBytes int64) (string, int64, error) {
+	if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
+		return "", 0, err
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/assets/%d", c.baseURL, owner, repo, assetID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	// GitHub redirects to the storage host, and the client drops the token on the way there.
+	req.Header.Set("Authorization", "token "+c.token)
+	req.Header.Set("Accept", "application/octet-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("downloading release asset %d: %w", assetID, err)
+	}
+	defer resp.Body.Close()
+	c.rateLimiter.UpdateFromResponse(resp)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", 0, classifyAPIError(&APIError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)})
+	}
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return "", size, fmt.Errorf("downloading release asset %d: %w", assetID, err)
+	}
+	if size > maxBytes {
+		return "", size, fmt.Errorf("%w: asset %d exceeds %d bytes", ErrAssetTooLarge, assetID, maxBytes)
+	}
+	return "sha256:" + hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
+
+// ListCommits returns the SHAs of up to limit recent commits on branch, newest first. An empty
+// branch lists the default branch.
+func (c *Client) ListCommits(ctx context.Context, owner, repo, branch string, limit int) ([]string, error) {
+	commits, err := c.GetRepoCommits(ctx, owner, repo, branch, limit)
+	if err != nil {
+		return nil, err
+	}
+	shas := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		shas = append(shas, commit.SHA)
+	}
+	return shas, nil
+}
+
+// GetRepoCommits returns up to limit recent commits on branch with their messages, newest first,
+// without their files. An empty branch lists the default branch, and an empty repository has no
+// commits.
+func (c *Client) GetRepoCommits(ctx context.Context, owner, repo, branch string, limit int) ([]models.Commit, error) {
+	if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	reqURL := fmt.Sprintf("%s/repos/%s/%s/commits?per_page=%d", c.baseURL, owner, repo, limit)
+	if branch != "" {
+		reqURL += "&sha=" + url.QueryEscape(branch)
+	}
+	cacheKey := fmt.Sprintf("commits:%s:%s:%s:%d", owner, repo, branch, limit)
+
+	responseBody, err := c.get(ctx, reqURL, "application/vnd.github.v3+json", cacheKey)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+		// GitHub answers 409 Conflict for a repository without commits.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch commits: %w", err)
+	}
+
+	var commits []struct {
+		SHA    string `json:"sha"`
+		Commit struct {
+			Message string `json:"message"`
+			Author  struct {
+				Date time.Time `json:"date"`
+			} `json:"author"`
+		} `json:"commit"`
+	}
+	if err := json.Unmarshal(responseBody, &commits); err != nil {
+		return nil, fmt.Errorf("decoding commits: %w", err)
+	}
+
+	result := make([]models.Commit, 0, len(commits))
+	for _, commit := range commits {
+		result = append(result, models.Commit{SHA: commit.SHA, Message: commit.Commit.Message, Date: commit.Commit.Author.Date})
+	}
+	return result, nil
+}
+
+// GetCommitFiles fetches the files changed by a commit.
+func (c *Client) GetCommitFiles(ctx context.Context, owner, repo, sha string) ([]models.CommitFile, error) {
+	if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s", c.baseURL, owner, repo, sha)
+	cacheKey := fmt.Sprintf("commit:%s:%s:%s", owner, repo, sha)
+
+	responseBody, err := c.get(ctx, url, "application/vnd.github.v3+json", cacheKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch commit %s: %w", sha, err)
+	}
+
+	var data struct {
+		Files []struct {
+			Filename string `json:"filename"`
+			Status   string `json:"status"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(responseBody, &data); err != nil {
+		return nil, fmt.Errorf("decoding commit %s: %w", sha, err)
+	}
+
+	files := make([]models.CommitFile, 0, len(data.Files))
+	for _, file := range data.Files {
+		files = append(files, models.CommitFile{Filename: file.Filename, Status: file.Status})
+	}
+	return files, nil
+}
+
+// GetStargazers fetches up to limit stargazers of a repository with their starring time.
+// A limit of zero or less fetches every page.
+func (c *Client) GetStargazers(ctx context.Context, owner, repo string, limit int) ([]models.Stargazer, error) {
+	var stargazers []models.Stargazer
+	page := 1
+
+	for {
+		if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
+			return nil, err
+		}
+
+		url := fmt.Sprintf("%s/repos/%s/%s/stargazers?per_page=100&page=%d", c.baseURL, owner, repo, page)
+		cacheKey := fmt.Sprintf("stargazers:%s:%s:%d", owner, repo, page)
+
+		// The star media type includes starred_at alongside each user.
+		responseBody, err := c.get(ctx, url, "application/vnd.github.star+json", cacheKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch stargazers: %w", err)
+		}
+
+		var pageStargazers []struct {
+			StarredAt time.Time `json:"starred_at"`
+			User      struct {
+				Login string `json:"login"`
+			} `json:"user"`
+		}
+		if err := json.Unmarshal(responseBody, &pageStargazers); err != nil {
+			return nil, fmt.Errorf("decoding stargazers: %w", err)
+		}
+
+		for _, s := range pageStargazers {
+			stargazers = append(stargazers, models.Stargazer{Login: s.User.Login, StarredAt: s.StarredAt})
+			if limit > 0 && len(stargazers) >= limit {
+				return stargazers, nil
 			}
 		}
+
+		if len(pageStargazers) < 100 {
+			break
+		}
+		page++
 	}
 
-	return false, nil
+	return stargazers, nil
+}
+
+// GetUserStarred returns how many repositories username has starred, counting at most limit
+// so the answer costs a single request.
+func (c *Client) GetUserStarred(ctx context.Context, username string, limit int) (int, error) {
+	if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
+		return 0, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	reqURL := fmt.Sprintf("%s/users/%s/starred?per_page=%d", c.baseURL, username, limit)
+	cacheKey := fmt.Sprintf("starred:%s:%d", username, limit)
+
+	responseBody, err := c.get(ctx, reqURL, "application/vnd.github.v3+json", cacheKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch starred repositories: %w", err)
+	}
+
+	var starred []json.RawMessage
+	if err := json.Unmarshal(responseBody, &starred); err != nil {
+		return 0, fmt.Errorf("decoding starred repositories: %w", err)
+	}
+	return len(starred), nil
 }
 
 // FetchRateLimits gets GitHub API rate limit information
 func (c *Client) FetchRateLimits(ctx context.Context) error {
-	return c.rateLimiter.FetchRateLimits(ctx, c.token)
+	return c.rateLimiter.FetchRateLimits(ctx, c.httpClient, c.baseURL, c.token)
 }
 
 func sleepWithContext(ctx context.Context, d time.Duration) error {
@@ -0,0 +1,266 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseCache stores API responses for the client. APICache keeps them in memory and
+// DiskCache in a directory, so they survive restarts.
+type ResponseCache interface {
+	// Get returns the response stored under key if it is younger than ttl.
+	Get(key string, ttl time.Duration) ([]byte, bool)
+	// Stale returns the response stored under key regardless of age, with its ETag.
+	Stale(key string) ([]byte, string, bool)
+	Set(key string, data []byte)
+	SetWithETag(key string, data []byte, etag string)
+	Delete(key string)
+	Clear()
+	// Len returns the number of stored responses.
+	Len() int
+	// Close stops background maintenance.
+	Close()
+}
+
+var (
+	_ ResponseCache = (*APICache)(nil)
+	_ ResponseCache = (*DiskCache)(nil)
+)
+
+// diskCacheSuffix names the cache files, so Clear and Len ignore anything else in the directory.
+const diskCacheSuffix = ".cache"
+
+// DiskCache stores each response in a file named by the SHA-256 of its key. A file holds one
+// JSON header line with the key, ETag, and store time, followed by the raw response bytes. Like
+// APICache, a janitor deletes files older than the maximum age, and MaxEntries evicts the least
+// recently used files.
+type DiskCache struct {
+	dir        string
+	maxAge     time.Duration
+	maxEntries int
+	// mu serializes eviction, which lists the directory.
+	mu       sync.Mutex
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type diskCacheHeader struct {
+	Key       string    `json:"key"`
+	ETag      string    `json:"etag,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewDiskCache opens the cache in dir, creating the directory when needed, and starts its
+// janitor. Responses stored by an earlier process are served until they age out.
+func NewDiskCache(dir string, opts CacheOptions) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+	c := &DiskCache{
+		dir:        dir,
+		maxAge:     opts.MaxAge,
+		maxEntries: max(opts.MaxEntries, 0),
+		stop:       make(chan struct{}),
+	}
+	if c.maxAge <= 0 {
+		c.maxAge = DefaultCacheMaxAge
+	}
+	interval := opts.JanitorInterval
+	if interval <= 0 {
+		interval = DefaultCacheJanitorInterval
+	}
+	go c.janitor(interval)
+	return c, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+diskCacheSuffix)
+}
+
+// read loads the entry stored under key. Unreadable and foreign files count as missing.
+func (c *DiskCache) read(key string) (diskCacheHeader, []byte, bool) {
+	contents, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return diskCacheHeader{}, nil, false
+	}
+	line, data, found := bytes.Cut(contents, []byte("\n"))
+	if !found {
+		return diskCacheHeader{}, nil, false
+	}
+	var header diskCacheHeader
+	if err := json.Unmarshal(line, &header); err != nil || header.Key != key {
+		return diskCacheHeader{}, nil, false
+	}
+	return header, data, true
+}
+
+// touch marks the file as recently used for eviction.
+func (c *DiskCache) touch(key string) {
+	now := time.Now()
+	_ = os.Chtimes(c.path(key), now, now)
+}
+
+// Get retrieves a cached response if it exists and is not expired.
+func (c *DiskCache) Get(key string, ttl time.Duration) ([]byte, bool) {
+	header, data, ok := c.read(key)
+	if !ok || time.Since(header.Timestamp) >= ttl {
+		return nil, false
+	}
+	c.touch(key)
+	return data, true
+}
+
+// Stale retrieves a cached response regardless of age, with the ETag it was served with.
+func (c *DiskCache) Stale(key string) ([]byte, string, bool) {
+	header, data, ok := c.read(key)
+	if !ok {
+		return nil, "", false
+	}
+	c.touch(key)
+	return data, header.ETag, true
+}
+
+// Set stores a response in the cache.
+func (c *DiskCache) Set(key string, data []byte) {
+	c.SetWithETag(key, data, "")
+}
+
+// SetWithETag stores a response along with its ETag validator. The file is written to a
+// temporary name and renamed into place, so concurrent readers never see a partial entry.
+// Write errors leave the response uncached.
+func (c *DiskCache) SetWithETag(key string, data []byte, etag string) {
+	header, err := json.Marshal(diskCacheHeader{Key: key, ETag: etag, Timestamp: time.Now()})
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return
+	}
+	w := bufio.NewWriter(tmp)
+	w.Write(header)
+	w.WriteByte('\n')
+	w.Write(data)
+	if err := errors.Join(w.Flush(), tmp.Close()); err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if c.maxEntries > 0 {
+		c.evict()
+	}
+}
+
+// Delete drops a cached response.
+func (c *DiskCache) Delete(key string) {
+	_ = os.Remove(c.path(key))
+}
+
+// Clear deletes every cached response.
+func (c *DiskCache) Clear() {
+	for _, entry := range c.files() {
+		_ = os.Remove(filepath.Join(c.dir, entry.Name()))
+	}
+}
+
+// Len returns the number of cached responses, including expired ones not yet purged.
+func (c *DiskCache) Len() int {
+	return len(c.files())
+}
+
+// Close stops the janitor. The files stay for the next process.
+func (c *DiskCache) Close() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// Purge deletes the responses stored longer ago than the maximum age and returns how many it
+// deleted.
+func (c *DiskCache) Purge() int {
+	purged := 0
+	for _, entry := range c.files() {
+		path := filepath.Join(c.dir, entry.Name())
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		line, _, _ := bytes.Cut(contents, []byte("\n"))
+		var header diskCacheHeader
+		if err := json.Unmarshal(line, &header); err != nil || time.Since(header.Timestamp) >= c.maxAge {
+			if os.Remove(path) == nil {
+				purged++
+			}
+		}
+	}
+	return purged
+}
+
+func (c *DiskCache) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.Purge()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// evict deletes the least recently used files until a tenth of MaxEntries is free.
+func (c *DiskCache) evict() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := c.files()
+	if len(entries) <= c.maxEntries {
+		return
+	}
+	type usedFile struct {
+		name     string
+		lastUsed time.Time
+	}
+	files := make([]usedFile, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, usedFile{name: entry.Name(), lastUsed: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].lastUsed.After(files[j].lastUsed) })
+	keep := max(c.maxEntries-max(c.maxEntries/10, 1), 1)
+	for _, file := range files[min(keep, len(files)):] {
+		_ = os.Remove(filepath.Join(c.dir, file.name))
+	}
+}
+
+// files lists the cache files in the directory.
+func (c *DiskCache) files() []fs.DirEntry {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil
+	}
+	files := entries[:0]
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), diskCacheSuffix) {
+			files = append(files, entry)
+		}
+	}
+	return files
+}
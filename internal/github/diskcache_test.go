@@ -0,0 +1,101 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github/githubtest"
+	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
+)
+
+func newTestDiskCache(t *testing.T, dir string, opts CacheOptions) *DiskCache {
+	t.Helper()
+	cache, err := NewDiskCache(dir, opts)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+	t.Cleanup(cache.Close)
+	return cache
+}
+
+func TestDiskCacheSurvivesReopening(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	first := newTestDiskCache(t, dir, CacheOptions{})
+	first.SetWithETag("readme:octo/tool", []byte("# Tool\nbinary \x00 safe"), `"abc"`)
+	first.Close()
+
+	reopened := newTestDiskCache(t, dir, CacheOptions{})
+	data, found := reopened.Get("readme:octo/tool", time.Hour)
+	if !found || string(data) != "# Tool\nbinary \x00 safe" {
+		t.Fatalf("Get() after reopening = %q, %v, want the stored response", data, found)
+	}
+	if _, etag, found := reopened.Stale("readme:octo/tool"); !found || etag != `"abc"` {
+		t.Fatalf("Stale() = %q, %v, want the stored ETag", etag, found)
+	}
+	if _, found := reopened.Get("readme:octo/tool", 0); found {
+		t.Fatal("Get() served an entry past its TTL")
+	}
+	if reopened.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", reopened.Len())
+	}
+
+	reopened.Delete("readme:octo/tool")
+	if _, _, found := reopened.Stale("readme:octo/tool"); found || reopened.Len() != 0 {
+		t.Fatalf("entry survived Delete(), Len() = %d", reopened.Len())
+	}
+}
+
+func TestDiskCachePurgesAndBoundsEntries(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep me"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cache := newTestDiskCache(t, dir, CacheOptions{MaxAge: 50 * time.Millisecond, MaxEntries: 10})
+	cache.Set("old", []byte("x"))
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 15; i++ {
+		cache.Set(fmt.Sprintf("key-%d", i), []byte("x"))
+	}
+	if cache.Len() > 10 {
+		t.Fatalf("Len() = %d, want at most 10", cache.Len())
+	}
+	if _, _, found := cache.Stale("key-14"); !found {
+		t.Fatal("newest entry was evicted")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	cache.Set("fresh", []byte("x"))
+	cache.Purge()
+	if cache.Len() != 1 {
+		t.Fatalf("Len() after Purge() = %d, want only the fresh entry", cache.Len())
+	}
+	cache.Clear()
+	if cache.Len() != 0 {
+		t.Fatalf("Len() after Clear() = %d, want 0", cache.Len())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatalf("Clear() removed a file it did not write: %v", err)
+	}
+}
+
+func TestClientServesDiskCacheAcrossRestarts(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.SetReadme("octo", "tool", "# Tool")
+	dir := t.TempDir()
+	for run := 0; run < 2; run++ {
+		client := NewClient(testToken, 0, 60, newTestDiskCache(t, dir, CacheOptions{}), logger.New(false))
+		t.Cleanup(client.Close)
+		client.SetBaseURL(server.URL)
+		readme, err := client.GetRepoReadme(context.Background(), "octo", "tool")
+		if err != nil || readme != "# Tool" {
+			t.Fatalf("run %d: GetRepoReadme() = %q, %v", run, readme, err)
+		}
+	}
+	if got := server.RequestCount("/repos/octo/tool/readme"); got != 1 {
+		t.Fatalf("README requests = %d, want the second run served from disk", got)
+	}
+}
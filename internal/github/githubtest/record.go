@@ -0,0 +1,136 @@
+package githubtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// recordedHeaders are the response headers kept in fixtures. Everything else, including
+// cookies and request IDs, is dropped.
+var recordedHeaders = []string{"Content-Type", "ETag", "Link", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "X-RateLimit-Resource"}
+
+// tokenPattern matches GitHub token formats so they are scrubbed even when not passed as secrets.
+var tokenPattern = regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,})\b`)
+
+// Fixture is a recorded response for one request. Path includes the query string.
+type Fixture struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Response
+}
+
+// Recorder is an http.RoundTripper that forwards requests to Transport and keeps a scrubbed
+// copy of every response. Set it as the transport of the client under test, run the calls
+// against the real API once, and Save the result as fixtures.
+type Recorder struct {
+	Transport http.RoundTripper
+	// Secrets are replaced with [redacted] in recorded bodies and headers.
+	Secrets []string
+
+	mu       sync.Mutex
+	fixtures []Fixture
+}
+
+// NewRecorder records responses from transport, or http.DefaultTransport when nil.
+func NewRecorder(transport http.RoundTripper, secrets ...string) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{Transport: transport, Secrets: secrets}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("recording %s: %w", req.URL.Path, err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	fixture := Fixture{
+		Method:   req.Method,
+		Path:     req.URL.RequestURI(),
+		Response: Response{Status: resp.StatusCode, Body: r.scrub(string(body))},
+	}
+	for _, name := range recordedHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			if fixture.Headers == nil {
+				fixture.Headers = map[string]string{}
+			}
+			fixture.Headers[name] = r.scrub(value)
+		}
+	}
+	r.mu.Lock()
+	r.fixtures = append(r.fixtures, fixture)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) scrub(text string) string {
+	for _, secret := range r.Secrets {
+		if secret != "" {
+			text = strings.ReplaceAll(text, secret, "[redacted]")
+		}
+	}
+	return tokenPattern.ReplaceAllString(text, "[redacted]")
+}
+
+// Fixtures returns the responses recorded so far, in request order.
+func (r *Recorder) Fixtures() []Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Fixture(nil), r.fixtures...)
+}
+
+// Save writes the recorded fixtures to path as indented JSON.
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Fixtures(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding fixtures: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing fixtures: %w", err)
+	}
+	return nil
+}
+
+// LoadFixtures reads fixtures written by Recorder.Save.
+func LoadFixtures(path string) ([]Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading fixtures: %w", err)
+	}
+	var fixtures []Fixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("decoding fixtures %s: %w", path, err)
+	}
+	return fixtures, nil
+}
+
+// Replay registers fixtures on the server. Repeated requests to the same path are served in
+// the order they were recorded.
+func (s *Server) Replay(fixtures ...Fixture) {
+	byPath := map[string][]Response{}
+	var order []string
+	for _, fixture := range fixtures {
+		if _, ok := byPath[fixture.Path]; !ok {
+			order = append(order, fixture.Path)
+		}
+		byPath[fixture.Path] = append(byPath[fixture.Path], fixture.Response)
+	}
+	for _, path := range order {
+		s.Handle(path, byPath[path]...)
+	}
+}
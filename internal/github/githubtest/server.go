@@ -0,0 +1,407 @@
+// Package githubtest provides a fake GitHub REST API for tests. A Server serves canned
+// responses registered per request path, adds rate-limit headers, honors ETags, and records
+// every request it receives.
+package githubtest
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Response is a canned reply. A zero Status means 200. Body is sent as-is.
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body"`
+}
+
+// RateLimit is the state reported in X-RateLimit headers and by /rate_limit for one resource.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	// Body is the request payload, for write requests such as POST and PATCH.
+	Body string
+}
+
+// Server is an httptest server that imitates the GitHub REST API.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	routes    map[string][]Response
+	requests  []Request
+	core      RateLimit
+	search    RateLimit
+	withETags bool
+}
+
+// NewServer starts a fake GitHub API that is closed when the test ends. Unregistered paths
+// return 404 with GitHub's error body.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	s := &Server{
+		routes: map[string][]Response{},
+		core:   RateLimit{Limit: 5000, Remaining: 4999, Reset: reset},
+		search: RateLimit{Limit: 30, Remaining: 29, Reset: reset},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Handle registers responses for a path. The key may include a query string, such as
+// "/users/octocat/repos?page=2", to match only requests carrying those query values. When
+// several responses are given they are served in order and the last one repeats.
+func (s *Server) Handle(path string, responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[path] = append([]Response(nil), responses...)
+}
+
+// HandleJSON registers a 200 response whose body is value encoded as JSON.
+func (s *Server) HandleJSON(path string, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		panic(fmt.Sprintf("githubtest: encoding %s: %v", path, err))
+	}
+	s.Handle(path, Response{Body: string(body)})
+}
+
+// EnableETags makes every 200 response carry an ETag derived from its body and answers
+// matching If-None-Match requests with 304 Not Modified.
+func (s *Server) EnableETags() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.withETags = true
+}
+
+// SetRateLimit sets the core or search rate-limit state reported by the server.
+func (s *Server) SetRateLimit(resource string, limit RateLimit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if resource == "search" {
+		s.search = limit
+	} else {
+		s.core = limit
+	}
+}
+
+// Requests returns every request received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestCount returns how many requests were made to path, ignoring the query string.
+func (s *Server) RequestCount(path string) int {
+	count := 0
+	for _, req := range s.Requests() {
+		if req.Path == path {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Header: r.Header.Clone(), Body: string(body)})
+	limit := &s.core
+	if strings.HasPrefix(r.URL.Path, "/search/") {
+		limit = &s.search
+	}
+	if r.URL.Path != "/rate_limit" && limit.Remaining > 0 {
+		limit.Remaining--
+	}
+	current := *limit
+	resp, ok := s.match(r)
+	withETags := s.withETags
+	s.mu.Unlock()
+
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(current.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(current.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(current.Reset.Unix(), 10))
+
+	if !ok && r.URL.Path == "/rate_limit" {
+		resp, ok = s.rateLimitResponse(), true
+	}
+	if !ok {
+		resp = Response{Status: http.StatusNotFound, Body: `{"message":"Not Found","documentation_url":"https://docs.github.com/rest"}`}
+	}
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	for name, value := range resp.Headers {
+		w.Header().Set(name, value)
+	}
+	if withETags && status == http.StatusOK && w.Header().Get("ETag") == "" {
+		sum := sha1.Sum([]byte(resp.Body))
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+	}
+	if etag := w.Header().Get("ETag"); status == http.StatusOK && etag != "" && r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(resp.Body))
+}
+
+// match finds the most specific registered route for r and advances its response sequence.
+// The caller holds s.mu.
+func (s *Server) match(r *http.Request) (Response, bool) {
+	query := r.URL.Query()
+	bestKey, bestScore := "", -1
+	for key := range s.routes {
+		path, rawQuery, _ := strings.Cut(key, "?")
+		if path != r.URL.Path {
+			continue
+		}
+		want, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			continue
+		}
+		matched := true
+		for name := range want {
+			if query.Get(name) != want.Get(name) {
+				matched = false
+				break
+			}
+		}
+		if matched && len(want) > bestScore {
+			bestKey, bestScore = key, len(want)
+		}
+	}
+	if bestScore < 0 {
+		return Response{}, false
+	}
+	responses := s.routes[bestKey]
+	if len(responses) == 0 {
+		return Response{}, true
+	}
+	resp := responses[0]
+	if len(responses) > 1 {
+		s.routes[bestKey] = responses[1:]
+	}
+	return resp, true
+}
+
+func (s *Server) rateLimitResponse() Response {
+	resource := func(limit RateLimit) map[string]interface{} {
+		return map[string]interface{}{"limit": limit.Limit, "remaining": limit.Remaining, "reset": limit.Reset.Unix()}
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"resources": map[string]interface{}{"core": resource(s.core), "search": resource(s.search)},
+	})
+	return Response{Body: string(body)}
+}
+
+// Repo describes a repository returned by search and user repository listings.
+type Repo struct {
+	Owner         string
+	Name          string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Size          int
+	Stars         int
+	DefaultBranch string
+	Description   string
+	// Fork marks the repository as a fork, and PushedAt is served as pushed_at when non-zero.
+	Fork     bool
+	PushedAt time.Time
+	// ID is served as the numeric id, with node_id R_<ID>, when non-zero.
+	ID int64
+}
+
+func (r Repo) item() map[string]interface{} {
+	branch := r.DefaultBranch
+	if branch == "" {
+		branch = "main"
+	}
+	item := map[string]interface{}{
+		"name":             r.Name,
+		"full_name":        r.Owner + "/" + r.Name,
+		"created_at":       r.CreatedAt.UTC().Format(time.RFC3339),
+		"updated_at":       r.UpdatedAt.UTC().Format(time.RFC3339),
+		"size":             r.Size,
+		"stargazers_count": r.Stars,
+		"owner":            map[string]string{"login": r.Owner},
+		"default_branch":   branch,
+		"description":      r.Description,
+		"fork":             r.Fork,
+	}
+	if !r.PushedAt.IsZero() {
+		item["pushed_at"] = r.PushedAt.UTC().Format(time.RFC3339)
+	}
+	if r.ID != 0 {
+		item["id"], item["node_id"] = r.ID, fmt.Sprintf("R_%d", r.ID)
+	}
+	return item
+}
+
+// SetSearchResults serves repos from /search/repositories for any query, split into pages of
+// per_page items.
+func (s *Server) SetSearchResults(perPage int, repos ...Repo) {
+	pages := paginate(len(repos), perPage)
+	for page, bounds := range pages {
+		items := make([]map[string]interface{}, 0, bounds[1]-bounds[0])
+		for _, repo := range repos[bounds[0]:bounds[1]] {
+			items = append(items, repo.item())
+		}
+		s.HandleJSON(fmt.Sprintf("/search/repositories?page=%d", page+1), map[string]interface{}{"total_count": len(repos), "items": items})
+	}
+	s.HandleJSON("/search/repositories", map[string]interface{}{"total_count": len(repos), "items": []interface{}{}})
+}
+
+// SetUser serves a user profile.
+func (s *Server) SetUser(login string, createdAt time.Time) {
+	s.HandleJSON("/users/"+login, map[string]interface{}{"login": login, "created_at": createdAt.UTC().Format(time.RFC3339)})
+}
+
+// SetUserAccount serves a user profile with a numeric id and node_id U_<id>.
+func (s *Server) SetUserAccount(login string, id int64, createdAt time.Time) {
+	s.HandleJSON("/users/"+login, map[string]interface{}{"login": login, "id": id, "node_id": fmt.Sprintf("U_%d", id), "created_at": createdAt.UTC().Format(time.RFC3339)})
+}
+
+// SetUserRepos serves a user's repositories in pages of 100, as GitHub does for per_page=100.
+func (s *Server) SetUserRepos(login string, repos ...Repo) {
+	for page, bounds := range paginate(len(repos), 100) {
+		items := make([]map[string]interface{}, 0, bounds[1]-bounds[0])
+		for _, repo := range repos[bounds[0]:bounds[1]] {
+			items = append(items, repo.item())
+		}
+		s.HandleJSON(fmt.Sprintf("/users/%s/repos?page=%d", login, page+1), items)
+	}
+	s.HandleJSON("/users/"+login+"/repos", []interface{}{})
+}
+
+// SetUserEvents serves public events created at the given times in pages of 100, as GitHub
+// does for per_page=100.
+func (s *Server) SetUserEvents(login string, createdAt ...time.Time) {
+	for page, bounds := range paginate(len(createdAt), 100) {
+		events := make([]map[string]string, 0, bounds[1]-bounds[0])
+		for _, at := range createdAt[bounds[0]:bounds[1]] {
+			events = append(events, map[string]string{"type": "PushEvent", "created_at": at.UTC().Format(time.RFC3339)})
+		}
+		s.HandleJSON(fmt.Sprintf("/users/%s/events/public?page=%d", login, page+1), events)
+	}
+	s.HandleJSON("/users/"+login+"/events/public", []interface{}{})
+}
+
+// SetReadme serves a base64-encoded README.
+func (s *Server) SetReadme(owner, repo, content string) {
+	s.HandleJSON(fmt.Sprintf("/repos/%s/%s/readme", owner, repo), map[string]string{
+		"encoding": "base64",
+		"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+	})
+}
+
+// SetTree serves a recursive tree whose blobs are paths.
+func (s *Server) SetTree(owner, repo, branch string, paths ...string) {
+	tree := make([]map[string]string, 0, len(paths))
+	for _, path := range paths {
+		tree = append(tree, map[string]string{"path": path, "type": "blob"})
+	}
+	s.HandleJSON(fmt.Sprintf("/repos/%s/%s/git/trees/%s", owner, repo, branch), map[string]interface{}{"tree": tree})
+}
+
+// SetReleases serves a single release with the named assets.
+func (s *Server) SetReleases(owner, repo string, assetNames ...string) {
+	assets := make([]map[string]string, 0, len(assetNames))
+	for _, name := range assetNames {
+		assets = append(assets, map[string]string{"name": name})
+	}
+	s.HandleJSON(fmt.Sprintf("/repos/%s/%s/releases", owner, repo), []map[string]interface{}{{"assets": assets}})
+}
+
+// Asset is a release asset served by SetReleaseAssets. ID, Digest, and ContentType are served
+// when non-empty.
+type Asset struct {
+	ID            int64
+	Name          string
+	Size          int64
+	DownloadCount int
+	ContentType   string
+	Digest        string
+}
+
+// SetReleaseAssets serves a single release with the given assets.
+func (s *Server) SetReleaseAssets(owner, repo string, assets ...Asset) {
+	items := make([]map[string]interface{}, 0, len(assets))
+	for _, asset := range assets {
+		item := map[string]interface{}{"name": asset.Name, "size": asset.Size, "download_count": asset.DownloadCount}
+		if asset.Digest != "" {
+			item["digest"] = asset.Digest
+		}
+		if asset.ID != 0 {
+			item["id"] = asset.ID
+		}
+		if asset.ContentType != "" {
+			item["content_type"] = asset.ContentType
+		}
+		items = append(items, item)
+	}
+	s.HandleJSON(fmt.Sprintf("/repos/%s/%s/releases", owner, repo), []map[string]interface{}{{"assets": items}})
+}
+
+// SetStargazers serves stargazers with their starring times in pages of 100, keyed by login.
+func (s *Server) SetStargazers(owner, repo string, starredAt map[string]time.Time) {
+	logins := make([]string, 0, len(starredAt))
+	for login := range starredAt {
+		logins = append(logins, login)
+	}
+	sort.Strings(logins)
+	for page, bounds := range paginate(len(logins), 100) {
+		items := make([]map[string]interface{}, 0, bounds[1]-bounds[0])
+		for _, login := range logins[bounds[0]:bounds[1]] {
+			items = append(items, map[string]interface{}{
+				"starred_at": starredAt[login].UTC().Format(time.RFC3339),
+				"user":       map[string]string{"login": login},
+			})
+		}
+		s.HandleJSON(fmt.Sprintf("/repos/%s/%s/stargazers?page=%d", owner, repo, page+1), items)
+	}
+	s.HandleJSON(fmt.Sprintf("/repos/%s/%s/stargazers", owner, repo), []interface{}{})
+}
+
+// paginate splits n items into [start, end) pages of size perPage.
+func paginate(n, perPage int) [][2]int {
+	if perPage <= 0 {
+		perPage = 30
+	}
+	var pages [][2]int
+	for start := 0; start < n; start += perPage {
+		end := start + perPage
+		if end > n {
+			end = n
+		}
+		pages = append(pages, [2]int{start, end})
+	}
+	return pages
+}
@@ -0,0 +1,121 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Contribution sources selected with SetContributionSource.
+const (
+	// ContributionSourceEvents counts a user's public events from the last year. GitHub serves
+	// at most 300 events, so it undercounts very active accounts.
+	ContributionSourceEvents = "events"
+	// ContributionSourceGraphQL reads the yearly total of the user's contribution calendar.
+	ContributionSourceGraphQL = "graphql"
+)
+
+const contributionsQuery = `query($login: String!) {
+  user(login: $login) {
+    contributionsCollection {
+      contributionCalendar { totalContributions }
+    }
+  }
+}`
+
+// SetContributionSource selects how GetUserContributions counts: ContributionSourceEvents (the
+// default) or ContributionSourceGraphQL.
+func (c *Client) SetContributionSource(source string) {
+	c.contributionSource = source
+}
+
+// graphQLContributions reads a user's contributions over the last year from the GraphQL API.
+// Organizations have no contribution calendar and return an error.
+func (c *Client) graphQLContributions(ctx context.Context, username string) (int, error) {
+	var data struct {
+		User *struct {
+			ContributionsCollection struct {
+				ContributionCalendar struct {
+					TotalContributions int `json:"totalContributions"`
+				} `json:"contributionCalendar"`
+			} `json:"contributionsCollection"`
+		} `json:"user"`
+	}
+	cacheKey := fmt.Sprintf("contributions:%s", username)
+	if err := c.graphQL(ctx, contributionsQuery, map[string]interface{}{"login": username}, cacheKey, &data); err != nil {
+		return 0, fmt.Errorf("fetching contributions: %w", err)
+	}
+	if data.User == nil {
+		return 0, fmt.Errorf("fetching contributions: no user %s", username)
+	}
+	return data.User.ContributionsCollection.ContributionCalendar.TotalContributions, nil
+}
+
+// graphQLURL is the GraphQL endpoint next to the REST root: /graphql on api.github.com, and
+// /api/graphql for a GitHub Enterprise Server REST root ending in /api/v3.
+func (c *Client) graphQLURL() string {
+	return strings.TrimSuffix(c.baseURL, "/v3") + "/graphql"
+}
+
+// graphQL runs query and decodes its data into out, caching the data under cacheKey like get.
+// A response carrying GraphQL errors fails with their messages. GraphQL has its own point-based
+// rate limit, so its responses are not fed to the core rate limiter.
+func (c *Client) graphQL(ctx context.Context, query string, variables map[string]interface{}, cacheKey string, out interface{}) error {
+	if cached, found := c.apiCache.Get(cacheKey, c.cacheTTL); found {
+		c.cacheLog.Debug("Cache hit for %s", cacheKey)
+		return json.Unmarshal(cached, out)
+	}
+	c.cacheLog.Debug("Cache miss for %s, fetching from API", cacheKey)
+
+	payload, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.graphQLURL(), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{StatusCode: resp.StatusCode, Status: resp.Status, Body: strings.TrimSpace(string(responseBody))}
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(responseBody, &envelope); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	if len(envelope.Errors) > 0 {
+		messages := make([]string, 0, len(envelope.Errors))
+		for _, e := range envelope.Errors {
+			messages = append(messages, e.Message)
+		}
+		return errors.New(strings.Join(messages, "; "))
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	c.apiCache.Set(cacheKey, envelope.Data)
+	c.cacheLog.Debug("Cached response for '%s' (%d bytes)", cacheKey, len(envelope.Data))
+	return nil
+}
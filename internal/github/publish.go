@@ -0,0 +1,139 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Issue is a GitHub issue the findings were published to.
+type Issue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+	// PullRequest is set when the issues API returned a pull request.
+	PullRequest *struct{} `json:"pull_request,omitempty"`
+}
+
+// Gist is a GitHub gist the findings were published to.
+type Gist struct {
+	ID      string `json:"id"`
+	HTMLURL string `json:"html_url"`
+}
+
+// FindOpenIssue returns the most recently created open issue in owner/repo titled title. Only
+// the first 100 open issues are searched.
+func (c *Client) FindOpenIssue(ctx context.Context, owner, repo, title string) (Issue, bool, error) {
+	reqURL := fmt.Sprintf("%s/repos/%s/%s/issues?state=open&sort=created&direction=desc&per_page=100", c.baseURL, owner, repo)
+	var issues []Issue
+	if err := c.send(ctx, http.MethodGet, reqURL, nil, &issues); err != nil {
+		return Issue{}, false, fmt.Errorf("listing issues: %w", err)
+	}
+	for _, issue := range issues {
+		if issue.PullRequest == nil && issue.Title == title {
+			return issue, true, nil
+		}
+	}
+	return Issue{}, false, nil
+}
+
+// CreateIssue opens an issue in owner/repo.
+func (c *Client) CreateIssue(ctx context.Context, owner, repo, title, body string) (Issue, error) {
+	reqURL := fmt.Sprintf("%s/repos/%s/%s/issues", c.baseURL, owner, repo)
+	var issue Issue
+	if err := c.send(ctx, http.MethodPost, reqURL, map[string]string{"title": title, "body": body}, &issue); err != nil {
+		return Issue{}, fmt.Errorf("creating issue: %w", err)
+	}
+	return issue, nil
+}
+
+// UpdateIssue replaces the title and body of issue number in owner/repo.
+func (c *Client) UpdateIssue(ctx context.Context, owner, repo string, number int, title, body string) (Issue, error) {
+	reqURL := fmt.Sprintf("%s/repos/%s/%s/issues/%d", c.baseURL, owner, repo, number)
+	var issue Issue
+	if err := c.send(ctx, http.MethodPatch, reqURL, map[string]string{"title": title, "body": body}, &issue); err != nil {
+		return Issue{}, fmt.Errorf("updating issue %d: %w", number, err)
+	}
+	return issue, nil
+}
+
+// CreateGist creates a secret gist holding one file.
+func (c *Client) CreateGist(ctx context.Context, description, filename, content string) (Gist, error) {
+	var gist Gist
+	if err := c.send(ctx, http.MethodPost, c.baseURL+"/gists", gistPayload(description, filename, content, false), &gist); err != nil {
+		return Gist{}, fmt.Errorf("creating gist: %w", err)
+	}
+	return gist, nil
+}
+
+// UpdateGist replaces filename in gist id.
+func (c *Client) UpdateGist(ctx context.Context, id, description, filename, content string) (Gist, error) {
+	var gist Gist
+	if err := c.send(ctx, http.MethodPatch, c.baseURL+"/gists/"+id, gistPayload(description, filename, content, true), &gist); err != nil {
+		return Gist{}, fmt.Errorf("updating gist %s: %w", id, err)
+	}
+	return gist, nil
+}
+
+func gistPayload(description, filename, content string, update bool) map[string]interface{} {
+	payload := map[string]interface{}{
+		"description": description,
+		"files":       map[string]map[string]string{filename: {"content": content}},
+	}
+	if !update {
+		payload["public"] = false
+	}
+	return payload
+}
+
+// send makes an uncached request with an optional JSON payload and decodes the response into
+// out. Any non-2xx response is an *APIError.
+func (c *Client) send(ctx context.Context, method, reqURL string, payload, out interface{}) error {
+	if err := c.rateLimiter.CheckCoreRateLimit(ctx); err != nil {
+		return err
+	}
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+c.token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.rateLimiter.UpdateFromResponse(resp)
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{StatusCode: resp.StatusCode, Status: resp.Status, Body: strings.TrimSpace(string(responseBody))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(responseBody, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
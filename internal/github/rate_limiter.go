@@ -13,6 +13,34 @@ import (
 	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
 )
 
+// Rate-limit handling modes, set with Client.SetOnRateLimit.
+const (
+	// OnRateLimitWait blocks until the limit resets or the context ends.
+	OnRateLimitWait = "wait"
+	// OnRateLimitFail returns a *RateLimitError immediately so an outer loop can reschedule.
+	OnRateLimitFail = "fail"
+)
+
+// RateLimitError reports that a call was refused because the core or search rate limit is
+// exhausted. Reset is when the limit refills, or zero when only RetryAfter is known.
+type RateLimitError struct {
+	Resource   string
+	Remaining  int
+	Reset      time.Time
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	switch {
+	case !e.Reset.IsZero():
+		return fmt.Sprintf("%s rate limit exceeded (%d remaining), resets at %s", e.Resource, e.Remaining, e.Reset.Format(time.RFC3339))
+	case e.RetryAfter > 0:
+		return fmt.Sprintf("%s rate limit exceeded, retry after %s", e.Resource, e.RetryAfter)
+	default:
+		return fmt.Sprintf("%s rate limit exceeded, please retry later", e.Resource)
+	}
+}
+
 // RateLimiter handles GitHub API rate limiting
 type RateLimiter struct {
 	mutex             sync.Mutex
@@ -24,6 +52,7 @@ type RateLimiter struct {
 	searchLimitBuffer int // Buffer for search API (30/minute)
 	lastCheck         time.Time
 	checkInterval     time.Duration
+	failFast          bool // return a *RateLimitError instead of waiting for reset
 	logger            *logger.Logger
 }
 
@@ -44,6 +73,13 @@ func NewRateLimiter(buffer int, appLogger *logger.Logger) *RateLimiter {
 	}
 }
 
+// SetFailFast makes CheckRateLimit return a *RateLimitError instead of waiting for the reset.
+func (r *RateLimiter) SetFailFast(failFast bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.failFast = failFast
+}
+
 // UpdateFromResponse updates rate limit info from response headers
 func (r *RateLimiter) UpdateFromResponse(resp *http.Response) {
 	r.mutex.Lock()
@@ -105,6 +141,7 @@ func (r *RateLimiter) CheckRateLimit(ctx context.Context, apiType string) error
 		buffer = r.coreLimitBuffer
 		resetTime = r.coreReset
 	}
+	failFast := r.failFast
 	r.mutex.Unlock()
 
 	// Check if we're approaching the rate limit
@@ -120,6 +157,10 @@ func (r *RateLimiter) CheckRateLimit(ctx context.Context, apiType string) error
 		return nil
 	}
 
+	if failFast {
+		return &RateLimitError{Resource: apiType, Remaining: remaining, Reset: resetTime}
+	}
+
 	// We're approaching rate limit, calculate wait time
 	waitTime := time.Until(resetTime) + 5*time.Second
 
@@ -159,15 +200,17 @@ func (r *RateLimiter) CheckCoreRateLimit(ctx context.Context) error {
 	return r.CheckRateLimit(ctx, "core")
 }
 
-// FetchRateLimits explicitly gets current rate limit status
-func (r *RateLimiter) FetchRateLimits(ctx context.Context, token string) error {
+// FetchRateLimits explicitly gets current rate limit status from the API rooted at baseURL.
+func (r *RateLimiter) FetchRateLimits(ctx context.Context, client *http.Client, baseURL, token string) error {
 	// Only check rate limits at most once per check interval
-	if time.Since(r.lastCheck) < r.checkInterval {
+	r.mutex.Lock()
+	lastCheck := r.lastCheck
+	r.mutex.Unlock()
+	if time.Since(lastCheck) < r.checkInterval {
 		return nil
 	}
 
-	client := &http.Client{}
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.github.com/rate_limit", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/rate_limit", nil)
 	if err != nil {
 		return err
 	}
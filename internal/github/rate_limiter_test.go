@@ -32,3 +32,30 @@ func TestSleepWithContextHonorsCancellation(t *testing.T) {
 		t.Fatalf("expected context cancellation error, got %v", err)
 	}
 }
+
+func TestCheckRateLimitModes(t *testing.T) {
+	reset := time.Now().Add(time.Hour)
+
+	failing := NewRateLimiter(500, logger.New(false))
+	failing.SetFailFast(true)
+	failing.coreRemaining, failing.coreReset = 10, reset
+	err := failing.CheckCoreRateLimit(context.Background())
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) || rateErr.Resource != "core" || rateErr.Remaining != 10 || !rateErr.Reset.Equal(reset) {
+		t.Fatalf("fail mode error = %v, want core RateLimitError", err)
+	}
+
+	waiting := NewRateLimiter(500, logger.New(false))
+	waiting.coreRemaining, waiting.coreReset = 10, reset
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := waiting.CheckCoreRateLimit(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wait mode error = %v, want it to block until the context ends", err)
+	}
+
+	// Above the buffer neither mode refuses.
+	failing.coreRemaining = 4000
+	if err := failing.CheckCoreRateLimit(context.Background()); err != nil {
+		t.Fatalf("fail mode with quota left error = %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+// Package legacy imports the flat-file records written by earlier versions of the watchdog
+// into the SQLite database.
+package legacy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+)
+
+// ImportHeuristic names the flag recorded on every suspicious entity created from a legacy file.
+const ImportHeuristic = "LegacyImportHeuristic"
+
+// ImportFlag is recorded on every suspicious entity created from a legacy file.
+const ImportFlag = "Other Suspicious Patterns:" + ImportHeuristic
+
+// fileKind describes how one legacy file maps onto database rows.
+type fileKind struct {
+	entityType string
+	suspicious bool
+	malicious  bool
+}
+
+// legacyFiles lists the recognized file names.
+var legacyFiles = map[string]fileKind{
+	"processed_repos.txt":      {entityType: "repo"},
+	"suspicious_repos.txt":     {entityType: "repo", suspicious: true},
+	"malicious_repos.txt":      {entityType: "repo", suspicious: true, malicious: true},
+	"processed_users.txt":      {entityType: "user"},
+	"suspicious_users.txt":     {entityType: "user", suspicious: true},
+	"malicious_stargazers.txt": {entityType: "user", suspicious: true},
+}
+
+// FileSummary counts what happened to the entries of one file. Inserted entries created new
+// rows, duplicates were already in the database or repeated in the file, and skipped lines
+// could not be parsed.
+type FileSummary struct {
+	Path       string `json:"path"`
+	EntityType string `json:"entity_type"`
+	Inserted   int    `json:"inserted"`
+	Duplicates int    `json:"duplicates"`
+	Skipped    int    `json:"skipped"`
+}
+
+// Summary is the outcome of an import.
+type Summary struct {
+	Dir        string        `json:"dir"`
+	Files      []FileSummary `json:"files"`
+	Inserted   int           `json:"inserted"`
+	Duplicates int           `json:"duplicates"`
+	Skipped    int           `json:"skipped"`
+}
+
+// Import walks dir for recognized legacy files and inserts their entries, most severe files
+// first. Entities already in the database are left untouched, so running the import again
+// changes nothing.
+func Import(database *db.Database, dir string) (Summary, error) {
+	paths, err := findFiles(dir)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	summary := Summary{Dir: dir, Files: []FileSummary{}}
+	for _, path := range paths {
+		result, err := importFile(database, path, legacyFiles[filepath.Base(path)])
+		if err != nil {
+			return summary, err
+		}
+		summary.Files = append(summary.Files, result)
+		summary.Inserted += result.Inserted
+		summary.Duplicates += result.Duplicates
+		summary.Skipped += result.Skipped
+	}
+	return summary, nil
+}
+
+func findFiles(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if _, ok := legacyFiles[entry.Name()]; ok {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("searching %s for legacy files: %w", dir, err)
+	}
+	// Most severe files go first: existing rows are never changed, so an entity listed as both
+	// processed and malicious must be inserted from the malicious file.
+	sort.Slice(paths, func(i, j int) bool {
+		ri, rj := legacyFiles[filepath.Base(paths[i])].rank(), legacyFiles[filepath.Base(paths[j])].rank()
+		if ri != rj {
+			return ri < rj
+		}
+		return paths[i] < paths[j]
+	})
+	return paths, nil
+}
+
+func (k fileKind) rank() int {
+	switch {
+	case k.malicious:
+		return 0
+	case k.suspicious:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func importFile(database *db.Database, path string, kind fileKind) (FileSummary, error) {
+	result := FileSummary{Path: path, EntityType: kind.entityType}
+	file, err := os.Open(path)
+	if err != nil {
+		return result, fmt.Errorf("opening legacy file: %w", err)
+	}
+	defer file.Close()
+
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, err := parseEntry(line, kind.entityType)
+		if err != nil {
+			result.Skipped++
+			continue
+		}
+		if seen[strings.ToLower(id)] {
+			result.Duplicates++
+			continue
+		}
+		seen[strings.ToLower(id)] = true
+
+		inserted, err := insertEntry(database, id, kind, filepath.Base(path))
+		if err != nil {
+			return result, err
+		}
+		if inserted {
+			result.Inserted++
+		} else {
+			result.Duplicates++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("reading legacy file %s: %w", path, err)
+	}
+	return result, nil
+}
+
+func insertEntry(database *db.Database, id string, kind fileKind, fileName string) (bool, error) {
+	var inserted bool
+	var err error
+	if kind.entityType == "repo" {
+		owner, name, _ := strings.Cut(id, "/")
+		inserted, err = database.InsertProcessedRepoIfAbsent(id, owner, name, kind.malicious)
+	} else {
+		inserted, err = database.InsertProcessedUserIfAbsent(id, kind.suspicious)
+	}
+	if err != nil || !inserted || !kind.suspicious {
+		return inserted, err
+	}
+	message := fmt.Sprintf("Imported from legacy file %s.", fileName)
+	if err := database.InsertHeuristicFlag(kind.entityType, id, ImportHeuristic, ImportFlag, message); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// parseEntry extracts a repository (owner/name) or username from a legacy line. Lines may be
+// bare identifiers, GitHub URLs, or Markdown list links, optionally followed by other fields.
+func parseEntry(line, entityType string) (string, error) {
+	if start := strings.Index(line, "]("); start >= 0 {
+		if end := strings.Index(line[start:], ")"); end > 0 {
+			line = line[start+2 : start+end]
+		}
+	}
+	fields := strings.FieldsFunc(line, func(r rune) bool { return r == ' ' || r == '\t' || r == ',' })
+	if len(fields) == 0 {
+		return "", errors.New("empty entry")
+	}
+	value := fields[0]
+	for _, prefix := range []string{"https://", "http://", "github.com/", "www.github.com/"} {
+		value = strings.TrimPrefix(value, prefix)
+	}
+	value = strings.Trim(value, "/")
+
+	parts := strings.Split(value, "/")
+	for _, part := range parts {
+		if part == "" {
+			return "", fmt.Errorf("invalid entry %q", line)
+		}
+	}
+	switch {
+	case entityType == "repo" && len(parts) >= 2:
+		return parts[0] + "/" + strings.TrimSuffix(parts[1], ".git"), nil
+	case entityType == "user" && len(parts) == 1:
+		return parts[0], nil
+	default:
+		return "", fmt.Errorf("invalid %s entry %q", entityType, line)
+	}
+}
@@ -0,0 +1,93 @@
+package legacy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func TestImportIsIdempotent(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "processed_repos.txt"), "octocat/hello-world\nevil/loader\n")
+	writeFile(t, filepath.Join(dir, "spike-research", "malicious_repos.txt"), "https://github.com/evil/loader\nnot a repo\n")
+	writeFile(t, filepath.Join(dir, "suspicious_users.txt"), "1. [farmer](https://github.com/farmer)\nfarmer\n\n# comment\n")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "ignored/repo\n")
+
+	database, err := db.New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	defer database.Close()
+
+	first, err := Import(database, dir)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(first.Files) != 3 || first.Inserted != 3 || first.Duplicates != 2 || first.Skipped != 1 {
+		t.Fatalf("Import() = %+v, want 3 files with 3 inserted, 2 duplicates, 1 skipped", first)
+	}
+
+	malicious, err := database.ListMaliciousRepos()
+	if err != nil {
+		t.Fatalf("ListMaliciousRepos() error = %v", err)
+	}
+	if len(malicious) != 1 || malicious[0].RepoID != "evil/loader" {
+		t.Fatalf("ListMaliciousRepos() = %+v, want evil/loader from the malicious file", malicious)
+	}
+
+	users, err := database.ListSuspiciousUsers()
+	if err != nil {
+		t.Fatalf("ListSuspiciousUsers() error = %v", err)
+	}
+	if len(users) != 1 || users[0].Username != "farmer" {
+		t.Fatalf("ListSuspiciousUsers() = %+v, want farmer", users)
+	}
+	flags, err := database.ListHeuristicFlags("user", "farmer")
+	if err != nil {
+		t.Fatalf("ListHeuristicFlags() error = %v", err)
+	}
+	if len(flags) != 1 || flags[0].Flag != ImportFlag {
+		t.Fatalf("ListHeuristicFlags() = %+v, want legacy import flag", flags)
+	}
+
+	second, err := Import(database, dir)
+	if err != nil {
+		t.Fatalf("Import() second run error = %v", err)
+	}
+	if second.Inserted != 0 || second.Duplicates != 5 || second.Skipped != 1 {
+		t.Fatalf("Import() second run = %+v, want no inserts", second)
+	}
+}
+
+func TestParseEntry(t *testing.T) {
+	tests := []struct {
+		line, entityType, want string
+		wantErr                bool
+	}{
+		{"owner/repo", "repo", "owner/repo", false},
+		{"https://github.com/owner/repo.git", "repo", "owner/repo", false},
+		{"1. [owner/repo](https://github.com/owner/repo)", "repo", "owner/repo", false},
+		{"owner/repo,2024-01-01", "repo", "owner/repo", false},
+		{"https://www.github.com/someone", "user", "someone", false},
+		{"someone", "repo", "", true},
+		{"owner/repo", "user", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseEntry(tt.line, tt.entityType)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Fatalf("parseEntry(%q, %q) = %q, %v, want %q, error %v", tt.line, tt.entityType, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
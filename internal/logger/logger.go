@@ -2,61 +2,152 @@
 package logger
 
 import (
+	"fmt"
 	"log"
+	"strings"
 )
 
+// Level is the lowest message severity a subsystem logs.
+type Level int
+
+// Log levels, from most to least verbose.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// ParseLevel parses debug, info, warn, or error.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return 0, fmt.Errorf("log level must be debug, info, warn, or error, got %q", name)
+}
+
 // Logger is a custom logger with verbosity control
 type Logger struct {
-	verbose bool
-	quiet   bool
+	// level is the lowest severity logged by subsystems not listed in levels.
+	level Level
+	quiet bool
+	// subsystem tags messages and selects an entry of levels.
+	subsystem string
+	// levels overrides level for the subsystems it lists.
+	levels map[string]Level
 }
 
-// New creates a new logger with verbosity control
+// New creates a new logger that logs debug messages when verbose and info messages otherwise.
 func New(verbose bool) *Logger {
+	return NewWithLevel(verboseLevel(verbose))
+}
+
+// NewWithLevel creates a logger that drops messages below level.
+func NewWithLevel(level Level) *Logger {
 	return &Logger{
-		verbose: verbose,
+		level: level,
 	}
 }
 
 // NewWithQuiet creates a logger that can suppress informational output.
 func NewWithQuiet(verbose, quiet bool) *Logger {
 	return &Logger{
-		verbose: verbose,
-		quiet:   quiet,
+		level: verboseLevel(verbose),
+		quiet: quiet,
+	}
+}
+
+func verboseLevel(verbose bool) Level {
+	if verbose {
+		return LevelDebug
+	}
+	return LevelInfo
+}
+
+// WithLevel returns a copy of the logger that drops messages below level, replacing the level
+// selected by verbose. Subsystems listed by WithLevels keep their own level.
+func (l *Logger) WithLevel(level Level) *Logger {
+	leveled := *l
+	leveled.level = level
+	return &leveled
+}
+
+// WithLevels returns a copy of the logger that logs the listed subsystems at their own level.
+// Subsystems not listed keep the logger's level.
+func (l *Logger) WithLevels(levels map[string]Level) *Logger {
+	tagged := *l
+	tagged.levels = levels
+	return &tagged
+}
+
+// For returns a copy of the logger that tags messages with subsystem, such as github or
+// analyzer, and honors that subsystem's level.
+func (l *Logger) For(subsystem string) *Logger {
+	tagged := *l
+	tagged.subsystem = subsystem
+	return &tagged
+}
+
+// Enabled reports whether a message at level would be logged.
+func (l *Logger) Enabled(level Level) bool {
+	if min, ok := l.levels[l.subsystem]; ok && l.subsystem != "" {
+		return level >= min
 	}
+	return level >= l.level
 }
 
-// Info logs informational messages that are always shown
+func (l *Logger) printf(prefix, format string, v ...interface{}) {
+	if l.subsystem != "" {
+		prefix += "[" + l.subsystem + "] "
+	}
+	log.Printf(prefix+format, v...)
+}
+
+// Info logs informational messages unless quiet or below the logger's or the subsystem's level
 func (l *Logger) Info(format string, v ...interface{}) {
-	if l.quiet {
+	if l.quiet || !l.Enabled(LevelInfo) {
 		return
 	}
-	log.Printf(format, v...)
+	l.printf("", format, v...)
 }
 
-// Debug logs debug messages only when verbose mode is enabled
+// Debug logs debug messages when the logger's or the subsystem's level enables them
 func (l *Logger) Debug(format string, v ...interface{}) {
-	if l.verbose {
-		log.Printf("[DEBUG] "+format, v...)
+	if l.Enabled(LevelDebug) {
+		l.printf("[DEBUG] ", format, v...)
 	}
 }
 
-// Error logs error messages
+// Error logs error messages unless below the logger's or the subsystem's level
 func (l *Logger) Error(format string, v ...interface{}) {
-	log.Printf("[ERROR] "+format, v...)
+	if l.Enabled(LevelError) {
+		l.printf("[ERROR] ", format, v...)
+	}
 }
 
-// Warn logs warning messages
+// Warn logs warning messages unless below the logger's or the subsystem's level
 func (l *Logger) Warn(format string, v ...interface{}) {
-	log.Printf("[WARN] "+format, v...)
+	if l.Enabled(LevelWarn) {
+		l.printf("[WARN] ", format, v...)
+	}
 }
 
 // Fatal logs an error message and then exits the program
 func (l *Logger) Fatal(format string, v ...interface{}) {
+	if l.subsystem != "" {
+		format = "[" + l.subsystem + "] " + format
+	}
 	log.Fatalf("[FATAL] "+format, v...)
 }
 
 // IsVerbose returns whether verbose logging is enabled
 func (l *Logger) IsVerbose() bool {
-	return l.verbose
+	return l.Enabled(LevelDebug)
 }
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	out, flags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(out)
+		log.SetFlags(flags)
+	})
+	return &buf
+}
+
+func TestSubsystemLevelsOverrideVerbose(t *testing.T) {
+	buf := captureLog(t)
+	base := New(false).WithLevels(map[string]Level{"github": LevelDebug, "db": LevelWarn})
+
+	base.For("github").Debug("cache miss")
+	base.For("analyzer").Debug("analyzing")
+	base.For("db").Info("opened")
+	base.For("db").Warn("slow query")
+	base.Debug("untagged")
+
+	got := buf.String()
+	if !strings.Contains(got, "[DEBUG] [github] cache miss") {
+		t.Fatalf("log = %q, want github debug output", got)
+	}
+	if !strings.Contains(got, "[WARN] [db] slow query") {
+		t.Fatalf("log = %q, want db warning", got)
+	}
+	for _, unwanted := range []string{"analyzing", "opened", "untagged"} {
+		if strings.Contains(got, unwanted) {
+			t.Fatalf("log = %q, want %q suppressed", got, unwanted)
+		}
+	}
+}
+
+func TestUnlistedSubsystemsFollowVerbose(t *testing.T) {
+	buf := captureLog(t)
+	verbose := New(true).WithLevels(map[string]Level{"github": LevelError})
+
+	verbose.For("analyzer").Debug("analyzing")
+	verbose.For("github").Warn("rate limit low")
+
+	got := buf.String()
+	if !strings.Contains(got, "[DEBUG] [analyzer] analyzing") || strings.Contains(got, "rate limit low") {
+		t.Fatalf("log = %q, want analyzer debug shown and github warning suppressed", got)
+	}
+}
+
+func TestMessagesBelowLevelAreDropped(t *testing.T) {
+	buf := captureLog(t)
+	quiet := NewWithLevel(LevelError)
+
+	quiet.Debug("tracing")
+	quiet.Info("scanning")
+	quiet.Warn("rate limit low")
+	quiet.Error("request failed")
+
+	if got := buf.String(); got != "[ERROR] request failed\n" {
+		t.Fatalf("log = %q, want only the error", got)
+	}
+}
+
+func TestNewMapsVerboseToLevel(t *testing.T) {
+	for _, tc := range []struct {
+		verbose bool
+		want    Level
+	}{{true, LevelDebug}, {false, LevelInfo}} {
+		if got := New(tc.verbose).level; got != tc.want {
+			t.Fatalf("New(%v) level = %v, want %v", tc.verbose, got, tc.want)
+		}
+	}
+
+	buf := captureLog(t)
+	base := New(true).WithLevel(LevelWarn).WithLevels(map[string]Level{"github": LevelDebug})
+	base.For("analyzer").Info("analyzing")
+	base.For("github").Debug("cache miss")
+	if got := buf.String(); got != "[DEBUG] [github] cache miss\n" {
+		t.Fatalf("log = %q, want the level to replace verbose outside listed subsystems", got)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]Level{"debug": LevelDebug, "INFO": LevelInfo, "warn": LevelWarn, "error": LevelError} {
+		if got, err := ParseLevel(name); err != nil || got != want {
+			t.Fatalf("ParseLevel(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("trace"); err == nil {
+		t.Fatal("ParseLevel(trace) error = nil, want error")
+	}
+}
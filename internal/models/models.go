@@ -2,27 +2,58 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
 // RepoItem represents a repository from GitHub's REST API
 type RepoItem struct {
+	ID              int64     `json:"id"`
+	NodeID          string    `json:"node_id"`
 	Name            string    `json:"name"`
 	FullName        string    `json:"full_name"`
+	Description     string    `json:"description"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 	Size            int       `json:"size"`
 	StargazersCount int       `json:"stargazers_count"`
 	Owner           struct {
+		ID    int64  `json:"id"`
 		Login string `json:"login"`
 	} `json:"owner"`
 	DefaultBranch string `json:"default_branch"`
 }
 
+// UserInfo is a GitHub account profile. ID and NodeID stay with the account across renames,
+// and a re-registered login gets new ones.
+type UserInfo struct {
+	ID        int64     `json:"id"`
+	NodeID    string    `json:"node_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Followers int       `json:"followers"`
+	Following int       `json:"following"`
+	// Type is OwnerTypeUser or OwnerTypeOrganization.
+	Type string `json:"type,omitempty"`
+	// Bio, Blog, and TwitterUsername are the free-text profile fields; empty when unset.
+	Bio             string `json:"bio,omitempty"`
+	Blog            string `json:"blog,omitempty"`
+	TwitterUsername string `json:"twitter_username,omitempty"`
+	// AvatarURL is the account's avatar image.
+	AvatarURL string `json:"avatar_url,omitempty"`
+}
+
+// Account types GitHub reports for repository owners.
+const (
+	OwnerTypeUser         = "User"
+	OwnerTypeOrganization = "Organization"
+)
+
 // SearchResult represents the result of a GitHub search API call
 type SearchResult struct {
-	TotalCount int        `json:"total_count"`
-	Items      []RepoItem `json:"items"`
+	TotalCount int `json:"total_count"`
+	// IncompleteResults is set when the search timed out on GitHub and Items is partial.
+	IncompleteResults bool       `json:"incomplete_results"`
+	Items             []RepoItem `json:"items"`
 }
 
 // Repo represents repository data for internal processing
@@ -32,23 +63,103 @@ type Repo struct {
 	UpdatedAt      time.Time
 	DiskUsage      int
 	StargazerCount int
+	Description    string
 }
 
 // RepoData represents repository data for malicious checks
 type RepoData struct {
+	ID             int64
 	Owner          string
 	Name           string
 	Readme         string
 	TreeEntries    []string
 	DiskUsage      int
 	StargazerCount int
+	// Description is the one-line description from the search result, read by the
+	// description heuristic without an extra request.
+	Description string
+	// Fork and PushedAt are set for the repositories of an analyzed user.
+	Fork     bool
+	PushedAt time.Time
+	// Topics are the repository's GitHub topics, and Languages its bytes of code per language.
+	Topics    []string
+	Languages map[string]int
 }
 
 // UserData represents user data for analysis
 type UserData struct {
+	Username      string
+	GitHubID      int64
+	NodeID        string
 	CreatedAt     time.Time
 	Contributions int
 	Repositories  []RepoData
+	// Followers and Following are the account's follower and followed-account counts.
+	Followers int
+	Following int
+	// OwnerType is OwnerTypeUser or OwnerTypeOrganization; empty when unknown.
+	OwnerType string
+	// Bio, Blog, and TwitterUsername are the account's profile fields, and ProfileReadme the
+	// README of its login/login profile repository, when it has one.
+	Bio             string
+	Blog            string
+	TwitterUsername string
+	ProfileReadme   string
+	// AvatarHash is the perceptual hash of the account's avatar in hex, empty when it was not
+	// computed or the avatar is a default identicon. AvatarMatches are the flagged accounts
+	// whose stored avatar hash is close to it.
+	AvatarHash    string
+	AvatarMatches []string
+	// CommitSampled is how many repositories had their commit history sampled, and
+	// SingleCommitRepos how many of those have at most one commit.
+	CommitSampled     int
+	SingleCommitRepos int
+}
+
+// IsOrganization reports whether the account is an organization rather than a person, so its
+// creation date and contributions say little about who runs it.
+func (d UserData) IsOrganization() bool {
+	return d.OwnerType == OwnerTypeOrganization
+}
+
+// Stargazer represents an account that starred a repository
+type Stargazer struct {
+	Login     string
+	StarredAt time.Time
+}
+
+// ReleaseAsset is a file attached to a release. Digest is GitHub's "sha256:<hex>" content
+// digest, which assets uploaded before GitHub started computing digests lack. Hash is the
+// identity the analyzer correlates assets by.
+type ReleaseAsset struct {
+	ID            int64  `json:"id,omitempty"`
+	Name          string `json:"name"`
+	Size          int64  `json:"size"`
+	DownloadCount int    `json:"download_count"`
+	ContentType   string `json:"content_type,omitempty"`
+	Digest        string `json:"digest,omitempty"`
+	Hash          string `json:"hash,omitempty"`
+}
+
+// FlaggedAsset is a release asset the release check flagged, with why.
+type FlaggedAsset struct {
+	ReleaseAsset
+	Reasons []string `json:"reasons"`
+}
+
+// CommitFile is a file touched by a commit. Status is GitHub's added, removed, modified, or renamed.
+type CommitFile struct {
+	Filename string
+	Status   string
+}
+
+// Commit is a commit with its message and the files it changed. Listings may leave either out.
+type Commit struct {
+	SHA     string
+	Message string
+	// Date is the author date, which rebases and cherry-picks keep. It is zero when unknown.
+	Date  time.Time
+	Files []CommitFile
 }
 
 // RepoMetrics represents repository metrics for a user
@@ -56,23 +167,122 @@ type RepoMetrics struct {
 	Name           string
 	DiskUsage      int
 	StargazerCount int
+	// Fork is set when the repository is a fork, and PushedAt is its latest push. A fork starts
+	// with the pushed_at of its parent.
+	Fork     bool
+	PushedAt time.Time
 }
 
 // AnalysisResult represents the result of analyzing a user
 type AnalysisResult struct {
+	GitHubID             int64
+	NodeID               string
 	CreatedAt            time.Time
 	Suspicious           bool
+	InsufficientEvidence bool // heuristics flagged but fell short of the evidence policy
 	TotalStars           int
 	EmptyCount           int
 	SuspiciousEmptyCount int
 	Contributions        int
+	Followers            int
+	Following            int
+	ForkCount            int     // repos that are forks
+	TotalScore           float64 // weighted sum of the user heuristic scores
+	TemplateUniformity   float64 // share of repos following the dominant sequential naming template
+	CommitSampled        int     // repos whose commit history was sampled
+	SingleCommitFraction float64 // share of sampled repos with no commits after the initial import
+	Tier                 string  // low, medium, or high by how many tier heuristics flagged; empty when none did
+	AvatarHash           string  // perceptual hash of the avatar in hex; empty when not computed
+	OwnerType            string  // User or Organization; empty when unknown
+	Allowlisted          bool    // cleared by an operator; nothing else is filled in
 	HeuristicResults     []HeuristicResult
 }
 
+// Checker severities, from least to most severe.
+const (
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+// SeverityRank orders severities from 1 (low) to 3 (high). Unknown values rank 0.
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityLow:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityHigh:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// CheckerResult is the outcome of one repository checker. Unflagged results may still carry
+// evidence of a near miss.
+type CheckerResult struct {
+	Name     string `json:"name"`
+	Flagged  bool   `json:"flagged"`
+	Severity string `json:"severity"`
+	Evidence string `json:"evidence,omitempty"`
+}
+
 // HeuristicResult represents the result of a single heuristic check
 type HeuristicResult struct {
 	Category    string
 	Flag        bool
 	Name        string
 	Description string
+	// Score is how strongly a user heuristic matched, from 0 to 1, and Weight how much that
+	// counts toward the user's total score. A flag without a score counts as a full match, and
+	// a missing weight as 1, so heuristics can contribute to the total without flagging.
+	Score  float64 `json:",omitempty"`
+	Weight float64 `json:",omitempty"`
+}
+
+// Taxonomy categories group flags for triage. Category labels are free text chosen by each
+// heuristic, so Taxonomy maps them, and a few heuristic names, onto this fixed set.
+const (
+	TaxonomySpam                = "spam"
+	TaxonomyMalwareDistribution = "malware_distribution"
+	TaxonomyPhishing            = "phishing"
+	TaxonomyStarAbuse           = "star_abuse"
+	TaxonomyMassRepoCreation    = "mass_repo_creation"
+	TaxonomyAutomation          = "automation"
+	TaxonomyUncategorized       = "uncategorized"
+)
+
+// starAbuseHeuristics report star manipulation under the Automated Activity label.
+var starAbuseHeuristics = map[string]bool{
+	"lonestargazerheuristic": true,
+	"starfarmheuristic":      true,
+	"starringringheuristic":  true,
+}
+
+// Taxonomy returns the taxonomy category of a flag with the given Category label and heuristic
+// name, or TaxonomyUncategorized for labels it does not know.
+func Taxonomy(category, name string) string {
+	if starAbuseHeuristics[strings.ToLower(strings.TrimSpace(name))] {
+		return TaxonomyStarAbuse
+	}
+	switch strings.ToLower(strings.TrimSpace(category)) {
+	case "malware":
+		return TaxonomyMalwareDistribution
+	case "phishing":
+		return TaxonomyPhishing
+	case "spam behavior", "scam":
+		return TaxonomySpam
+	case "mass repository creation":
+		return TaxonomyMassRepoCreation
+	case "automated activity":
+		return TaxonomyAutomation
+	default:
+		return TaxonomyUncategorized
+	}
+}
+
+// Taxonomy returns the taxonomy category of the result.
+func (r HeuristicResult) Taxonomy() string {
+	return Taxonomy(r.Category, r.Name)
 }
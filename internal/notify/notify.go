@@ -0,0 +1,138 @@
+// Package notify alerts external systems when a scan flags a user or repository.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
+)
+
+const (
+	// webhookQueueSize is how many events wait for delivery before new ones are dropped.
+	webhookQueueSize = 100
+	// webhookTimeout bounds each delivery.
+	webhookTimeout = 10 * time.Second
+)
+
+// Event reports the heuristics newly triggered for one user or repository.
+type Event struct {
+	// EntityType is user or repo.
+	EntityType string      `json:"entity_type"`
+	EntityID   string      `json:"entity_id"`
+	Heuristics []Heuristic `json:"heuristics"`
+	Timestamp  time.Time   `json:"timestamp"`
+}
+
+// Heuristic is one triggered flag.
+type Heuristic struct {
+	Name        string `json:"name"`
+	Category    string `json:"category"`
+	Description string `json:"description,omitempty"`
+}
+
+// Notifier delivers events. Notify must not block the scan, and Close flushes the events
+// still pending until ctx is done.
+type Notifier interface {
+	Notify(event Event)
+	Close(ctx context.Context) error
+}
+
+// WebhookNotifier POSTs each event as JSON to a URL from a background goroutine. Events are
+// queued, and dropped with a warning when the queue is full; failed deliveries are logged and
+// not retried.
+type WebhookNotifier struct {
+	url        string
+	httpClient *http.Client
+	logger     *logger.Logger
+
+	mu     sync.Mutex
+	closed bool
+	queue  chan Event
+	done   chan struct{}
+}
+
+// NewWebhookNotifier starts a notifier posting to url.
+func NewWebhookNotifier(url string, appLogger *logger.Logger) *WebhookNotifier {
+	if appLogger == nil {
+		appLogger = logger.New(false)
+	}
+	n := &WebhookNotifier{
+		url:        url,
+		httpClient: &http.Client{Timeout: webhookTimeout},
+		logger:     appLogger.For("notify"),
+		queue:      make(chan Event, webhookQueueSize),
+		done:       make(chan struct{}),
+	}
+	go n.run()
+	return n
+}
+
+// Notify queues event for delivery without waiting for it.
+func (n *WebhookNotifier) Notify(event Event) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		n.logger.Warn("Dropping notification for %s %s: notifier closed", event.EntityType, event.EntityID)
+		return
+	}
+	select {
+	case n.queue <- event:
+	default:
+		n.logger.Warn("Dropping notification for %s %s: %d notifications pending", event.EntityType, event.EntityID, webhookQueueSize)
+	}
+}
+
+// Close stops accepting events and waits until the queued ones are delivered or ctx is done.
+func (n *WebhookNotifier) Close(ctx context.Context) error {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mu.Unlock()
+	select {
+	case <-n.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("flushing notifications: %w", ctx.Err())
+	}
+}
+
+func (n *WebhookNotifier) run() {
+	defer close(n.done)
+	for event := range n.queue {
+		if err := n.deliver(event); err != nil {
+			n.logger.Warn("Notification for %s %s failed: %v", event.EntityType, event.EntityID, err)
+		}
+	}
+}
+
+func (n *WebhookNotifier) deliver(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding notification: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	n.logger.Debug("Notified webhook of %s %s", event.EntityType, event.EntityID)
+	return nil
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
+)
+
+func TestWebhookNotifierPostsEventsWithoutBlocking(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan map[string]interface{}, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request %s with Content-Type %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("payload %s is not JSON: %v", body, err)
+		}
+		received <- payload
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, logger.New(false))
+	timestamp := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	start := time.Now()
+	for _, id := range []string{"mallory", "octo/loader", "eve"} {
+		notifier.Notify(Event{
+			EntityType: "user",
+			EntityID:   id,
+			Heuristics: []Heuristic{{Name: "RecentHeuristic", Category: "Spam Behavior", Description: "User is recent."}},
+			Timestamp:  timestamp,
+		})
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("Notify() blocked for %v while the webhook was stalled", elapsed)
+	}
+	select {
+	case payload := <-received:
+		t.Fatalf("webhook answered before it was released: %v", payload)
+	default:
+	}
+
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := notifier.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if len(received) != 3 {
+		t.Fatalf("webhook received %d events, want all 3 flushed by Close()", len(received))
+	}
+	payload := <-received
+	want := map[string]interface{}{
+		"entity_type": "user",
+		"entity_id":   "mallory",
+		"timestamp":   "2026-03-04T05:06:07Z",
+		"heuristics": []interface{}{map[string]interface{}{
+			"name": "RecentHeuristic", "category": "Spam Behavior", "description": "User is recent.",
+		}},
+	}
+	got, _ := json.Marshal(payload)
+	wantJSON, _ := json.Marshal(want)
+	if string(got) != string(wantJSON) {
+		t.Fatalf("payload = %s, want %s", got, wantJSON)
+	}
+
+	notifier.Notify(Event{EntityType: "repo", EntityID: "octo/late"})
+	if len(received) != 2 {
+		t.Fatal("notifier delivered an event after Close()")
+	}
+}
+
+func TestWebhookNotifierCloseHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-release }))
+	defer server.Close()
+	defer close(release)
+
+	notifier := NewWebhookNotifier(server.URL, logger.New(false))
+	notifier.Notify(Event{EntityType: "repo", EntityID: "octo/loader"})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := notifier.Close(ctx); err == nil {
+		t.Fatal("Close() returned before the stalled delivery finished")
+	}
+}
@@ -0,0 +1,201 @@
+// Package report renders persisted scan results into human-facing reports.
+package report
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+)
+
+// DefaultAbuseTemplate is the built-in text for GitHub's report-abuse form.
+const DefaultAbuseTemplate = `Violation: {{join .Violations "; "}}
+
+Reported {{.EntityType}}: {{.URL}}
+
+The {{.EntityType}} {{.EntityID}} appears to violate the GitHub Acceptable Use Policies ({{join .Violations "; "}}).
+
+Evidence:
+{{range .Evidence}}- {{.}}
+{{end}}
+Scan history:
+- First flagged: {{date .FirstFlagged}}
+- Last analyzed: {{date .LastAnalyzed}}
+
+Please review the {{.EntityType}} and remove the violating content.
+`
+
+// policyLanguage maps flag categories to GitHub Acceptable Use Policies wording.
+var policyLanguage = map[string]string{
+	"Malware":                   "Active malware or exploits",
+	"Phishing":                  "Active malware or exploits (phishing links)",
+	"Mass Repository Creation":  "Inauthentic activity and platform manipulation (automated bulk repository creation)",
+	"Automated Activity":        "Inauthentic activity and platform manipulation (automated excessive bulk activity)",
+	"Spam Behavior":             "Spam",
+	"Other Suspicious Patterns": "Inauthentic activity and platform manipulation",
+}
+
+// AbuseReport is the data rendered into abuse report text.
+type AbuseReport struct {
+	EntityType   string    `json:"entity_type"`
+	EntityID     string    `json:"entity_id"`
+	URL          string    `json:"url"`
+	Violations   []string  `json:"violations"`
+	Evidence     []string  `json:"evidence"`
+	FirstFlagged time.Time `json:"first_flagged,omitempty"`
+	LastAnalyzed time.Time `json:"last_analyzed"`
+}
+
+// BuildAbuseReport collects the persisted evidence for a repository (owner/name) or user target.
+func BuildAbuseReport(database *db.Database, target string) (AbuseReport, error) {
+	if strings.Contains(target, "/") {
+		return buildRepoAbuseReport(database, target)
+	}
+	return buildUserAbuseReport(database, target)
+}
+
+func buildRepoAbuseReport(database *db.Database, repoID string) (AbuseReport, error) {
+	repo, err := database.GetProcessedRepo(repoID)
+	if err != nil {
+		return AbuseReport{}, err
+	}
+	flags, err := database.ListHeuristicFlags("repo", repoID)
+	if err != nil {
+		return AbuseReport{}, err
+	}
+	threats, err := database.ListURLThreats(repoID)
+	if err != nil {
+		return AbuseReport{}, err
+	}
+	scans, err := database.ListURLScans(repoID)
+	if err != nil {
+		return AbuseReport{}, err
+	}
+
+	report := AbuseReport{
+		EntityType:   "repository",
+		EntityID:     repoID,
+		URL:          "https://github.com/" + repoID,
+		LastAnalyzed: repo.ProcessedAt,
+	}
+	categories := map[string]bool{}
+	if repo.IsMalicious {
+		categories["Malware"] = true
+		report.Evidence = append(report.Evidence, fmt.Sprintf("Repository content matched malware loader checks: %s", report.URL))
+	}
+	report.addFlags(flags, categories)
+	for _, threat := range threats {
+		categories["Malware"] = true
+		report.Evidence = append(report.Evidence, fmt.Sprintf("README links to %s, listed by Google Safe Browsing as %s", threat.URL, threat.ThreatType))
+	}
+	for _, scan := range scans {
+		if scan.Verdict == "malicious" {
+			report.Evidence = append(report.Evidence, fmt.Sprintf("urlscan.io rated %s malicious (score %d): %s", scan.URL, scan.Score, scan.ResultURL))
+		}
+	}
+	report.Violations = violations(categories)
+	return report, nil
+}
+
+func buildUserAbuseReport(database *db.Database, username string) (AbuseReport, error) {
+	user, err := database.GetProcessedUser(username)
+	if err != nil {
+		return AbuseReport{}, err
+	}
+	flags, err := database.ListHeuristicFlags("user", username)
+	if err != nil {
+		return AbuseReport{}, err
+	}
+
+	report := AbuseReport{
+		EntityType:   "account",
+		EntityID:     username,
+		URL:          "https://github.com/" + username,
+		LastAnalyzed: user.ProcessedAt,
+	}
+	categories := map[string]bool{}
+	report.addFlags(flags, categories)
+	if user.SuspiciousEmptyCount > 0 {
+		report.Evidence = append(report.Evidence, fmt.Sprintf("Account hosts %d near-empty repositories with stars and %d total stars across %d contributions",
+			user.SuspiciousEmptyCount, user.TotalStars, user.Contributions))
+	}
+	if user.Suspicious && len(categories) == 0 {
+		categories["Other Suspicious Patterns"] = true
+	}
+	report.Violations = violations(categories)
+	return report, nil
+}
+
+// addFlags appends one evidence line per distinct flag and records its category.
+func (r *AbuseReport) addFlags(flags []db.HeuristicFlag, categories map[string]bool) {
+	seen := map[string]bool{}
+	for _, flag := range flags {
+		if r.FirstFlagged.IsZero() || flag.TriggeredAt.Before(r.FirstFlagged) {
+			r.FirstFlagged = flag.TriggeredAt
+		}
+		if seen[flag.Flag] {
+			continue
+		}
+		seen[flag.Flag] = true
+		category, name, _ := strings.Cut(flag.Flag, ":")
+		categories[category] = true
+		r.Evidence = append(r.Evidence, fmt.Sprintf("%s detection: %s", category, name))
+	}
+}
+
+func violations(categories map[string]bool) []string {
+	seen := map[string]bool{}
+	var result []string
+	for category := range categories {
+		language, ok := policyLanguage[category]
+		if !ok {
+			language = policyLanguage["Other Suspicious Patterns"]
+		}
+		if !seen[language] {
+			seen[language] = true
+			result = append(result, language)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// LoadAbuseTemplate parses the template at path, or the built-in template when path is empty.
+func LoadAbuseTemplate(path string) (*template.Template, error) {
+	text := DefaultAbuseTemplate
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading abuse report template: %w", err)
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New("abuse").Funcs(template.FuncMap{
+		"join": strings.Join,
+		"date": formatDate,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing abuse report template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderAbuseReport renders report through tmpl.
+func RenderAbuseReport(tmpl *template.Template, report AbuseReport) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, report); err != nil {
+		return "", fmt.Errorf("rendering abuse report: %w", err)
+	}
+	return sb.String(), nil
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+)
+
+func newTestDatabase(t *testing.T) *db.Database {
+	t.Helper()
+	database, err := db.New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func TestBuildAbuseReportForMaliciousRepo(t *testing.T) {
+	database := newTestDatabase(t)
+	if err := database.InsertProcessedRepo("evil/loader", "evil", "loader", time.Now(), 10, 50, true); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	for _, flag := range []string{"Spam Behavior:PromotionSpamReadmeHeuristic", "Spam Behavior:PromotionSpamReadmeHeuristic"} {
+		if err := database.InsertHeuristicFlag("repo", "evil/loader", db.HeuristicName(flag), flag, ""); err != nil {
+			t.Fatalf("InsertHeuristicFlag() error = %v", err)
+		}
+	}
+	if err := database.UpsertURLThreat("evil/loader", "http://bad.example/x.zip", "MALWARE", "ANY_PLATFORM"); err != nil {
+		t.Fatalf("UpsertURLThreat() error = %v", err)
+	}
+
+	abuse, err := BuildAbuseReport(database, "evil/loader")
+	if err != nil {
+		t.Fatalf("BuildAbuseReport() error = %v", err)
+	}
+	if len(abuse.Violations) != 2 {
+		t.Fatalf("Violations = %v, want malware and spam", abuse.Violations)
+	}
+	if len(abuse.Evidence) != 3 {
+		t.Fatalf("Evidence = %v, want loader, deduplicated flag, and link evidence", abuse.Evidence)
+	}
+
+	tmpl, err := LoadAbuseTemplate("")
+	if err != nil {
+		t.Fatalf("LoadAbuseTemplate() error = %v", err)
+	}
+	text, err := RenderAbuseReport(tmpl, abuse)
+	if err != nil {
+		t.Fatalf("RenderAbuseReport() error = %v", err)
+	}
+	for _, want := range []string{"https://github.com/evil/loader", "Active malware or exploits", "- README links to http://bad.example/x.zip"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("RenderAbuseReport() missing %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestAbuseTemplateOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abuse.tmpl")
+	if err := os.WriteFile(path, []byte("{{.EntityID}} | {{len .Evidence}}"), 0o644); err != nil {
+		t.Fatalf("writing template: %v", err)
+	}
+	tmpl, err := LoadAbuseTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadAbuseTemplate() error = %v", err)
+	}
+	text, err := RenderAbuseReport(tmpl, AbuseReport{EntityID: "mallory", Evidence: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("RenderAbuseReport() error = %v", err)
+	}
+	if text != "mallory | 2" {
+		t.Fatalf("RenderAbuseReport() = %q, want custom template output", text)
+	}
+}
+
+func TestBuildAbuseReportUnknownTarget(t *testing.T) {
+	if _, err := BuildAbuseReport(newTestDatabase(t), "ghost"); err == nil {
+		t.Fatal("BuildAbuseReport() error = nil, want not found")
+	}
+}
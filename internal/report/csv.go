@@ -0,0 +1,99 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+)
+
+// CSV export types accepted by WriteCSV.
+const (
+	CSVUsers = "users"
+	CSVRepos = "repos"
+	CSVFlags = "flags"
+)
+
+var (
+	csvUserHeader = []string{"username", "login", "created_at", "total_stars", "empty_count", "suspicious_empty_count", "contributions", "followers", "following", "score", "tier", "github_id", "processed_at"}
+	csvRepoHeader = []string{"repo_id", "owner", "name", "updated_at", "disk_usage", "stargazer_count", "is_malicious", "status", "github_id", "processed_at"}
+	csvFlagHeader = []string{"entity_type", "entity_id", "flag", "heuristic_name", "category", "message", "triggered_at", "updated_at"}
+)
+
+// WriteCSV writes the suspicious users, flagged repositories, or recorded flags as CSV with a
+// header row, streaming rows from the database as they are read rather than collecting them
+// first. It returns the number of data rows written.
+func WriteCSV(w io.Writer, database *db.Database, exportType string) (int, error) {
+	out := csv.NewWriter(w)
+	rows := 0
+	write := func(record []string) error {
+		if err := out.Write(record); err != nil {
+			return fmt.Errorf("writing CSV row: %w", err)
+		}
+		rows++
+		return nil
+	}
+
+	var err error
+	switch exportType {
+	case CSVUsers:
+		if err = out.Write(csvUserHeader); err == nil {
+			err = database.EachSuspiciousUser(func(user db.ProcessedUser) error {
+				return write([]string{
+					user.Username, user.Login, csvTime(user.CreatedAt),
+					strconv.Itoa(user.TotalStars), strconv.Itoa(user.EmptyCount), strconv.Itoa(user.SuspiciousEmptyCount),
+					strconv.Itoa(user.Contributions), strconv.Itoa(user.Followers), strconv.Itoa(user.Following),
+					strconv.FormatFloat(user.Score, 'f', -1, 64), user.Tier, csvID(user.GitHubID), csvTime(user.ProcessedAt),
+				})
+			})
+		}
+	case CSVRepos:
+		if err = out.Write(csvRepoHeader); err == nil {
+			err = database.EachFlaggedRepo("", func(repo db.ProcessedRepo) error {
+				return write([]string{
+					repo.RepoID, repo.Owner, repo.Name, csvTime(repo.UpdatedAt),
+					strconv.Itoa(repo.DiskUsage), strconv.Itoa(repo.StargazerCount), strconv.FormatBool(repo.IsMalicious),
+					repo.Status, csvID(repo.GitHubID), csvTime(repo.ProcessedAt),
+				})
+			})
+		}
+	case CSVFlags:
+		if err = out.Write(csvFlagHeader); err == nil {
+			err = database.EachHeuristicFlag(func(flag db.HeuristicFlag) error {
+				return write([]string{
+					flag.EntityType, flag.EntityID, flag.Flag, flag.HeuristicName, flag.Category, flag.Message,
+					csvTime(flag.TriggeredAt), csvTime(flag.UpdatedAt),
+				})
+			})
+		}
+	default:
+		return 0, fmt.Errorf("unknown CSV export type %q", exportType)
+	}
+	if err != nil {
+		return rows, err
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return rows, fmt.Errorf("writing CSV: %w", err)
+	}
+	return rows, nil
+}
+
+// csvTime renders t as RFC3339 in UTC, or empty for the zero time of a pending record.
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// csvID renders a GitHub ID, or empty when it is not known.
+func csvID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWriteCSVRoundTripsEachType(t *testing.T) {
+	database := newTestDatabase(t)
+	created := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if err := database.InsertProcessedUser("Farmer", created, 3, 9, 2, 0, true, "low"); err != nil {
+		t.Fatalf("InsertProcessedUser() error = %v", err)
+	}
+	if err := database.SetUserFollowCounts("farmer", 1, 450); err != nil {
+		t.Fatalf("SetUserFollowCounts() error = %v", err)
+	}
+	if err := database.SetUserScore("farmer", 2.5); err != nil {
+		t.Fatalf("SetUserScore() error = %v", err)
+	}
+	if err := database.InsertProcessedUser("octocat", created, 900, 0, 0, 80, false, ""); err != nil {
+		t.Fatalf("InsertProcessedUser() error = %v", err)
+	}
+	if err := database.InsertProcessedRepo("evil/loader", "evil", "loader", created, 10, 50, true); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	if err := database.InsertProcessedRepo("octocat/hello", "octocat", "hello", created, 500, 3, false); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	for _, flag := range []struct{ entityType, entityID, name, message string }{
+		{"repo", "evil/loader", "LoaderHeuristic", "Loader script, with a comma"},
+		{"user", "farmer", "NewHeuristic", "Account is new"},
+		{"user", "farmer", "FollowRatioHeuristic", "Follows 450 accounts"},
+	} {
+		if err := database.InsertHeuristicFlag(flag.entityType, flag.entityID, flag.name, "Malware:"+flag.name, flag.message); err != nil {
+			t.Fatalf("InsertHeuristicFlag() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		exportType string
+		header     []string
+		rows       int
+		check      func(t *testing.T, first []string)
+	}{
+		{CSVUsers, csvUserHeader, 1, func(t *testing.T, first []string) {
+			if first[0] != "farmer" || first[1] != "Farmer" || first[2] != "2026-03-01T00:00:00Z" || first[7] != "1" || first[8] != "450" || first[9] != "2.5" || first[10] != "low" {
+				t.Fatalf("user row = %q, want farmer with its follow counts, score, and tier", first)
+			}
+		}},
+		{CSVRepos, csvRepoHeader, 1, func(t *testing.T, first []string) {
+			if first[0] != "evil/loader" || first[6] != "true" || first[8] != "" {
+				t.Fatalf("repo row = %q, want the malicious repo without a GitHub ID", first)
+			}
+		}},
+		{CSVFlags, csvFlagHeader, 3, func(t *testing.T, first []string) {
+			if first[0] != "repo" || first[1] != "evil/loader" || first[4] != "malware_distribution" || first[5] != "Loader script, with a comma" {
+				t.Fatalf("flag row = %q, want the repo flag with its category and quoted message", first)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.exportType, func(t *testing.T) {
+			var buf bytes.Buffer
+			written, err := WriteCSV(&buf, database, tt.exportType)
+			if err != nil || written != tt.rows {
+				t.Fatalf("WriteCSV() = %d, %v, want %d rows", written, err, tt.rows)
+			}
+			records, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatalf("parsing CSV: %v", err)
+			}
+			if len(records) != tt.rows+1 || !reflect.DeepEqual(records[0], tt.header) {
+				t.Fatalf("CSV = %q, want header %q and %d rows", records, tt.header, tt.rows)
+			}
+			tt.check(t, records[1])
+		})
+	}
+
+	if _, err := WriteCSV(&bytes.Buffer{}, database, "stars"); err == nil {
+		t.Fatal("WriteCSV() accepted an unknown export type")
+	}
+}
@@ -0,0 +1,252 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+// categoryOrder ranks flag categories from most to least severe. An entry is grouped under the
+// most severe category among its flags.
+var categoryOrder = []string{
+	"Malware",
+	"Phishing",
+	"Mass Repository Creation",
+	"Automated Activity",
+	"Spam Behavior",
+	"Other Suspicious Patterns",
+}
+
+// MarkdownFilter narrows a Markdown report. Zero values match everything.
+type MarkdownFilter struct {
+	Since    time.Time
+	Category string
+	// Tier drops users graded below it. Repositories are not tiered and are unaffected.
+	Tier string
+}
+
+// MarkdownReport lists suspicious users and flagged repositories grouped by category.
+type MarkdownReport struct {
+	Since    time.Time       `json:"since,omitempty"`
+	Category string          `json:"category,omitempty"`
+	Tier     string          `json:"tier,omitempty"`
+	Users    []MarkdownGroup `json:"users"`
+	Repos    []MarkdownGroup `json:"repos"`
+}
+
+// MarkdownGroup is the entries whose most severe flag falls in Category.
+type MarkdownGroup struct {
+	Category string          `json:"category"`
+	Entries  []MarkdownEntry `json:"entries"`
+}
+
+// MarkdownEntry is one user or repository.
+type MarkdownEntry struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Flags     []string  `json:"flags"`
+	Stars     int       `json:"stars"`
+	Tier      string    `json:"tier,omitempty"`
+	FirstSeen time.Time `json:"first_seen"`
+}
+
+type markdownCandidate struct {
+	entry      MarkdownEntry
+	categories []string
+}
+
+// BuildMarkdownReport collects suspicious users and flagged repositories that match filter.
+func BuildMarkdownReport(database *db.Database, filter MarkdownFilter) (MarkdownReport, error) {
+	result := MarkdownReport{Since: filter.Since, Category: filter.Category, Tier: filter.Tier}
+
+	users, err := database.ListSuspiciousUsers()
+	if err != nil {
+		return MarkdownReport{}, err
+	}
+	var userCandidates []markdownCandidate
+	for _, user := range users {
+		if models.SeverityRank(user.Tier) < models.SeverityRank(filter.Tier) {
+			continue
+		}
+		candidate, err := newMarkdownCandidate(database, "user", user.Username, user.TotalStars, user.ProcessedAt, false)
+		if err != nil {
+			return MarkdownReport{}, err
+		}
+		candidate.entry.Tier = user.Tier
+		userCandidates = append(userCandidates, candidate)
+	}
+	result.Users = groupMarkdownEntries(userCandidates, filter)
+
+	repos, err := database.ListFlaggedRepos("")
+	if err != nil {
+		return MarkdownReport{}, err
+	}
+	var repoCandidates []markdownCandidate
+	for _, repo := range repos {
+		candidate, err := newMarkdownCandidate(database, "repo", repo.RepoID, repo.StargazerCount, repo.ProcessedAt, repo.IsMalicious)
+		if err != nil {
+			return MarkdownReport{}, err
+		}
+		repoCandidates = append(repoCandidates, candidate)
+	}
+	result.Repos = groupMarkdownEntries(repoCandidates, filter)
+	return result, nil
+}
+
+func newMarkdownCandidate(database *db.Database, entityType, entityID string, stars int, processedAt time.Time, malicious bool) (markdownCandidate, error) {
+	flags, err := database.ListHeuristicFlags(entityType, entityID)
+	if err != nil {
+		return markdownCandidate{}, err
+	}
+	candidate := markdownCandidate{entry: MarkdownEntry{
+		ID:        entityID,
+		URL:       "https://github.com/" + entityID,
+		Stars:     stars,
+		FirstSeen: processedAt,
+	}}
+	if malicious {
+		candidate.entry.Flags = append(candidate.entry.Flags, "Malware:"+maliciousRepoRuleID)
+		candidate.categories = append(candidate.categories, "Malware")
+	}
+	for _, flag := range flags {
+		if !containsString(candidate.entry.Flags, flag.Flag) {
+			candidate.entry.Flags = append(candidate.entry.Flags, flag.Flag)
+		}
+		category, _, _ := strings.Cut(flag.Flag, ":")
+		if !containsString(candidate.categories, category) {
+			candidate.categories = append(candidate.categories, category)
+		}
+		if flag.TriggeredAt.Before(candidate.entry.FirstSeen) {
+			candidate.entry.FirstSeen = flag.TriggeredAt
+		}
+	}
+	if len(candidate.categories) == 0 {
+		candidate.categories = []string{defaultSARIFCategory}
+	}
+	return candidate, nil
+}
+
+func groupMarkdownEntries(candidates []markdownCandidate, filter MarkdownFilter) []MarkdownGroup {
+	grouped := map[string][]MarkdownEntry{}
+	for _, candidate := range candidates {
+		if !filter.Since.IsZero() && candidate.entry.FirstSeen.Before(filter.Since) {
+			continue
+		}
+		if filter.Category != "" && !containsFold(candidate.categories, filter.Category) {
+			continue
+		}
+		category := primaryCategory(candidate.categories)
+		grouped[category] = append(grouped[category], candidate.entry)
+	}
+
+	var groups []MarkdownGroup
+	for _, category := range orderedCategories(grouped) {
+		entries := grouped[category]
+		sort.Slice(entries, func(i, j int) bool {
+			if rankI, rankJ := models.SeverityRank(entries[i].Tier), models.SeverityRank(entries[j].Tier); rankI != rankJ {
+				return rankI > rankJ
+			}
+			return strings.ToLower(entries[i].ID) < strings.ToLower(entries[j].ID)
+		})
+		groups = append(groups, MarkdownGroup{Category: category, Entries: entries})
+	}
+	return groups
+}
+
+func primaryCategory(categories []string) string {
+	for _, category := range categoryOrder {
+		if containsString(categories, category) {
+			return category
+		}
+	}
+	sorted := append([]string(nil), categories...)
+	sort.Strings(sorted)
+	return sorted[0]
+}
+
+// orderedCategories returns known categories by severity, then unknown ones alphabetically.
+func orderedCategories[V any](grouped map[string]V) []string {
+	var ordered []string
+	for _, category := range categoryOrder {
+		if _, ok := grouped[category]; ok {
+			ordered = append(ordered, category)
+		}
+	}
+	var extra []string
+	for category := range grouped {
+		if !containsString(categoryOrder, category) {
+			extra = append(extra, category)
+		}
+	}
+	sort.Strings(extra)
+	return append(ordered, extra...)
+}
+
+// RenderMarkdown formats a Markdown report.
+func RenderMarkdown(r MarkdownReport) string {
+	var sb strings.Builder
+	sb.WriteString("# GitHubWatchdog Findings\n")
+	var filters []string
+	if !r.Since.IsZero() {
+		filters = append(filters, "first seen since "+r.Since.UTC().Format("2006-01-02"))
+	}
+	if r.Category != "" {
+		filters = append(filters, "category "+r.Category)
+	}
+	if r.Tier != "" {
+		filters = append(filters, "users of tier "+r.Tier+" or above")
+	}
+	if len(filters) > 0 {
+		fmt.Fprintf(&sb, "\nFiltered to %s.\n", strings.Join(filters, ", "))
+	}
+
+	writeMarkdownSection(&sb, "Suspicious Users", r.Users)
+	writeMarkdownSection(&sb, "Flagged Repositories", r.Repos)
+	return sb.String()
+}
+
+func writeMarkdownSection(sb *strings.Builder, title string, groups []MarkdownGroup) {
+	total := 0
+	for _, group := range groups {
+		total += len(group.Entries)
+	}
+	fmt.Fprintf(sb, "\n## %s (%d)\n", title, total)
+	if total == 0 {
+		sb.WriteString("\nNone.\n")
+		return
+	}
+	for _, group := range groups {
+		fmt.Fprintf(sb, "\n### %s\n\n", group.Category)
+		for _, entry := range group.Entries {
+			fmt.Fprintf(sb, "1. [%s](%s) - %d stars", entry.ID, entry.URL, entry.Stars)
+			if entry.Tier != "" {
+				fmt.Fprintf(sb, ", %s tier", entry.Tier)
+			}
+			fmt.Fprintf(sb, ", first seen %s", markdownDate(entry.FirstSeen))
+			if len(entry.Flags) > 0 {
+				fmt.Fprintf(sb, " - %s", strings.Join(entry.Flags, ", "))
+			}
+			sb.WriteString("\n")
+		}
+	}
+}
+
+func markdownDate(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
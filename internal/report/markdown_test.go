@@ -0,0 +1,106 @@
+package report
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func seedMarkdownDatabase(t *testing.T) *db.Database {
+	t.Helper()
+	database := newTestDatabase(t)
+	repos := []struct {
+		id, owner, name string
+		stars           int
+		malicious       bool
+	}{
+		{"evil/loader", "evil", "loader", 120, true},
+		{"spam/portfolio", "spam", "portfolio", 3, false},
+		{"clean/tool", "clean", "tool", 40, false},
+	}
+	for _, repo := range repos {
+		if err := database.InsertProcessedRepo(repo.id, repo.owner, repo.name, time.Now(), 10, repo.stars, repo.malicious); err != nil {
+			t.Fatalf("InsertProcessedRepo() error = %v", err)
+		}
+	}
+	if err := database.InsertProcessedUser("farmer", time.Now(), 7, 20, 18, 0, true, "high"); err != nil {
+		t.Fatalf("InsertProcessedUser() error = %v", err)
+	}
+	if err := database.InsertProcessedUser("abandoned", time.Now(), 0, 4, 4, 0, true, "low"); err != nil {
+		t.Fatalf("InsertProcessedUser() error = %v", err)
+	}
+	if err := database.InsertProcessedUser("bystander", time.Now(), 500, 0, 0, 900, false, ""); err != nil {
+		t.Fatalf("InsertProcessedUser() error = %v", err)
+	}
+	flags := []struct{ entityType, entityID, flag string }{
+		{"repo", "evil/loader", "Phishing:SafeBrowsingHeuristic"},
+		{"repo", "spam/portfolio", "Spam Behavior:BoilerplateReadmeHeuristic"},
+		{"user", "farmer", "Mass Repository Creation:EmptyRepoHeuristic"},
+		{"user", "farmer", "Spam Behavior:GeneratedPortfolioHeuristic"},
+		{"user", "abandoned", "Mass Repository Creation:NewHeuristic"},
+	}
+	for _, f := range flags {
+		if err := database.InsertHeuristicFlag(f.entityType, f.entityID, db.HeuristicName(f.flag), f.flag, ""); err != nil {
+			t.Fatalf("InsertHeuristicFlag() error = %v", err)
+		}
+	}
+	// Pin timestamps so the rendered dates are stable.
+	for _, stmt := range []string{
+		`UPDATE processed_repositories SET processed_at = '2026-03-10 12:00:00'`,
+		`UPDATE processed_repositories SET processed_at = '2026-01-05 12:00:00' WHERE repo_id = 'spam/portfolio'`,
+		`UPDATE processed_users SET processed_at = '2026-03-12 08:00:00'`,
+		`UPDATE heuristic_flags SET triggered_at = '2026-03-01 09:30:00' WHERE entity_type = 'user'`,
+		`UPDATE heuristic_flags SET triggered_at = '2026-03-11 00:00:00' WHERE entity_type = 'repo'`,
+	} {
+		if _, err := database.Exec(stmt); err != nil {
+			t.Fatalf("Exec(%q) error = %v", stmt, err)
+		}
+	}
+	return database
+}
+
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("writing %s: %v", path, err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	if got != string(want) {
+		t.Fatalf("%s mismatch:\n--- got ---\n%s\n--- want ---\n%s", name, got, want)
+	}
+}
+
+func TestRenderMarkdownGolden(t *testing.T) {
+	database := seedMarkdownDatabase(t)
+
+	tests := []struct {
+		golden string
+		filter MarkdownFilter
+	}{
+		{"markdown_all.golden", MarkdownFilter{}},
+		{"markdown_filtered.golden", MarkdownFilter{Since: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), Category: "spam behavior"}},
+		{"markdown_empty.golden", MarkdownFilter{Category: "Automated Activity"}},
+		{"markdown_tier.golden", MarkdownFilter{Tier: "medium"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			result, err := BuildMarkdownReport(database, tt.filter)
+			if err != nil {
+				t.Fatalf("BuildMarkdownReport() error = %v", err)
+			}
+			assertGolden(t, tt.golden, RenderMarkdown(result))
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+)
+
+const (
+	// SARIFVersion is the SARIF specification version emitted by BuildSARIF.
+	SARIFVersion = "2.1.0"
+	// SARIFSchema is the published JSON schema for SARIFVersion.
+	SARIFSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	sarifToolName        = "GitHubWatchdog"
+	sarifToolURI         = "https://github.com/BearHuddleston/GitHubWatchdog"
+	sarifFingerprintKey  = "githubwatchdogFinding/v1"
+	maliciousRepoRuleID  = "MaliciousRepository"
+	defaultSARIFCategory = "Other Suspicious Patterns"
+)
+
+// sarifRuleInfo is the fixed metadata published for a rule. Keeping it static means rule
+// ids, descriptions, and indexes do not change between exports.
+type sarifRuleInfo struct {
+	Category    string
+	Description string
+}
+
+// sarifRules lists the repository-level detections that can appear in an export.
+var sarifRules = map[string]sarifRuleInfo{
+	maliciousRepoRuleID:            {"Malware", "Repository content matched the malware loader checks."},
+	"SafeBrowsingHeuristic":        {"Malware", "README links to a URL listed by Google Safe Browsing."},
+	"ExternalIndicatorHeuristic":   {"Malware", "Repository is listed as confirmed malicious on an imported shared blocklist."},
+	"ExternalCommandHeuristic":     {"Other Suspicious Patterns", "An operator-supplied external command flagged the repository."},
+	"GeneratedRepoNamingHeuristic": {"Automated Activity", "Repository name follows a generated project-name plus number pattern."},
+	"BoilerplateReadmeHeuristic":   {"Spam Behavior", "README is generic boilerplate with little project-specific content."},
+	"SparseProjectHeuristic":       {"Other Suspicious Patterns", "Repository has too few files to be a real project."},
+	"PromotionSpamReadmeHeuristic": {"Spam Behavior", "README promotes downloads, cracks, or similar spam content."},
+}
+
+// sarifLevels maps flag categories to SARIF result levels.
+var sarifLevels = map[string]string{
+	"Malware":                   "error",
+	"Phishing":                  "error",
+	"Mass Repository Creation":  "warning",
+	"Automated Activity":        "warning",
+	"Spam Behavior":             "warning",
+	"Other Suspicious Patterns": "note",
+}
+
+// sarifSecuritySeverity gives GitHub code scanning a numeric severity per SARIF level.
+var sarifSecuritySeverity = map[string]string{
+	"error":   "9.0",
+	"warning": "5.0",
+	"note":    "2.0",
+}
+
+// SARIFFilter narrows a SARIF export. Zero values match everything.
+type SARIFFilter struct {
+	Owner    string
+	Since    time.Time
+	Category string
+}
+
+// SARIFLog is a SARIF 2.1.0 log file.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is a single tool run.
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+// SARIFTool describes the producing tool.
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver carries tool identity and its rule catalog.
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule is one heuristic or checker.
+type SARIFRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     SARIFMessage       `json:"shortDescription"`
+	DefaultConfiguration SARIFConfiguration `json:"defaultConfiguration"`
+	Properties           SARIFProperties    `json:"properties"`
+}
+
+// SARIFConfiguration holds a rule's default level.
+type SARIFConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SARIFProperties carries watchdog-specific metadata in the SARIF property bag.
+type SARIFProperties struct {
+	Category         string   `json:"category"`
+	Tags             []string `json:"tags,omitempty"`
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+	FirstFlagged     string   `json:"firstFlagged,omitempty"`
+}
+
+// SARIFMessage is a plain-text SARIF message.
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult is one flag on one repository.
+type SARIFResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             SARIFMessage      `json:"message"`
+	Locations           []SARIFLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          SARIFProperties   `json:"properties"`
+}
+
+// SARIFLocation points at the flagged repository.
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation wraps the artifact location.
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+// SARIFArtifactLocation is the URI of the flagged artifact.
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifFinding struct {
+	ruleID       string
+	category     string
+	repoID       string
+	message      string
+	firstFlagged time.Time
+}
+
+// BuildSARIF exports persisted repository findings that match filter as a SARIF log.
+func BuildSARIF(database *db.Database, filter SARIFFilter) (SARIFLog, error) {
+	repos, err := database.ListFlaggedRepos(filter.Owner)
+	if err != nil {
+		return SARIFLog{}, err
+	}
+
+	var findings []sarifFinding
+	for _, repo := range repos {
+		repoFindings, err := repoSARIFFindings(database, repo)
+		if err != nil {
+			return SARIFLog{}, err
+		}
+		for _, finding := range repoFindings {
+			if filter.Category != "" && !strings.EqualFold(finding.category, filter.Category) {
+				continue
+			}
+			if !filter.Since.IsZero() && finding.firstFlagged.Before(filter.Since) {
+				continue
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return newSARIFLog(findings), nil
+}
+
+func repoSARIFFindings(database *db.Database, repo db.ProcessedRepo) ([]sarifFinding, error) {
+	flags, err := database.ListHeuristicFlags("repo", repo.RepoID)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []sarifFinding
+	if repo.IsMalicious {
+		findings = append(findings, sarifFinding{
+			ruleID:       maliciousRepoRuleID,
+			category:     "Malware",
+			repoID:       repo.RepoID,
+			message:      "Repository content matched the malware loader checks.",
+			firstFlagged: repo.ProcessedAt,
+		})
+	}
+
+	index := map[string]int{}
+	safeBrowsing := false
+	for _, flag := range flags {
+		category, name, _ := strings.Cut(flag.Flag, ":")
+		if i, ok := index[flag.Flag]; ok {
+			if flag.TriggeredAt.Before(findings[i].firstFlagged) {
+				findings[i].firstFlagged = flag.TriggeredAt
+			}
+			continue
+		}
+		index[flag.Flag] = len(findings)
+		safeBrowsing = safeBrowsing || name == "SafeBrowsingHeuristic"
+		findings = append(findings, sarifFinding{
+			ruleID:       name,
+			category:     category,
+			repoID:       repo.RepoID,
+			message:      ruleDescription(name),
+			firstFlagged: flag.TriggeredAt,
+		})
+	}
+
+	if safeBrowsing {
+		threats, err := database.ListURLThreats(repo.RepoID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range findings {
+			if findings[i].ruleID != "SafeBrowsingHeuristic" {
+				continue
+			}
+			var urls []string
+			for _, threat := range threats {
+				if sarifThreatCategory(threat.ThreatType) == findings[i].category {
+					urls = append(urls, threat.URL+" ("+threat.ThreatType+")")
+				}
+			}
+			if len(urls) > 0 {
+				findings[i].message = "README links to URLs listed by Google Safe Browsing: " + strings.Join(urls, ", ")
+			}
+		}
+	}
+	return findings, nil
+}
+
+func newSARIFLog(findings []sarifFinding) SARIFLog {
+	// Catalog rules come first so their indexes never move; rules outside the catalog follow.
+	ruleIDs := make([]string, 0, len(sarifRules))
+	for id := range sarifRules {
+		ruleIDs = append(ruleIDs, id)
+	}
+	sort.Strings(ruleIDs)
+	var extraIDs []string
+	for _, finding := range findings {
+		if _, ok := sarifRules[finding.ruleID]; !ok && !containsString(extraIDs, finding.ruleID) {
+			extraIDs = append(extraIDs, finding.ruleID)
+		}
+	}
+	sort.Strings(extraIDs)
+	ruleIDs = append(ruleIDs, extraIDs...)
+
+	ruleIndex := make(map[string]int, len(ruleIDs))
+	rules := make([]SARIFRule, 0, len(ruleIDs))
+	for i, id := range ruleIDs {
+		ruleIndex[id] = i
+		category := ruleCategory(id, findings)
+		level := sarifLevel(category)
+		rules = append(rules, SARIFRule{
+			ID:                   id,
+			Name:                 id,
+			ShortDescription:     SARIFMessage{Text: ruleDescription(id)},
+			DefaultConfiguration: SARIFConfiguration{Level: level},
+			Properties: SARIFProperties{
+				Category:         category,
+				Tags:             []string{"security", category},
+				SecuritySeverity: sarifSecuritySeverity[level],
+			},
+		})
+	}
+
+	results := make([]SARIFResult, 0, len(findings))
+	for _, finding := range findings {
+		level := sarifLevel(finding.category)
+		results = append(results, SARIFResult{
+			RuleID:    finding.ruleID,
+			RuleIndex: ruleIndex[finding.ruleID],
+			Level:     level,
+			Message:   SARIFMessage{Text: finding.repoID + ": " + finding.message},
+			Locations: []SARIFLocation{{
+				PhysicalLocation: SARIFPhysicalLocation{
+					ArtifactLocation: SARIFArtifactLocation{URI: "https://github.com/" + finding.repoID},
+				},
+			}},
+			PartialFingerprints: map[string]string{sarifFingerprintKey: sarifFingerprint(finding)},
+			Properties: SARIFProperties{
+				Category:         finding.category,
+				SecuritySeverity: sarifSecuritySeverity[level],
+				FirstFlagged:     formatDate(finding.firstFlagged),
+			},
+		})
+	}
+
+	return SARIFLog{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs: []SARIFRun{{
+			Tool: SARIFTool{Driver: SARIFDriver{
+				Name:           sarifToolName,
+				InformationURI: sarifToolURI,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
+
+func ruleDescription(ruleID string) string {
+	if info, ok := sarifRules[ruleID]; ok {
+		return info.Description
+	}
+	return ruleID + " flagged the repository."
+}
+
+// ruleCategory returns the catalog category for a rule, or the category of its first finding for unknown rules.
+func ruleCategory(ruleID string, findings []sarifFinding) string {
+	if info, ok := sarifRules[ruleID]; ok {
+		return info.Category
+	}
+	for _, finding := range findings {
+		if finding.ruleID == ruleID && finding.category != "" {
+			return finding.category
+		}
+	}
+	return defaultSARIFCategory
+}
+
+func sarifLevel(category string) string {
+	if level, ok := sarifLevels[category]; ok {
+		return level
+	}
+	return sarifLevels[defaultSARIFCategory]
+}
+
+// sarifFingerprint identifies a finding independently of when it was exported.
+func sarifFingerprint(finding sarifFinding) string {
+	sum := sha256.Sum256([]byte(finding.ruleID + "|" + finding.category + "|" + strings.ToLower(finding.repoID)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// sarifThreatCategory mirrors how scans categorize Safe Browsing threat types.
+func sarifThreatCategory(threatType string) string {
+	if threatType == "SOCIAL_ENGINEERING" {
+		return "Phishing"
+	}
+	return "Malware"
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
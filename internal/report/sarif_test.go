@@ -0,0 +1,151 @@
+package report
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// validateSARIF checks the constraints the SARIF 2.1.0 schema places on the properties we emit.
+func validateSARIF(t *testing.T, log SARIFLog) {
+	t.Helper()
+	data, err := json.Marshal(log)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var doc struct {
+		Schema  string `json:"$schema"`
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool *struct {
+				Driver *struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID                   string `json:"id"`
+						DefaultConfiguration struct {
+							Level string `json:"level"`
+						} `json:"defaultConfiguration"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex *int   `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Message   *struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	levels := map[string]bool{"none": true, "note": true, "warning": true, "error": true}
+	if doc.Version != "2.1.0" || doc.Schema == "" || doc.Runs == nil {
+		t.Fatalf("SARIF header = %q %q runs=%v, want 2.1.0 with schema and runs", doc.Version, doc.Schema, doc.Runs)
+	}
+	for _, run := range doc.Runs {
+		if run.Tool == nil || run.Tool.Driver == nil || run.Tool.Driver.Name == "" {
+			t.Fatal("run.tool.driver.name is required")
+		}
+		rules := run.Tool.Driver.Rules
+		for _, rule := range rules {
+			if rule.ID == "" || !levels[rule.DefaultConfiguration.Level] {
+				t.Fatalf("rule = %+v, want id and valid default level", rule)
+			}
+		}
+		for _, result := range run.Results {
+			if result.Message == nil || result.Message.Text == "" {
+				t.Fatalf("result %q missing message.text", result.RuleID)
+			}
+			if !levels[result.Level] {
+				t.Fatalf("result level = %q, want SARIF level", result.Level)
+			}
+			if result.RuleIndex == nil || *result.RuleIndex < 0 || *result.RuleIndex >= len(rules) || rules[*result.RuleIndex].ID != result.RuleID {
+				t.Fatalf("result %q has ruleIndex %v inconsistent with rules", result.RuleID, result.RuleIndex)
+			}
+			for _, location := range result.Locations {
+				if parsed, err := url.Parse(location.PhysicalLocation.ArtifactLocation.URI); err != nil || !parsed.IsAbs() {
+					t.Fatalf("artifactLocation.uri = %q, want absolute URI", location.PhysicalLocation.ArtifactLocation.URI)
+				}
+			}
+		}
+	}
+}
+
+func TestBuildSARIFMapsFlagsToRules(t *testing.T) {
+	database := newTestDatabase(t)
+	if err := database.InsertProcessedRepo("evil/loader", "evil", "loader", time.Now(), 10, 50, true); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	if err := database.InsertProcessedRepo("spam/portfolio", "spam", "portfolio", time.Now(), 10, 50, false); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	if err := database.InsertProcessedRepo("clean/tool", "clean", "tool", time.Now(), 10, 50, false); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	for _, flag := range []string{"Phishing:SafeBrowsingHeuristic", "Phishing:SafeBrowsingHeuristic"} {
+		if err := database.InsertHeuristicFlag("repo", "evil/loader", "SafeBrowsingHeuristic", flag, ""); err != nil {
+			t.Fatalf("InsertHeuristicFlag() error = %v", err)
+		}
+	}
+	if err := database.UpsertURLThreat("evil/loader", "http://bad.example/login", "SOCIAL_ENGINEERING", "ANY_PLATFORM"); err != nil {
+		t.Fatalf("UpsertURLThreat() error = %v", err)
+	}
+	if err := database.InsertHeuristicFlag("repo", "spam/portfolio", "BoilerplateReadmeHeuristic", "Spam Behavior:BoilerplateReadmeHeuristic", ""); err != nil {
+		t.Fatalf("InsertHeuristicFlag() error = %v", err)
+	}
+
+	log, err := BuildSARIF(database, SARIFFilter{})
+	if err != nil {
+		t.Fatalf("BuildSARIF() error = %v", err)
+	}
+	validateSARIF(t, log)
+
+	results := log.Runs[0].Results
+	if len(results) != 3 {
+		t.Fatalf("BuildSARIF() results = %+v, want malicious, deduplicated phishing, and spam results", results)
+	}
+	levels := map[string]string{}
+	for _, result := range results {
+		levels[result.RuleID] = result.Level
+	}
+	if levels[maliciousRepoRuleID] != "error" || levels["SafeBrowsingHeuristic"] != "error" || levels["BoilerplateReadmeHeuristic"] != "warning" {
+		t.Fatalf("result levels = %v, want error for malware/phishing and warning for spam", levels)
+	}
+
+	filtered, err := BuildSARIF(database, SARIFFilter{Owner: "spam", Category: "spam behavior"})
+	if err != nil {
+		t.Fatalf("BuildSARIF() filtered error = %v", err)
+	}
+	validateSARIF(t, filtered)
+	if got := filtered.Runs[0].Results; len(got) != 1 || got[0].RuleID != "BoilerplateReadmeHeuristic" {
+		t.Fatalf("BuildSARIF(owner=spam) results = %+v, want one spam result", got)
+	}
+	if !reflect.DeepEqual(filtered.Runs[0].Tool.Driver.Rules, log.Runs[0].Tool.Driver.Rules) {
+		t.Fatal("rule metadata changed between exports")
+	}
+	if filtered.Runs[0].Results[0].PartialFingerprints[sarifFingerprintKey] != results[2].PartialFingerprints[sarifFingerprintKey] {
+		t.Fatal("fingerprint changed between exports")
+	}
+
+	future, err := BuildSARIF(database, SARIFFilter{Since: time.Now().Add(24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("BuildSARIF() since error = %v", err)
+	}
+	validateSARIF(t, future)
+	if got := future.Runs[0].Results; len(got) != 0 {
+		t.Fatalf("BuildSARIF(since=tomorrow) results = %+v, want none", got)
+	}
+}
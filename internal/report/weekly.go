@@ -0,0 +1,284 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+)
+
+// weeklyTopHeuristics caps the heuristics listed in a weekly summary.
+const weeklyTopHeuristics = 10
+
+// WeeklyReport summarizes what the watchdog found between Since and Until.
+type WeeklyReport struct {
+	Since            time.Time            `json:"since"`
+	Until            time.Time            `json:"until"`
+	Stats            db.ScanStats         `json:"stats"`
+	Categories       []WeeklyCount        `json:"categories"`
+	TopHeuristics    []WeeklyCount        `json:"top_heuristics"`
+	OwnerClusters    []WeeklyCluster      `json:"owner_clusters"`
+	SharedStargazers []db.SharedStargazer `json:"shared_stargazers"`
+	ContentClusters  []db.ContentCluster  `json:"content_clusters"`
+	Reported         []db.AbuseReport     `json:"reported"`
+}
+
+// WeeklyCount is the number of new flags for a category or heuristic. Category is set for
+// heuristics only.
+type WeeklyCount struct {
+	Name     string `json:"name"`
+	Category string `json:"category,omitempty"`
+	Count    int    `json:"count"`
+}
+
+// WeeklyCluster is an owner with several newly flagged repositories.
+type WeeklyCluster struct {
+	Owner string   `json:"owner"`
+	Repos []string `json:"repos"`
+}
+
+// BuildWeeklyReport collects new flags, scan counts, correlations, and abuse reports filed in
+// [since, until).
+func BuildWeeklyReport(database *db.Database, since, until time.Time) (WeeklyReport, error) {
+	result := WeeklyReport{Since: since, Until: until}
+
+	stats, err := database.GetScanStats(since, until)
+	if err != nil {
+		return WeeklyReport{}, err
+	}
+	result.Stats = stats
+
+	flags, err := database.ListHeuristicFlagsBetween(since, until)
+	if err != nil {
+		return WeeklyReport{}, err
+	}
+	categories := map[string]int{}
+	heuristics := map[string]WeeklyCount{}
+	ownerRepos := map[string][]string{}
+	for _, flag := range flags {
+		category, name, _ := strings.Cut(flag.Flag, ":")
+		if name == "" {
+			category, name = defaultSARIFCategory, flag.Flag
+		}
+		categories[category]++
+		count := heuristics[name]
+		count.Name, count.Category = name, category
+		count.Count++
+		heuristics[name] = count
+		if flag.EntityType == "repo" {
+			owner, _, _ := strings.Cut(flag.EntityID, "/")
+			if !containsString(ownerRepos[owner], flag.EntityID) {
+				ownerRepos[owner] = append(ownerRepos[owner], flag.EntityID)
+			}
+		}
+	}
+
+	for _, category := range orderedCategories(categories) {
+		result.Categories = append(result.Categories, WeeklyCount{Name: category, Count: categories[category]})
+	}
+	for _, count := range heuristics {
+		result.TopHeuristics = append(result.TopHeuristics, count)
+	}
+	sort.Slice(result.TopHeuristics, func(i, j int) bool {
+		if result.TopHeuristics[i].Count != result.TopHeuristics[j].Count {
+			return result.TopHeuristics[i].Count > result.TopHeuristics[j].Count
+		}
+		return result.TopHeuristics[i].Name < result.TopHeuristics[j].Name
+	})
+	if len(result.TopHeuristics) > weeklyTopHeuristics {
+		result.TopHeuristics = result.TopHeuristics[:weeklyTopHeuristics]
+	}
+	for owner, repos := range ownerRepos {
+		if len(repos) >= 2 {
+			sort.Strings(repos)
+			result.OwnerClusters = append(result.OwnerClusters, WeeklyCluster{Owner: owner, Repos: repos})
+		}
+	}
+	sort.Slice(result.OwnerClusters, func(i, j int) bool {
+		if len(result.OwnerClusters[i].Repos) != len(result.OwnerClusters[j].Repos) {
+			return len(result.OwnerClusters[i].Repos) > len(result.OwnerClusters[j].Repos)
+		}
+		return result.OwnerClusters[i].Owner < result.OwnerClusters[j].Owner
+	})
+
+	result.SharedStargazers, err = database.ListSharedStargazers(2)
+	if err != nil {
+		return WeeklyReport{}, err
+	}
+	result.ContentClusters, err = database.ListContentClusters(2)
+	if err != nil {
+		return WeeklyReport{}, err
+	}
+
+	reports, err := database.ListAbuseReports("")
+	if err != nil {
+		return WeeklyReport{}, err
+	}
+	for _, abuse := range reports {
+		if abuse.Status != db.AbuseReportReported && abuse.Status != db.AbuseReportActioned {
+			continue
+		}
+		if abuse.StatusUpdatedAt.Before(since) || !abuse.StatusUpdatedAt.Before(until) {
+			continue
+		}
+		abuse.Body = ""
+		result.Reported = append(result.Reported, abuse)
+	}
+	sort.Slice(result.Reported, func(i, j int) bool {
+		return result.Reported[i].StatusUpdatedAt.Before(result.Reported[j].StatusUpdatedAt)
+	})
+	return result, nil
+}
+
+// WeeklyFileName is the dated base name, without extension, of the summary ending at until.
+func WeeklyFileName(until time.Time) string {
+	return "weekly-" + until.UTC().Format("2006-01-02")
+}
+
+// RenderWeeklyMarkdown formats a weekly summary as Markdown.
+func RenderWeeklyMarkdown(r WeeklyReport) string {
+	var sb strings.Builder
+	sb.WriteString("# GitHubWatchdog Weekly Summary\n\n")
+	fmt.Fprintf(&sb, "%s to %s\n", markdownDate(r.Since), markdownDate(r.Until))
+
+	sb.WriteString("\n## Scan Statistics\n\n")
+	fmt.Fprintf(&sb, "- Repositories processed: %d (%d malicious)\n", r.Stats.ReposProcessed, r.Stats.ReposMalicious)
+	fmt.Fprintf(&sb, "- Users processed: %d (%d suspicious)\n", r.Stats.UsersProcessed, r.Stats.UsersSuspicious)
+	fmt.Fprintf(&sb, "- Repositories taken down: %d (%d previously flagged)\n", r.Stats.ReposTakenDown, r.Stats.TakedownsConfirmed)
+	fmt.Fprintf(&sb, "- New flags by taxonomy category: %s\n", taxonomyCounts(r.Stats.FlagsByCategory))
+
+	sb.WriteString("\n## New Detections by Category\n\n")
+	if len(r.Categories) == 0 {
+		sb.WriteString("None.\n")
+	} else {
+		sb.WriteString("| Category | New flags |\n| --- | ---: |\n")
+		for _, count := range r.Categories {
+			fmt.Fprintf(&sb, "| %s | %d |\n", count.Name, count.Count)
+		}
+	}
+
+	sb.WriteString("\n## Top Heuristics\n\n")
+	if len(r.TopHeuristics) == 0 {
+		sb.WriteString("None.\n")
+	}
+	for _, count := range r.TopHeuristics {
+		fmt.Fprintf(&sb, "1. %s (%s) - %d\n", count.Name, count.Category, count.Count)
+	}
+
+	sb.WriteString("\n## Clusters\n\n### Owners with several newly flagged repositories\n\n")
+	if len(r.OwnerClusters) == 0 {
+		sb.WriteString("None.\n")
+	}
+	for _, cluster := range r.OwnerClusters {
+		fmt.Fprintf(&sb, "- [%s](https://github.com/%s): %s\n", cluster.Owner, cluster.Owner, strings.Join(cluster.Repos, ", "))
+	}
+	sb.WriteString("\n### Stargazers of several malicious repositories\n\n")
+	if len(r.SharedStargazers) == 0 {
+		sb.WriteString("None.\n")
+	}
+	for _, stargazer := range r.SharedStargazers {
+		fmt.Fprintf(&sb, "- [%s](https://github.com/%s): %s\n", stargazer.Username, stargazer.Username, strings.Join(stargazer.Repos, ", "))
+	}
+	sb.WriteString("\n### Identical content under different owners\n\n")
+	if len(r.ContentClusters) == 0 {
+		sb.WriteString("None.\n")
+	}
+	for _, cluster := range r.ContentClusters {
+		fmt.Fprintf(&sb, "- %s (%d owners): %s\n", cluster.ID, len(cluster.Owners), strings.Join(cluster.Repos, ", "))
+	}
+
+	sb.WriteString("\n## Reported to GitHub\n\n")
+	if len(r.Reported) == 0 {
+		sb.WriteString("None.\n")
+	}
+	for _, abuse := range r.Reported {
+		fmt.Fprintf(&sb, "- %s [%s](https://github.com/%s) - %s %s\n", abuse.EntityType, abuse.EntityID, abuse.EntityID, abuse.Status, markdownDate(abuse.StatusUpdatedAt))
+	}
+	return sb.String()
+}
+
+var weeklyHTMLTemplate = template.Must(template.New("weekly").Funcs(template.FuncMap{
+	"date":     markdownDate,
+	"join":     strings.Join,
+	"taxonomy": taxonomyCounts,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>GitHubWatchdog Weekly Summary {{date .Until}}</title>
+</head>
+<body>
+<h1>GitHubWatchdog Weekly Summary</h1>
+<p>{{date .Since}} to {{date .Until}}</p>
+<h2>Scan Statistics</h2>
+<ul>
+<li>Repositories processed: {{.Stats.ReposProcessed}} ({{.Stats.ReposMalicious}} malicious)</li>
+<li>Users processed: {{.Stats.UsersProcessed}} ({{.Stats.UsersSuspicious}} suspicious)</li>
+<li>Repositories taken down: {{.Stats.ReposTakenDown}} ({{.Stats.TakedownsConfirmed}} previously flagged)</li>
+<li>New flags by taxonomy category: {{taxonomy .Stats.FlagsByCategory}}</li>
+</ul>
+<h2>New Detections by Category</h2>
+{{if .Categories}}<table>
+<tr><th>Category</th><th>New flags</th></tr>
+{{range .Categories}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+{{else}}<p>None.</p>
+{{end}}<h2>Top Heuristics</h2>
+{{if .TopHeuristics}}<ol>
+{{range .TopHeuristics}}<li>{{.Name}} ({{.Category}}) - {{.Count}}</li>
+{{end}}</ol>
+{{else}}<p>None.</p>
+{{end}}<h2>Clusters</h2>
+<h3>Owners with several newly flagged repositories</h3>
+{{if .OwnerClusters}}<ul>
+{{range .OwnerClusters}}<li><a href="https://github.com/{{.Owner}}">{{.Owner}}</a>: {{join .Repos ", "}}</li>
+{{end}}</ul>
+{{else}}<p>None.</p>
+{{end}}<h3>Stargazers of several malicious repositories</h3>
+{{if .SharedStargazers}}<ul>
+{{range .SharedStargazers}}<li><a href="https://github.com/{{.Username}}">{{.Username}}</a>: {{join .Repos ", "}}</li>
+{{end}}</ul>
+{{else}}<p>None.</p>
+{{end}}<h3>Identical content under different owners</h3>
+{{if .ContentClusters}}<ul>
+{{range .ContentClusters}}<li>{{.ID}} ({{len .Owners}} owners): {{join .Repos ", "}}</li>
+{{end}}</ul>
+{{else}}<p>None.</p>
+{{end}}<h2>Reported to GitHub</h2>
+{{if .Reported}}<ul>
+{{range .Reported}}<li>{{.EntityType}} <a href="https://github.com/{{.EntityID}}">{{.EntityID}}</a> - {{.Status}} {{date .StatusUpdatedAt}}</li>
+{{end}}</ul>
+{{else}}<p>None.</p>
+{{end}}</body>
+</html>
+`))
+
+// taxonomyCounts lists flag counts by taxonomy category in name order, or "none".
+func taxonomyCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+	categories := make([]string, 0, len(counts))
+	for category := range counts {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	parts := make([]string, len(categories))
+	for i, category := range categories {
+		parts[i] = fmt.Sprintf("%s %d", category, counts[category])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// RenderWeeklyHTML formats a weekly summary as a standalone HTML page.
+func RenderWeeklyHTML(r WeeklyReport) (string, error) {
+	var buf bytes.Buffer
+	if err := weeklyHTMLTemplate.Execute(&buf, r); err != nil {
+		return "", fmt.Errorf("rendering weekly summary: %w", err)
+	}
+	return buf.String(), nil
+}
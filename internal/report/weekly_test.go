@@ -0,0 +1,107 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+)
+
+func TestWeeklyReportGolden(t *testing.T) {
+	database := newTestDatabase(t)
+	for _, repo := range []struct {
+		id, owner, name string
+		malicious       bool
+	}{
+		{"evil/loader", "evil", "loader", true},
+		{"evil/dropper", "evil", "dropper", true},
+		{"spam/portfolio", "spam", "portfolio", false},
+		{"old/tool", "old", "tool", false},
+	} {
+		if err := database.InsertProcessedRepo(repo.id, repo.owner, repo.name, time.Now(), 10, 5, repo.malicious); err != nil {
+			t.Fatalf("InsertProcessedRepo() error = %v", err)
+		}
+	}
+	if err := database.InsertProcessedUser("farmer", time.Now(), 7, 20, 18, 0, true, ""); err != nil {
+		t.Fatalf("InsertProcessedUser() error = %v", err)
+	}
+	flags := []struct{ entityType, entityID, flag string }{
+		{"repo", "evil/loader", "Phishing:SafeBrowsingHeuristic"},
+		{"repo", "evil/dropper", "Phishing:SafeBrowsingHeuristic"},
+		{"repo", "evil/dropper", "Malware:ExternalIndicatorHeuristic"},
+		{"repo", "spam/portfolio", "Spam Behavior:BoilerplateReadmeHeuristic"},
+		{"user", "farmer", "Mass Repository Creation:EmptyRepoHeuristic"},
+		{"repo", "old/tool", "Spam Behavior:BoilerplateReadmeHeuristic"},
+	}
+	for _, f := range flags {
+		if err := database.InsertHeuristicFlag(f.entityType, f.entityID, db.HeuristicName(f.flag), f.flag, ""); err != nil {
+			t.Fatalf("InsertHeuristicFlag() error = %v", err)
+		}
+	}
+	for _, repoID := range []string{"evil/loader", "evil/dropper"} {
+		if err := database.InsertStargazer(repoID, "booster", time.Now()); err != nil {
+			t.Fatalf("InsertStargazer() error = %v", err)
+		}
+	}
+	if err := database.InsertStargazer("evil/loader", "passerby", time.Now()); err != nil {
+		t.Fatalf("InsertStargazer() error = %v", err)
+	}
+	for _, target := range []string{"evil/loader", "spam/portfolio"} {
+		if err := database.SaveAbuseReport("repo", target, "body"); err != nil {
+			t.Fatalf("SaveAbuseReport() error = %v", err)
+		}
+	}
+	if err := database.SetAbuseReportStatus("repo", "evil/loader", "reported"); err != nil {
+		t.Fatalf("SetAbuseReportStatus() error = %v", err)
+	}
+	if err := database.SetRepoStatus("evil/loader", "evil", "loader", "blocked_dmca"); err != nil {
+		t.Fatalf("SetRepoStatus() error = %v", err)
+	}
+	if err := database.SetRepoStatus("gone/tool", "gone", "tool", "disabled"); err != nil {
+		t.Fatalf("SetRepoStatus() error = %v", err)
+	}
+	for _, repoID := range []string{"evil/dropper", "spam/portfolio"} {
+		if err := database.SetRepoFingerprint(repoID, "0123456789abcdef"); err != nil {
+			t.Fatalf("SetRepoFingerprint() error = %v", err)
+		}
+	}
+	if err := database.SetContentCluster("0123456789abcdef", "content-0123456789ab"); err != nil {
+		t.Fatalf("SetContentCluster() error = %v", err)
+	}
+	for _, stmt := range []string{
+		`UPDATE processed_repositories SET processed_at = '2026-03-10 12:00:00'`,
+		`UPDATE processed_repositories SET processed_at = '2026-02-01 12:00:00' WHERE repo_id = 'old/tool'`,
+		`UPDATE processed_users SET processed_at = '2026-03-12 08:00:00'`,
+		`UPDATE heuristic_flags SET triggered_at = '2026-03-11 09:30:00'`,
+		`UPDATE heuristic_flags SET triggered_at = '2026-02-01 12:00:00' WHERE entity_id = 'old/tool'`,
+		`UPDATE abuse_reports SET status_updated_at = '2026-03-13 10:00:00'`,
+	} {
+		if _, err := database.Exec(stmt); err != nil {
+			t.Fatalf("Exec(%q) error = %v", stmt, err)
+		}
+	}
+
+	until := time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)
+	result, err := BuildWeeklyReport(database, until.AddDate(0, 0, -7), until)
+	if err != nil {
+		t.Fatalf("BuildWeeklyReport() error = %v", err)
+	}
+	assertGolden(t, "weekly.md.golden", RenderWeeklyMarkdown(result))
+	html, err := RenderWeeklyHTML(result)
+	if err != nil {
+		t.Fatalf("RenderWeeklyHTML() error = %v", err)
+	}
+	assertGolden(t, "weekly.html.golden", html)
+}
+
+func TestWeeklyReportEmptyGolden(t *testing.T) {
+	until := time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)
+	result, err := BuildWeeklyReport(newTestDatabase(t), until.AddDate(0, 0, -7), until)
+	if err != nil {
+		t.Fatalf("BuildWeeklyReport() error = %v", err)
+	}
+	assertGolden(t, "weekly_empty.md.golden", RenderWeeklyMarkdown(result))
+	if got := WeeklyFileName(until); got != "weekly-2026-03-16" {
+		t.Fatalf("WeeklyFileName() = %q", got)
+	}
+}
@@ -0,0 +1,219 @@
+// Package safebrowsing provides URL reputation lookups against the Google Safe Browsing v4 API.
+package safebrowsing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
+)
+
+const (
+	defaultEndpoint = "https://safebrowsing.googleapis.com/v4/threatMatches:find"
+	// maxURLsPerRequest is the threatMatches:find limit on threat entries per request.
+	maxURLsPerRequest = 500
+	// cleanCacheTTL is how long a URL without matches is trusted before it is looked up again.
+	cleanCacheTTL = 30 * time.Minute
+	// defaultMatchCacheTTL is used when a match does not carry a cacheDuration.
+	defaultMatchCacheTTL = 5 * time.Minute
+)
+
+// Verdict is the Safe Browsing reputation of a single URL.
+type Verdict struct {
+	URL        string `json:"url"`
+	ThreatType string `json:"threat_type,omitempty"`
+	Platform   string `json:"platform,omitempty"`
+}
+
+// IsThreat reports whether Safe Browsing matched the URL against a threat list.
+func (v Verdict) IsThreat() bool {
+	return v.ThreatType != ""
+}
+
+// Client batches URL lookups and caches verdicts for the API's recommended durations.
+type Client struct {
+	httpClient *http.Client
+	apiKey     string
+	endpoint   string
+	logger     *logger.Logger
+
+	mutex sync.Mutex
+	cache map[string]cachedVerdict
+}
+
+type cachedVerdict struct {
+	verdict   Verdict
+	expiresAt time.Time
+}
+
+// NewClient creates a Safe Browsing client. An empty apiKey yields a disabled client whose lookups are no-ops.
+func NewClient(apiKey string, appLogger *logger.Logger) *Client {
+	if appLogger == nil {
+		appLogger = logger.New(false)
+	}
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		apiKey:     apiKey,
+		endpoint:   defaultEndpoint,
+		logger:     appLogger.For("safebrowsing"),
+		cache:      make(map[string]cachedVerdict),
+	}
+}
+
+// Enabled reports whether an API key is configured.
+func (c *Client) Enabled() bool {
+	return c != nil && c.apiKey != ""
+}
+
+// CheckURLs returns a verdict for every distinct URL, consulting the cache before the API.
+func (c *Client) CheckURLs(ctx context.Context, urls []string) ([]Verdict, error) {
+	if !c.Enabled() || len(urls) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	verdicts := make(map[string]Verdict, len(urls))
+	var pending []string
+
+	c.mutex.Lock()
+	for _, rawURL := range urls {
+		if _, seen := verdicts[rawURL]; seen {
+			continue
+		}
+		if entry, ok := c.cache[rawURL]; ok && now.Before(entry.expiresAt) {
+			verdicts[rawURL] = entry.verdict
+			continue
+		}
+		verdicts[rawURL] = Verdict{URL: rawURL}
+		pending = append(pending, rawURL)
+	}
+	c.mutex.Unlock()
+
+	for start := 0; start < len(pending); start += maxURLsPerRequest {
+		end := start + maxURLsPerRequest
+		if end > len(pending) {
+			end = len(pending)
+		}
+		batch := pending[start:end]
+		matches, err := c.findThreatMatches(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mutex.Lock()
+		for _, rawURL := range batch {
+			if match, ok := matches[rawURL]; ok {
+				verdicts[rawURL] = match.verdict
+				c.cache[rawURL] = match
+				continue
+			}
+			c.cache[rawURL] = cachedVerdict{verdict: Verdict{URL: rawURL}, expiresAt: now.Add(cleanCacheTTL)}
+		}
+		c.mutex.Unlock()
+	}
+
+	results := make([]Verdict, 0, len(verdicts))
+	for _, rawURL := range urls {
+		verdict, ok := verdicts[rawURL]
+		if !ok {
+			continue
+		}
+		results = append(results, verdict)
+		delete(verdicts, rawURL)
+	}
+	return results, nil
+}
+
+type threatMatchesRequest struct {
+	Client struct {
+		ClientID      string `json:"clientId"`
+		ClientVersion string `json:"clientVersion"`
+	} `json:"client"`
+	ThreatInfo struct {
+		ThreatTypes      []string      `json:"threatTypes"`
+		PlatformTypes    []string      `json:"platformTypes"`
+		ThreatEntryTypes []string      `json:"threatEntryTypes"`
+		ThreatEntries    []threatEntry `json:"threatEntries"`
+	} `json:"threatInfo"`
+}
+
+type threatEntry struct {
+	URL string `json:"url"`
+}
+
+type threatMatchesResponse struct {
+	Matches []struct {
+		ThreatType    string      `json:"threatType"`
+		PlatformType  string      `json:"platformType"`
+		Threat        threatEntry `json:"threat"`
+		CacheDuration string      `json:"cacheDuration"`
+	} `json:"matches"`
+}
+
+func (c *Client) findThreatMatches(ctx context.Context, urls []string) (map[string]cachedVerdict, error) {
+	var body threatMatchesRequest
+	body.Client.ClientID = "githubwatchdog"
+	body.Client.ClientVersion = "1.0"
+	body.ThreatInfo.ThreatTypes = []string{"MALWARE", "SOCIAL_ENGINEERING", "UNWANTED_SOFTWARE", "POTENTIALLY_HARMFUL_APPLICATION"}
+	body.ThreatInfo.PlatformTypes = []string{"ANY_PLATFORM"}
+	body.ThreatInfo.ThreatEntryTypes = []string{"URL"}
+	for _, rawURL := range urls {
+		body.ThreatInfo.ThreatEntries = append(body.ThreatInfo.ThreatEntries, threatEntry{URL: rawURL})
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("encoding safe browsing request: %w", err)
+	}
+
+	reqURL := c.endpoint + "?key=" + url.QueryEscape(c.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying safe browsing: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("safe browsing lookup failed: %s - %s", resp.Status, string(bodyBytes))
+	}
+
+	var decoded threatMatchesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("decoding safe browsing response: %w", err)
+	}
+
+	now := time.Now()
+	matches := make(map[string]cachedVerdict, len(decoded.Matches))
+	for _, match := range decoded.Matches {
+		ttl := defaultMatchCacheTTL
+		if match.CacheDuration != "" {
+			if d, err := time.ParseDuration(match.CacheDuration); err == nil {
+				ttl = d
+			}
+		}
+		matches[match.Threat.URL] = cachedVerdict{
+			verdict: Verdict{
+				URL:        match.Threat.URL,
+				ThreatType: match.ThreatType,
+				Platform:   match.PlatformType,
+			},
+			expiresAt: now.Add(ttl),
+		}
+	}
+	c.logger.Debug("Safe Browsing checked %d URLs, %d matches", len(urls), len(matches))
+	return matches, nil
+}
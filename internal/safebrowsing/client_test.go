@@ -0,0 +1,114 @@
+package safebrowsing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
+)
+
+func newFakeSafeBrowsing(t *testing.T, threats map[string]string, requests *int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.URL.Query().Get("key") != "test-key" {
+			t.Errorf("request key = %q, want test-key", r.URL.Query().Get("key"))
+		}
+
+		var body threatMatchesRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		if len(body.ThreatInfo.ThreatEntries) > maxURLsPerRequest {
+			t.Errorf("request carried %d entries, want at most %d", len(body.ThreatInfo.ThreatEntries), maxURLsPerRequest)
+		}
+
+		var resp threatMatchesResponse
+		for _, entry := range body.ThreatInfo.ThreatEntries {
+			threatType, ok := threats[entry.URL]
+			if !ok {
+				continue
+			}
+			resp.Matches = append(resp.Matches, struct {
+				ThreatType    string      `json:"threatType"`
+				PlatformType  string      `json:"platformType"`
+				Threat        threatEntry `json:"threat"`
+				CacheDuration string      `json:"cacheDuration"`
+			}{ThreatType: threatType, PlatformType: "ANY_PLATFORM", Threat: entry, CacheDuration: "300s"})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestCheckURLsFlagsMatchesAndCachesVerdicts(t *testing.T) {
+	var requests int32
+	server := newFakeSafeBrowsing(t, map[string]string{"http://bad.example/payload": "MALWARE"}, &requests)
+	defer server.Close()
+
+	client := NewClient("test-key", logger.New(false))
+	client.endpoint = server.URL
+
+	urls := []string{"http://bad.example/payload", "https://github.com/octocat"}
+	verdicts, err := client.CheckURLs(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("CheckURLs() error = %v", err)
+	}
+	if len(verdicts) != 2 {
+		t.Fatalf("CheckURLs() len = %d, want 2", len(verdicts))
+	}
+	if !verdicts[0].IsThreat() || verdicts[0].ThreatType != "MALWARE" {
+		t.Fatalf("verdicts[0] = %+v, want MALWARE match", verdicts[0])
+	}
+	if verdicts[1].IsThreat() {
+		t.Fatalf("verdicts[1] = %+v, want clean", verdicts[1])
+	}
+
+	if _, err := client.CheckURLs(context.Background(), urls); err != nil {
+		t.Fatalf("CheckURLs() cached error = %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Fatalf("requests = %d, want 1 after cached lookup", got)
+	}
+}
+
+func TestCheckURLsBatchesLargeLookups(t *testing.T) {
+	var requests int32
+	server := newFakeSafeBrowsing(t, nil, &requests)
+	defer server.Close()
+
+	client := NewClient("test-key", logger.New(false))
+	client.endpoint = server.URL
+
+	urls := make([]string, 0, 1200)
+	for i := 0; i < 1200; i++ {
+		urls = append(urls, fmt.Sprintf("https://example.com/%d", i))
+	}
+	verdicts, err := client.CheckURLs(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("CheckURLs() error = %v", err)
+	}
+	if len(verdicts) != len(urls) {
+		t.Fatalf("CheckURLs() len = %d, want %d", len(verdicts), len(urls))
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Fatalf("requests = %d, want 3 batches", got)
+	}
+}
+
+func TestCheckURLsWithoutKeyIsNoop(t *testing.T) {
+	client := NewClient("", logger.New(false))
+	client.endpoint = "http://127.0.0.1:0"
+
+	verdicts, err := client.CheckURLs(context.Background(), []string{"http://bad.example"})
+	if err != nil {
+		t.Fatalf("CheckURLs() error = %v", err)
+	}
+	if verdicts != nil {
+		t.Fatalf("CheckURLs() = %+v, want nil when disabled", verdicts)
+	}
+}
@@ -0,0 +1,95 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+const (
+	// DefaultAssetDownloadMaxBytes is the largest release asset downloaded for hashing.
+	DefaultAssetDownloadMaxBytes = 25 << 20
+	// DefaultAssetDownloadBudget is how many bytes of release assets one service downloads in
+	// total.
+	DefaultAssetDownloadBudget = 250 << 20
+)
+
+// AssetDownloadPolicy bounds the release assets downloaded for hashing. The zero value
+// downloads nothing.
+type AssetDownloadPolicy struct {
+	// MaxBytes skips assets larger than this.
+	MaxBytes int64
+	// Budget caps the bytes downloaded over the service's lifetime, which is one run of the
+	// CLI. Assets are skipped once it is spent.
+	Budget int64
+}
+
+// downloadBudget is the part of an AssetDownloadPolicy budget not yet reserved.
+type downloadBudget struct {
+	left atomic.Int64
+}
+
+// reserve takes n bytes from the budget, or reports false and takes nothing when fewer are left.
+func (b *downloadBudget) reserve(n int64) bool {
+	for {
+		left := b.left.Load()
+		if n > left {
+			return false
+		}
+		if b.left.CompareAndSwap(left, left-n) {
+			return true
+		}
+	}
+}
+
+// hashFlaggedAssets sets the Hash of each asset the release check flagged to the SHA-256 of its
+// content. GitHub's sha256 digest is used when the asset has one; other assets are downloaded
+// when the policy allows. Each download reserves the asset's listed size from the budget and
+// fails if the asset turns out larger, so the budget holds even against a misreported size.
+func (s *Service) hashFlaggedAssets(ctx context.Context, repo *RepoReport) {
+	for i := range repo.FlaggedAssets {
+		asset := &repo.FlaggedAssets[i]
+		if strings.HasPrefix(asset.Digest, "sha256:") {
+			asset.Hash = strings.ToLower(asset.Digest)
+			continue
+		}
+		if asset.ID == 0 || asset.Size <= 0 || asset.Size > s.assetDownloads.MaxBytes {
+			continue
+		}
+		if !s.assetBudget.reserve(asset.Size) {
+			repo.Errors = append(repo.Errors, fmt.Sprintf("not downloading release asset %s: download budget spent", asset.Name))
+			continue
+		}
+		hash, _, err := s.client.DownloadReleaseAsset(ctx, repo.Owner, repo.Name, asset.ID, asset.Size)
+		if err != nil {
+			repo.Errors = append(repo.Errors, fmt.Sprintf("hashing release asset %s: %v", asset.Name, err))
+			continue
+		}
+		asset.Hash = hash
+	}
+}
+
+// withContentHashes replaces the hashes of payloads with the content hashes of the flagged
+// assets of the same name, and appends hashed flagged assets that are not payloads, so every
+// hashed asset is correlated and recorded.
+func withContentHashes(payloads []models.ReleaseAsset, flagged []models.FlaggedAsset) []models.ReleaseAsset {
+	for _, asset := range flagged {
+		if !strings.HasPrefix(asset.Hash, "sha256:") {
+			continue
+		}
+		found := false
+		for i := range payloads {
+			if payloads[i].Name == asset.Name {
+				payloads[i].Hash = asset.Hash
+				found = true
+			}
+		}
+		if !found {
+			payloads = append(payloads, asset.ReleaseAsset)
+		}
+	}
+	return payloads
+}
@@ -0,0 +1,76 @@
+package scan
+
+import "time"
+
+// OldestTimestamp returns the earliest non-zero time, truncated to the second as search
+// qualifiers are, and how many of times fall in that second. An empty page, or one whose
+// results carry no timestamps, returns the zero time.
+func OldestTimestamp(times ...time.Time) (time.Time, int) {
+	var oldest time.Time
+	ties := 0
+	for _, t := range times {
+		if t.IsZero() {
+			continue
+		}
+		t = t.UTC().Truncate(time.Second)
+		switch {
+		case oldest.IsZero() || t.Before(oldest):
+			oldest, ties = t, 1
+		case t.Equal(oldest):
+			ties++
+		}
+	}
+	return oldest, ties
+}
+
+// mergeOldest folds a page's oldest timestamp into the oldest seen so far.
+func mergeOldest(oldest time.Time, ties int, page time.Time, pageTies int) (time.Time, int) {
+	switch {
+	case page.IsZero():
+		return oldest, ties
+	case oldest.IsZero() || page.Before(oldest):
+		return page, pageTies
+	case page.Equal(oldest):
+		return oldest, ties + pageTies
+	}
+	return oldest, ties
+}
+
+// NextBefore is the inclusive upper bound that continues a crawl whose oldest result is oldest,
+// with ties results in that second, after a run bounded by current. A lone oldest result is
+// stepped past. When several share the second, more may sit on pages the run did not reach,
+// so the bound keeps that second unless it is already the current bound, where repeating the
+// query would make no progress.
+func NextBefore(oldest time.Time, ties int, current string) string {
+	if oldest.IsZero() {
+		return ""
+	}
+	oldest = oldest.UTC().Truncate(time.Second)
+	if ties > 1 {
+		bound, err := parseSearchBoundary(current, true)
+		if err != nil || !bound.Truncate(time.Second).Equal(oldest) {
+			return oldest.Format(time.RFC3339)
+		}
+	}
+	return oldest.Add(-time.Second).Format(time.RFC3339)
+}
+
+// observeOldest records the oldest created and updated timestamps of a page of results.
+func (r *SearchReport) observeOldest(results []RepoReport) {
+	created := make([]time.Time, 0, len(results))
+	updated := make([]time.Time, 0, len(results))
+	for _, result := range results {
+		created = append(created, result.CreatedAt)
+		updated = append(updated, result.UpdatedAt)
+	}
+	pageCreated, createdTies := OldestTimestamp(created...)
+	pageUpdated, updatedTies := OldestTimestamp(updated...)
+	r.OldestCreatedAt, r.createdTies = mergeOldest(r.OldestCreatedAt, r.createdTies, pageCreated, createdTies)
+	r.OldestUpdatedAt, r.updatedTies = mergeOldest(r.OldestUpdatedAt, r.updatedTies, pageUpdated, updatedTies)
+}
+
+// setContinuation fills the next bounds that resume the crawl below the oldest results.
+func (r *SearchReport) setContinuation() {
+	r.NextCreatedBefore = NextBefore(r.OldestCreatedAt, r.createdTies, r.CreatedBefore)
+	r.NextUpdatedBefore = NextBefore(r.OldestUpdatedAt, r.updatedTies, r.UpdatedBefore)
+}
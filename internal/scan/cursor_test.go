@@ -0,0 +1,91 @@
+package scan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/github/githubtest"
+	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
+)
+
+func TestOldestTimestamp(t *testing.T) {
+	base := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		times    []time.Time
+		want     time.Time
+		wantTies int
+	}{
+		{name: "empty page"},
+		{name: "no timestamps", times: []time.Time{{}, {}}},
+		{name: "single item", times: []time.Time{base}, want: base, wantTies: 1},
+		{name: "zero after oldest is ignored", times: []time.Time{base, base.Add(time.Hour), {}}, want: base, wantTies: 1},
+		{name: "equal timestamps", times: []time.Time{base.Add(time.Hour), base, base}, want: base, wantTies: 2},
+		{name: "same second", times: []time.Time{base.Add(300 * time.Millisecond), base}, want: base, wantTies: 2},
+		{name: "other zones", times: []time.Time{base.In(time.FixedZone("CET", 3600)), base.Add(time.Minute)}, want: base, wantTies: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ties := OldestTimestamp(tc.times...)
+			if !got.Equal(tc.want) || ties != tc.wantTies {
+				t.Fatalf("OldestTimestamp() = %v, %d, want %v, %d", got, ties, tc.want, tc.wantTies)
+			}
+		})
+	}
+}
+
+func TestNextBefore(t *testing.T) {
+	oldest := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		oldest  time.Time
+		ties    int
+		current string
+		want    string
+	}{
+		{name: "no results", want: ""},
+		{name: "single oldest result", oldest: oldest, ties: 1, want: "2026-03-13T11:59:59Z"},
+		{name: "tied oldest results", oldest: oldest, ties: 3, current: "2026-03-14", want: "2026-03-13T12:00:00Z"},
+		{name: "tied at the current bound", oldest: oldest, ties: 3, current: "2026-03-13T12:00:00Z", want: "2026-03-13T11:59:59Z"},
+		{name: "tied at the end of a date bound", oldest: oldest.Add(12*time.Hour - time.Second), ties: 2, current: "2026-03-13", want: "2026-03-13T23:59:58Z"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NextBefore(tc.oldest, tc.ties, tc.current); got != tc.want {
+				t.Fatalf("NextBefore() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSearchContinuesBelowTiedOldestResults(t *testing.T) {
+	tied := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	server := githubtest.NewServer(t)
+	server.SetSearchResults(100,
+		githubtest.Repo{Owner: "alice", Name: "newest", CreatedAt: tied, UpdatedAt: tied.Add(time.Hour)},
+		githubtest.Repo{Owner: "bob", Name: "first", CreatedAt: tied, UpdatedAt: tied},
+		githubtest.Repo{Owner: "carol", Name: "second", CreatedAt: tied, UpdatedAt: tied},
+	)
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	service := NewService(client, nil)
+
+	report, err := service.Search(context.Background(), SearchOptions{Query: "stars:>1", PerPage: 100})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if report.NextUpdatedBefore != "2026-03-13T12:00:00Z" || report.NextCreatedBefore != "2026-03-13T12:00:00Z" {
+		t.Fatalf("Search() next bounds = %q, %q, want the tied second kept", report.NextUpdatedBefore, report.NextCreatedBefore)
+	}
+
+	resumed, err := service.Search(context.Background(), SearchOptions{Query: "stars:>1", PerPage: 100, UpdatedBefore: report.NextUpdatedBefore})
+	if err != nil {
+		t.Fatalf("Search(resumed) error = %v", err)
+	}
+	if len(resumed.Results) != 2 || resumed.NextUpdatedBefore != "2026-03-13T11:59:59Z" {
+		t.Fatalf("Search(resumed) = %d results, next %q, want 2 and a step past the tie", len(resumed.Results), resumed.NextUpdatedBefore)
+	}
+}
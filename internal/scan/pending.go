@@ -0,0 +1,219 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+)
+
+// DefaultPendingConcurrency bounds parallel analyses in AnalyzePending.
+const DefaultPendingConcurrency = 4
+
+// Outcomes of one entity in AnalyzePending.
+const (
+	PendingAnalyzed = "analyzed"
+	PendingFailed   = "error"
+	PendingSkipped  = "skipped"
+)
+
+// PendingOptions controls AnalyzePending and ReanalyzeUsers.
+type PendingOptions struct {
+	// MaxConcurrent bounds analyses in flight. Zero uses DefaultPendingConcurrency.
+	MaxConcurrent int
+	// Limit caps how many pending repositories and users are taken up; zero takes all of them.
+	Limit int
+	// OnResult, when set, receives each entity's result as it completes. Calls are serialized.
+	OnResult func(PendingResult)
+}
+
+// PendingResult is the outcome of analyzing one pending entity. Changed is set by
+// ReanalyzeUsers when the new verdict differs from the stored one.
+type PendingResult struct {
+	EntityType string `json:"entity_type"`
+	EntityID   string `json:"entity_id"`
+	Status     string `json:"status"`
+	Flagged    bool   `json:"flagged,omitempty"`
+	Changed    bool   `json:"changed,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// PendingReport summarizes an AnalyzePending or ReanalyzeUsers run. Skipped entities were not
+// started because the run was canceled, ran out of time, or hit the GitHub rate limit;
+// StopReason says which. Changed counts re-analyzed users whose verdict flipped.
+type PendingReport struct {
+	StartedAt   time.Time       `json:"started_at"`
+	CompletedAt time.Time       `json:"completed_at"`
+	Pending     int             `json:"pending"`
+	Analyzed    int             `json:"analyzed"`
+	Failed      int             `json:"failed"`
+	Skipped     int             `json:"skipped"`
+	Changed     int             `json:"changed"`
+	StopReason  string          `json:"stop_reason,omitempty"`
+	Results     []PendingResult `json:"results,omitempty"`
+}
+
+type pendingTarget struct {
+	entityType string
+	entityID   string
+	owner      string
+	name       string
+	// suspicious is the stored verdict of a user being re-analyzed.
+	suspicious bool
+}
+
+// AnalyzePending analyzes and persists the repositories and users the database recorded without
+// analyzing, such as legacy imports. Repositories come first, and their owners are only analyzed
+// when they are pending themselves. Once ctx is done or GitHub refuses a call for its rate limit,
+// no further entities are started and the rest are reported as skipped; they stay pending for
+// the next run.
+func (s *Service) AnalyzePending(ctx context.Context, opts PendingOptions) (PendingReport, error) {
+	report := PendingReport{StartedAt: time.Now().UTC()}
+	if s.db == nil {
+		return report, fmt.Errorf("analyzing pending entities: no database")
+	}
+
+	repos, err := s.db.ListPendingRepos(opts.Limit)
+	if err != nil {
+		return report, err
+	}
+	var targets []pendingTarget
+	for _, repo := range repos {
+		targets = append(targets, pendingTarget{entityType: "repo", entityID: repo.RepoID, owner: repo.Owner, name: repo.Name})
+	}
+	if opts.Limit <= 0 || len(targets) < opts.Limit {
+		users, err := s.db.ListPendingUsers(opts.Limit - len(targets))
+		if err != nil {
+			return report, err
+		}
+		for _, username := range users {
+			targets = append(targets, pendingTarget{entityType: "user", entityID: username})
+		}
+	}
+	return s.runPendingTargets(ctx, targets, opts, s.analyzePendingTarget), nil
+}
+
+// ReanalyzeUsers re-runs the current heuristics on every user with a stored verdict, least
+// recently analyzed first, fetching fresh data and persisting the new verdict and any new flags.
+// Flags that no longer fire are kept. It stops starting new users like AnalyzePending, and
+// counts the users whose verdict changed.
+func (s *Service) ReanalyzeUsers(ctx context.Context, opts PendingOptions) (PendingReport, error) {
+	if s.db == nil {
+		return PendingReport{StartedAt: time.Now().UTC()}, fmt.Errorf("re-analyzing users: no database")
+	}
+	users, err := s.db.ListAnalyzedUsers(opts.Limit)
+	if err != nil {
+		return PendingReport{StartedAt: time.Now().UTC()}, err
+	}
+	targets := make([]pendingTarget, 0, len(users))
+	for _, user := range users {
+		targets = append(targets, pendingTarget{entityType: "user", entityID: user.Login, suspicious: user.Suspicious})
+	}
+	return s.runPendingTargets(ctx, targets, opts, func(ctx context.Context, target pendingTarget) (PendingResult, error) {
+		result, err := s.analyzePendingTarget(ctx, target)
+		result.Changed = result.Status == PendingAnalyzed && result.Flagged != target.suspicious
+		return result, err
+	}), nil
+}
+
+// runPendingTargets analyzes targets with bounded concurrency until ctx is done or GitHub
+// refuses a call for its rate limit, reporting the targets not started as skipped.
+func (s *Service) runPendingTargets(ctx context.Context, targets []pendingTarget, opts PendingOptions, analyze func(context.Context, pendingTarget) (PendingResult, error)) PendingReport {
+	report := PendingReport{StartedAt: time.Now().UTC(), Pending: len(targets)}
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = DefaultPendingConcurrency
+	}
+
+	var (
+		mu         sync.Mutex
+		stopReason string
+		wg         sync.WaitGroup
+	)
+	record := func(result PendingResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch result.Status {
+		case PendingAnalyzed:
+			report.Analyzed++
+			if result.Changed {
+				report.Changed++
+			}
+		case PendingFailed:
+			report.Failed++
+		default:
+			report.Skipped++
+		}
+		report.Results = append(report.Results, result)
+		if opts.OnResult != nil {
+			opts.OnResult(result)
+		}
+	}
+	stopped := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if stopReason == "" && ctx.Err() != nil {
+			stopReason = ctx.Err().Error()
+		}
+		return stopReason != ""
+	}
+
+	sem := make(chan struct{}, opts.MaxConcurrent)
+	for _, target := range targets {
+		sem <- struct{}{}
+		if stopped() {
+			<-sem
+			record(PendingResult{EntityType: target.entityType, EntityID: target.entityID, Status: PendingSkipped})
+			continue
+		}
+		wg.Add(1)
+		go func(target pendingTarget) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result, err := analyze(ctx, target)
+			var rateErr *github.RateLimitError
+			if errors.As(err, &rateErr) {
+				mu.Lock()
+				if stopReason == "" {
+					stopReason = rateErr.Error()
+				}
+				mu.Unlock()
+			}
+			record(result)
+		}(target)
+	}
+	wg.Wait()
+
+	report.StopReason = stopReason
+	report.CompletedAt = time.Now().UTC()
+	return report
+}
+
+// analyzePendingTarget scans and persists one pending entity.
+func (s *Service) analyzePendingTarget(ctx context.Context, target pendingTarget) (PendingResult, error) {
+	result := PendingResult{EntityType: target.entityType, EntityID: target.entityID, Status: PendingAnalyzed}
+	var errs []string
+	var err error
+	if target.entityType == "repo" {
+		var repo RepoReport
+		repo, err = s.ScanRepository(ctx, target.owner, target.name, RepoOptions{Persist: true})
+		result.Flagged = repo.IsFlagged()
+		errs = repo.Errors
+	} else {
+		var user UserReport
+		user, err = s.ScanUser(ctx, target.entityID, UserOptions{Persist: true})
+		result.Flagged = user.Suspicious
+		errs = user.Errors
+	}
+	switch {
+	case err != nil:
+		result.Status = PendingFailed
+		result.Error = err.Error()
+	case len(errs) > 0:
+		result.Status = PendingFailed
+		result.Error = errs[0]
+	}
+	return result, err
+}
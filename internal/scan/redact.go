@@ -0,0 +1,41 @@
+package scan
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultStoredTextMaxChars caps persisted flag messages when no limit is configured.
+const DefaultStoredTextMaxChars = 1000
+
+var (
+	storedURLPattern   = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s"'<>)\]]+`)
+	storedEmailPattern = regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b`)
+)
+
+// StoredTextPolicy limits and scrubs free text, such as heuristic descriptions and external
+// command evidence, before it is persisted. Reports returned to callers keep the full text.
+type StoredTextPolicy struct {
+	// MaxChars truncates stored text to this many characters. Zero or less stores it whole.
+	MaxChars     int
+	RedactURLs   bool
+	RedactEmails bool
+}
+
+// Apply returns text as it should be stored.
+func (p StoredTextPolicy) Apply(text string) string {
+	if p.RedactURLs {
+		text = storedURLPattern.ReplaceAllStringFunc(text, func(match string) string {
+			trimmed := strings.TrimRight(match, ".,;:!?")
+			return "[url]" + match[len(trimmed):]
+		})
+	}
+	if p.RedactEmails {
+		text = storedEmailPattern.ReplaceAllString(text, "[email]")
+	}
+	if p.MaxChars > 0 && utf8.RuneCountInString(text) > p.MaxChars {
+		text = string([]rune(text)[:p.MaxChars]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,59 @@
+package scan
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+func TestStoredTextPolicyApply(t *testing.T) {
+	text := `External command flagged this entity: contact admin@evil.example or visit https://evil.example/get?id=1 and www.mirror.example/dl.`
+	tests := []struct {
+		name   string
+		policy StoredTextPolicy
+		want   string
+	}{
+		{"unchanged", StoredTextPolicy{}, text},
+		{"redact urls and emails", StoredTextPolicy{RedactURLs: true, RedactEmails: true}, "External command flagged this entity: contact [email] or visit [url] and [url]."},
+		{"redact emails only", StoredTextPolicy{RedactEmails: true}, "External command flagged this entity: contact [email] or visit https://evil.example/get?id=1 and www.mirror.example/dl."},
+		{"truncate", StoredTextPolicy{MaxChars: 16}, "External command..."},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.Apply(text); got != tt.want {
+			t.Fatalf("%s: Apply() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPersistUserStoresRedactedMessage(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	defer database.Close()
+
+	s := &Service{db: database, storedText: StoredTextPolicy{RedactURLs: true, RedactEmails: true}}
+	description := "External command flagged this entity: mail ops@evil.example, see https://evil.example/x"
+	report := UserReport{
+		Username:   "farmer",
+		Suspicious: true,
+		Heuristics: []models.HeuristicResult{{Category: "Other Suspicious Patterns", Name: "ExternalCommandHeuristic", Flag: true, Description: description}},
+	}
+	if err := s.persistUser(&report); err != nil {
+		t.Fatalf("persistUser() error = %v", err)
+	}
+	if report.Heuristics[0].Description != description {
+		t.Fatalf("persistUser() changed the reported description to %q", report.Heuristics[0].Description)
+	}
+
+	flags, err := database.ListHeuristicFlags("user", "farmer")
+	if err != nil {
+		t.Fatalf("ListHeuristicFlags() error = %v", err)
+	}
+	want := "External command flagged this entity: mail [email], see [url]"
+	if len(flags) != 1 || flags[0].Message != want {
+		t.Fatalf("stored flags = %+v, want message %q", flags, want)
+	}
+}
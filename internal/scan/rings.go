@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+)
+
+const (
+	// StarringRingHeuristic names the user flag recorded for starring ring members.
+	StarringRingHeuristic = "StarringRingHeuristic"
+	// StarringRingFlag is the Category:Name flag recorded for starring ring members.
+	StarringRingFlag = "Automated Activity:" + StarringRingHeuristic
+	// DefaultRingMinRepos is how many malicious repositories an account must have starred to be
+	// counted as a ring member.
+	DefaultRingMinRepos = 3
+)
+
+// RingOptions configures DetectStarringRings.
+type RingOptions struct {
+	// MinRepos is how many repositories a member must have starred; DefaultRingMinRepos when
+	// not positive.
+	MinRepos int
+	// Flagged counts every flagged repository, not only malicious ones.
+	Flagged bool
+	// Record flags each member in the database.
+	Record bool
+}
+
+// RingReport lists the accounts that starred several malicious or, with Flagged, flagged
+// repositories.
+type RingReport struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	MinRepos    int                  `json:"min_repos"`
+	Flagged     bool                 `json:"flagged,omitempty"`
+	Members     []db.SharedStargazer `json:"members"`
+	// Recorded is set when the members were flagged in the database.
+	Recorded bool `json:"recorded"`
+}
+
+// DetectStarringRings finds accounts recorded as stargazers of at least opts.MinRepos malicious
+// repositories, or flagged ones with opts.Flagged. With opts.Record set, each member gets a
+// StarringRingHeuristic flag listing the shared repositories, and members never analyzed are
+// added as pending suspicious users. Stargazers are only recorded by the star farm check and
+// the fetch_stargazers response, so rings are found among the repositories those covered.
+func DetectStarringRings(database *db.Database, opts RingOptions) (RingReport, error) {
+	minRepos := opts.MinRepos
+	if minRepos <= 0 {
+		minRepos = DefaultRingMinRepos
+	}
+	report := RingReport{GeneratedAt: time.Now().UTC(), MinRepos: minRepos, Flagged: opts.Flagged}
+	lookup := database.ListSharedStargazers
+	if opts.Flagged {
+		lookup = database.GetStargazerOverlap
+	}
+	members, err := lookup(minRepos)
+	if err != nil {
+		return report, err
+	}
+	report.Members = members
+	if report.Members == nil {
+		report.Members = []db.SharedStargazer{}
+	}
+	if !opts.Record {
+		return report, nil
+	}
+
+	kind := "malicious"
+	if opts.Flagged {
+		kind = "flagged"
+	}
+	for _, member := range members {
+		if _, err := database.InsertProcessedUserIfAbsent(member.Username, true); err != nil {
+			return report, err
+		}
+		message := fmt.Sprintf("Starred %d %s repositories: %s.", len(member.Repos), kind, strings.Join(member.Repos, ", "))
+		if err := database.InsertHeuristicFlag("user", member.Username, StarringRingHeuristic, StarringRingFlag, message); err != nil {
+			return report, err
+		}
+	}
+	report.Recorded = true
+	return report, nil
+}
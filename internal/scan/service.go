@@ -3,6 +3,8 @@ package scan
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,13 +12,69 @@ import (
 	"github.com/arkouda/github/GitHubWatchdog/internal/db"
 	"github.com/arkouda/github/GitHubWatchdog/internal/github"
 	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+	"github.com/arkouda/github/GitHubWatchdog/internal/notify"
+	"github.com/arkouda/github/GitHubWatchdog/internal/safebrowsing"
+	"github.com/arkouda/github/GitHubWatchdog/internal/urlscan"
 )
 
+// Responses to discovering a malicious repository, from cheapest to most expensive.
+const (
+	OnMaliciousNone                      = "none"
+	OnMaliciousFetchStargazers           = "fetch_stargazers"
+	OnMaliciousFetchStargazersAndAnalyze = "fetch_stargazers_and_analyze"
+)
+
+// DefaultMaxStargazers bounds how many stargazers are fetched for one malicious repository.
+const DefaultMaxStargazers = 300
+
+// stargazerAnalysisConcurrency bounds parallel user analyses when expanding from a malicious repository.
+const stargazerAnalysisConcurrency = 5
+
 // Service coordinates GitHub scanning, heuristic analysis, and optional persistence.
 type Service struct {
-	client   *github.Client
-	analyzer *analyzer.Analyzer
-	db       *db.Database
+	client        github.GitHubAPI
+	analyzer      *analyzer.Analyzer
+	db            *db.Database
+	safeBrowsing  *safebrowsing.Client
+	urlScan       *urlscan.Client
+	onMalicious   string
+	maxStargazers int
+	storedText    StoredTextPolicy
+	coalesce      bool
+	reuseOwners   bool
+	ownerTTL      time.Duration
+	notifier      notify.Notifier
+	// assetDownloads bounds the flagged release assets downloaded for hashing, and assetBudget
+	// is what is left of its budget.
+	assetDownloads AssetDownloadPolicy
+	assetBudget    downloadBudget
+}
+
+// ServiceOptions configures optional integrations used while scanning.
+type ServiceOptions struct {
+	SafeBrowsing *safebrowsing.Client
+	URLScan      *urlscan.Client
+	Analyzer     analyzer.Options
+	// OnMalicious selects how far to expand from a malicious repository. Empty means OnMaliciousNone.
+	OnMalicious string
+	// MaxStargazers caps stargazers fetched per malicious repository. Zero uses DefaultMaxStargazers.
+	MaxStargazers int
+	// StoredText limits and redacts flag messages before they are persisted.
+	StoredText StoredTextPolicy
+	// CoalesceOwners fast-tracks the remaining repositories of an owner found suspicious
+	// earlier in the same search page, skipping their file checks.
+	CoalesceOwners bool
+	// ReuseOwnerAnalysis reports an owner's stored analysis instead of analyzing them again
+	// while it is younger than OwnerAnalysisTTL, unless the scanned repository is newer than it.
+	// A zero OwnerAnalysisTTL reuses it for good.
+	ReuseOwnerAnalysis bool
+	OwnerAnalysisTTL   time.Duration
+	// Notifier, when set, is told about every persisted user or repository that gains flags
+	// it did not have.
+	Notifier notify.Notifier
+	// AssetDownload, when its MaxBytes is positive, downloads the release assets the release
+	// check flags and records the SHA-256 of their content.
+	AssetDownload AssetDownloadPolicy
 }
 
 // SearchOptions controls batch repository scanning.
@@ -35,6 +93,31 @@ type SearchOptions struct {
 	PerPage        int
 	MaxConcurrent  int
 	Persist        bool
+	// IncompleteRetries is how many more times a page is requested while GitHub reports its
+	// results as incomplete.
+	IncompleteRetries int
+	// Band skips results outside a star and size range without analyzing them.
+	Band RepoBand
+}
+
+// RepoBand is a range of stargazer counts and sizes in KB. A zero maximum leaves that side open.
+type RepoBand struct {
+	MinStars, MaxStars int
+	MinSize, MaxSize   int
+}
+
+// bandSkipReason is the SkipReason of repositories outside the search band.
+const bandSkipReason = "repository outside the configured star/size band"
+
+// allowlistSkipReason marks repositories an operator cleared with the allowlist command.
+const allowlistSkipReason = "repository is allowlisted"
+
+// Contains reports whether a repository with the given stars and size is inside the band.
+func (b RepoBand) Contains(stars, size int) bool {
+	if stars < b.MinStars || (b.MaxStars > 0 && stars > b.MaxStars) {
+		return false
+	}
+	return size >= b.MinSize && (b.MaxSize <= 0 || size <= b.MaxSize)
 }
 
 // RepoOptions controls direct repository scanning.
@@ -43,6 +126,13 @@ type RepoOptions struct {
 	SkipIfUnchanged  bool
 	AnalyzeOwner     bool
 	OwnerIfSmallOnly bool
+	// Band skips repositories outside it, recording them as processed at their revision when
+	// persisting so later searches skip them without a lookup.
+	Band RepoBand
+
+	// followedMove is set when scanning a repository under the new name it moved to, so a
+	// second redirect is reported instead of followed.
+	followedMove bool
 }
 
 // UserOptions controls direct user scanning.
@@ -52,68 +142,199 @@ type UserOptions struct {
 
 // SearchReport is the machine-readable output from a search scan.
 type SearchReport struct {
-	CheckpointName    string       `json:"checkpoint_name,omitempty"`
-	ProfileName       string       `json:"profile_name,omitempty"`
-	Activity          string       `json:"activity,omitempty"`
-	BaseQuery         string       `json:"base_query,omitempty"`
-	Query             string       `json:"query"`
-	Queries           []string     `json:"queries,omitempty"`
-	Since             string       `json:"since,omitempty"`
-	CreatedSince      string       `json:"created_since,omitempty"`
-	CreatedBefore     string       `json:"created_before,omitempty"`
-	UpdatedSince      string       `json:"updated_since,omitempty"`
-	UpdatedBefore     string       `json:"updated_before,omitempty"`
-	NextCreatedBefore string       `json:"next_created_before,omitempty"`
-	NextUpdatedBefore string       `json:"next_updated_before,omitempty"`
-	StartedAt         time.Time    `json:"started_at"`
-	OldestCreatedAt   time.Time    `json:"oldest_created_at,omitempty"`
-	CompletedAt       time.Time    `json:"completed_at"`
-	OldestUpdatedAt   time.Time    `json:"oldest_updated_at,omitempty"`
-	Results           []RepoReport `json:"results"`
+	CheckpointName    string    `json:"checkpoint_name,omitempty"`
+	ProfileName       string    `json:"profile_name,omitempty"`
+	Activity          string    `json:"activity,omitempty"`
+	BaseQuery         string    `json:"base_query,omitempty"`
+	Query             string    `json:"query"`
+	Queries           []string  `json:"queries,omitempty"`
+	Since             string    `json:"since,omitempty"`
+	CreatedSince      string    `json:"created_since,omitempty"`
+	CreatedBefore     string    `json:"created_before,omitempty"`
+	UpdatedSince      string    `json:"updated_since,omitempty"`
+	UpdatedBefore     string    `json:"updated_before,omitempty"`
+	NextCreatedBefore string    `json:"next_created_before,omitempty"`
+	NextUpdatedBefore string    `json:"next_updated_before,omitempty"`
+	StartedAt         time.Time `json:"started_at"`
+	OldestCreatedAt   time.Time `json:"oldest_created_at,omitempty"`
+	CompletedAt       time.Time `json:"completed_at"`
+	OldestUpdatedAt   time.Time `json:"oldest_updated_at,omitempty"`
+	// IncompletePages are the pages GitHub still returned partially after any retries.
+	IncompletePages []IncompletePage `json:"incomplete_pages,omitempty"`
+	// BandFiltered counts results skipped for falling outside the star/size band.
+	BandFiltered int `json:"band_filtered,omitempty"`
+	// UserAnalysis counts owner and stargazer analyses made by this service's analyzer.
+	UserAnalysis analyzer.Stats `json:"user_analysis"`
+	Results      []RepoReport   `json:"results"`
+
+	// createdTies and updatedTies count results in the second of the oldest timestamps.
+	createdTies, updatedTies int
+}
+
+// IncompletePage is a search page whose results timed out on GitHub, so repositories on it may
+// have been missed.
+type IncompletePage struct {
+	Query string `json:"query"`
+	Page  int    `json:"page"`
 }
 
 // RepoReport is the machine-readable output from a repository scan.
 type RepoReport struct {
-	RepoID        string                   `json:"repo_id"`
-	Owner         string                   `json:"owner"`
-	Name          string                   `json:"name"`
-	DefaultBranch string                   `json:"default_branch,omitempty"`
-	CreatedAt     time.Time                `json:"created_at"`
-	UpdatedAt     time.Time                `json:"updated_at"`
-	DiskUsage     int                      `json:"disk_usage"`
-	Stargazers    int                      `json:"stargazers"`
-	ReadmePresent bool                     `json:"readme_present"`
-	FileCount     int                      `json:"file_count"`
-	Skipped       bool                     `json:"skipped,omitempty"`
-	SkipReason    string                   `json:"skip_reason,omitempty"`
-	IsMalicious   bool                     `json:"is_malicious"`
-	RepoFlags     []models.HeuristicResult `json:"repo_flags,omitempty"`
-	OwnerAnalysis *UserReport              `json:"owner_analysis,omitempty"`
-	Persisted     bool                     `json:"persisted"`
-	Errors        []string                 `json:"errors,omitempty"`
-}
-
-// UserReport is the machine-readable output from a user scan.
+	RepoID        string    `json:"repo_id"`
+	GitHubID      int64     `json:"github_id,omitempty"` // stable across renames
+	NodeID        string    `json:"node_id,omitempty"`
+	Owner         string    `json:"owner"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description,omitempty"`
+	DefaultBranch string    `json:"default_branch,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	DiskUsage     int       `json:"disk_usage"`
+	Stargazers    int       `json:"stargazers"`
+	ReadmePresent bool      `json:"readme_present"`
+	FileCount     int       `json:"file_count"`
+	// Topics and Languages are the repository's GitHub topics and bytes of code per language.
+	Topics    []string       `json:"topics,omitempty"`
+	Languages map[string]int `json:"languages,omitempty"`
+	// Fingerprint hashes the tree paths and README; ContentCluster is set when other owners
+	// hold repositories with the same fingerprint.
+	Fingerprint    string `json:"fingerprint,omitempty"`
+	ContentCluster string `json:"content_cluster,omitempty"`
+	// PayloadAssets are the archives and executables the repository's releases ship, and the
+	// flagged assets whose content was hashed, with the hash they are correlated by.
+	PayloadAssets []models.ReleaseAsset `json:"payload_assets,omitempty"`
+	// FlaggedAssets are the release assets the release check flagged, with the reasons. Their
+	// hash is the SHA-256 of the content when GitHub has a digest or the asset was downloaded.
+	FlaggedAssets []models.FlaggedAsset `json:"flagged_assets,omitempty"`
+	// LoneStargazerFraction is the share of sampled stargazers that starred nothing else, when
+	// the lone stargazer check ran.
+	LoneStargazerFraction float64 `json:"lone_stargazer_fraction,omitempty"`
+	// StargazerMedianAgeDays is the median age of the sampled stargazer accounts when they
+	// starred, when the star farm check ran. StarFarmStargazers lists the sampled logins when it
+	// flagged the repository.
+	StargazerMedianAgeDays float64  `json:"stargazer_median_age_days,omitempty"`
+	StarFarmStargazers     []string `json:"star_farm_stargazers,omitempty"`
+	// RenamedFrom is the stale ID a scan was redirected from after the repository moved.
+	RenamedFrom string `json:"renamed_from,omitempty"`
+	// Status is blocked_dmca or disabled when GitHub no longer serves the repository, or
+	// deleted_on_github when a previously scanned repository is gone. A takedown is confirmed
+	// when the repository was flagged before it became unavailable.
+	Status            string `json:"status,omitempty"`
+	TakedownConfirmed bool   `json:"takedown_confirmed,omitempty"`
+	Skipped           bool   `json:"skipped,omitempty"`
+	SkipReason        string `json:"skip_reason,omitempty"`
+	// FastTracked is set when file checks were skipped because the owner was already found
+	// suspicious in the same search page.
+	FastTracked bool `json:"fast_tracked,omitempty"`
+	IsMalicious bool `json:"is_malicious"`
+	// CheckerResults has one entry per repository checker; IsMalicious is derived from them.
+	CheckerResults []models.CheckerResult   `json:"checker_results,omitempty"`
+	RepoFlags      []models.HeuristicResult `json:"repo_flags,omitempty"`
+	// HeldFlags fired but fell short of the evidence policy, so they are reported but not
+	// recorded.
+	HeldFlags    []models.HeuristicResult `json:"held_flags,omitempty"`
+	LinkVerdicts []safebrowsing.Verdict   `json:"link_verdicts,omitempty"`
+	URLScans     []urlscan.Result         `json:"url_scans,omitempty"`
+	// StargazerLogins and StargazerAnalyses are filled when OnMalicious expands from a malicious repo.
+	StargazerLogins   []string     `json:"stargazer_logins,omitempty"`
+	StargazerAnalyses []UserReport `json:"stargazer_analyses,omitempty"`
+	OwnerAnalysis     *UserReport  `json:"owner_analysis,omitempty"`
+	Persisted         bool         `json:"persisted"`
+	Errors            []string     `json:"errors,omitempty"`
+
+	// payloadsChecked is set once the release assets were listed, so persisting replaces the
+	// stored ones; sharedPayload is the asset that raised the shared payload flag.
+	payloadsChecked bool
+	sharedPayload   *models.ReleaseAsset
+	// starFarmStargazers are the stargazers sampled by a flagging star farm check, stored for
+	// cross-referencing with other repositories.
+	starFarmStargazers []models.Stargazer
+}
+
+// UserReport is the machine-readable output from a user scan. PreviousGitHubID is set when the
+// login was stored under another account's ID, meaning it was deleted and registered again.
+// Reused is set when a search reported the owner's stored analysis instead of a new one.
 type UserReport struct {
-	Username             string                   `json:"username"`
-	CreatedAt            time.Time                `json:"created_at"`
-	Contributions        int                      `json:"contributions"`
-	TotalStars           int                      `json:"total_stars"`
-	EmptyCount           int                      `json:"empty_count"`
-	SuspiciousEmptyCount int                      `json:"suspicious_empty_count"`
-	Suspicious           bool                     `json:"is_suspicious"`
-	Heuristics           []models.HeuristicResult `json:"heuristics,omitempty"`
-	Persisted            bool                     `json:"persisted"`
-	Errors               []string                 `json:"errors,omitempty"`
+	Username             string    `json:"username"`
+	GitHubID             int64     `json:"github_id,omitempty"`
+	NodeID               string    `json:"node_id,omitempty"`
+	PreviousGitHubID     int64     `json:"previous_github_id,omitempty"`
+	CreatedAt            time.Time `json:"created_at"`
+	Contributions        int       `json:"contributions"`
+	Followers            int       `json:"followers"`
+	Following            int       `json:"following"`
+	ForkCount            int       `json:"fork_count"`
+	OwnerType            string    `json:"owner_type,omitempty"`
+	Score                float64   `json:"score"`
+	TotalStars           int       `json:"total_stars"`
+	EmptyCount           int       `json:"empty_count"`
+	SuspiciousEmptyCount int       `json:"suspicious_empty_count"`
+	TemplateUniformity   float64   `json:"template_uniformity"`
+	CommitSampled        int       `json:"commit_sampled,omitempty"`
+	SingleCommitFraction float64   `json:"single_commit_fraction,omitempty"`
+	Tier                 string    `json:"tier,omitempty"`
+	AvatarHash           string    `json:"avatar_hash,omitempty"`
+	Suspicious           bool      `json:"is_suspicious"`
+	InsufficientEvidence bool      `json:"insufficient_evidence,omitempty"`
+	// Allowlisted users are cleared without analysis and not persisted.
+	Allowlisted bool                     `json:"allowlisted,omitempty"`
+	Heuristics  []models.HeuristicResult `json:"heuristics,omitempty"`
+	Reused      bool                     `json:"reused,omitempty"`
+	Persisted   bool                     `json:"persisted"`
+	Errors      []string                 `json:"errors,omitempty"`
 }
 
 // NewService creates a new scan service.
-func NewService(client *github.Client, database *db.Database) *Service {
-	return &Service{
-		client:   client,
-		analyzer: analyzer.New(client),
-		db:       database,
+func NewService(client github.GitHubAPI, database *db.Database) *Service {
+	return NewServiceWithOptions(client, database, ServiceOptions{})
+}
+
+// NewServiceWithOptions creates a new scan service with optional integrations.
+func NewServiceWithOptions(client github.GitHubAPI, database *db.Database, opts ServiceOptions) *Service {
+	maxStargazers := opts.MaxStargazers
+	if maxStargazers <= 0 {
+		maxStargazers = DefaultMaxStargazers
+	}
+	if opts.Analyzer.Indicators == nil && database != nil {
+		opts.Analyzer.Indicators = database
+	}
+	if opts.Analyzer.Allowlist == nil && database != nil {
+		opts.Analyzer.Allowlist = database
+	}
+	if opts.Analyzer.Fingerprints == nil && database != nil {
+		opts.Analyzer.Fingerprints = database
+	}
+	if opts.Analyzer.AssetHashes == nil && database != nil {
+		opts.Analyzer.AssetHashes = database
+	}
+	if opts.Analyzer.AvatarHashes == nil && database != nil {
+		opts.Analyzer.AvatarHashes = database
+	}
+	s := &Service{
+		client:         client,
+		analyzer:       analyzer.NewWithOptions(client, opts.Analyzer),
+		db:             database,
+		safeBrowsing:   opts.SafeBrowsing,
+		urlScan:        opts.URLScan,
+		onMalicious:    firstNonEmpty(opts.OnMalicious, OnMaliciousNone),
+		maxStargazers:  maxStargazers,
+		storedText:     opts.StoredText,
+		coalesce:       opts.CoalesceOwners,
+		reuseOwners:    opts.ReuseOwnerAnalysis,
+		ownerTTL:       opts.OwnerAnalysisTTL,
+		notifier:       opts.Notifier,
+		assetDownloads: opts.AssetDownload,
+	}
+	s.assetBudget.left.Store(opts.AssetDownload.Budget)
+	return s
+}
+
+// Close flushes pending notifications, waiting until ctx is done at most.
+func (s *Service) Close(ctx context.Context) error {
+	if s.notifier == nil {
+		return nil
 	}
+	return s.notifier.Close(ctx)
 }
 
 // Search scans repositories matching the provided search query.
@@ -147,10 +368,13 @@ func (s *Service) SearchStream(ctx context.Context, opts SearchOptions, onResult
 	seenRepoIDs := make(map[string]struct{})
 	for _, query := range queries {
 		for page := 1; page <= opts.MaxPages; page++ {
-			result, err := s.client.SearchRepositories(ctx, query, page, opts.PerPage)
+			result, err := s.searchPage(ctx, query, page, opts)
 			if err != nil {
 				return report, err
 			}
+			if result.IncompleteResults {
+				report.IncompletePages = append(report.IncompletePages, IncompletePage{Query: query, Page: page})
+			}
 			rawCount := len(result.Items)
 			if rawCount == 0 {
 				break
@@ -170,6 +394,11 @@ func (s *Service) SearchStream(ctx context.Context, opts SearchOptions, onResult
 
 			pageResults, err := s.processSearchPage(ctx, filteredItems, opts, onResult, &report)
 			report.Results = append(report.Results, pageResults...)
+			for _, result := range pageResults {
+				if result.SkipReason == bandSkipReason {
+					report.BandFiltered++
+				}
+			}
 			if err != nil {
 				return report, err
 			}
@@ -181,6 +410,8 @@ func (s *Service) SearchStream(ctx context.Context, opts SearchOptions, onResult
 	}
 
 	report.CompletedAt = time.Now().UTC()
+	report.UserAnalysis = s.analyzer.Stats()
+	report.setContinuation()
 	return report, nil
 }
 
@@ -293,13 +524,58 @@ func (s *Service) processSearchPage(
 	opts SearchOptions,
 	onResult func(RepoReport) error,
 	report *SearchReport,
+) ([]RepoReport, error) {
+	repoOpts := RepoOptions{
+		Persist:          opts.Persist,
+		SkipIfUnchanged:  true,
+		AnalyzeOwner:     true,
+		OwnerIfSmallOnly: true,
+		Band:             opts.Band,
+	}
+	scanItem := func(item models.RepoItem) RepoReport {
+		return s.scanRepoItem(ctx, item, repoOpts)
+	}
+	if !s.coalesce {
+		return s.scanBatch(items, opts.MaxConcurrent, scanItem, onResult, report)
+	}
+
+	// Scan one repository per owner first so that owners confirmed suspicious can have the
+	// rest of their repositories fast-tracked instead of each paying for file checks.
+	leaders, followers := splitByOwner(items)
+	pageResults, err := s.scanBatch(leaders, opts.MaxConcurrent, scanItem, onResult, report)
+	if err != nil || len(followers) == 0 {
+		return pageResults, err
+	}
+	suspicious := make(map[string]*UserReport)
+	for _, result := range pageResults {
+		if result.OwnerAnalysis != nil && result.OwnerAnalysis.Suspicious {
+			suspicious[result.Owner] = result.OwnerAnalysis
+		}
+	}
+	followerResults, err := s.scanBatch(followers, opts.MaxConcurrent, func(item models.RepoItem) RepoReport {
+		if owner, ok := suspicious[item.Owner.Login]; ok {
+			return s.fastTrackRepoItem(ctx, item, repoOpts, owner)
+		}
+		return scanItem(item)
+	}, onResult, report)
+	return append(pageResults, followerResults...), err
+}
+
+// scanBatch scans items with at most maxConcurrent in flight, updating the oldest timestamps
+// in report and passing each result to onResult as it completes.
+func (s *Service) scanBatch(
+	items []models.RepoItem,
+	maxConcurrent int,
+	scanItem func(models.RepoItem) RepoReport,
+	onResult func(RepoReport) error,
+	report *SearchReport,
 ) ([]RepoReport, error) {
 	type pageResult struct {
 		report RepoReport
 	}
 
 	resultsCh := make(chan pageResult, len(items))
-	sem := make(chan struct{}, opts.MaxConcurrent)
+	sem := make(chan struct{}, maxConcurrent)
 	var wg sync.WaitGroup
 
 	for _, item := range items {
@@ -310,14 +586,7 @@ func (s *Service) processSearchPage(
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			resultsCh <- pageResult{
-				report: s.scanRepoItem(ctx, item, RepoOptions{
-					Persist:          opts.Persist,
-					SkipIfUnchanged:  true,
-					AnalyzeOwner:     true,
-					OwnerIfSmallOnly: true,
-				}),
-			}
+			resultsCh <- pageResult{report: scanItem(item)}
 		}()
 	}
 
@@ -330,17 +599,12 @@ func (s *Service) processSearchPage(
 	var callbackErr error
 	for result := range resultsCh {
 		pageResults = append(pageResults, result.report)
-		if report.OldestCreatedAt.IsZero() || result.report.CreatedAt.Before(report.OldestCreatedAt) {
-			report.OldestCreatedAt = result.report.CreatedAt
-		}
-		if report.OldestUpdatedAt.IsZero() || result.report.UpdatedAt.Before(report.OldestUpdatedAt) {
-			report.OldestUpdatedAt = result.report.UpdatedAt
-		}
 		if callbackErr == nil && onResult != nil {
 			callbackErr = onResult(result.report)
 		}
 	}
 
+	report.observeOldest(pageResults)
 	if callbackErr != nil {
 		return pageResults, callbackErr
 	}
@@ -348,6 +612,30 @@ func (s *Service) processSearchPage(
 	return pageResults, nil
 }
 
+// splitByOwner returns the first item of each owner, in page order, and the remaining items.
+func splitByOwner(items []models.RepoItem) (leaders, followers []models.RepoItem) {
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if seen[item.Owner.Login] {
+			followers = append(followers, item)
+			continue
+		}
+		seen[item.Owner.Login] = true
+		leaders = append(leaders, item)
+	}
+	return leaders, followers
+}
+
+// searchPage fetches one search page, asking again up to opts.IncompleteRetries times while
+// GitHub reports its results as incomplete.
+func (s *Service) searchPage(ctx context.Context, query string, page int, opts SearchOptions) (*models.SearchResult, error) {
+	result, err := s.client.SearchRepositories(ctx, query, page, opts.PerPage)
+	for retry := 0; err == nil && result.IncompleteResults && retry < opts.IncompleteRetries; retry++ {
+		result, err = s.client.SearchRepositories(ctx, query, page, opts.PerPage)
+	}
+	return result, err
+}
+
 // ScanRepository scans a specific repository by owner/name.
 func (s *Service) ScanRepository(ctx context.Context, owner, name string, opts RepoOptions) (RepoReport, error) {
 	query := fmt.Sprintf("repo:%s/%s", owner, name)
@@ -356,7 +644,28 @@ func (s *Service) ScanRepository(ctx context.Context, owner, name string, opts R
 		return RepoReport{}, err
 	}
 	if len(result.Items) == 0 {
-		return RepoReport{}, fmt.Errorf("repository %s/%s not found", owner, name)
+		// Search omits blocked and disabled repositories, so ask for the repository directly.
+		// Renamed repositories are only reachable through the redirect from their old name.
+		item, err := s.client.GetRepository(ctx, owner, name)
+		repo := RepoReport{RepoID: fmt.Sprintf("%s/%s", owner, name), Owner: owner, Name: name}
+		if status, unavailable := github.RepoUnavailableStatus(err); unavailable {
+			s.recordUnavailableRepo(&repo, status, opts.Persist)
+			return repo, nil
+		}
+		if github.IsNotFound(err) && s.isKnownRepo(repo.RepoID) {
+			s.recordUnavailableRepo(&repo, github.RepoStatusDeleted, opts.Persist)
+			return repo, nil
+		}
+		if err != nil && !github.IsNotFound(err) {
+			return RepoReport{}, err
+		}
+		if err != nil || item.Name == "" {
+			return RepoReport{}, fmt.Errorf("repository %s/%s not found", owner, name)
+		}
+		if !strings.EqualFold(repoItemID(item), repo.RepoID) {
+			return s.scanMovedRepo(ctx, repo, item, opts), nil
+		}
+		return s.scanRepoItem(ctx, item, opts), nil
 	}
 
 	return s.scanRepoItem(ctx, result.Items[0], opts), nil
@@ -367,12 +676,26 @@ func (s *Service) ScanUser(ctx context.Context, username string, opts UserOption
 	analysis, err := s.analyzer.AnalyzeUser(ctx, username)
 	report := UserReport{
 		Username:             username,
+		GitHubID:             analysis.GitHubID,
+		NodeID:               analysis.NodeID,
 		CreatedAt:            analysis.CreatedAt,
 		Contributions:        analysis.Contributions,
+		Followers:            analysis.Followers,
+		Following:            analysis.Following,
+		ForkCount:            analysis.ForkCount,
+		OwnerType:            analysis.OwnerType,
+		Score:                analysis.TotalScore,
 		TotalStars:           analysis.TotalStars,
 		EmptyCount:           analysis.EmptyCount,
 		SuspiciousEmptyCount: analysis.SuspiciousEmptyCount,
+		TemplateUniformity:   analysis.TemplateUniformity,
+		CommitSampled:        analysis.CommitSampled,
+		SingleCommitFraction: analysis.SingleCommitFraction,
+		Tier:                 analysis.Tier,
+		AvatarHash:           analysis.AvatarHash,
 		Suspicious:           analysis.Suspicious,
+		InsufficientEvidence: analysis.InsufficientEvidence,
+		Allowlisted:          analysis.Allowlisted,
 		Heuristics:           analysis.HeuristicResults,
 	}
 
@@ -381,8 +704,8 @@ func (s *Service) ScanUser(ctx context.Context, username string, opts UserOption
 		return report, err
 	}
 
-	if opts.Persist {
-		if err := s.persistUser(report); err != nil {
+	if opts.Persist && !report.Allowlisted {
+		if err := s.persistUser(&report); err != nil {
 			report.Errors = append(report.Errors, err.Error())
 			return report, err
 		}
@@ -441,53 +764,130 @@ func (r RepoReport) IsFlagged() bool {
 }
 
 func (s *Service) scanRepoItem(ctx context.Context, item models.RepoItem, opts RepoOptions) RepoReport {
-	repo := RepoReport{
-		RepoID:        fmt.Sprintf("%s/%s", item.Owner.Login, item.Name),
-		Owner:         item.Owner.Login,
-		Name:          item.Name,
-		DefaultBranch: item.DefaultBranch,
-		CreatedAt:     item.CreatedAt,
-		UpdatedAt:     item.UpdatedAt,
-		DiskUsage:     item.Size,
-		Stargazers:    item.StargazersCount,
-	}
-	if repo.DefaultBranch == "" {
-		repo.DefaultBranch = "main"
-	}
-
-	if opts.Persist && opts.SkipIfUnchanged && s.db != nil {
-		already, err := s.db.WasRepoProcessed(repo.RepoID, repo.UpdatedAt)
-		if err != nil {
-			repo.Errors = append(repo.Errors, fmt.Sprintf("checking persisted state: %v", err))
-		} else if already {
-			repo.Skipped = true
-			repo.SkipReason = "repository already processed at this revision"
-			return repo
-		}
+	repo, skipped := s.newRepoReport(item, opts)
+	if skipped {
+		return repo
 	}
 
 	analyzedRepo := models.RepoData{
+		ID:             repo.GitHubID,
 		Owner:          repo.Owner,
 		Name:           repo.Name,
 		DiskUsage:      repo.DiskUsage,
 		StargazerCount: repo.Stargazers,
+		Description:    repo.Description,
 	}
 
 	if repo.DefaultBranch != "" && repo.DiskUsage > 0 {
-		repoData, malicious, err := s.analyzer.CheckRepoFiles(ctx, repo.Owner, repo.Name, repo.DefaultBranch)
+		repoData, results, err := s.analyzer.CheckRepoFiles(ctx, repo.Owner, repo.Name, repo.DefaultBranch)
+		if status, unavailable := github.RepoUnavailableStatus(err); unavailable {
+			s.recordUnavailableRepo(&repo, status, opts.Persist)
+			return repo
+		}
+		if github.IsRepoMoved(err) && !opts.followedMove {
+			item, lookupErr := s.client.GetRepository(ctx, repo.Owner, repo.Name)
+			if lookupErr == nil {
+				return s.scanMovedRepo(ctx, repo, item, opts)
+			}
+			err = fmt.Errorf("%v; resolving new name: %w", err, lookupErr)
+		}
 		if err != nil {
 			repo.Errors = append(repo.Errors, fmt.Sprintf("checking repository files: %v", err))
 		} else {
 			analyzedRepo = repoData
+			analyzedRepo.ID = repo.GitHubID
 			analyzedRepo.DiskUsage = repo.DiskUsage
 			analyzedRepo.StargazerCount = repo.Stargazers
-			repo.IsMalicious = malicious
+			analyzedRepo.Description = repo.Description
+			repo.CheckerResults = results
+			repo.IsMalicious = s.analyzer.IsMalicious(results)
 			repo.ReadmePresent = repoData.Readme != ""
 			repo.FileCount = len(repoData.TreeEntries)
+			repo.Topics = repoData.Topics
+			repo.Languages = repoData.Languages
+			repo.Fingerprint = analyzer.Fingerprint(repoData)
 		}
 	}
 
-	repo.RepoFlags = analyzer.EvaluateRepoHeuristics(analyzedRepo)
+	repoFlags, err := s.analyzer.EvaluateRepoHeuristics(ctx, analyzedRepo)
+	if err != nil {
+		repo.Errors = append(repo.Errors, fmt.Sprintf("evaluating repository heuristics: %v", err))
+	}
+	repo.RepoFlags = repoFlags
+	if repo.Fingerprint != "" {
+		result, _, err := s.analyzer.CheckDuplicateContent(analyzedRepo, repo.Fingerprint)
+		if err != nil {
+			repo.Errors = append(repo.Errors, fmt.Sprintf("checking duplicate content: %v", err))
+		} else if result.Flag {
+			repo.RepoFlags = append(repo.RepoFlags, result)
+			repo.ContentCluster = analyzer.ContentClusterID(repo.Fingerprint)
+		}
+	}
+	if result, enabled, err := s.analyzer.CheckReleases(ctx, analyzedRepo); err != nil {
+		repo.Errors = append(repo.Errors, fmt.Sprintf("checking release assets: %v", err))
+	} else if enabled && result.Flag {
+		repo.RepoFlags = append(repo.RepoFlags, result.HeuristicResult)
+		repo.FlaggedAssets = result.Assets
+		if s.assetDownloads.MaxBytes > 0 {
+			s.hashFlaggedAssets(ctx, &repo)
+		}
+	}
+	if (repo.CheckerResults != nil || repo.FlaggedAssets != nil) && s.db != nil {
+		s.checkSharedPayloads(ctx, &repo, analyzedRepo)
+	}
+	if !repo.IsMalicious && len(repoFlags) > 0 && analyzedRepo.TreeEntries != nil {
+		// The history check is expensive, so only borderline repos that already raised a flag pay for it.
+		result, enabled, err := s.analyzer.CheckHistory(ctx, analyzedRepo, repo.DefaultBranch)
+		if err != nil {
+			repo.Errors = append(repo.Errors, fmt.Sprintf("checking repository history: %v", err))
+		} else if enabled && result.Flag {
+			repo.RepoFlags = append(repo.RepoFlags, result)
+		}
+	}
+	if result, fraction, enabled, err := s.analyzer.CheckLoneStargazers(ctx, analyzedRepo); err != nil {
+		repo.Errors = append(repo.Errors, fmt.Sprintf("sampling stargazers: %v", err))
+	} else if enabled {
+		repo.LoneStargazerFraction = fraction
+		if result.Flag {
+			repo.RepoFlags = append(repo.RepoFlags, result)
+		}
+	}
+	if result, enabled, err := s.analyzer.CheckStarFarm(ctx, analyzedRepo, repo.IsMalicious || len(repo.RepoFlags) > 0); err != nil {
+		repo.Errors = append(repo.Errors, fmt.Sprintf("checking stargazer account ages: %v", err))
+	} else if enabled {
+		repo.StargazerMedianAgeDays = result.MedianAge.Hours() / 24
+		if result.Flag {
+			repo.RepoFlags = append(repo.RepoFlags, result.HeuristicResult)
+			repo.starFarmStargazers = result.Stargazers
+			for _, stargazer := range result.Stargazers {
+				repo.StarFarmStargazers = append(repo.StarFarmStargazers, stargazer.Login)
+			}
+		}
+	}
+	if s.safeBrowsing.Enabled() && analyzedRepo.Readme != "" {
+		verdicts, err := s.safeBrowsing.CheckURLs(ctx, analyzer.ExtractLinks(analyzedRepo.Readme))
+		if err != nil {
+			repo.Errors = append(repo.Errors, fmt.Sprintf("checking link reputation: %v", err))
+		} else {
+			repo.LinkVerdicts = verdicts
+			repo.RepoFlags = append(repo.RepoFlags, safeBrowsingFlags(verdicts)...)
+		}
+	}
+	if s.urlScan.Enabled() && isHighSeverity(repo) {
+		if link := landingPageLink(analyzer.ExtractLinks(analyzedRepo.Readme)); link != "" {
+			result, err := s.urlScan.Scan(ctx, link)
+			if err != nil {
+				repo.Errors = append(repo.Errors, fmt.Sprintf("submitting %s to urlscan: %v", link, err))
+			}
+			if result.UUID != "" {
+				repo.URLScans = append(repo.URLScans, result)
+			}
+		}
+	}
+	s.holdWeakFlags(&repo)
+	if repo.IsMalicious {
+		s.expandMaliciousRepo(ctx, &repo, opts.Persist)
+	}
 	if opts.Persist && s.db != nil {
 		if err := s.persistRepo(repo); err != nil {
 			repo.Errors = append(repo.Errors, err.Error())
@@ -504,6 +904,13 @@ func (s *Service) scanRepoItem(ctx context.Context, item models.RepoItem, opts R
 		return repo
 	}
 
+	if opts.Persist && s.reuseOwners && s.db != nil {
+		if stored, reused := s.storedOwnerAnalysis(&repo, time.Now()); reused {
+			repo.OwnerAnalysis = &stored
+			return repo
+		}
+	}
+
 	userReport, err := s.ScanUser(ctx, repo.Owner, UserOptions{Persist: opts.Persist})
 	if err != nil {
 		repo.Errors = append(repo.Errors, err.Error())
@@ -513,6 +920,358 @@ func (s *Service) scanRepoItem(ctx context.Context, item models.RepoItem, opts R
 	return repo
 }
 
+// storedOwnerAnalysis rebuilds the owner's report from the database when their stored analysis
+// is still fresh, so owners recurring across scheduled runs are not analyzed again.
+func (s *Service) storedOwnerAnalysis(repo *RepoReport, now time.Time) (UserReport, bool) {
+	if s.analyzer.IsAllowlisted("user", repo.Owner) {
+		// A fresh analysis clears the owner instead of reusing a stored verdict.
+		return UserReport{}, false
+	}
+	user, found, err := s.db.LookupProcessedUser(repo.Owner)
+	if err != nil {
+		repo.Errors = append(repo.Errors, fmt.Sprintf("checking stored owner analysis: %v", err))
+		return UserReport{}, false
+	}
+	if !found || !ownerAnalysisFresh(user.ProcessedAt, repo.CreatedAt, s.ownerTTL, now) {
+		return UserReport{}, false
+	}
+	flags, err := s.db.ListHeuristicFlags("user", user.Username)
+	if err != nil {
+		repo.Errors = append(repo.Errors, fmt.Sprintf("loading stored owner flags: %v", err))
+		return UserReport{}, false
+	}
+	report := UserReport{
+		Username:             user.Username,
+		GitHubID:             user.GitHubID,
+		NodeID:               user.NodeID,
+		CreatedAt:            user.CreatedAt,
+		Contributions:        user.Contributions,
+		Followers:            user.Followers,
+		Following:            user.Following,
+		ForkCount:            user.ForkCount,
+		Score:                user.Score,
+		TotalStars:           user.TotalStars,
+		EmptyCount:           user.EmptyCount,
+		SuspiciousEmptyCount: user.SuspiciousEmptyCount,
+		Tier:                 user.Tier,
+		Suspicious:           user.Suspicious,
+		Reused:               true,
+		Persisted:            true,
+	}
+	for _, flag := range flags {
+		category, name, _ := strings.Cut(flag.Flag, ":")
+		report.Heuristics = append(report.Heuristics, models.HeuristicResult{Category: category, Name: name, Flag: true, Description: flag.Message})
+	}
+	return report, true
+}
+
+// ownerAnalysisFresh reports whether an analysis stored at processedAt can stand in for a new
+// one at now. A repository created since means the owner's data changed, and a zero ttl never
+// expires.
+func ownerAnalysisFresh(processedAt, repoCreatedAt time.Time, ttl time.Duration, now time.Time) bool {
+	if repoCreatedAt.After(processedAt) {
+		return false
+	}
+	return ttl == 0 || now.Sub(processedAt) < ttl
+}
+
+// newRepoReport builds the report for a search item. skipped is true when the repository was
+// already processed at this revision and should not be scanned again.
+func (s *Service) newRepoReport(item models.RepoItem, opts RepoOptions) (repo RepoReport, skipped bool) {
+	repo = RepoReport{
+		RepoID:        fmt.Sprintf("%s/%s", item.Owner.Login, item.Name),
+		GitHubID:      item.ID,
+		NodeID:        item.NodeID,
+		Owner:         item.Owner.Login,
+		Name:          item.Name,
+		Description:   item.Description,
+		DefaultBranch: item.DefaultBranch,
+		CreatedAt:     item.CreatedAt,
+		UpdatedAt:     item.UpdatedAt,
+		DiskUsage:     item.Size,
+		Stargazers:    item.StargazersCount,
+	}
+	if repo.DefaultBranch == "" {
+		repo.DefaultBranch = "main"
+	}
+	if opts.Persist && s.db != nil && repo.GitHubID != 0 && !opts.followedMove {
+		s.followStoredGitHubID(&repo)
+	}
+
+	if opts.Persist && opts.SkipIfUnchanged && s.db != nil {
+		already, err := s.db.WasRepoProcessed(repo.RepoID, repo.UpdatedAt)
+		if err != nil {
+			repo.Errors = append(repo.Errors, fmt.Sprintf("checking persisted state: %v", err))
+		} else if already {
+			repo.Skipped = true
+			repo.SkipReason = "repository already processed at this revision"
+			return repo, true
+		}
+	}
+	if !opts.Band.Contains(repo.Stargazers, repo.DiskUsage) {
+		repo.Skipped = true
+		repo.SkipReason = bandSkipReason
+		if opts.Persist && s.db != nil {
+			if err := s.db.RecordSkippedRepo(repo.RepoID, repo.Owner, repo.Name, repo.UpdatedAt, repo.DiskUsage, repo.Stargazers); err != nil {
+				repo.Errors = append(repo.Errors, err.Error())
+			}
+		}
+		return repo, true
+	}
+	if s.analyzer.IsAllowlisted("repo", repo.RepoID) {
+		repo.Skipped = true
+		repo.SkipReason = allowlistSkipReason
+		return repo, true
+	}
+	return repo, false
+}
+
+// followStoredGitHubID moves the stored record of a repository found under a new name, matched
+// by its numeric GitHub ID, to the name it has now.
+func (s *Service) followStoredGitHubID(repo *RepoReport) {
+	storedID, found, err := s.db.RepoIDForGitHubID(repo.GitHubID)
+	if err != nil {
+		repo.Errors = append(repo.Errors, fmt.Sprintf("matching GitHub ID: %v", err))
+		return
+	}
+	if !found || strings.EqualFold(storedID, repo.RepoID) {
+		return
+	}
+	if err := s.db.RenameRepo(storedID, repo.Owner, repo.Name); err != nil {
+		repo.Errors = append(repo.Errors, err.Error())
+		return
+	}
+	repo.RenamedFrom = storedID
+}
+
+// checkSharedPayloads lists the repository's release assets, keeps the payloads along with the
+// flagged assets hashed by content, and flags the repository when repos of other owners ship one
+// of them.
+func (s *Service) checkSharedPayloads(ctx context.Context, repo *RepoReport, analyzed models.RepoData) {
+	assets, err := s.client.GetReleaseAssets(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		repo.Errors = append(repo.Errors, fmt.Sprintf("listing release assets: %v", err))
+		return
+	}
+	repo.payloadsChecked = true
+	repo.PayloadAssets = withContentHashes(analyzer.PayloadAssets(assets), repo.FlaggedAssets)
+	result, asset, err := s.analyzer.CheckSharedPayloads(analyzed, repo.PayloadAssets)
+	if err != nil {
+		repo.Errors = append(repo.Errors, fmt.Sprintf("checking shared payloads: %v", err))
+		return
+	}
+	if result.Flag {
+		repo.RepoFlags = append(repo.RepoFlags, result)
+		repo.sharedPayload = &asset
+	}
+}
+
+// fastTrackRepoItem records a repository of an owner already found suspicious in the same
+// page. It skips the file, history, and link checks and evaluates only repository metadata.
+func (s *Service) fastTrackRepoItem(ctx context.Context, item models.RepoItem, opts RepoOptions, owner *UserReport) RepoReport {
+	repo, skipped := s.newRepoReport(item, opts)
+	if skipped {
+		return repo
+	}
+	repo.FastTracked = true
+	repo.OwnerAnalysis = owner
+
+	repoFlags, err := s.analyzer.EvaluateRepoHeuristics(ctx, models.RepoData{
+		ID:             repo.GitHubID,
+		Owner:          repo.Owner,
+		Name:           repo.Name,
+		DiskUsage:      repo.DiskUsage,
+		StargazerCount: repo.Stargazers,
+		Description:    repo.Description,
+	})
+	if err != nil {
+		repo.Errors = append(repo.Errors, fmt.Sprintf("evaluating repository heuristics: %v", err))
+	}
+	repo.RepoFlags = repoFlags
+	s.holdWeakFlags(&repo)
+	if opts.Persist && s.db != nil {
+		if err := s.persistRepo(repo); err != nil {
+			repo.Errors = append(repo.Errors, err.Error())
+		} else {
+			repo.Persisted = true
+		}
+	}
+	return repo
+}
+
+// holdWeakFlags moves repository flags that fall short of the evidence policy to HeldFlags.
+func (s *Service) holdWeakFlags(repo *RepoReport) {
+	if len(repo.RepoFlags) == 0 || s.analyzer.PassesEvidence(repo.RepoFlags) {
+		return
+	}
+	repo.HeldFlags = repo.RepoFlags
+	repo.RepoFlags = nil
+}
+
+// scanMovedRepo scans item, the current location of a repository previously known as
+// stale.RepoID, and moves the stored rows for the old ID to the new one.
+func (s *Service) scanMovedRepo(ctx context.Context, stale RepoReport, item models.RepoItem, opts RepoOptions) RepoReport {
+	var renameErr error
+	if opts.Persist && s.db != nil && s.isKnownRepo(stale.RepoID) {
+		renameErr = s.db.RenameRepo(stale.RepoID, item.Owner.Login, item.Name)
+	}
+	opts.followedMove = true
+	repo := s.scanRepoItem(ctx, item, opts)
+	repo.RenamedFrom = stale.RepoID
+	if renameErr != nil {
+		repo.Errors = append(repo.Errors, renameErr.Error())
+	}
+	return repo
+}
+
+// isKnownRepo reports whether repoID has a stored row. Lookup errors count as unknown.
+func (s *Service) isKnownRepo(repoID string) bool {
+	if s.db == nil {
+		return false
+	}
+	_, err := s.db.GetProcessedRepo(repoID)
+	return err == nil
+}
+
+// recordUnavailableRepo marks a repository GitHub blocked or disabled instead of failing the
+// scan. A repository flagged by an earlier scan is reported as a confirmed takedown.
+func (s *Service) recordUnavailableRepo(repo *RepoReport, status string, persist bool) {
+	repo.Status = status
+	if s.db == nil {
+		return
+	}
+	flagged, err := s.db.WasRepoFlagged(repo.RepoID)
+	if err != nil {
+		repo.Errors = append(repo.Errors, err.Error())
+	}
+	repo.TakedownConfirmed = flagged
+	if !persist {
+		return
+	}
+	if err := s.db.SetRepoStatus(repo.RepoID, repo.Owner, repo.Name, status); err != nil {
+		repo.Errors = append(repo.Errors, err.Error())
+		return
+	}
+	repo.Persisted = true
+}
+
+// expandMaliciousRepo applies the configured OnMalicious response to a malicious repository.
+func (s *Service) expandMaliciousRepo(ctx context.Context, repo *RepoReport, persist bool) {
+	if s.onMalicious == OnMaliciousNone {
+		return
+	}
+
+	stargazers, err := s.client.GetStargazers(ctx, repo.Owner, repo.Name, s.maxStargazers)
+	if err != nil {
+		repo.Errors = append(repo.Errors, fmt.Sprintf("fetching stargazers: %v", err))
+		return
+	}
+	for _, stargazer := range stargazers {
+		repo.StargazerLogins = append(repo.StargazerLogins, stargazer.Login)
+	}
+	if persist && s.db != nil {
+		for _, stargazer := range stargazers {
+			if err := s.db.InsertStargazer(repo.RepoID, stargazer.Login, stargazer.StarredAt); err != nil {
+				repo.Errors = append(repo.Errors, err.Error())
+				break
+			}
+		}
+	}
+
+	if s.onMalicious != OnMaliciousFetchStargazersAndAnalyze {
+		return
+	}
+
+	reports := make([]UserReport, len(repo.StargazerLogins))
+	sem := make(chan struct{}, stargazerAnalysisConcurrency)
+	var wg sync.WaitGroup
+	for i, login := range repo.StargazerLogins {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, login string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			// Errors are kept on the per-user report.
+			reports[i], _ = s.ScanUser(ctx, login, UserOptions{Persist: persist})
+		}(i, login)
+	}
+	wg.Wait()
+	repo.StargazerAnalyses = reports
+}
+
+// safeBrowsingFlags converts Safe Browsing threat matches into repository flags.
+func safeBrowsingFlags(verdicts []safebrowsing.Verdict) []models.HeuristicResult {
+	var flags []models.HeuristicResult
+	for _, verdict := range verdicts {
+		if !verdict.IsThreat() {
+			continue
+		}
+		category := "Malware"
+		if verdict.ThreatType == "SOCIAL_ENGINEERING" {
+			category = "Phishing"
+		}
+		flags = append(flags, models.HeuristicResult{
+			Category:    category,
+			Flag:        true,
+			Name:        "SafeBrowsingHeuristic",
+			Description: fmt.Sprintf("README links to %s, listed by Safe Browsing as %s.", verdict.URL, verdict.ThreatType),
+		})
+	}
+	return flags
+}
+
+// isHighSeverity reports whether a repository warrants an external sandbox scan of its links:
+// it is malicious, carries a Malware flag such as a blocklist match, or links to a known threat.
+func isHighSeverity(repo RepoReport) bool {
+	if repo.IsMalicious {
+		return true
+	}
+	for _, flag := range repo.RepoFlags {
+		if flag.Category == "Malware" {
+			return true
+		}
+	}
+	for _, verdict := range repo.LinkVerdicts {
+		if verdict.IsThreat() {
+			return true
+		}
+	}
+	return false
+}
+
+// landingPageLink returns the first README link that leaves GitHub, skipping badge hosts.
+func landingPageLink(links []string) string {
+	for _, link := range links {
+		parsed, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		host := strings.ToLower(parsed.Hostname())
+		switch {
+		case host == "github.com", strings.HasSuffix(host, ".github.com"),
+			strings.HasSuffix(host, "githubusercontent.com"), strings.HasSuffix(host, "shields.io"):
+			continue
+		}
+		return link
+	}
+	return ""
+}
+
+// URLScanRecord converts a urlscan.io result into its database record, optionally attached to a repository.
+func URLScanRecord(repoID string, result urlscan.Result) db.URLScan {
+	return db.URLScan{
+		UUID:          result.UUID,
+		RepoID:        repoID,
+		URL:           result.URL,
+		ResultURL:     result.ResultURL,
+		ScreenshotURL: result.ScreenshotURL,
+		Verdict:       result.Verdict,
+		Score:         result.Score,
+		SubmittedAt:   result.SubmittedAt,
+		CompletedAt:   result.CompletedAt,
+	}
+}
+
 func (s *Service) persistRepo(report RepoReport) error {
 	if s.db == nil {
 		return nil
@@ -520,38 +1279,215 @@ func (s *Service) persistRepo(report RepoReport) error {
 	if err := s.db.InsertProcessedRepo(report.RepoID, report.Owner, report.Name, report.UpdatedAt, report.DiskUsage, report.Stargazers, report.IsMalicious); err != nil {
 		return err
 	}
-	for _, flag := range report.RepoFlags {
-		if flag.Flag {
-			if err := s.db.InsertHeuristicFlag("repo", report.RepoID, fmt.Sprintf("%s:%s", flag.Category, flag.Name)); err != nil {
+	if report.GitHubID != 0 {
+		if err := s.db.SetRepoGitHubID(report.RepoID, report.GitHubID, report.NodeID); err != nil {
+			return err
+		}
+	}
+	if len(report.CheckerResults) > 0 {
+		results := make([]db.RepoCheckerResult, 0, len(report.CheckerResults))
+		for _, result := range report.CheckerResults {
+			results = append(results, db.RepoCheckerResult{
+				Checker:  result.Name,
+				Flagged:  result.Flagged,
+				Severity: result.Severity,
+				Evidence: s.storedText.Apply(result.Evidence),
+			})
+		}
+		if err := s.db.ReplaceRepoCheckerResults(report.RepoID, results); err != nil {
+			return err
+		}
+	}
+	for _, verdict := range report.LinkVerdicts {
+		if verdict.IsThreat() {
+			if err := s.db.UpsertURLThreat(report.RepoID, verdict.URL, verdict.ThreatType, verdict.Platform); err != nil {
 				return err
 			}
 		}
 	}
-	if report.OwnerAnalysis != nil {
-		for _, heuristic := range report.OwnerAnalysis.Heuristics {
-			if heuristic.Flag {
-				if err := s.db.InsertHeuristicFlag("user", report.OwnerAnalysis.Username, fmt.Sprintf("%s:%s", heuristic.Category, heuristic.Name)); err != nil {
-					return err
-				}
+	for _, result := range report.URLScans {
+		if err := s.db.UpsertURLScan(URLScanRecord(report.RepoID, result)); err != nil {
+			return err
+		}
+	}
+	if err := s.recordFlags("repo", report.RepoID, report.RepoFlags); err != nil {
+		return err
+	}
+	for _, stargazer := range report.starFarmStargazers {
+		if err := s.db.InsertStargazer(report.RepoID, stargazer.Login, stargazer.StarredAt); err != nil {
+			return err
+		}
+	}
+	if report.Description != "" {
+		if err := s.db.SetRepoDescription(report.RepoID, s.storedText.Apply(report.Description)); err != nil {
+			return err
+		}
+	}
+	if report.CheckerResults != nil {
+		if err := s.db.SetRepoFingerprint(report.RepoID, report.Fingerprint); err != nil {
+			return err
+		}
+		if err := s.db.SetRepoTopics(report.RepoID, report.Topics); err != nil {
+			return err
+		}
+	}
+	if report.ContentCluster != "" {
+		if err := s.persistContentCluster(report); err != nil {
+			return err
+		}
+	}
+	if report.payloadsChecked {
+		assets := make([]db.ReleaseAsset, 0, len(report.PayloadAssets))
+		for _, asset := range report.PayloadAssets {
+			assets = append(assets, db.ReleaseAsset{RepoID: report.RepoID, Name: asset.Name, Size: asset.Size, Hash: asset.Hash})
+		}
+		if err := s.db.ReplaceReleaseAssets(report.RepoID, assets); err != nil {
+			return err
+		}
+	}
+	if report.sharedPayload != nil && len(report.RepoFlags) > 0 {
+		if err := s.persistSharedPayload(report); err != nil {
+			return err
+		}
+	}
+	if report.OwnerAnalysis != nil && report.OwnerAnalysis.Suspicious {
+		if err := s.recordFlags("user", report.OwnerAnalysis.Username, report.OwnerAnalysis.Heuristics); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordFlags stores the flagged results of an entity. With a notifier set, the flags the
+// entity did not have before are reported in one event.
+func (s *Service) recordFlags(entityType, entityID string, results []models.HeuristicResult) error {
+	var known map[string]bool
+	if s.notifier != nil {
+		recorded, err := s.db.ListHeuristicFlags(entityType, entityID)
+		if err != nil {
+			return err
+		}
+		known = make(map[string]bool, len(recorded))
+		for _, flag := range recorded {
+			known[flag.FlagKey] = true
+		}
+	}
+	var triggered []notify.Heuristic
+	for _, result := range results {
+		if !result.Flag {
+			continue
+		}
+		flag := fmt.Sprintf("%s:%s", result.Category, result.Name)
+		message := s.storedText.Apply(result.Description)
+		if err := s.db.InsertHeuristicFlag(entityType, entityID, result.Name, flag, message); err != nil {
+			return err
+		}
+		if s.notifier != nil && !known[db.FlagKey(flag)] {
+			known[db.FlagKey(flag)] = true
+			triggered = append(triggered, notify.Heuristic{Name: result.Name, Category: result.Category, Description: message})
+		}
+	}
+	if len(triggered) > 0 {
+		s.notifier.Notify(notify.Event{EntityType: entityType, EntityID: entityID, Heuristics: triggered, Timestamp: time.Now().UTC()})
+	}
+	return nil
+}
+
+// persistContentCluster records the cluster on every repository sharing the report's
+// fingerprint and flags the members scanned earlier, which matched nothing at the time.
+func (s *Service) persistContentCluster(report RepoReport) error {
+	if err := s.db.SetContentCluster(report.Fingerprint, report.ContentCluster); err != nil {
+		return err
+	}
+	members, err := s.db.ReposWithFingerprint(report.Fingerprint)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if strings.EqualFold(member, report.RepoID) {
+			continue
+		}
+		others := make([]string, 0, len(members)-1)
+		for _, other := range members {
+			if other != member {
+				others = append(others, other)
 			}
 		}
+		flag := analyzer.DuplicateContentResult(report.ContentCluster, others)
+		if err := s.recordFlags("repo", member, []models.HeuristicResult{flag}); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (s *Service) persistUser(report UserReport) error {
+// persistSharedPayload flags the other repositories shipping the report's shared payload,
+// which matched too few repos when they were scanned.
+func (s *Service) persistSharedPayload(report RepoReport) error {
+	members, err := s.db.FindReposByAssetHash(report.sharedPayload.Hash)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if strings.EqualFold(member, report.RepoID) {
+			continue
+		}
+		others := make([]string, 0, len(members)-1)
+		for _, other := range members {
+			if other != member {
+				others = append(others, other)
+			}
+		}
+		flag := analyzer.SharedPayloadResult(*report.sharedPayload, others)
+		if err := s.recordFlags("repo", member, []models.HeuristicResult{flag}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) persistUser(report *UserReport) error {
 	if s.db == nil {
 		return nil
 	}
-	if err := s.db.InsertProcessedUser(report.Username, report.CreatedAt, report.TotalStars, report.EmptyCount, report.SuspiciousEmptyCount, report.Contributions, report.Suspicious); err != nil {
+	if err := s.db.InsertProcessedUser(report.Username, report.CreatedAt, report.TotalStars, report.EmptyCount, report.SuspiciousEmptyCount, report.Contributions, report.Suspicious, report.Tier); err != nil {
 		return err
 	}
-	for _, heuristic := range report.Heuristics {
-		if heuristic.Flag {
-			if err := s.db.InsertHeuristicFlag("user", report.Username, fmt.Sprintf("%s:%s", heuristic.Category, heuristic.Name)); err != nil {
-				return err
-			}
+	if err := s.db.SetUserFollowCounts(report.Username, report.Followers, report.Following); err != nil {
+		return err
+	}
+	if err := s.db.SetUserForkCount(report.Username, report.ForkCount); err != nil {
+		return err
+	}
+	if err := s.db.SetUserScore(report.Username, report.Score); err != nil {
+		return err
+	}
+	if report.AvatarHash != "" {
+		if err := s.db.SetUserAvatarHash(report.Username, report.AvatarHash); err != nil {
+			return err
 		}
 	}
-	return nil
+	if report.GitHubID != 0 {
+		previous, err := s.db.SetUserGitHubID(report.Username, report.GitHubID, report.NodeID)
+		if err != nil {
+			return err
+		}
+		if previous != 0 && previous != report.GitHubID {
+			report.PreviousGitHubID = previous
+		}
+	}
+	if !report.Suspicious {
+		// Flags of a user below the evidence policy are reported but not recorded.
+		return nil
+	}
+	return s.recordFlags("user", report.Username, report.Heuristics)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
 }
@@ -1,10 +1,28 @@
 package scan
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/arkouda/github/GitHubWatchdog/internal/analyzer"
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/github/githubtest"
+	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
 	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+	"github.com/arkouda/github/GitHubWatchdog/internal/notify"
+	"github.com/arkouda/github/GitHubWatchdog/internal/safebrowsing"
 )
 
 func TestRepoReportIsFlagged(t *testing.T) {
@@ -190,6 +208,28 @@ func TestFilterSearchItemsEither(t *testing.T) {
 	}
 }
 
+func TestRepoBandContains(t *testing.T) {
+	band := RepoBand{MinStars: 5, MaxStars: 50, MaxSize: 50}
+	tests := []struct {
+		stars, size int
+		want        bool
+	}{
+		{stars: 5, size: 0, want: true},
+		{stars: 50, size: 50, want: true},
+		{stars: 4, size: 10, want: false},
+		{stars: 51, size: 10, want: false},
+		{stars: 20, size: 51, want: false},
+	}
+	for _, tt := range tests {
+		if got := band.Contains(tt.stars, tt.size); got != tt.want {
+			t.Errorf("Contains(%d stars, %d KB) = %v, want %v", tt.stars, tt.size, got, tt.want)
+		}
+	}
+	if !(RepoBand{}).Contains(100000, 100000) {
+		t.Error("zero band should contain every repository")
+	}
+}
+
 func TestParseSearchBoundary(t *testing.T) {
 	lower, err := parseSearchBoundary("2026-03-10", false)
 	if err != nil {
@@ -215,3 +255,1009 @@ func TestParseSearchBoundary(t *testing.T) {
 		t.Fatalf("parseSearchBoundary(rfc3339) = %s", exact)
 	}
 }
+
+func TestSafeBrowsingFlags(t *testing.T) {
+	flags := safeBrowsingFlags([]safebrowsing.Verdict{
+		{URL: "https://clean.example"},
+		{URL: "http://phish.example", ThreatType: "SOCIAL_ENGINEERING"},
+		{URL: "http://malware.example", ThreatType: "MALWARE"},
+	})
+
+	if len(flags) != 2 {
+		t.Fatalf("safeBrowsingFlags() len = %d, want 2", len(flags))
+	}
+	if flags[0].Category != "Phishing" || !flags[0].Flag {
+		t.Fatalf("flags[0] = %+v, want phishing flag", flags[0])
+	}
+	if flags[1].Category != "Malware" || !strings.Contains(flags[1].Description, "http://malware.example") {
+		t.Fatalf("flags[1] = %+v, want malware flag citing URL", flags[1])
+	}
+}
+
+func TestLandingPageLinkSkipsGitHubAndBadges(t *testing.T) {
+	link := landingPageLink([]string{
+		"https://github.com/owner/repo/releases",
+		"https://img.shields.io/badge/build-passing-green",
+		"https://raw.githubusercontent.com/owner/repo/main/logo.png",
+		"https://owner.github.io/download",
+	})
+	if link != "https://owner.github.io/download" {
+		t.Fatalf("landingPageLink() = %q, want GitHub Pages landing page", link)
+	}
+	if link := landingPageLink([]string{"https://github.com/owner"}); link != "" {
+		t.Fatalf("landingPageLink() = %q, want empty", link)
+	}
+}
+
+func newStargazerTestService(t *testing.T, mode string) (*Service, *int32) {
+	t.Helper()
+	var userLookups int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/evil/loader/stargazers":
+			_, _ = w.Write([]byte(`[{"starred_at":"2026-03-01T00:00:00Z","user":{"login":"sock1"}},{"starred_at":"2026-03-01T00:05:00Z","user":{"login":"sock2"}}]`))
+		case strings.HasSuffix(r.URL.Path, "/repos"), strings.HasSuffix(r.URL.Path, "/events/public"):
+			_, _ = w.Write([]byte(`[]`))
+		case strings.HasPrefix(r.URL.Path, "/users/"):
+			atomic.AddInt32(&userLookups, 1)
+			_, _ = w.Write([]byte(`{"created_at":"2026-02-28T00:00:00Z"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	return NewServiceWithOptions(client, nil, ServiceOptions{OnMalicious: mode}), &userLookups
+}
+
+func TestExpandMaliciousRepoModes(t *testing.T) {
+	tests := []struct {
+		mode          string
+		wantLogins    int
+		wantAnalyses  int
+		wantUserCalls int32
+	}{
+		{mode: OnMaliciousNone},
+		{mode: "", wantLogins: 0},
+		{mode: OnMaliciousFetchStargazers, wantLogins: 2},
+		{mode: OnMaliciousFetchStargazersAndAnalyze, wantLogins: 2, wantAnalyses: 2, wantUserCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(firstNonEmpty(tt.mode, "default"), func(t *testing.T) {
+			service, userLookups := newStargazerTestService(t, tt.mode)
+			repo := RepoReport{RepoID: "evil/loader", Owner: "evil", Name: "loader", IsMalicious: true}
+
+			service.expandMaliciousRepo(context.Background(), &repo, false)
+
+			if len(repo.Errors) > 0 {
+				t.Fatalf("expandMaliciousRepo() errors = %v", repo.Errors)
+			}
+			if len(repo.StargazerLogins) != tt.wantLogins {
+				t.Fatalf("StargazerLogins = %v, want %d logins", repo.StargazerLogins, tt.wantLogins)
+			}
+			if len(repo.StargazerAnalyses) != tt.wantAnalyses {
+				t.Fatalf("StargazerAnalyses len = %d, want %d", len(repo.StargazerAnalyses), tt.wantAnalyses)
+			}
+			if got := atomic.LoadInt32(userLookups); got != tt.wantUserCalls {
+				t.Fatalf("user lookups = %d, want %d", got, tt.wantUserCalls)
+			}
+		})
+	}
+}
+
+func TestScanRepositorySkipsAllowlistedRepos(t *testing.T) {
+	now := time.Now()
+	server := githubtest.NewServer(t)
+	server.SetSearchResults(100, githubtest.Repo{Owner: "farmer", Name: "tool", CreatedAt: now.Add(-time.Hour), UpdatedAt: now, Size: 0, Stars: 12})
+	starredAt := map[string]time.Time{}
+	for i := 0; i < 12; i++ {
+		login := fmt.Sprintf("sock%d", i)
+		starredAt[login] = now.Add(-time.Hour)
+		server.SetUser(login, now.Add(-48*time.Hour))
+	}
+	server.SetStargazers("farmer", "tool", starredAt)
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.AddAllowlistEntry("repo", "farmer/tool", "launch campaign"); err != nil {
+		t.Fatalf("AddAllowlistEntry() error = %v", err)
+	}
+	service := NewServiceWithOptions(client, database, ServiceOptions{Analyzer: analyzer.Options{StarFarmMinStars: 10}})
+
+	report, err := service.ScanRepository(context.Background(), "farmer", "tool", RepoOptions{Persist: true})
+	if err != nil {
+		t.Fatalf("ScanRepository() error = %v", err)
+	}
+	if !report.Skipped || report.SkipReason != allowlistSkipReason || len(report.RepoFlags) != 0 || report.IsMalicious {
+		t.Fatalf("ScanRepository() = %+v, want an allowlisted repo skipped without findings", report)
+	}
+}
+
+func TestScanRepositoryRecordsStarFarmStargazers(t *testing.T) {
+	now := time.Now()
+	server := githubtest.NewServer(t)
+	server.SetSearchResults(100, githubtest.Repo{Owner: "farmer", Name: "tool", CreatedAt: now.Add(-time.Hour), UpdatedAt: now, Size: 0, Stars: 12})
+	starredAt := map[string]time.Time{}
+	for i := 0; i < 12; i++ {
+		login := fmt.Sprintf("sock%d", i)
+		starredAt[login] = now.Add(-time.Hour)
+		server.SetUser(login, now.Add(-48*time.Hour))
+	}
+	server.SetStargazers("farmer", "tool", starredAt)
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	service := NewServiceWithOptions(client, database, ServiceOptions{Analyzer: analyzer.Options{StarFarmMinStars: 10}})
+
+	report, err := service.ScanRepository(context.Background(), "farmer", "tool", RepoOptions{Persist: true})
+	if err != nil {
+		t.Fatalf("ScanRepository() error = %v", err)
+	}
+	if len(report.RepoFlags) != 1 || report.RepoFlags[0].Name != "StarFarmHeuristic" || len(report.StarFarmStargazers) != 12 {
+		t.Fatalf("ScanRepository() flags = %+v, stargazers = %v, want a star farm flag over 12 stargazers", report.RepoFlags, report.StarFarmStargazers)
+	}
+	if report.StargazerMedianAgeDays < 1.9 || report.StargazerMedianAgeDays > 2 {
+		t.Fatalf("StargazerMedianAgeDays = %v, want just under 2", report.StargazerMedianAgeDays)
+	}
+	stored, err := database.ListStargazers("farmer/tool")
+	if err != nil || len(stored) != 12 {
+		t.Fatalf("ListStargazers() = %v, %v, want the 12 sampled stargazers", stored, err)
+	}
+}
+
+func TestScanRepositoryRecordsTakedowns(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.HandleJSON("/search/repositories", map[string]interface{}{"total_count": 0, "items": []interface{}{}})
+	server.Handle("/repos/evil/loader", githubtest.Response{Status: http.StatusUnavailableForLegalReasons, Body: `{"message":"Repository access blocked","block":{"reason":"dmca"}}`})
+	server.Handle("/repos/quiet/tool", githubtest.Response{Status: http.StatusForbidden, Body: `{"message":"Repository access blocked","block":{"reason":"tos"}}`})
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+
+	database, err := db.New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.InsertProcessedRepo("evil/loader", "evil", "loader", time.Now(), 10, 50, true); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	service := NewService(client, database)
+
+	tests := []struct {
+		owner, name   string
+		wantStatus    string
+		wantConfirmed bool
+	}{
+		{"evil", "loader", github.RepoStatusBlockedDMCA, true},
+		{"quiet", "tool", github.RepoStatusDisabled, false},
+	}
+	for _, tt := range tests {
+		report, err := service.ScanRepository(context.Background(), tt.owner, tt.name, RepoOptions{Persist: true})
+		if err != nil {
+			t.Fatalf("ScanRepository(%s/%s) error = %v", tt.owner, tt.name, err)
+		}
+		if report.Status != tt.wantStatus || report.TakedownConfirmed != tt.wantConfirmed || !report.Persisted {
+			t.Fatalf("ScanRepository(%s/%s) = %+v, want status %s confirmed %v", tt.owner, tt.name, report, tt.wantStatus, tt.wantConfirmed)
+		}
+		stored, err := database.GetProcessedRepo(report.RepoID)
+		if err != nil || stored.Status != tt.wantStatus {
+			t.Fatalf("GetProcessedRepo(%s) = %+v, %v, want status %s", report.RepoID, stored, err, tt.wantStatus)
+		}
+	}
+	if stored, _ := database.GetProcessedRepo("evil/loader"); !stored.IsMalicious {
+		t.Fatal("SetRepoStatus() cleared the earlier malicious verdict")
+	}
+
+	if _, err := service.ScanRepository(context.Background(), "missing", "repo", RepoOptions{}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("ScanRepository(missing) error = %v, want not found", err)
+	}
+}
+
+func TestSearchCoalescesSuspiciousOwnerRepos(t *testing.T) {
+	now := time.Now()
+	var repos []githubtest.Repo
+	for i := 0; i < 25; i++ {
+		repos = append(repos, githubtest.Repo{Owner: "farmer", Name: fmt.Sprintf("tool-%d", i), CreatedAt: now, UpdatedAt: now, Size: 1, Stars: 5})
+	}
+	repoRequests := func(coalesce bool) (SearchReport, int) {
+		server := githubtest.NewServer(t)
+		server.SetSearchResults(100, repos[:6]...)
+		server.SetUser("farmer", now.Add(-48*time.Hour))
+		server.SetUserRepos("farmer", repos...)
+		server.SetUserEvents("farmer", now)
+		for _, repo := range repos[:6] {
+			server.SetReadme("farmer", repo.Name, "A useful tool")
+			server.SetTree("farmer", repo.Name, "main", "main.go")
+		}
+		client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+		t.Cleanup(client.Close)
+		client.SetBaseURL(server.URL)
+		service := NewServiceWithOptions(client, nil, ServiceOptions{CoalesceOwners: coalesce})
+
+		report, err := service.Search(context.Background(), SearchOptions{Query: "stars:>1", MaxPages: 1, PerPage: 100, MaxConcurrent: 4})
+		if err != nil {
+			t.Fatalf("Search(coalesce=%v) error = %v", coalesce, err)
+		}
+		count := 0
+		for _, req := range server.Requests() {
+			// Owner analysis samples commits once either way; only count per-repo scan requests.
+			if strings.HasPrefix(req.Path, "/repos/farmer/") && !strings.HasSuffix(req.Path, "/commits") {
+				count++
+			}
+		}
+		return report, count
+	}
+
+	full, fullRequests := repoRequests(false)
+	coalesced, coalescedRequests := repoRequests(true)
+	if len(full.Results) != 6 || len(coalesced.Results) != 6 {
+		t.Fatalf("Search() results = %d and %d, want 6 each", len(full.Results), len(coalesced.Results))
+	}
+	fastTracked := 0
+	for _, result := range coalesced.Results {
+		if !result.IsFlagged() || result.OwnerAnalysis == nil || !result.OwnerAnalysis.Suspicious {
+			t.Fatalf("coalesced result %s = %+v, want suspicious owner attached", result.RepoID, result)
+		}
+		if result.FastTracked {
+			fastTracked++
+		}
+	}
+	if fastTracked != 5 {
+		t.Fatalf("fast-tracked %d repos, want 5", fastTracked)
+	}
+	if coalescedRequests*6 != fullRequests {
+		t.Fatalf("repository requests = %d coalesced, %d without, want one repo's worth of %d", coalescedRequests, fullRequests, fullRequests/6)
+	}
+}
+
+func TestScanRepositoryFollowsRenamesAndRecordsDeletions(t *testing.T) {
+	now := time.Now()
+	server := githubtest.NewServer(t)
+	server.HandleJSON("/search/repositories", map[string]interface{}{"total_count": 0, "items": []interface{}{}})
+	server.Handle("/repos/old/loader", githubtest.Response{Status: http.StatusMovedPermanently, Headers: map[string]string{"Location": "/repositories/42"}})
+	server.HandleJSON("/repositories/42", map[string]interface{}{
+		"name": "loader", "full_name": "new/loader", "owner": map[string]string{"login": "new"},
+		"default_branch": "main", "size": 0, "updated_at": now.UTC().Format(time.RFC3339),
+	})
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+
+	database, err := db.New(filepath.Join(t.TempDir(), "watchdog.db"))
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	for _, id := range []string{"old/loader", "gone/tool"} {
+		owner, name, _ := strings.Cut(id, "/")
+		if err := database.InsertProcessedRepo(id, owner, name, now.Add(-time.Hour), 10, 5, true); err != nil {
+			t.Fatalf("InsertProcessedRepo(%s) error = %v", id, err)
+		}
+	}
+	service := NewService(client, database)
+
+	report, err := service.ScanRepository(context.Background(), "old", "loader", RepoOptions{Persist: true})
+	if err != nil || report.RepoID != "new/loader" || report.RenamedFrom != "old/loader" || len(report.Errors) != 0 {
+		t.Fatalf("ScanRepository(old/loader) = %+v, %v, want a scan of new/loader", report, err)
+	}
+	if renames, err := database.ListRepoRenames(); err != nil || len(renames) != 1 || renames[0].NewID != "new/loader" {
+		t.Fatalf("ListRepoRenames() = %+v, %v, want old/loader -> new/loader", renames, err)
+	}
+	if _, err := database.GetProcessedRepo("new/loader"); err != nil {
+		t.Fatalf("GetProcessedRepo(new/loader) error = %v", err)
+	}
+
+	report, err = service.ScanRepository(context.Background(), "gone", "tool", RepoOptions{Persist: true})
+	if err != nil || report.Status != github.RepoStatusDeleted || !report.TakedownConfirmed {
+		t.Fatalf("ScanRepository(gone/tool) = %+v, %v, want deleted_on_github", report, err)
+	}
+	if stored, err := database.GetProcessedRepo("gone/tool"); err != nil || stored.Status != github.RepoStatusDeleted || stored.StatusUpdatedAt == nil {
+		t.Fatalf("GetProcessedRepo(gone/tool) = %+v, %v, want deleted status with timestamp", stored, err)
+	}
+}
+
+func TestSearchFlagsDuplicateContentClusters(t *testing.T) {
+	now := time.Now()
+	server := githubtest.NewServer(t)
+	repos := []githubtest.Repo{
+		{Owner: "alice", Name: "tool", CreatedAt: now, UpdatedAt: now, Size: 50},
+		{Owner: "bob", Name: "tool", CreatedAt: now, UpdatedAt: now, Size: 50},
+		{Owner: "farmer", Name: "tool-1", CreatedAt: now, UpdatedAt: now, Size: 50},
+		{Owner: "carol", Name: "blank", CreatedAt: now, UpdatedAt: now, Size: 1},
+		{Owner: "dave", Name: "blank", CreatedAt: now, UpdatedAt: now, Size: 1},
+		{Owner: "erin", Name: "blank", CreatedAt: now, UpdatedAt: now, Size: 1},
+	}
+	server.SetSearchResults(100, repos...)
+	for _, repo := range repos {
+		server.SetReleases(repo.Owner, repo.Name)
+		if repo.Name == "blank" {
+			server.SetReadme(repo.Owner, repo.Name, "# blank")
+			server.SetTree(repo.Owner, repo.Name, "main", "README.md")
+			continue
+		}
+		server.SetReadme(repo.Owner, repo.Name, "# Tool\nFast and free.")
+		server.SetTree(repo.Owner, repo.Name, "main", "README.md", "src/main.go", "src/util.go")
+	}
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	service := NewService(client, database)
+
+	// One at a time, so each repo sees the ones persisted before it.
+	if _, err := service.Search(context.Background(), SearchOptions{Query: "stars:>1", MaxPages: 1, PerPage: 100, MaxConcurrent: 1, Persist: true}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	clusters, err := database.ListContentClusters(2)
+	if err != nil || len(clusters) != 1 {
+		t.Fatalf("ListContentClusters() = %+v, %v, want one cluster", clusters, err)
+	}
+	if got := strings.Join(clusters[0].Repos, ","); got != "alice/tool,bob/tool,farmer/tool-1" {
+		t.Fatalf("cluster repos = %s, want the three identical repos and no blank ones", got)
+	}
+	for _, repoID := range clusters[0].Repos {
+		flags, err := database.ListHeuristicFlags("repo", repoID)
+		if err != nil {
+			t.Fatalf("ListHeuristicFlags(%s) error = %v", repoID, err)
+		}
+		found := false
+		for _, flag := range flags {
+			found = found || strings.HasSuffix(flag.Flag, ":DuplicateContentHeuristic")
+		}
+		if !found {
+			t.Fatalf("ListHeuristicFlags(%s) = %+v, want DuplicateContentHeuristic", repoID, flags)
+		}
+	}
+}
+
+func TestSearchRetriesAndRecordsIncompletePages(t *testing.T) {
+	server := githubtest.NewServer(t)
+	incomplete := githubtest.Response{Body: `{"total_count":0,"incomplete_results":true,"items":[]}`}
+	server.Handle("/search/repositories", incomplete, incomplete, githubtest.Response{Body: `{"total_count":0,"items":[]}`})
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	service := NewService(client, nil)
+	opts := SearchOptions{Query: "stars:>1", MaxPages: 1, PerPage: 100, IncompleteRetries: 1}
+
+	report, err := service.Search(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(report.IncompletePages) != 1 || report.IncompletePages[0] != (IncompletePage{Query: "stars:>1", Page: 1}) {
+		t.Fatalf("IncompletePages = %+v, want page 1 recorded after the retry", report.IncompletePages)
+	}
+	if got := server.RequestCount("/search/repositories"); got != 2 {
+		t.Fatalf("search requests = %d, want the page asked for twice", got)
+	}
+
+	report, err = service.Search(context.Background(), opts)
+	if err != nil || len(report.IncompletePages) != 0 {
+		t.Fatalf("Search(again) = %+v, %v, want a complete page", report.IncompletePages, err)
+	}
+}
+
+func TestScanMatchesStoredGitHubIDs(t *testing.T) {
+	now := time.Now()
+	server := githubtest.NewServer(t)
+	server.HandleJSON("/search/repositories", map[string]interface{}{"total_count": 0, "items": []interface{}{}})
+	server.HandleJSON("/repos/new/loader", map[string]interface{}{
+		"id": 42, "node_id": "R_42", "name": "loader", "full_name": "new/loader", "owner": map[string]string{"login": "new"},
+		"default_branch": "main", "size": 0, "updated_at": now.UTC().Format(time.RFC3339),
+	})
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.InsertProcessedRepo("old/loader", "old", "loader", now.Add(-time.Hour), 10, 5, true); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	if err := database.SetRepoGitHubID("old/loader", 42, "R_42"); err != nil {
+		t.Fatalf("SetRepoGitHubID() error = %v", err)
+	}
+	service := NewService(client, database)
+
+	repo, err := service.ScanRepository(context.Background(), "new", "loader", RepoOptions{Persist: true})
+	if err != nil || repo.RenamedFrom != "old/loader" || repo.GitHubID != 42 || repo.NodeID != "R_42" {
+		t.Fatalf("ScanRepository(new/loader) = %+v, %v, want it matched to old/loader by ID", repo, err)
+	}
+	if stored, err := database.GetProcessedRepo("new/loader"); err != nil || stored.GitHubID != 42 {
+		t.Fatalf("GetProcessedRepo(new/loader) = %+v, %v, want GitHub ID 42", stored, err)
+	}
+
+	original := UserReport{Username: "farmer", GitHubID: 7, Suspicious: true, Heuristics: []models.HeuristicResult{{Category: "Other Suspicious Patterns", Name: "NewHeuristic", Flag: true}}}
+	if err := service.persistUser(&original); err != nil || original.PreviousGitHubID != 0 {
+		t.Fatalf("persistUser(original) = %v, previous %d, want no previous ID", err, original.PreviousGitHubID)
+	}
+	reregistered := UserReport{Username: "farmer", GitHubID: 9, NodeID: "U_9", Followers: 1, Following: 450, Score: 1.5}
+	if err := service.persistUser(&reregistered); err != nil || reregistered.PreviousGitHubID != 7 {
+		t.Fatalf("persistUser(reregistered) = %v, previous %d, want 7", err, reregistered.PreviousGitHubID)
+	}
+	if stored, err := database.GetProcessedUser("farmer"); err != nil || stored.Followers != 1 || stored.Following != 450 || stored.Score != 1.5 {
+		t.Fatalf("GetProcessedUser(farmer) = %+v, %v, want the follow counts and score stored", stored, err)
+	}
+	if flags, err := database.ListHeuristicFlags("user", "farmer"); err != nil || len(flags) != 0 {
+		t.Fatalf("ListHeuristicFlags(farmer) = %+v, %v, want the old account's flags dropped", flags, err)
+	}
+}
+
+func TestOwnerAnalysisFresh(t *testing.T) {
+	processedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	tests := []struct {
+		name        string
+		repoCreated time.Time
+		ttl         time.Duration
+		now         time.Time
+		want        bool
+	}{
+		{"within ttl", processedAt.Add(-time.Hour), week, processedAt.Add(6 * 24 * time.Hour), true},
+		{"ttl elapsed", processedAt.Add(-time.Hour), week, processedAt.Add(week), false},
+		{"zero ttl never expires", processedAt.Add(-time.Hour), 0, processedAt.Add(365 * 24 * time.Hour), true},
+		{"new repository", processedAt.Add(time.Hour), week, processedAt.Add(2 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		if got := ownerAnalysisFresh(processedAt, tt.repoCreated, tt.ttl, tt.now); got != tt.want {
+			t.Fatalf("%s: ownerAnalysisFresh() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScanRepositoryReusesStoredOwnerAnalysis(t *testing.T) {
+	now := time.Now()
+	server := githubtest.NewServer(t)
+	server.SetSearchResults(100, githubtest.Repo{Owner: "farmer", Name: "tool", CreatedAt: now.Add(-time.Hour), UpdatedAt: now, Size: 0})
+	server.SetUser("farmer", now.Add(-48*time.Hour))
+	server.SetUserRepos("farmer")
+	server.SetUserEvents("farmer", now)
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	opts := RepoOptions{Persist: true, AnalyzeOwner: true}
+
+	for run := 1; run <= 2; run++ {
+		service := NewServiceWithOptions(client, database, ServiceOptions{ReuseOwnerAnalysis: true, OwnerAnalysisTTL: 7 * 24 * time.Hour})
+		report, err := service.ScanRepository(context.Background(), "farmer", "tool", opts)
+		if err != nil || report.OwnerAnalysis == nil {
+			t.Fatalf("run %d: ScanRepository() = %+v, %v, want an owner analysis", run, report, err)
+		}
+		if reused := report.OwnerAnalysis.Reused; reused != (run == 2) {
+			t.Fatalf("run %d: owner analysis reused = %v", run, reused)
+		}
+	}
+	if got := server.RequestCount("/users/farmer"); got != 1 {
+		t.Fatalf("user requests = %d, want the owner analyzed once", got)
+	}
+}
+
+func TestSearchCorrelatesReposBySharedPayload(t *testing.T) {
+	now := time.Now()
+	server := githubtest.NewServer(t)
+	repos := []githubtest.Repo{
+		{Owner: "alice", Name: "cheat", CreatedAt: now, UpdatedAt: now, Size: 50},
+		{Owner: "bob", Name: "hack", CreatedAt: now, UpdatedAt: now, Size: 50},
+		{Owner: "carol", Name: "tool", CreatedAt: now, UpdatedAt: now, Size: 50},
+		{Owner: "dave", Name: "app", CreatedAt: now, UpdatedAt: now, Size: 50},
+	}
+	server.SetSearchResults(100, repos...)
+	payload := githubtest.Asset{Name: "setup.zip", Size: 4096, Digest: "sha256:ABC123"}
+	for i, repo := range repos {
+		server.SetReadme(repo.Owner, repo.Name, fmt.Sprintf("# %s", repo.Name))
+		server.SetTree(repo.Owner, repo.Name, "main", "README.md", fmt.Sprintf("src/%d.go", i))
+		if repo.Owner == "dave" {
+			server.SetReleaseAssets(repo.Owner, repo.Name, githubtest.Asset{Name: "setup.zip", Size: 4096, Digest: "sha256:def456"})
+			continue
+		}
+		server.SetReleaseAssets(repo.Owner, repo.Name, payload, githubtest.Asset{Name: "checksums.txt", Size: 64, Digest: "sha256:abc123"})
+	}
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	service := NewService(client, database)
+
+	report, err := service.Search(context.Background(), SearchOptions{Query: "stars:>1", MaxPages: 1, PerPage: 100, MaxConcurrent: 1, Persist: true})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	// Repositories are scanned concurrently, so results are not in search order.
+	for _, result := range report.Results {
+		if result.RepoID != "alice/cheat" {
+			continue
+		}
+		if assets := result.PayloadAssets; len(assets) != 1 || assets[0].Hash != "sha256:abc123" {
+			t.Fatalf("PayloadAssets = %+v, want only setup.zip by its digest", assets)
+		}
+	}
+
+	repoIDs, err := database.FindReposByAssetHash("sha256:abc123")
+	if err != nil || strings.Join(repoIDs, ",") != "alice/cheat,bob/hack,carol/tool" {
+		t.Fatalf("FindReposByAssetHash() = %v, %v, want the three repos shipping the payload", repoIDs, err)
+	}
+	for _, repoID := range []string{"alice/cheat", "bob/hack", "carol/tool", "dave/app"} {
+		flags, err := database.ListHeuristicFlags("repo", repoID)
+		if err != nil {
+			t.Fatalf("ListHeuristicFlags(%s) error = %v", repoID, err)
+		}
+		found := false
+		for _, flag := range flags {
+			found = found || flag.Flag == "Malware:SharedPayloadHeuristic"
+		}
+		if found != (repoID != "dave/app") {
+			t.Fatalf("ListHeuristicFlags(%s) = %+v, SharedPayloadHeuristic found = %v", repoID, flags, found)
+		}
+	}
+}
+
+func TestSearchHashesFlaggedAssetsWithinBudget(t *testing.T) {
+	now := time.Now()
+	server := githubtest.NewServer(t)
+	repos := []githubtest.Repo{
+		{Owner: "alice", Name: "cheat", CreatedAt: now, UpdatedAt: now, Size: 50},
+		{Owner: "bob", Name: "hack", CreatedAt: now, UpdatedAt: now, Size: 50},
+		{Owner: "carol", Name: "tool", CreatedAt: now, UpdatedAt: now, Size: 50},
+	}
+	server.SetSearchResults(100, repos...)
+	const content = "MZ payload!"
+	sum := sha256.Sum256([]byte(content))
+	hash := "sha256:" + hex.EncodeToString(sum[:])
+	assetPaths := map[string]string{}
+	for i, repo := range repos {
+		id := int64(i + 1)
+		assetPaths[repo.Owner+"/"+repo.Name] = fmt.Sprintf("/repos/%s/%s/releases/assets/%d", repo.Owner, repo.Name, id)
+		server.SetUser(repo.Owner, now.AddDate(-3, 0, 0))
+		server.SetReadme(repo.Owner, repo.Name, fmt.Sprintf("# %s", repo.Name))
+		server.SetTree(repo.Owner, repo.Name, "main", "README.md", fmt.Sprintf("src/%d.go", i))
+		server.SetReleaseAssets(repo.Owner, repo.Name, githubtest.Asset{ID: id, Name: "invoice.pdf.exe", Size: int64(len(content))})
+		server.Handle(assetPaths[repo.Owner+"/"+repo.Name], githubtest.Response{Body: content})
+	}
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	service := NewServiceWithOptions(client, database, ServiceOptions{
+		Analyzer:      analyzer.Options{ReleaseMaxOwnerAge: 14 * 24 * time.Hour},
+		AssetDownload: AssetDownloadPolicy{MaxBytes: 1 << 20, Budget: 2 * int64(len(content))},
+	})
+
+	report, err := service.Search(context.Background(), SearchOptions{Query: "stars:>1", MaxPages: 1, PerPage: 100, MaxConcurrent: 1, Persist: true})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	// Repositories are scanned concurrently, so any two of them may spend the budget.
+	var hashed []string
+	var skipped int
+	for i, result := range report.Results {
+		if errs := strings.Join(result.Errors, "; "); strings.Contains(errs, "download budget spent") {
+			skipped++
+			if got := server.RequestCount(assetPaths[result.RepoID]); got != 0 {
+				t.Fatalf("%s asset requests = %d, want none past the budget", result.RepoID, got)
+			}
+			continue
+		}
+		if assets := result.FlaggedAssets; len(assets) != 1 || assets[0].Hash != hash {
+			t.Fatalf("Results[%d].FlaggedAssets = %+v, want invoice.pdf.exe hashed by content", i, assets)
+		}
+		if assets := result.PayloadAssets; len(assets) != 1 || assets[0].Hash != hash {
+			t.Fatalf("Results[%d].PayloadAssets = %+v, want the content hash recorded", i, assets)
+		}
+		hashed = append(hashed, result.RepoID)
+	}
+	if len(hashed) != 2 || skipped != 1 {
+		t.Fatalf("hashed %v and skipped %d, want two repos hashed and one skipped", hashed, skipped)
+	}
+
+	sort.Strings(hashed)
+	repoIDs, err := database.FindReposByAssetHash(hash)
+	if err != nil || strings.Join(repoIDs, ",") != strings.Join(hashed, ",") {
+		t.Fatalf("FindReposByAssetHash() = %v, %v, want the two downloaded repos %v", repoIDs, err, hashed)
+	}
+}
+
+func TestSearchFlagsDescriptionWithoutFileAnalysis(t *testing.T) {
+	now := time.Now()
+	server := githubtest.NewServer(t)
+	server.SetSearchResults(100, githubtest.Repo{
+		Owner: "lure", Name: "fn-tool", CreatedAt: now, UpdatedAt: now,
+		Description: "Fortnite Aimbot - free download, undetected cheat",
+	})
+	server.SetUser("lure", now.AddDate(-3, 0, 0))
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	service := NewService(client, database)
+
+	report, err := service.Search(context.Background(), SearchOptions{Query: "stars:>1", MaxPages: 1, PerPage: 100, MaxConcurrent: 1, Persist: true})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	var names []string
+	for _, flag := range report.Results[0].RepoFlags {
+		names = append(names, flag.Name)
+	}
+	if got := strings.Join(names, ","); got != "FreeDownloadDescription,AimbotDescription,UndetectedCheatDescription" {
+		t.Fatalf("RepoFlags = %s, want the description rules", got)
+	}
+	if got := server.RequestCount("/repos/lure/fn-tool/git/trees/main"); got != 0 {
+		t.Fatalf("tree requests = %d, want none for an empty repository", got)
+	}
+	repo, err := database.GetProcessedRepo("lure/fn-tool")
+	if err != nil || repo.Description != "Fortnite Aimbot - free download, undetected cheat" {
+		t.Fatalf("GetProcessedRepo() = %+v, %v, want the description stored", repo, err)
+	}
+}
+
+func TestAnalyzePendingTakesUpUnanalyzedRows(t *testing.T) {
+	now := time.Now()
+	server := githubtest.NewServer(t)
+	server.SetSearchResults(100, githubtest.Repo{Owner: "legacy", Name: "tool", CreatedAt: now, UpdatedAt: now})
+	server.Handle("/search/repositories?q=repo:legacy/broken&page=1", githubtest.Response{Status: http.StatusForbidden, Body: `{"message":"API rate limit exceeded"}`})
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.InsertProcessedRepo("done/repo", "done", "repo", now, 10, 1, false); err != nil {
+		t.Fatalf("InsertProcessedRepo() error = %v", err)
+	}
+	if err := database.InsertProcessedUser("done", now, 1, 0, 0, 5, false, ""); err != nil {
+		t.Fatalf("InsertProcessedUser() error = %v", err)
+	}
+	for _, repo := range []string{"tool", "broken"} {
+		if _, err := database.InsertProcessedRepoIfAbsent("legacy/"+repo, "legacy", repo, false); err != nil {
+			t.Fatalf("InsertProcessedRepoIfAbsent() error = %v", err)
+		}
+	}
+	if _, err := database.InsertProcessedUserIfAbsent("legacyuser", true); err != nil {
+		t.Fatalf("InsertProcessedUserIfAbsent() error = %v", err)
+	}
+
+	var progress []string
+	service := NewService(client, database)
+	report, err := service.AnalyzePending(context.Background(), PendingOptions{
+		MaxConcurrent: 1,
+		OnResult:      func(result PendingResult) { progress = append(progress, result.EntityID+"="+result.Status) },
+	})
+	if err != nil {
+		t.Fatalf("AnalyzePending() error = %v", err)
+	}
+	want := []string{"legacy/tool=analyzed", "legacy/broken=error", "legacyuser=skipped"}
+	if strings.Join(progress, ",") != strings.Join(want, ",") {
+		t.Fatalf("progress = %v, want %v", progress, want)
+	}
+	if report.Pending != 3 || report.Analyzed != 1 || report.Failed != 1 || report.Skipped != 1 {
+		t.Fatalf("report = %+v, want one analyzed, failed, and skipped entity", report)
+	}
+	if !strings.Contains(report.StopReason, "rate limit") {
+		t.Fatalf("StopReason = %q, want the rate limit", report.StopReason)
+	}
+
+	repos, err := database.ListPendingRepos(0)
+	if err != nil || len(repos) != 1 || repos[0].RepoID != "legacy/broken" {
+		t.Fatalf("ListPendingRepos() = %+v, %v, want only legacy/broken", repos, err)
+	}
+	users, err := database.ListPendingUsers(0)
+	if err != nil || len(users) != 1 || users[0] != "legacyuser" {
+		t.Fatalf("ListPendingUsers() = %v, %v, want the skipped user", users, err)
+	}
+	if got := server.RequestCount("/users/done"); got != 0 {
+		t.Fatalf("analyzed user requested %d times", got)
+	}
+}
+
+func TestReanalyzeUsersUpdatesChangedVerdicts(t *testing.T) {
+	now := time.Now()
+	var repos []githubtest.Repo
+	for i := 0; i < 25; i++ {
+		repos = append(repos, githubtest.Repo{Owner: "farmer", Name: fmt.Sprintf("tool-%d", i), CreatedAt: now, UpdatedAt: now, Size: 1, Stars: 5})
+	}
+	server := githubtest.NewServer(t)
+	server.SetUser("farmer", now.Add(-48*time.Hour))
+	server.SetUserRepos("farmer", repos...)
+	server.SetUserEvents("farmer", now)
+	server.SetUser("veteran", now.AddDate(-8, 0, 0))
+	server.SetUserRepos("veteran")
+	server.SetUserEvents("veteran", now)
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.InsertProcessedUser("farmer", now.Add(-48*time.Hour), 0, 0, 0, 0, false, ""); err != nil {
+		t.Fatalf("InsertProcessedUser() error = %v", err)
+	}
+	if err := database.InsertProcessedUser("veteran", now.AddDate(-8, 0, 0), 0, 0, 0, 0, false, ""); err != nil {
+		t.Fatalf("InsertProcessedUser() error = %v", err)
+	}
+	if _, err := database.InsertProcessedUserIfAbsent("legacyuser", true); err != nil {
+		t.Fatalf("InsertProcessedUserIfAbsent() error = %v", err)
+	}
+
+	service := NewService(client, database)
+	report, err := service.ReanalyzeUsers(context.Background(), PendingOptions{MaxConcurrent: 1})
+	if err != nil {
+		t.Fatalf("ReanalyzeUsers() error = %v", err)
+	}
+	if report.Pending != 2 || report.Analyzed != 2 || report.Changed != 1 {
+		t.Fatalf("report = %+v, want two users reanalyzed and one verdict changed", report)
+	}
+	for _, result := range report.Results {
+		if result.Changed != (result.EntityID == "farmer") {
+			t.Fatalf("result %+v, want only farmer changed", result)
+		}
+	}
+
+	user, err := database.GetProcessedUser("farmer")
+	if err != nil || !user.Suspicious {
+		t.Fatalf("GetProcessedUser(farmer) = %+v, %v, want the verdict updated to suspicious", user, err)
+	}
+	if flags, err := database.ListHeuristicFlags("user", "farmer"); err != nil || len(flags) == 0 {
+		t.Fatalf("ListHeuristicFlags(farmer) = %+v, %v, want the new flags", flags, err)
+	}
+	if got := server.RequestCount("/users/legacyuser"); got != 0 {
+		t.Fatalf("pending user requested %d times, want it left to analyze-pending", got)
+	}
+}
+
+func TestDetectStarringRingsFlagsAccountsOnSeveralMaliciousRepos(t *testing.T) {
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	stars := map[string][]string{
+		"evil/one":   {"sock", "Bot", "fan"},
+		"evil/two":   {"sock", "bot"},
+		"evil/three": {"sock", "bot", "fan"},
+		"clean/lib":  {"fan"},
+	}
+	for repoID, logins := range stars {
+		owner, name, _ := strings.Cut(repoID, "/")
+		if err := database.InsertProcessedRepo(repoID, owner, name, time.Now(), 10, len(logins), owner == "evil"); err != nil {
+			t.Fatalf("InsertProcessedRepo() error = %v", err)
+		}
+		for _, login := range logins {
+			if err := database.InsertStargazer(repoID, login, time.Now()); err != nil {
+				t.Fatalf("InsertStargazer() error = %v", err)
+			}
+		}
+	}
+
+	report, err := DetectStarringRings(database, RingOptions{MinRepos: 3, Record: true})
+	if err != nil {
+		t.Fatalf("DetectStarringRings() error = %v", err)
+	}
+	if len(report.Members) != 2 || report.Members[0].Username != "bot" || report.Members[1].Username != "sock" || len(report.Members[0].Repos) != 3 {
+		t.Fatalf("Members = %+v, want bot and sock on three repos each", report.Members)
+	}
+	flags, err := database.ListHeuristicFlags("user", "sock")
+	if err != nil || len(flags) != 1 || flags[0].HeuristicName != StarringRingHeuristic || !strings.Contains(flags[0].Message, "evil/three") {
+		t.Fatalf("ListHeuristicFlags(sock) = %+v, %v, want a ring flag naming the shared repos", flags, err)
+	}
+	if pending, err := database.ListPendingUsers(0); err != nil || len(pending) != 2 {
+		t.Fatalf("ListPendingUsers() = %v, %v, want both members pending analysis", pending, err)
+	}
+	if flags, _ := database.ListHeuristicFlags("user", "fan"); len(flags) != 0 {
+		t.Fatalf("fan flagged with %+v, want only accounts on three malicious repos", flags)
+	}
+}
+
+func TestSearchSkipsReposOutsideBand(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	server := githubtest.NewServer(t)
+	server.SetSearchResults(100,
+		githubtest.Repo{Owner: "alice", Name: "popular", CreatedAt: now, UpdatedAt: now, Stars: 900},
+		githubtest.Repo{Owner: "bob", Name: "huge", CreatedAt: now, UpdatedAt: now, Stars: 10, Size: 5000},
+		githubtest.Repo{Owner: "carol", Name: "lure", CreatedAt: now, UpdatedAt: now, Stars: 10},
+	)
+	server.SetUser("carol", now.Add(-time.Hour))
+	server.SetUserRepos("carol")
+	server.SetUserEvents("carol", now)
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	service := NewService(client, database)
+	opts := SearchOptions{Query: "stars:>1", MaxPages: 1, PerPage: 100, MaxConcurrent: 1, Persist: true, Band: RepoBand{MinStars: 5, MaxStars: 50, MaxSize: 50}}
+
+	report, err := service.Search(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if report.BandFiltered != 2 {
+		t.Fatalf("BandFiltered = %d, want 2", report.BandFiltered)
+	}
+	for _, result := range report.Results {
+		if inBand := result.RepoID == "carol/lure"; result.Skipped == inBand {
+			t.Fatalf("%s skipped = %v (%s)", result.RepoID, result.Skipped, result.SkipReason)
+		}
+	}
+	if server.RequestCount("/users/alice")+server.RequestCount("/users/bob") != 0 {
+		t.Fatal("owners of repositories outside the band were analyzed")
+	}
+	if already, err := database.WasRepoProcessed("bob/huge", now); err != nil || !already {
+		t.Fatalf("WasRepoProcessed(bob/huge) = %v, %v, want it recorded", already, err)
+	}
+}
+
+type recordingNotifier struct {
+	events []notify.Event
+}
+
+func (n *recordingNotifier) Notify(event notify.Event)       { n.events = append(n.events, event) }
+func (n *recordingNotifier) Close(ctx context.Context) error { return nil }
+
+func TestScanUserClearsAllowlistedUsers(t *testing.T) {
+	now := time.Now()
+	var repos []githubtest.Repo
+	for i := 0; i < 25; i++ {
+		repos = append(repos, githubtest.Repo{Owner: "farmer", Name: fmt.Sprintf("tool-%d", i), CreatedAt: now, UpdatedAt: now, Size: 1, Stars: 5})
+	}
+	server := githubtest.NewServer(t)
+	server.SetUser("farmer", now.Add(-48*time.Hour))
+	server.SetUserRepos("farmer", repos...)
+	server.SetUserEvents("farmer", now)
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.AddAllowlistEntry("user", "Farmer", "maintains a template generator"); err != nil {
+		t.Fatalf("AddAllowlistEntry() error = %v", err)
+	}
+
+	notifier := &recordingNotifier{}
+	service := NewServiceWithOptions(client, database, ServiceOptions{Notifier: notifier})
+	report, err := service.ScanUser(context.Background(), "farmer", UserOptions{Persist: true})
+	if err != nil {
+		t.Fatalf("ScanUser() error = %v", err)
+	}
+	if !report.Allowlisted || report.Suspicious || len(report.Heuristics) != 0 {
+		t.Fatalf("ScanUser() = %+v, want an allowlisted user with no findings", report)
+	}
+	if requests := server.Requests(); len(requests) != 0 {
+		t.Fatalf("Requests() = %+v, want an allowlisted user cleared without API calls", requests)
+	}
+	if _, err := database.GetProcessedUser("farmer"); !errors.Is(err, db.ErrNotFound) {
+		t.Fatalf("GetProcessedUser() error = %v, want the allowlisted user unpersisted", err)
+	}
+	if len(notifier.events) != 0 {
+		t.Fatalf("events = %+v, want no notification", notifier.events)
+	}
+}
+
+func TestScanUserFlagsForkFarmAndStoresForkCount(t *testing.T) {
+	now := time.Now()
+	var repos []githubtest.Repo
+	for i := 0; i < 20; i++ {
+		repos = append(repos, githubtest.Repo{Owner: "booster", Name: fmt.Sprintf("fork-%d", i), CreatedAt: now, UpdatedAt: now, Size: 300, Fork: true, PushedAt: now.AddDate(-1, 0, 0)})
+	}
+	server := githubtest.NewServer(t)
+	server.SetUser("booster", now.AddDate(0, 0, -20))
+	server.SetUserRepos("booster", repos...)
+	server.SetUserEvents("booster")
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	report, err := NewService(client, database).ScanUser(context.Background(), "booster", UserOptions{Persist: true})
+	if err != nil || report.ForkCount != 20 {
+		t.Fatalf("ScanUser() = %+v, %v, want 20 forks", report, err)
+	}
+	var forkFarm *models.HeuristicResult
+	for i := range report.Heuristics {
+		if report.Heuristics[i].Name == "ForkFarmHeuristic" {
+			forkFarm = &report.Heuristics[i]
+		}
+	}
+	if forkFarm == nil || !forkFarm.Flag || !strings.Contains(forkFarm.Description, "20 of them forks (20 last pushed before the account existed)") {
+		t.Fatalf("Heuristics = %+v, want the fork farm flag", report.Heuristics)
+	}
+	user, err := database.GetProcessedUser("booster")
+	if err != nil || user.ForkCount != 20 {
+		t.Fatalf("GetProcessedUser() = %+v, %v, want the fork count stored", user, err)
+	}
+}
+
+func TestScanUserNotifiesNewlyFlaggedUsersOnce(t *testing.T) {
+	now := time.Now()
+	var repos []githubtest.Repo
+	for i := 0; i < 25; i++ {
+		repos = append(repos, githubtest.Repo{Owner: "farmer", Name: fmt.Sprintf("tool-%d", i), CreatedAt: now, UpdatedAt: now, Size: 1, Stars: 5})
+	}
+	server := githubtest.NewServer(t)
+	server.SetUser("farmer", now.Add(-48*time.Hour))
+	server.SetUserRepos("farmer", repos...)
+	server.SetUserEvents("farmer", now)
+	client := github.NewClient("test-token", 0, 0, nil, logger.New(false))
+	t.Cleanup(client.Close)
+	client.SetBaseURL(server.URL)
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	notifier := &recordingNotifier{}
+	for run := 0; run < 2; run++ {
+		service := NewServiceWithOptions(client, database, ServiceOptions{Notifier: notifier})
+		report, err := service.ScanUser(context.Background(), "farmer", UserOptions{Persist: true})
+		if err != nil || !report.Suspicious {
+			t.Fatalf("run %d: ScanUser() = %+v, %v, want a suspicious user", run, report, err)
+		}
+	}
+	if len(notifier.events) != 1 {
+		t.Fatalf("events = %+v, want one notification for the first scan only", notifier.events)
+	}
+	event := notifier.events[0]
+	if event.EntityType != "user" || event.EntityID != "farmer" || len(event.Heuristics) == 0 || event.Timestamp.IsZero() {
+		t.Fatalf("event = %+v, want farmer's triggered heuristics", event)
+	}
+	for _, heuristic := range event.Heuristics {
+		if heuristic.Name == "" || heuristic.Category == "" {
+			t.Fatalf("event heuristic %+v, want its name and category", heuristic)
+		}
+	}
+}
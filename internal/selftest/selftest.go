@@ -0,0 +1,353 @@
+// Package selftest runs every built-in detector against bundled known-bad and known-clean fixtures.
+package selftest
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/analyzer"
+	"github.com/arkouda/github/GitHubWatchdog/internal/github"
+	"github.com/arkouda/github/GitHubWatchdog/internal/models"
+)
+
+//go:embed fixtures/*.json
+var fixtureFiles embed.FS
+
+var (
+	errNoRepoInput = errors.New("case has no repo input")
+	errNoUserInput = errors.New("case has no user input")
+)
+
+// Detector kinds.
+const (
+	KindRepoChecker   = "repo_checker"
+	KindRepoHeuristic = "repo_heuristic"
+	KindUserHeuristic = "user_heuristic"
+)
+
+// Fixture documents one detector and the cases it must get right.
+type Fixture struct {
+	Detector    string `json:"detector"`
+	Description string `json:"description"`
+	Cases       []Case `json:"cases"`
+}
+
+// Case is one known-bad or known-clean input.
+type Case struct {
+	Name       string       `json:"name"`
+	ExpectFlag bool         `json:"expect_flag"`
+	Repo       *FixtureRepo `json:"repo,omitempty"`
+	User       *FixtureUser `json:"user,omitempty"`
+}
+
+// FixtureRepo describes repository input. When Count is above one the entry expands into
+// Count repositories, with {n} in Name replaced by 1..Count.
+type FixtureRepo struct {
+	Owner       string   `json:"owner"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Readme      string   `json:"readme"`
+	TreeEntries []string `json:"tree_entries"`
+	DiskUsage   int      `json:"disk_usage"`
+	Stargazers  int      `json:"stargazers"`
+	Count       int      `json:"count"`
+	// Languages gives bytes of code per language, as GitHub reports them.
+	Languages map[string]int `json:"languages"`
+	// Commits, when positive, is the sampled commit count of each generated repository.
+	Commits int `json:"commits"`
+	// Fork marks each generated repository as a fork.
+	Fork bool `json:"fork"`
+	// Topics are the repository's GitHub topics.
+	Topics []string `json:"topics"`
+	// Files gives the content of tree entries by path, for the checkers that read source files.
+	Files map[string]string `json:"files"`
+}
+
+// FixtureUser describes user input. Account age is relative so fixtures do not expire.
+type FixtureUser struct {
+	Username       string `json:"username"`
+	CreatedDaysAgo int    `json:"created_days_ago"`
+	Contributions  int    `json:"contributions"`
+	Followers      int    `json:"followers"`
+	Following      int    `json:"following"`
+	// Bio, Blog, TwitterUsername, and ProfileReadme are the account's profile text.
+	Bio             string `json:"bio"`
+	Blog            string `json:"blog"`
+	TwitterUsername string `json:"twitter_username"`
+	ProfileReadme   string `json:"profile_readme"`
+	// AvatarMatches are the flagged accounts sharing the user's avatar.
+	AvatarMatches []string      `json:"avatar_matches"`
+	Repos         []FixtureRepo `json:"repos"`
+}
+
+// CaseResult is the outcome of one fixture case.
+type CaseResult struct {
+	Name     string `json:"name"`
+	Expected bool   `json:"expected"`
+	Got      bool   `json:"got"`
+	Passed   bool   `json:"passed"`
+	Error    string `json:"error,omitempty"`
+}
+
+// DetectorResult is the outcome for one detector across its fixture cases.
+type DetectorResult struct {
+	Detector    string       `json:"detector"`
+	Kind        string       `json:"kind,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Passed      bool         `json:"passed"`
+	Error       string       `json:"error,omitempty"`
+	Cases       []CaseResult `json:"cases,omitempty"`
+}
+
+// Report is the outcome of a self-test run.
+type Report struct {
+	Passed    bool             `json:"passed"`
+	Detectors []DetectorResult `json:"detectors"`
+}
+
+// FailedCount returns the number of detectors that did not pass.
+func (r Report) FailedCount() int {
+	failed := 0
+	for _, detector := range r.Detectors {
+		if !detector.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+type detector struct {
+	kind     string
+	evaluate func(ctx context.Context, c Case) (bool, error)
+}
+
+// Run evaluates the built-in detectors configured by opts against the bundled fixtures.
+// The external command is never run.
+func Run(ctx context.Context, opts analyzer.Options) (Report, error) {
+	fixtures, err := LoadFixtures()
+	if err != nil {
+		return Report{}, err
+	}
+	return run(ctx, builtinDetectors(opts), fixtures), nil
+}
+
+// LoadFixtures reads the bundled fixtures, ordered by detector name.
+func LoadFixtures() ([]Fixture, error) {
+	paths, err := fs.Glob(fixtureFiles, "fixtures/*.json")
+	if err != nil {
+		return nil, fmt.Errorf("listing selftest fixtures: %w", err)
+	}
+	fixtures := make([]Fixture, 0, len(paths))
+	for _, path := range paths {
+		data, err := fixtureFiles.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading selftest fixture %s: %w", path, err)
+		}
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("parsing selftest fixture %s: %w", path, err)
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].Detector < fixtures[j].Detector })
+	return fixtures, nil
+}
+
+func builtinDetectors(opts analyzer.Options) map[string]detector {
+	// Every fixture needs its detector, so the self-test covers heuristics the run disables.
+	opts.DisabledHeuristics = nil
+	detectors := map[string]detector{
+		"ReadmeChecker":  repoCheckerDetector(&analyzer.ReadmeChecker{}),
+		"KeywordChecker": repoCheckerDetector(&analyzer.KeywordChecker{}),
+		"LoaderChecker":  repoCheckerDetector(&analyzer.LoaderChecker{}),
+		// Without a resolver the outbound link check runs offline, on links as written.
+		"OutboundLinkChecker": repoCheckerDetector(&analyzer.OutboundLinkChecker{}),
+		"TopicSpamChecker":    repoCheckerDetector(&analyzer.TopicSpamChecker{}),
+		"BinaryOnlyChecker":   repoCheckerDetector(&analyzer.BinaryOnlyChecker{}),
+		"DescriptionChecker":  repoCheckerDetector(&analyzer.DescriptionChecker{}),
+		"PayloadBlobChecker": fileCheckerDetector(func(client github.GitHubAPI) analyzer.RepoChecker {
+			return &analyzer.PayloadBlobChecker{Sources: &analyzer.SourceSampler{Client: client}}
+		}),
+		"ObfuscationChecker": fileCheckerDetector(func(client github.GitHubAPI) analyzer.RepoChecker {
+			return &analyzer.ObfuscationChecker{Sources: &analyzer.SourceSampler{Client: client}}
+		}),
+		"ManifestChecker": fileCheckerDetector(func(client github.GitHubAPI) analyzer.RepoChecker {
+			return &analyzer.ManifestChecker{Client: client}
+		}),
+		"WorkflowChecker": fileCheckerDetector(func(client github.GitHubAPI) analyzer.RepoChecker {
+			return &analyzer.WorkflowChecker{Client: client}
+		}),
+	}
+	// SingleLanguageHeuristic is registered so configuration can turn it off, but it judges the
+	// same metadata as the built-in repository heuristics.
+	for _, heuristic := range append(analyzer.DefaultRepoHeuristics(), &analyzer.SingleLanguageHeuristic{}) {
+		name := heuristic.Evaluate(models.RepoData{}).Name
+		detectors[name] = detector{kind: KindRepoHeuristic, evaluate: func(_ context.Context, c Case) (bool, error) {
+			if c.Repo == nil {
+				return false, errNoRepoInput
+			}
+			return heuristic.Evaluate(c.Repo.repoData(0)).Flag, nil
+		}}
+	}
+	for _, heuristic := range analyzer.DefaultUserHeuristics(opts) {
+		name := heuristic.Evaluate(models.UserData{}, nil).Name
+		detectors[name] = detector{kind: KindUserHeuristic, evaluate: func(_ context.Context, c Case) (bool, error) {
+			if c.User == nil {
+				return false, errNoUserInput
+			}
+			data, repos := c.User.userData(time.Now())
+			return heuristic.Evaluate(data, repos).Flag, nil
+		}}
+	}
+	return detectors
+}
+
+func repoCheckerDetector(checker analyzer.RepoChecker) detector {
+	return detector{kind: KindRepoChecker, evaluate: func(ctx context.Context, c Case) (bool, error) {
+		if c.Repo == nil {
+			return false, errNoRepoInput
+		}
+		return checker.Check(ctx, c.Repo.repoData(0))
+	}}
+}
+
+// fileCheckerDetector evaluates a checker that reads file contents, serving each case's files
+// from the fixture rather than GitHub.
+func fileCheckerDetector(newChecker func(github.GitHubAPI) analyzer.RepoChecker) detector {
+	return detector{kind: KindRepoChecker, evaluate: func(ctx context.Context, c Case) (bool, error) {
+		if c.Repo == nil {
+			return false, errNoRepoInput
+		}
+		return newChecker(fixtureContents{files: c.Repo.Files}).Check(ctx, c.Repo.repoData(0))
+	}}
+}
+
+// fixtureContents serves a case's files to the checkers that read file contents, which read
+// nothing else. Any other GitHub call panics on the nil embedded client.
+type fixtureContents struct {
+	github.GitHubAPI
+	files map[string]string
+}
+
+// GetFileContent returns the fixture content of path, or "" when the case does not give it.
+func (f fixtureContents) GetFileContent(_ context.Context, _, _, path, _ string) (string, error) {
+	return f.files[path], nil
+}
+
+// run evaluates each detector's fixture cases. Detectors without fixtures, and fixtures
+// naming unknown detectors, fail so coverage gaps are visible.
+func run(ctx context.Context, detectors map[string]detector, fixtures []Fixture) Report {
+	report := Report{Passed: true}
+	covered := map[string]bool{}
+	for _, fixture := range fixtures {
+		covered[fixture.Detector] = true
+		result := DetectorResult{Detector: fixture.Detector, Description: fixture.Description, Passed: true}
+		d, ok := detectors[fixture.Detector]
+		switch {
+		case !ok:
+			result.Passed = false
+			result.Error = "fixture names an unknown detector"
+		case len(fixture.Cases) == 0:
+			result.Passed = false
+			result.Error = "fixture has no cases"
+		default:
+			result.Kind = d.kind
+			for _, c := range fixture.Cases {
+				caseResult := CaseResult{Name: c.Name, Expected: c.ExpectFlag}
+				got, err := d.evaluate(ctx, c)
+				caseResult.Got = got
+				caseResult.Passed = err == nil && got == c.ExpectFlag
+				if err != nil {
+					caseResult.Error = err.Error()
+				}
+				if !caseResult.Passed {
+					result.Passed = false
+				}
+				result.Cases = append(result.Cases, caseResult)
+			}
+		}
+		report.Detectors = append(report.Detectors, result)
+	}
+
+	var uncovered []string
+	for name := range detectors {
+		if !covered[name] {
+			uncovered = append(uncovered, name)
+		}
+	}
+	sort.Strings(uncovered)
+	for _, name := range uncovered {
+		report.Detectors = append(report.Detectors, DetectorResult{
+			Detector: name,
+			Kind:     detectors[name].kind,
+			Error:    "no fixtures for detector",
+		})
+	}
+
+	sort.SliceStable(report.Detectors, func(i, j int) bool { return report.Detectors[i].Detector < report.Detectors[j].Detector })
+	report.Passed = report.FailedCount() == 0
+	return report
+}
+
+func (r FixtureRepo) repoData(n int) models.RepoData {
+	name := r.Name
+	if n > 0 {
+		name = strings.ReplaceAll(name, "{n}", strconv.Itoa(n))
+	}
+	return models.RepoData{
+		Owner:          r.Owner,
+		Name:           name,
+		Description:    r.Description,
+		Readme:         r.Readme,
+		TreeEntries:    r.TreeEntries,
+		DiskUsage:      r.DiskUsage,
+		StargazerCount: r.Stargazers,
+		Languages:      r.Languages,
+		Fork:           r.Fork,
+		Topics:         r.Topics,
+	}
+}
+
+func (u FixtureUser) userData(now time.Time) (models.UserData, []models.RepoData) {
+	var repos []models.RepoData
+	var sampled, singleCommit int
+	for _, repo := range u.Repos {
+		if repo.Owner == "" {
+			repo.Owner = u.Username
+		}
+		count := max(repo.Count, 1)
+		for n := 1; n <= count; n++ {
+			repos = append(repos, repo.repoData(n))
+		}
+		if repo.Commits > 0 {
+			sampled += count
+			if repo.Commits == 1 {
+				singleCommit += count
+			}
+		}
+	}
+	data := models.UserData{
+		Username:          u.Username,
+		CreatedAt:         now.Add(-time.Duration(u.CreatedDaysAgo) * 24 * time.Hour),
+		Contributions:     u.Contributions,
+		Followers:         u.Followers,
+		Following:         u.Following,
+		Bio:               u.Bio,
+		Blog:              u.Blog,
+		TwitterUsername:   u.TwitterUsername,
+		ProfileReadme:     u.ProfileReadme,
+		AvatarMatches:     u.AvatarMatches,
+		Repositories:      repos,
+		CommitSampled:     sampled,
+		SingleCommitRepos: singleCommit,
+	}
+	return data, repos
+}
@@ -0,0 +1,48 @@
+package selftest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/analyzer"
+)
+
+func TestRunPassesBundledFixtures(t *testing.T) {
+	report, err := Run(context.Background(), analyzer.Options{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for _, detector := range report.Detectors {
+		if !detector.Passed {
+			t.Errorf("detector %s failed: %s %+v", detector.Detector, detector.Error, detector.Cases)
+		}
+	}
+	if !report.Passed {
+		t.Fatalf("Run() passed = false, want every bundled fixture to pass")
+	}
+	if got, want := len(report.Detectors), len(builtinDetectors(analyzer.Options{})); got != want {
+		t.Fatalf("Run() detectors = %d, want %d", got, want)
+	}
+}
+
+func TestRunReportsBrokenAndUncoveredDetectors(t *testing.T) {
+	detectors := map[string]detector{
+		"Broken": {kind: KindRepoHeuristic, evaluate: func(context.Context, Case) (bool, error) { return false, nil }},
+		"Silent": {kind: KindRepoHeuristic, evaluate: func(context.Context, Case) (bool, error) { return true, nil }},
+	}
+	fixtures := []Fixture{{
+		Detector: "Broken",
+		Cases:    []Case{{Name: "known bad", ExpectFlag: true, Repo: &FixtureRepo{Name: "bad"}}},
+	}}
+
+	report := run(context.Background(), detectors, fixtures)
+	if report.Passed {
+		t.Fatal("run() passed = true, want failure")
+	}
+	if report.FailedCount() != 2 {
+		t.Fatalf("FailedCount() = %d, want broken and uncovered detectors", report.FailedCount())
+	}
+	if report.Detectors[1].Detector != "Silent" || report.Detectors[1].Error == "" {
+		t.Fatalf("Detectors[1] = %+v, want uncovered Silent detector", report.Detectors[1])
+	}
+}
@@ -0,0 +1,283 @@
+// Package urlscan submits suspicious landing pages to urlscan.io and collects the resulting verdicts.
+package urlscan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
+)
+
+const (
+	defaultBaseURL = "https://urlscan.io"
+	// defaultSubmitInterval spaces submissions to stay well inside the per-minute private scan quota.
+	defaultSubmitInterval = 2 * time.Second
+	maxSubmitAttempts     = 3
+)
+
+// Verdict values recorded for a scan.
+const (
+	VerdictPending   = "pending"
+	VerdictClean     = "clean"
+	VerdictMalicious = "malicious"
+)
+
+// ErrDisabled is returned when a submission is attempted without an API key.
+var ErrDisabled = errors.New("urlscan is disabled; set URLSCAN_API_KEY")
+
+// Result is the outcome of a urlscan.io submission.
+type Result struct {
+	UUID          string    `json:"uuid"`
+	URL           string    `json:"url"`
+	ResultURL     string    `json:"result_url"`
+	ScreenshotURL string    `json:"screenshot_url,omitempty"`
+	Verdict       string    `json:"verdict"`
+	Score         int       `json:"score"`
+	Categories    []string  `json:"categories,omitempty"`
+	SubmittedAt   time.Time `json:"submitted_at"`
+	CompletedAt   time.Time `json:"completed_at,omitempty"`
+}
+
+// Client submits URLs with private visibility and polls for their results.
+type Client struct {
+	httpClient *http.Client
+	apiKey     string
+	baseURL    string
+	logger     *logger.Logger
+
+	submitInterval  time.Duration
+	initialWait     time.Duration
+	pollInterval    time.Duration
+	maxPollInterval time.Duration
+	pollTimeout     time.Duration
+
+	mutex      sync.Mutex
+	lastSubmit time.Time
+}
+
+// NewClient creates a urlscan.io client. An empty apiKey yields a disabled client.
+func NewClient(apiKey string, appLogger *logger.Logger) *Client {
+	if appLogger == nil {
+		appLogger = logger.New(false)
+	}
+	return &Client{
+		httpClient:      &http.Client{Timeout: 30 * time.Second},
+		apiKey:          apiKey,
+		baseURL:         defaultBaseURL,
+		logger:          appLogger.For("urlscan"),
+		submitInterval:  defaultSubmitInterval,
+		initialWait:     10 * time.Second,
+		pollInterval:    2 * time.Second,
+		maxPollInterval: 30 * time.Second,
+		pollTimeout:     2 * time.Minute,
+	}
+}
+
+// Enabled reports whether an API key is configured.
+func (c *Client) Enabled() bool {
+	return c != nil && c.apiKey != ""
+}
+
+// Scan submits rawURL and waits for the result. If the scan is still running when the
+// poll timeout expires, the pending result is returned so it can be revisited later.
+func (c *Client) Scan(ctx context.Context, rawURL string) (Result, error) {
+	result, err := c.Submit(ctx, rawURL)
+	if err != nil {
+		return Result{}, err
+	}
+	return c.WaitForResult(ctx, result)
+}
+
+// Submit queues rawURL for a private scan.
+func (c *Client) Submit(ctx context.Context, rawURL string) (Result, error) {
+	if !c.Enabled() {
+		return Result{}, ErrDisabled
+	}
+
+	payload, err := json.Marshal(map[string]string{"url": rawURL, "visibility": "private"})
+	if err != nil {
+		return Result{}, fmt.Errorf("encoding urlscan submission: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := c.waitForSubmitSlot(ctx); err != nil {
+			return Result{}, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/scan/", bytes.NewReader(payload))
+		if err != nil {
+			return Result{}, err
+		}
+		req.Header.Set("API-Key", c.apiKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return Result{}, fmt.Errorf("submitting to urlscan: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxSubmitAttempts {
+			wait := rateLimitResetAfter(resp)
+			resp.Body.Close()
+			c.logger.Info("urlscan rate limit reached. Waiting %s before retrying", wait)
+			if err := sleepContext(ctx, wait); err != nil {
+				return Result{}, err
+			}
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			bodyBytes, _ := io.ReadAll(resp.Body)
+			return Result{}, fmt.Errorf("urlscan submission failed: %s - %s", resp.Status, string(bodyBytes))
+		}
+
+		var submission struct {
+			UUID   string `json:"uuid"`
+			Result string `json:"result"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&submission); err != nil {
+			return Result{}, fmt.Errorf("decoding urlscan submission: %w", err)
+		}
+		return Result{
+			UUID:        submission.UUID,
+			URL:         rawURL,
+			ResultURL:   submission.Result,
+			Verdict:     VerdictPending,
+			SubmittedAt: time.Now().UTC(),
+		}, nil
+	}
+}
+
+// WaitForResult polls for a submitted scan with exponential backoff.
+func (c *Client) WaitForResult(ctx context.Context, pending Result) (Result, error) {
+	deadline := time.Now().Add(c.pollTimeout)
+	wait := c.initialWait
+	interval := c.pollInterval
+	for {
+		if time.Now().Add(wait).After(deadline) {
+			c.logger.Debug("urlscan result %s still pending after %s", pending.UUID, c.pollTimeout)
+			return pending, nil
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return pending, err
+		}
+
+		result, ready, err := c.fetchResult(ctx, pending)
+		if err != nil {
+			return pending, err
+		}
+		if ready {
+			return result, nil
+		}
+
+		wait = interval
+		interval *= 2
+		if interval > c.maxPollInterval {
+			interval = c.maxPollInterval
+		}
+	}
+}
+
+func (c *Client) fetchResult(ctx context.Context, pending Result) (Result, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/result/"+pending.UUID+"/", nil)
+	if err != nil {
+		return pending, false, err
+	}
+	req.Header.Set("API-Key", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return pending, false, fmt.Errorf("fetching urlscan result: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return pending, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return pending, false, fmt.Errorf("urlscan result lookup failed: %s - %s", resp.Status, string(bodyBytes))
+	}
+
+	var body struct {
+		Task struct {
+			ReportURL     string `json:"reportURL"`
+			ScreenshotURL string `json:"screenshotURL"`
+		} `json:"task"`
+		Verdicts struct {
+			Overall struct {
+				Score      int      `json:"score"`
+				Malicious  bool     `json:"malicious"`
+				Categories []string `json:"categories"`
+			} `json:"overall"`
+		} `json:"verdicts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return pending, false, fmt.Errorf("decoding urlscan result: %w", err)
+	}
+
+	result := pending
+	result.ResultURL = firstNonEmpty(body.Task.ReportURL, pending.ResultURL)
+	result.ScreenshotURL = body.Task.ScreenshotURL
+	result.Score = body.Verdicts.Overall.Score
+	result.Categories = body.Verdicts.Overall.Categories
+	result.Verdict = VerdictClean
+	if body.Verdicts.Overall.Malicious {
+		result.Verdict = VerdictMalicious
+	}
+	result.CompletedAt = time.Now().UTC()
+	return result, true, nil
+}
+
+func (c *Client) waitForSubmitSlot(ctx context.Context) error {
+	c.mutex.Lock()
+	next := c.lastSubmit.Add(c.submitInterval)
+	now := time.Now()
+	if next.Before(now) {
+		next = now
+	}
+	c.lastSubmit = next
+	c.mutex.Unlock()
+	return sleepContext(ctx, time.Until(next))
+}
+
+func rateLimitResetAfter(resp *http.Response) time.Duration {
+	if value := resp.Header.Get("X-Rate-Limit-Reset-After"); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+			return time.Duration(seconds * float64(time.Second))
+		}
+	}
+	return time.Minute
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
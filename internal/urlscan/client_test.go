@@ -0,0 +1,100 @@
+package urlscan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/logger"
+)
+
+func newTestClient(baseURL string) *Client {
+	client := NewClient("test-key", logger.New(false))
+	client.baseURL = baseURL
+	client.submitInterval = 0
+	client.initialWait = time.Millisecond
+	client.pollInterval = time.Millisecond
+	client.maxPollInterval = 5 * time.Millisecond
+	client.pollTimeout = time.Second
+	return client
+}
+
+func TestScanSubmitsPrivatelyAndPollsUntilReady(t *testing.T) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("API-Key") != "test-key" {
+			t.Errorf("API-Key = %q, want test-key", r.Header.Get("API-Key"))
+		}
+		switch r.URL.Path {
+		case "/api/v1/scan/":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["visibility"] != "private" {
+				t.Errorf("visibility = %q, want private", body["visibility"])
+			}
+			_, _ = w.Write([]byte(`{"uuid":"abc-123","result":"https://urlscan.io/result/abc-123/"}`))
+		case "/api/v1/result/abc-123/":
+			if atomic.AddInt32(&polls, 1) < 3 {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(`{"task":{"reportURL":"https://urlscan.io/result/abc-123/","screenshotURL":"https://urlscan.io/screenshots/abc-123.png"},"verdicts":{"overall":{"score":100,"malicious":true,"categories":["phishing"]}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	result, err := newTestClient(server.URL).Scan(context.Background(), "https://landing.example/download")
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if result.Verdict != VerdictMalicious || result.Score != 100 {
+		t.Fatalf("Scan() = %+v, want malicious verdict", result)
+	}
+	if result.ScreenshotURL == "" || result.UUID != "abc-123" {
+		t.Fatalf("Scan() = %+v, want uuid and screenshot", result)
+	}
+	if got := atomic.LoadInt32(&polls); got != 3 {
+		t.Fatalf("polls = %d, want 3", got)
+	}
+}
+
+func TestSubmitRetriesAfterRateLimit(t *testing.T) {
+	var submits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&submits, 1) == 1 {
+			w.Header().Set("X-Rate-Limit-Reset-After", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"uuid":"retry-1","result":"https://urlscan.io/result/retry-1/"}`))
+	}))
+	defer server.Close()
+
+	result, err := newTestClient(server.URL).Submit(context.Background(), "https://landing.example")
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if result.UUID != "retry-1" || result.Verdict != VerdictPending {
+		t.Fatalf("Submit() = %+v, want pending retry-1", result)
+	}
+	if got := atomic.LoadInt32(&submits); got != 2 {
+		t.Fatalf("submits = %d, want 2", got)
+	}
+}
+
+func TestSubmitWithoutKeyIsDisabled(t *testing.T) {
+	client := NewClient("", logger.New(false))
+	if client.Enabled() {
+		t.Fatal("Enabled() = true, want false without key")
+	}
+	if _, err := client.Submit(context.Background(), "https://landing.example"); !errors.Is(err, ErrDisabled) {
+		t.Fatalf("Submit() error = %v, want ErrDisabled", err)
+	}
+}
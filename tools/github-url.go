@@ -1,62 +1,63 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"time"
+
+	"github.com/arkouda/github/GitHubWatchdog/internal/db"
+	"github.com/arkouda/github/GitHubWatchdog/internal/report"
 )
 
-func processSuspiciousUsers(inputPath, outputPath string) error {
-	inputFile, err := os.Open(inputPath)
+func writeMarkdown(dbPath, outputPath string, filter report.MarkdownFilter) error {
+	database, err := db.New(dbPath)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer database.Close()
+
+	result, err := report.BuildMarkdownReport(database, filter)
 	if err != nil {
-		return fmt.Errorf("opening input file: %w", err)
+		return err
 	}
-	defer inputFile.Close()
 
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return fmt.Errorf("creating output directory: %w", err)
 	}
-
-	outputFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("creating output file: %w", err)
+	if err := os.WriteFile(outputPath, []byte(report.RenderMarkdown(result)), 0644); err != nil {
+		return fmt.Errorf("writing output file: %w", err)
 	}
-	defer outputFile.Close()
-
-	scanner := bufio.NewScanner(inputFile)
-	writer := bufio.NewWriter(outputFile)
-	defer writer.Flush()
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
+	return nil
+}
 
-		if _, err := fmt.Fprintf(writer, "1. [%s](https://github.com/%s)\n", line, line); err != nil {
-			return fmt.Errorf("writing output line: %w", err)
-		}
+func parseSince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
 	}
-
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("reading input file: %w", err)
+	if parsed, err := time.Parse("2006-01-02", value); err == nil {
+		return parsed, nil
 	}
-
-	return nil
+	return time.Parse(time.RFC3339, value)
 }
 
 func main() {
-	inputPath := flag.String("input", "malicious_stargazers.txt", "Path to the newline-delimited username list")
-	outputPath := flag.String("output", "./bark/malicious_stargazers/README.md", "Path to write the generated Markdown list")
+	dbPath := flag.String("db", "github_watchdog.db", "Path to the SQLite database")
+	outputPath := flag.String("o", "./bark/README.md", "Path to write the generated Markdown report")
+	since := flag.String("since", "", "Only include entries first seen on or after this YYYY-MM-DD or RFC3339 time")
+	category := flag.String("category", "", "Only include entries with a flag in this category")
 	flag.Parse()
 
-	if err := processSuspiciousUsers(*inputPath, *outputPath); err != nil {
+	sinceTime, err := parseSince(*since)
+	if err != nil {
+		fmt.Println("Error: invalid -since:", err)
+		os.Exit(1)
+	}
+	if err := writeMarkdown(*dbPath, *outputPath, report.MarkdownFilter{Since: sinceTime, Category: *category}); err != nil {
 		fmt.Println("Error:", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("Parsed URLs written to", *outputPath)
+	fmt.Println("Markdown report written to", *outputPath)
 }